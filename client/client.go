@@ -0,0 +1,281 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+// Package client calls the GTS HTTP server (see package server) with typed requests and the
+// result types of package gts.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+// codeValidationFailed is the error code of content rejected by validation (see server.ErrorCodeValidationFailed)
+const codeValidationFailed = "GTS_VALIDATION_FAILED"
+
+// Error is returned for non-2xx responses; Code, Message and Details are those of the server's
+// error envelope, e.g. GTS_ENTITY_NOT_FOUND for 404
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    map[string]any
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("gts server: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("gts server: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Client calls a GTS server. It is safe for concurrent use once configured.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	authToken  string
+}
+
+// New creates a client of the server at baseURL, e.g. "http://127.0.0.1:8000". A nil httpClient
+// uses http.DefaultClient.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: httpClient}
+}
+
+// SetAuthToken sends token as a bearer token with every request, for servers started with -auth-token
+func (c *Client) SetAuthToken(token string) {
+	c.authToken = token
+}
+
+// Entity is a registered entity as answered by GET /entities/{id}
+type Entity struct {
+	ID           string            `json:"id"`
+	ShortID      string            `json:"short_id"`
+	Content      map[string]any    `json:"content"`
+	ContentHash  string            `json:"content_hash"`
+	RegisteredAt time.Time         `json:"registered_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+// RegisterResult is the answer of POST /entities
+type RegisterResult struct {
+	OK    bool   `json:"ok"`
+	GtsID string `json:"gts_id"`
+	// Warnings lists unresolved references when the server runs with -ref-validation warn
+	Warnings []string `json:"warnings,omitempty"`
+	// Dependents is the re-validation report of an overwritten schema (see -revalidate-dependents)
+	Dependents *gts.DependentsReport `json:"dependents,omitempty"`
+}
+
+// BulkItem is the outcome of one entity of POST /entities/bulk; Code and Error are set when it failed
+type BulkItem struct {
+	OK       bool     `json:"ok"`
+	GtsID    string   `json:"gts_id,omitempty"`
+	Code     string   `json:"code,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// BulkResult is the answer of POST /entities/bulk: Count of Total entities were registered
+type BulkResult struct {
+	OK      bool       `json:"ok"`
+	Count   int        `json:"count"`
+	Total   int        `json:"total"`
+	Results []BulkItem `json:"results"`
+}
+
+// GetEntity returns the registered entity with the given ID
+func (c *Client) GetEntity(ctx context.Context, id string) (*Entity, error) {
+	var entity Entity
+	if err := c.do(ctx, http.MethodGet, "/entities/"+url.PathEscape(id), nil, &entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// AddEntity registers a schema or an instance
+func (c *Client) AddEntity(ctx context.Context, content map[string]any) (*RegisterResult, error) {
+	var result RegisterResult
+	if err := c.do(ctx, http.MethodPost, "/entities", content, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddEntities registers entities one by one; the entities that fail are reported in the result
+// and do not fail the call
+func (c *Client) AddEntities(ctx context.Context, contents []map[string]any) (*BulkResult, error) {
+	var result BulkResult
+	if err := c.do(ctx, http.MethodPost, "/entities/bulk", contents, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ValidateInstance validates a registered instance against its schema. An instance the server
+// finds invalid is not an error: the result has OK unset, Error and Violations describe the
+// failure and Err holds the *Error answered. Unknown instances, missing schemas and malformed IDs
+// fail with *Error.
+func (c *Client) ValidateInstance(ctx context.Context, instanceID string) (*gts.ValidationResult, error) {
+	var result gts.ValidationResult
+	err := c.do(ctx, http.MethodPost, "/validate-instance", map[string]any{"instance_id": instanceID}, &result)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.Code == codeValidationFailed {
+		return invalidResult(instanceID, apiErr), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// invalidResult returns the validation result of an instance the server answered 422 for
+func invalidResult(instanceID string, apiErr *Error) *gts.ValidationResult {
+	result := &gts.ValidationResult{ID: instanceID, Error: apiErr.Message, Err: apiErr}
+	if violations, ok := apiErr.Details["violations"]; ok {
+		// The violations were decoded generically with the rest of the envelope
+		if data, err := json.Marshal(violations); err == nil {
+			_ = json.Unmarshal(data, &result.Violations)
+		}
+	}
+	return result
+}
+
+// CheckCompatibility checks the compatibility of two schema versions
+func (c *Client) CheckCompatibility(ctx context.Context, oldSchemaID, newSchemaID string) (*gts.CompatibilityResult, error) {
+	query := url.Values{"old_schema_id": {oldSchemaID}, "new_schema_id": {newSchemaID}}
+	var result gts.CompatibilityResult
+	if err := c.do(ctx, http.MethodGet, "/compatibility?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Cast casts a registered instance to another minor version of its schema
+func (c *Client) Cast(ctx context.Context, instanceID, toSchemaID string) (*gts.CastResult, error) {
+	var result gts.CastResult
+	body := map[string]any{"instance_id": instanceID, "to_schema_id": toSchemaID}
+	if err := c.do(ctx, http.MethodPost, "/cast", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Query returns up to limit entities matching a query expression, e.g.
+// "gts.x.shop.events.*[tenant=initech]"; a limit of 0 uses the server default
+func (c *Client) Query(ctx context.Context, expr string, limit int) (*gts.QueryResult, error) {
+	query := url.Values{"expr": {expr}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var result gts.QueryResult
+	if err := c.do(ctx, http.MethodGet, "/query?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAttribute returns the value of an attribute, e.g.
+// "gts.x.shop.events.event.v1~x.shop._.o1004.v1@payload.total". A path the entity does not have
+// fails with *Error carrying the available fields in its Details.
+func (c *Client) GetAttribute(ctx context.Context, gtsWithPath string) (*gts.AttributeResult, error) {
+	query := url.Values{"gts_with_path": {gtsWithPath}}
+	var result gts.AttributeResult
+	if err := c.do(ctx, http.MethodGet, "/attr?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ResolveRelationships returns the graph of the schemas and entities an entity refers to
+func (c *Client) ResolveRelationships(ctx context.Context, gtsID string) (*gts.SchemaGraphNode, error) {
+	query := url.Values{"gts_id": {gtsID}}
+	var result gts.SchemaGraphNode
+	if err := c.do(ctx, http.MethodGet, "/resolve-relationships?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// do sends body as JSON, when not nil, and decodes the JSON response into out. Non-2xx responses
+// fail with *Error.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s: read response: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return responseError(resp, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// responseError returns the error of a non-2xx response, from its error envelope when it has one
+func responseError(resp *http.Response, data []byte) *Error {
+	var envelope struct {
+		Error *struct {
+			Code    string         `json:"code"`
+			Message string         `json:"message"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Error != nil {
+		return &Error{
+			StatusCode: resp.StatusCode,
+			Code:       envelope.Error.Code,
+			Message:    envelope.Error.Message,
+			Details:    envelope.Error.Details,
+		}
+	}
+	message := strings.TrimSpace(string(data))
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &Error{StatusCode: resp.StatusCode, Message: message}
+}
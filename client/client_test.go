@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+	"github.com/GlobalTypeSystem/gts-go/server"
+)
+
+const (
+	orderV10 = "gts.x.shop.orders.order.v1.0~"
+	orderV11 = "gts.x.shop.orders.order.v1.1~"
+	order1   = orderV10 + "x.shop._.o1.v1"
+)
+
+// orderSchema returns a minor version of the order schema; v1.1 adds an optional currency
+func orderSchema(id string, withCurrency bool) map[string]any {
+	properties := map[string]any{
+		"id":    map[string]any{"type": "string"},
+		"total": map[string]any{"type": "number"},
+	}
+	if withCurrency {
+		properties["currency"] = map[string]any{"type": "string", "default": "EUR"}
+	}
+	return map[string]any{
+		"$id":        "gts://" + id,
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"required":   []any{"id", "total"},
+		"properties": properties,
+	}
+}
+
+// newTestClient serves an empty store and returns a client of it
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	ts := httptest.NewServer(server.NewServer(gts.NewGtsStore(nil), "127.0.0.1", 0, 0).Handler())
+	t.Cleanup(ts.Close)
+	return New(ts.URL, ts.Client())
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	bulk, err := c.AddEntities(ctx, []map[string]any{orderSchema(orderV10, false), orderSchema(orderV11, true), {"type": "missing"}})
+	if err != nil {
+		t.Fatalf("AddEntities failed: %v", err)
+	}
+	if bulk.OK || bulk.Count != 2 || bulk.Total != 3 || bulk.Results[2].OK || bulk.Results[2].Code != "GTS_INVALID_ID" {
+		t.Errorf("Expected two registered schemas and one invalid entity, got %+v", bulk)
+	}
+
+	registered, err := c.AddEntity(ctx, map[string]any{"id": order1, "total": 12})
+	if err != nil || !registered.OK || registered.GtsID != order1 {
+		t.Fatalf("AddEntity failed: %+v %v", registered, err)
+	}
+
+	entity, err := c.GetEntity(ctx, order1)
+	if err != nil {
+		t.Fatalf("GetEntity failed: %v", err)
+	}
+	if entity.ID != order1 || entity.Content["total"] != float64(12) || entity.ContentHash == "" || entity.RegisteredAt.IsZero() {
+		t.Errorf("Unexpected entity: %+v", entity)
+	}
+
+	validation, err := c.ValidateInstance(ctx, order1)
+	if err != nil || !validation.OK {
+		t.Errorf("Expected the order to be valid, got %+v %v", validation, err)
+	}
+
+	compat, err := c.CheckCompatibility(ctx, orderV10, orderV11)
+	if err != nil || !compat.IsBackwardCompatible || compat.NewID != orderV11 {
+		t.Errorf("Expected v1.1 to be backward compatible, got %+v %v", compat, err)
+	}
+
+	cast, err := c.Cast(ctx, order1, orderV11)
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	if cast.CastedEntity["currency"] != "EUR" || cast.CompatibilityResult == nil || cast.ToID != orderV11 {
+		t.Errorf("Expected the default currency to be added, got %+v", cast)
+	}
+
+	query, err := c.Query(ctx, "gts.x.shop.orders.*[total=12]", 10)
+	if err != nil || query.Count != 1 || query.Limit != 10 {
+		t.Errorf("Expected one matching order, got %+v %v", query, err)
+	}
+
+	attr, err := c.GetAttribute(ctx, order1+"@total")
+	if err != nil || !attr.Resolved || attr.Value != float64(12) {
+		t.Errorf("Expected the total attribute, got %+v %v", attr, err)
+	}
+
+	graph, err := c.ResolveRelationships(ctx, order1)
+	if err != nil || graph.ID != order1 {
+		t.Errorf("Expected the relationships of the order, got %+v %v", graph, err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	if _, err := c.AddEntity(ctx, orderSchema(orderV10, false)); err != nil {
+		t.Fatalf("AddEntity failed: %v", err)
+	}
+
+	var apiErr *Error
+	if _, err := c.GetEntity(ctx, order1); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "GTS_ENTITY_NOT_FOUND" {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+	if _, err := c.GetAttribute(ctx, orderV10+"@missing"); !errors.As(err, &apiErr) || apiErr.Details["available_fields"] == nil {
+		t.Errorf("Expected an attribute error listing the available fields, got %v", err)
+	}
+
+	// An invalid instance is a result, not an error
+	if _, err := c.AddEntity(ctx, map[string]any{"id": order1, "total": "twelve"}); err != nil {
+		t.Fatalf("AddEntity failed: %v", err)
+	}
+	validation, err := c.ValidateInstance(ctx, order1)
+	if err != nil {
+		t.Fatalf("ValidateInstance failed: %v", err)
+	}
+	if validation.OK || validation.Error == "" || len(validation.Violations) == 0 || !errors.As(validation.Err, &apiErr) {
+		t.Errorf("Expected the violations of the invalid order, got %+v", validation)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.GetEntity(canceled, orderV10); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled context to fail the request, got %v", err)
+	}
+}
+
+func TestClient_AuthToken(t *testing.T) {
+	srv := server.NewServer(gts.NewGtsStore(nil), "127.0.0.1", 0, 0)
+	srv.SetAccessConfig(server.AccessConfig{AuthToken: "secret"})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	c := New(ts.URL+"/", nil)
+	var apiErr *Error
+	if _, err := c.AddEntity(context.Background(), orderSchema(orderV10, false)); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without the token, got %v", err)
+	}
+	c.SetAuthToken("secret")
+	if _, err := c.AddEntity(context.Background(), orderSchema(orderV10, false)); err != nil {
+		t.Errorf("Expected the token to be accepted, got %v", err)
+	}
+}
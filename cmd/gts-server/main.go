@@ -7,7 +7,11 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/GlobalTypeSystem/gts-go/gts"
 	"github.com/GlobalTypeSystem/gts-go/server"
@@ -17,12 +21,156 @@ func main() {
 	host := flag.String("host", "127.0.0.1", "Host to bind to")
 	port := flag.Int("port", 8000, "Port to listen on")
 	verbose := flag.Int("verbose", 1, "Verbosity level (0=silent, 1=info, 2=debug)")
+	path := flag.String("path", "", "Comma-separated paths to JSON and schema files or directories to load")
+	configPath := flag.String("config", os.Getenv("GTS_CONFIG"), "Path to a GTS config JSON file: ID fields, excluded directories and ID limits (default $GTS_CONFIG)")
+	refValidation := flag.String("ref-validation", "off", "GTS reference validation on registration: off, warn or strict")
+	freezeAfterLoad := flag.Bool("freeze-after-load", false, "Switch the store to read-only mode once the initial load completes")
+	stable := flag.Bool("stable", false, "List and query entities in ID order so repeated responses are byte-identical")
+	revalidateDependents := flag.String("revalidate-dependents", "off", "Re-validate instances when a schema is overwritten: off, report or reject")
+	rejectSchemaIDConflicts := flag.Bool("reject-schema-id-conflicts", false, "Refuse instances whose schema-ID fields name different schemas, unless they differ in minor version only")
+	enforceIDTypeConsistency := flag.Bool("enforce-id-type-consistency", false, "Refuse instances declaring a schema ID other than the type their chained ID encodes, up to minor versions")
+	lenientLookup := flag.Bool("lenient-lookup", false, "Retry missed IDs and query patterns lowercased, trimmed and without gts:// (answers carry X-GTS-Normalized-ID)")
+	readerMissPolicy := flag.String("reader-miss-policy", "always-retry", "Lookups of IDs the loaded files do not have: always-retry, negative-cache or never-retry-after-load")
+	strict := flag.Bool("strict", false, "Refuse to start when -path files are invalid JSON, hold objects without a valid GTS ID or repeat IDs")
+	watch := flag.Bool("watch", false, "Poll the files of -path for changes and apply them to the store (edits, new and deleted files)")
+	dataDir := flag.String("data-dir", "", "Directory registered entities are written to and loaded from on startup, one file per entity")
+	maxUploadFileSize := flag.Int64("max-upload-file-size", server.DefaultMaxUploadFileSize, "Maximum size in bytes of a file or archive member uploaded to /entities:upload")
+	maxUploadSize := flag.Int64("max-upload-size", server.DefaultMaxUploadSize, "Maximum size in bytes of an /entities:upload request")
+	corsOrigins := flag.String("cors-origins", "", "Comma-separated origins allowed to call the API from a browser (* for any)")
+	authToken := flag.String("auth-token", "", "Bearer token required on POST, PUT, PATCH and DELETE requests")
+	authReads := flag.Bool("auth-reads", false, "Require the -auth-token on GET requests too")
 	flag.Parse()
+	if *authReads && *authToken == "" {
+		log.Fatal("-auth-reads requires -auth-token")
+	}
+	var cfg *gts.GtsConfig
+	if *configPath != "" {
+		loaded, err := gts.LoadGtsConfig(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg = loaded
+		gts.SetLimits(cfg.MaxIDLength, cfg.MaxSegments)
+	}
 
 	// Create store
-	store := gts.NewGtsStore(nil)
+	store, err := newStore(*path, storeOptions{
+		refValidation:            *refValidation,
+		revalidateDependents:     *revalidateDependents,
+		freezeAfterLoad:          *freezeAfterLoad,
+		stable:                   *stable,
+		rejectSchemaIDConflicts:  *rejectSchemaIDConflicts,
+		enforceIDTypeConsistency: *enforceIDTypeConsistency,
+		readerMissPolicy:         *readerMissPolicy,
+		lenientLookup:            *lenientLookup,
+		strict:                   *strict,
+		watch:                    *watch,
+		dataDir:                  *dataDir,
+		config:                   cfg,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Create and start server
 	srv := server.NewServer(store, *host, *port, *verbose)
+	srv.SetUploadLimits(*maxUploadFileSize, *maxUploadSize)
+	srv.SetAccessConfig(server.AccessConfig{
+		CORSOrigins: server.ParseCORSOrigins(*corsOrigins),
+		AuthToken:   *authToken,
+		AuthReads:   *authReads,
+	})
 	log.Fatal(srv.Start())
 }
+
+// storeOptions holds the store settings given on the command line
+type storeOptions struct {
+	refValidation            string
+	revalidateDependents     string
+	freezeAfterLoad          bool
+	stable                   bool
+	rejectSchemaIDConflicts  bool
+	enforceIDTypeConsistency bool
+	readerMissPolicy         string
+	lenientLookup            bool
+	// strict makes newStore fail with the errors of the files of path (see gts.GtsStore.LoadErrors)
+	strict  bool
+	watch   bool
+	dataDir string
+	// config is the configuration entities are read and request bodies parsed with; nil uses
+	// gts.DefaultGtsConfig
+	config *gts.GtsConfig
+}
+
+// newStore creates the server store, loading entities from path and freezing it if requested.
+// With a data directory the entities persisted there are loaded after path, so they take
+// precedence, and registrations and removals are written through to it. With watch the files of
+// path, but not those of the data directory, are watched for changes for the life of the process.
+func newStore(path string, opts storeOptions) (*gts.GtsStore, error) {
+	mode, err := gts.ParseRefValidationMode(opts.refValidation)
+	if err != nil {
+		return nil, err
+	}
+	dependentsMode, err := gts.ParseDependentValidationMode(opts.revalidateDependents)
+	if err != nil {
+		return nil, err
+	}
+	missPolicy, err := gts.ParseReaderMissPolicy(opts.readerMissPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, p := range strings.Split(path, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if opts.watch && len(paths) == 0 {
+		return nil, fmt.Errorf("-watch requires -path")
+	}
+	if opts.watch && opts.freezeAfterLoad {
+		return nil, fmt.Errorf("-watch cannot be combined with -freeze-after-load")
+	}
+	watched := slices.Clone(paths)
+	var writer gts.GtsWriter
+	if opts.dataDir != "" {
+		dirWriter, err := gts.NewGtsDirWriter(opts.dataDir)
+		if err != nil {
+			return nil, err
+		}
+		writer = dirWriter
+		paths = append(paths, opts.dataDir)
+	}
+	var reader gts.GtsReader
+	if len(paths) > 0 {
+		reader = gts.NewGtsFileReader(paths, opts.config)
+	}
+
+	store := gts.NewGtsStoreWithPersistence(reader, writer, &gts.RegistryConfig{
+		RefValidation:                      mode,
+		StableOrder:                        opts.stable,
+		RevalidateDependentsOnSchemaChange: dependentsMode,
+		RejectSchemaIDConflicts:            opts.rejectSchemaIDConflicts,
+		EnforceIDTypeConsistency:           opts.enforceIDTypeConsistency,
+		ReaderMissPolicy:                   missPolicy,
+		LenientLookup:                      opts.lenientLookup,
+		IndexUUIDs:                         true,
+		GtsConfig:                          opts.config,
+	})
+	if errs := store.LoadErrors(); len(errs) > 0 {
+		if opts.strict {
+			return nil, &gts.StoreLoadError{Errors: errs}
+		}
+		log.Printf("WARNING: %d load errors in -path files, first: %v", len(errs), errs[0])
+	}
+	if opts.freezeAfterLoad {
+		store.Freeze()
+	}
+	if opts.watch {
+		if _, err := store.WatchPaths(watched, gts.WatchOptions{Config: opts.config}); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
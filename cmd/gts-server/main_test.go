@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+func TestNewStore_FreezeAfterLoad(t *testing.T) {
+	dir := t.TempDir()
+	schema := `{
+		"$id": "gts://gts.test.pkg.ns.user.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object"
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "user.schema.json"), []byte(schema), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	store, err := newStore(dir, storeOptions{refValidation: "off", freezeAfterLoad: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !store.IsFrozen() {
+		t.Fatal("Expected store to be frozen after load")
+	}
+	if store.Get("gts.test.pkg.ns.user.v1~") == nil {
+		t.Error("Expected entities from path to be loaded before freezing")
+	}
+
+	entity := gts.NewJsonEntity(map[string]any{"id": "gts.test.pkg.ns.user.v1~test.app._.alice.v1"}, gts.DefaultGtsConfig())
+	var frozenErr *gts.StoreFrozenError
+	if err := store.Register(entity); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected StoreFrozenError, got %v", err)
+	}
+}
+
+func TestNewStore_WithoutFreeze(t *testing.T) {
+	store, err := newStore("", storeOptions{refValidation: "warn"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if store.IsFrozen() {
+		t.Error("Expected store to be writable without -freeze-after-load")
+	}
+
+	if _, err := newStore("", storeOptions{refValidation: "loud"}); err == nil {
+		t.Error("Expected error for invalid -ref-validation value")
+	}
+	if _, err := newStore("", storeOptions{revalidateDependents: "always"}); err == nil {
+		t.Error("Expected error for invalid -revalidate-dependents value")
+	}
+	if _, err := newStore("", storeOptions{readerMissPolicy: "sometimes"}); err == nil {
+		t.Error("Expected error for invalid -reader-miss-policy value")
+	}
+}
+
+func TestNewStore_DataDir(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+	store, err := newStore("", storeOptions{dataDir: dataDir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.RegisterSchema("gts.test.pkg.ns.user.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+
+	restarted, err := newStore("", storeOptions{dataDir: dataDir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restarted.Get("gts.test.pkg.ns.user.v1~") == nil {
+		t.Error("Expected the registered schema to be loaded from -data-dir after a restart")
+	}
+}
+
+func TestNewStore_Config(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"uri": "gts.test.pkg.ns.user.v1~test.app._.alice.v1"}`), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	cfg := gts.DefaultGtsConfig()
+	cfg.EntityIDFields = []string{"uri"}
+
+	store, err := newStore(dir, storeOptions{config: cfg})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if store.Get("gts.test.pkg.ns.user.v1~test.app._.alice.v1") == nil {
+		t.Error("Expected the entity ID to be read from the configured field")
+	}
+	if store.GtsConfig() != cfg {
+		t.Error("Expected the store to parse request bodies with the loaded config")
+	}
+}
+
+func TestNewStore_Watch(t *testing.T) {
+	if _, err := newStore("", storeOptions{watch: true}); err == nil {
+		t.Error("Expected error for -watch without -path")
+	}
+	dir := t.TempDir()
+	if _, err := newStore(dir, storeOptions{watch: true, freezeAfterLoad: true}); err == nil {
+		t.Error("Expected error for -watch with -freeze-after-load")
+	}
+	if _, err := newStore(filepath.Join(dir, "missing"), storeOptions{watch: true}); err == nil {
+		t.Error("Expected error for a missing watched path")
+	}
+	if _, err := newStore(dir, storeOptions{watch: true}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNewStore_Strict(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"id": `), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	if _, err := newStore(dir, storeOptions{}); err != nil {
+		t.Fatalf("Expected load errors to be logged only, got %v", err)
+	}
+	var loadErr *gts.StoreLoadError
+	if _, err := newStore(dir, storeOptions{strict: true}); !errors.As(err, &loadErr) || loadErr.Errors[0].Category != gts.ReaderErrorInvalidJSON {
+		t.Errorf("Expected a StoreLoadError with -strict, got %v", err)
+	}
+}
@@ -6,17 +6,22 @@ Released under Apache License 2.0
 package main
 
 var cmdAttr = &Command{
-	UsageLine: "attr -path <gts-id@path>",
+	UsageLine: "attr [-path] <gts-id@path>",
 	Short:     "get attribute value from a GTS entity",
 	Long: `
 Attr retrieves an attribute value from a GTS entity using path notation.
 
-The -path flag specifies the GTS ID with attribute path (e.g., gts.x.y.z.v1.0@field.subfield).
+The -path flag, or the single argument, specifies the GTS ID with attribute
+path (e.g., gts.x.y.z.v1.0@field.subfield). Array elements are selected by
+index, e.g. items[0], negative indices counting from the end (items[-1] is the
+last element), or all at once with [*]: items[*].sku collects the sku of every
+element into an array, and nested [*] selections are flattened into it.
 Requires the global -path flag to be set to load entities.
 
 Example:
 
 	gts -path ./examples attr -path gts.vendor.pkg.ns.type.v1.0@name
+	gts -path ./examples attr 'gts.vendor.pkg.ns.type.v1.0@items[*].sku'
 	`,
 }
 
@@ -30,11 +35,14 @@ func init() {
 }
 
 func runAttr(cmd *Command, args []string) {
-	if attrPath == "" {
+	if attrPath == "" && len(args) == 1 {
+		attrPath = args[0]
+	}
+	if attrPath == "" || len(args) > 1 {
 		cmd.Usage()
 	}
 
 	store := newStore()
 	result := store.GetAttribute(attrPath)
-	writeJSON(result)
+	emitResult(result, result.Resolved)
 }
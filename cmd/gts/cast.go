@@ -5,42 +5,98 @@ Released under Apache License 2.0
 
 package main
 
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
 var cmdCast = &Command{
-	UsageLine: "cast -from <from-id> -to <to-schema-id>",
+	UsageLine: "cast -from <from-id> -to <to-schema-id> | cast -in <file> -to <to-schema-id> | cast -all <pattern> <to-schema-id>",
 	Short:     "cast an instance to a target schema",
 	Long: `
 Cast transforms an instance to conform to a target schema version.
 
 The -from flag specifies the source instance GTS ID.
 The -to flag specifies the target schema GTS ID.
+The -in flag names a JSON file holding the instance instead, which is cast
+without being registered; its schema is resolved from its content as on
+registration, e.g. from its type field.
+The -all flag casts every instance matching a query expression instead, e.g. to
+migrate a topic of events between minor versions; the target schema is given
+with -to or as argument. Instances of another type or major version than the
+target are skipped, and the output summarizes the casts that succeeded, failed
+and were fully compatible. The -limit flag caps the number of instances.
 Requires -path to be set to load entities.
 
 Example:
 
 	gts -path ./examples cast -from gts.vendor.pkg.ns.type.v1.0 -to gts.vendor.pkg.ns.type.v2~
+	gts -path ./examples cast -in event.json -to gts.x.orders.events.placed.v1.2~
+	gts -path ./examples cast -all 'gts.x.orders.*' gts.x.orders.events.placed.v1.2~
 	`,
 }
 
 var (
-	castFrom string
-	castTo   string
+	castFrom  string
+	castIn    string
+	castTo    string
+	castAll   string
+	castLimit int
 )
 
 func init() {
 	cmdCast.Run = runCast
 	cmdCast.Flag.StringVar(&castFrom, "from", "", "source instance GTS ID")
+	cmdCast.Flag.StringVar(&castIn, "in", "", "JSON file of an instance to cast without registering it")
 	cmdCast.Flag.StringVar(&castTo, "to", "", "target schema GTS ID")
+	cmdCast.Flag.StringVar(&castAll, "all", "", "cast every instance matching this query expression")
+	cmdCast.Flag.IntVar(&castLimit, "limit", 0, "maximum number of instances to cast with -all (0 for no limit)")
 }
 
 func runCast(cmd *Command, args []string) {
-	if castFrom == "" || castTo == "" {
+	if castAll != "" {
+		if castTo == "" && len(args) == 1 {
+			castTo = args[0]
+		}
+		if castTo == "" || castFrom != "" || castIn != "" {
+			cmd.Usage()
+		}
+		result := newStore().CastAll(castAll, castTo, castLimit)
+		if result.Err != nil {
+			fatalError(result.Err, "cast failed: %v", result.Err)
+		}
+		emitResult(result, result.Failed == 0)
+		return
+	}
+	if (castFrom == "") == (castIn == "") || castTo == "" {
 		cmd.Usage()
 	}
 
 	store := newStore()
-	result, err := store.Cast(castFrom, castTo)
+	var result *gts.CastResult
+	var err error
+	if castIn != "" {
+		result, err = store.CastContent(readInstanceFile(castIn), castTo)
+	} else {
+		result, err = store.Cast(castFrom, castTo)
+	}
 	if err != nil {
-		fatalf("cast failed: %v", err)
+		fatalError(err, "cast failed: %v", err)
+	}
+	emitResult(result, result.IsFullyCompatible)
+}
+
+// readInstanceFile reads a JSON object from a file
+func readInstanceFile(file string) map[string]any {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fatalf("failed to read %s: %v", file, err)
+	}
+	var content map[string]any
+	if err := json.Unmarshal(data, &content); err != nil || content == nil {
+		failf("%s must hold a JSON object", file)
 	}
-	writeJSON(result)
+	return content
 }
@@ -6,38 +6,75 @@ Released under Apache License 2.0
 package main
 
 var cmdCompatibility = &Command{
-	UsageLine: "compatibility -old <old-schema-id> -new <new-schema-id>",
+	UsageLine: "compatibility -old <old-schema-id> -new <new-schema-id> | compatibility -series <type-id>",
 	Short:     "check compatibility between two schemas",
 	Long: `
 Compatibility checks whether two schema versions are compatible.
 
 The -old flag specifies the old schema GTS ID.
 The -new flag specifies the new schema GTS ID.
+The -series flag checks every loaded minor version of a type instead, given
+its schema ID without minor version: consecutive minor versions are checked
+pairwise and the lowest against the highest, and the output tells whether the
+whole series is backward compatible. Minor versions missing from the series
+are listed in missing_versions.
+The -diff flag adds the structural difference of the two schemas to the
+output: the JSON Patch turning the old schema into the new one, both with
+their allOf layers merged and referenced GTS schemas inlined, sorted by
+path so that it can be committed for review, and a summary line per
+operation.
 Requires -path to be set to load entities.
 
 Example:
 
 	gts -path ./examples compatibility -old gts.vendor.pkg.ns.type.v1~ -new gts.vendor.pkg.ns.type.v2~
+	gts -path ./examples compatibility -diff -old gts.x.core.events.type.v1.0~ -new gts.x.core.events.type.v1.1~
+	gts -path ./examples compatibility -series gts.x.core.compat.event.v1~
 	`,
 }
 
 var (
-	compatOld string
-	compatNew string
+	compatOld    string
+	compatNew    string
+	compatSeries string
+	compatDiff   bool
 )
 
 func init() {
 	cmdCompatibility.Run = runCompatibility
 	cmdCompatibility.Flag.StringVar(&compatOld, "old", "", "old schema GTS ID")
 	cmdCompatibility.Flag.StringVar(&compatNew, "new", "", "new schema GTS ID")
+	cmdCompatibility.Flag.StringVar(&compatSeries, "series", "", "check every minor version of this schema ID without minor version")
+	cmdCompatibility.Flag.BoolVar(&compatDiff, "diff", false, "add the JSON Patch between the two schemas to the output")
 }
 
 func runCompatibility(cmd *Command, args []string) {
+	if compatSeries != "" {
+		if compatOld != "" || compatNew != "" || compatDiff {
+			cmd.Usage()
+		}
+		result := newStore().CheckCompatibilitySeries(compatSeries)
+		if result.Err != nil {
+			fatalError(result.Err, "compatibility failed: %v", result.Err)
+		}
+		emitResult(result, result.IsBackwardCompatible)
+		return
+	}
 	if compatOld == "" || compatNew == "" {
 		cmd.Usage()
 	}
 
 	store := newStore()
 	result := store.CheckCompatibility(compatOld, compatNew)
-	writeJSON(result)
+	if result.Err != nil {
+		fatalError(result.Err, "compatibility failed: %v", result.Err)
+	}
+	if compatDiff {
+		diff, err := store.DiffSchemas(compatOld, compatNew)
+		if err != nil {
+			fatalError(err, "diff failed: %v", err)
+		}
+		result.Diff = diff
+	}
+	emitResult(result, result.IsBackwardCompatible)
 }
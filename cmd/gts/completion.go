@@ -0,0 +1,500 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdCompletion = &Command{
+	UsageLine: "completion <bash|zsh|fish>",
+	Short:     "generate a shell completion script",
+	Long: `
+Completion prints a completion script for the given shell. The script is
+generated from the command table: command names and aliases, the flags of
+each command and their descriptions.
+
+Flags and arguments that take a GTS ID are completed with the IDs of the
+registered entities, loaded from the -path given on the command line being
+completed (or GTS_PATH), or fetched from the server in GTS_SERVER when set.
+At most GTS_COMPLETE_MAX IDs (default 100) are offered.
+
+Example:
+
+	source <(gts completion bash)
+	gts completion zsh > "${fpath[1]}/_gts"
+	gts completion fish > ~/.config/fish/completions/gts.fish
+	`,
+}
+
+var cmdComplete = &Command{
+	UsageLine: "__complete [-server url] [-max n] [prefix]",
+	Short:     "print the GTS IDs starting with a prefix",
+	Long: `
+__complete is called by the completion scripts. It prints the IDs of the
+entities loaded from -path, or listed by the server given with -server, that
+start with prefix, one per line in ID order, at most -max of them.
+	`,
+	Hidden: true,
+}
+
+// defaultCompleteMax is the default number of IDs printed by __complete
+const defaultCompleteMax = 100
+
+var (
+	completeServer string
+	completeMax    int
+)
+
+func init() {
+	cmdCompletion.Run = runCompletion
+	cmdComplete.Run = runComplete
+
+	completeMax = defaultCompleteMax
+	if v := os.Getenv("GTS_COMPLETE_MAX"); v != "" {
+		fmt.Sscanf(v, "%d", &completeMax)
+	}
+	cmdComplete.Flag.StringVar(&completeServer, "server", os.Getenv("GTS_SERVER"), "URL of a GTS server to list IDs from")
+	cmdComplete.Flag.IntVar(&completeMax, "max", completeMax, "maximum number of IDs to print")
+}
+
+func runCompletion(cmd *Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+	}
+	var script bytes.Buffer
+	if err := writeCompletionScript(&script, args[0], flag.CommandLine); err != nil {
+		usagef("%v", err)
+	}
+	if jsonOutput {
+		writeJSON(map[string]string{"shell": args[0], "script": script.String()})
+		return
+	}
+	os.Stdout.Write(script.Bytes())
+}
+
+func runComplete(cmd *Command, args []string) {
+	prefix := ""
+	if len(args) > 0 {
+		prefix = args[0]
+	}
+	ids, err := completionCandidates(completeServer)
+	if err != nil {
+		// Completion must not print errors into the shell, leave the candidates empty instead
+		os.Exit(exitError)
+	}
+	if jsonOutput {
+		var out bytes.Buffer
+		printCompletions(&out, ids, prefix, completeMax)
+		writeJSON(strings.Fields(out.String()))
+		return
+	}
+	printCompletions(os.Stdout, ids, prefix, completeMax)
+}
+
+// completionCandidates returns the IDs of the entities listed by server, or of the store loaded from -path
+func completionCandidates(server string) ([]string, error) {
+	if server != "" {
+		return fetchServerIDs(server)
+	}
+	store := newStore()
+	result := store.List(store.Count())
+	ids := make([]string, 0, len(result.Entities))
+	for _, info := range result.Entities {
+		ids = append(ids, info.ID)
+	}
+	return ids, nil
+}
+
+// fetchServerIDs lists entity IDs from a GTS server; the server returns at most 1000 entities
+func fetchServerIDs(server string) ([]string, error) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(strings.TrimSuffix(server, "/") + "/entities?limit=1000")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server answered %s", resp.Status)
+	}
+
+	var result gts.ListResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(result.Entities))
+	for _, info := range result.Entities {
+		ids = append(ids, info.ID)
+	}
+	return ids, nil
+}
+
+// printCompletions prints the IDs starting with prefix in ID order, at most max of them when positive
+func printCompletions(w io.Writer, ids []string, prefix string, max int) {
+	var matches []string
+	for _, id := range ids {
+		if strings.HasPrefix(id, prefix) {
+			matches = append(matches, id)
+		}
+	}
+	sort.Strings(matches)
+	if max > 0 && len(matches) > max {
+		matches = matches[:max]
+	}
+	for _, id := range matches {
+		fmt.Fprintln(w, id)
+	}
+}
+
+// completionFlag describes a flag to the completion scripts
+type completionFlag struct {
+	Name  string
+	Usage string
+	// Value is set for flags that take a value
+	Value bool
+	// ID is set for flags whose value is a GTS ID
+	ID bool
+}
+
+// completionCommand describes a command to the completion scripts
+type completionCommand struct {
+	Name    string
+	Aliases []string
+	Short   string
+	Flags   []completionFlag
+	// IDArgs is set when the positional arguments are GTS IDs
+	IDArgs bool
+	// Words are the fixed positional arguments, e.g. the shells of completion
+	Words []string
+}
+
+// completionCommands describes the visible runnable commands. Flags come from each command's flag set;
+// which of them take GTS IDs, and the positional arguments, come from the placeholders of its usage
+// line: a placeholder mentioning "id" (<gts-id>, <type-id>, ...) is completed with IDs and a|b lists
+// the accepted words.
+func completionCommands() []completionCommand {
+	var result []completionCommand
+	for _, cmd := range commands {
+		if !cmd.Runnable() || cmd.Hidden {
+			continue
+		}
+		c := completionCommand{Name: cmd.Name(), Aliases: cmd.Aliases, Short: cmd.Short}
+		idFlags := make(map[string]bool)
+
+		tokens := strings.Fields(cmd.UsageLine)[1:]
+		for i := 0; i < len(tokens); i++ {
+			tok := strings.Trim(tokens[i], "[]")
+			if strings.HasPrefix(tok, "-") {
+				name, _, _ := strings.Cut(strings.TrimLeft(tok, "-"), "=")
+				f := cmd.Flag.Lookup(name)
+				if f == nil || isBoolFlag(f) || strings.Contains(tok, "=") || i+1 >= len(tokens) {
+					continue
+				}
+				i++
+				if isIDPlaceholder(tokens[i]) {
+					idFlags[name] = true
+				}
+				continue
+			}
+			word := strings.Trim(tok, "<>.")
+			if strings.Contains(word, "|") {
+				c.Words = append(c.Words, strings.Split(word, "|")...)
+			} else if isIDPlaceholder(tok) {
+				c.IDArgs = true
+			}
+		}
+
+		c.Flags = flagsOf(&cmd.Flag)
+		for i := range c.Flags {
+			c.Flags[i].ID = idFlags[c.Flags[i].Name]
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// flagsOf describes the flags of a flag set in name order
+func flagsOf(fs *flag.FlagSet) []completionFlag {
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, completionFlag{Name: f.Name, Usage: f.Usage, Value: !isBoolFlag(f)})
+	})
+	return flags
+}
+
+// isBoolFlag reports whether a flag is given without a value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// isIDPlaceholder reports whether a usage line placeholder such as <gts-id> stands for a GTS ID
+func isIDPlaceholder(tok string) bool {
+	tok = strings.Trim(tok, "[]")
+	return strings.HasPrefix(tok, "<") && strings.Contains(strings.ToLower(tok), "id")
+}
+
+// writeCompletionScript writes the completion script of a shell for the commands and the global flags
+func writeCompletionScript(w io.Writer, shell string, global *flag.FlagSet) error {
+	cmds := completionCommands()
+	globals := flagsOf(global)
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, cmds, globals)
+	case "zsh":
+		writeZshCompletion(w, cmds, globals)
+	case "fish":
+		writeFishCompletion(w, cmds, globals)
+	default:
+		return fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", shell)
+	}
+	return nil
+}
+
+// dashed returns the flag names with their leading dash, filtered by keep when not nil
+func dashed(flags []completionFlag, keep func(completionFlag) bool) string {
+	var names []string
+	for _, f := range flags {
+		if keep == nil || keep(f) {
+			names = append(names, "-"+f.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// commandNames returns the name and aliases of a command
+func commandNames(c completionCommand) []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+func takesValue(f completionFlag) bool { return f.Value }
+
+func takesID(f completionFlag) bool { return f.ID }
+
+// singleQuoted quotes s for bash, zsh and fish, closing and reopening the quotes around each quote in s
+func singleQuoted(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeBashCompletion(w io.Writer, cmds []completionCommand, globals []completionFlag) {
+	var names []string
+	for _, c := range cmds {
+		names = append(names, commandNames(c)...)
+	}
+
+	fmt.Fprintf(w, `# bash completion for gts, generated by "gts completion bash"
+_gts() {
+    local cur prev cmd i flags="" value_flags="" id_flags="" id_args=0 words=""
+    local -a path_args=()
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]/#--/-}"
+
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]/#--/-}" in
+            -path|-config) path_args+=("${COMP_WORDS[i]}" "${COMP_WORDS[i+1]}"); ((i++)) ;;
+            %s) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    if [[ -z $cmd ]]; then
+        case " %s " in
+            *" $prev "*) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+        esac
+        if [[ $cur == -* ]]; then
+            COMPREPLY=($(compgen -W %s -- "$cur"))
+        else
+            COMPREPLY=($(compgen -W %s -- "$cur"))
+        fi
+        return
+    fi
+
+    case "$cmd" in
+`, strings.ReplaceAll(dashed(globals, takesValue), " ", "|"), dashed(globals, takesValue),
+		singleQuoted(dashed(globals, nil)), singleQuoted(strings.Join(names, " ")))
+
+	for _, c := range cmds {
+		idArgs := 0
+		if c.IDArgs {
+			idArgs = 1
+		}
+		fmt.Fprintf(w, "        %s) flags=%s; value_flags=%s; id_flags=%s; id_args=%d; words=%s ;;\n",
+			strings.Join(commandNames(c), "|"), singleQuoted(dashed(c.Flags, nil)),
+			singleQuoted(dashed(c.Flags, takesValue)), singleQuoted(dashed(c.Flags, takesID)),
+			idArgs, singleQuoted(strings.Join(c.Words, " ")))
+	}
+
+	fmt.Fprint(w, `    esac
+
+    if [[ " $id_flags " == *" $prev "* ]] || [[ $id_args == 1 && $cur != -* && " $value_flags " != *" $prev "* ]]; then
+        COMPREPLY=($(compgen -W "$(gts "${path_args[@]}" __complete -- "$cur" 2>/dev/null)" -- "$cur"))
+    elif [[ " $value_flags " == *" $prev "* ]]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+    elif [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+    elif [[ -n $words ]]; then
+        COMPREPLY=($(compgen -W "$words" -- "$cur"))
+    fi
+}
+complete -F _gts gts
+`)
+}
+
+// zshDescribed returns the name:description entries of a zsh _describe array
+func zshDescribed(names []string, desc string) string {
+	var entries []string
+	for _, name := range names {
+		entries = append(entries, singleQuoted(name+":"+desc))
+	}
+	return strings.Join(entries, " ")
+}
+
+// zshFlags returns the _describe entries of flags
+func zshFlags(flags []completionFlag) string {
+	var entries []string
+	for _, f := range flags {
+		entries = append(entries, zshDescribed([]string{"-" + f.Name}, f.Usage))
+	}
+	return strings.Join(entries, " ")
+}
+
+func writeZshCompletion(w io.Writer, cmds []completionCommand, globals []completionFlag) {
+	var commandEntries []string
+	for _, c := range cmds {
+		commandEntries = append(commandEntries, zshDescribed(commandNames(c), c.Short))
+	}
+
+	fmt.Fprintf(w, `#compdef gts
+# zsh completion for gts, generated by "gts completion zsh"
+_gts() {
+    local cmd i id_args=0
+    local -a path_args commands global_flags flags value_flags id_flags cmd_words ids
+    commands=(%s)
+    global_flags=(%s)
+
+    for ((i = 2; i < CURRENT; i++)); do
+        case "${words[i]/#--/-}" in
+            -path|-config) path_args+=("${words[i]}" "${words[i+1]}"); ((i++)) ;;
+            %s) ((i++)) ;;
+            -*) ;;
+            *) cmd="${words[i]}"; break ;;
+        esac
+    done
+
+    if [[ -z $cmd ]]; then
+        if [[ $PREFIX == -* ]]; then
+            _describe 'global flag' global_flags
+        else
+            _describe 'command' commands
+        fi
+        return
+    fi
+
+    case "$cmd" in
+`, strings.Join(commandEntries, " "), zshFlags(globals), strings.ReplaceAll(dashed(globals, takesValue), " ", "|"))
+
+	for _, c := range cmds {
+		idArgs := 0
+		if c.IDArgs {
+			idArgs = 1
+		}
+		fmt.Fprintf(w, "        %s) flags=(%s); value_flags=(%s); id_flags=(%s); id_args=%d; cmd_words=(%s) ;;\n",
+			strings.Join(commandNames(c), "|"), zshFlags(c.Flags), dashed(c.Flags, takesValue),
+			dashed(c.Flags, takesID), idArgs, strings.Join(c.Words, " "))
+	}
+
+	fmt.Fprint(w, `    esac
+
+    local prev="${words[CURRENT-1]/#--/-}"
+    if (( ${id_flags[(Ie)$prev]} )) || { [[ $id_args == 1 && $PREFIX != -* ]] && (( ! ${value_flags[(Ie)$prev]} )); }; then
+        ids=(${(f)"$(gts "${path_args[@]}" __complete -- "$PREFIX" 2>/dev/null)"})
+        compadd -a ids
+    elif (( ${value_flags[(Ie)$prev]} )); then
+        _files
+    elif [[ $PREFIX == -* ]]; then
+        _describe 'flag' flags
+    elif (( ${#cmd_words} )); then
+        compadd -a cmd_words
+    fi
+}
+
+compdef _gts gts
+`)
+}
+
+func writeFishCompletion(w io.Writer, cmds []completionCommand, globals []completionFlag) {
+	fmt.Fprint(w, `# fish completion for gts, generated by "gts completion fish"
+function __gts_path_args
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    while set -q tokens[1]
+        switch $tokens[1]
+            case -path --path -config --config
+                echo $tokens[1]
+                echo $tokens[2]
+                set -e tokens[1]
+            case '-*'
+            case '*'
+                return
+        end
+        set -e tokens[1]
+    end
+end
+
+function __gts_ids
+    gts (__gts_path_args) __complete -- (commandline -ct) 2>/dev/null
+end
+
+complete -c gts -f
+`)
+
+	for _, f := range globals {
+		fmt.Fprintf(w, "complete -c gts -n __fish_use_subcommand -o %s%s -d %s\n", f.Name, fishRequires(f), singleQuoted(f.Usage))
+	}
+	for _, c := range cmds {
+		for _, name := range commandNames(c) {
+			fmt.Fprintf(w, "complete -c gts -n __fish_use_subcommand -a %s -d %s\n", name, singleQuoted(c.Short))
+		}
+		seen := singleQuoted("__fish_seen_subcommand_from " + strings.Join(commandNames(c), " "))
+		for _, f := range c.Flags {
+			args := fishRequires(f)
+			if f.ID {
+				args += " -a '(__gts_ids)'"
+			}
+			fmt.Fprintf(w, "complete -c gts -n %s -o %s%s -d %s\n", seen, f.Name, args, singleQuoted(f.Usage))
+		}
+		if c.IDArgs {
+			fmt.Fprintf(w, "complete -c gts -n %s -a '(__gts_ids)'\n", seen)
+		}
+		if len(c.Words) > 0 {
+			fmt.Fprintf(w, "complete -c gts -n %s -a %s\n", seen, singleQuoted(strings.Join(c.Words, " ")))
+		}
+	}
+}
+
+// fishRequires returns the fish options of a flag taking a value
+func fishRequires(f completionFlag) string {
+	if f.Value && !f.ID {
+		return " -r -F"
+	}
+	if f.Value {
+		return " -r"
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindCommand_Aliases(t *testing.T) {
+	tests := map[string]*Command{
+		"validate":      cmdValidate,
+		"val":           cmdValidate,
+		"relationships": cmdRelationships,
+		"rel":           cmdRelationships,
+		"__complete":    cmdComplete,
+		"unknown":       nil,
+	}
+	for name, expected := range tests {
+		if got := findCommand(name); got != expected {
+			t.Errorf("findCommand(%q): expected %v, got %v", name, expected, got)
+		}
+	}
+
+	// Aliases must not shadow command names or each other
+	seen := make(map[string]string)
+	for _, cmd := range commands {
+		for _, name := range commandNames(completionCommand{Name: cmd.Name(), Aliases: cmd.Aliases}) {
+			if other, ok := seen[name]; ok {
+				t.Errorf("%q is used by both %s and %s", name, other, cmd.Name())
+			}
+			seen[name] = cmd.Name()
+		}
+	}
+}
+
+func TestComplete_FixtureStore(t *testing.T) {
+	dir := t.TempDir()
+	fixture := `[
+		{"id": "gts.x.core.events.type.v1~x.test._.one.v1"},
+		{"id": "gts.x.core.events.type.v1~x.test._.two.v1"},
+		{"id": "gts.y.other.pkg.item.v1~y.test._.three.v1"}
+	]`
+	if err := os.WriteFile(filepath.Join(dir, "entities.json"), []byte(fixture), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	defer func(previous string) { path = previous }(path)
+	path = dir
+
+	ids, err := completionCandidates("")
+	if err != nil {
+		t.Fatalf("completionCandidates failed: %v", err)
+	}
+
+	tests := []struct {
+		prefix   string
+		max      int
+		expected string
+	}{
+		{"gts.x.", 0, "gts.x.core.events.type.v1~x.test._.one.v1\ngts.x.core.events.type.v1~x.test._.two.v1\n"},
+		{"gts.", 2, "gts.x.core.events.type.v1~x.test._.one.v1\ngts.x.core.events.type.v1~x.test._.two.v1\n"},
+		{"gts.y", 10, "gts.y.other.pkg.item.v1~y.test._.three.v1\n"},
+		{"gts.z", 10, ""},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		printCompletions(&out, ids, tt.prefix, tt.max)
+		if out.String() != tt.expected {
+			t.Errorf("prefix %q max %d: expected %q, got %q", tt.prefix, tt.max, tt.expected, out.String())
+		}
+	}
+}
+
+func TestCompletionScript_ReferencesFlags(t *testing.T) {
+	global := flag.NewFlagSet("gts", flag.ContinueOnError)
+	defineGlobalFlags(global)
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var out bytes.Buffer
+		if err := writeCompletionScript(&out, shell, global); err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		script := out.String()
+		prefix := "-"
+		if shell == "fish" {
+			prefix = "-o "
+		}
+
+		for _, cmd := range commands {
+			if cmd.Hidden {
+				if strings.Contains(script, cmd.Name()+")") {
+					t.Errorf("%s: hidden command %s is completed", shell, cmd.Name())
+				}
+				continue
+			}
+			for _, name := range commandNames(completionCommand{Name: cmd.Name(), Aliases: cmd.Aliases}) {
+				if !strings.Contains(script, name) {
+					t.Errorf("%s: command %s is missing", shell, name)
+				}
+			}
+			cmd.Flag.VisitAll(func(f *flag.Flag) {
+				if !strings.Contains(script, prefix+f.Name) {
+					t.Errorf("%s: flag -%s of %s is missing", shell, f.Name, cmd.Name())
+				}
+			})
+		}
+		global.VisitAll(func(f *flag.Flag) {
+			if !strings.Contains(script, prefix+f.Name) {
+				t.Errorf("%s: global flag -%s is missing", shell, f.Name)
+			}
+		})
+	}
+
+	if err := writeCompletionScript(&bytes.Buffer{}, "powershell", global); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestCompletionCommands_IDFlags(t *testing.T) {
+	for _, c := range completionCommands() {
+		switch c.Name {
+		case "validate", "relationships":
+			if len(c.Flags) == 0 || !hasIDFlag(c.Flags, "id") {
+				t.Errorf("expected -id of %s to complete IDs, got %+v", c.Name, c.Flags)
+			}
+		case "tag":
+			if !c.IDArgs {
+				t.Error("expected the arguments of tag to complete IDs")
+			}
+		case "query":
+			if hasIDFlag(c.Flags, "expr") {
+				t.Error("expected -expr of query not to complete IDs")
+			}
+		}
+	}
+}
+
+// hasIDFlag reports whether the named flag is completed with GTS IDs
+func hasIDFlag(flags []completionFlag, name string) bool {
+	for _, f := range flags {
+		if f.Name == name {
+			return f.ID
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdConformance = &Command{
+	UsageLine: "conformance -fixtures <dir>",
+	Short:     "run conformance fixtures against this implementation",
+	Long: `
+Conformance runs every JSON fixture found in a directory against the Go
+implementation and reports mismatches with their diffs.
+
+The -fixtures flag specifies the fixtures directory.
+
+The command prints a JSON summary and exits with status 1 when any fixture fails.
+
+Example:
+
+	gts conformance -fixtures ./conformance/fixtures
+	`,
+}
+
+var (
+	conformanceFixtures string
+)
+
+func init() {
+	cmdConformance.Run = runConformance
+	cmdConformance.Flag.StringVar(&conformanceFixtures, "fixtures", "", "fixtures directory")
+}
+
+func runConformance(cmd *Command, args []string) {
+	if conformanceFixtures == "" {
+		cmd.Usage()
+	}
+
+	results, err := gts.RunConformanceSuite(os.DirFS(conformanceFixtures))
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	passed, failed, skipped := 0, 0, 0
+	failures := make([]gts.ConformanceResult, 0)
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.Passed:
+			passed++
+		default:
+			failed++
+			failures = append(failures, result)
+		}
+	}
+
+	writeJSON(map[string]any{
+		"total":    len(results),
+		"passed":   passed,
+		"failed":   failed,
+		"skipped":  skipped,
+		"failures": failures,
+	})
+
+	if failed > 0 {
+		failf("%d of %d conformance fixtures failed", failed, len(results))
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import "github.com/GlobalTypeSystem/gts-go/gts"
+
+var cmdDefaults = &Command{
+	UsageLine: "defaults <instance-id> | defaults -in <file> [-schema <schema-id>]",
+	Short:     "fill the defaults declared by the schema of an instance",
+	Long: `
+Defaults fills the missing properties of an instance that declare a default in
+its own schema, in nested objects and arrays of objects too, and prints the
+filled entity with the paths of the added properties. Unlike cast it never
+removes properties nor rewrites consts, so an instance that already holds
+every default is printed unchanged with no added path. Required properties
+without default are listed in missing_required.
+
+The argument is the GTS ID of a loaded instance.
+The -in flag names a JSON file holding the instance instead, e.g. an inbound
+API payload, whose schema is resolved from its content as on registration.
+The -schema flag takes the defaults of another schema for the -in instance.
+Requires -path to be set to load entities.
+
+Example:
+
+	gts -path ./examples defaults gts.x.orders.events.placed.v1.2~x.shop._.o1.v1
+	gts -path ./examples defaults -in payload.json -schema gts.x.orders.events.placed.v1.2~
+	`,
+}
+
+var (
+	defaultsIn     string
+	defaultsSchema string
+)
+
+func init() {
+	cmdDefaults.Run = runDefaults
+	cmdDefaults.Flag.StringVar(&defaultsIn, "in", "", "JSON file of an instance that is not loaded")
+	cmdDefaults.Flag.StringVar(&defaultsSchema, "schema", "", "schema GTS ID to take the defaults of the -in instance from")
+}
+
+func runDefaults(cmd *Command, args []string) {
+	if (defaultsIn == "") == (len(args) != 1) || (defaultsSchema != "" && defaultsIn == "") {
+		cmd.Usage()
+	}
+
+	store := newStore()
+	var result *gts.ApplyDefaultsResult
+	var err error
+	if defaultsIn != "" {
+		result, err = store.ApplyDefaultsToContent(readInstanceFile(defaultsIn), defaultsSchema)
+	} else {
+		result, err = store.ApplyDefaults(args[0])
+	}
+	if err != nil {
+		fatalError(err, "defaults failed: %v", err)
+	}
+	writeJSON(result)
+}
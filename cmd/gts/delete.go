@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+var cmdDelete = &Command{
+	UsageLine: "delete [-force] <id>",
+	Short:     "unregister an entity",
+	Long: `
+Delete unregisters an entity from the store loaded from -path. With
+-ref-validation strict an entity that other entities still reference, as their
+schema, parent type or in their content, is refused and the referencing IDs
+are listed; -ref-validation warn logs them instead.
+
+The -force flag removes the entity even if it is referenced.
+
+The store is rebuilt from -path on every run, so the files are left untouched
+and the command reports whether the entity can be removed; use
+DELETE /entities/{id} on a running server to remove it from the served store.
+
+Example:
+
+	gts -path ./examples -ref-validation strict delete gts.x.core.events.type.v1~
+	`,
+}
+
+var deleteForce bool
+
+func init() {
+	cmdDelete.Run = runDelete
+	cmdDelete.Flag.BoolVar(&deleteForce, "force", false, "remove the entity even if other entities reference it")
+}
+
+func runDelete(cmd *Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+	}
+
+	id := args[0]
+	store := newStore()
+	unregister := store.Unregister
+	if deleteForce {
+		unregister = store.ForceUnregister
+	}
+	if err := unregister(id); err != nil {
+		fatalError(err, "%v", err)
+	}
+	writeJSON(map[string]any{"ok": true, "id": id})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdGet = &Command{
+	UsageLine: "get <id|short-id>",
+	Short:     "get an entity by GTS ID or short ID",
+	Long: `
+Get prints a registered entity with its ID, short ID, schema ID, content, tags
+and timestamps. The entity is named by its GTS ID or by its short ID, the
+16-character [a-z2-7] encoding printed by 'gts uuid -short' and 'gts list'.
+Requires -path to be set to load entities; entities loaded from files are
+timestamped with the load time unless the tree was written by 'gts export',
+whose manifest keeps the timestamps of the exporting store.
+With the global -lenient-lookup flag an ID that is not found is retried
+lowercased, trimmed and without gts://; a note on stderr tells when it was.
+
+Example:
+
+	gts -path ./examples get gts.x.core.events.type.v1~
+	gts -path ./examples get hp46bkjnt7mpl5bd
+	gts -lenient-lookup -path ./examples get GTS.X.Core.Events.Type.v1~
+	`,
+}
+
+func init() {
+	cmdGet.Run = runGet
+}
+
+func runGet(cmd *Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+	}
+
+	store := newStore()
+	var entity *gts.JsonEntity
+	if gts.IsShortID(args[0]) {
+		found, err := store.FindByShortID(args[0])
+		if err != nil {
+			fatalError(err, "%v", err)
+		}
+		entity = found
+	} else {
+		found, normalizedID := store.Lookup(args[0])
+		if found == nil {
+			failf("%v", &gts.StoreGtsObjectNotFoundError{EntityID: args[0]})
+		}
+		if normalizedID != "" {
+			fmt.Fprintf(os.Stderr, "note: %q was found as %s (lenient lookup)\n", args[0], normalizedID)
+		}
+		entity = found
+	}
+
+	short, _ := gts.ShortID(entity.GtsID.ID)
+	result := map[string]any{
+		"id":            entity.GtsID.ID,
+		"short_id":      short,
+		"schema_id":     entity.SchemaID,
+		"is_schema":     entity.IsSchema,
+		"content":       entity.Content,
+		"registered_at": entity.RegisteredAt,
+		"updated_at":    entity.UpdatedAt,
+	}
+	if tags := store.GetTags(entity.GtsID.ID); tags != nil {
+		result["tags"] = tags
+	}
+	writeJSON(result)
+}
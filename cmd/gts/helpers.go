@@ -11,34 +11,132 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/GlobalTypeSystem/gts-go/gts"
 )
 
-// newStore creates a new GTS store with optional file reader
+// stdinPath is the path that stands for stdin in -path and in file arguments
+const stdinPath = "-"
+
+// recordPositions makes newStore record the file positions of loaded entities, for commands
+// that report findings as file:line:column
+var recordPositions bool
+
+// newStore creates a new GTS store with optional file reader. A "-" path reads entities from stdin
+// (a JSON object, array or NDJSON) in addition to the other paths.
 func newStore() *gts.GtsStore {
-	var reader gts.GtsReader
+	var readers []gts.GtsReader
+	var stdin *gts.GtsStreamReader
 
+	cfg := readerConfig()
 	if path != "" {
 		paths := parsePaths(path)
-		var cfg *gts.GtsConfig
-		if cfgPath != "" {
-			cfg = loadConfig(cfgPath)
+		if i := slices.Index(paths, stdinPath); i >= 0 {
+			paths = slices.Delete(paths, i, i+1)
+			stdin = gts.NewGtsStreamReader(os.Stdin, cfg)
+			readers = append(readers, stdin)
+		}
+		if len(paths) > 0 {
+			readers = append([]gts.GtsReader{gts.NewGtsFileReader(paths, cfg)}, readers...)
 		}
-		reader = gts.NewGtsFileReader(paths, cfg)
 		if verbose > 0 {
-			log.Printf("loaded entities from: %s", strings.Join(paths, ", "))
+			log.Printf("loaded entities from: %s", strings.Join(parsePaths(path), ", "))
 		}
 	}
 
-	store := gts.NewGtsStore(reader)
+	mode, err := gts.ParseRefValidationMode(refValidation)
+	if err != nil {
+		usagef("%v", err)
+	}
+	dependentsMode, err := gts.ParseDependentValidationMode(revalidateDependents)
+	if err != nil {
+		usagef("%v", err)
+	}
+	missPolicy, err := gts.ParseReaderMissPolicy(readerMissPolicy)
+	if err != nil {
+		usagef("%v", err)
+	}
+
+	config := &gts.RegistryConfig{
+		RefValidation:                      mode,
+		StrictSchemaKeywords:               strictKeywords,
+		StableOrder:                        stableOrder,
+		RevalidateDependentsOnSchemaChange: dependentsMode,
+		RejectSchemaIDConflicts:            rejectSchemaIDConflicts,
+		EnforceIDTypeConsistency:           enforceIDTypeConsistency,
+		ReaderMissPolicy:                   missPolicy,
+		LenientLookup:                      lenientLookup,
+		GtsConfig:                          cfg,
+	}
+	var store *gts.GtsStore
+	if len(readers) > 1 {
+		// Files and stdin are loaded together, so references resolve across them
+		store = gts.NewGtsStoreFromReaders(readers, config, len(readers))
+	} else {
+		var reader gts.GtsReader
+		if len(readers) == 1 {
+			reader = readers[0]
+		}
+		store = gts.NewGtsStoreWithConfig(reader, config)
+	}
+	if stdin != nil && stdin.Err() != nil {
+		fatalf("%v", stdin.Err())
+	}
+	if strictLoad {
+		if errs := store.LoadErrors(); len(errs) > 0 {
+			for _, e := range errs {
+				fmt.Fprintf(os.Stderr, "gts: %v\n", e)
+			}
+			fatalf("%v", &gts.StoreLoadError{Errors: errs})
+		}
+	}
 	if verbose > 0 && path != "" {
 		log.Printf("entity count: %d", store.Count())
 	}
 	return store
 }
 
+// warnLoadErrors prints a summary of the files and objects skipped while loading the store to
+// stderr, with one line per error at verbosity 1 or more
+func warnLoadErrors(store *gts.GtsStore) {
+	errs := store.LoadErrors()
+	if len(errs) == 0 {
+		return
+	}
+	counts := map[string]int{}
+	for _, e := range errs {
+		counts[e.Category]++
+		if verbose > 0 {
+			fmt.Fprintf(os.Stderr, "gts: warning: %v\n", e)
+		}
+	}
+	var parts []string
+	for _, category := range []string{gts.ReaderErrorInvalidJSON, gts.ReaderErrorNoGtsID, gts.ReaderErrorInvalidGtsID, gts.ReaderErrorDuplicateID} {
+		if counts[category] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[category], category))
+		}
+	}
+	hint := ""
+	if verbose == 0 {
+		hint = " (-v lists them)"
+	}
+	fmt.Fprintf(os.Stderr, "gts: warning: %d load errors: %s%s\n", len(errs), strings.Join(parts, ", "), hint)
+}
+
+// readerConfig returns the configuration entities are read with: the -config file, if any, with
+// -record-positions
+func readerConfig() *gts.GtsConfig {
+	cfg := gts.DefaultGtsConfig()
+	if gtsConfig != nil {
+		loaded := *gtsConfig
+		cfg = &loaded
+	}
+	cfg.RecordPositions = cfg.RecordPositions || recordPositions
+	return cfg
+}
+
 // parsePaths splits a comma-separated path specification into individual paths
 func parsePaths(pathSpec string) []string {
 	parts := strings.Split(pathSpec, ",")
@@ -59,41 +157,6 @@ func parsePaths(pathSpec string) []string {
 	return paths
 }
 
-// loadConfig loads a GTS config from a file
-func loadConfig(path string) *gts.GtsConfig {
-	f, err := os.Open(path)
-	if err != nil {
-		log.Printf("warning: could not open config file: %v", err)
-		return gts.DefaultGtsConfig()
-	}
-	defer f.Close()
-
-	var data struct {
-		EntityIDFields []string `json:"entity_id_fields"`
-		SchemaIDFields []string `json:"schema_id_fields"`
-	}
-
-	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		log.Printf("warning: could not parse config file: %v", err)
-		return gts.DefaultGtsConfig()
-	}
-
-	return &gts.GtsConfig{
-		EntityIDFields: data.EntityIDFields,
-		SchemaIDFields: data.SchemaIDFields,
-	}
-}
-
-// writeJSON writes a value as JSON to stdout
-func writeJSON(v any) {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(v); err != nil {
-		fatalf("json encoding failed: %v", err)
-	}
-}
-
 // writeJSONFile writes a value as JSON to a file
 func writeJSONFile(path string, v any) error {
 	f, err := os.Create(path)
@@ -107,9 +170,3 @@ func writeJSONFile(path string, v any) error {
 	enc.SetEscapeHTML(false)
 	return enc.Encode(v)
 }
-
-// fatalf prints an error message and exits with status 1
-func fatalf(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "gts: "+format+"\n", args...)
-	os.Exit(1)
-}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdInfer = &Command{
+	UsageLine: "infer -type <type-id> [-closed] [-strict] [-out file] file|glob ...",
+	Short:     "infer a schema from example instances",
+	Long: `
+Infer generates a draft-07 schema from example instances, e.g. a set of event
+payloads collected before the type has a schema.
+
+The -type flag specifies the type (schema) ID of the schema, ending with '~'.
+Files or glob patterns of examples are given as arguments; each file holds a
+JSON object or an array of objects. Properties get the type of their values,
+integers widening to number, and strings that are all RFC 3339 timestamps or
+UUIDs get the date-time or uuid format. Properties present in every example
+are required; nested objects and array items are inferred the same way.
+The -closed flag sets additionalProperties to false on every object.
+Values of different types at the same location give a union type such as
+["number", "string"]; the -strict flag makes them an error instead.
+The -out flag writes the schema to a file (default: stdout).
+The schema is registered in an empty store and checked with the schema
+validation before it is written.
+
+Example:
+
+	gts infer -type gts.x.core.events.type.v1~ './samples/*.json'
+	gts infer -type gts.x.core.events.type.v1~ -closed -strict -out schemas/event.json samples.json
+	`,
+}
+
+var (
+	inferType   string
+	inferClosed bool
+	inferStrict bool
+	inferOut    string
+)
+
+func init() {
+	cmdInfer.Run = runInfer
+	cmdInfer.Flag.StringVar(&inferType, "type", "", "type ID of the inferred schema")
+	cmdInfer.Flag.BoolVar(&inferClosed, "closed", false, "set additionalProperties to false on every object")
+	cmdInfer.Flag.BoolVar(&inferStrict, "strict", false, "fail on values of different types instead of inferring a union type")
+	cmdInfer.Flag.StringVar(&inferOut, "out", "", "file to write the schema to (default: stdout)")
+}
+
+func runInfer(cmd *Command, args []string) {
+	if inferType == "" || len(args) == 0 {
+		cmd.Usage()
+	}
+
+	var instances []map[string]any
+	for _, pattern := range args {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			usagef("invalid file pattern %q: %v", pattern, err)
+		}
+		if len(files) == 0 {
+			usagef("no files match %q", pattern)
+		}
+		for _, file := range files {
+			instances = append(instances, readExamples(file)...)
+		}
+	}
+
+	schema, err := gts.InferSchema(instances, inferType, &gts.InferConfig{ClosedObjects: inferClosed, Strict: inferStrict})
+	if err != nil {
+		fatalError(err, "%v", err)
+	}
+
+	store := gts.NewGtsStore(nil)
+	entity := gts.NewJsonEntity(schema, gts.DefaultGtsConfig())
+	if err := store.Register(entity); err != nil {
+		fatalError(err, "inferred schema was refused: %v", err)
+	}
+	if err := store.ValidateSchema(entity.GtsID.ID); err != nil {
+		failf("inferred schema does not validate: %v", err)
+	}
+
+	if inferOut == "" {
+		writeJSON(schema)
+		return
+	}
+	if err := writeJSONFile(inferOut, schema); err != nil {
+		fatalf("failed to write schema: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s to %s\n", inferType, inferOut)
+}
+
+// readExamples reads the example objects of a file holding an object or an array of objects
+func readExamples(file string) []map[string]any {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fatalf("failed to read %s: %v", file, err)
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		failf("failed to parse %s: %v", file, err)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []any:
+		examples := make([]map[string]any, 0, len(v))
+		for i, item := range v {
+			example, ok := item.(map[string]any)
+			if !ok {
+				failf("%s: item %d is not a JSON object", file, i)
+			}
+			examples = append(examples, example)
+		}
+		return examples
+	default:
+		failf("%s must hold a JSON object or an array of objects", file)
+		return nil
+	}
+}
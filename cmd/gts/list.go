@@ -5,32 +5,81 @@ Released under Apache License 2.0
 
 package main
 
+import "github.com/GlobalTypeSystem/gts-go/gts"
+
 var cmdList = &Command{
-	UsageLine: "list [-limit n]",
+	UsageLine: "list [-limit n] [-cursor c] [-schemas | -instances] [-orphans] [-pattern <pattern>] | list -types [-pattern <pattern>]",
 	Short:     "list all entities",
 	Long: `
-List displays all entities in the store.
+List displays all entities in the store, in GTS ID order.
 
 The -limit flag limits the number of results (default: 100).
-Requires -path to be set to load entities.
+The -cursor flag continues after a previous page: pass its next_cursor, which
+is only present when more entities follow.
+The -schemas and -instances flags list schemas or instances only.
+The -pattern flag lists the IDs matching a GTS ID or wildcard, as query does,
+e.g. "gts.x.commerce.*".
+The -orphans flag lists only the instances whose schema is not loaded.
+The total of the output counts the listed kind of entities before the limit.
+The -types flag lists type lines instead, each with its sorted versions, the
+latest version ID, schema and instance counts per version and derived types.
+With -types the -pattern flag restricts the type IDs.
+Requires -path to be set to load entities. Files and objects skipped while
+loading, and IDs found in several files, are summarized on stderr; the global
+-strict flag makes them fail the command instead.
 
 Example:
 
 	gts -path ./examples list -limit 50
+	gts -path ./examples list -limit 50 -cursor gts.x.core.events.type.v1~
+	gts -path ./examples list -schemas -pattern "gts.x.commerce.*"
+	gts -path ./examples list -orphans
+	gts -path ./examples list -types -pattern "gts.x.core.*"
 	`,
 }
 
 var (
-	listLimit int
+	listLimit     int
+	listCursor    string
+	listTypes     bool
+	listPattern   string
+	listSchemas   bool
+	listInstances bool
+	listOrphans   bool
 )
 
 func init() {
 	cmdList.Run = runList
 	cmdList.Flag.IntVar(&listLimit, "limit", 100, "maximum number of results")
+	cmdList.Flag.StringVar(&listCursor, "cursor", "", "next_cursor of the previous page")
+	cmdList.Flag.BoolVar(&listTypes, "types", false, "list type lines with versions and instance counts")
+	cmdList.Flag.StringVar(&listPattern, "pattern", "", "GTS ID or wildcard pattern the listed IDs match")
+	cmdList.Flag.BoolVar(&listSchemas, "schemas", false, "list schemas only")
+	cmdList.Flag.BoolVar(&listInstances, "instances", false, "list instances only")
+	cmdList.Flag.BoolVar(&listOrphans, "orphans", false, "list only the instances whose schema is not loaded")
 }
 
 func runList(cmd *Command, args []string) {
+	if listSchemas && listInstances {
+		usagef("-schemas cannot be combined with -instances")
+	}
 	store := newStore()
-	result := store.List(listLimit)
+	warnLoadErrors(store)
+	if listTypes {
+		writeJSON(store.TypeSummaries(listPattern))
+		return
+	}
+
+	opts := gts.ListOptions{Limit: listLimit, Cursor: listCursor, Pattern: listPattern, Orphans: listOrphans}
+	switch {
+	case listSchemas:
+		opts.Kind = gts.ListSchemas
+	case listInstances:
+		opts.Kind = gts.ListInstances
+	}
+	result, err := store.ListFiltered(opts)
+	if err != nil {
+		fatalError(err, "list failed: %v", err)
+	}
 	writeJSON(result)
 }
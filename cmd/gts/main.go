@@ -11,7 +11,10 @@ import (
 	"io"
 	"log"
 	"os"
+	"slices"
 	"strings"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
 )
 
 const usageText = `GTS is a tool for working with Global Type System identifiers and schemas.
@@ -26,19 +29,41 @@ The commands are:
 	parse-id        parse a GTS ID into its components
 	match-id-pattern match a GTS ID against a pattern
 	uuid            generate UUID from a GTS ID
-	validate        validate an instance against its schema
-	relationships   resolve relationships for an entity
+	validate        validate an instance against its schema (alias: val)
+	relationships   resolve relationships for an entity (alias: rel)
 	compatibility   check compatibility between two schemas
 	cast            cast an instance to a target schema
+	defaults        fill the defaults declared by the schema of an instance
 	query           query entities using an expression
 	attr            get attribute value from a GTS entity
 	list            list all entities
+	stats           summarize the loaded entities and their integrity
+	get             get an entity by GTS ID or short ID
+	tag             tag an entity with operational metadata
+	delete          unregister an entity
+	export          export entities as a directory tree
+	import          import a bundle of entities
+	register        register entity files into the loaded entities
+	prune           remove superseded schema versions and stale instances
+	bundle          build an offline schema validator bundle
+	allocate-id     allocate the next free instance ID under a type
+	new-type        scaffold a new type derived from a base schema
+	infer           infer a schema from example instances
+	ce              convert between GTS instances and CloudEvents
+	conformance     run conformance fixtures against this implementation
 	server          start the GTS HTTP server
 	openapi         generate OpenAPI specification
+	completion      generate a shell completion script
 	version         print GTS version
 
 Use "gts <command> -h" for more information about a command.
 
+Every command takes a -json flag that makes it print exactly one JSON
+document: its result or, when it fails before writing it, an object with
+"error" and "exit_code". The exit status is 0 on success, 1 on a logical
+failure (an invalid ID, failed validation, incompatible schemas, an entity
+that is not found), 2 on a usage error and 3 on an I/O or store error.
+
 Additional help topics:
 
 Use "gts help <topic>" for more information about that topic.
@@ -51,6 +76,10 @@ type Command struct {
 	Short     string
 	Long      string
 	Flag      flag.FlagSet
+	// Aliases are alternative names the command can be invoked by
+	Aliases []string
+	// Hidden commands are left out of usage and completion scripts
+	Hidden bool
 }
 
 // Name returns the command's name: the first word in the usage line.
@@ -63,11 +92,14 @@ func (c *Command) Name() string {
 	return name
 }
 
-// Usage prints the command usage and exits.
+// Usage prints the command usage and exits with exitUsage.
 func (c *Command) Usage() {
 	fmt.Fprintf(os.Stderr, "usage: %s\n", c.UsageLine)
 	fmt.Fprintf(os.Stderr, "%s\n", strings.TrimSpace(c.Long))
-	os.Exit(2)
+	if jsonOutput {
+		writeJSON(errorResult{Error: "usage: gts " + c.UsageLine, ExitCode: exitUsage})
+	}
+	os.Exit(exitUsage)
 }
 
 // Runnable reports whether the command can be run; otherwise
@@ -86,19 +118,61 @@ var commands = []*Command{
 	cmdRelationships,
 	cmdCompatibility,
 	cmdCast,
+	cmdDefaults,
 	cmdQuery,
 	cmdAttr,
 	cmdList,
+	cmdStats,
+	cmdGet,
+	cmdTag,
+	cmdDelete,
+	cmdExport,
+	cmdImport,
+	cmdRegister,
+	cmdPrune,
+	cmdBundle,
+	cmdAllocateID,
+	cmdNewType,
+	cmdInfer,
+	cmdCE,
+	cmdConformance,
 	cmdServer,
 	cmdOpenAPI,
+	cmdCompletion,
+	cmdComplete,
 	cmdVersion,
 }
 
+func init() {
+	for _, cmd := range commands {
+		cmd.Flag.BoolVar(&jsonOutput, "json", false, "print exactly one JSON document: the result, or the error of a failure")
+	}
+}
+
+// findCommand returns the runnable command with the given name or alias, or nil
+func findCommand(name string) *Command {
+	for _, cmd := range commands {
+		if !cmd.Runnable() {
+			continue
+		}
+		if cmd.Name() == name || slices.Contains(cmd.Aliases, name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
 // Global flags
 var (
 	verbose int
 	cfgPath string
-	path    string
+	// gtsConfig is the configuration loaded from cfgPath, nil without -config (see readerConfig)
+	gtsConfig     *gts.GtsConfig
+	path          string
+	refValidation string
+	stableOrder   bool
+	lenientLookup bool
+	strictLoad    bool
 )
 
 func init() {
@@ -114,11 +188,20 @@ func init() {
 	}
 }
 
+// defineGlobalFlags defines the flags given before the command name
+func defineGlobalFlags(fs *flag.FlagSet) {
+	fs.IntVar(&verbose, "v", verbose, "enable verbose logging")
+	fs.StringVar(&path, "path", path, "path to JSON and schema files or directories, - for stdin")
+	fs.StringVar(&cfgPath, "config", cfgPath, "path to GTS config JSON file")
+	fs.StringVar(&refValidation, "ref-validation", "off", "GTS reference validation on registration: off, warn or strict")
+	fs.BoolVar(&stableOrder, "stable", false, "list and query entities in ID order for reproducible output")
+	fs.BoolVar(&lenientLookup, "lenient-lookup", false, "retry missed IDs and query patterns lowercased, trimmed and without gts://")
+	fs.BoolVar(&strictLoad, "strict", false, "fail when -path files are invalid JSON, hold objects without a valid GTS ID or repeat IDs")
+}
+
 func main() {
 	flag.Usage = usage
-	flag.IntVar(&verbose, "v", verbose, "enable verbose logging")
-	flag.StringVar(&path, "path", path, "path to JSON and schema files or directories")
-	flag.StringVar(&cfgPath, "config", cfgPath, "path to GTS config JSON file")
+	defineGlobalFlags(flag.CommandLine)
 
 	log.SetPrefix("gts: ")
 	log.SetFlags(0)
@@ -134,24 +217,29 @@ func main() {
 		log.SetOutput(io.Discard)
 	}
 
-	cmdName := args[0]
-	for _, cmd := range commands {
-		if cmd.Name() == cmdName {
-			if !cmd.Runnable() {
-				continue
-			}
-			cmd.Flag.Usage = func() { cmd.Usage() }
-			cmd.Flag.Parse(args[1:])
-			cmd.Run(cmd, cmd.Flag.Args())
-			return
+	// ID limits from the config apply to every command, including the server
+	if cfgPath != "" {
+		cfg, err := gts.LoadGtsConfig(cfgPath)
+		if err != nil {
+			fatalf("%v", err)
 		}
+		gtsConfig = cfg
+		gts.SetLimits(cfg.MaxIDLength, cfg.MaxSegments)
+	}
+
+	cmdName := args[0]
+	if cmd := findCommand(cmdName); cmd != nil {
+		cmd.Flag.Usage = func() { cmd.Usage() }
+		cmd.Flag.Parse(args[1:])
+		cmd.Run(cmd, cmd.Flag.Args())
+		return
 	}
 
 	fmt.Fprintf(os.Stderr, "gts: unknown command %q\nRun 'gts help' for usage.\n", cmdName)
-	os.Exit(2)
+	os.Exit(exitUsage)
 }
 
 func usage() {
 	fmt.Fprint(os.Stderr, usageText)
-	os.Exit(2)
+	os.Exit(exitUsage)
 }
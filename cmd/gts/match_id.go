@@ -41,5 +41,5 @@ func runMatchIDPattern(cmd *Command, args []string) {
 	}
 
 	result := gts.MatchIDPattern(matchCandidate, matchPattern)
-	writeJSON(result)
+	emitResult(result, result.Match)
 }
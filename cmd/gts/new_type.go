@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdNewType = &Command{
+	UsageLine: "new-type -base <type-id> -vendor <v> -package <p> -namespace <ns> -name <n> -version <ver> [-title text] [-payload-from file] [-out file]",
+	Short:     "scaffold a new type derived from a base schema",
+	Long: `
+New-type generates the schema of a new type derived from a base schema: its
+$id chains the new segment to the base ID, it extends the base through an allOf
+$ref, its type property is a const of its own ID and its payload property is
+an object. The $schema draft is copied from the base.
+
+The -base flag specifies the type (schema) ID to derive from.
+The -vendor, -package, -namespace and -name flags specify the segment tokens.
+The -version flag specifies the major or major.minor version (default 1.0).
+The -title flag sets the title of the schema.
+The -payload-from flag names a JSON file with an example payload; the payload
+properties are inferred from it, every property present being required.
+The -out flag writes the schema to a file, e.g. into a -path directory so that
+it is loaded with the other schemas (default: stdout).
+The generated schema is registered in the store loaded from -path and checked
+with the schema validation before it is written.
+
+Example:
+
+	gts -path ./examples new-type -base gts.x.core.events.type.v1~ -vendor acme -package commerce -namespace orders -name order_cancelled -version 1.0 -payload-from example.json
+	`,
+}
+
+var (
+	newTypeBase        string
+	newTypeVendor      string
+	newTypePackage     string
+	newTypeNamespace   string
+	newTypeName        string
+	newTypeVersion     string
+	newTypeTitle       string
+	newTypePayloadFrom string
+	newTypeOut         string
+)
+
+func init() {
+	cmdNewType.Run = runNewType
+	cmdNewType.Flag.StringVar(&newTypeBase, "base", "", "type ID to derive from")
+	cmdNewType.Flag.StringVar(&newTypeVendor, "vendor", "", "vendor token of the new segment")
+	cmdNewType.Flag.StringVar(&newTypePackage, "package", "", "package token of the new segment")
+	cmdNewType.Flag.StringVar(&newTypeNamespace, "namespace", "", "namespace token of the new segment")
+	cmdNewType.Flag.StringVar(&newTypeName, "name", "", "type token of the new segment")
+	cmdNewType.Flag.StringVar(&newTypeVersion, "version", "1.0", "version of the new segment")
+	cmdNewType.Flag.StringVar(&newTypeTitle, "title", "", "title of the schema")
+	cmdNewType.Flag.StringVar(&newTypePayloadFrom, "payload-from", "", "JSON file with an example payload")
+	cmdNewType.Flag.StringVar(&newTypeOut, "out", "", "file to write the schema to (default: stdout)")
+}
+
+func runNewType(cmd *Command, args []string) {
+	if newTypeBase == "" || newTypeVendor == "" || newTypePackage == "" || newTypeNamespace == "" || newTypeName == "" {
+		cmd.Usage()
+	}
+
+	spec := gts.DerivedTypeSpec{
+		Vendor:    newTypeVendor,
+		Package:   newTypePackage,
+		Namespace: newTypeNamespace,
+		Name:      newTypeName,
+		Version:   newTypeVersion,
+		Title:     newTypeTitle,
+	}
+	if newTypePayloadFrom != "" {
+		data, err := os.ReadFile(newTypePayloadFrom)
+		if err != nil {
+			fatalf("failed to read example payload: %v", err)
+		}
+		if err := json.Unmarshal(data, &spec.PayloadExample); err != nil || spec.PayloadExample == nil {
+			failf("example payload %s must be a JSON object", newTypePayloadFrom)
+		}
+	}
+
+	store := newStore()
+	entity, err := gts.ScaffoldDerivedType(store, newTypeBase, spec)
+	if err != nil {
+		fatalError(err, "%v", err)
+	}
+	if err := store.Register(entity); err != nil {
+		fatalError(err, "generated schema was refused: %v", err)
+	}
+	if err := store.ValidateSchema(entity.GtsID.ID); err != nil {
+		failf("generated schema does not validate: %v", err)
+	}
+
+	if newTypeOut == "" {
+		writeJSON(entity.Content)
+		return
+	}
+	if err := writeJSONFile(newTypeOut, entity.Content); err != nil {
+		fatalf("failed to write schema: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s to %s\n", entity.GtsID.ID, newTypeOut)
+}
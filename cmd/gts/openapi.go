@@ -6,29 +6,54 @@ Released under Apache License 2.0
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
 	"github.com/GlobalTypeSystem/gts-go/server"
 )
 
 var cmdOpenAPI = &Command{
-	UsageLine: "openapi -out <file> [-host address] [-port number]",
+	UsageLine: "openapi -out <file|dir> [-host address] [-port number] [-pattern <pattern>] [-split] [-schemas] [-format json|yaml]",
 	Short:     "generate OpenAPI specification",
 	Long: `
-OpenAPI generates an OpenAPI specification file for the GTS server.
+OpenAPI generates an OpenAPI specification file for the GTS server, with the
+schemas loaded from -path as components named after their GTS ID ('~' written
+as '-'), in ID order so that repeated runs produce identical files.
 
-The -out flag specifies the output file path.
+The -out flag specifies the output file path, or directory with -split.
 The -host flag specifies the server host (default: 127.0.0.1).
 The -port flag specifies the server port (default: 8000).
+The -pattern flag limits the components to the schemas matching a GTS ID
+pattern, e.g. gts.x.commerce.*; $refs to other schemas are left as they are.
+The -split flag writes one components file per vendor.package of the schemas
+plus a root openapi.json that $refs them, for reviewable diffs.
+The -schemas flag writes only the schemas, as the components.schemas of an
+OpenAPI 3.1 document without the server API: components are named after their
+GTS ID with '.' and '~' written as '_', the allOf chains of derived types are
+flattened into each component, and x-gts-ref is kept as a vendor extension.
+The -format flag selects json (default) or yaml output; yaml requires -schemas.
+With -v a progress line is shown while the components are generated. An
+interrupt (Ctrl-C) stops the generation; files are only written once it has
+completed, each replaced at once.
 
 Example:
 
 	gts openapi -out openapi.json
+	gts -v -path ./examples openapi -pattern 'gts.x.commerce.*' -split -out ./openapi
+	gts -path ./schemas openapi -schemas -format yaml -out schemas.yaml
 	`,
 }
 
 var (
-	openAPIOut  string
-	openAPIHost string
-	openAPIPort int
+	openAPIOut     string
+	openAPIHost    string
+	openAPIPort    int
+	openAPIPattern string
+	openAPISplit   bool
+	openAPISchemas bool
+	openAPIFormat  string
 )
 
 func init() {
@@ -36,18 +61,58 @@ func init() {
 	cmdOpenAPI.Flag.StringVar(&openAPIOut, "out", "", "output file path")
 	cmdOpenAPI.Flag.StringVar(&openAPIHost, "host", "127.0.0.1", "server host")
 	cmdOpenAPI.Flag.IntVar(&openAPIPort, "port", 8000, "server port")
+	cmdOpenAPI.Flag.StringVar(&openAPIPattern, "pattern", "", "GTS ID pattern selecting the schemas that become components")
+	cmdOpenAPI.Flag.BoolVar(&openAPISplit, "split", false, "write one file per package and a root document to the -out directory")
+	cmdOpenAPI.Flag.BoolVar(&openAPISchemas, "schemas", false, "write the registered schemas as OpenAPI 3.1 components only")
+	cmdOpenAPI.Flag.StringVar(&openAPIFormat, "format", "json", "output format: json or yaml")
 }
 
 func runOpenAPI(cmd *Command, args []string) {
 	if openAPIOut == "" {
 		cmd.Usage()
 	}
+	switch {
+	case openAPIFormat != "json" && openAPIFormat != "yaml":
+		usagef("unsupported format %q (expected json or yaml)", openAPIFormat)
+	case openAPIFormat == "yaml" && !openAPISchemas:
+		usagef("-format yaml requires -schemas")
+	case openAPISchemas && openAPISplit:
+		usagef("-schemas and -split cannot be combined")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	store := newStore()
 	srv := server.NewServer(store, openAPIHost, openAPIPort, verbose)
-	spec := srv.GetOpenAPISpec()
+	opts := server.OpenAPIOptions{Pattern: openAPIPattern}
+	if verbose > 0 {
+		opts.Progress = func(done, total int, currentID string) {
+			fmt.Fprintf(os.Stderr, "\rgenerating components %d/%d %s\033[K", done, total, currentID)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
 
-	if err := writeJSONFile(openAPIOut, spec); err != nil {
+	var err error
+	if openAPISchemas {
+		var spec map[string]any
+		if spec, err = srv.GenerateSchemaComponents(ctx, opts); err == nil {
+			err = server.WriteOpenAPIFile(openAPIOut, spec, openAPIFormat)
+		}
+	} else if openAPISplit {
+		var split *server.OpenAPISplit
+		if split, err = srv.GenerateOpenAPISplit(ctx, opts); err == nil {
+			err = split.WriteDir(openAPIOut)
+		}
+	} else {
+		var spec map[string]any
+		if spec, err = srv.GenerateOpenAPISpec(ctx, opts); err == nil {
+			err = server.WriteOpenAPIFile(openAPIOut, spec, openAPIFormat)
+		}
+	}
+	if err != nil {
 		fatalf("failed to write OpenAPI spec: %v", err)
 	}
 
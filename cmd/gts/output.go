@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+// Exit codes of the gts commands besides 0 for success
+const (
+	// exitFailure is a logical failure: an invalid ID, a failed validation, an incompatible
+	// schema, an entity that is not found
+	exitFailure = 1
+	// exitUsage is a usage error: missing, conflicting or invalid flags and arguments
+	exitUsage = 2
+	// exitError is an I/O or store error: unreadable files, failed writes, a frozen store
+	exitError = 3
+)
+
+// jsonOutput is set by the -json flag every command has: the command writes exactly one JSON
+// document to stdout, its result or, when it fails before writing it, an errorResult
+var jsonOutput bool
+
+// resultWritten is set once writeJSON wrote the result of the command
+var resultWritten bool
+
+// errorResult is the JSON document of a command that failed with -json before writing its result
+type errorResult struct {
+	OK       bool   `json:"ok"`
+	Error    string `json:"error"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// writeJSON writes a value as JSON to stdout
+func writeJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		fatalf("json encoding failed: %v", err)
+	}
+	resultWritten = true
+}
+
+// emitResult writes the result of a command as JSON and exits with exitFailure unless ok, so
+// that scripts can branch on the exit code without parsing the result
+func emitResult(v any, ok bool) {
+	writeJSON(v)
+	if !ok {
+		os.Exit(exitFailure)
+	}
+}
+
+// exitf prints an error message to stderr and exits with code; with -json the message is also
+// written to stdout as an errorResult, unless the command already wrote its result
+func exitf(code int, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Fprintf(os.Stderr, "gts: %s\n", message)
+	if jsonOutput && !resultWritten {
+		writeJSON(errorResult{Error: message, ExitCode: code})
+	}
+	os.Exit(code)
+}
+
+// fatalf prints an I/O or store error and exits with exitError
+func fatalf(format string, args ...any) {
+	exitf(exitError, format, args...)
+}
+
+// failf prints a logical failure and exits with exitFailure
+func failf(format string, args ...any) {
+	exitf(exitFailure, format, args...)
+}
+
+// usagef prints a usage error and exits with exitUsage
+func usagef(format string, args ...any) {
+	exitf(exitUsage, format, args...)
+}
+
+// fatalError prints an error returned by the gts package and exits with its exit code (see
+// errorExitCode)
+func fatalError(err error, format string, args ...any) {
+	exitf(errorExitCode(err), format, args...)
+}
+
+// errorExitCode returns exitError for I/O and store errors, and exitFailure for the other errors
+// of the gts package, which reject IDs or content or report entities that are not found
+func errorExitCode(err error) int {
+	var (
+		pathErr        *fs.PathError
+		frozenErr      *gts.StoreFrozenError
+		persistenceErr *gts.StorePersistenceError
+		loadErr        *gts.StoreLoadError
+		configErr      *gts.ConfigFileError
+	)
+	switch {
+	case errors.Is(err, gts.ErrInternal), errors.As(err, &pathErr), errors.As(err, &frozenErr),
+		errors.As(err, &persistenceErr), errors.As(err, &loadErr), errors.As(err, &configErr):
+		return exitError
+	default:
+		return exitFailure
+	}
+}
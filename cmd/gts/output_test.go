@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// TestMain runs the gts command instead of the tests when GTS_TEST_RUN_MAIN is set, so that
+// runGts can check the output and exit code of a whole invocation
+func TestMain(m *testing.M) {
+	if os.Getenv("GTS_TEST_RUN_MAIN") == "1" {
+		os.Args = append([]string{"gts"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runGts runs gts with args and returns its standard output and exit code
+func runGts(t *testing.T, args ...string) ([]byte, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "GTS_TEST_RUN_MAIN=1", "GTS_PATH=", "GTS_CONFIG=", "GTS_VERBOSE=")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return stdout.Bytes(), 0
+	case errors.As(err, &exitErr):
+		return stdout.Bytes(), exitErr.ExitCode()
+	}
+	t.Fatalf("Failed to run gts %v: %v", args, err)
+	return nil, 0
+}
+
+// checkGolden compares got with testdata/golden/<name>, rewriting the file with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run with -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Output differs from %s:\n%s", path, got)
+	}
+}
+
+func TestJSONOutput_Golden(t *testing.T) {
+	const entities = "testdata/entities"
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"validate_id_valid.json", []string{"validate-id", "-json", "-id", "gts.x.shop.orders.order.v1.0~"}, 0},
+		{"validate_id_invalid.json", []string{"validate-id", "-json", "-id", "gts.X.bad"}, exitFailure},
+		{"validate_id_usage.json", []string{"validate-id", "-json"}, exitUsage},
+		{"parse_id.json", []string{"parse-id", "-json", "-id", "gts.x.shop.orders.order.v1.0~x.shop._.o1.v1"}, 0},
+		{"match_id_pattern_no_match.json", []string{"match-id-pattern", "-json", "-pattern", "gts.y.*", "-candidate", "gts.x.shop.orders.order.v1.0~"}, exitFailure},
+		{"uuid.json", []string{"uuid", "-json", "-id", "gts.x.shop.orders.order.v1~"}, 0},
+		{"compatibility_backward.json", []string{"-path", entities, "compatibility", "-json",
+			"-old", "gts.x.shop.orders.order.v1.0~", "-new", "gts.x.shop.orders.order.v1.1~"}, 0},
+		{"compatibility_incompatible.json", []string{"-path", entities, "compatibility", "-json",
+			"-old", "gts.x.shop.orders.order.v1.1~", "-new", "gts.x.shop.orders.order.v1.2~"}, exitFailure},
+		{"compatibility_diff.json", []string{"-path", entities, "compatibility", "-json", "-diff",
+			"-old", "gts.x.shop.orders.order.v1.0~", "-new", "gts.x.shop.orders.order.v1.2~"}, exitFailure},
+		{"get_not_found.json", []string{"-path", entities, "get", "-json", "gts.x.shop.orders.order.v1.0~x.shop._.nope.v1"}, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runGts(t, tt.args...)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+			checkGolden(t, tt.name, out)
+		})
+	}
+}
+
+func TestJSONOutput_ExitCodes(t *testing.T) {
+	broken := t.TempDir()
+	if err := os.WriteFile(filepath.Join(broken, "broken.json"), []byte(`{"id": `), 0o644); err != nil {
+		t.Fatalf("Failed to write the broken file: %v", err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"valid instance", []string{"-path", "testdata/entities", "validate", "-json", "-id", "gts.x.shop.orders.order.v1.0~x.shop._.o1.v1"}, 0},
+		{"invalid instance", []string{"-path", "testdata/entities", "validate", "-json", "-id", "gts.x.shop.orders.order.v1.0~x.shop._.o2.v1"}, exitFailure},
+		{"strict load error", []string{"-path", broken, "-strict", "list", "-json"}, exitError},
+		{"unknown flag", []string{"list", "-json", "-nope"}, exitUsage},
+		{"version", []string{"version", "-json"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runGts(t, tt.args...)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+			// Every invocation prints exactly one JSON document
+			var doc any
+			decoder := json.NewDecoder(bytes.NewReader(out))
+			if err := decoder.Decode(&doc); err != nil {
+				t.Fatalf("Expected a JSON document, got %q: %v", out, err)
+			}
+			if decoder.More() {
+				t.Errorf("Expected a single JSON document, got %q", out)
+			}
+			if result, ok := doc.(map[string]any); ok && tt.code > exitFailure && result["exit_code"] != float64(tt.code) {
+				t.Errorf("Expected the error document to carry exit code %d, got %v", tt.code, result)
+			}
+		})
+	}
+}
+
+func TestValidateAll_Report(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.xml")
+	if _, code := runGts(t, "-path", "testdata/entities", "validate", "-all", "-report", "junit="+report); code != exitFailure {
+		t.Errorf("Expected exit code %d, got %d", exitFailure, code)
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("Expected the report to be written: %v", err)
+	}
+	// Every validated entity has a test case, not only the failing ones
+	for _, id := range []string{"gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1.0~x.shop._.o1.v1", "gts.x.shop.orders.order.v1.0~x.shop._.o2.v1"} {
+		if !bytes.Contains(data, []byte(id)) {
+			t.Errorf("Expected the report to cover %s, got %s", id, data)
+		}
+	}
+
+	if _, code := runGts(t, "validate", "-report", "junit="+report, "testdata/entities/orders.json"); code != exitUsage {
+		t.Errorf("Expected -report with files to be a usage error, got exit code %d", code)
+	}
+}
@@ -38,5 +38,5 @@ func runParseID(cmd *Command, args []string) {
 	}
 
 	result := gts.ParseGtsID(parseIDFlag)
-	writeJSON(result)
+	emitResult(result, result.OK)
 }
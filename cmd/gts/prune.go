@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdPrune = &Command{
+	UsageLine: "prune [-keep-minors <n>] [-remove-unused] [-older-than <duration>] [-orphans] [-protect <patterns>] [-dry-run]",
+	Short:     "remove superseded schema versions and stale instances",
+	Long: `
+Prune removes the entities selected by the given policy and prints a report
+listing every removed entity with the reason it was removed.
+
+Schemas are only removed when nothing left references them: no instance, no
+derived type and no reference from another entity's content. Removing an
+instance or a derived type can leave its schema unreferenced, in which case
+the schema is removed in the same run.
+
+The -keep-minors flag keeps the latest n minor versions of every major version
+of a type and removes the older ones (default: 0, keep every version).
+The -remove-unused flag removes every unreferenced schema, whatever its version.
+The -older-than flag removes instances not registered or updated within the
+duration, e.g. 720h.
+The -orphans flag removes instances whose schema is not registered.
+The -protect flag lists GTS IDs and wildcard patterns, separated by commas, of
+entities that are never removed.
+The -dry-run flag reports what would be removed without removing it.
+Requires -path to be set to load entities.
+
+The store is rebuilt from -path on every run, so this command only reports
+what pruning would do to the loaded entities; use POST /prune on a running
+server to prune its store.
+
+Example:
+
+	gts -path ./examples prune -keep-minors 2 -dry-run
+	`,
+}
+
+var (
+	pruneKeepMinors   int
+	pruneRemoveUnused bool
+	pruneOlderThan    time.Duration
+	pruneOrphans      bool
+	pruneProtect      string
+	pruneDryRun       bool
+)
+
+func init() {
+	cmdPrune.Run = runPrune
+	cmdPrune.Flag.IntVar(&pruneKeepMinors, "keep-minors", 0, "number of latest minor versions to keep per major version (0 keeps all)")
+	cmdPrune.Flag.BoolVar(&pruneRemoveUnused, "remove-unused", false, "remove every schema nothing references")
+	cmdPrune.Flag.DurationVar(&pruneOlderThan, "older-than", 0, "remove instances not updated within the duration")
+	cmdPrune.Flag.BoolVar(&pruneOrphans, "orphans", false, "remove instances whose schema is not registered")
+	cmdPrune.Flag.StringVar(&pruneProtect, "protect", "", "comma-separated IDs and patterns never to remove")
+	cmdPrune.Flag.BoolVar(&pruneDryRun, "dry-run", false, "report what would be removed without removing it")
+}
+
+func runPrune(cmd *Command, args []string) {
+	policy := gts.PrunePolicy{
+		KeepMinors:         pruneKeepMinors,
+		RemoveUnused:       pruneRemoveUnused,
+		InstancesOlderThan: pruneOlderThan,
+		RemoveOrphans:      pruneOrphans,
+	}
+	if pruneProtect != "" {
+		policy.Protect = strings.Split(pruneProtect, ",")
+	}
+
+	store := newStore()
+	report, err := store.Prune(policy, pruneDryRun)
+	if err != nil {
+		fatalError(err, "prune failed: %v", err)
+	}
+	writeJSON(report)
+}
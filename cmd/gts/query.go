@@ -5,39 +5,127 @@ Released under Apache License 2.0
 
 package main
 
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
 var cmdQuery = &Command{
-	UsageLine: "query -expr <expression> [-limit n]",
+	UsageLine: "query -expr <expression> [-limit n] [-sort key] [-ids-only | -fields a,b] [-stream] [-explain]",
 	Short:     "query entities using an expression",
 	Long: `
 Query filters entities using a GTS query expression.
 
-The -expr flag specifies the query expression.
+The -expr flag specifies the query expression. Filters in brackets compare
+attribute paths (or '#' tag keys) with =, !=, >, >=, <, <= and ~= (substring),
+e.g. "gts.x.commerce.*[payload.totalAmount>100, payload.customer.name~=doe]";
+ordering operators compare numbers when the value is a number, strings otherwise.
 The -limit flag limits the number of results (default: 100).
+The -sort flag sorts the matches before the limit applies, by gtsId or an
+attribute path, optionally followed by asc or desc, e.g. "occurredAt desc";
+matches without the attribute come last.
+The -ids-only flag lists the IDs of the matches instead of their content. The
+-fields flag returns, for every match, its ID and the values of the given
+comma-separated attribute paths; paths an entity lacks are omitted. Neither
+applies to -stream.
+The -stream flag writes each match as one JSON object per line (NDJSON) as it
+is found instead of collecting all results first, in store order; -sort does
+not apply to it.
+The -explain flag prints how the query was evaluated instead of the results:
+the parsed pattern, the scan strategy and entity count, the candidates each
+filter received and kept, the number of matches and the time taken.
 Requires -path to be set to load entities.
 
 Example:
 
 	gts -path ./examples query -expr "gts.vendor.pkg.*" -limit 10
+	gts -path ./examples query -expr "gts.vendor.pkg.*" -ids-only
+	gts -path ./examples query -expr "gts.x.orders.*" -sort "occurredAt desc" -limit 10
+	gts -path ./examples query -expr "gts.vendor.pkg.*" -fields payload.orderId,status
+	gts -path ./examples query -expr "gts.vendor.*" -limit 0 -stream > results.ndjson
+	gts -path ./examples query -expr "gts.vendor.*[status=active]" -explain
 	`,
 }
 
 var (
-	queryExpr  string
-	queryLimit int
+	queryExpr    string
+	queryLimit   int
+	queryStream  bool
+	queryExplain bool
+	queryIDsOnly bool
+	queryFields  string
+	querySort    string
 )
 
 func init() {
 	cmdQuery.Run = runQuery
 	cmdQuery.Flag.StringVar(&queryExpr, "expr", "", "query expression")
-	cmdQuery.Flag.IntVar(&queryLimit, "limit", 100, "maximum number of results")
+	cmdQuery.Flag.IntVar(&queryLimit, "limit", 100, "maximum number of results (0 for no limit with -stream)")
+	cmdQuery.Flag.BoolVar(&queryStream, "stream", false, "write one JSON object per line as matches are found")
+	cmdQuery.Flag.BoolVar(&queryExplain, "explain", false, "print the query plan and statistics instead of the results")
+	cmdQuery.Flag.BoolVar(&queryIDsOnly, "ids-only", false, "list the IDs of the matches instead of their content")
+	cmdQuery.Flag.StringVar(&queryFields, "fields", "", "comma-separated attribute paths to return for every match")
+	cmdQuery.Flag.StringVar(&querySort, "sort", "", "gtsId or attribute path to sort by, optionally followed by asc or desc")
 }
 
 func runQuery(cmd *Command, args []string) {
 	if queryExpr == "" {
 		cmd.Usage()
 	}
+	if queryStream && jsonOutput {
+		usagef("-stream writes NDJSON and cannot be combined with -json")
+	}
 
 	store := newStore()
-	result := store.Query(queryExpr, queryLimit)
-	writeJSON(result)
+	if queryExplain {
+		plan, err := store.ExplainQuery(queryExpr, queryLimit)
+		if err != nil {
+			fatalError(err, "%v", err)
+		}
+		writeJSON(plan)
+		return
+	}
+	if queryStream {
+		streamQuery(store, queryExpr, queryLimit)
+		return
+	}
+
+	opts := gts.QueryOptions{IDsOnly: queryIDsOnly, OrderBy: querySort}
+	for _, field := range strings.Split(queryFields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			opts.Projection = append(opts.Projection, field)
+		}
+	}
+	result := store.QueryWithOptions(queryExpr, queryLimit, opts)
+	emitResult(result, result.Error == "")
+}
+
+// streamQuery writes query matches to stdout as NDJSON
+func streamQuery(store *gts.GtsStore, expr string, limit int) {
+	out := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	written := 0
+	var writeErr error
+	err := store.QueryStream(expr, func(item gts.QueryItem) bool {
+		if writeErr = enc.Encode(item.Content); writeErr != nil {
+			return false
+		}
+		written++
+		return limit <= 0 || written < limit
+	})
+	if err != nil {
+		fatalError(err, "%v", err)
+	}
+	if writeErr == nil {
+		writeErr = out.Flush()
+	}
+	if writeErr != nil {
+		fatalf("failed to write results: %v", writeErr)
+	}
 }
@@ -0,0 +1,195 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"errors"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+var cmdRegister = &Command{
+	UsageLine: "register [-dry-run | -atomic] [-out <dir>] <file|glob ...>",
+	Short:     "register entity files into the loaded entities",
+	Long: `
+Register reads the entities of files or glob patterns, '-' for stdin (a JSON
+object, array or NDJSON), and registers them into the entities loaded with
+-path (if any), with the reference validation set by -ref-validation. Entities
+are registered in dependency order: schemas first, and bases, schemas and
+referenced entities before the entities referencing them, whatever their order
+in the files. The output gives the result of each entity and the command exits
+with an error when an entity failed.
+
+The -atomic flag registers all entities or none: every entity is checked first,
+references between them resolving whatever their order, and nothing is
+registered, nor exported with -out, when one fails.
+
+The -dry-run flag registers nothing and prints the registration plan of each
+entity instead: whether its ID is new or overwrites a loaded entity, for
+schemas the compatibility with the overwritten schema or the highest lower
+loaded minor version, and the validation errors of every check, including the
+validation of instances against their schema. The command exits with an error
+when an entity has validation errors.
+The -out flag exports the resulting entities as a directory tree, which can
+be loaded with -path (see gts export).
+
+Examples:
+
+	gts -path ./registry register -dry-run ./order.v1.1.schema.json
+	gts -path ./registry register -out ./registry './schemas/*.json'
+	gts -path ./registry register -atomic -out ./registry ./batch.json
+	`,
+}
+
+var (
+	registerDryRun bool
+	registerAtomic bool
+	registerOut    string
+)
+
+func init() {
+	cmdRegister.Run = runRegister
+	cmdRegister.Flag.BoolVar(&registerDryRun, "dry-run", false, "print what would be registered without registering it")
+	cmdRegister.Flag.BoolVar(&registerAtomic, "atomic", false, "register all entities or none of them")
+	cmdRegister.Flag.StringVar(&registerOut, "out", "", "export the resulting entities to this directory")
+}
+
+// registerResult is the outcome of registering an entity of a file
+type registerResult struct {
+	File  string `json:"file"`
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// registerPlan is the registration plan of an entity of a file
+type registerPlan struct {
+	File string `json:"file"`
+	*gts.RegisterPlan
+}
+
+// fileEntity is an entity read from a file to register
+type fileEntity struct {
+	file   string
+	entity *gts.JsonEntity
+}
+
+func runRegister(cmd *Command, args []string) {
+	if len(args) == 0 {
+		cmd.Usage()
+	}
+	if registerDryRun && registerOut != "" {
+		usagef("-out cannot be used with -dry-run")
+	}
+	if registerDryRun && registerAtomic {
+		usagef("-atomic cannot be used with -dry-run")
+	}
+
+	files := candidateFiles(args, "register")
+	store := newStore()
+	cfg := readerConfig()
+	var entities []fileEntity
+	for _, file := range files {
+		parsed, err := readCandidates(file, cfg)
+		if err != nil {
+			failf("failed to parse %s: %v", file, err)
+		}
+		if file == stdinPath {
+			file = gts.StdinStreamName
+		}
+		for _, entity := range parsed {
+			entities = append(entities, fileEntity{file: file, entity: entity})
+		}
+	}
+	// Entities are registered after the entities of the same files they depend on
+	batch := make([]*gts.JsonEntity, len(entities))
+	for i, e := range entities {
+		batch[i] = e.entity
+	}
+	ordered := make([]fileEntity, 0, len(entities))
+	for _, i := range gts.DependencyOrder(batch) {
+		ordered = append(ordered, entities[i])
+	}
+	entities = ordered
+
+	if registerDryRun {
+		planRegister(store, entities)
+		return
+	}
+
+	var results []registerResult
+	failed := 0
+	if registerAtomic {
+		results, failed = registerAtomically(store, entities)
+	} else {
+		for _, e := range entities {
+			result := registerResult{File: e.file, ID: e.entity.Label, OK: true}
+			if e.entity.GtsID != nil {
+				result.ID = e.entity.GtsID.ID
+			}
+			if err := store.Register(e.entity); err != nil {
+				result.OK, result.Error = false, err.Error()
+				failed++
+			}
+			results = append(results, result)
+		}
+	}
+	if registerOut != "" && (!registerAtomic || failed == 0) {
+		if _, err := store.ExportTree("", registerOut); err != nil {
+			fatalError(err, "export failed: %v", err)
+		}
+	}
+	writeJSON(results)
+	if failed > 0 {
+		failf("%d of %d entities failed to register", failed, len(results))
+	}
+}
+
+// registerAtomically registers the entities with GtsStore.RegisterAllWithOptions, all or none of
+// them, and returns the result of each entity with the number of failed entities. Entities that
+// passed their checks are not OK when others failed, as nothing was registered.
+func registerAtomically(store *gts.GtsStore, entities []fileEntity) ([]registerResult, int) {
+	batch := make([]*gts.JsonEntity, len(entities))
+	for i, e := range entities {
+		batch[i] = e.entity
+	}
+	outcome, err := store.RegisterAllWithOptions(batch, gts.BatchOptions{Atomic: true})
+	var rejected *gts.BatchRejectedError
+	if err != nil && !errors.As(err, &rejected) {
+		fatalError(err, "%v", err)
+	}
+
+	results := make([]registerResult, len(entities))
+	for i, e := range entities {
+		entry := outcome.Results[i]
+		results[i] = registerResult{File: e.file, ID: e.entity.Label, OK: entry.OK, Error: entry.Error}
+		if entry.ID != "" {
+			results[i].ID = entry.ID
+		}
+	}
+	return results, outcome.Failed
+}
+
+// planRegister prints the registration plans of the entities and fails if any has validation
+// errors. The store of the command is not saved, so each planned entity is registered to let the
+// following ones see it.
+func planRegister(store *gts.GtsStore, entities []fileEntity) {
+	plans := make([]registerPlan, 0, len(entities))
+	failed := 0
+	for _, e := range entities {
+		plan := store.PlanRegister(e.entity)
+		if len(plan.ValidationErrors) > 0 {
+			failed++
+		} else {
+			_ = store.Register(e.entity)
+		}
+		plans = append(plans, registerPlan{File: e.file, RegisterPlan: plan})
+	}
+	writeJSON(plans)
+	if failed > 0 {
+		failf("%d of %d entities have validation errors", failed, len(plans))
+	}
+}
@@ -5,36 +5,135 @@ Released under Apache License 2.0
 
 package main
 
+import (
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
 var cmdRelationships = &Command{
-	UsageLine: "relationships -id <gts-id>",
+	UsageLine: "relationships -id <gts-id> [-depth n] [-max-nodes n] [-format tree|edges|dot] | -id <gts-id> -reverse [-minor-versions] | -lineage <gts-id>",
+	Aliases:   []string{"rel"},
 	Short:     "resolve relationships for an entity",
 	Long: `
 Relationships builds a graph of schema relationships for an entity.
 
 The -id flag specifies the GTS ID of the entity.
+The -depth flag limits the number of edges between the entity and any node.
+The -max-nodes flag limits the number of nodes in the graph.
+The -format flag selects the nested tree or a flat list of
+{from, to, kind, source_path} edges.
+For an instance, the graph starts with a "schema" edge to its schema, which
+is followed to its references and base types, and has a "gts-ref" edge for
+every value its schema constrains with x-gts-ref; other GTS IDs it holds are
+"ref" edges.
+With any of these flags the output reports node and edge counts and sets
+truncated when a limit cut the graph short.
+With -format dot the graph is written in the Graphviz DOT language instead,
+for rendering with e.g. "dot -Tsvg": nodes are named by GTS ID, schemas are
+blue, instances yellow, unresolved nodes dashed and nodes cut short by a limit
+marked truncated.
+
+The -reverse flag lists the registered schemas and instances referencing the
+entity instead, grouped by the keyword carrying the reference ($ref, $schema,
+x-gts-ref, another property, or schema_id for the schema of an instance or the
+base of a derived schema). With -minor-versions, references to any minor
+version of a schema ID without minor version are included.
+
+The -lineage flag prints the property lineage of a schema instead: for every
+property path of the flattened schema, the layer of its allOf hierarchy that
+introduced it and each later layer that overrode it, with the changed keywords.
 Requires -path to be set to load entities.
 
 Example:
 
 	gts -path ./examples relationships -id gts.vendor.pkg.ns.type.v1~
+	gts -path ./examples relationships -id gts.vendor.pkg.ns.type.v1~ -depth 2 -format edges
+	gts -path ./examples relationships -id gts.vendor.pkg.ns.type.v1~ -depth 3 -format dot | dot -Tsvg > graph.svg
+	gts -path ./examples relationships -id gts.vendor.pkg.ns.type.v1~ -reverse -minor-versions
+	gts -path ./examples relationships -lineage gts.vendor.pkg.ns.type.v1~vendor.pkg.ns.derived.v1~
 	`,
 }
 
 var (
-	relationshipsID string
+	relationshipsID       string
+	relationshipsDepth    int
+	relationshipsMaxNodes int
+	relationshipsFormat   string
+	relationshipsLineage  string
+	relationshipsReverse  bool
+	relationshipsMinors   bool
 )
 
 func init() {
 	cmdRelationships.Run = runRelationships
 	cmdRelationships.Flag.StringVar(&relationshipsID, "id", "", "GTS ID of the entity")
+	cmdRelationships.Flag.IntVar(&relationshipsDepth, "depth", 0, "maximum depth of the graph (0 = unlimited)")
+	cmdRelationships.Flag.IntVar(&relationshipsMaxNodes, "max-nodes", 0, "maximum number of nodes (0 = unlimited)")
+	cmdRelationships.Flag.StringVar(&relationshipsFormat, "format", "", "output format: tree, edges or dot")
+	cmdRelationships.Flag.BoolVar(&relationshipsReverse, "reverse", false, "list the entities referencing the entity")
+	cmdRelationships.Flag.BoolVar(&relationshipsMinors, "minor-versions", false, "with -reverse, include references to minor versions")
+	cmdRelationships.Flag.StringVar(&relationshipsLineage, "lineage", "", "GTS ID of a schema whose property lineage to print")
 }
 
 func runRelationships(cmd *Command, args []string) {
+	if relationshipsLineage != "" {
+		lineage, err := newStore().PropertyLineage(relationshipsLineage)
+		if err != nil {
+			fatalError(err, "%v", err)
+		}
+		writeJSON(lineage)
+		return
+	}
 	if relationshipsID == "" {
 		cmd.Usage()
 	}
 
 	store := newStore()
-	result := store.BuildSchemaGraph(relationshipsID)
-	writeJSON(result)
+	if relationshipsReverse {
+		result := store.FindReferrersWithOptions(relationshipsID, gts.ReferrersOptions{IncludeMinorVersions: relationshipsMinors})
+		if result.Error != "" {
+			failf("%s", result.Error)
+		}
+		writeJSON(result)
+		return
+	}
+	if relationshipsDepth == 0 && relationshipsMaxNodes == 0 && relationshipsFormat == "" {
+		graph := store.BuildSchemaGraph(relationshipsID)
+		emitResult(graph, graph.Resolved)
+		return
+	}
+	if relationshipsFormat == "dot" && jsonOutput {
+		usagef("-format dot cannot be combined with -json")
+	}
+
+	format := relationshipsFormat
+	if format == "dot" {
+		format = gts.SchemaGraphFormatTree
+	}
+	result, err := store.BuildSchemaGraphWithOptions(relationshipsID, gts.SchemaGraphOptions{
+		MaxDepth: relationshipsDepth,
+		MaxNodes: relationshipsMaxNodes,
+		Format:   format,
+	})
+	if err != nil {
+		usagef("%v", err)
+	}
+	resolved := rootResolved(store, relationshipsID)
+	if relationshipsFormat == "dot" {
+		if err := gts.WriteSchemaGraphDOT(os.Stdout, result.Graph); err != nil {
+			fatalf("failed to write graph: %v", err)
+		}
+		if !resolved {
+			os.Exit(exitFailure)
+		}
+		return
+	}
+	emitResult(result, resolved)
+}
+
+// rootResolved reports whether the entity a relationship graph starts from is registered
+func rootResolved(store *gts.GtsStore, id string) bool {
+	result, err := store.BuildSchemaGraphWithOptions(id, gts.SchemaGraphOptions{MaxNodes: 1})
+	return err == nil && result.Graph.Resolved
 }
@@ -7,45 +7,133 @@ package main
 
 import (
 	"fmt"
+	"slices"
 
+	"github.com/GlobalTypeSystem/gts-go/gts"
 	"github.com/GlobalTypeSystem/gts-go/server"
 )
 
 var cmdServer = &Command{
-	UsageLine: "server [-host address] [-port number]",
+	UsageLine: "server [-host address] [-port number] [-freeze-after-load] [-watch] [-revalidate-dependents mode] [-reject-schema-id-conflicts] [-enforce-id-type-consistency] [-reader-miss-policy policy] [-max-upload-file-size bytes] [-max-upload-size bytes] [-cors-origins list] [-auth-token token [-auth-reads]]",
 	Short:     "start the GTS HTTP server",
 	Long: `
 Server starts the GTS HTTP server for REST API access.
 
 The -host flag specifies the host address (default: 127.0.0.1).
 The -port flag specifies the port number (default: 8000).
+The -freeze-after-load flag switches the store to read-only mode once the
+entities from -path are loaded; mutation endpoints then answer 409 Conflict.
+The -watch flag polls the files of -path every second and applies their
+changes to the store: edited and new files are re-registered, replacing the
+previous versions, and the entities of deleted files are removed. A file that
+cannot be parsed or an entity that fails to register is logged and the
+previous version is kept. It cannot be combined with -freeze-after-load.
+The -revalidate-dependents flag re-validates the registered instances of a
+schema when it is overwritten with different content: off (default), report
+(the registration response carries a dependents report) or reject (the schema
+is refused with 409 Conflict if any instance would no longer validate).
+The -reject-schema-id-conflicts flag refuses instances whose schema-ID fields
+(e.g. type and gtsType) name different schemas, unless they differ in minor
+version only, in which case the newer version is used.
+The -enforce-id-type-consistency flag refuses instances declaring a schema ID
+other than the type their chained ID encodes, up to minor versions.
+The -reader-miss-policy flag decides whether lookups of IDs that were not
+loaded from -path read the files again: always-retry (default), negative-cache
+(misses are remembered for a minute) or never-retry-after-load. GET /state
+reports the lookup counters.
+The -max-upload-file-size and -max-upload-size flags limit POST /entities:upload:
+the size of each uploaded file or archive member and of the whole request.
+The -cors-origins flag takes a comma-separated list of origins allowed to call
+the API from a browser, or * for any; their requests get Access-Control-Allow-*
+headers and their OPTIONS preflight requests are answered 204.
+The -auth-token flag requires an "Authorization: Bearer <token>" header on
+POST, PUT, PATCH and DELETE requests, which are answered 401 Unauthorized
+otherwise. GET requests stay open unless -auth-reads is set.
 
 Example:
 
 	gts -path ./examples server -host 127.0.0.1 -port 8000
+	gts -path ./schemas server -watch
+	gts -path ./schemas server -cors-origins https://ui.example.com -auth-token "$GTS_TOKEN"
 	`,
 }
 
 var (
-	serverHost string
-	serverPort int
+	serverHost            string
+	serverPort            int
+	serverFreezeAfterLoad bool
+	serverWatch           bool
+	serverMaxUploadFile   int64
+	serverMaxUpload       int64
+	serverCORSOrigins     string
+	serverAuthToken       string
+	serverAuthReads       bool
+	// revalidateDependents, rejectSchemaIDConflicts, enforceIDTypeConsistency and
+	// readerMissPolicy are read by newStore
+	revalidateDependents     string
+	rejectSchemaIDConflicts  bool
+	enforceIDTypeConsistency bool
+	readerMissPolicy         string
 )
 
 func init() {
 	cmdServer.Run = runServer
 	cmdServer.Flag.StringVar(&serverHost, "host", "127.0.0.1", "host address")
 	cmdServer.Flag.IntVar(&serverPort, "port", 8000, "port number")
+	cmdServer.Flag.BoolVar(&serverFreezeAfterLoad, "freeze-after-load", false, "switch the store to read-only mode after loading")
+	cmdServer.Flag.BoolVar(&serverWatch, "watch", false, "apply changes of the files of -path to the store")
+	cmdServer.Flag.StringVar(&revalidateDependents, "revalidate-dependents", "off", "re-validate instances of overwritten schemas: off, report or reject")
+	cmdServer.Flag.BoolVar(&rejectSchemaIDConflicts, "reject-schema-id-conflicts", false, "refuse instances whose schema-ID fields name different schemas")
+	cmdServer.Flag.BoolVar(&enforceIDTypeConsistency, "enforce-id-type-consistency", false, "refuse instances declaring a schema ID other than the type their ID encodes")
+	cmdServer.Flag.StringVar(&readerMissPolicy, "reader-miss-policy", "always-retry", "lookups of IDs not loaded: always-retry, negative-cache or never-retry-after-load")
+	cmdServer.Flag.Int64Var(&serverMaxUploadFile, "max-upload-file-size", server.DefaultMaxUploadFileSize, "maximum size in bytes of an uploaded file or archive member")
+	cmdServer.Flag.Int64Var(&serverMaxUpload, "max-upload-size", server.DefaultMaxUploadSize, "maximum size in bytes of an upload request")
+	cmdServer.Flag.StringVar(&serverCORSOrigins, "cors-origins", "", "comma-separated origins allowed to call the API from a browser")
+	cmdServer.Flag.StringVar(&serverAuthToken, "auth-token", "", "bearer token required on mutating requests")
+	cmdServer.Flag.BoolVar(&serverAuthReads, "auth-reads", false, "require the auth token on GET requests too")
 }
 
 func runServer(cmd *Command, args []string) {
+	if serverAuthReads && serverAuthToken == "" {
+		usagef("-auth-reads requires -auth-token")
+	}
+	var watched []string
+	if serverWatch {
+		watched = slices.DeleteFunc(parsePaths(path), func(p string) bool { return p == stdinPath })
+		if len(watched) == 0 {
+			usagef("-watch requires -path")
+		}
+		if serverFreezeAfterLoad {
+			usagef("-watch cannot be combined with -freeze-after-load")
+		}
+	}
+
 	store := newStore()
+	if serverFreezeAfterLoad {
+		store.Freeze()
+	}
+	if serverWatch {
+		if _, err := store.WatchPaths(watched, gts.WatchOptions{Config: readerConfig()}); err != nil {
+			fatalError(err, "%v", err)
+		}
+	}
 
-	fmt.Printf("starting server at http://%s:%d\n", serverHost, serverPort)
-	if verbose == 0 {
-		fmt.Println("use -v for verbose logging")
+	if jsonOutput {
+		writeJSON(map[string]any{"url": fmt.Sprintf("http://%s:%d", serverHost, serverPort), "entities": store.Count()})
+	} else {
+		fmt.Printf("starting server at http://%s:%d\n", serverHost, serverPort)
+		if verbose == 0 {
+			fmt.Println("use -v for verbose logging")
+		}
 	}
 
 	srv := server.NewServer(store, serverHost, serverPort, verbose)
+	srv.SetUploadLimits(serverMaxUploadFile, serverMaxUpload)
+	srv.SetAccessConfig(server.AccessConfig{
+		CORSOrigins: server.ParseCORSOrigins(serverCORSOrigins),
+		AuthToken:   serverAuthToken,
+		AuthReads:   serverAuthReads,
+	})
 	if err := srv.Start(); err != nil {
 		fatalf("server failed: %v", err)
 	}
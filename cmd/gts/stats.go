@@ -0,0 +1,48 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+var cmdStats = &Command{
+	UsageLine: "stats [-integrity]",
+	Short:     "summarize the loaded entities and their integrity",
+	Long: `
+Stats summarizes the loaded entities: the number of schemas and instances, of
+instances whose schema is not loaded, of schemas referencing entities that are
+not loaded and of IDs loaded from more than one file, and the number of
+entities per vendor and per vendor.package.
+
+The -integrity flag lists the problems behind these counts instead, each with
+the entity ID, its source label and file, and exits with status 1 when there
+are any.
+Requires -path to be set to load entities.
+
+Example:
+
+	gts -path ./data stats
+	gts -path ./data stats -integrity
+	`,
+}
+
+var statsIntegrity bool
+
+func init() {
+	cmdStats.Run = runStats
+	cmdStats.Flag.BoolVar(&statsIntegrity, "integrity", false, "list the integrity problems of the loaded entities")
+}
+
+func runStats(cmd *Command, args []string) {
+	store := newStore()
+	if !statsIntegrity {
+		writeJSON(store.Stats())
+		return
+	}
+
+	report := store.IntegrityCheck()
+	writeJSON(report)
+	if !report.OK {
+		failf("%d integrity problems in %d entities", len(report.Problems), report.Checked)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"strings"
+)
+
+var cmdTag = &Command{
+	UsageLine: "tag <id> key=value...",
+	Short:     "tag an entity with operational metadata",
+	Long: `
+Tag sets the tags of an entity and prints them. Tags are key/value metadata
+(owner, environment, review status, ...) kept alongside the entity, not in its
+content, and can be matched in queries with '#'-prefixed filter keys:
+
+	gts.x.commerce.*[#owner=payments-team, status=active]
+
+The given pairs replace the entity's tags. Tag keys may contain letters,
+digits, '_', '-', '.' and '/'. Requires -path to be set to load entities.
+
+The store is rebuilt from -path on every run, so tags set here only last for
+the command; use PUT /entities/{id}/tags on a running server to keep them, and
+export the server's store to carry them in the export manifest.
+
+Example:
+
+	gts -path ./examples tag gts.x.core.events.type.v1~ owner=platform env=prod
+	`,
+}
+
+func init() {
+	cmdTag.Run = runTag
+}
+
+func runTag(cmd *Command, args []string) {
+	if len(args) < 2 {
+		cmd.Usage()
+	}
+
+	id := args[0]
+	tags := make(map[string]string, len(args)-1)
+	for _, pair := range args[1:] {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			usagef("invalid tag %q: expected key=value", pair)
+		}
+		tags[key] = value
+	}
+
+	store := newStore()
+	if err := store.SetTags(id, tags); err != nil {
+		fatalError(err, "%v", err)
+	}
+	writeJSON(map[string]any{"id": id, "tags": store.GetTags(id)})
+}
@@ -7,36 +7,112 @@ package main
 
 import (
 	"github.com/GlobalTypeSystem/gts-go/gts"
+	"github.com/google/uuid"
 )
 
 var cmdUUID = &Command{
-	UsageLine: "uuid -id <gts-id>",
+	UsageLine: "uuid -id <gts-id> [-tree | -short] | uuid -reverse <uuid> | uuid -verify",
 	Short:     "generate UUID from a GTS ID",
 	Long: `
 UUID generates a deterministic UUID from a GTS identifier.
 
 The -id flag specifies the GTS ID.
+The -tree flag emits the UUID of every segment prefix of a chained ID, from the
+base type through each derived type to the full ID.
+The -short flag prints the short ID of the GTS ID instead: a deterministic
+16-character [a-z2-7] encoding for URLs, topic names and filenames. Short IDs
+cannot be decoded; resolve them with 'gts get' or GET /entities/{id}.
+The -reverse flag looks up the entity loaded from -path whose GTS ID derives
+the given UUID instead, and prints it like 'gts get'; it fails if no loaded
+ID derives the UUID.
+The -verify flag checks the UUIDs of every entity loaded from -path instead:
+it reports UUIDs shared by different IDs and IDs that are not canonical, with
+totals per vendor, and exits with status 1 if it finds any.
 
 Example:
 
 	gts uuid -id gts.vendor.pkg.ns.type.v1~
+	gts uuid -id gts.vendor.pkg.ns.type.v1~vendor.app._.order.v1.0 -tree
+	gts uuid -id gts.vendor.pkg.ns.type.v1~ -short
+	gts -path ./examples uuid -reverse e60c1de8-f666-587b-8d0b-73e81a7cc860
+	gts -path ./examples uuid -verify
 	`,
 }
 
 var (
 	uuidIDFlag string
+	uuidTree   bool
+	uuidShort  bool
+	uuidVerify bool
+	uuidRev    string
 )
 
 func init() {
 	cmdUUID.Run = runUUID
 	cmdUUID.Flag.StringVar(&uuidIDFlag, "id", "", "GTS ID")
+	cmdUUID.Flag.BoolVar(&uuidTree, "tree", false, "emit the UUID of every segment prefix")
+	cmdUUID.Flag.BoolVar(&uuidShort, "short", false, "print the short ID instead of the UUID")
+	cmdUUID.Flag.StringVar(&uuidRev, "reverse", "", "UUID to look up among the loaded entities")
+	cmdUUID.Flag.BoolVar(&uuidVerify, "verify", false, "verify the UUIDs of every loaded entity")
 }
 
 func runUUID(cmd *Command, args []string) {
+	if uuidVerify {
+		report := newStore().VerifyUUIDIntegrity()
+		emitResult(report, report.OK)
+		return
+	}
+
+	if uuidRev != "" {
+		runUUIDReverse(uuidRev)
+		return
+	}
+
 	if uuidIDFlag == "" {
 		cmd.Usage()
 	}
 
+	if uuidShort {
+		short, err := gts.ShortID(uuidIDFlag)
+		if err != nil {
+			fatalError(err, "%v", err)
+		}
+		writeJSON(map[string]any{"id": uuidIDFlag, "short_id": short})
+		return
+	}
+
+	if uuidTree {
+		tree := gts.NewUUIDTreeResult(uuidIDFlag)
+		emitResult(tree, tree.Error == "")
+		return
+	}
+
 	result := gts.IDToUUID(uuidIDFlag)
+	emitResult(result, result.Error == "")
+}
+
+// runUUIDReverse prints the loaded entity whose GTS ID derives u
+func runUUIDReverse(u string) {
+	if err := uuid.Validate(u); err != nil {
+		failf("invalid UUID '%s': %v", u, err)
+	}
+	store := newStore()
+	entity := store.GetByUUID(u)
+	if entity == nil {
+		failf("no loaded entity has UUID %s", u)
+	}
+
+	short, _ := gts.ShortID(entity.GtsID.ID)
+	result := map[string]any{
+		"id":        entity.GtsID.ID,
+		"uuid":      entity.GtsID.ToUUID().String(),
+		"short_id":  short,
+		"schema_id": entity.SchemaID,
+		"is_schema": entity.IsSchema,
+		"content":   entity.Content,
+	}
+	if tags := store.GetTags(entity.GtsID.ID); tags != nil {
+		result["tags"] = tags
+	}
 	writeJSON(result)
 }
@@ -5,36 +5,282 @@ Released under Apache License 2.0
 
 package main
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
 var cmdValidate = &Command{
-	UsageLine: "validate -id <gts-id>",
+	UsageLine: "validate [-id <gts-id> | -all [-format json|text]] [-schema-path <dir>] [-report format=path] [-strict-keywords] [file|glob ...]",
+	Aliases:   []string{"val"},
 	Short:     "validate an instance against its schema",
 	Long: `
 Validate checks an instance against its corresponding schema.
 
-The -id flag specifies the GTS ID of the instance.
-Requires -path to be set to load entities.
+The -id flag specifies the GTS ID of a loaded instance. Alternatively, files or
+glob patterns of candidate instances are given as arguments, '-' for stdin (a
+JSON object, array or NDJSON): their objects are validated without being
+registered, each reported separately, and the command exits with status 1 if
+any of them fails. The -schema-path flag loads the
+schemas to validate against, in addition to -path.
+The -all flag validates every loaded entity instead: schemas, including their
+$ref and x-gts-ref constraints, and instances against their schemas. The
+output counts valid, invalid and skipped entities (instances without schema
+ID) and the command exits with status 1 if any entity is invalid. The -format
+flag selects the JSON result (default) or one line per entity and a summary.
+Files and objects skipped while loading are summarized on stderr, as by list.
+The -report flag writes a validation report for CI systems in addition to the
+output of -id or -all, covering every validated entity. Supported formats are
+junit and sarif; the flag may be repeated.
+The -strict-keywords flag fails validation when the schema contains keys that
+are not JSON Schema keywords of its draft or GTS extensions, e.g. a misspelled
+"additionalProperites" that would otherwise be ignored.
+Failures are located in the instance's file: the output's location is
+file:line:column of the failing value, and report findings carry the position.
+Requires -path or -schema-path to be set to load schemas. Reports cannot be
+written for files given as arguments.
 
 Example:
 
 	gts -path ./examples validate -id gts.vendor.pkg.ns.type.v1.0
+	gts -path ./examples validate -id gts.vendor.pkg.ns.type.v1.0 -report junit=report.xml -report sarif=report.sarif
+	gts -path ./examples validate -id gts.vendor.pkg.ns.type.v1.0 -strict-keywords
+	gts validate -schema-path ./schemas ./candidate.json
+	gts validate -schema-path ./schemas './candidates/*.json'
+	kubectl get cm orders -o jsonpath='{.data.order\.json}' | gts validate -schema-path ./schemas -
+	gts -path ./data validate -all -format text
+	gts -path ./data validate -all -report junit=report.xml
 	`,
 }
 
 var (
-	validateInstance string
+	validateInstance   string
+	validateSchemaPath string
+	validateAll        bool
+	validateFormat     string
+	validateReports    reportFlag
+	strictKeywords     bool
 )
 
 func init() {
 	cmdValidate.Run = runValidate
 	cmdValidate.Flag.StringVar(&validateInstance, "id", "", "GTS ID of the instance")
+	cmdValidate.Flag.BoolVar(&validateAll, "all", false, "validate every loaded schema and instance")
+	cmdValidate.Flag.StringVar(&validateFormat, "format", "json", "output format of -all: json or text")
+	cmdValidate.Flag.StringVar(&validateSchemaPath, "schema-path", "", "path to schema files or directories to validate files against")
+	cmdValidate.Flag.Var(&validateReports, "report", "write a report as format=path (junit or sarif), may be repeated")
+	cmdValidate.Flag.BoolVar(&strictKeywords, "strict-keywords", false, "report schema keys that are not known keywords")
 }
 
 func runValidate(cmd *Command, args []string) {
-	if validateInstance == "" {
+	modes := 0
+	for _, set := range []bool{validateInstance != "", validateAll, len(args) > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
 		cmd.Usage()
 	}
+	if validateFormat != "json" && validateFormat != "text" {
+		usagef("invalid -format %q (expected json or text)", validateFormat)
+	}
+	if validateFormat == "text" && jsonOutput {
+		usagef("-format text cannot be combined with -json")
+	}
+	if len(args) > 0 && len(validateReports) > 0 {
+		usagef("-report cannot be combined with files to validate")
+	}
 
+	recordPositions = true
+	if validateSchemaPath != "" {
+		path = strings.Join(append(parsePaths(path), parsePaths(validateSchemaPath)...), ",")
+	}
 	store := newStore()
+	if len(args) > 0 {
+		validateFiles(store, args)
+		return
+	}
+	if validateAll {
+		warnLoadErrors(store)
+		validateStore(store)
+		return
+	}
+
 	result := store.ValidateInstance(validateInstance)
 	writeJSON(result)
+
+	if len(validateReports) > 0 {
+		report := store.BuildValidationReport([]string{validateInstance})
+		writeReports(validateReports, report)
+	}
+	if !result.OK {
+		os.Exit(exitFailure)
+	}
+}
+
+// fileValidationResult is the validation outcome of an object of a candidate file
+type fileValidationResult struct {
+	File string `json:"file"`
+	*gts.ValidationResult
+}
+
+// validateFiles validates the objects of the files matching the patterns without registering them
+func validateFiles(store *gts.GtsStore, patterns []string) {
+	files := candidateFiles(patterns, "validate")
+
+	cfg := readerConfig()
+	cfg.RecordPositions = true
+
+	results := []fileValidationResult{}
+	failed := 0
+	for _, file := range files {
+		entities, err := readCandidates(file, cfg)
+		if err != nil {
+			failf("failed to parse %s: %v", file, err)
+		}
+		if file == stdinPath {
+			file = gts.StdinStreamName
+		}
+		for _, entity := range entities {
+			result := store.ValidateEntity(entity)
+			if !result.OK {
+				failed++
+			}
+			results = append(results, fileValidationResult{File: file, ValidationResult: result})
+		}
+	}
+	writeJSON(results)
+
+	if failed > 0 {
+		failf("%d of %d entities failed validation", failed, len(results))
+	}
+}
+
+// candidateFiles expands the file and glob pattern arguments of a command reading candidate
+// files, keeping "-" for stdin; verb names what the command does with them in errors
+func candidateFiles(patterns []string, verb string) []string {
+	var files []string
+	for _, pattern := range patterns {
+		if pattern == stdinPath {
+			if slices.Contains(parsePaths(path), stdinPath) {
+				usagef("stdin cannot be read both as -path and as a file to %s", verb)
+			}
+			files = append(files, pattern)
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			usagef("invalid file pattern %q: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			usagef("no files match %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// readCandidates parses the candidate objects of a file, or of stdin (a JSON object, array or
+// NDJSON) for "-"
+func readCandidates(file string, cfg *gts.GtsConfig) ([]*gts.JsonEntity, error) {
+	if file == stdinPath {
+		return gts.ParseCandidateStream(gts.StdinStreamName, os.Stdin, cfg)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fatalf("failed to read %s: %v", file, err)
+	}
+	return gts.ParseCandidateDocument(file, data, cfg)
+}
+
+// validateStore validates every loaded entity, writes the -report reports over the checked ones
+// and fails if any is invalid
+func validateStore(store *gts.GtsStore) {
+	result := store.ValidateAll()
+	if len(validateReports) > 0 {
+		ids := make([]string, 0, len(result.Schemas)+len(result.Instances))
+		for _, entityResult := range append(result.Schemas, result.Instances...) {
+			ids = append(ids, entityResult.ID)
+		}
+		writeReports(validateReports, store.BuildValidationReport(ids))
+	}
+	if validateFormat == "text" {
+		for _, entityResult := range append(result.Schemas, result.Instances...) {
+			if entityResult.OK {
+				fmt.Printf("ok    %s\n", entityResult.ID)
+			} else {
+				fmt.Printf("FAIL  %s: %s\n", entityResult.ID, entityResult.Error)
+			}
+		}
+		for _, id := range result.SkippedIDs {
+			fmt.Printf("skip  %s: no schema ID\n", id)
+		}
+		fmt.Printf("%d valid, %d invalid, %d skipped\n", result.Valid, result.Invalid, result.Skipped)
+	} else {
+		writeJSON(result)
+	}
+
+	if !result.OK {
+		failf("%d of %d entities failed validation", result.Invalid, result.Valid+result.Invalid)
+	}
+}
+
+// reportSpec is a single -report format=path value
+type reportSpec struct {
+	format string
+	path   string
+}
+
+// reportFlag collects repeated -report format=path flags
+type reportFlag []reportSpec
+
+func (f *reportFlag) String() string {
+	parts := make([]string, 0, len(*f))
+	for _, spec := range *f {
+		parts = append(parts, spec.format+"="+spec.path)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *reportFlag) Set(value string) error {
+	format, path, ok := strings.Cut(value, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("report must be in the form format=path, got %q", value)
+	}
+	switch format {
+	case "junit", "sarif":
+	default:
+		return fmt.Errorf("unsupported report format %q (expected junit or sarif)", format)
+	}
+	*f = append(*f, reportSpec{format: format, path: path})
+	return nil
+}
+
+// writeReports serializes a validation report to every requested destination
+func writeReports(specs reportFlag, report *gts.ValidationReport) {
+	for _, spec := range specs {
+		f, err := os.Create(spec.path)
+		if err != nil {
+			fatalf("failed to create %s report: %v", spec.format, err)
+		}
+
+		switch spec.format {
+		case "junit":
+			err = gts.WriteJUnit(f, report)
+		case "sarif":
+			err = gts.WriteSARIF(f, report)
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fatalf("failed to write %s report: %v", spec.format, err)
+		}
+	}
 }
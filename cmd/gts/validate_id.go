@@ -38,5 +38,5 @@ func runValidateID(cmd *Command, args []string) {
 	}
 
 	result := gts.ValidateGtsID(validateIDFlag)
-	writeJSON(result)
+	emitResult(result, result.Valid)
 }
@@ -13,7 +13,10 @@ import (
 var cmdVersion = &Command{
 	UsageLine: "version",
 	Short:     "print GTS version",
-	Long:      `Version prints the GTS version.`,
+	Long: `
+Version prints the GTS version. With -json it prints an object with the
+version, the Go version and the module path.
+	`,
 }
 
 func init() {
@@ -22,6 +25,14 @@ func init() {
 
 func runVersion(cmd *Command, args []string) {
 	info, ok := debug.ReadBuildInfo()
+	if jsonOutput {
+		result := map[string]string{"version": "unknown"}
+		if ok {
+			result = map[string]string{"version": info.Main.Version, "go_version": info.GoVersion, "path": info.Path}
+		}
+		writeJSON(result)
+		return
+	}
 	if !ok {
 		fmt.Println("gts version unknown")
 		return
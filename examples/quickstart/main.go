@@ -0,0 +1,87 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+// Quickstart loads the example event types and orders, registers a new order, validates the
+// instances and queries them by tenant.
+//
+// Run it from the repository root:
+//
+//	go run ./examples/quickstart
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+const (
+	orderPlacedV10 = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~"
+	newOrderID     = orderPlacedV10 + "x.shop._.o1003.v1"
+)
+
+func main() {
+	fixtures := flag.String("fixtures", "examples/fixtures", "directory holding the example schemas and instances")
+	flag.Parse()
+	log.SetOutput(io.Discard)
+
+	if _, err := run(*fixtures, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run walks through the quickstart flow, printing each step to w, and returns the final store
+func run(fixtures string, w io.Writer) (*gts.GtsStore, error) {
+	// Load every schema and instance under the fixtures directory, checking GTS references
+	reader := gts.NewGtsFileReaderFromPath(fixtures, nil)
+	store := gts.NewGtsStoreWithConfig(reader, &gts.RegistryConfig{
+		RefValidation: gts.RefValidationStrict,
+		StableOrder:   true,
+	})
+	fmt.Fprintf(w, "loaded %d entities\n", store.Count())
+
+	// Register one more order in code
+	order := gts.NewJsonEntity(map[string]any{
+		"gtsId":      newOrderID,
+		"type":       orderPlacedV10,
+		"tenant":     "acme",
+		"occurredAt": "2025-05-02T09:15:00Z",
+		"payload":    map[string]any{"orderId": "o-1003", "total": 75.5},
+	}, gts.DefaultGtsConfig())
+	if err := store.Register(order); err != nil {
+		return nil, fmt.Errorf("register %s: %w", newOrderID, err)
+	}
+	fmt.Fprintf(w, "registered %s\n", newOrderID)
+
+	// Validate every order against its schema
+	result := store.Query(orderPlacedV10+"*", 100)
+	if result.Error != "" {
+		return nil, fmt.Errorf("query orders: %s", result.Error)
+	}
+	for _, item := range result.Results {
+		id, _ := item["gtsId"].(string)
+		if validation := store.ValidateInstance(id); !validation.OK {
+			return nil, fmt.Errorf("validate %s: %s", id, validation.Error)
+		}
+		fmt.Fprintf(w, "valid: %s\n", id)
+	}
+
+	// Query the orders of one tenant
+	acme := store.Query("gts.x.shop.events.*[tenant=acme]", 100)
+	if acme.Error != "" {
+		return nil, fmt.Errorf("query acme orders: %s", acme.Error)
+	}
+	fmt.Fprintf(w, "acme orders: %d\n", acme.Count)
+	for _, item := range acme.Results {
+		entity := gts.NewJsonEntity(item, gts.DefaultGtsConfig())
+		fmt.Fprintf(w, "  %s total %.2f\n", entity.MustString("payload.orderId"), entity.MustFloat("payload.total"))
+	}
+	return store, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestQuickstart(t *testing.T) {
+	var out bytes.Buffer
+	store, err := run("../fixtures", &out)
+	if err != nil {
+		t.Fatalf("Quickstart failed: %v", err)
+	}
+
+	expected := `loaded 5 entities
+registered gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1003.v1
+valid: gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1001.v1
+valid: gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1002.v1
+valid: gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1003.v1
+acme orders: 2
+  o-1001 total 149.99
+  o-1003 total 75.50
+`
+	if out.String() != expected {
+		t.Errorf("Expected output:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	ids := make([]string, 0, store.Count())
+	for id := range store.Items() {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	expectedIDs := []string{
+		"gts.x.shop.events.event.v1~",
+		"gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~",
+		"gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1001.v1",
+		"gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1002.v1",
+		"gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1003.v1",
+		"gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.1~",
+	}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("Expected store entities %v, got %v", expectedIDs, ids)
+	}
+	for _, id := range expectedIDs {
+		if entity := store.Get(id); entity.IsSchema {
+			if err := store.ValidateSchema(id); err != nil {
+				t.Errorf("Expected schema %s to be valid, got %v", id, err)
+			}
+		} else if result := store.ValidateInstance(id); !result.OK {
+			t.Errorf("Expected instance %s to be valid, got %s", id, result.Error)
+		}
+	}
+	if store.UnresolvedRefCount() != 0 {
+		t.Errorf("Expected no unresolved references, got %d", store.UnresolvedRefCount())
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+// Server-client serves the example fixtures with the GTS HTTP server and drives it with the
+// client package: it registers an order, validates it, upcasts it and queries the orders of a
+// tenant.
+//
+// Run it from the repository root:
+//
+//	go run ./examples/server-client
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/GlobalTypeSystem/gts-go/client"
+	"github.com/GlobalTypeSystem/gts-go/gts"
+	"github.com/GlobalTypeSystem/gts-go/server"
+)
+
+const (
+	orderPlacedV10 = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~"
+	orderPlacedV11 = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.1~"
+	newOrderID     = orderPlacedV10 + "x.shop._.o1004.v1"
+)
+
+func main() {
+	fixtures := flag.String("fixtures", "examples/fixtures", "directory holding the example schemas and instances")
+	flag.Parse()
+	log.SetOutput(io.Discard)
+
+	if err := run(*fixtures, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run serves the fixtures on a free local port and calls the server, printing each step to w
+func run(fixtures string, w io.Writer) error {
+	store := gts.NewGtsStore(gts.NewGtsFileReaderFromPath(fixtures, nil))
+	srv := server.NewServer(store, "127.0.0.1", 0, 0)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: srv.Handler()}
+	// Serve returns http.ErrServerClosed once the deferred Close runs
+	go func() { _ = httpServer.Serve(listener) }()
+	defer httpServer.Close()
+	c := client.New("http://"+listener.Addr().String(), nil)
+	ctx := context.Background()
+
+	if _, err := c.AddEntity(ctx, map[string]any{
+		"gtsId":      newOrderID,
+		"type":       orderPlacedV10,
+		"tenant":     "initech",
+		"occurredAt": "2025-05-03T08:00:00Z",
+		"payload":    map[string]any{"orderId": "o-1004", "total": 12},
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "registered %s\n", newOrderID)
+
+	validation, err := c.ValidateInstance(ctx, newOrderID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "valid: %v\n", validation.OK)
+
+	cast, err := c.Cast(ctx, newOrderID, orderPlacedV11)
+	if err != nil {
+		return err
+	}
+	payload, _ := cast.CastedEntity["payload"].(map[string]any)
+	fmt.Fprintf(w, "upcast currency: %v\n", payload["currency"])
+
+	query, err := c.Query(ctx, "gts.x.shop.events.*[tenant=initech]", 0)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "initech orders: %v\n", query.Count)
+	return nil
+}
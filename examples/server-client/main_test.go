@@ -0,0 +1,27 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestServerClient(t *testing.T) {
+	var out bytes.Buffer
+	if err := run("../fixtures", &out); err != nil {
+		t.Fatalf("Server-client failed: %v", err)
+	}
+
+	expected := `registered gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1004.v1
+valid: true
+upcast currency: EUR
+initech orders: 1
+`
+	if out.String() != expected {
+		t.Errorf("Expected output:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
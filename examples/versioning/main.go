@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+// Versioning checks that order_placed v1.1 is compatible with v1.0 and upcasts a v1.0 order to
+// v1.1, which fills in the currency the new minor version adds.
+//
+// Run it from the repository root:
+//
+//	go run ./examples/versioning
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+const (
+	orderPlacedV10 = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~"
+	orderPlacedV11 = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.1~"
+	orderID        = orderPlacedV10 + "x.shop._.o1001.v1"
+)
+
+func main() {
+	fixtures := flag.String("fixtures", "examples/fixtures", "directory holding the example schemas and instances")
+	flag.Parse()
+	log.SetOutput(io.Discard)
+
+	if _, err := run(*fixtures, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run checks compatibility and upcasts an order, printing each step to w, and returns the cast
+func run(fixtures string, w io.Writer) (*gts.CastResult, error) {
+	store := gts.NewGtsStore(gts.NewGtsFileReaderFromPath(fixtures, nil))
+
+	compat := store.CheckCompatibility(orderPlacedV10, orderPlacedV11)
+	if len(compat.IncompatibilityReasons) > 0 {
+		return nil, fmt.Errorf("v1.1 is not compatible with v1.0: %s", strings.Join(compat.IncompatibilityReasons, "; "))
+	}
+	fmt.Fprintf(w, "backward compatible: %t, forward compatible: %t\n", compat.IsBackwardCompatible, compat.IsForwardCompatible)
+
+	cast, err := store.Cast(orderID, orderPlacedV11)
+	if err != nil {
+		return nil, fmt.Errorf("cast %s: %w", orderID, err)
+	}
+	fmt.Fprintf(w, "added by the cast: %s\n", strings.Join(cast.AddedProperties, ", "))
+
+	upcast := gts.NewJsonEntity(cast.CastedEntity, gts.DefaultGtsConfig())
+	fmt.Fprintf(w, "type: %s\n", upcast.MustString("type"))
+	fmt.Fprintf(w, "currency: %s\n", upcast.MustString("payload.currency"))
+	return cast, nil
+}
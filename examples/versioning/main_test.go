@@ -0,0 +1,31 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVersioning(t *testing.T) {
+	var out bytes.Buffer
+	cast, err := run("../fixtures", &out)
+	if err != nil {
+		t.Fatalf("Versioning failed: %v", err)
+	}
+
+	expected := `backward compatible: true, forward compatible: true
+added by the cast: payload.currency
+type: gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.1~
+currency: EUR
+`
+	if out.String() != expected {
+		t.Errorf("Expected output:\n%s\ngot:\n%s", expected, out.String())
+	}
+	if !cast.IsFullyCompatible {
+		t.Error("Expected the upcast to be fully compatible")
+	}
+}
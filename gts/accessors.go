@@ -0,0 +1,221 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// The typed accessors read the value at an attribute path of the entity content, using the path
+// syntax of GetAttribute without the "gts_id@" prefix: fields separated by dots or slashes, array
+// indices in brackets and quoted keys (["a.b"]) for field names containing separators. They
+// return false when the path does not resolve or the value has another type; no value is
+// converted to or from a string.
+//
+// Numbers decoded from JSON are float64, so GetInt accepts a float64 with an integral value in
+// the int64 range, and GetFloat accepts any number. Both also accept the Go integer types and
+// json.Number, for content built in code or decoded with UseNumber.
+
+// value returns the value at path in the entity content
+func (e *JsonEntity) value(path string) (any, bool) {
+	if e == nil || e.Content == nil {
+		return nil, false
+	}
+	result := resolveAttributePath("", path, e.Content)
+	return result.Value, result.Resolved
+}
+
+// GetString returns the string at path
+func (e *JsonEntity) GetString(path string) (string, bool) {
+	v, ok := e.value(path)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// GetInt returns the integer at path
+func (e *JsonEntity) GetInt(path string) (int64, bool) {
+	v, ok := e.value(path)
+	if !ok {
+		return 0, false
+	}
+	return intValue(v)
+}
+
+// GetFloat returns the number at path
+func (e *JsonEntity) GetFloat(path string) (float64, bool) {
+	v, ok := e.value(path)
+	if !ok {
+		return 0, false
+	}
+	return floatValue(v)
+}
+
+// GetBool returns the boolean at path
+func (e *JsonEntity) GetBool(path string) (bool, bool) {
+	v, ok := e.value(path)
+	if !ok {
+		return false, false
+	}
+	b, ok := v.(bool)
+	return b, ok
+}
+
+// GetMap returns the object at path
+func (e *JsonEntity) GetMap(path string) (map[string]any, bool) {
+	v, ok := e.value(path)
+	if !ok {
+		return nil, false
+	}
+	m, ok := v.(map[string]any)
+	return m, ok
+}
+
+// GetSlice returns the array at path
+func (e *JsonEntity) GetSlice(path string) ([]any, bool) {
+	v, ok := e.value(path)
+	if !ok {
+		return nil, false
+	}
+	s, ok := v.([]any)
+	return s, ok
+}
+
+// GetTime parses the string at path with the first of layouts that matches; without layouts it
+// is parsed as RFC 3339
+func (e *JsonEntity) GetTime(path string, layouts ...string) (time.Time, bool) {
+	s, ok := e.GetString(path)
+	if !ok {
+		return time.Time{}, false
+	}
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339Nano}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MustString is GetString panicking when path has no string, for use in tests
+func (e *JsonEntity) MustString(path string) string {
+	s, ok := e.GetString(path)
+	if !ok {
+		e.accessorPanic("string", path)
+	}
+	return s
+}
+
+// MustInt is GetInt panicking when path has no integer, for use in tests
+func (e *JsonEntity) MustInt(path string) int64 {
+	n, ok := e.GetInt(path)
+	if !ok {
+		e.accessorPanic("integer", path)
+	}
+	return n
+}
+
+// MustFloat is GetFloat panicking when path has no number, for use in tests
+func (e *JsonEntity) MustFloat(path string) float64 {
+	f, ok := e.GetFloat(path)
+	if !ok {
+		e.accessorPanic("number", path)
+	}
+	return f
+}
+
+// MustBool is GetBool panicking when path has no boolean, for use in tests
+func (e *JsonEntity) MustBool(path string) bool {
+	b, ok := e.GetBool(path)
+	if !ok {
+		e.accessorPanic("boolean", path)
+	}
+	return b
+}
+
+// MustMap is GetMap panicking when path has no object, for use in tests
+func (e *JsonEntity) MustMap(path string) map[string]any {
+	m, ok := e.GetMap(path)
+	if !ok {
+		e.accessorPanic("object", path)
+	}
+	return m
+}
+
+// MustSlice is GetSlice panicking when path has no array, for use in tests
+func (e *JsonEntity) MustSlice(path string) []any {
+	s, ok := e.GetSlice(path)
+	if !ok {
+		e.accessorPanic("array", path)
+	}
+	return s
+}
+
+func (e *JsonEntity) accessorPanic(kind, path string) {
+	id := "entity"
+	if e != nil && e.GtsID != nil {
+		id = e.GtsID.ID
+	}
+	panic(fmt.Sprintf("gts: %s has no %s at '%s'", id, kind, path))
+}
+
+// intValue converts v to an int64 if it is an integral number in range
+func intValue(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		// 2^63 itself is out of range, and is exactly representable as a float64
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		// "2.0" and "1e3" are integral too
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return intValue(f)
+	default:
+		return 0, false
+	}
+}
+
+// floatValue converts v to a float64 if it is a number
+func floatValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newAccessorTestEntity() *JsonEntity {
+	return NewJsonEntity(map[string]any{
+		"id":      "gts.x.test.accessors.order.v1~x.test._.o1.v1",
+		"name":    "order",
+		"count":   float64(3),
+		"ratio":   1.5,
+		"big":     math.Pow(2, 63),
+		"native":  int64(7),
+		"number":  json.Number("42"),
+		"decimal": json.Number("2.0"),
+		"active":  true,
+		"created": "2025-05-01T10:00:00Z",
+		"day":     "2025-05-01",
+		"payload": map[string]any{
+			"a.b":   "dotted",
+			"lines": []any{map[string]any{"sku": "A-1", "qty": float64(2)}},
+		},
+	}, DefaultGtsConfig())
+}
+
+func TestAccessors(t *testing.T) {
+	e := newAccessorTestEntity()
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		get  func(path string) (any, bool)
+		path string
+		want any
+		ok   bool
+	}{
+		{"string", func(p string) (any, bool) { return e.GetString(p) }, "name", "order", true},
+		{"string nested index", func(p string) (any, bool) { return e.GetString(p) }, "payload.lines[0].sku", "A-1", true},
+		{"string slash path", func(p string) (any, bool) { return e.GetString(p) }, "payload/lines/0/sku", "A-1", true},
+		{"string quoted key", func(p string) (any, bool) { return e.GetString(p) }, `payload["a.b"]`, "dotted", true},
+		{"string single-quoted key", func(p string) (any, bool) { return e.GetString(p) }, `payload['a.b']`, "dotted", true},
+		{"string wrong type", func(p string) (any, bool) { return e.GetString(p) }, "count", "", false},
+		{"string missing", func(p string) (any, bool) { return e.GetString(p) }, "payload.missing", "", false},
+		{"string index out of range", func(p string) (any, bool) { return e.GetString(p) }, "payload.lines[1].sku", "", false},
+
+		{"int from integral float", func(p string) (any, bool) { return e.GetInt(p) }, "count", int64(3), true},
+		{"int nested", func(p string) (any, bool) { return e.GetInt(p) }, "payload.lines[0].qty", int64(2), true},
+		{"int native", func(p string) (any, bool) { return e.GetInt(p) }, "native", int64(7), true},
+		{"int json.Number", func(p string) (any, bool) { return e.GetInt(p) }, "number", int64(42), true},
+		{"int integral json.Number", func(p string) (any, bool) { return e.GetInt(p) }, "decimal", int64(2), true},
+		{"int fractional", func(p string) (any, bool) { return e.GetInt(p) }, "ratio", int64(0), false},
+		{"int out of range", func(p string) (any, bool) { return e.GetInt(p) }, "big", int64(0), false},
+		{"int from string", func(p string) (any, bool) { return e.GetInt(p) }, "name", int64(0), false},
+		{"int missing", func(p string) (any, bool) { return e.GetInt(p) }, "missing", int64(0), false},
+
+		{"float", func(p string) (any, bool) { return e.GetFloat(p) }, "ratio", 1.5, true},
+		{"float native int", func(p string) (any, bool) { return e.GetFloat(p) }, "native", float64(7), true},
+		{"float json.Number", func(p string) (any, bool) { return e.GetFloat(p) }, "decimal", 2.0, true},
+		{"float wrong type", func(p string) (any, bool) { return e.GetFloat(p) }, "active", float64(0), false},
+
+		{"bool", func(p string) (any, bool) { return e.GetBool(p) }, "active", true, true},
+		{"bool wrong type", func(p string) (any, bool) { return e.GetBool(p) }, "name", false, false},
+		{"bool missing", func(p string) (any, bool) { return e.GetBool(p) }, "missing", false, false},
+
+		{"map", func(p string) (any, bool) { return e.GetMap(p) }, "payload.lines[0]", map[string]any{"sku": "A-1", "qty": float64(2)}, true},
+		{"map wrong type", func(p string) (any, bool) { return e.GetMap(p) }, "payload.lines", map[string]any(nil), false},
+
+		{"slice", func(p string) (any, bool) { return e.GetSlice(p) }, "payload.lines", []any{map[string]any{"sku": "A-1", "qty": float64(2)}}, true},
+		{"slice wrong type", func(p string) (any, bool) { return e.GetSlice(p) }, "payload", []any(nil), false},
+
+		{"time RFC 3339", func(p string) (any, bool) { return e.GetTime(p) }, "created", day.Add(10 * time.Hour), true},
+		{"time layout", func(p string) (any, bool) { return e.GetTime(p, time.RFC3339, time.DateOnly) }, "day", day, true},
+		{"time no matching layout", func(p string) (any, bool) { return e.GetTime(p) }, "day", time.Time{}, false},
+		{"time wrong type", func(p string) (any, bool) { return e.GetTime(p) }, "count", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.get(tt.path)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: expected (%v, %v), got (%v, %v)", tt.path, tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestAccessors_NilEntity(t *testing.T) {
+	var e *JsonEntity
+	if _, ok := e.GetString("name"); ok {
+		t.Error("Expected a nil entity to resolve no path")
+	}
+}
+
+func TestMustAccessors(t *testing.T) {
+	e := newAccessorTestEntity()
+	if e.MustString("name") != "order" || e.MustInt("count") != 3 || e.MustFloat("ratio") != 1.5 ||
+		!e.MustBool("active") || len(e.MustMap("payload")) != 2 || len(e.MustSlice("payload.lines")) != 1 {
+		t.Error("Expected the Must accessors to return the values at their paths")
+	}
+
+	defer func() {
+		want := "gts: gts.x.test.accessors.order.v1~x.test._.o1.v1 has no integer at 'ratio'"
+		if r := recover(); r != want {
+			t.Errorf("Expected panic %q, got %v", want, r)
+		}
+	}()
+	e.MustInt("ratio")
+}
+
+func TestGetAttribute_QuotedKey(t *testing.T) {
+	store := NewGtsStore(nil)
+	if err := store.Register(newAccessorTestEntity()); err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+	result := store.GetAttribute(`gts.x.test.accessors.order.v1~x.test._.o1.v1@payload["a.b"]`)
+	if !result.Resolved || result.Value != "dotted" {
+		t.Errorf("Expected the quoted key to resolve, got %+v", result)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// allocateOptions holds the optional settings of AllocateInstanceID
+type allocateOptions struct {
+	major      int
+	reserveTTL time.Duration
+}
+
+// AllocateOption configures AllocateInstanceID
+type AllocateOption func(*allocateOptions)
+
+// WithMajorVersion sets the major version of the allocated instance segment (default 1)
+func WithMajorVersion(major int) AllocateOption {
+	return func(o *allocateOptions) {
+		o.major = major
+	}
+}
+
+// WithReservation reserves the allocated ID for ttl so that concurrent allocations
+// skip it until the caller registers the entity or the reservation expires
+func WithReservation(ttl time.Duration) AllocateOption {
+	return func(o *allocateOptions) {
+		o.reserveTTL = ttl
+	}
+}
+
+// AllocateInstanceID mints the next free chained instance ID under a type
+// The ID has the form <schemaID><vendor>.<pkg>.<namespace>.<typeName>.v<major>.<minor>, where minor is
+// one past the highest minor already registered or reserved under the same prefix (0 if none).
+func (s *GtsStore) AllocateInstanceID(schemaID, vendor, pkg, namespace, typeName string, opts ...AllocateOption) (string, error) {
+	options := allocateOptions{major: 1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	schemaGtsID, err := NewGtsID(schemaID)
+	if err != nil {
+		return "", err
+	}
+	if !schemaGtsID.IsType() {
+		return "", fmt.Errorf("schema ID must end with '~': %s", schemaID)
+	}
+	if s.getExact(schemaGtsID.ID) == nil {
+		return "", &StoreGtsSchemaNotFoundError{EntityID: schemaGtsID.ID}
+	}
+
+	tokens := []struct {
+		name  string
+		value string
+	}{
+		{"vendor", vendor},
+		{"package", pkg},
+		{"namespace", namespace},
+		{"type", typeName},
+	}
+	for _, tok := range tokens {
+		if !segmentTokenRegex.MatchString(tok.value) {
+			return "", fmt.Errorf("invalid %s token '%s': must start with a lowercase letter or '_' and contain only lowercase letters, digits and '_'", tok.name, tok.value)
+		}
+	}
+	if options.major < 0 {
+		return "", fmt.Errorf("major version must be non-negative, got %d", options.major)
+	}
+
+	prefix := fmt.Sprintf("%s%s.%s.%s.%s.v%d", schemaGtsID.ID, vendor, pkg, namespace, typeName, options.major)
+
+	s.allocMu.Lock()
+	defer s.allocMu.Unlock()
+
+	now := s.clock()
+	s.expireReservations(now)
+
+	next := 0
+	consider := func(id string) {
+		if minor, ok := allocatedMinor(id, prefix); ok && minor >= next {
+			next = minor + 1
+		}
+	}
+	s.mu.RLock()
+	for id := range s.byID {
+		consider(id)
+	}
+	s.mu.RUnlock()
+	for id := range s.reservations {
+		consider(id)
+	}
+
+	id := prefix + "." + strconv.Itoa(next)
+	if _, err := NewGtsID(id); err != nil {
+		return "", err
+	}
+
+	if options.reserveTTL > 0 {
+		if s.reservations == nil {
+			s.reservations = make(map[string]time.Time)
+		}
+		s.reservations[id] = now.Add(options.reserveTTL)
+	}
+	return id, nil
+}
+
+// allocatedMinor returns the minor version of id if it is <prefix>.<minor>
+func allocatedMinor(id, prefix string) (int, bool) {
+	rest, ok := strings.CutPrefix(id, prefix+".")
+	if !ok || rest == "" {
+		return 0, false
+	}
+	for _, r := range rest {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	minor, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false
+	}
+	return minor, true
+}
+
+// expireReservations drops reservations whose TTL has passed; callers must hold allocMu
+func (s *GtsStore) expireReservations(now time.Time) {
+	for id, expiry := range s.reservations {
+		if !now.Before(expiry) {
+			delete(s.reservations, id)
+		}
+	}
+}
+
+// releaseReservation drops the reservation of an ID once it has been registered
+func (s *GtsStore) releaseReservation(id string) {
+	s.allocMu.Lock()
+	delete(s.reservations, id)
+	s.allocMu.Unlock()
+}
+
+// clock returns the current time, allowing tests to control reservation expiry
+func (s *GtsStore) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const allocTestSchemaID = "gts.x.core.events.type.v1~"
+
+// setupAllocateTestStore creates a store holding the base event schema and two instances under it
+func setupAllocateTestStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+
+	for _, content := range []map[string]any{
+		{
+			"$id":     "gts://" + allocTestSchemaID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		},
+		{"id": allocTestSchemaID + "acme.app._.order_evt.v1.0"},
+		{"id": allocTestSchemaID + "acme.app._.order_evt.v1.4"},
+	} {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+	}
+	return store
+}
+
+func TestAllocateInstanceID_NextMinor(t *testing.T) {
+	store := setupAllocateTestStore(t)
+
+	id, err := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != allocTestSchemaID+"acme.app._.order_evt.v1.5" {
+		t.Errorf("Expected next minor after highest registered, got %s", id)
+	}
+
+	// A fresh prefix starts at minor 0
+	id, err = store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt", WithMajorVersion(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != allocTestSchemaID+"acme.app._.order_evt.v2.0" {
+		t.Errorf("Expected first minor under new major, got %s", id)
+	}
+}
+
+func TestAllocateInstanceID_InvalidInput(t *testing.T) {
+	store := setupAllocateTestStore(t)
+
+	tests := []struct {
+		name      string
+		schemaID  string
+		vendor    string
+		typeName  string
+		errSubstr string
+	}{
+		{"invalid vendor token", allocTestSchemaID, "Acme", "order_evt", "invalid vendor token"},
+		{"invalid type token", allocTestSchemaID, "acme", "order-evt", "invalid type token"},
+		{"empty vendor token", allocTestSchemaID, "", "order_evt", "invalid vendor token"},
+		{"schema is not a type", allocTestSchemaID + "acme.app._.order_evt.v1.0", "acme", "order_evt", "must end with '~'"},
+		{"unknown schema", "gts.x.core.events.missing.v1~", "acme", "order_evt", "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.AllocateInstanceID(tt.schemaID, tt.vendor, "app", "_", tt.typeName)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errSubstr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestAllocateInstanceID_ConcurrentReservations(t *testing.T) {
+	store := setupAllocateTestStore(t)
+
+	const workers = 32
+	ids := make([]string, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id, err := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt", WithReservation(time.Minute))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			ids[i] = id
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("Duplicate ID allocated: %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestAllocateInstanceID_ReservationExpiry(t *testing.T) {
+	store := setupAllocateTestStore(t)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	first, err := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt", WithReservation(time.Second))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, _ := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt")
+	if second == first {
+		t.Fatalf("Expected reserved ID %s to be skipped", first)
+	}
+
+	// Once the reservation expires the ID becomes available again
+	now = now.Add(2 * time.Second)
+	third, _ := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt")
+	if third != first {
+		t.Errorf("Expected expired reservation %s to be reused, got %s", first, third)
+	}
+}
+
+func TestAllocateInstanceID_RegisterReleasesReservation(t *testing.T) {
+	store := setupAllocateTestStore(t)
+
+	id, err := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt", WithReservation(time.Minute))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Register(NewJsonEntity(map[string]any{"id": id}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register allocated ID: %v", err)
+	}
+	if _, reserved := store.reservations[id]; reserved {
+		t.Error("Expected reservation to be released after registration")
+	}
+
+	next, _ := store.AllocateInstanceID(allocTestSchemaID, "acme", "app", "_", "order_evt")
+	if next == id {
+		t.Errorf("Expected registered ID %s not to be allocated again", id)
+	}
+}
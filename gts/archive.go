@@ -0,0 +1,293 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// entityFileExtensions are the file extensions read as JSON entity documents
+var entityFileExtensions = map[string]bool{
+	".json":  true,
+	".jsonc": true,
+	".gts":   true,
+}
+
+// EntityDocument is a JSON document read from an archive member, with the entities it holds
+type EntityDocument struct {
+	// Path is the member path, prefixed with the paths of the archives containing it
+	Path     string
+	Entities []*JsonEntity
+	// Skipped counts the objects of the document that carry no GTS ID
+	Skipped int
+	// Err is set when the document or nested archive could not be read
+	Err error
+}
+
+// ArchiveMemberTooLargeError is returned when an archive member exceeds the member size limit
+type ArchiveMemberTooLargeError struct {
+	Path  string
+	Size  uint64
+	Limit int64
+}
+
+func (e *ArchiveMemberTooLargeError) Error() string {
+	return fmt.Sprintf("Archive member '%s' is %d bytes, exceeding the limit of %d bytes", e.Path, e.Size, e.Limit)
+}
+
+// ParseEntityDocument parses a JSON document holding a single object or an array of objects, the
+// way GtsFileReader parses files: array items keep their list sequence and entities are labelled
+// after name. It returns the entities with a GTS ID and the number of objects without one.
+func ParseEntityDocument(name string, data []byte, cfg *GtsConfig) ([]*JsonEntity, int, error) {
+	return parseEntityDocument(&JsonFile{Path: name, Name: filepath.Base(name)}, data, cfg, false)
+}
+
+// ParseCandidateDocument parses a JSON document as ParseEntityDocument does, but returns every
+// object, including those without a GTS ID such as instances identified by a UUID, for validation
+// with GtsStore.ValidateEntity without registering them
+func ParseCandidateDocument(name string, data []byte, cfg *GtsConfig) ([]*JsonEntity, error) {
+	entities, _, err := parseEntityDocument(&JsonFile{Path: name, Name: filepath.Base(name)}, data, cfg, true)
+	return entities, err
+}
+
+// parseEntityDocument parses data into the entities of jsonFile, whose Path and Name are set by the
+// caller; objects without a GTS ID are counted as skipped unless keepAll is set
+func parseEntityDocument(jsonFile *JsonFile, data []byte, cfg *GtsConfig, keepAll bool) ([]*JsonEntity, int, error) {
+	var content any
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, 0, err
+	}
+	jsonFile.Content = content
+
+	var positions PositionIndex
+	if cfg != nil && cfg.RecordPositions {
+		var err error
+		if positions, err = buildPositionIndex(data); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var entities []*JsonEntity
+	skipped := 0
+	add := func(item map[string]any, seq *int) {
+		entity := NewJsonEntityWithFile(item, cfg, jsonFile, seq)
+		if positions != nil {
+			// Array items keep positions relative to the file, keyed from the item
+			if seq != nil {
+				entity.PositionIndex = positions.subIndex("/" + strconv.Itoa(*seq))
+			} else {
+				entity.PositionIndex = positions
+			}
+		}
+		if entity.GtsID != nil || keepAll {
+			entities = append(entities, entity)
+		} else {
+			skipped++
+		}
+	}
+
+	// Handle both single objects and arrays
+	switch v := content.(type) {
+	case []any:
+		for idx, item := range v {
+			if itemMap, ok := item.(map[string]any); ok {
+				add(itemMap, &idx)
+			}
+		}
+	case map[string]any:
+		add(v, nil)
+	default:
+		return nil, 0, fmt.Errorf("expected a JSON object or array, got %T", content)
+	}
+	return entities, skipped, nil
+}
+
+// IsEntityFile reports whether a file name has one of the extensions read as entity documents
+func IsEntityFile(name string) bool {
+	return entityFileExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// isExcludedPath reports whether any directory of a slash-separated path is excluded by cfg
+func isExcludedPath(p string, cfg *GtsConfig) bool {
+	dirs := strings.Split(path.Dir(p), "/")
+	return slices.ContainsFunc(dirs, func(dir string) bool {
+		return slices.Contains(cfg.excludeDirs(), dir)
+	})
+}
+
+// DefaultMaxArchiveDepth is the number of nested archive levels ReadEntityArchive reads
+const DefaultMaxArchiveDepth = 4
+
+// ArchiveLimits bounds what ReadEntityArchiveWithLimits inflates; zero values mean no limit
+type ArchiveLimits struct {
+	// MaxMemberSize is the maximum uncompressed size in bytes of a member
+	MaxMemberSize int64
+	// MaxTotalSize is the maximum number of bytes inflated from the archive, nested archives included
+	MaxTotalSize int64
+	// MaxDepth is the maximum number of nested archive levels below the archive itself
+	MaxDepth int
+}
+
+// ArchiveTooLargeError is returned when the members of an archive inflate to more than the total size limit
+type ArchiveTooLargeError struct {
+	Path  string
+	Size  int64
+	Limit int64
+}
+
+func (e *ArchiveTooLargeError) Error() string {
+	return fmt.Sprintf("Archive '%s' inflates to more than %d bytes (%d bytes read)", e.Path, e.Limit, e.Size)
+}
+
+// ArchiveNestingTooDeepError is returned when archives are nested deeper than the depth limit
+type ArchiveNestingTooDeepError struct {
+	Path  string
+	Limit int
+}
+
+func (e *ArchiveNestingTooDeepError) Error() string {
+	return fmt.Sprintf("Archive '%s' is nested more than %d levels deep", e.Path, e.Limit)
+}
+
+// ReadEntityArchive reads the entity documents of a zip archive with a member size limit and at most
+// DefaultMaxArchiveDepth levels of nested archives, see ReadEntityArchiveWithLimits
+func ReadEntityArchive(name string, data []byte, maxMemberSize int64, cfg *GtsConfig) ([]EntityDocument, error) {
+	return ReadEntityArchiveWithLimits(name, data, ArchiveLimits{MaxMemberSize: maxMemberSize, MaxDepth: DefaultMaxArchiveDepth}, cfg)
+}
+
+// ReadEntityArchiveWithLimits reads the entity documents of a zip archive. Members are selected like
+// the files of a directory given to GtsFileReader: JSON extensions only, skipping the directories of
+// cfg.ExcludeDirs. Nested .zip members are read recursively. Entities are labelled after their member
+// path inside the archive. A member that cannot be read is reported in its document's Err without
+// stopping the rest. Exceeding a limit fails the whole archive: a member larger than MaxMemberSize with
+// an ArchiveMemberTooLargeError, more than MaxTotalSize inflated bytes with an ArchiveTooLargeError and
+// archives nested more than MaxDepth levels with an ArchiveNestingTooDeepError.
+func ReadEntityArchiveWithLimits(name string, data []byte, limits ArchiveLimits, cfg *GtsConfig) ([]EntityDocument, error) {
+	if cfg == nil {
+		cfg = DefaultGtsConfig()
+	}
+	reader := &archiveReader{limits: limits, cfg: cfg, root: name}
+	return reader.read(name, data, 0)
+}
+
+// IsArchiveLimitError reports whether err is one of the errors failing a whole archive for exceeding
+// its limits
+func IsArchiveLimitError(err error) bool {
+	var (
+		memberErr *ArchiveMemberTooLargeError
+		totalErr  *ArchiveTooLargeError
+		depthErr  *ArchiveNestingTooDeepError
+	)
+	return errors.As(err, &memberErr) || errors.As(err, &totalErr) || errors.As(err, &depthErr)
+}
+
+// archiveReader reads an archive and its nested archives, counting the bytes inflated from all of them
+type archiveReader struct {
+	limits ArchiveLimits
+	cfg    *GtsConfig
+	root   string
+	total  int64
+}
+
+// read reads the archive data found at name, depth levels below the root archive
+func (r *archiveReader) read(name string, data []byte, depth int) ([]EntityDocument, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive %s: %w", name, err)
+	}
+
+	maxMemberSize := r.limits.MaxMemberSize
+	var documents []EntityDocument
+	for _, member := range archive.File {
+		if member.FileInfo().IsDir() || isExcludedPath(member.Name, r.cfg) {
+			continue
+		}
+		isArchive := strings.EqualFold(path.Ext(member.Name), ".zip")
+		if !isArchive && !IsEntityFile(member.Name) {
+			continue
+		}
+
+		memberPath := name + "/" + member.Name
+		if isArchive && r.limits.MaxDepth > 0 && depth >= r.limits.MaxDepth {
+			return nil, &ArchiveNestingTooDeepError{Path: memberPath, Limit: r.limits.MaxDepth}
+		}
+		if maxMemberSize > 0 && member.UncompressedSize64 > uint64(maxMemberSize) {
+			return nil, &ArchiveMemberTooLargeError{Path: memberPath, Size: member.UncompressedSize64, Limit: maxMemberSize}
+		}
+		content, err := r.readMember(member)
+		if IsArchiveLimitError(err) {
+			var tooLarge *ArchiveMemberTooLargeError
+			if errors.As(err, &tooLarge) {
+				tooLarge.Path = memberPath
+			}
+			return nil, err
+		}
+		if err != nil {
+			documents = append(documents, EntityDocument{Path: memberPath, Err: err})
+			continue
+		}
+
+		if isArchive {
+			nested, err := r.read(memberPath, content, depth+1)
+			if IsArchiveLimitError(err) {
+				return nil, err
+			}
+			if err != nil {
+				documents = append(documents, EntityDocument{Path: memberPath, Err: err})
+				continue
+			}
+			documents = append(documents, nested...)
+			continue
+		}
+
+		// Label entities after the member path inside the archive rather than its base name
+		entities, skipped, err := parseEntityDocument(&JsonFile{Path: memberPath, Name: member.Name}, content, r.cfg, false)
+		documents = append(documents, EntityDocument{Path: memberPath, Entities: entities, Skipped: skipped, Err: err})
+	}
+	return documents, nil
+}
+
+// readMember reads a member, refusing to inflate more than the member size limit or the bytes left
+// under the total size limit. The declared uncompressed size is not trusted.
+func (r *archiveReader) readMember(member *zip.File) ([]byte, error) {
+	rc, err := member.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	maxMemberSize, maxTotalSize := r.limits.MaxMemberSize, r.limits.MaxTotalSize
+	readLimit := maxMemberSize
+	if remaining := maxTotalSize - r.total; maxTotalSize > 0 && (readLimit <= 0 || remaining < readLimit) {
+		readLimit = remaining
+	}
+	var reader io.Reader = rc
+	if readLimit > 0 || maxTotalSize > 0 {
+		reader = io.LimitReader(rc, readLimit+1)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if maxMemberSize > 0 && int64(len(content)) > maxMemberSize {
+		return nil, &ArchiveMemberTooLargeError{Path: member.Name, Size: uint64(len(content)), Limit: maxMemberSize}
+	}
+	r.total += int64(len(content))
+	if maxTotalSize > 0 && r.total > maxTotalSize {
+		return nil, &ArchiveTooLargeError{Path: r.root, Size: r.total, Limit: maxTotalSize}
+	}
+	return content, nil
+}
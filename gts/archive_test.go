@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// buildZip returns a zip archive holding the given member contents
+func buildZip(t *testing.T, members map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range members {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip member: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write zip member: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadEntityArchive(t *testing.T) {
+	nested := buildZip(t, map[string]string{
+		"inner/c.json": `{"id": "gts.x.test.archive.item.v1~x.test._.c.v1"}`,
+	})
+	data := buildZip(t, map[string]string{
+		"a/b/items.json":           `[{"id": "gts.x.test.archive.item.v1~x.test._.a.v1"}, {"name": "no id"}, {"id": "gts.x.test.archive.item.v1~x.test._.b.v1"}]`,
+		"a/broken.json":            `{"id": `,
+		"a/node_modules/skip.json": `{"id": "gts.x.test.archive.item.v1~x.test._.skipped.v1"}`,
+		"a/readme.txt":             `not an entity`,
+		"nested.zip":               string(nested),
+	})
+
+	documents, err := ReadEntityArchive("upload.zip", data, 0, nil)
+	if err != nil {
+		t.Fatalf("ReadEntityArchive failed: %v", err)
+	}
+	byPath := make(map[string]EntityDocument)
+	for _, doc := range documents {
+		byPath[doc.Path] = doc
+	}
+	if len(byPath) != 3 {
+		t.Fatalf("Expected 3 documents, got %v", byPath)
+	}
+
+	items := byPath["upload.zip/a/b/items.json"]
+	if items.Err != nil || len(items.Entities) != 2 || items.Skipped != 1 {
+		t.Fatalf("Unexpected items document: %+v", items)
+	}
+	if label := items.Entities[1].Label; label != "a/b/items.json#2" {
+		t.Errorf("Expected label after the archive path, got %s", label)
+	}
+	if byPath["upload.zip/a/broken.json"].Err == nil {
+		t.Error("Expected an error for the malformed member")
+	}
+	inner := byPath["upload.zip/nested.zip/inner/c.json"]
+	if inner.Err != nil || len(inner.Entities) != 1 || inner.Entities[0].GtsID.ID != "gts.x.test.archive.item.v1~x.test._.c.v1" {
+		t.Errorf("Unexpected nested document: %+v", inner)
+	}
+}
+
+func TestReadEntityArchive_MemberTooLarge(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"big.json": `{"id": "gts.x.test.archive.item.v1~x.test._.big.v1", "padding": "0123456789"}`,
+	})
+	var tooLarge *ArchiveMemberTooLargeError
+	if _, err := ReadEntityArchive("upload.zip", data, 16, nil); !errors.As(err, &tooLarge) || tooLarge.Path != "upload.zip/big.json" {
+		t.Errorf("Expected member too large error, got %v", err)
+	}
+}
+
+func TestReadEntityArchive_TotalTooLarge(t *testing.T) {
+	// Each member is under the member limit, but together with the nested archive they inflate past the total
+	member := `{"id": "gts.x.test.archive.item.v1~x.test._.a.v1", "padding": "0123456789"}`
+	nested := buildZip(t, map[string]string{"inner/b.json": member})
+	data := buildZip(t, map[string]string{"a.json": member, "nested.zip": string(nested)})
+
+	limits := ArchiveLimits{MaxMemberSize: 1 << 10, MaxTotalSize: int64(len(nested) + len(member)), MaxDepth: DefaultMaxArchiveDepth}
+	var tooLarge *ArchiveTooLargeError
+	if _, err := ReadEntityArchiveWithLimits("upload.zip", data, limits, nil); !errors.As(err, &tooLarge) || tooLarge.Path != "upload.zip" {
+		t.Errorf("Expected archive too large error, got %v", err)
+	}
+
+	limits.MaxTotalSize += int64(len(member))
+	if documents, err := ReadEntityArchiveWithLimits("upload.zip", data, limits, nil); err != nil || len(documents) != 2 {
+		t.Errorf("Expected 2 documents within the total limit, got %v, %v", documents, err)
+	}
+}
+
+func TestReadEntityArchive_NestingTooDeep(t *testing.T) {
+	data := buildZip(t, map[string]string{"c.json": `{"id": "gts.x.test.archive.item.v1~x.test._.c.v1"}`})
+	for _, name := range []string{"level2.zip", "level1.zip"} {
+		data = buildZip(t, map[string]string{name: string(data)})
+	}
+
+	var tooDeep *ArchiveNestingTooDeepError
+	if _, err := ReadEntityArchiveWithLimits("upload.zip", data, ArchiveLimits{MaxDepth: 1}, nil); !errors.As(err, &tooDeep) || tooDeep.Path != "upload.zip/level1.zip/level2.zip" {
+		t.Errorf("Expected nesting too deep error, got %v", err)
+	}
+	documents, err := ReadEntityArchiveWithLimits("upload.zip", data, ArchiveLimits{MaxDepth: 2}, nil)
+	if err != nil || len(documents) != 1 || documents[0].Path != "upload.zip/level1.zip/level2.zip/c.json" {
+		t.Errorf("Expected the nested document within the depth limit, got %v, %v", documents, err)
+	}
+}
@@ -7,6 +7,7 @@ package gts
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -19,10 +20,18 @@ type AttributeResult struct {
 	Resolved        bool     `json:"resolved"`
 	Error           string   `json:"error,omitempty"`
 	AvailableFields []string `json:"available_fields,omitempty"`
+	// ErrorCode is the stable cause of Error, one of the ErrorCode constants
+	ErrorCode string `json:"error_code,omitempty"`
+	// Collected is set when the path has a [*] segment: Value is the array of the values collected
+	// from the selected elements, which is empty when there are none
+	Collected bool `json:"collected,omitempty"`
+	// Err is set when the entity does not exist, as opposed to a path that does not resolve
+	Err error `json:"-"`
 }
 
 // GetAttribute retrieves an attribute value from an entity using a path selector
-// Format: "gts_id@path.to.field" or "gts_id@array[0].field"
+// Format: "gts_id@path.to.field", "gts_id@array[0].field", "gts_id@array[-1]" for the last element
+// or "gts_id@array[*].field" for the field of every element, see resolveAttributePath
 // see gts-python ops.py attr method
 func (s *GtsStore) GetAttribute(gtsWithPath string) *AttributeResult {
 	// Split GTS ID from attribute path
@@ -31,10 +40,11 @@ func (s *GtsStore) GetAttribute(gtsWithPath string) *AttributeResult {
 	// Check if @ symbol was provided
 	if path == "" {
 		return &AttributeResult{
-			GtsID:    gtsID,
-			Path:     "",
-			Resolved: false,
-			Error:    "Attribute selector requires '@path' in the identifier",
+			GtsID:     gtsID,
+			Path:      "",
+			Resolved:  false,
+			Error:     "Attribute selector requires '@path' in the identifier",
+			ErrorCode: ErrorCodeAttrPathInvalid,
 		}
 	}
 
@@ -42,10 +52,12 @@ func (s *GtsStore) GetAttribute(gtsWithPath string) *AttributeResult {
 	entity := s.Get(gtsID)
 	if entity == nil {
 		return &AttributeResult{
-			GtsID:    gtsID,
-			Path:     path,
-			Resolved: false,
-			Error:    fmt.Sprintf("Entity not found: %s", gtsID),
+			GtsID:     gtsID,
+			Path:      path,
+			Resolved:  false,
+			Error:     fmt.Sprintf("Entity not found: %s", gtsID),
+			ErrorCode: ErrorCodeEntityNotFound,
+			Err:       &StoreGtsObjectNotFoundError{EntityID: gtsID},
 		}
 	}
 
@@ -53,6 +65,37 @@ func (s *GtsStore) GetAttribute(gtsWithPath string) *AttributeResult {
 	return resolveAttributePath(gtsID, path, entity.Content)
 }
 
+// PartialResult holds the values of selected attribute paths of an entity
+type PartialResult struct {
+	ID     string         `json:"id"`
+	Values map[string]any `json:"values"`
+	// Missing lists the paths that do not resolve, in request order
+	Missing []string `json:"missing"`
+}
+
+// GetPartial resolves attribute paths (as in GetAttribute, e.g. "properties.name" or
+// "required[0]") against the content of an entity, so that clients can read part of a large
+// entity. Paths that do not resolve are listed in Missing; an unknown entity is an error.
+func (s *GtsStore) GetPartial(id string, paths []string) (*PartialResult, error) {
+	entity := s.Get(id)
+	if entity == nil {
+		return nil, &StoreGtsObjectNotFoundError{EntityID: id}
+	}
+
+	result := &PartialResult{ID: entity.GtsID.ID, Values: make(map[string]any), Missing: []string{}}
+	for _, path := range paths {
+		if _, done := result.Values[path]; done || slices.Contains(result.Missing, path) {
+			continue
+		}
+		if attr := resolveAttributePath(id, path, entity.Content); attr.Resolved {
+			result.Values[path] = attr.Value
+		} else {
+			result.Missing = append(result.Missing, path)
+		}
+	}
+	return result, nil
+}
+
 // splitAtPath splits a GTS ID with path into GTS ID and attribute path
 // see gts-python gts.py GtsID.split_at_path method
 func splitAtPath(gtsWithPath string) (string, string) {
@@ -70,7 +113,10 @@ func splitAtPath(gtsWithPath string) (string, string) {
 	return gtsID, path
 }
 
-// resolveAttributePath resolves an attribute path in content
+// resolveAttributePath resolves an attribute path in content. A [*] segment selects every element
+// of an array: the rest of the path is resolved in each element and the values are collected into
+// an array, those of nested [*] segments flattened into it. Elements in which the rest does not
+// resolve are skipped, unless none resolves. Negative indices count from the end of an array.
 // see gts-python path_resolver.py JsonPathResolver.resolve method
 func resolveAttributePath(gtsID, path string, content map[string]any) *AttributeResult {
 	result := &AttributeResult{
@@ -80,79 +126,151 @@ func resolveAttributePath(gtsID, path string, content map[string]any) *Attribute
 		AvailableFields: []string{},
 	}
 
-	// Parse path into parts
-	parts := parsePath(path)
+	value, collected, failure := resolvePathParts(content, parsePath(path), path)
+	if failure != nil {
+		result.Error = failure.message
+		result.ErrorCode = failure.code
+		if failure.availableFields != nil {
+			result.AvailableFields = failure.availableFields
+		}
+		return result
+	}
+
+	// Successfully resolved
+	result.Value = value
+	result.Resolved = true
+	result.Collected = collected
+	return result
+}
+
+// pathFailure describes why an attribute path does not resolve
+type pathFailure struct {
+	message         string
+	code            string
+	availableFields []string
+}
 
-	// Traverse content following the path
-	var current any = content
-	for _, part := range parts {
+// resolvePathParts follows parts from current, reporting whether the value was collected by a
+// [*] segment
+func resolvePathParts(current any, parts []string, path string) (any, bool, *pathFailure) {
+	for i, part := range parts {
 		switch node := current.(type) {
 		case map[string]any:
 			// Expect field name, not array index
 			if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
-				result.Error = fmt.Sprintf("Path not found at segment '%s' in '%s', see available fields", part, path)
-				result.AvailableFields = collectAvailableFields(node, "")
-				return result
+				return nil, false, &pathFailure{
+					message:         fmt.Sprintf("Path not found at segment '%s' in '%s', see available fields", part, path),
+					code:            ErrorCodeAttrPathInvalid,
+					availableFields: collectAvailableFields(node, ""),
+				}
 			}
 
 			// Check if field exists
 			val, exists := node[part]
 			if !exists {
-				result.Error = fmt.Sprintf("Path not found at segment '%s' in '%s', see available fields", part, path)
-				result.AvailableFields = collectAvailableFields(node, "")
-				return result
+				return nil, false, &pathFailure{
+					message:         fmt.Sprintf("Path not found at segment '%s' in '%s', see available fields", part, path),
+					code:            ErrorCodeAttrNotFound,
+					availableFields: collectAvailableFields(node, ""),
+				}
 			}
 
 			current = val
 
 		case []any:
-			// Expect array index
-			var idx int
-			var err error
+			if part == "[*]" {
+				return collectPathParts(node, parts[i+1:], path)
+			}
 
-			if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
-				// Parse [N] format
-				idxStr := part[1 : len(part)-1]
-				idx, err = strconv.Atoi(idxStr)
-				if err != nil {
-					result.Error = fmt.Sprintf("Expected list index at segment '%s'", part)
-					result.AvailableFields = collectAvailableFieldsFromArray(node, "")
-					return result
-				}
-			} else {
-				// Try to parse as integer
-				idx, err = strconv.Atoi(part)
-				if err != nil {
-					result.Error = fmt.Sprintf("Expected list index at segment '%s'", part)
-					result.AvailableFields = collectAvailableFieldsFromArray(node, "")
-					return result
+			// Expect array index, as [N] or N
+			idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(part, "["), "]"))
+			if err != nil {
+				return nil, false, &pathFailure{
+					message:         fmt.Sprintf("Expected list index at segment '%s'", part),
+					code:            ErrorCodeAttrPathInvalid,
+					availableFields: collectAvailableFieldsFromArray(node, ""),
 				}
 			}
+			if idx < 0 {
+				idx += len(node)
+			}
 
 			// Check bounds
 			if idx < 0 || idx >= len(node) {
-				result.Error = fmt.Sprintf("Index out of range at segment '%s'", part)
-				result.AvailableFields = collectAvailableFieldsFromArray(node, "")
-				return result
+				return nil, false, &pathFailure{
+					message:         fmt.Sprintf("Index out of range at segment '%s'", part),
+					code:            ErrorCodeAttrIndexOutOfRange,
+					availableFields: collectAvailableFieldsFromArray(node, ""),
+				}
 			}
 
 			current = node[idx]
 
 		default:
-			result.Error = fmt.Sprintf("Cannot descend into %T at segment '%s'", current, part)
-			return result
+			return nil, false, &pathFailure{message: fmt.Sprintf("Cannot descend into %T at segment '%s'", current, part), code: ErrorCodeAttrNotFound}
 		}
 	}
+	return current, false, nil
+}
 
-	// Successfully resolved
-	result.Value = current
-	result.Resolved = true
-	return result
+// collectPathParts resolves parts in every element of node for a [*] segment. Over an empty array
+// the result is an empty array; when no element resolves, the failure of the first one is returned.
+func collectPathParts(node []any, parts []string, path string) (any, bool, *pathFailure) {
+	values := []any{}
+	var firstFailure *pathFailure
+	for _, elem := range node {
+		value, collected, failure := resolvePathParts(elem, parts, path)
+		if failure != nil {
+			if firstFailure == nil {
+				firstFailure = failure
+			}
+			continue
+		}
+		if nested, ok := value.([]any); collected && ok {
+			values = append(values, nested...)
+		} else {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 && firstFailure != nil {
+		return nil, false, firstFailure
+	}
+	return values, true, nil
 }
 
-// parsePath parses an attribute path into parts, handling array indices
+// parsePath parses an attribute path into parts, handling array indices and quoted keys
+// (["a.b"] or ['a.b']), which select a field whose name contains separators or brackets
 // see gts-python path_resolver.py JsonPathResolver._parts method
 func parsePath(path string) []string {
+	parts := []string{}
+	for {
+		start, key, end := findQuotedPathKey(path)
+		if start < 0 {
+			return append(parts, parseUnquotedPath(path)...)
+		}
+		parts = append(parts, parseUnquotedPath(path[:start])...)
+		parts = append(parts, key)
+		path = path[end:]
+	}
+}
+
+// findQuotedPathKey finds the first quoted key in path, returning where it starts, the unquoted
+// key and where it ends, or a negative start when path has none
+func findQuotedPathKey(path string) (int, string, int) {
+	for i := 0; i+1 < len(path); i++ {
+		if path[i] != '[' || (path[i+1] != '"' && path[i+1] != '\'') {
+			continue
+		}
+		closing := string(path[i+1]) + "]"
+		if j := strings.Index(path[i+2:], closing); j >= 0 {
+			return i, path[i+2 : i+2+j], i + 2 + j + len(closing)
+		}
+	}
+	return -1, "", 0
+}
+
+// parseUnquotedPath parses a path without quoted keys into parts
+func parseUnquotedPath(path string) []string {
 	// Normalize path (replace / with .)
 	normalized := strings.ReplaceAll(path, "/", ".")
 
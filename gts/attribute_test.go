@@ -6,6 +6,7 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -430,3 +431,104 @@ func TestGetAttribute_PathNormalizationWithSlashes(t *testing.T) {
 		t.Errorf("Expected value 'test-value', got: %v", result.Value)
 	}
 }
+
+func TestGetPartial(t *testing.T) {
+	store := NewGtsStore(nil)
+	const id = "gts.x.test11.events.type.v1~x.test11._.partial.v1"
+	store.Register(NewJsonEntity(map[string]any{
+		"id":      id,
+		"payload": map[string]any{"items": []any{map[string]any{"sku": "A-1"}}, "note": nil},
+	}, DefaultGtsConfig()))
+
+	result, err := store.GetPartial(id, []string{"payload.items[0].sku", "payload.note", "payload.items[3]", "payload.note"})
+	if err != nil {
+		t.Fatalf("GetPartial failed: %v", err)
+	}
+	if len(result.Values) != 2 || result.Values["payload.items[0].sku"] != "A-1" {
+		t.Errorf("Expected the sku and the null note, got %v", result.Values)
+	}
+	if note, ok := result.Values["payload.note"]; !ok || note != nil {
+		t.Errorf("Expected a null value to be returned rather than missing, got %v", result.Values)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "payload.items[3]" {
+		t.Errorf("Expected payload.items[3] to be missing, got %v", result.Missing)
+	}
+
+	if _, err := store.GetPartial("gts.x.test11.events.type.v1~x.test11._.unknown.v1", []string{"id"}); err == nil {
+		t.Error("Expected an unknown entity to fail")
+	}
+}
+
+func TestGetAttribute_WildcardAndNegativeIndex(t *testing.T) {
+	store := NewGtsStore(nil)
+	const id = "gts.x.test11.events.type.v1~x.test11.wild.event.v1.0"
+	store.Register(NewJsonEntity(map[string]any{
+		"gtsId": id,
+		"items": []any{
+			map[string]any{"sku": "a", "tags": []any{"x", "y"}},
+			map[string]any{"sku": "b", "tags": []any{}},
+			map[string]any{"tags": []any{"z"}},
+		},
+		"empty": []any{},
+	}, DefaultGtsConfig()))
+
+	tests := []struct {
+		path      string
+		value     any
+		collected bool
+		resolved  bool
+	}{
+		{"items[*].sku", []any{"a", "b"}, true, true},
+		{"items[*].tags[*]", []any{"x", "y", "z"}, true, true},
+		{"items[*].tags", []any{[]any{"x", "y"}, []any{}, []any{"z"}}, true, true},
+		{"empty[*].sku", []any{}, true, true},
+		{"items[-1].tags[0]", "z", false, true},
+		{"items[-3].sku", "a", false, true},
+		{"items[-4]", nil, false, false},
+		{"items[3]", nil, false, false},
+		{"items[*].missing", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := store.GetAttribute(id + "@" + tt.path)
+			if result.Resolved != tt.resolved || result.Collected != tt.collected {
+				t.Fatalf("Expected resolved=%t collected=%t, got %+v", tt.resolved, tt.collected, result)
+			}
+			if !reflect.DeepEqual(result.Value, tt.value) {
+				t.Errorf("Expected value %#v, got %#v", tt.value, result.Value)
+			}
+		})
+	}
+}
+
+func TestGetAttribute_ErrorCodes(t *testing.T) {
+	store := NewGtsStore(nil)
+	const id = "gts.x.test11.events.type.v1~x.test11.codes.event.v1.0"
+	store.Register(NewJsonEntity(map[string]any{
+		"gtsId": id,
+		"name":  "n",
+		"items": []any{map[string]any{"sku": "a"}},
+	}, DefaultGtsConfig()))
+
+	tests := []struct {
+		selector string
+		code     string
+	}{
+		{id + "@name", ""},
+		{id, ErrorCodeAttrPathInvalid},
+		{id + "@[0]", ErrorCodeAttrPathInvalid},
+		{id + "@items[x]", ErrorCodeAttrPathInvalid},
+		{id + "@missing", ErrorCodeAttrNotFound},
+		{id + "@name.first", ErrorCodeAttrNotFound},
+		{id + "@items[1]", ErrorCodeAttrIndexOutOfRange},
+		{id + "@items[*].missing", ErrorCodeAttrNotFound},
+		{"gts.x.test11.events.type.v1~x.test11.codes.missing.v1.0@name", ErrorCodeEntityNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			if result := store.GetAttribute(tt.selector); result.ErrorCode != tt.code {
+				t.Errorf("Expected error code %q, got %q (%s)", tt.code, result.ErrorCode, result.Error)
+			}
+		})
+	}
+}
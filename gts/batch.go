@@ -0,0 +1,217 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"log"
+)
+
+// BatchEntityResult is the outcome of registering one entity of a batch
+type BatchEntityResult struct {
+	// Index is the position of the entity in the batch
+	Index int    `json:"index"`
+	ID    string `json:"gts_id,omitempty"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// Err is the error behind Error, for callers inspecting its type
+	Err error `json:"-"`
+}
+
+// BatchResult reports the registration of a batch of entities
+type BatchResult struct {
+	Atomic bool `json:"atomic"`
+	// Committed is set when the entities that passed were stored; an atomic batch commits all or none
+	Committed  bool                `json:"committed"`
+	Registered int                 `json:"registered"`
+	Failed     int                 `json:"failed"`
+	Results    []BatchEntityResult `json:"results"`
+	// Order lists the batch indices in registration order, with BatchOptions.DependencyOrder
+	Order []int `json:"order,omitempty"`
+}
+
+// BatchOptions controls RegisterAllWithOptions
+type BatchOptions struct {
+	// Atomic commits every entity of the batch or none of them
+	Atomic bool
+	// DependencyOrder registers the entities in DependencyOrder instead of batch order, so that a
+	// derived schema listed before its base, or an instance before its schema, finds it
+	DependencyOrder bool
+}
+
+// BatchRejectedError is returned when an atomic batch is not committed because entities failed
+type BatchRejectedError struct {
+	Failed int
+	Total  int
+}
+
+func (e *BatchRejectedError) Error() string {
+	return fmt.Sprintf("Atomic batch rejected: %d of %d entities failed, nothing was registered", e.Failed, e.Total)
+}
+
+// RegisterAll registers a batch of entities, see RegisterAllWithOptions
+func (s *GtsStore) RegisterAll(entities []*JsonEntity, atomic bool) (*BatchResult, error) {
+	return s.RegisterAllWithOptions(entities, BatchOptions{Atomic: atomic})
+}
+
+// RegisterAllWithOptions registers a batch of entities. Without Atomic, entities are registered
+// one by one as with Register and failures are reported without affecting the others.
+//
+// With Atomic, the batch is indexed first and every entity is then checked as Register would
+// (ID limits, reference validation, dependents of overwritten schemas), with references resolving
+// against the other entities of the batch as well as the store, so the order of the batch does
+// not matter. Only when every entity passes are they all stored in one locked operation;
+// otherwise nothing is registered and a BatchRejectedError is returned along with the result
+// detailing every failure. An ID appearing twice in an atomic batch is a failure. Schemas of the
+// batch overwriting registered ones are re-validated against the registered instances only.
+//
+// With DependencyOrder, entities are registered, and an atomic batch stored, in DependencyOrder;
+// Results keep batch order either way.
+func (s *GtsStore) RegisterAllWithOptions(entities []*JsonEntity, opts BatchOptions) (*BatchResult, error) {
+	atomic := opts.Atomic
+	result := &BatchResult{Atomic: atomic, Results: make([]BatchEntityResult, len(entities))}
+	fail := func(i int, err error) {
+		result.Results[i].Err = err
+		result.Results[i].Error = err.Error()
+		result.Failed++
+	}
+
+	for i, entity := range entities {
+		result.Results[i].Index = i
+		if entity.GtsID != nil {
+			result.Results[i].ID = entity.GtsID.ID
+		}
+	}
+
+	order := make([]int, len(entities))
+	for i := range order {
+		order[i] = i
+	}
+	if opts.DependencyOrder {
+		order = DependencyOrder(entities)
+		result.Order = order
+	}
+
+	if !atomic {
+		for _, i := range order {
+			entity := entities[i]
+			if err := s.Register(entity); err != nil {
+				fail(i, err)
+				continue
+			}
+			result.Results[i].OK = true
+			result.Registered++
+		}
+		result.Committed = result.Registered > 0
+		return result, nil
+	}
+
+	if s.IsFrozen() {
+		return nil, &StoreFrozenError{Operation: "register batch"}
+	}
+
+	// Index the batch so that references between its entities resolve whatever their order
+	staged := make(map[string]*JsonEntity, len(entities))
+	for i, entity := range entities {
+		if entity.GtsID == nil || entity.GtsID.ID == "" {
+			continue
+		}
+		if _, ok := staged[entity.GtsID.ID]; ok {
+			fail(i, fmt.Errorf("duplicate GTS ID %s in batch", entity.GtsID.ID))
+			continue
+		}
+		staged[entity.GtsID.ID] = entity
+	}
+
+	for i, entity := range entities {
+		if result.Results[i].Err != nil {
+			continue
+		}
+		if entity.GtsID == nil && entity.IDError != nil {
+			fail(i, entity.IDError)
+			continue
+		}
+		if err := s.checkRegistration(entity, staged); err != nil {
+			fail(i, err)
+		}
+	}
+
+	// Short IDs must be unique within the batch too; the check is repeated under the commit lock
+	pending := make(map[string]string, len(entities))
+	s.mu.RLock()
+	for i, entity := range entities {
+		if result.Results[i].Err != nil || entity.GtsID == nil {
+			continue
+		}
+		if err := s.checkShortIDLocked(entity.GtsID.ID, pending); err != nil {
+			fail(i, err)
+			continue
+		}
+		pending[shortIDOf(entity.GtsID.ID)] = entity.GtsID.ID
+	}
+	s.mu.RUnlock()
+	if result.Failed > 0 {
+		return result, &BatchRejectedError{Failed: result.Failed, Total: len(entities)}
+	}
+
+	s.mu.Lock()
+	if s.frozen {
+		s.mu.Unlock()
+		return nil, &StoreFrozenError{Operation: "register batch"}
+	}
+	for _, entity := range entities {
+		if err := s.checkShortIDLocked(entity.GtsID.ID, nil); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+	ordered := make([]*JsonEntity, len(order))
+	for k, i := range order {
+		ordered[k] = entities[i]
+	}
+	if err := s.persistBatchLocked(ordered); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	for _, entity := range ordered {
+		s.putLocked(entity)
+	}
+	s.mu.Unlock()
+
+	for i, entity := range entities {
+		s.releaseReservation(entity.GtsID.ID)
+		result.Results[i].OK = true
+	}
+	result.Registered = len(entities)
+	result.Committed = true
+	log.Printf("Registered batch of %d entities", len(entities))
+	return result, nil
+}
+
+// persistBatchLocked writes the entities of a batch through to the writer, if any. When a write
+// fails the entities already written are rolled back, restoring the entities they replaced, so
+// that the writer keeps matching the store; s.mu must be held for writing.
+func (s *GtsStore) persistBatchLocked(entities []*JsonEntity) error {
+	for i, entity := range entities {
+		err := s.persistLocked(entity)
+		if err == nil {
+			continue
+		}
+		for _, written := range entities[:i] {
+			var rollbackErr error
+			if previous, ok := s.byID[written.GtsID.ID]; ok {
+				rollbackErr = s.persistLocked(previous)
+			} else {
+				rollbackErr = s.unpersistLocked(written.GtsID.ID)
+			}
+			if rollbackErr != nil {
+				log.Printf("ERROR: failed to roll back %s: %v", written.GtsID.ID, rollbackErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
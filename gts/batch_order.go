@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"sort"
+	"strings"
+)
+
+// DependencyOrder returns the indices of a batch of entities in an order that registers every
+// entity after the entities of the batch it references: the schema of an instance, the base of a
+// derived schema and the targets of its $ref, x-gts-ref and other GTS ID values, a schema ID
+// without minor version depending on its minor versions in the batch. Among the entities whose
+// dependencies are met, schemas come before instances, then batch order is kept. Entities of a
+// reference cycle are released one at a time in that same order, so every index is returned once.
+func DependencyOrder(entities []*JsonEntity) []int {
+	n := len(entities)
+	byID := make(map[string][]int, n)
+	for i, entity := range entities {
+		if entity.GtsID != nil {
+			byID[entity.GtsID.ID] = append(byID[entity.GtsID.ID], i)
+		}
+	}
+	// Schema IDs without minor version depend on the minor versions of the batch
+	minors := func(id string) []int {
+		requested, err := NewGtsID(id)
+		if err != nil || !requested.IsType() || requested.LastSegment().VerMinor != nil {
+			return nil
+		}
+		var matches []int
+		for i, entity := range entities {
+			if isMinorVersionOf(entity.GtsID, requested) {
+				matches = append(matches, i)
+			}
+		}
+		return matches
+	}
+
+	dependents := make([][]int, n)
+	pending := make([]int, n)
+	for i, entity := range entities {
+		seen := map[int]bool{i: true}
+		for _, target := range batchDependencies(entity) {
+			targets, ok := byID[target]
+			if !ok {
+				targets = minors(target)
+			}
+			for _, j := range targets {
+				if !seen[j] {
+					seen[j] = true
+					dependents[j] = append(dependents[j], i)
+					pending[i]++
+				}
+			}
+		}
+	}
+
+	before := func(a, b int) bool {
+		if entities[a].IsSchema != entities[b].IsSchema {
+			return entities[a].IsSchema
+		}
+		return a < b
+	}
+	var ready []int
+	enqueue := func(i int) {
+		at := sort.Search(len(ready), func(k int) bool { return before(i, ready[k]) })
+		ready = append(ready, 0)
+		copy(ready[at+1:], ready[at:])
+		ready[at] = i
+	}
+	for i := range entities {
+		if pending[i] == 0 {
+			enqueue(i)
+		}
+	}
+
+	order := make([]int, 0, n)
+	done := make([]bool, n)
+	for len(order) < n {
+		if len(ready) == 0 {
+			// Only reference cycles are left: release their first entity
+			next := -1
+			for i := range entities {
+				if !done[i] && (next < 0 || before(i, next)) {
+					next = i
+				}
+			}
+			pending[next] = 0
+			enqueue(next)
+		}
+		i := ready[0]
+		ready = ready[1:]
+		done[i] = true
+		order = append(order, i)
+		for _, d := range dependents[i] {
+			pending[d]--
+			if pending[d] == 0 && !done[d] {
+				enqueue(d)
+			}
+		}
+	}
+	return order
+}
+
+// batchDependencies returns the IDs an entity must be registered after: its schema ID and its
+// GTS references, except for references to itself and to JSON Schema meta-schemas
+func batchDependencies(entity *JsonEntity) []string {
+	var ids []string
+	self := ""
+	if entity.GtsID != nil {
+		self = entity.GtsID.ID
+	}
+	if entity.SchemaID != "" && entity.SchemaID != self {
+		ids = append(ids, entity.SchemaID)
+	}
+	for _, ref := range entity.GtsRefs {
+		id := strings.TrimPrefix(ref.ID, GtsURIPrefix)
+		if id != self && !isJSONSchemaURL(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
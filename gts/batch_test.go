@@ -0,0 +1,151 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	batchBaseID     = "gts.x.test.batch.base.v1~"
+	batchDerivedID  = "gts.x.test.batch.base.v1~x.test.batch.derived.v1~"
+	batchInstanceID = "gts.x.test.batch.base.v1~x.test.batch.derived.v1~x.test._.seed.v1"
+)
+
+// batchEntities returns a base schema, an instance of the derived schema and the derived schema,
+// so that the instance references an entity placed after it in the batch
+func batchEntities() []*JsonEntity {
+	return []*JsonEntity{
+		NewJsonEntity(map[string]any{
+			"$id":     "gts://" + batchBaseID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		}, DefaultGtsConfig()),
+		NewJsonEntity(map[string]any{
+			"id":   batchInstanceID,
+			"type": batchDerivedID,
+		}, DefaultGtsConfig()),
+		NewJsonEntity(map[string]any{
+			"$id":     "gts://" + batchDerivedID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"allOf":   []any{map[string]any{"$ref": "gts://" + batchBaseID}},
+		}, DefaultGtsConfig()),
+	}
+}
+
+func TestRegisterAll_AtomicIntraBatchReferences(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+
+	result, err := store.RegisterAll(batchEntities(), true)
+	if err != nil {
+		t.Fatalf("RegisterAll failed: %v (%+v)", err, result)
+	}
+	if !result.Committed || result.Registered != 3 || result.Failed != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	for _, id := range []string{batchBaseID, batchDerivedID, batchInstanceID} {
+		if store.Get(id) == nil {
+			t.Errorf("Expected %s to be registered", id)
+		}
+	}
+
+	// Registered one by one in the same order, the forward reference of the instance fails
+	sequential := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+	result, err = sequential.RegisterAll(batchEntities(), false)
+	if err != nil {
+		t.Fatalf("RegisterAll failed: %v", err)
+	}
+	if result.Registered != 2 || result.Failed != 1 || result.Results[1].OK {
+		t.Errorf("Expected the instance alone to fail, got %+v", result)
+	}
+}
+
+func TestRegisterAll_AtomicRejection(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+	if err := store.Register(NewJsonEntity(map[string]any{"id": "gts.x.test.batch.other.v1~x.test._.kept.v1"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+	before := store.Count()
+
+	entities := batchEntities()
+	entities = append(entities,
+		NewJsonEntity(map[string]any{"id": "gts.x.test.batch.base.v1~x.test._.dangling.v1", "type": "gts.x.test.batch.missing.v1~"}, DefaultGtsConfig()),
+		NewJsonEntity(map[string]any{"id": batchInstanceID, "type": batchDerivedID}, DefaultGtsConfig()),
+	)
+
+	result, err := store.RegisterAll(entities, true)
+	var rejected *BatchRejectedError
+	if !errors.As(err, &rejected) || rejected.Failed != 2 || rejected.Total != 5 {
+		t.Fatalf("Expected the batch to be rejected, got %v", err)
+	}
+	if store.Count() != before {
+		t.Errorf("Expected Count() to stay %d, got %d", before, store.Count())
+	}
+	if result.Committed || result.Registered != 0 {
+		t.Errorf("Expected nothing committed, got %+v", result)
+	}
+	for i, r := range result.Results {
+		if failed := i >= 3; failed != (r.Error != "") {
+			t.Errorf("Unexpected result for entity %d: %+v", i, r)
+		}
+	}
+
+	store.Freeze()
+	var frozen *StoreFrozenError
+	if _, err := store.RegisterAll(batchEntities(), true); !errors.As(err, &frozen) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	entities := batchEntities()
+	if order := DependencyOrder(entities); !reflect.DeepEqual(order, []int{0, 2, 1}) {
+		t.Errorf("Expected the derived schema before its instance, got %v", order)
+	}
+	reversed := []*JsonEntity{entities[2], entities[1], entities[0]}
+	if order := DependencyOrder(reversed); !reflect.DeepEqual(order, []int{2, 0, 1}) {
+		t.Errorf("Expected the base schema first, got %v", order)
+	}
+
+	// Instances referencing each other are released in batch order after their schema
+	cycle := []*JsonEntity{
+		NewJsonEntity(map[string]any{"id": "gts.x.test.batch.base.v1~x.test._.a.v1", "peer": "gts.x.test.batch.base.v1~x.test._.b.v1"}, DefaultGtsConfig()),
+		NewJsonEntity(map[string]any{"id": "gts.x.test.batch.base.v1~x.test._.b.v1", "peer": "gts.x.test.batch.base.v1~x.test._.a.v1"}, DefaultGtsConfig()),
+		entities[0],
+	}
+	if order := DependencyOrder(cycle); !reflect.DeepEqual(order, []int{2, 0, 1}) {
+		t.Errorf("Expected the schema first and the cycle in batch order, got %v", order)
+	}
+
+	// A schema ID without minor version depends on its minor versions in the batch
+	minor := []*JsonEntity{
+		NewJsonEntity(map[string]any{"id": "gts.x.test.batch.item.v1~x.test._.a.v1", "type": "gts.x.test.batch.item.v1~"}, DefaultGtsConfig()),
+		NewJsonEntity(map[string]any{"id": "gts.x.test.batch.ref.v1~x.test._.b.v1", "target": "gts.x.test.batch.item.v1~"}, DefaultGtsConfig()),
+		NewJsonEntity(map[string]any{"$id": "gts://gts.x.test.batch.item.v1.2~", "type": "object"}, DefaultGtsConfig()),
+	}
+	if order := DependencyOrder(minor); order[0] != 2 {
+		t.Errorf("Expected the minor version first, got %v", order)
+	}
+}
+
+func TestRegisterAllWithOptions_DependencyOrder(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+		entities := batchEntities()
+		result, err := store.RegisterAllWithOptions([]*JsonEntity{entities[2], entities[1], entities[0]}, BatchOptions{Atomic: atomic, DependencyOrder: true})
+		if err != nil {
+			t.Fatalf("RegisterAllWithOptions(atomic=%t) failed: %v", atomic, err)
+		}
+		if result.Registered != 3 || result.Failed != 0 || !reflect.DeepEqual(result.Order, []int{2, 0, 1}) {
+			t.Errorf("Expected the batch registered base first with atomic=%t, got %+v", atomic, result)
+		}
+		if result.Results[0].ID != batchDerivedID || !result.Results[0].OK {
+			t.Errorf("Expected results in batch order, got %+v", result.Results)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GtsIDBuilder builds a GTS identifier segment by segment, e.g.
+//
+//	NewGtsIDBuilder().Segment("x", "core", "events", "type", 1).AsType().
+//		Chain("x", "commerce", "orders", "order_placed", 1).Minor(0).AsType().Build()
+//
+// for gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~. The first error of the
+// chain of calls is kept and returned by Build, and Build validates the result with NewGtsID,
+// so that no invalid ID can be built.
+type GtsIDBuilder struct {
+	segments []*builderSegment
+	err      error
+}
+
+// builderSegment holds the parts of a segment under construction
+type builderSegment struct {
+	vendor, pkg, namespace, typ string
+	major                       int
+	minor                       *int
+	isType                      bool
+}
+
+// NewGtsIDBuilder creates an empty builder; start it with Segment
+func NewGtsIDBuilder() *GtsIDBuilder {
+	return &GtsIDBuilder{}
+}
+
+// Segment sets the first segment of the ID from its vendor, package, namespace, type and major
+// version; derived segments are added with Chain
+func (b *GtsIDBuilder) Segment(vendor, pkg, namespace, typ string, major int) *GtsIDBuilder {
+	if len(b.segments) > 0 {
+		return b.fail(fmt.Errorf("the first segment is already set, use Chain to add a segment"))
+	}
+	return b.add(vendor, pkg, namespace, typ, major)
+}
+
+// Chain adds a segment derived from the previous one, which becomes a type
+func (b *GtsIDBuilder) Chain(vendor, pkg, namespace, typ string, major int) *GtsIDBuilder {
+	if len(b.segments) == 0 {
+		return b.fail(fmt.Errorf("chained segment needs a preceding segment, use Segment first"))
+	}
+	b.segments[len(b.segments)-1].isType = true
+	return b.add(vendor, pkg, namespace, typ, major)
+}
+
+// Minor sets the minor version of the last segment
+func (b *GtsIDBuilder) Minor(n int) *GtsIDBuilder {
+	last := b.last("Minor")
+	if last == nil {
+		return b
+	}
+	if n < 0 {
+		return b.fail(fmt.Errorf("minor version must be >= 0, got %d", n))
+	}
+	last.minor = &n
+	return b
+}
+
+// AsType makes the last segment a type, so that the ID ends with '~'
+func (b *GtsIDBuilder) AsType() *GtsIDBuilder {
+	if last := b.last("AsType"); last != nil {
+		last.isType = true
+	}
+	return b
+}
+
+// Build returns the validated identifier, or the first error of the builder
+func (b *GtsIDBuilder) Build() (*GtsID, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.segments) == 0 {
+		return nil, fmt.Errorf("GTS ID needs at least one segment")
+	}
+	var sb strings.Builder
+	sb.WriteString(GtsPrefix)
+	for _, seg := range b.segments {
+		sb.WriteString(formatSegment(seg.vendor, seg.pkg, seg.namespace, seg.typ, seg.major, seg.minor, seg.isType))
+	}
+	return NewGtsID(sb.String())
+}
+
+func (b *GtsIDBuilder) add(vendor, pkg, namespace, typ string, major int) *GtsIDBuilder {
+	if major < 0 {
+		return b.fail(fmt.Errorf("major version must be >= 0, got %d", major))
+	}
+	b.segments = append(b.segments, &builderSegment{vendor: vendor, pkg: pkg, namespace: namespace, typ: typ, major: major})
+	return b
+}
+
+// last returns the last segment, recording an error for operation when there is none
+func (b *GtsIDBuilder) last(operation string) *builderSegment {
+	if len(b.segments) == 0 {
+		b.fail(fmt.Errorf("%s needs a segment, use Segment first", operation))
+		return nil
+	}
+	return b.segments[len(b.segments)-1]
+}
+
+func (b *GtsIDBuilder) fail(err error) *GtsIDBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// formatSegment returns the text of a segment, with its '~' terminator when it is a type
+func formatSegment(vendor, pkg, namespace, typ string, major int, minor *int, isType bool) string {
+	text := vendor + "." + pkg + "." + namespace + "." + typ + ".v" + strconv.Itoa(major)
+	if minor != nil {
+		text += "." + strconv.Itoa(*minor)
+	}
+	if isType {
+		text += "~"
+	}
+	return text
+}
+
+// String returns the identifier
+func (g *GtsID) String() string {
+	return g.ID
+}
+
+// LastSegment returns the last segment of the identifier, the one its version applies to
+func (g *GtsID) LastSegment() *GtsIDSegment {
+	return g.Segments[len(g.Segments)-1]
+}
+
+// WithMinor returns the identifier with the minor version of its last segment set to n
+func (g *GtsID) WithMinor(n int) (*GtsID, error) {
+	if g.IsWildcard() {
+		return nil, fmt.Errorf("cannot set the minor version of wildcard pattern '%s'", g.ID)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("minor version must be >= 0, got %d", n)
+	}
+	last := g.LastSegment()
+	prefix := GtsPrefix
+	if len(g.Segments) > 1 {
+		prefix = g.SegmentPrefixIDs()[len(g.Segments)-2]
+	}
+	return NewGtsID(prefix + formatSegment(last.Vendor, last.Package, last.Namespace, last.Type, last.VerMajor, &n, last.IsType))
+}
+
+// Parent returns the identifier without its last segment: the type of a chained instance, or the
+// parent of a derived type
+func (g *GtsID) Parent() (*GtsID, error) {
+	if len(g.Segments) < 2 {
+		return nil, fmt.Errorf("'%s' has a single segment and no parent", g.ID)
+	}
+	return NewGtsID(g.SegmentPrefixIDs()[len(g.Segments)-2])
+}
+
+// BaseType returns the type of the first segment, which is the identifier itself for a base type
+func (g *GtsID) BaseType() (*GtsID, error) {
+	if !g.Segments[0].IsType {
+		return nil, fmt.Errorf("the first segment of '%s' is not a type", g.ID)
+	}
+	return NewGtsID(g.SegmentPrefixIDs()[0])
+}
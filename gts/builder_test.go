@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"testing"
+)
+
+func TestGtsIDBuilder(t *testing.T) {
+	id, err := NewGtsIDBuilder().Segment("x", "core", "events", "type", 1).AsType().
+		Chain("x", "commerce", "orders", "order_placed", 1).Minor(0).AsType().Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if id.String() != "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~" {
+		t.Errorf("Unexpected ID %s", id)
+	}
+
+	instance, err := NewGtsIDBuilder().Segment("x", "core", "events", "type", 1).
+		Chain("x", "app", "_", "alice", 1).Build()
+	if err != nil || instance.ID != "gts.x.core.events.type.v1~x.app._.alice.v1" {
+		t.Errorf("Expected a chained instance ID, got %v, %v", instance, err)
+	}
+
+	invalid := []*GtsIDBuilder{
+		NewGtsIDBuilder(),
+		NewGtsIDBuilder().Minor(1),
+		NewGtsIDBuilder().Chain("x", "core", "events", "type", 1),
+		NewGtsIDBuilder().Segment("x", "core", "events", "type", 1).Segment("x", "core", "events", "type", 1),
+		NewGtsIDBuilder().Segment("x", "core", "events", "type", -1).AsType(),
+		NewGtsIDBuilder().Segment("x", "core", "events", "type", 1).Minor(-1).AsType(),
+		NewGtsIDBuilder().Segment("X", "core", "events", "type", 1).AsType(),
+		NewGtsIDBuilder().Segment("x", "core.extra", "events", "type", 1).AsType(),
+		// Single-segment instances are prohibited
+		NewGtsIDBuilder().Segment("x", "core", "events", "type", 1),
+	}
+	for i, builder := range invalid {
+		if id, err := builder.Build(); err == nil {
+			t.Errorf("Case %d: expected an error, got %s", i, id)
+		}
+	}
+}
+
+func TestGtsID_SegmentHelpers(t *testing.T) {
+	id, err := NewGtsID("gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~x.app._.alice.v1")
+	if err != nil {
+		t.Fatalf("NewGtsID failed: %v", err)
+	}
+
+	withMinor, err := id.WithMinor(2)
+	if err != nil || withMinor.ID != "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~x.app._.alice.v1.2" {
+		t.Errorf("Unexpected WithMinor result %v, %v", withMinor, err)
+	}
+	if _, err := id.WithMinor(-1); err == nil {
+		t.Error("Expected an error for a negative minor version")
+	}
+
+	parent, err := id.Parent()
+	if err != nil || parent.ID != "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~" || !parent.IsType() {
+		t.Errorf("Unexpected Parent result %v, %v", parent, err)
+	}
+	grandparent, err := parent.Parent()
+	if err != nil || grandparent.ID != "gts.x.core.events.type.v1~" {
+		t.Errorf("Unexpected Parent result %v, %v", grandparent, err)
+	}
+	if _, err := grandparent.Parent(); err == nil {
+		t.Error("Expected an error for the parent of a base type")
+	}
+
+	base, err := id.BaseType()
+	if err != nil || base.ID != "gts.x.core.events.type.v1~" {
+		t.Errorf("Unexpected BaseType result %v, %v", base, err)
+	}
+	if self, err := base.BaseType(); err != nil || self.ID != base.ID {
+		t.Errorf("Expected a base type to be its own base type, got %v, %v", self, err)
+	}
+
+	if last := id.LastSegment(); last.Type != "alice" || last.VerMajor != 1 || last.VerMinor != nil {
+		t.Errorf("Unexpected last segment %+v", last)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// ValidatorBundleVersion is the format version written to and accepted from bundle files
+const ValidatorBundleVersion = 1
+
+// ValidatorBundle is a self-contained set of schemas for validating instances without a store.
+// It holds the requested schemas together with every schema they reference through $ref.
+type ValidatorBundle struct {
+	Version int `json:"version"`
+	// Schemas lists the schema IDs the bundle was compiled for
+	Schemas []string `json:"schemas"`
+	// Documents holds every bundled schema by ID, as registered in the store
+	Documents map[string]map[string]any `json:"documents"`
+	// Index maps each bundled schema ID to the GTS schema IDs it references
+	Index map[string][]string `json:"index"`
+}
+
+// BundleUnresolvedRefsError reports references that could not be bundled
+type BundleUnresolvedRefsError struct {
+	// Refs maps each referencing schema ID to the references it could not resolve
+	Refs map[string][]string
+}
+
+func (e *BundleUnresolvedRefsError) Error() string {
+	ids := make([]string, 0, len(e.Refs))
+	for id := range e.Refs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%s -> %s", id, strings.Join(e.Refs[id], ", ")))
+	}
+	return fmt.Sprintf("Unresolved schema references: %s", strings.Join(parts, "; "))
+}
+
+// BundleSchemaNotFoundError is returned when validating against a schema that is not in the bundle
+type BundleSchemaNotFoundError struct {
+	SchemaID string
+}
+
+func (e *BundleSchemaNotFoundError) Error() string {
+	return fmt.Sprintf("Schema not in validator bundle: %s", e.SchemaID)
+}
+
+// CompileBundle builds a validator bundle for the given schemas and the transitive closure of the
+// GTS schemas they reference with $ref. Local references ("#/...") stay inside their document.
+// All references that cannot be bundled (GTS IDs missing from the store, non-schema targets, or
+// non-GTS URLs) are reported together in a BundleUnresolvedRefsError.
+func (s *GtsStore) CompileBundle(schemaIDs []string) (*ValidatorBundle, error) {
+	bundle := &ValidatorBundle{
+		Version:   ValidatorBundleVersion,
+		Schemas:   make([]string, 0, len(schemaIDs)),
+		Documents: make(map[string]map[string]any),
+		Index:     make(map[string][]string),
+	}
+
+	for _, id := range schemaIDs {
+		id = strings.TrimPrefix(strings.TrimSpace(id), GtsURIPrefix)
+		entity := s.Get(id)
+		if entity == nil || !entity.IsSchema {
+			return nil, &StoreGtsSchemaNotFoundError{EntityID: id}
+		}
+		bundle.Schemas = append(bundle.Schemas, id)
+	}
+
+	unresolved := make(map[string][]string)
+	queue := append([]string(nil), bundle.Schemas...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, done := bundle.Documents[id]; done {
+			continue
+		}
+
+		entity := s.Get(id)
+		bundle.Documents[id] = entity.Content
+
+		deps := make([]string, 0)
+		for _, ref := range collectSchemaRefs(entity.Content) {
+			target := s.Get(ref)
+			switch {
+			case !IsValidGtsID(ref):
+				unresolved[id] = append(unresolved[id], ref)
+			case target == nil || !target.IsSchema:
+				unresolved[id] = append(unresolved[id], ref)
+			default:
+				deps = append(deps, ref)
+				queue = append(queue, ref)
+			}
+		}
+		bundle.Index[id] = deps
+	}
+
+	if len(unresolved) > 0 {
+		return nil, &BundleUnresolvedRefsError{Refs: unresolved}
+	}
+	return bundle, nil
+}
+
+// collectSchemaRefs returns the sorted, distinct non-local $ref targets of a schema,
+// with the gts:// prefix and any fragment removed
+func collectSchemaRefs(node any) []string {
+	seen := make(map[string]bool)
+	var walk func(node any)
+	walk = func(node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			for k, child := range v {
+				if k == "$ref" {
+					if ref, ok := child.(string); ok && !strings.HasPrefix(ref, "#") {
+						ref, _, _ = strings.Cut(strings.TrimPrefix(ref, GtsURIPrefix), "#")
+						seen[ref] = true
+					}
+					continue
+				}
+				if valueKeywords[k] {
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(node)
+
+	refs := make([]string, 0, len(seen))
+	for ref := range seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// WriteFile writes the bundle as a single JSON file
+func (b *ValidatorBundle) WriteFile(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadValidatorBundle reads a bundle written by ValidatorBundle.WriteFile
+func LoadValidatorBundle(path string) (*ValidatorBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bundle ValidatorBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid validator bundle %s: %w", path, err)
+	}
+	if bundle.Version != ValidatorBundleVersion {
+		return nil, fmt.Errorf("unsupported validator bundle version %d in %s", bundle.Version, path)
+	}
+	return &bundle, nil
+}
+
+// BundleValidator validates instances against the schemas of a ValidatorBundle with no store.
+// Schemas are compiled on first use and cached; it is safe for concurrent use.
+type BundleValidator struct {
+	bundle   *ValidatorBundle
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	compiled map[string]*jsonschema.Schema
+}
+
+// bundleLoader refuses to load anything, so that validation never leaves the bundle
+type bundleLoader struct{}
+
+func (bundleLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("reference not in validator bundle: %s", url)
+}
+
+// NewBundleValidator registers every bundled document with a schema compiler
+func NewBundleValidator(bundle *ValidatorBundle) (*BundleValidator, error) {
+	compiler := newSchemaCompiler()
+	compiler.UseLoader(bundleLoader{})
+
+	for id, document := range bundle.Documents {
+		normalized, _ := normalizeSchemaDocument(document)
+		// Register under the bundle key, which is how GTS $refs address the document
+		normalized["$id"] = id
+		if err := compiler.AddResource(id, normalized); err != nil {
+			return nil, fmt.Errorf("add schema resource %s: %v", id, err)
+		}
+	}
+
+	return &BundleValidator{
+		bundle:   bundle,
+		compiler: compiler,
+		compiled: make(map[string]*jsonschema.Schema),
+	}, nil
+}
+
+// ValidateContent validates content against a bundled schema
+func (v *BundleValidator) ValidateContent(content map[string]any, schemaID string) error {
+	schemaID = strings.TrimPrefix(schemaID, GtsURIPrefix)
+	if _, ok := v.bundle.Documents[schemaID]; !ok {
+		return &BundleSchemaNotFoundError{SchemaID: schemaID}
+	}
+
+	schema, err := v.compile(schemaID)
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(content); err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+	return nil
+}
+
+// compile returns the cached compiled schema, compiling it on first use
+func (v *BundleValidator) compile(schemaID string) (*jsonschema.Schema, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if schema, ok := v.compiled[schemaID]; ok {
+		return schema, nil
+	}
+	schema, err := v.compiler.Compile(schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("compile schema: %v", err)
+	}
+	v.compiled[schemaID] = schema
+	return schema, nil
+}
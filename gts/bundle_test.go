@@ -0,0 +1,129 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompileBundle_Closure(t *testing.T) {
+	store := newOrderPlacedStore(t)
+
+	bundle, err := store.CompileBundle([]string{"gts://" + orderPlacedTypeID})
+	if err != nil {
+		t.Fatalf("CompileBundle failed: %v", err)
+	}
+	if !reflect.DeepEqual(bundle.Schemas, []string{orderPlacedTypeID}) {
+		t.Errorf("Expected root schema, got %v", bundle.Schemas)
+	}
+	if len(bundle.Documents) != 2 || bundle.Documents["gts.x.core.events.type.v1~"] == nil {
+		t.Errorf("Expected the base schema to be bundled, got %v", bundle.Documents)
+	}
+	if !reflect.DeepEqual(bundle.Index[orderPlacedTypeID], []string{"gts.x.core.events.type.v1~"}) {
+		t.Errorf("Unexpected index: %v", bundle.Index)
+	}
+}
+
+func TestBundleValidator_MatchesStore(t *testing.T) {
+	store := newOrderPlacedStore(t)
+	bundle, err := store.CompileBundle([]string{orderPlacedTypeID})
+	if err != nil {
+		t.Fatalf("CompileBundle failed: %v", err)
+	}
+
+	// Validate against a bundle that went through a file, as offline consumers would
+	path := filepath.Join(t.TempDir(), "validators.json")
+	if err := bundle.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	loaded, err := LoadValidatorBundle(path)
+	if err != nil {
+		t.Fatalf("LoadValidatorBundle failed: %v", err)
+	}
+	validator, err := NewBundleValidator(loaded)
+	if err != nil {
+		t.Fatalf("NewBundleValidator failed: %v", err)
+	}
+
+	missingPayload := orderPlacedInstance()
+	delete(missingPayload["payload"].(map[string]any), "orderId")
+	missingBase := orderPlacedInstance()
+	delete(missingBase, "occurredAt")
+
+	tests := []struct {
+		name     string
+		instance map[string]any
+		valid    bool
+	}{
+		{"valid", orderPlacedInstance(), true},
+		{"invalid derived field", missingPayload, false},
+		{"invalid base field", missingBase, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeErr := store.validateWithSchema(tt.instance, store.Get(orderPlacedTypeID).Content)
+			bundleErr := validator.ValidateContent(tt.instance, orderPlacedTypeID)
+			if (storeErr == nil) != tt.valid || (bundleErr == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got store %v and bundle %v", tt.valid, storeErr, bundleErr)
+			}
+		})
+	}
+}
+
+func TestBundleValidator_UnknownSchema(t *testing.T) {
+	store := newOrderPlacedStore(t)
+	bundle, err := store.CompileBundle([]string{"gts.x.core.events.type.v1~"})
+	if err != nil {
+		t.Fatalf("CompileBundle failed: %v", err)
+	}
+	validator, err := NewBundleValidator(bundle)
+	if err != nil {
+		t.Fatalf("NewBundleValidator failed: %v", err)
+	}
+
+	var notFound *BundleSchemaNotFoundError
+	if err := validator.ValidateContent(orderPlacedInstance(), orderPlacedTypeID); !errors.As(err, &notFound) {
+		t.Errorf("Expected bundle schema not found error, got %v", err)
+	}
+}
+
+func TestCompileBundle_Errors(t *testing.T) {
+	store := newOrderPlacedStore(t)
+
+	var notFound *StoreGtsSchemaNotFoundError
+	if _, err := store.CompileBundle([]string{"gts.x.core.events.missing.v1~"}); !errors.As(err, &notFound) {
+		t.Errorf("Expected schema not found error, got %v", err)
+	}
+
+	derived := map[string]any{
+		"$id":     "gts://gts.x.core.events.type.v1~x.test.bundle.dangling.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"allOf": []any{
+			map[string]any{"$ref": "gts://gts.x.core.events.type.v1~"},
+			map[string]any{"$ref": "gts://gts.x.core.events.missing.v1~"},
+			map[string]any{"$ref": "#/definitions/local"},
+		},
+		"definitions": map[string]any{"local": map[string]any{"type": "object"}},
+	}
+	if err := store.Register(NewJsonEntity(derived, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+
+	_, err := store.CompileBundle([]string{"gts.x.core.events.type.v1~x.test.bundle.dangling.v1~"})
+	var unresolved *BundleUnresolvedRefsError
+	if !errors.As(err, &unresolved) {
+		t.Fatalf("Expected unresolved refs error, got %v", err)
+	}
+	expected := map[string][]string{
+		"gts.x.core.events.type.v1~x.test.bundle.dangling.v1~": {"gts.x.core.events.missing.v1~"},
+	}
+	if !reflect.DeepEqual(unresolved.Refs, expected) {
+		t.Errorf("Expected %v, got %v", expected, unresolved.Refs)
+	}
+}
@@ -7,6 +7,7 @@ package gts
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
@@ -17,39 +18,126 @@ import (
 type CastResult struct {
 	*CompatibilityResult
 	CastedEntity map[string]any `json:"casted_entity,omitempty"`
+	// UpdatedProperties lists the paths of GTS ID values rewritten to the const of the target schema
+	UpdatedProperties []string `json:"updated_properties,omitempty"`
+	// ConditionalBranches lists the if/then/else branches of the target schema applied to the instance
+	ConditionalBranches []AppliedConditional `json:"conditional_branches,omitempty"`
+	// SchemaResolution lists the sources consulted to resolve the schema of the instance
+	SchemaResolution []ResolutionStep `json:"schema_resolution,omitempty"`
+	// MigrationMapID is the migration map applied to cast across major versions, and
+	// MigrationOperations the operations of the map that changed the instance
+	MigrationMapID      string               `json:"migration_map_id,omitempty"`
+	MigrationOperations []MigrationOperation `json:"migration_operations,omitempty"`
+}
+
+// Codes of IncompatibilityDetail
+const (
+	// IncompatibilityNotObject is an instance or nested value that is not an object
+	IncompatibilityNotObject = "not_object"
+	// IncompatibilityMissingRequired is a missing required property without default
+	IncompatibilityMissingRequired = "missing_required"
+	// IncompatibilityConditionalRequired is a missing property without default required by an
+	// applied if/then/else branch
+	IncompatibilityConditionalRequired = "conditional_required"
+	// IncompatibilityValidationFailed is the failed validation of the cast instance
+	IncompatibilityValidationFailed = "validation_failed"
+)
+
+// IncompatibilityDetail is an incompatibility reason of a cast in structured form
+type IncompatibilityDetail struct {
+	// Path is the instance path of the value, with indices for array elements such as
+	// "orders[0].lines[1].sku"; empty for the whole instance
+	Path string `json:"path"`
+	// Code is one of the Incompatibility constants
+	Code string `json:"code"`
+	// SchemaID is the schema imposing the requirement, the target schema of the cast
+	SchemaID string `json:"schema_id"`
+	Message  string `json:"message"`
+}
+
+// String returns the incompatibility reason: the message and the schema imposing it
+func (d IncompatibilityDetail) String() string {
+	return fmt.Sprintf("%s (target schema %s)", d.Message, d.SchemaID)
 }
 
 // Cast transforms an instance to conform to a target schema version
+// When the source and target schemas differ in major version, the registered migration map for
+// the pair (see MigrationMapTypeID) is applied to the instance first; without one Cast fails with
+// StoreGtsMajorCastRequiresMappingError.
+// A panic during the cast is returned as an error wrapping ErrInternal.
 // see gts-python store.py cast method
-func (s *GtsStore) Cast(instanceID, toSchemaID string) (*CastResult, error) {
+func (s *GtsStore) Cast(instanceID, toSchemaID string) (result *CastResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, newStoreInternalError("Cast", r)
+		}
+	}()
+
+	return s.cast(instanceID, toSchemaID)
+}
+
+// CastContent casts instance content that is not registered, e.g. an event received by a
+// stateless transformation service, to a target schema version like Cast. The source schema is
+// resolved from the content as on registration: its schema ID field, $schema or chained ID.
+// Content without resolvable schema fails with StoreGtsSchemaForInstanceNotFoundError. The store
+// is not modified. The result is reported from the instance's GTS ID, or from its source schema
+// ID when the content has none, e.g. for instances identified by a UUID.
+// A panic during the cast is returned as an error wrapping ErrInternal.
+func (s *GtsStore) CastContent(content map[string]any, toSchemaID string) (result *CastResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, newStoreInternalError("CastContent", r)
+		}
+	}()
+
+	entity := NewJsonEntity(content, s.GtsConfig())
+	instanceID := ""
+	if entity.GtsID != nil {
+		instanceID = entity.GtsID.ID
+	}
+	return s.castEntity(instanceID, entity, toSchemaID)
+}
+
+// cast is the unguarded implementation of Cast
+func (s *GtsStore) cast(instanceID, toSchemaID string) (*CastResult, error) {
 	// Get instance entity
 	instanceEntity := s.Get(instanceID)
 	if instanceEntity == nil {
 		return nil, &StoreGtsObjectNotFoundError{EntityID: instanceID}
 	}
+	return s.castEntity(instanceID, instanceEntity, toSchemaID)
+}
 
-	// Get target schema
-	toSchema := s.Get(toSchemaID)
-	if toSchema == nil {
-		return nil, &StoreGtsSchemaNotFoundError{EntityID: toSchemaID}
+// castEntity casts an instance entity, registered or not, to the target schema; an empty
+// instanceID is replaced with the ID of the instance's schema
+func (s *GtsStore) castEntity(instanceID string, instanceEntity *JsonEntity, toSchemaID string) (*CastResult, error) {
+	// Get target schema; a target without minor version is cast to its latest minor version
+	toSchema, err := s.ResolveSchema(toSchemaID)
+	if err != nil {
+		return nil, err
 	}
+	toSchemaID = toSchema.GtsID.ID
 
-	// Determine source schema
-	var fromSchemaID string
-	var fromSchema *JsonEntity
+	// Not allowed to cast directly from a schema
 	if instanceEntity.IsSchema {
-		// Not allowed to cast directly from a schema
-		return nil, &StoreGtsCastFromSchemaNotAllowedError{FromID: instanceID}
-	} else {
-		// Casting an instance - need to find its schema
-		fromSchemaID = instanceEntity.SchemaID
-		if fromSchemaID == "" {
-			return nil, &StoreGtsSchemaForInstanceNotFoundError{EntityID: instanceID}
-		}
-		fromSchema = s.Get(fromSchemaID)
-		if fromSchema == nil {
-			return nil, &StoreGtsSchemaNotFoundError{EntityID: fromSchemaID}
+		fromID := instanceID
+		if fromID == "" {
+			fromID = instanceEntity.Label
 		}
+		return nil, &StoreGtsCastFromSchemaNotAllowedError{FromID: fromID}
+	}
+
+	// Casting an instance - need to resolve its schema
+	fromSchemaID, trace, err := s.resolveInstanceSchema(instanceEntity)
+	if err != nil {
+		return nil, err
+	}
+	fromSchema, err := s.ResolveSchema(fromSchemaID)
+	if err != nil {
+		return nil, err
+	}
+	if instanceID == "" {
+		instanceID = fromSchema.GtsID.ID
 	}
 
 	// Get content as maps
@@ -57,8 +145,25 @@ func (s *GtsStore) Cast(instanceID, toSchemaID string) (*CastResult, error) {
 	fromSchemaContent := fromSchema.Content
 	toSchemaContent := toSchema.Content
 
+	// Across major versions the migration map reshapes the instance before the cast
+	var migration *MigrationMap
+	var migrated []MigrationOperation
+	if crossesMajorVersion(fromSchema.GtsID, toSchema.GtsID) {
+		if migration, err = s.findMigrationMap(fromSchema.GtsID, toSchema.GtsID); err != nil {
+			return nil, err
+		}
+		instanceContent, migrated = migration.Apply(instanceContent)
+	}
+
 	// Perform the cast
-	return castInstance(instanceID, toSchemaID, instanceContent, fromSchemaContent, toSchemaContent, s)
+	result, err := castInstance(instanceID, toSchemaID, instanceContent, fromSchemaContent, toSchemaContent, s)
+	if result != nil {
+		result.SchemaResolution = trace
+		if migration != nil {
+			result.MigrationMapID, result.MigrationOperations = migration.ID, migrated
+		}
+	}
+	return result, err
 }
 
 // castInstance performs the actual casting logic
@@ -68,43 +173,69 @@ func castInstance(
 	fromInstanceContent, fromSchemaContent, toSchemaContent map[string]any,
 	store *GtsStore,
 ) (*CastResult, error) {
-	// Flatten target schema to merge allOf
-	targetSchema := flattenSchema(toSchemaContent)
+	// Bring schemas into the canonical draft-independent form
+	normalizedFrom, fromWarnings := normalizeSchema(fromSchemaContent)
+	normalizedTo, toWarnings := normalizeSchema(toSchemaContent)
+
+	// Flatten both schemas with the schemas they reference to merge allOf
+	targetSchema, toDefects, toUnresolved := flattenSchemaWithStore(normalizedTo, store, nil)
+	fromFlat, fromDefects, fromUnresolved := flattenSchemaWithStore(normalizedFrom, store, nil)
+	toFlat := targetSchema
+
+	// Apply the branches of the target's if/then/else blocks selected by the instance
+	var resolve func(ref string) map[string]any
+	if store != nil {
+		resolve = store.storeSchemaResolver()
+	}
+	targetSchema, appliedConditionals, conditionalRequired, conditionalWarnings := applyConditionals(
+		fromInstanceContent, targetSchema, collectConditionals(normalizedTo, "", resolve))
 
 	// Determine direction
-	direction := inferDirection(fromInstanceID, toSchemaID)
+	direction := InferDirection(fromInstanceID, toSchemaID)
 
 	// Determine which is old/new based on direction
 	var oldSchema, newSchema map[string]any
-	switch direction {
-	case "up":
-		oldSchema = fromSchemaContent
-		newSchema = toSchemaContent
-	case "down":
-		oldSchema = toSchemaContent
-		newSchema = fromSchemaContent
+	switch direction.Direction {
+	case DirectionUp:
+		oldSchema = fromFlat
+		newSchema = toFlat
+	case DirectionDown:
+		oldSchema = toFlat
+		newSchema = fromFlat
 	default:
-		oldSchema = fromSchemaContent
-		newSchema = toSchemaContent
+		oldSchema = fromFlat
+		newSchema = toFlat
 	}
 
 	// Check compatibility
 	isBackward, backwardErrors := checkBackwardCompatibility(oldSchema, newSchema)
 	isForward, forwardErrors := checkForwardCompatibility(oldSchema, newSchema)
+	if refErrors := append(schemaDefects(schemaLabel(fromSchemaContent), fromUnresolved), schemaDefects(toSchemaID, toUnresolved)...); len(refErrors) > 0 {
+		// Without the referenced schemas the comparison is incomplete
+		isBackward, isForward = false, false
+		backwardErrors = append(slices.Clone(refErrors), backwardErrors...)
+		forwardErrors = append(slices.Clone(refErrors), forwardErrors...)
+	}
 
 	// Apply casting rules to transform the instance
-	casted, added, removed, incompatibilityReasons := castInstanceToSchema(
+	casted, added, removed, updated, incompatibilities := castInstanceToSchema(
 		copyMap(fromInstanceContent),
 		targetSchema,
 		"",
+		newLocalRefResolver(toSchemaContent),
 	)
+	if casted != nil {
+		addedByBranch, branchIncompatibilities := castConditionalRequirements(casted, targetSchema, conditionalRequired)
+		added = append(added, addedByBranch...)
+		incompatibilities = append(incompatibilities, branchIncompatibilities...)
+	}
 
 	// Validate the casted instance against the full target schema
 	var isFullyCompatible bool
 	if casted != nil {
 		err := validateWithGtsIDTolerance(casted, toSchemaContent, store)
 		if err != nil {
-			incompatibilityReasons = append(incompatibilityReasons, err.Error())
+			incompatibilities = append(incompatibilities, IncompatibilityDetail{Code: IncompatibilityValidationFailed, Message: err.Error()})
 			isFullyCompatible = false
 		} else {
 			isFullyCompatible = true
@@ -113,13 +244,21 @@ func castInstance(
 		isFullyCompatible = false
 	}
 
+	// Every requirement of the cast is imposed by the target schema
+	incompatibilityReasons := make([]string, len(incompatibilities))
+	for i := range incompatibilities {
+		incompatibilities[i].SchemaID = toSchemaID
+		incompatibilityReasons[i] = incompatibilities[i].String()
+	}
+
 	return &CastResult{
 		CompatibilityResult: &CompatibilityResult{
 			FromID:                 fromInstanceID,
 			ToID:                   toSchemaID,
 			OldID:                  fromInstanceID,
 			NewID:                  toSchemaID,
-			Direction:              direction,
+			Direction:              direction.Direction,
+			DirectionInfo:          direction,
 			AddedProperties:        deduplicate(added),
 			RemovedProperties:      deduplicate(removed),
 			ChangedProperties:      []map[string]string{},
@@ -127,27 +266,66 @@ func castInstance(
 			IsBackwardCompatible:   isBackward,
 			IsForwardCompatible:    isForward,
 			IncompatibilityReasons: incompatibilityReasons,
+			IncompatibilityDetails: incompatibilities,
 			BackwardErrors:         backwardErrors,
 			ForwardErrors:          forwardErrors,
+			Warnings: mergeWarnings(fromWarnings, toWarnings, conditionalWarnings,
+				schemaDefects(schemaLabel(fromSchemaContent), fromDefects), schemaDefects(toSchemaID, toDefects)),
 		},
-		CastedEntity: casted,
+		CastedEntity:        casted,
+		UpdatedProperties:   updated,
+		ConditionalBranches: appliedConditionals,
 	}, nil
 }
 
-// castInstanceToSchema transforms instance to conform to target schema
+// castConditionalRequirements fills the properties required by applied conditional branches from
+// their defaults, and reports those without a default together with the condition requiring them
+func castConditionalRequirements(casted, targetSchema map[string]any, requiredBy map[string]AppliedConditional) ([]string, []IncompatibilityDetail) {
+	var added []string
+	var incompatibilities []IncompatibilityDetail
+	props := getPropertiesMap(targetSchema)
+	for _, name := range sortedKeys(requiredBy) {
+		if _, exists := casted[name]; exists {
+			continue
+		}
+		if propSchema := getMap(props, name); propSchema != nil {
+			if defaultVal, hasDefault := propSchema["default"]; hasDefault {
+				casted[name] = copyValue(defaultVal)
+				added = append(added, name)
+				continue
+			}
+		}
+		branch := requiredBy[name]
+		location := branch.Path
+		if location == "" {
+			location = "the schema root"
+		}
+		incompatibilities = append(incompatibilities, IncompatibilityDetail{
+			Path: name,
+			Code: IncompatibilityConditionalRequired,
+			Message: fmt.Sprintf("Missing property '%s' required by the %s branch of the condition at %s (%s) and no default is defined",
+				name, branch.Branch, location, branch.Condition),
+		})
+	}
+	return added, incompatibilities
+}
+
+// castInstanceToSchema transforms instance to conform to target schema; refs resolves the local
+// $refs of property schemas against the target schema document
 // see gts-python schema_cast.py _cast_instance_to_schema method
 func castInstanceToSchema(
 	instance map[string]any,
 	schema map[string]any,
 	basePath string,
-) (map[string]any, []string, []string, []string) {
+	refs *localRefResolver,
+) (map[string]any, []string, []string, []string, []IncompatibilityDetail) {
 	added := []string{}
 	removed := []string{}
-	incompatibilityReasons := []string{}
+	incompatibilities := []IncompatibilityDetail{}
 
 	if instance == nil {
-		incompatibilityReasons = append(incompatibilityReasons, "Instance must be an object for casting")
-		return nil, added, removed, incompatibilityReasons
+		incompatibilities = append(incompatibilities, IncompatibilityDetail{Path: basePath, Code: IncompatibilityNotObject, Message: "Instance must be an object for casting"})
+		return nil, added, removed, []string{}, incompatibilities
 	}
 
 	targetProps := getPropertiesMap(schema)
@@ -157,63 +335,19 @@ func castInstanceToSchema(
 	// Start from current values
 	result := copyMap(instance)
 
-	// 1) Ensure required properties exist (fill defaults if provided)
-	for reqProp := range required {
-		if _, exists := result[reqProp]; !exists {
-			propSchema := getMap(targetProps, reqProp)
-			if propSchema != nil {
-				if defaultVal, hasDefault := propSchema["default"]; hasDefault {
-					result[reqProp] = copyValue(defaultVal)
-					path := buildPath(basePath, reqProp)
-					added = append(added, path)
-				} else {
-					path := buildPath(basePath, reqProp)
-					incompatibilityReasons = append(incompatibilityReasons,
-						fmt.Sprintf("Missing required property '%s' and no default is defined", path))
-				}
-			}
-		}
-	}
-
-	// 2) For optional properties with defaults, set if missing
-	for prop, propSchemaAny := range targetProps {
-		if required[prop] {
-			continue
-		}
-		propSchema, ok := propSchemaAny.(map[string]any)
-		if !ok {
-			continue
-		}
-		if _, exists := result[prop]; !exists {
-			if defaultVal, hasDefault := propSchema["default"]; hasDefault {
-				result[prop] = copyValue(defaultVal)
-				path := buildPath(basePath, prop)
-				added = append(added, path)
-			}
-		}
+	// 1) and 2) Fill missing properties that declare a default
+	addedHere, missing := fillObjectDefaults(result, targetProps, required, basePath, refs)
+	added = append(added, addedHere...)
+	for _, path := range missing {
+		incompatibilities = append(incompatibilities, IncompatibilityDetail{
+			Path:    path,
+			Code:    IncompatibilityMissingRequired,
+			Message: fmt.Sprintf("Missing required property '%s' and no default is defined", path),
+		})
 	}
 
 	// 2.5) Update const values to match target schema (for GTS ID fields)
-	for prop, propSchemaAny := range targetProps {
-		propSchema, ok := propSchemaAny.(map[string]any)
-		if !ok {
-			continue
-		}
-		if constVal, hasConst := propSchema["const"]; hasConst {
-			if existingVal, exists := result[prop]; exists {
-				constStr, constIsStr := constVal.(string)
-				existingStr, existingIsStr := existingVal.(string)
-				if constIsStr && existingIsStr {
-					// Only update if both are GTS IDs and they differ
-					if IsValidGtsID(constStr) && IsValidGtsID(existingStr) {
-						if existingStr != constStr {
-							result[prop] = constStr
-						}
-					}
-				}
-			}
-		}
-	}
+	updated := updateGtsIDConsts(result, targetProps, basePath)
 
 	// 3) Remove properties not in target schema when additionalProperties is false
 	if !additional {
@@ -236,53 +370,194 @@ func castInstanceToSchema(
 		if !ok {
 			continue
 		}
-		propType := getString(propSchema, "type")
+		propSchema = refs.resolve(propSchema)
 
-		// Handle nested objects
-		if propType == "object" {
+		// Handle nested objects, including sub-schemas declaring properties without a type
+		if castsAsObject(propSchema) {
 			if valMap, isMap := val.(map[string]any); isMap {
-				nestedSchema := effectiveObjectSchema(propSchema)
-				newObj, addSub, remSub, incompatSub := castInstanceToSchema(
+				nestedSchema := castObjectSchema(propSchema)
+				newObj, addSub, remSub, updSub, incompatSub := castInstanceToSchema(
 					valMap,
 					nestedSchema,
 					buildPath(basePath, prop),
+					refs,
 				)
 				result[prop] = newObj
 				added = append(added, addSub...)
 				removed = append(removed, remSub...)
-				incompatibilityReasons = append(incompatibilityReasons, incompatSub...)
+				updated = append(updated, updSub...)
+				incompatibilities = append(incompatibilities, incompatSub...)
 			}
 		}
 
-		// Handle arrays of objects
-		if propType == "array" {
+		// Handle arrays of objects and tuples
+		if getString(propSchema, "type") == "array" {
 			if valArray, isArray := val.([]any); isArray {
-				itemsSchema := getMap(propSchema, "items")
-				if itemsSchema != nil && getString(itemsSchema, "type") == "object" {
-					nestedSchema := effectiveObjectSchema(itemsSchema)
-					newList := []any{}
-					for idx, item := range valArray {
-						if itemMap, isMap := item.(map[string]any); isMap {
-							newItem, addSub, remSub, incompatSub := castInstanceToSchema(
-								itemMap,
-								nestedSchema,
-								buildPath(basePath, fmt.Sprintf("%s[%d]", prop, idx)),
-							)
-							newList = append(newList, newItem)
-							added = append(added, addSub...)
-							removed = append(removed, remSub...)
-							incompatibilityReasons = append(incompatibilityReasons, incompatSub...)
-						} else {
-							newList = append(newList, item)
-						}
-					}
+				newList, addSub, remSub, updSub, incompatSub := castArrayToSchema(valArray, propSchema, buildPath(basePath, prop), refs)
+				if newList != nil {
 					result[prop] = newList
 				}
+				added = append(added, addSub...)
+				removed = append(removed, remSub...)
+				updated = append(updated, updSub...)
+				incompatibilities = append(incompatibilities, incompatSub...)
+			}
+		}
+	}
+
+	return result, added, removed, updated, incompatibilities
+}
+
+// fillObjectDefaults sets the missing properties of obj that declare a default in targetProps,
+// required ones first, and returns the paths of the added properties and of the missing required
+// properties without default
+func fillObjectDefaults(obj, targetProps map[string]any, required map[string]bool, basePath string, refs *localRefResolver) ([]string, []string) {
+	var added, missing []string
+	for _, pass := range []bool{true, false} {
+		for _, prop := range sortedKeys(targetProps) {
+			if required[prop] != pass {
+				continue
+			}
+			if _, exists := obj[prop]; exists {
+				continue
+			}
+			propSchema, ok := targetProps[prop].(map[string]any)
+			if !ok {
+				continue
+			}
+			path := buildPath(basePath, prop)
+			if defaultVal, hasDefault := refs.resolve(propSchema)["default"]; hasDefault {
+				obj[prop] = copyValue(defaultVal)
+				added = append(added, path)
+			} else if pass {
+				missing = append(missing, path)
+			}
+		}
+	}
+	return added, missing
+}
+
+// updateGtsIDConsts rewrites the values of properties whose target schema declares a const GTS ID,
+// when the existing value is a different GTS ID, and returns the paths of the rewritten values
+func updateGtsIDConsts(result, targetProps map[string]any, basePath string) []string {
+	updated := []string{}
+	for _, prop := range sortedKeys(targetProps) {
+		propSchema, ok := targetProps[prop].(map[string]any)
+		if !ok {
+			continue
+		}
+		constVal, hasConst := propSchema["const"]
+		existingVal, exists := result[prop]
+		if !hasConst || !exists {
+			continue
+		}
+		constStr, constIsStr := constVal.(string)
+		existingStr, existingIsStr := existingVal.(string)
+		// Only update if both are GTS IDs and they differ
+		if constIsStr && existingIsStr && existingStr != constStr && IsValidGtsID(constStr) && IsValidGtsID(existingStr) {
+			result[prop] = constStr
+			updated = append(updated, buildPath(basePath, prop))
+		}
+	}
+	return updated
+}
+
+// castsAsObject reports whether instance values under schema are cast as objects: the schema has
+// type object, or declares no type but describes object properties directly or through allOf
+func castsAsObject(schema map[string]any) bool {
+	if schema == nil {
+		return false
+	}
+	if _, hasType := schema["type"]; hasType {
+		return getString(schema, "type") == "object"
+	}
+	_, hasProps := effectiveObjectSchema(schema)["properties"]
+	return hasProps
+}
+
+// castArrayToSchema casts the elements of an array to their item schemas
+// Tuple positions (prefixItems) are cast against their own schema; remaining elements use items.
+// When the tuple is closed (items: false) surplus elements are dropped. Elements that are arrays
+// themselves are cast against their array item schema in turn.
+// It returns nil when the array is left untouched.
+func castArrayToSchema(values []any, schema map[string]any, path string, refs *localRefResolver) ([]any, []string, []string, []string, []IncompatibilityDetail) {
+	added := []string{}
+	removed := []string{}
+	updated := []string{}
+	incompatibilities := []IncompatibilityDetail{}
+
+	tuple := getTupleItems(schema)
+	for i := range tuple {
+		tuple[i] = refs.resolve(tuple[i])
+	}
+	itemsSchema := refs.resolve(getMap(schema, "items"))
+	closedTuple := false
+	if itemsVal, ok := schema["items"].(bool); ok && !itemsVal && len(tuple) > 0 {
+		closedTuple = true
+	}
+
+	castsElements := func(elemSchema map[string]any) bool {
+		return castsAsObject(elemSchema) || getString(elemSchema, "type") == "array"
+	}
+	touched := closedTuple && len(values) > len(tuple)
+	for i := range tuple {
+		touched = touched || castsElements(tuple[i])
+	}
+	touched = touched || castsElements(itemsSchema)
+	if !touched {
+		return nil, added, removed, updated, incompatibilities
+	}
+
+	newList := []any{}
+	for idx, item := range values {
+		itemPath := buildIndexPath(path, idx)
+
+		var elemSchema map[string]any
+		if idx < len(tuple) {
+			elemSchema = tuple[idx]
+		} else if closedTuple {
+			removed = append(removed, itemPath)
+			continue
+		} else {
+			elemSchema = itemsSchema
+		}
+
+		var newItem any = item
+		var addSub, remSub, updSub []string
+		var incompatSub []IncompatibilityDetail
+		switch value := item.(type) {
+		case map[string]any:
+			if castsAsObject(elemSchema) {
+				newItem, addSub, remSub, updSub, incompatSub = castInstanceToSchema(value, castObjectSchema(elemSchema), itemPath, refs)
+			}
+		case []any:
+			if getString(elemSchema, "type") == "array" {
+				var newNested []any
+				newNested, addSub, remSub, updSub, incompatSub = castArrayToSchema(value, elemSchema, itemPath, refs)
+				if newNested != nil {
+					newItem = newNested
+				}
 			}
 		}
+		newList = append(newList, newItem)
+		added = append(added, addSub...)
+		removed = append(removed, remSub...)
+		updated = append(updated, updSub...)
+		incompatibilities = append(incompatibilities, incompatSub...)
 	}
 
-	return result, added, removed, incompatibilityReasons
+	return newList, added, removed, updated, incompatibilities
+}
+
+// castObjectSchema returns the schema nested values are cast against: the flattened schema when
+// its allOf layers are closed by unevaluatedProperties: false, so that properties declared by any
+// layer are kept and the others removed, and the effective object schema otherwise
+func castObjectSchema(schema map[string]any) map[string]any {
+	flat := flattenSchema(schema)
+	if closed, ok := flat["unevaluatedProperties"].(bool); ok && !closed {
+		return flat
+	}
+	return effectiveObjectSchema(schema)
 }
 
 // effectiveObjectSchema extracts the object schema from allOf if needed
@@ -332,10 +607,8 @@ func validateWithGtsIDTolerance(instance, schema map[string]any, store *GtsStore
 	compiler.UseLoader(&gtsURLLoader{store: store})
 
 	// Pre-load all schemas from the store
-	for id, entity := range store.byID {
-		if entity.IsSchema {
-			compiler.AddResource(id, entity.Content)
-		}
+	for id, content := range store.schemaContents() {
+		compiler.AddResource(id, content)
 	}
 
 	// Add the modified schema as a resource
@@ -388,7 +661,11 @@ func removeGtsConstConstraints(schema any) any {
 // Helper functions
 
 // getAdditionalProperties safely extracts additionalProperties (defaults to true)
+// unevaluatedProperties: false closes the model the same way additionalProperties: false does.
 func getAdditionalProperties(schema map[string]any) bool {
+	if val, ok := schema["unevaluatedProperties"].(bool); ok && !val {
+		return false
+	}
 	if val, ok := schema["additionalProperties"]; ok {
 		if boolVal, ok := val.(bool); ok {
 			return boolVal
@@ -397,18 +674,23 @@ func getAdditionalProperties(schema map[string]any) bool {
 	return true // Default is true if not specified
 }
 
-// buildPath constructs a property path for error messages
+// buildPath constructs a property path for error messages; a prop in brackets, such as "[0]" or
+// "allOf[1]" after a base, is an index segment appended without a dot
 func buildPath(base, prop string) string {
 	if base == "" {
 		return prop
 	}
-	// Handle array indices that already have brackets
-	if strings.HasPrefix(prop, "[") {
+	if strings.HasPrefix(prop, "[") && strings.HasSuffix(prop, "]") {
 		return base + prop
 	}
 	return base + "." + prop
 }
 
+// buildIndexPath constructs the path of an array element, e.g. "lines[0]" or "grid[1][0]"
+func buildIndexPath(base string, idx int) string {
+	return fmt.Sprintf("%s[%d]", base, idx)
+}
+
 // copyMap creates a deep copy of a map
 func copyMap(m map[string]any) map[string]any {
 	if m == nil {
@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+)
+
+// Outcomes of a BatchCastItem
+const (
+	BatchCastCast    = "cast"
+	BatchCastFailed  = "failed"
+	BatchCastSkipped = "skipped"
+)
+
+// BatchCastItem is the outcome of casting one instance of a batch
+type BatchCastItem struct {
+	InstanceID string `json:"instance_id"`
+	// Status is BatchCastCast, BatchCastFailed or BatchCastSkipped
+	Status string      `json:"status"`
+	Result *CastResult `json:"result,omitempty"`
+	// Reason tells why the instance was skipped or failed
+	Reason string `json:"reason,omitempty"`
+	// Err is the error behind a failure, for callers inspecting its type
+	Err error `json:"-"`
+}
+
+// BatchCastResult reports the cast of the instances matching a pattern to a target schema
+type BatchCastResult struct {
+	Pattern    string `json:"pattern"`
+	ToSchemaID string `json:"to_schema_id"`
+	// Matched counts the instances matching the pattern; each was cast, skipped or failed
+	Matched         int             `json:"matched"`
+	Succeeded       int             `json:"succeeded"`
+	Failed          int             `json:"failed"`
+	Skipped         int             `json:"skipped"`
+	FullyCompatible int             `json:"fully_compatible"`
+	Items           []BatchCastItem `json:"items"`
+	Error           string          `json:"error,omitempty"`
+	// Err is set when the batch could not run: the pattern is invalid or the target schema is missing
+	Err error `json:"-"`
+}
+
+// CastAll casts every instance matching a query expression (a pattern with optional filters, as
+// accepted by Query) to the target schema, up to limit instances; limit <= 0 means no limit.
+// Schemas matching the pattern are left out. Instances whose schema is a different type or major
+// version than the target are skipped with a reason, unless a migration map covers their schema
+// (see MigrationMapTypeID), and a failed cast does not stop the batch.
+// Instances are visited in store order, which is unspecified unless RegistryConfig.StableOrder is set.
+func (s *GtsStore) CastAll(pattern string, toSchemaID string, limit int) *BatchCastResult {
+	result := &BatchCastResult{Pattern: pattern, ToSchemaID: toSchemaID, Items: []BatchCastItem{}}
+	fail := func(err error) *BatchCastResult {
+		result.Error, result.Err = err.Error(), err
+		return result
+	}
+
+	target, err := s.ResolveSchema(toSchemaID)
+	if err != nil {
+		return fail(err)
+	}
+	result.ToSchemaID = target.GtsID.ID
+	targetKey := majorVersionKey(target.GtsID)
+
+	var instances []*JsonEntity
+	err = s.QueryStream(pattern, func(item QueryItem) bool {
+		if entity := s.getExact(item.ID); entity != nil && !entity.IsSchema {
+			instances = append(instances, entity)
+		}
+		return limit <= 0 || len(instances) < limit
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, instance := range instances {
+		item := BatchCastItem{InstanceID: instance.GtsID.ID}
+		result.Matched++
+
+		schemaID, _, err := s.resolveInstanceSchema(instance)
+		if err == nil {
+			var fromID *GtsID
+			if fromID, err = NewGtsID(schemaID); err == nil && majorVersionKey(fromID) != targetKey && !s.hasMigrationMap(fromID, target.GtsID) {
+				item.Status = BatchCastSkipped
+				item.Reason = fmt.Sprintf("schema '%s' is not a version of the same type and major version as '%s'", schemaID, result.ToSchemaID)
+				result.Skipped++
+				result.Items = append(result.Items, item)
+				continue
+			}
+		}
+		if err == nil {
+			item.Result, err = s.Cast(instance.GtsID.ID, result.ToSchemaID)
+		}
+		if err != nil {
+			item.Status, item.Reason, item.Err = BatchCastFailed, err.Error(), err
+			result.Failed++
+		} else {
+			item.Status = BatchCastCast
+			result.Succeeded++
+			if item.Result.IsFullyCompatible {
+				result.FullyCompatible++
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result
+}
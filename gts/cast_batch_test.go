@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCastAll(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{StableOrder: true})
+	schemas := map[string]map[string]any{
+		"gts.x.test.batch.order.v1.0~": {"total": map[string]any{"type": "number"}},
+		"gts.x.test.batch.order.v1.1~": {
+			"total":    map[string]any{"type": "number"},
+			"currency": map[string]any{"type": "string", "default": "EUR"},
+		},
+		"gts.x.test.batch.order.v2.0~": {"amount": map[string]any{"type": "number"}},
+	}
+	for id, props := range schemas {
+		props["id"] = map[string]any{"type": "string"}
+		schema := map[string]any{
+			"$schema":    "http://json-schema.org/draft-07/schema#",
+			"type":       "object",
+			"required":   []any{"id"},
+			"properties": props,
+		}
+		if err := store.RegisterSchema(id, schema); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+	for _, content := range []map[string]any{
+		{"id": "gts.x.test.batch.order.v1.0~x.test._.a.v1", "total": 10},
+		{"id": "gts.x.test.batch.order.v1.0~x.test._.b.v1", "total": 20},
+		{"id": "gts.x.test.batch.order.v1.0~x.test._.c.v1", "total": "thirty"},
+		{"id": "gts.x.test.batch.order.v2.0~x.test._.d.v1", "amount": 40},
+	} {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %v: %v", content["id"], err)
+		}
+	}
+
+	result := store.CastAll("gts.x.test.batch.*", "gts.x.test.batch.order.v1~", 0)
+	if result.Err != nil {
+		t.Fatalf("CastAll: %v", result.Err)
+	}
+	if result.ToSchemaID != "gts.x.test.batch.order.v1.1~" {
+		t.Errorf("Expected the target to resolve to v1.1, got %s", result.ToSchemaID)
+	}
+	if result.Matched != 4 || result.Succeeded != 3 || result.Skipped != 1 || result.Failed != 0 {
+		t.Errorf("Unexpected counts: matched %d, succeeded %d, skipped %d, failed %d",
+			result.Matched, result.Succeeded, result.Skipped, result.Failed)
+	}
+	if result.FullyCompatible != 2 {
+		t.Errorf("Expected 2 fully compatible casts, got %d", result.FullyCompatible)
+	}
+	if cast := result.Items[0].Result; cast == nil || cast.CastedEntity["currency"] != "EUR" {
+		t.Errorf("Expected the default of v1.1 to be applied, got %+v", result.Items[0])
+	}
+	last := result.Items[len(result.Items)-1]
+	if last.Status != BatchCastSkipped || last.Reason == "" {
+		t.Errorf("Expected the v2.0 instance to be skipped with a reason, got %+v", last)
+	}
+
+	if limited := store.CastAll("gts.x.test.batch.*", "gts.x.test.batch.order.v1.1~", 2); limited.Matched != 2 {
+		t.Errorf("Expected the limit to cap the batch at 2, got %d", limited.Matched)
+	}
+
+	var notFound *StoreGtsSchemaNotFoundError
+	if missing := store.CastAll("gts.x.test.*", "gts.x.test.batch.missing.v1~", 0); !errors.As(missing.Err, &notFound) {
+		t.Errorf("Expected StoreGtsSchemaNotFoundError for a missing target, got %v", missing.Err)
+	}
+}
@@ -6,11 +6,17 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
-func TestCast_MinorVersionUpcast(t *testing.T) {
-	store := NewGtsStore(nil)
+// registerOrderPlacedSchemas registers the base event schema and the order_placed v1.0 and v1.1
+// schemas derived from it; v1.1 adds payload.new_field_in_v1_1 with a default
+func registerOrderPlacedSchemas(t *testing.T, store *GtsStore) {
+	t.Helper()
 
 	// Register base event schema
 	baseSchema := map[string]any{
@@ -107,6 +113,11 @@ func TestCast_MinorVersionUpcast(t *testing.T) {
 	if err := store.Register(v11Entity); err != nil {
 		t.Fatalf("Failed to register v1.1 schema: %v", err)
 	}
+}
+
+func TestCast_MinorVersionUpcast(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerOrderPlacedSchemas(t, store)
 
 	// Register v1.0 instance
 	v10Instance := map[string]any{
@@ -522,6 +533,45 @@ func TestCast_ArrayOfObjects(t *testing.T) {
 	}
 }
 
+func TestCastContent(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerOrderPlacedSchemas(t, store)
+	before := store.Count()
+
+	content := map[string]any{
+		"type":       "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~",
+		"id":         "af0e3c1b-8f1e-4a27-9a9b-b7b9b70c1f01",
+		"tenantId":   "11111111-2222-3333-4444-555555555555",
+		"occurredAt": "2025-09-20T18:35:00Z",
+		"payload": map[string]any{
+			"orderId":     "af0e3c1b-8f1e-4a27-9a9b-b7b9b70c1f01",
+			"customerId":  "0f2e4a9b-1c3d-4e5f-8a9b-0c1d2e3f4a5b",
+			"totalAmount": 149.99,
+			"items":       []any{},
+		},
+	}
+	result, err := store.CastContent(content, "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.1~")
+	if err != nil {
+		t.Fatalf("CastContent failed: %v", err)
+	}
+	payload, _ := result.CastedEntity["payload"].(map[string]any)
+	if payload["new_field_in_v1_1"] != "some_value" {
+		t.Errorf("Expected the default of the new field, got: %v", result.CastedEntity)
+	}
+	if result.FromID != "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~" {
+		t.Errorf("Expected the source schema as FromID, got: %s", result.FromID)
+	}
+	if store.Count() != before {
+		t.Errorf("Expected the store to be unchanged, got %d entities instead of %d", store.Count(), before)
+	}
+
+	_, err = store.CastContent(map[string]any{"name": "untyped"}, "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.1~")
+	var notFound *StoreGtsSchemaForInstanceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected StoreGtsSchemaForInstanceNotFoundError for content without schema, got: %v", err)
+	}
+}
+
 func TestCast_InstanceNotFound(t *testing.T) {
 	store := NewGtsStore(nil)
 
@@ -625,3 +675,174 @@ func TestCast_MissingRequiredFieldNoDefault(t *testing.T) {
 		t.Error("Expected incompatibility reasons for missing required field")
 	}
 }
+
+func TestCast_NestedArrayMissingRequired(t *testing.T) {
+	store := NewGtsStore(nil)
+
+	// v1.0 requires the sku of order lines and the cell of grid entries, v1.1 made both optional
+	schemaFor := func(minor string, required []any) map[string]any {
+		line := map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"sku": map[string]any{"type": "string"}, "qty": map[string]any{"type": "integer"}},
+		}
+		entry := map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"cell": map[string]any{"type": "string"}},
+		}
+		if required != nil {
+			line["required"] = []any{"sku"}
+			entry["required"] = []any{"cell"}
+		}
+		return map[string]any{
+			"$id":     "gts://gts.x.test.nested.basket.v1." + minor + "~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				"id": map[string]any{"type": "string"},
+				"orders": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"lines": map[string]any{"type": "array", "items": line}},
+					},
+				},
+				"grid": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "array", "items": entry},
+				},
+			},
+		}
+	}
+	for _, schema := range []map[string]any{schemaFor("0", []any{"sku"}), schemaFor("1", nil)} {
+		if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register schema: %v", err)
+		}
+	}
+	instanceID := "gts.x.test.nested.basket.v1.1~x.test._.b1.v1"
+	instance := map[string]any{
+		"id": instanceID,
+		"orders": []any{
+			map[string]any{"lines": []any{map[string]any{"sku": "a", "qty": 1}, map[string]any{"qty": 2}}},
+		},
+		"grid": []any{
+			[]any{map[string]any{"cell": "a1"}},
+			[]any{map[string]any{}},
+		},
+	}
+	if err := store.Register(NewJsonEntity(instance, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+
+	result, err := store.Cast(instanceID, "gts.x.test.nested.basket.v1.0~")
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	targetID := "gts.x.test.nested.basket.v1.0~"
+	expected := []IncompatibilityDetail{
+		{Path: "grid[1][0].cell", Code: IncompatibilityMissingRequired, SchemaID: targetID, Message: "Missing required property 'grid[1][0].cell' and no default is defined"},
+		{Path: "orders[0].lines[1].sku", Code: IncompatibilityMissingRequired, SchemaID: targetID, Message: "Missing required property 'orders[0].lines[1].sku' and no default is defined"},
+	}
+	var missing []IncompatibilityDetail
+	for _, detail := range result.IncompatibilityDetails {
+		if detail.Code == IncompatibilityMissingRequired {
+			missing = append(missing, detail)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Path < missing[j].Path })
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Expected the nested missing requirements %+v, got %+v", expected, missing)
+	}
+	if len(result.IncompatibilityReasons) != len(result.IncompatibilityDetails) {
+		t.Fatalf("Expected a reason per detail, got %v", result.IncompatibilityReasons)
+	}
+	for i, detail := range result.IncompatibilityDetails {
+		if result.IncompatibilityReasons[i] != detail.String() || !strings.HasSuffix(detail.String(), "(target schema "+targetID+")") {
+			t.Errorf("Expected reason %d to name the target schema, got %q", i, result.IncompatibilityReasons[i])
+		}
+	}
+}
+
+func TestCast_NestedConstUpdated(t *testing.T) {
+	store := NewGtsStore(nil)
+
+	// The discriminator lives in payload.meta.type, under a sub-schema declaring no type, and in
+	// the entries of payload.links
+	schemaFor := func(minor string) map[string]any {
+		typeID := "gts.x.test.nested.event.v1." + minor + "~"
+		return map[string]any{
+			"$id":     "gts://" + typeID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				"gtsId": map[string]any{"type": "string"},
+				"payload": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"meta": map[string]any{
+							"required": []any{"type"},
+							"properties": map[string]any{
+								"type": map[string]any{"type": "string", "const": typeID},
+							},
+						},
+						"links": map[string]any{
+							"type": "array",
+							"items": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"type": map[string]any{"type": "string", "const": typeID},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	for _, minor := range []string{"0", "1"} {
+		if err := store.Register(NewJsonEntity(schemaFor(minor), DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register schema v1.%s: %v", minor, err)
+		}
+	}
+
+	instanceID := "gts.x.test.nested.event.v1.0~x.test.nested.inst.v1.0"
+	instance := map[string]any{
+		"gtsId": instanceID,
+		"payload": map[string]any{
+			"meta": map[string]any{"type": "gts.x.test.nested.event.v1.0~"},
+			"links": []any{
+				map[string]any{"type": "gts.x.test.nested.event.v1.0~"},
+				map[string]any{"type": "not a gts id"},
+			},
+		},
+	}
+	if err := store.Register(NewJsonEntity(instance, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+
+	result, err := store.Cast(instanceID, "gts.x.test.nested.event.v1.1~")
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+
+	payload := result.CastedEntity["payload"].(map[string]any)
+	if got := payload["meta"].(map[string]any)["type"]; got != "gts.x.test.nested.event.v1.1~" {
+		t.Errorf("Expected payload.meta.type to be updated, got: %v", got)
+	}
+	links := payload["links"].([]any)
+	if got := links[0].(map[string]any)["type"]; got != "gts.x.test.nested.event.v1.1~" {
+		t.Errorf("Expected payload.links[0].type to be updated, got: %v", got)
+	}
+	if got := links[1].(map[string]any)["type"]; got != "not a gts id" {
+		t.Errorf("Expected a value that is not a GTS ID to be kept, got: %v", got)
+	}
+
+	want := []string{"payload.links[0].type", "payload.meta.type"}
+	if len(result.UpdatedProperties) != len(want) {
+		t.Fatalf("Expected updated properties %v, got: %v", want, result.UpdatedProperties)
+	}
+	for _, path := range want {
+		if !anyContains(result.UpdatedProperties, path) {
+			t.Errorf("Expected %s in updated properties, got: %v", path, result.UpdatedProperties)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CloudEvents attribute names used by the GTS mapping
+const (
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsGtsIDExtension carries the GTS ID of a well-known instance, which the
+	// CloudEvents id (a UUID) cannot be reversed into
+	CloudEventsGtsIDExtension = "gtsid"
+)
+
+// CloudEventsConfig configures the mapping between GTS instances and CloudEvents envelopes
+type CloudEventsConfig struct {
+	// GtsConfig is used to extract IDs from instances; nil uses DefaultGtsConfig
+	GtsConfig *GtsConfig
+	// Source is the CloudEvents source attribute (default "gts")
+	Source string
+	// TimeField is the instance field copied to the time attribute (default "occurredAt")
+	TimeField string
+	// DataField selects the instance field carried as data; empty carries the whole instance.
+	// With a data field, unwrapping rebuilds the instance from the id, type, time and data only.
+	DataField string
+	// IDField and TypeField name the instance fields rebuilt from the gtsid extension and the type
+	// attribute when DataField is set (defaults "id" and "type")
+	IDField   string
+	TypeField string
+	// Validate makes FromCloudEvent validate the instance against its schema
+	Validate bool
+}
+
+// DefaultCloudEventsConfig returns the default CloudEvents mapping
+func DefaultCloudEventsConfig() *CloudEventsConfig {
+	return &CloudEventsConfig{
+		Source:    "gts",
+		TimeField: "occurredAt",
+		IDField:   "id",
+		TypeField: "type",
+	}
+}
+
+// withDefaults returns a copy of cfg with empty settings replaced by their defaults
+func (c *CloudEventsConfig) withDefaults() *CloudEventsConfig {
+	result := DefaultCloudEventsConfig()
+	if c == nil {
+		return result
+	}
+	merged := *c
+	if merged.Source == "" {
+		merged.Source = result.Source
+	}
+	if merged.TimeField == "" {
+		merged.TimeField = result.TimeField
+	}
+	if merged.IDField == "" {
+		merged.IDField = result.IDField
+	}
+	if merged.TypeField == "" {
+		merged.TypeField = result.TypeField
+	}
+	return &merged
+}
+
+// CloudEventError reports an envelope or instance that cannot be mapped
+type CloudEventError struct {
+	Attribute string
+	Reason    string
+}
+
+func (e *CloudEventError) Error() string {
+	if e.Attribute == "" {
+		return fmt.Sprintf("Invalid CloudEvent: %s", e.Reason)
+	}
+	return fmt.Sprintf("Invalid CloudEvent attribute '%s': %s", e.Attribute, e.Reason)
+}
+
+// ToCloudEvent wraps a GTS instance into a structured-mode CloudEvents 1.0 JSON envelope.
+// The type attribute is the instance's schema ID and dataschema its gts:// URI. The id is the
+// deterministic UUID of the instance's GTS ID, or a random UUID for anonymous instances; the GTS
+// ID itself is kept in the gtsid extension attribute.
+func ToCloudEvent(instance map[string]any, cfg *CloudEventsConfig) (map[string]any, error) {
+	cfg = cfg.withDefaults()
+
+	entity := NewJsonEntity(instance, cfg.GtsConfig)
+	if entity.IsSchema {
+		return nil, &CloudEventError{Reason: "schemas cannot be wrapped, only instances"}
+	}
+	if entity.SchemaID == "" {
+		return nil, &CloudEventError{Attribute: "type", Reason: "instance has no schema ID"}
+	}
+
+	envelope := map[string]any{
+		"specversion":     CloudEventsSpecVersion,
+		"source":          cfg.Source,
+		"type":            entity.SchemaID,
+		"dataschema":      GtsURIPrefix + entity.SchemaID,
+		"datacontenttype": "application/json",
+	}
+	if entity.GtsID != nil {
+		envelope["id"] = entity.GtsID.ToUUID().String()
+		envelope[CloudEventsGtsIDExtension] = entity.GtsID.ID
+	} else {
+		envelope["id"] = uuid.NewString()
+	}
+
+	if t, ok := instance[cfg.TimeField].(string); ok && t != "" {
+		envelope["time"] = t
+	}
+
+	if cfg.DataField == "" {
+		envelope["data"] = instance
+	} else {
+		data, ok := instance[cfg.DataField]
+		if !ok {
+			return nil, &CloudEventError{Attribute: "data", Reason: fmt.Sprintf("instance has no '%s' field", cfg.DataField)}
+		}
+		envelope["data"] = data
+	}
+
+	return envelope, nil
+}
+
+// FromCloudEvent rebuilds a GTS instance from a structured-mode CloudEvents envelope.
+// The schema is resolved from the dataschema attribute when present and the type attribute
+// otherwise; both must name the same GTS type when both are given, and the schema must be
+// registered in store. With cfg.Validate the instance is validated against the schema.
+// The instance is returned as an entity; it is not registered.
+func FromCloudEvent(envelope map[string]any, store *GtsStore, cfg *CloudEventsConfig) (*JsonEntity, error) {
+	cfg = cfg.withDefaults()
+
+	if v, _ := envelope["specversion"].(string); v != CloudEventsSpecVersion {
+		return nil, &CloudEventError{Attribute: "specversion", Reason: fmt.Sprintf("expected %s, got %v", CloudEventsSpecVersion, envelope["specversion"])}
+	}
+
+	ceType, _ := envelope["type"].(string)
+	if ceType == "" {
+		return nil, &CloudEventError{Attribute: "type", Reason: "missing"}
+	}
+	schemaID := ceType
+	if dataschema, ok := envelope["dataschema"].(string); ok && dataschema != "" {
+		schemaID = strings.TrimPrefix(dataschema, GtsURIPrefix)
+		if schemaID != ceType {
+			return nil, &CloudEventError{Attribute: "dataschema", Reason: fmt.Sprintf("%s does not match type %s", dataschema, ceType)}
+		}
+	}
+	schemaGtsID, err := NewGtsID(schemaID)
+	if err != nil || !schemaGtsID.IsType() {
+		return nil, &CloudEventError{Attribute: "type", Reason: fmt.Sprintf("not a GTS type ID: %s", schemaID)}
+	}
+
+	schema := store.Get(schemaGtsID.ID)
+	if schema == nil || !schema.IsSchema {
+		return nil, &StoreGtsSchemaNotFoundError{EntityID: schemaGtsID.ID}
+	}
+
+	var content map[string]any
+	if cfg.DataField == "" {
+		data, ok := envelope["data"].(map[string]any)
+		if !ok {
+			return nil, &CloudEventError{Attribute: "data", Reason: "must be a JSON object holding the instance"}
+		}
+		content = copyMap(data)
+	} else {
+		content = map[string]any{
+			cfg.TypeField: schemaGtsID.ID,
+			cfg.DataField: envelope["data"],
+		}
+		if id, ok := envelope[CloudEventsGtsIDExtension].(string); ok && id != "" {
+			content[cfg.IDField] = id
+		}
+		if t, ok := envelope["time"].(string); ok && t != "" {
+			content[cfg.TimeField] = t
+		}
+	}
+
+	entity := NewJsonEntity(content, cfg.GtsConfig)
+	if entity.IsSchema {
+		return nil, &CloudEventError{Attribute: "data", Reason: "holds a schema, not an instance"}
+	}
+	if entity.SchemaID != schemaGtsID.ID {
+		return nil, &CloudEventError{Attribute: "type", Reason: fmt.Sprintf("instance schema %q does not match type %s", entity.SchemaID, schemaGtsID.ID)}
+	}
+
+	if cfg.Validate {
+		if err := store.validateWithSchema(entity.Content, schema.Content); err != nil {
+			return nil, fmt.Errorf("instance does not match schema %s: %w", schemaGtsID.ID, err)
+		}
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const orderPlacedTypeID = "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~"
+
+func newOrderPlacedStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	schemas := []map[string]any{
+		{
+			"$id":      "gts://gts.x.core.events.type.v1~",
+			"$schema":  "http://json-schema.org/draft-07/schema#",
+			"type":     "object",
+			"required": []any{"id", "type", "occurredAt"},
+			"properties": map[string]any{
+				"id":         map[string]any{"type": "string"},
+				"type":       map[string]any{"type": "string"},
+				"occurredAt": map[string]any{"type": "string", "format": "date-time"},
+				"payload":    map[string]any{"type": "object"},
+			},
+		},
+		{
+			"$id":     orderPlacedTypeID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"allOf": []any{
+				map[string]any{"$ref": "gts.x.core.events.type.v1~"},
+				map[string]any{
+					"type":     "object",
+					"required": []any{"payload"},
+					"properties": map[string]any{
+						"payload": map[string]any{
+							"type":     "object",
+							"required": []any{"orderId", "totalAmount"},
+							"properties": map[string]any{
+								"orderId":     map[string]any{"type": "string"},
+								"totalAmount": map[string]any{"type": "number"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, schema := range schemas {
+		if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register schema: %v", err)
+		}
+	}
+	return store
+}
+
+func orderPlacedInstance() map[string]any {
+	return map[string]any{
+		"type":       orderPlacedTypeID,
+		"id":         orderPlacedTypeID + "x.y._.some_event.v1.0",
+		"tenantId":   "11111111-2222-3333-4444-555555555555",
+		"occurredAt": "2025-09-20T18:35:00Z",
+		"payload": map[string]any{
+			"orderId":     "af0e3c1b-8f1e-4a27-9a9b-b7b9b70c1f01",
+			"totalAmount": 149.99,
+		},
+	}
+}
+
+func TestCloudEvents_RoundTrip(t *testing.T) {
+	store := newOrderPlacedStore(t)
+	instance := orderPlacedInstance()
+
+	envelope, err := ToCloudEvent(instance, nil)
+	if err != nil {
+		t.Fatalf("ToCloudEvent failed: %v", err)
+	}
+	expected := map[string]any{
+		"specversion": "1.0",
+		"type":        orderPlacedTypeID,
+		"dataschema":  "gts://" + orderPlacedTypeID,
+		"id":          IDToUUID(instance["id"].(string)).UUID,
+		"time":        "2025-09-20T18:35:00Z",
+		"gtsid":       instance["id"],
+		"source":      "gts",
+	}
+	for k, v := range expected {
+		if envelope[k] != v {
+			t.Errorf("Expected %s=%v, got %v", k, v, envelope[k])
+		}
+	}
+
+	entity, err := FromCloudEvent(envelope, store, &CloudEventsConfig{Validate: true})
+	if err != nil {
+		t.Fatalf("FromCloudEvent failed: %v", err)
+	}
+	if !reflect.DeepEqual(entity.Content, instance) {
+		t.Errorf("Expected round-tripped instance %v, got %v", instance, entity.Content)
+	}
+	if entity.GtsID == nil || entity.GtsID.ID != instance["id"] || entity.SchemaID != orderPlacedTypeID {
+		t.Errorf("Unexpected entity IDs: %+v", entity)
+	}
+}
+
+func TestCloudEvents_DataField(t *testing.T) {
+	store := newOrderPlacedStore(t)
+	instance := orderPlacedInstance()
+	cfg := &CloudEventsConfig{DataField: "payload", Validate: true}
+
+	envelope, err := ToCloudEvent(instance, cfg)
+	if err != nil {
+		t.Fatalf("ToCloudEvent failed: %v", err)
+	}
+	if !reflect.DeepEqual(envelope["data"], instance["payload"]) {
+		t.Errorf("Expected payload as data, got %v", envelope["data"])
+	}
+
+	entity, err := FromCloudEvent(envelope, store, cfg)
+	if err != nil {
+		t.Fatalf("FromCloudEvent failed: %v", err)
+	}
+	delete(instance, "tenantId")
+	if !reflect.DeepEqual(entity.Content, instance) {
+		t.Errorf("Expected %v, got %v", instance, entity.Content)
+	}
+}
+
+func TestCloudEvents_AnonymousInstance(t *testing.T) {
+	instance := map[string]any{
+		"id":   "7a1d2f34-5b6c-4d7e-8f90-a1b2c3d4e5f6",
+		"type": orderPlacedTypeID,
+	}
+	first, err := ToCloudEvent(instance, nil)
+	if err != nil {
+		t.Fatalf("ToCloudEvent failed: %v", err)
+	}
+	second, _ := ToCloudEvent(instance, nil)
+	if first["id"] == "" || first["id"] == second["id"] {
+		t.Errorf("Expected generated event IDs, got %v and %v", first["id"], second["id"])
+	}
+	if _, ok := first["gtsid"]; ok {
+		t.Error("Expected no gtsid extension for an anonymous instance")
+	}
+}
+
+func TestCloudEvents_MissingDataschema(t *testing.T) {
+	store := newOrderPlacedStore(t)
+	envelope, _ := ToCloudEvent(orderPlacedInstance(), nil)
+	delete(envelope, "dataschema")
+
+	// The schema is resolved from the type attribute
+	if _, err := FromCloudEvent(envelope, store, &CloudEventsConfig{Validate: true}); err != nil {
+		t.Errorf("Expected type to resolve the schema, got %v", err)
+	}
+
+	envelope["dataschema"] = "gts://gts.x.core.events.type.v1~"
+	var ceErr *CloudEventError
+	if _, err := FromCloudEvent(envelope, store, nil); !errors.As(err, &ceErr) || ceErr.Attribute != "dataschema" {
+		t.Errorf("Expected dataschema mismatch error, got %v", err)
+	}
+}
+
+func TestCloudEvents_UnknownType(t *testing.T) {
+	store := newOrderPlacedStore(t)
+
+	envelope, _ := ToCloudEvent(map[string]any{
+		"id": "gts.x.core.events.type.v1~x.commerce.orders.order_cancelled.v1.0~x.y._.ev.v1.0",
+	}, nil)
+	var notFound *StoreGtsSchemaNotFoundError
+	if _, err := FromCloudEvent(envelope, store, nil); !errors.As(err, &notFound) {
+		t.Errorf("Expected schema not found error, got %v", err)
+	}
+
+	envelope["type"] = "com.example.order.placed"
+	delete(envelope, "dataschema")
+	var ceErr *CloudEventError
+	if _, err := FromCloudEvent(envelope, store, nil); !errors.As(err, &ceErr) || ceErr.Attribute != "type" {
+		t.Errorf("Expected type error, got %v", err)
+	}
+}
+
+func TestCloudEvents_InvalidInstance(t *testing.T) {
+	store := newOrderPlacedStore(t)
+	instance := orderPlacedInstance()
+	delete(instance["payload"].(map[string]any), "orderId")
+
+	envelope, err := ToCloudEvent(instance, nil)
+	if err != nil {
+		t.Fatalf("ToCloudEvent failed: %v", err)
+	}
+	if _, err := FromCloudEvent(envelope, store, nil); err != nil {
+		t.Errorf("Expected no validation without Validate, got %v", err)
+	}
+	if _, err := FromCloudEvent(envelope, store, &CloudEventsConfig{Validate: true}); err == nil {
+		t.Error("Expected validation error")
+	}
+
+	if _, err := ToCloudEvent(map[string]any{"name": "no ids"}, nil); err == nil {
+		t.Error("Expected error for instance without schema ID")
+	}
+}
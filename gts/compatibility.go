@@ -5,13 +5,24 @@ Released under Apache License 2.0
 
 package gts
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
 // CompatibilityResult represents the result of schema compatibility checking
 type CompatibilityResult struct {
-	FromID                 string              `json:"from"`
-	ToID                   string              `json:"to"`
-	OldID                  string              `json:"old"`
-	NewID                  string              `json:"new"`
-	Direction              string              `json:"direction"`
+	FromID    string `json:"from"`
+	ToID      string `json:"to"`
+	OldID     string `json:"old"`
+	NewID     string `json:"new"`
+	Direction string `json:"direction"`
+	// DirectionInfo details Direction along the type chains of the two IDs, see InferDirection
+	DirectionInfo          *DirectionInfo      `json:"direction_info,omitempty"`
 	AddedProperties        []string            `json:"added_properties"`
 	RemovedProperties      []string            `json:"removed_properties"`
 	ChangedProperties      []map[string]string `json:"changed_properties"`
@@ -19,70 +30,126 @@ type CompatibilityResult struct {
 	IsBackwardCompatible   bool                `json:"is_backward_compatible"`
 	IsForwardCompatible    bool                `json:"is_forward_compatible"`
 	IncompatibilityReasons []string            `json:"incompatibility_reasons"`
-	BackwardErrors         []string            `json:"backward_errors"`
-	ForwardErrors          []string            `json:"forward_errors"`
-	Error                  string              `json:"error,omitempty"`
+	// IncompatibilityDetails holds the incompatibility reasons of a cast in structured form, in
+	// the same order
+	IncompatibilityDetails []IncompatibilityDetail `json:"incompatibility_details,omitempty"`
+	BackwardErrors         []string                `json:"backward_errors"`
+	ForwardErrors          []string                `json:"forward_errors"`
+	Warnings               []string                `json:"warnings,omitempty"`
+	// Diff is the structural difference between the schemas when it was asked for, see DiffSchemas
+	Diff  *SchemaDiff `json:"diff,omitempty"`
+	Error string      `json:"error,omitempty"`
+	// Err is set when the check could not run: a schema is missing or the check panicked
+	Err error `json:"-"`
 }
 
 // CheckCompatibility checks compatibility between two schemas
+// A panic during the check is reported in the result Error field as an internal error.
 // see gts-python store.py is_minor_compatible method
-func (s *GtsStore) CheckCompatibility(oldSchemaID, newSchemaID string) *CompatibilityResult {
+func (s *GtsStore) CheckCompatibility(oldSchemaID, newSchemaID string) (result *CompatibilityResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = internalCompatibilityError("CheckCompatibility", oldSchemaID, newSchemaID, r)
+		}
+	}()
+
+	return s.checkCompatibility(oldSchemaID, newSchemaID)
+}
+
+// CheckReplacementCompatibility checks the compatibility of the registered schema schemaID with
+// content that would replace it, e.g. before an update, the way CheckCompatibility compares two
+// registered schemas: the registered schema is the old one and content the new one
+func (s *GtsStore) CheckReplacementCompatibility(schemaID string, content map[string]any) *CompatibilityResult {
+	oldEntity := s.Get(schemaID)
+	if oldEntity == nil || !oldEntity.IsSchema {
+		return schemaNotFoundCompatibility(schemaID, schemaID, schemaID)
+	}
+	return s.replacementCompatibility(schemaID, oldEntity, content)
+}
+
+// replacementCompatibility compares the content of the registered schema oldEntity with content
+// that would replace it; a panic is reported like in CheckReplacementCompatibility
+func (s *GtsStore) replacementCompatibility(schemaID string, oldEntity *JsonEntity, content map[string]any) (result *CompatibilityResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = internalCompatibilityError("CheckReplacementCompatibility", schemaID, schemaID, r)
+		}
+	}()
+
+	if oldEntity.Content == nil || content == nil {
+		return invalidContentCompatibility(schemaID, schemaID)
+	}
+	return s.compareSchemas(schemaID, schemaID, oldEntity.Content, content)
+}
+
+// internalCompatibilityError is the result of a compatibility check that panicked
+func internalCompatibilityError(operation, oldSchemaID, newSchemaID string, r any) *CompatibilityResult {
+	internalErr := newStoreInternalError(operation, r)
+	return &CompatibilityResult{
+		FromID:                 oldSchemaID,
+		ToID:                   newSchemaID,
+		OldID:                  oldSchemaID,
+		NewID:                  newSchemaID,
+		Direction:              DirectionUnknown,
+		AddedProperties:        []string{},
+		RemovedProperties:      []string{},
+		ChangedProperties:      []map[string]string{},
+		IncompatibilityReasons: []string{},
+		BackwardErrors:         []string{},
+		ForwardErrors:          []string{},
+		Error:                  internalErr.Error(),
+		Err:                    internalErr,
+	}
+}
+
+// checkCompatibility is the unguarded implementation of CheckCompatibility
+func (s *GtsStore) checkCompatibility(oldSchemaID, newSchemaID string) *CompatibilityResult {
 	oldEntity := s.Get(oldSchemaID)
 	newEntity := s.Get(newSchemaID)
 
 	if oldEntity == nil || newEntity == nil {
-		return &CompatibilityResult{
-			FromID:                 oldSchemaID,
-			ToID:                   newSchemaID,
-			OldID:                  oldSchemaID,
-			NewID:                  newSchemaID,
-			Direction:              "unknown",
-			AddedProperties:        []string{},
-			RemovedProperties:      []string{},
-			ChangedProperties:      []map[string]string{},
-			IsFullyCompatible:      false,
-			IsBackwardCompatible:   false,
-			IsForwardCompatible:    false,
-			IncompatibilityReasons: []string{},
-			BackwardErrors:         []string{"Schema not found"},
-			ForwardErrors:          []string{"Schema not found"},
-		}
-	}
-
-	oldSchema, ok1 := oldEntity.Content, oldEntity.Content != nil
-	newSchema, ok2 := newEntity.Content, newEntity.Content != nil
-	if !ok1 || !ok2 {
-		return &CompatibilityResult{
-			FromID:                 oldSchemaID,
-			ToID:                   newSchemaID,
-			OldID:                  oldSchemaID,
-			NewID:                  newSchemaID,
-			Direction:              "unknown",
-			AddedProperties:        []string{},
-			RemovedProperties:      []string{},
-			ChangedProperties:      []map[string]string{},
-			IsFullyCompatible:      false,
-			IsBackwardCompatible:   false,
-			IsForwardCompatible:    false,
-			IncompatibilityReasons: []string{},
-			BackwardErrors:         []string{"Invalid schema content"},
-			ForwardErrors:          []string{"Invalid schema content"},
+		missingID := oldSchemaID
+		if oldEntity != nil {
+			missingID = newSchemaID
 		}
+		return schemaNotFoundCompatibility(oldSchemaID, newSchemaID, missingID)
+	}
+	if oldEntity.Content == nil || newEntity.Content == nil {
+		return invalidContentCompatibility(oldSchemaID, newSchemaID)
 	}
+	return s.compareSchemas(oldSchemaID, newSchemaID, oldEntity.Content, newEntity.Content)
+}
+
+// compareSchemas checks the compatibility of the content of two schemas
+func (s *GtsStore) compareSchemas(oldSchemaID, newSchemaID string, oldSchema, newSchema map[string]any) *CompatibilityResult {
+	// Bring both schemas into the canonical draft-independent form
+	oldSchema, oldWarnings := normalizeSchema(oldSchema)
+	newSchema, newWarnings := normalizeSchema(newSchema)
+
+	// Flatten them with the schemas they reference, so that inherited properties are compared
+	oldFlat, oldDefects, oldUnresolved := flattenSchemaWithStore(oldSchema, s, nil)
+	newFlat, newDefects, newUnresolved := flattenSchemaWithStore(newSchema, s, nil)
 
 	// Check compatibility
-	isBackward, backwardErrors := checkBackwardCompatibility(oldSchema, newSchema)
-	isForward, forwardErrors := checkForwardCompatibility(oldSchema, newSchema)
+	isBackward, backwardErrors := checkBackwardCompatibility(oldFlat, newFlat)
+	isForward, forwardErrors := checkForwardCompatibility(oldFlat, newFlat)
+	if refErrors := append(schemaDefects(oldSchemaID, oldUnresolved), schemaDefects(newSchemaID, newUnresolved)...); len(refErrors) > 0 {
+		// Without the referenced schemas the comparison is incomplete
+		isBackward, isForward = false, false
+		backwardErrors = append(slices.Clone(refErrors), backwardErrors...)
+		forwardErrors = append(slices.Clone(refErrors), forwardErrors...)
+	}
 
 	// Determine direction
-	direction := inferDirection(oldSchemaID, newSchemaID)
+	direction := InferDirection(oldSchemaID, newSchemaID)
 
 	return &CompatibilityResult{
 		FromID:                 oldSchemaID,
 		ToID:                   newSchemaID,
 		OldID:                  oldSchemaID,
 		NewID:                  newSchemaID,
-		Direction:              direction,
+		Direction:              direction.Direction,
+		DirectionInfo:          direction,
 		AddedProperties:        []string{},
 		RemovedProperties:      []string{},
 		ChangedProperties:      []map[string]string{},
@@ -92,80 +159,281 @@ func (s *GtsStore) CheckCompatibility(oldSchemaID, newSchemaID string) *Compatib
 		IncompatibilityReasons: []string{},
 		BackwardErrors:         backwardErrors,
 		ForwardErrors:          forwardErrors,
+		Warnings: mergeWarnings(oldWarnings, newWarnings,
+			schemaDefects(oldSchemaID, oldDefects), schemaDefects(newSchemaID, newDefects)),
+	}
+}
+
+// schemaNotFoundCompatibility is the result of a compatibility check of a schema that is not
+// registered
+func schemaNotFoundCompatibility(oldSchemaID, newSchemaID, missingID string) *CompatibilityResult {
+	return &CompatibilityResult{
+		FromID:                 oldSchemaID,
+		ToID:                   newSchemaID,
+		OldID:                  oldSchemaID,
+		NewID:                  newSchemaID,
+		Direction:              DirectionUnknown,
+		AddedProperties:        []string{},
+		RemovedProperties:      []string{},
+		ChangedProperties:      []map[string]string{},
+		IsFullyCompatible:      false,
+		IsBackwardCompatible:   false,
+		IsForwardCompatible:    false,
+		IncompatibilityReasons: []string{},
+		BackwardErrors:         []string{"Schema not found"},
+		ForwardErrors:          []string{"Schema not found"},
+		Err:                    &StoreGtsSchemaNotFoundError{EntityID: missingID},
+	}
+}
+
+// invalidContentCompatibility is the result of a compatibility check of a schema without content
+func invalidContentCompatibility(oldSchemaID, newSchemaID string) *CompatibilityResult {
+	return &CompatibilityResult{
+		FromID:                 oldSchemaID,
+		ToID:                   newSchemaID,
+		OldID:                  oldSchemaID,
+		NewID:                  newSchemaID,
+		Direction:              DirectionUnknown,
+		AddedProperties:        []string{},
+		RemovedProperties:      []string{},
+		ChangedProperties:      []map[string]string{},
+		IsFullyCompatible:      false,
+		IsBackwardCompatible:   false,
+		IsForwardCompatible:    false,
+		IncompatibilityReasons: []string{},
+		BackwardErrors:         []string{"Invalid schema content"},
+		ForwardErrors:          []string{"Invalid schema content"},
 	}
 }
 
-// inferDirection determines if going up/down based on minor version
-// see gts-python schema_cast.py _infer_direction method
-func inferDirection(fromID, toID string) string {
-	fromGtsID, err1 := NewGtsID(fromID)
-	toGtsID, err2 := NewGtsID(toID)
+// schemaLabel names a schema by its $id, for schemas whose ID the caller does not have
+func schemaLabel(schema map[string]any) string {
+	if id := strings.TrimPrefix(getString(schema, "$id"), GtsURIPrefix); id != "" {
+		return id
+	}
+	return "source schema"
+}
 
-	if err1 != nil || err2 != nil {
-		return "unknown"
+// schemaDefects prefixes the defects found in a schema with its ID for the warnings of a result
+func schemaDefects(schemaID string, defects []string) []string {
+	result := make([]string, 0, len(defects))
+	for _, defect := range defects {
+		result = append(result, schemaID+": "+defect)
 	}
+	return result
+}
 
-	// Get last segment (the one with version info)
-	if len(fromGtsID.Segments) == 0 || len(toGtsID.Segments) == 0 {
-		return "unknown"
+// mergeWarnings combines warning lists, dropping duplicates
+func mergeWarnings(lists ...[]string) []string {
+	var all []string
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	if len(all) == 0 {
+		return nil
 	}
+	return deduplicate(all)
+}
+
+// flattenSchema merges allOf schemas into a single schema, see flattenSchemaWithDefects
+func flattenSchema(schema map[string]any) map[string]any {
+	flat, _ := flattenSchemaWithDefects(schema)
+	return flat
+}
+
+// flattenSchemaWithDefects merges allOf schemas into a single schema and normalizes the merged
+// required list: names required by several layers appear once, in first-seen order, and entries
+// that are not strings are skipped. When additionalProperties is false, required names missing
+// from the merged properties are dropped, as no instance can satisfy them. Skipped and dropped
+// entries are returned as schema defects. allOf $refs are not followed, see flattenSchemaWithStore.
+// see gts-python schema_cast.py _flatten_schema method
+func flattenSchemaWithDefects(schema map[string]any) (map[string]any, []string) {
+	flat, defects, _ := flattenSchemaWithStore(schema, nil, nil)
+	return flat, defects
+}
 
-	fromSeg := fromGtsID.Segments[len(fromGtsID.Segments)-1]
-	toSeg := toGtsID.Segments[len(toGtsID.Segments)-1]
+// flattenSchemaWithStore is flattenSchemaWithDefects following the allOf $refs of the schema, and
+// of the schemas it references, to the schemas registered in store, so that the properties and
+// required names a derived type inherits from its parent are part of the flattened schema.
+// visited holds the IDs of the schemas being flattened, to which the $id of schema is added; a
+// $ref to one of them closes a cycle and is skipped. $refs that name no registered schema are
+// returned as unresolved, one message each. With a nil store, $refs are not followed.
+func flattenSchemaWithStore(schema map[string]any, store *GtsStore, visited map[string]bool) (map[string]any, []string, []string) {
+	return flattenSchemaInContext(schema, store, &flattenContext{visited: visited})
+}
 
-	if fromSeg.VerMinor != nil && toSeg.VerMinor != nil {
-		if *toSeg.VerMinor > *fromSeg.VerMinor {
-			return "up"
+// FlattenSchema returns a copy of schema with its allOf parts merged into it, following the allOf
+// $refs to the schemas registered in the store as compatibility checks do, so that a derived type
+// describes its type completely. Properties merge by name and required names are joined without
+// duplicates; for other keywords, later parts and finally the schema's own keywords win, except
+// that the title, description and $comment of referenced schemas are left out. Parts whose $ref
+// names no registered schema, or that would close a cycle, are kept in allOf.
+func (s *GtsStore) FlattenSchema(schema map[string]any) map[string]any {
+	flat, _, _ := flattenSchemaInContext(schema, s, &flattenContext{keywords: true})
+	if props, _ := flat["properties"].(map[string]any); len(props) == 0 {
+		delete(flat, "properties")
+	}
+	if required, _ := flat["required"].([]any); len(required) == 0 {
+		delete(flat, "required")
+	}
+	for _, key := range []string{"$id", "$schema"} {
+		if value, ok := schema[key]; ok {
+			flat[key] = value
 		}
-		if *toSeg.VerMinor < *fromSeg.VerMinor {
-			return "down"
+	}
+	return flat
+}
+
+// flattenSchemaInContext is flattenSchemaWithStore with the state of the flattening in ctx
+func flattenSchemaInContext(schema map[string]any, store *GtsStore, ctx *flattenContext) (map[string]any, []string, []string) {
+	if store != nil {
+		ctx.resolve = store.storeSchemaResolver()
+		if ctx.visited == nil {
+			ctx.visited = make(map[string]bool)
 		}
-		return "none"
+		if id := strings.TrimPrefix(getString(schema, "$id"), GtsURIPrefix); id != "" {
+			ctx.visited[id] = true
+		}
+	}
+	result := flattenSchemaLayers(schema, "", ctx)
+
+	if !getAdditionalProperties(result) {
+		props := getPropertiesMap(result)
+		required, _ := result["required"].([]any)
+		kept := make([]any, 0, len(required))
+		for _, name := range required {
+			if _, declared := props[name.(string)]; !declared {
+				ctx.defects = append(ctx.defects, fmt.Sprintf(
+					"Schema defect: required property '%s' is not declared in properties and additionalProperties is false; it is ignored", name))
+				continue
+			}
+			kept = append(kept, name)
+		}
+		result["required"] = kept
 	}
+	return result, ctx.defects, ctx.unresolved
+}
 
-	return "unknown"
+// flattenContext carries the state of a flattening: the defects found and, when $refs are
+// followed, the resolver, the schemas being flattened and the $refs that could not be resolved.
+// With keywords set, the other keywords of the layers are merged too (see GtsStore.FlattenSchema).
+type flattenContext struct {
+	resolve    func(ref string) map[string]any
+	visited    map[string]bool
+	keywords   bool
+	defects    []string
+	unresolved []string
 }
 
-// flattenSchema merges allOf schemas into a single schema
-// see gts-python schema_cast.py _flatten_schema method
-func flattenSchema(schema map[string]any) map[string]any {
+// flattenedKeywords are the keywords flattenSchemaLayers merges in every mode
+var flattenedKeywords = map[string]bool{
+	"allOf": true, "properties": true, "required": true, "additionalProperties": true, "unevaluatedProperties": true,
+}
+
+// flattenSchemaLayers merges the allOf layers of a schema at path, deduplicating required names
+// and recording non-string required entries in the defects of ctx
+func flattenSchemaLayers(schema map[string]any, path string, ctx *flattenContext) map[string]any {
 	result := map[string]any{
 		"properties": make(map[string]any),
 		"required":   []any{},
 	}
+	mergeRequired := func(req []any, reqPath string) {
+		resultReq, _ := result["required"].([]any)
+		for i, item := range req {
+			name, ok := item.(string)
+			if !ok {
+				encoded, _ := json.Marshal(item)
+				ctx.defects = append(ctx.defects, fmt.Sprintf(
+					"Schema defect: required entry %s at %s[%d] is not a string; it is ignored", encoded, reqPath, i))
+				continue
+			}
+			if !slices.Contains(resultReq, any(name)) {
+				resultReq = append(resultReq, name)
+			}
+		}
+		result["required"] = resultReq
+	}
+	var kept []any
+	mergeLayer := func(flattened map[string]any, referenced bool) {
+		if ctx.keywords {
+			for key, value := range flattened {
+				switch key {
+				case "$id", "$schema", "$ref":
+				case "title", "description", "$comment":
+					// Annotations of a referenced parent describe the parent, not the schema
+					if !referenced {
+						result[key] = value
+					}
+				case "allOf":
+					parts, _ := value.([]any)
+					kept = append(kept, parts...)
+				default:
+					if !flattenedKeywords[key] {
+						result[key] = value
+					}
+				}
+			}
+		}
+
+		// Merge properties
+		if props, ok := flattened["properties"].(map[string]any); ok {
+			if resultProps, ok := result["properties"].(map[string]any); ok {
+				for k, v := range props {
+					resultProps[k] = v
+				}
+			}
+		}
+
+		// Merge required; the layer's own entries were checked when it was flattened
+		if req, ok := flattened["required"].([]any); ok {
+			mergeRequired(req, "")
+		}
+
+		// Preserve additionalProperties and unevaluatedProperties (last one wins)
+		if addProps, ok := flattened["additionalProperties"]; ok {
+			result["additionalProperties"] = addProps
+		}
+		if unevaluated, ok := flattened["unevaluatedProperties"]; ok {
+			result["unevaluatedProperties"] = unevaluated
+		}
+	}
 
 	// Merge allOf schemas
 	if allOfVal, ok := schema["allOf"]; ok {
 		if allOfList, ok := allOfVal.([]any); ok {
-			for _, subSchemaAny := range allOfList {
+			for i, subSchemaAny := range allOfList {
 				if subSchema, ok := subSchemaAny.(map[string]any); ok {
-					flattened := flattenSchema(subSchema)
-
-					// Merge properties
-					if props, ok := flattened["properties"].(map[string]any); ok {
-						if resultProps, ok := result["properties"].(map[string]any); ok {
-							for k, v := range props {
-								resultProps[k] = v
-							}
-						}
-					}
-
-					// Merge required
-					if req, ok := flattened["required"].([]any); ok {
-						if resultReq, ok := result["required"].([]any); ok {
-							result["required"] = append(resultReq, req...)
+					partPath := buildPath(path, fmt.Sprintf("allOf[%d]", i))
+					ref, isRef := subSchema["$ref"].(string)
+					if isRef && ctx.resolve != nil && IsValidGtsID(strings.TrimPrefix(ref, GtsURIPrefix)) {
+						referenced := flattenReferencedSchema(ref, partPath, ctx)
+						if referenced == nil && ctx.keywords {
+							kept = append(kept, subSchema)
+							continue
 						}
+						mergeLayer(referenced, true)
+					} else if isRef && ctx.keywords {
+						kept = append(kept, subSchema)
+						continue
 					}
-
-					// Preserve additionalProperties (last one wins)
-					if addProps, ok := flattened["additionalProperties"]; ok {
-						result["additionalProperties"] = addProps
-					}
+					mergeLayer(flattenSchemaLayers(subSchema, partPath, ctx), false)
 				}
 			}
 		}
 	}
 
+	// Add direct keywords
+	if ctx.keywords {
+		for key, value := range schema {
+			if key != "$id" && key != "$schema" && !flattenedKeywords[key] {
+				result[key] = value
+			}
+		}
+		if len(kept) > 0 {
+			result["allOf"] = kept
+		}
+	}
+
 	// Add direct properties
 	if props, ok := schema["properties"].(map[string]any); ok {
 		if resultProps, ok := result["properties"].(map[string]any); ok {
@@ -177,19 +445,39 @@ func flattenSchema(schema map[string]any) map[string]any {
 
 	// Add direct required
 	if req, ok := schema["required"].([]any); ok {
-		if resultReq, ok := result["required"].([]any); ok {
-			result["required"] = append(resultReq, req...)
-		}
+		mergeRequired(req, buildPath(path, "required"))
 	}
 
 	// Top level additionalProperties overrides
 	if addProps, ok := schema["additionalProperties"]; ok {
 		result["additionalProperties"] = addProps
 	}
+	if unevaluated, ok := schema["unevaluatedProperties"]; ok {
+		result["unevaluatedProperties"] = unevaluated
+	}
 
 	return result
 }
 
+// flattenReferencedSchema flattens the registered schema the GTS $ref of an allOf part at path
+// names, or
+// returns an empty layer when the $ref closes a cycle or names no registered schema
+func flattenReferencedSchema(ref, path string, ctx *flattenContext) map[string]any {
+	id := strings.TrimPrefix(ref, GtsURIPrefix)
+	if ctx.visited[id] {
+		return nil
+	}
+	content := ctx.resolve(ref)
+	if content == nil {
+		ctx.unresolved = append(ctx.unresolved, fmt.Sprintf("referenced schema '%s' at %s is not registered", id, path))
+		return nil
+	}
+	normalized, _ := normalizeSchema(content)
+	ctx.visited[id] = true
+	defer delete(ctx.visited, id)
+	return flattenSchemaLayers(normalized, path, ctx)
+}
+
 // checkBackwardCompatibility checks if new schema is backward compatible with old
 // Backward compatibility: new consumers can read old data
 // see gts-python schema_cast.py _check_backward_compatibility method
@@ -233,70 +521,147 @@ func checkSchemaCompatibility(oldSchema, newSchema map[string]any, checkBackward
 		}
 	}
 
+	// Closed models reject properties the other side may still produce
+	oldClosed := !getAdditionalProperties(oldFlat)
+	newClosed := !getAdditionalProperties(newFlat)
+	if checkBackward && newClosed {
+		// Backward: old data may carry properties the new closed model no longer declares
+		if dropped := setDifference(getKeys(oldProps), getKeys(newProps)); len(dropped) > 0 {
+			errors = append(errors, "Removed properties not allowed by closed model: "+joinStrings(dropped))
+		}
+	}
+	if !checkBackward && oldClosed {
+		// Forward: new data may carry properties the old closed model rejects
+		if addedProps := setDifference(getKeys(newProps), getKeys(oldProps)); len(addedProps) > 0 {
+			errors = append(errors, "Added properties not allowed by closed model: "+joinStrings(addedProps))
+		}
+	}
+
 	// Check properties that exist in both schemas
 	commonProps := setIntersection(getKeys(oldProps), getKeys(newProps))
 	for _, prop := range commonProps {
-		oldPropSchema := oldProps[prop].(map[string]any)
-		newPropSchema := newProps[prop].(map[string]any)
-
-		// Check if type changed
-		oldType := getString(oldPropSchema, "type")
-		newType := getString(newPropSchema, "type")
-		if oldType != "" && newType != "" && oldType != newType {
-			errors = append(errors, "Property '"+prop+"' type changed from "+oldType+" to "+newType)
-		}
-
-		// Check enum constraints
-		oldEnum := getStringSlice(oldPropSchema, "enum")
-		newEnum := getStringSlice(newPropSchema, "enum")
-		if len(oldEnum) > 0 && len(newEnum) > 0 {
-			oldEnumSet := stringSliceToSet(oldEnum)
-			newEnumSet := stringSliceToSet(newEnum)
-			if checkBackward {
-				// Backward: cannot add enum values
-				addedEnumValues := setDifference(newEnumSet, oldEnumSet)
-				if len(addedEnumValues) > 0 {
-					errors = append(errors, "Property '"+prop+"' added enum values: "+joinStrings(addedEnumValues))
-				}
-			} else {
-				// Forward: cannot remove enum values
-				removedEnumValues := setDifference(oldEnumSet, newEnumSet)
-				if len(removedEnumValues) > 0 {
-					errors = append(errors, "Property '"+prop+"' removed enum values: "+joinStrings(removedEnumValues))
-				}
+		oldPropSchema, _ := oldProps[prop].(map[string]any)
+		newPropSchema, _ := newProps[prop].(map[string]any)
+		if oldPropSchema == nil || newPropSchema == nil {
+			continue
+		}
+		errors = append(errors, checkPropertyCompatibility(prop, oldPropSchema, newPropSchema, checkBackward)...)
+	}
+
+	return len(errors) == 0, errors
+}
+
+// checkPropertyCompatibility compares the schemas of a single property (or tuple position)
+func checkPropertyCompatibility(prop string, oldPropSchema, newPropSchema map[string]any, checkBackward bool) []string {
+	errors := []string{}
+
+	// Check if type changed
+	oldType := getString(oldPropSchema, "type")
+	newType := getString(newPropSchema, "type")
+	if oldType != "" && newType != "" && oldType != newType {
+		errors = append(errors, "Property '"+prop+"' type changed from "+oldType+" to "+newType)
+	}
+
+	// Check enum constraints
+	oldEnum := getStringSlice(oldPropSchema, "enum")
+	newEnum := getStringSlice(newPropSchema, "enum")
+	if len(oldEnum) > 0 && len(newEnum) > 0 {
+		oldEnumSet := stringSliceToSet(oldEnum)
+		newEnumSet := stringSliceToSet(newEnum)
+		if checkBackward {
+			// Backward: cannot add enum values
+			addedEnumValues := setDifference(newEnumSet, oldEnumSet)
+			if len(addedEnumValues) > 0 {
+				errors = append(errors, "Property '"+prop+"' added enum values: "+joinStrings(addedEnumValues))
+			}
+		} else {
+			// Forward: cannot remove enum values
+			removedEnumValues := setDifference(oldEnumSet, newEnumSet)
+			if len(removedEnumValues) > 0 {
+				errors = append(errors, "Property '"+prop+"' removed enum values: "+joinStrings(removedEnumValues))
 			}
 		}
+	}
 
-		// Check constraint compatibility
-		constraintErrors := checkConstraintCompatibility(prop, oldPropSchema, newPropSchema, checkBackward)
-		errors = append(errors, constraintErrors...)
+	// Check constraint compatibility
+	constraintErrors := checkConstraintCompatibility(prop, oldPropSchema, newPropSchema, checkBackward)
+	errors = append(errors, constraintErrors...)
 
-		// Recursively check nested object properties
-		if oldType == "object" && newType == "object" {
-			nestedCompat, nestedErrors := checkSchemaCompatibility(oldPropSchema, newPropSchema, checkBackward)
-			if !nestedCompat {
-				for _, err := range nestedErrors {
-					errors = append(errors, "Property '"+prop+"': "+err)
-				}
+	// Recursively check nested object properties
+	if oldType == "object" && newType == "object" {
+		nestedCompat, nestedErrors := checkSchemaCompatibility(oldPropSchema, newPropSchema, checkBackward)
+		if !nestedCompat {
+			for _, err := range nestedErrors {
+				errors = append(errors, "Property '"+prop+"': "+err)
 			}
 		}
+	}
 
-		// Recursively check array item schemas
-		if oldType == "array" && newType == "array" {
-			oldItems := getMap(oldPropSchema, "items")
-			newItems := getMap(newPropSchema, "items")
-			if oldItems != nil && newItems != nil {
-				itemsCompat, itemsErrors := checkSchemaCompatibility(oldItems, newItems, checkBackward)
-				if !itemsCompat {
-					for _, err := range itemsErrors {
-						errors = append(errors, "Property '"+prop+"' array items: "+err)
-					}
+	// Recursively check array item schemas
+	if oldType == "array" && newType == "array" {
+		errors = append(errors, checkTupleCompatibility(prop, oldPropSchema, newPropSchema, checkBackward)...)
+
+		oldItems := getMap(oldPropSchema, "items")
+		newItems := getMap(newPropSchema, "items")
+		if oldItems != nil && newItems != nil {
+			itemsCompat, itemsErrors := checkSchemaCompatibility(oldItems, newItems, checkBackward)
+			if !itemsCompat {
+				for _, err := range itemsErrors {
+					errors = append(errors, "Property '"+prop+"' array items: "+err)
 				}
 			}
 		}
 	}
 
-	return len(errors) == 0, errors
+	return errors
+}
+
+// checkTupleCompatibility compares tuple (prefixItems) positions of two array schemas
+// Common positions are compared like properties, and positions one side declares against the
+// items schema of the other side; positions added in the new schema break
+// backward compatibility when they become required (minItems grows to cover them), and
+// positions removed from the new schema break forward compatibility when old data required them.
+func checkTupleCompatibility(prop string, oldSchema, newSchema map[string]any, checkBackward bool) []string {
+	errors := []string{}
+	oldTuple := getTupleItems(oldSchema)
+	newTuple := getTupleItems(newSchema)
+	if len(oldTuple) == 0 && len(newTuple) == 0 {
+		return errors
+	}
+
+	common := min(len(oldTuple), len(newTuple))
+	for i := 0; i < common; i++ {
+		errors = append(errors, checkPropertyCompatibility(fmt.Sprintf("%s[%d]", prop, i), oldTuple[i], newTuple[i], checkBackward)...)
+	}
+	// Positions only one side declares are governed by the items schema of the other side
+	if oldItems := getMap(oldSchema, "items"); oldItems != nil {
+		for i := common; i < len(newTuple); i++ {
+			errors = append(errors, checkPropertyCompatibility(fmt.Sprintf("%s[%d]", prop, i), oldItems, newTuple[i], checkBackward)...)
+		}
+	}
+	if newItems := getMap(newSchema, "items"); newItems != nil {
+		for i := common; i < len(oldTuple); i++ {
+			errors = append(errors, checkPropertyCompatibility(fmt.Sprintf("%s[%d]", prop, i), oldTuple[i], newItems, checkBackward)...)
+		}
+	}
+
+	oldMin := 0.0
+	if v := getNumber(oldSchema, "minItems"); v != nil {
+		oldMin = *v
+	}
+	newMin := 0.0
+	if v := getNumber(newSchema, "minItems"); v != nil {
+		newMin = *v
+	}
+
+	if checkBackward && len(newTuple) > len(oldTuple) && newMin > oldMin && newMin > float64(len(oldTuple)) {
+		errors = append(errors, fmt.Sprintf("Property '%s' added required tuple positions: %d -> %d", prop, len(oldTuple), len(newTuple)))
+	}
+	if !checkBackward && len(newTuple) < len(oldTuple) && oldMin > float64(len(newTuple)) {
+		errors = append(errors, fmt.Sprintf("Property '%s' removed required tuple positions: %d -> %d", prop, len(oldTuple), len(newTuple)))
+	}
+
+	return errors
 }
 
 // checkConstraintCompatibility checks if constraints are compatible
@@ -305,9 +670,15 @@ func checkConstraintCompatibility(prop string, oldPropSchema, newPropSchema map[
 	errors := []string{}
 	propType := getString(oldPropSchema, "type")
 
+	// Keywords restricting the value of any type
+	for _, key := range []string{"pattern", "format", "const"} {
+		errors = append(errors, checkKeywordChange(prop, oldPropSchema, newPropSchema, key, checkTightening)...)
+	}
+
 	// Numeric constraints (for number/integer types)
 	if propType == "number" || propType == "integer" {
 		errors = append(errors, checkMinMaxConstraint(prop, oldPropSchema, newPropSchema, "minimum", "maximum", checkTightening)...)
+		errors = append(errors, checkMultipleOfConstraint(prop, oldPropSchema, newPropSchema, checkTightening)...)
 	}
 
 	// String constraints
@@ -318,11 +689,93 @@ func checkConstraintCompatibility(prop string, oldPropSchema, newPropSchema map[
 	// Array constraints
 	if propType == "array" {
 		errors = append(errors, checkMinMaxConstraint(prop, oldPropSchema, newPropSchema, "minItems", "maxItems", checkTightening)...)
+
+		oldUnique, _ := oldPropSchema["uniqueItems"].(bool)
+		newUnique, _ := newPropSchema["uniqueItems"].(bool)
+		// Backward: cannot start requiring unique items; forward: cannot stop requiring them
+		if checkTightening && !oldUnique && newUnique || !checkTightening && oldUnique && !newUnique {
+			errors = append(errors, fmt.Sprintf("Property '%s' uniqueItems changed from %t to %t", prop, oldUnique, newUnique))
+		}
 	}
 
 	return errors
 }
 
+// checkKeywordChange checks a keyword that restricts the value, such as pattern: adding it
+// tightens the schema, removing it relaxes the schema and changing it does both
+func checkKeywordChange(prop string, oldSchema, newSchema map[string]any, key string, checkTightening bool) []string {
+	oldVal, hadOld := oldSchema[key]
+	newVal, hasNew := newSchema[key]
+	if hadOld == hasNew && (!hadOld || reflect.DeepEqual(oldVal, newVal)) {
+		return nil
+	}
+	if key == "const" && isGtsIDValue(oldVal) && isGtsIDValue(newVal) {
+		// GTS ID consts such as the type of an instance follow the schema version; casts update them
+		return nil
+	}
+	// Backward breaks when the new schema restricts the value, forward when the old one did
+	if checkTightening && !hasNew || !checkTightening && !hadOld {
+		return nil
+	}
+	return []string{fmt.Sprintf("Property '%s' %s changed from %s to %s",
+		prop, key, keywordValueString(key, oldVal, hadOld), keywordValueString(key, newVal, hasNew))}
+}
+
+// isGtsIDValue reports whether a keyword value is a GTS ID
+func isGtsIDValue(value any) bool {
+	str, ok := value.(string)
+	return ok && IsValidGtsID(str)
+}
+
+// keywordValueString formats a keyword value for a compatibility error: "none" when absent,
+// strings as they are except for const, whose value is JSON encoded
+func keywordValueString(key string, value any, present bool) string {
+	if !present {
+		return "none"
+	}
+	if str, ok := value.(string); ok && key != "const" {
+		return str
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// checkMultipleOfConstraint checks multipleOf compatibility: the schema accepting more values has
+// no multipleOf, or one that divides the multipleOf of the other schema
+func checkMultipleOfConstraint(prop string, oldSchema, newSchema map[string]any, checkTightening bool) []string {
+	oldMultiple := getNumber(oldSchema, "multipleOf")
+	newMultiple := getNumber(newSchema, "multipleOf")
+
+	var broken bool
+	if checkTightening {
+		// Backward: every multiple of the old value must be a multiple of the new one
+		broken = newMultiple != nil && (oldMultiple == nil || !isMultipleOf(*oldMultiple, *newMultiple))
+	} else {
+		// Forward: every multiple of the new value must be a multiple of the old one
+		broken = oldMultiple != nil && (newMultiple == nil || !isMultipleOf(*newMultiple, *oldMultiple))
+	}
+	if !broken {
+		return nil
+	}
+
+	format := func(n *float64) string {
+		if n == nil {
+			return "none"
+		}
+		return floatToString(*n)
+	}
+	return []string{fmt.Sprintf("Property '%s' multipleOf changed from %s to %s", prop, format(oldMultiple), format(newMultiple))}
+}
+
+// isMultipleOf reports whether a is an integer multiple of b, within floating point precision
+func isMultipleOf(a, b float64) bool {
+	if b == 0 {
+		return false
+	}
+	q := a / b
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
 // checkMinMaxConstraint checks min/max constraint compatibility
 // see gts-python schema_cast.py _check_min_max_constraint method
 func checkMinMaxConstraint(prop string, oldSchema, newSchema map[string]any, minKey, maxKey string, checkTightening bool) []string {
@@ -0,0 +1,118 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SeriesCompatibilityResult represents the compatibility of the registered minor versions of a
+// schema type, e.g. v1.0 through v1.7 of gts.x.core.compat.event.v1~
+type SeriesCompatibilityResult struct {
+	TypeID string `json:"type_id"`
+	// Versions lists the registered minor versions in minor version order
+	Versions []string `json:"versions"`
+	// Pairs holds the checks between consecutive versions, and FirstToLast the check between the
+	// lowest and the highest; both are empty with fewer than two versions
+	Pairs       []*CompatibilityResult `json:"pairs"`
+	FirstToLast *CompatibilityResult   `json:"first_to_last,omitempty"`
+	// IsBackwardCompatible and IsForwardCompatible hold when every check of the series holds them
+	IsBackwardCompatible bool `json:"is_backward_compatible"`
+	IsForwardCompatible  bool `json:"is_forward_compatible"`
+	// MissingVersions lists the minor versions between the lowest and the highest that are not
+	// registered; the series is checked across them
+	MissingVersions []string `json:"missing_versions,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	// Err is set when the series could not be checked: the type ID is invalid or has no
+	// registered minor version, or the check panicked
+	Err error `json:"-"`
+}
+
+// CheckCompatibilitySeries checks the compatibility of the registered minor versions of a schema
+// type. typeID is the major-only schema ID, e.g. "gts.x.core.compat.event.v1~". Its registered
+// minor versions are ordered by minor version and CheckCompatibility runs between each
+// consecutive pair and between the lowest and the highest. Minor versions missing from the
+// series are listed in MissingVersions and are not an error.
+// A panic during the check is reported in the result Error field as an internal error.
+func (s *GtsStore) CheckCompatibilitySeries(typeID string) (result *SeriesCompatibilityResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := newStoreInternalError("CheckCompatibilitySeries", r)
+			result = &SeriesCompatibilityResult{TypeID: typeID, Versions: []string{}, Pairs: []*CompatibilityResult{}, Error: err.Error(), Err: err}
+		}
+	}()
+
+	return s.checkCompatibilitySeries(typeID)
+}
+
+// checkCompatibilitySeries is the unguarded implementation of CheckCompatibilitySeries
+func (s *GtsStore) checkCompatibilitySeries(typeID string) *SeriesCompatibilityResult {
+	result := &SeriesCompatibilityResult{TypeID: typeID, Versions: []string{}, Pairs: []*CompatibilityResult{}}
+	fail := func(err error) *SeriesCompatibilityResult {
+		result.Error, result.Err = err.Error(), err
+		return result
+	}
+
+	id, err := NewGtsID(typeID)
+	if err != nil {
+		return fail(err)
+	}
+	if !id.IsType() || id.LastSegment().VerMinor != nil {
+		return fail(fmt.Errorf("series type ID '%s' must be a schema ID without minor version, e.g. gts.x.core.compat.event.v1~", typeID))
+	}
+
+	versions := s.registeredMinors(id)
+	if len(versions) == 0 {
+		return fail(&StoreGtsSchemaNotFoundError{EntityID: typeID})
+	}
+	result.IsBackwardCompatible, result.IsForwardCompatible = true, true
+	for i, version := range versions {
+		result.Versions = append(result.Versions, version.ID)
+		if i == 0 {
+			continue
+		}
+		previous := versions[i-1]
+		for minor := *previous.LastSegment().VerMinor + 1; minor < *version.LastSegment().VerMinor; minor++ {
+			last := id.LastSegment()
+			result.MissingVersions = append(result.MissingVersions,
+				id.ID[:last.Offset]+formatSegment(last.Vendor, last.Package, last.Namespace, last.Type, last.VerMajor, &minor, true))
+		}
+		result.addCheck(s.CheckCompatibility(previous.ID, version.ID))
+	}
+	if len(versions) > 2 {
+		result.FirstToLast = s.CheckCompatibility(versions[0].ID, versions[len(versions)-1].ID)
+		result.IsBackwardCompatible = result.IsBackwardCompatible && result.FirstToLast.IsBackwardCompatible
+		result.IsForwardCompatible = result.IsForwardCompatible && result.FirstToLast.IsForwardCompatible
+	} else if len(versions) == 2 {
+		result.FirstToLast = result.Pairs[0]
+	}
+	return result
+}
+
+// addCheck adds the check of a consecutive pair to the series
+func (r *SeriesCompatibilityResult) addCheck(check *CompatibilityResult) {
+	r.Pairs = append(r.Pairs, check)
+	r.IsBackwardCompatible = r.IsBackwardCompatible && check.IsBackwardCompatible
+	r.IsForwardCompatible = r.IsForwardCompatible && check.IsForwardCompatible
+}
+
+// registeredMinors returns the IDs of the registered schemas that are minor versions of the
+// major-only schema ID, in minor version order
+func (s *GtsStore) registeredMinors(id *GtsID) []*GtsID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var versions []*GtsID
+	for _, entity := range s.byID {
+		if entity.IsSchema && isMinorVersionOf(entity.GtsID, id) {
+			versions = append(versions, entity.GtsID)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return *versions[i].LastSegment().VerMinor < *versions[j].LastSegment().VerMinor
+	})
+	return versions
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// registerSeriesSchema registers a minor version of gts.x.core.compat.series.v1~ with the
+// properties given, of which the first is required
+func registerSeriesSchema(t *testing.T, store *GtsStore, minor string, props ...string) {
+	t.Helper()
+	properties := map[string]any{}
+	for _, prop := range props {
+		properties[prop] = map[string]any{"type": "string"}
+	}
+	schema := map[string]any{
+		"$id":        "gts://gts.x.core.compat.series.v1." + minor + "~",
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"required":   []any{props[0]},
+		"properties": properties,
+	}
+	if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register v1.%s: %v", minor, err)
+	}
+}
+
+func TestCheckCompatibilitySeries(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerSeriesSchema(t, store, "0", "id")
+	registerSeriesSchema(t, store, "2", "id", "name")
+	registerSeriesSchema(t, store, "3", "id", "name", "email")
+	registerSeriesSchema(t, store, "10", "id", "name", "email", "phone")
+	if err := store.RegisterSchema("gts.x.core.compat.series.v2.0~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register v2.0: %v", err)
+	}
+
+	result := store.CheckCompatibilitySeries("gts.x.core.compat.series.v1~")
+	if result.Err != nil {
+		t.Fatalf("CheckCompatibilitySeries failed: %v", result.Err)
+	}
+	expected := []string{
+		"gts.x.core.compat.series.v1.0~",
+		"gts.x.core.compat.series.v1.2~",
+		"gts.x.core.compat.series.v1.3~",
+		"gts.x.core.compat.series.v1.10~",
+	}
+	if !reflect.DeepEqual(result.Versions, expected) {
+		t.Errorf("Expected versions %v, got %v", expected, result.Versions)
+	}
+	if len(result.Pairs) != 3 || result.Pairs[2].OldID != expected[2] || result.Pairs[2].NewID != expected[3] {
+		t.Errorf("Expected checks between consecutive versions, got %+v", result.Pairs)
+	}
+	if result.FirstToLast == nil || result.FirstToLast.OldID != expected[0] || result.FirstToLast.NewID != expected[3] {
+		t.Errorf("Expected a check between the lowest and highest versions, got %+v", result.FirstToLast)
+	}
+	if !result.IsBackwardCompatible {
+		t.Errorf("Expected the series to be backward compatible: %+v", result.Pairs)
+	}
+	if len(result.MissingVersions) != 7 || result.MissingVersions[0] != "gts.x.core.compat.series.v1.1~" {
+		t.Errorf("Expected v1.1 and v1.4 to v1.9 to be missing, got %v", result.MissingVersions)
+	}
+
+	// A newly required property breaks the series
+	registerSeriesSchema(t, store, "11", "mandatory", "id", "name", "email", "phone")
+	result = store.CheckCompatibilitySeries("gts.x.core.compat.series.v1~")
+	if result.IsBackwardCompatible || result.Pairs[3].IsBackwardCompatible || result.FirstToLast.IsBackwardCompatible {
+		t.Errorf("Expected the series not to be backward compatible, got %+v", result)
+	}
+}
+
+func TestCheckCompatibilitySeries_Errors(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerSeriesSchema(t, store, "0", "id")
+
+	result := store.CheckCompatibilitySeries("gts.x.core.compat.series.v1~")
+	if result.Err != nil || len(result.Versions) != 1 || len(result.Pairs) != 0 || result.FirstToLast != nil || !result.IsBackwardCompatible {
+		t.Errorf("Expected a compatible series of one version, got %+v", result)
+	}
+
+	var notFound *StoreGtsSchemaNotFoundError
+	if result := store.CheckCompatibilitySeries("gts.x.core.compat.other.v1~"); !errors.As(result.Err, &notFound) {
+		t.Errorf("Expected StoreGtsSchemaNotFoundError, got %v", result.Err)
+	}
+	for _, id := range []string{"gts.x.core.compat.series.v1.0~", "gts.x.core.compat.series.v1~x.test._.a.v1", "invalid"} {
+		if result := store.CheckCompatibilitySeries(id); result.Err == nil || result.Error == "" {
+			t.Errorf("Expected an error for %s", id)
+		}
+	}
+}
@@ -6,6 +6,8 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -640,6 +642,42 @@ func TestInferDirection(t *testing.T) {
 			toID:     "also-invalid",
 			expected: "unknown",
 		},
+		{
+			name:     "Up direction in a chain",
+			fromID:   "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~",
+			toID:     "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.2~",
+			expected: "up",
+		},
+		{
+			name:     "Major direction in a chain",
+			fromID:   "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~",
+			toID:     "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v2.0~",
+			expected: "major",
+		},
+		{
+			name:     "Down direction of an intermediate segment",
+			fromID:   "gts.x.core.events.type.v1.3~x.commerce.orders.order_placed.v1.0~",
+			toID:     "gts.x.core.events.type.v1.1~x.commerce.orders.order_placed.v1.0~",
+			expected: "down",
+		},
+		{
+			name:     "Divergent direction",
+			fromID:   "gts.x.core.events.type.v1.0~x.commerce.orders.order_placed.v1.0~",
+			toID:     "gts.x.core.events.type.v1.1~x.commerce.orders.order_placed.v1.1~",
+			expected: "divergent",
+		},
+		{
+			name:     "Unknown direction (other type)",
+			fromID:   "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~",
+			toID:     "gts.x.core.events.type.v1~x.commerce.orders.order_shipped.v1.1~",
+			expected: "unknown",
+		},
+		{
+			name:     "Up direction from an instance",
+			fromID:   "gts.x.core.schema.test.v1.0~x.core._.a.v1",
+			toID:     "gts.x.core.schema.test.v1.1~",
+			expected: "up",
+		},
 	}
 
 	for _, tt := range tests {
@@ -651,3 +689,341 @@ func TestInferDirection(t *testing.T) {
 		})
 	}
 }
+
+func TestInferDirection_Info(t *testing.T) {
+	info := InferDirection("gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~", "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v2.0~")
+	if info.Direction != DirectionMajor || info.Segment != 1 || info.FromVersion != "v1.0" || info.ToVersion != "v2.0" {
+		t.Errorf("Expected a major change of segment 1, got %+v", info)
+	}
+	info = InferDirection("gts.x.core.events.type.v1.0~x.commerce.orders.order_placed.v1.0~", "gts.x.core.events.type.v1.1~x.commerce.orders.order_placed.v1.1~")
+	if info.Segment != -1 || !reflect.DeepEqual(info.DifferingSegments, []int{0, 1}) || info.FromVersion != "" {
+		t.Errorf("Expected both segments to differ, got %+v", info)
+	}
+
+	store := NewGtsStore(nil)
+	for _, id := range []string{"gts.x.core.events.type.v1~", "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~", "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.1~"} {
+		if err := store.RegisterSchema(id, map[string]any{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+	result := store.CheckCompatibility("gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~", "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.1~")
+	if result.Direction != DirectionUp || result.DirectionInfo == nil || result.DirectionInfo.Segment != 1 {
+		t.Errorf("Expected an up direction of segment 1, got %s %+v", result.Direction, result.DirectionInfo)
+	}
+}
+
+func TestFlattenSchema_RequiredNormalization(t *testing.T) {
+	schema := map[string]any{
+		"allOf": []any{
+			map[string]any{
+				"properties": map[string]any{"type": map[string]any{"type": "string"}, "id": map[string]any{"type": "string"}},
+				"required":   []any{"type", "id"},
+			},
+			map[string]any{
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+				"required":   []any{"name", "type", float64(5)},
+			},
+		},
+		"required":             []any{"id", "ghost"},
+		"additionalProperties": false,
+	}
+
+	flat, defects := flattenSchemaWithDefects(schema)
+	if !reflect.DeepEqual(flat["required"], []any{"type", "id", "name"}) {
+		t.Errorf("Expected required [type id name] in first-seen order, got %v", flat["required"])
+	}
+	expected := []string{
+		"Schema defect: required entry 5 at allOf[1].required[2] is not a string; it is ignored",
+		"Schema defect: required property 'ghost' is not declared in properties and additionalProperties is false; it is ignored",
+	}
+	if !reflect.DeepEqual(defects, expected) {
+		t.Errorf("Expected defects %v, got %v", expected, defects)
+	}
+
+	// An open model keeps required names declared elsewhere
+	delete(schema, "additionalProperties")
+	if flat, _ := flattenSchemaWithDefects(schema); !reflect.DeepEqual(flat["required"], []any{"type", "id", "name", "ghost"}) {
+		t.Errorf("Expected the open model to keep ghost, got %v", flat["required"])
+	}
+}
+
+func TestCheckCompatibility_SchemaDefects(t *testing.T) {
+	store := NewGtsStore(nil)
+	base := map[string]any{
+		"$id":      "gts://gts.x.test.defects.item.v1.0~",
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"id"},
+		"properties": map[string]any{
+			"id": map[string]any{"type": "string"},
+		},
+	}
+	defective := map[string]any{
+		"$id":     "gts://gts.x.test.defects.item.v1.1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"allOf": []any{
+			map[string]any{"type": "object", "required": []any{"id", "id", true}},
+			map[string]any{"properties": map[string]any{"id": map[string]any{"type": "string"}}, "additionalProperties": false, "required": []any{"id", "phantom"}},
+		},
+	}
+	registerTestEntities(t, store, base, defective)
+
+	result := store.CheckCompatibility("gts.x.test.defects.item.v1.0~", "gts.x.test.defects.item.v1.1~")
+	if !result.IsBackwardCompatible || !result.IsForwardCompatible {
+		t.Errorf("Expected the normalized required sets to be equal, got %v %v", result.BackwardErrors, result.ForwardErrors)
+	}
+	expected := []string{
+		"gts.x.test.defects.item.v1.1~: Schema defect: required entry true at allOf[0].required[2] is not a string; it is ignored",
+		"gts.x.test.defects.item.v1.1~: Schema defect: required property 'phantom' is not declared in properties and additionalProperties is false; it is ignored",
+	}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, result.Warnings)
+	}
+
+	if err := store.Register(NewJsonEntity(map[string]any{"id": "gts.x.test.defects.item.v1.0~x.test._.one.v1"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+	cast, err := store.Cast("gts.x.test.defects.item.v1.0~x.test._.one.v1", "gts.x.test.defects.item.v1.1~")
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	for _, reason := range cast.IncompatibilityReasons {
+		if strings.Contains(reason, "phantom") || strings.Contains(reason, "%!") {
+			t.Errorf("Expected the defects not to be reported as missing properties, got %q", reason)
+		}
+	}
+	if !reflect.DeepEqual(cast.Warnings, expected) {
+		t.Errorf("Expected cast warnings %v, got %v", expected, cast.Warnings)
+	}
+}
+
+func TestCheckCompatibility_ResolvesAllOfRefs(t *testing.T) {
+	store := NewGtsStore(nil)
+	register := func(schema map[string]any) {
+		t.Helper()
+		if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %v: %v", schema["$id"], err)
+		}
+	}
+	base := func(id string, required ...any) map[string]any {
+		return map[string]any{
+			"$id":      id,
+			"$schema":  "http://json-schema.org/draft-07/schema#",
+			"type":     "object",
+			"required": required,
+			"properties": map[string]any{
+				"id":         map[string]any{"type": "string"},
+				"tenantId":   map[string]any{"type": "string"},
+				"occurredAt": map[string]any{"type": "string"},
+			},
+		}
+	}
+	derived := func(id, baseRef string) map[string]any {
+		return map[string]any{
+			"$id":     id,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"allOf": []any{
+				map[string]any{"$ref": baseRef},
+				map[string]any{"properties": map[string]any{"orderId": map[string]any{"type": "string"}}},
+			},
+		}
+	}
+
+	// The base schema adds a required property between the versions the derived schemas extend
+	register(base("gts://gts.x.core.events.type.v1.0~", "id", "occurredAt"))
+	register(base("gts://gts.x.core.events.type.v1.1~", "id", "tenantId", "occurredAt"))
+	register(derived("gts://gts.x.core.events.type.v1.0~x.test.compat.order.v1.0~", "gts://gts.x.core.events.type.v1.0~"))
+	register(derived("gts://gts.x.core.events.type.v1.1~x.test.compat.order.v1.1~", "gts://gts.x.core.events.type.v1.1~"))
+	register(derived("gts://gts.x.core.events.type.v1.1~x.test.compat.order.v1.2~", "gts://gts.x.core.events.type.v1.9~"))
+
+	result := store.CheckCompatibility("gts.x.core.events.type.v1.0~x.test.compat.order.v1.0~",
+		"gts.x.core.events.type.v1.1~x.test.compat.order.v1.1~")
+	if result.IsBackwardCompatible {
+		t.Error("Expected the required property added to the base schema to break backward compatibility")
+	}
+	if !reflect.DeepEqual(result.BackwardErrors, []string{"Added required properties: tenantId"}) {
+		t.Errorf("Unexpected backward errors: %v", result.BackwardErrors)
+	}
+	if !result.IsForwardCompatible {
+		t.Errorf("Expected forward compatible, got errors: %v", result.ForwardErrors)
+	}
+
+	// A $ref to a schema that is not registered fails both directions explicitly
+	result = store.CheckCompatibility("gts.x.core.events.type.v1.1~x.test.compat.order.v1.1~",
+		"gts.x.core.events.type.v1.1~x.test.compat.order.v1.2~")
+	if result.IsBackwardCompatible || result.IsForwardCompatible || result.IsFullyCompatible {
+		t.Errorf("Expected an unresolved $ref to make the schemas incompatible: %+v", result)
+	}
+	want := "gts.x.core.events.type.v1.1~x.test.compat.order.v1.2~: referenced schema 'gts.x.core.events.type.v1.9~' at allOf[0] is not registered"
+	for _, errs := range [][]string{result.BackwardErrors, result.ForwardErrors} {
+		if len(errs) == 0 || errs[0] != want {
+			t.Errorf("Expected %q first, got %v", want, errs)
+		}
+	}
+}
+
+func TestFlattenSchemaWithStore_Cycle(t *testing.T) {
+	store := NewGtsStore(nil)
+	for _, schema := range []map[string]any{
+		{
+			"$id":        "gts://gts.x.test.cycle.a.v1.0~",
+			"$schema":    "http://json-schema.org/draft-07/schema#",
+			"allOf":      []any{map[string]any{"$ref": "gts://gts.x.test.cycle.b.v1.0~"}},
+			"properties": map[string]any{"a": map[string]any{"type": "string"}},
+			"required":   []any{"a"},
+		},
+		{
+			"$id":        "gts://gts.x.test.cycle.b.v1.0~",
+			"$schema":    "http://json-schema.org/draft-07/schema#",
+			"allOf":      []any{map[string]any{"$ref": "gts://gts.x.test.cycle.a.v1.0~"}},
+			"properties": map[string]any{"b": map[string]any{"type": "string"}},
+			"required":   []any{"b"},
+		},
+	} {
+		if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %v: %v", schema["$id"], err)
+		}
+	}
+
+	flat, _, unresolved := flattenSchemaWithStore(store.Get("gts.x.test.cycle.a.v1.0~").Content, store, nil)
+	if len(unresolved) != 0 {
+		t.Errorf("Unexpected unresolved refs: %v", unresolved)
+	}
+	if !reflect.DeepEqual(flat["required"], []any{"b", "a"}) {
+		t.Errorf("Expected required [b a], got %v", flat["required"])
+	}
+}
+
+func TestGtsStore_FlattenSchema(t *testing.T) {
+	store := NewGtsStore(nil)
+	base := map[string]any{
+		"$id":          "gts://gts.x.test.flat.event.v1~",
+		"$schema":      "http://json-schema.org/draft-07/schema#",
+		"title":        "Event",
+		"type":         "object",
+		"x-gts-traits": map[string]any{"retention": "P30D"},
+		"properties":   map[string]any{"id": map[string]any{"type": "string"}},
+		"required":     []any{"id"},
+	}
+	if err := store.Register(NewJsonEntity(base, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register base schema: %v", err)
+	}
+
+	unknown := map[string]any{"$ref": "gts://gts.x.test.flat.missing.v1~"}
+	derived := map[string]any{
+		"$id":     "gts://gts.x.test.flat.event.v1~x.test.flat.order.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"allOf": []any{
+			map[string]any{"$ref": "gts://gts.x.test.flat.event.v1~"},
+			map[string]any{"description": "An order", "properties": map[string]any{"total": map[string]any{"type": "number"}}, "required": []any{"total"}},
+			unknown,
+		},
+	}
+	want := map[string]any{
+		"$id":          derived["$id"],
+		"$schema":      derived["$schema"],
+		"description":  "An order",
+		"type":         "object",
+		"x-gts-traits": map[string]any{"retention": "P30D"},
+		"properties":   map[string]any{"id": map[string]any{"type": "string"}, "total": map[string]any{"type": "number"}},
+		"required":     []any{"id", "total"},
+		"allOf":        []any{unknown},
+	}
+	if flat := store.FlattenSchema(derived); !reflect.DeepEqual(flat, want) {
+		t.Errorf("Unexpected flattened schema:\n got %v\nwant %v", flat, want)
+	}
+}
+
+func TestCheckCompatibility_ValueKeywords(t *testing.T) {
+	check := func(oldProp, newProp map[string]any) (*CompatibilityResult, error) {
+		store := NewGtsStore(nil)
+		for _, schema := range []map[string]any{
+			{"$id": "gts://gts.x.test.keywords.user.v1.0~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object",
+				"properties": map[string]any{"field": oldProp}},
+			{"$id": "gts://gts.x.test.keywords.user.v1.1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object",
+				"properties": map[string]any{"field": newProp}},
+		} {
+			if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+				return nil, err
+			}
+		}
+		return store.CheckCompatibility("gts.x.test.keywords.user.v1.0~", "gts.x.test.keywords.user.v1.1~"), nil
+	}
+
+	tests := []struct {
+		name           string
+		oldProp        map[string]any
+		newProp        map[string]any
+		backwardErrors []string
+		forwardErrors  []string
+	}{
+		{
+			name:           "pattern changed",
+			oldProp:        map[string]any{"type": "string", "pattern": "^[A-Z]+$"},
+			newProp:        map[string]any{"type": "string", "pattern": "^[A-Z]{3}$"},
+			backwardErrors: []string{"Property 'field' pattern changed from ^[A-Z]+$ to ^[A-Z]{3}$"},
+			forwardErrors:  []string{"Property 'field' pattern changed from ^[A-Z]+$ to ^[A-Z]{3}$"},
+		},
+		{
+			name:          "format removed",
+			oldProp:       map[string]any{"type": "string", "format": "email"},
+			newProp:       map[string]any{"type": "string"},
+			forwardErrors: []string{"Property 'field' format changed from email to none"},
+		},
+		{
+			name:           "format added",
+			oldProp:        map[string]any{"type": "string"},
+			newProp:        map[string]any{"type": "string", "format": "uuid"},
+			backwardErrors: []string{"Property 'field' format changed from none to uuid"},
+		},
+		{
+			name:           "const changed",
+			oldProp:        map[string]any{"type": "string", "const": "a"},
+			newProp:        map[string]any{"type": "string", "const": "b"},
+			backwardErrors: []string{`Property 'field' const changed from "a" to "b"`},
+			forwardErrors:  []string{`Property 'field' const changed from "a" to "b"`},
+		},
+		{
+			name:    "GTS ID const changed",
+			oldProp: map[string]any{"type": "string", "const": "gts.x.test.keywords.user.v1.0~"},
+			newProp: map[string]any{"type": "string", "const": "gts.x.test.keywords.user.v1.1~"},
+		},
+		{
+			name:          "multipleOf relaxed to a divisor",
+			oldProp:       map[string]any{"type": "integer", "multipleOf": 10},
+			newProp:       map[string]any{"type": "integer", "multipleOf": 5},
+			forwardErrors: []string{"Property 'field' multipleOf changed from 10 to 5"},
+		},
+		{
+			name:           "multipleOf added",
+			oldProp:        map[string]any{"type": "number"},
+			newProp:        map[string]any{"type": "number", "multipleOf": 0.5},
+			backwardErrors: []string{"Property 'field' multipleOf changed from none to 0.5"},
+		},
+		{
+			name:           "uniqueItems added",
+			oldProp:        map[string]any{"type": "array"},
+			newProp:        map[string]any{"type": "array", "uniqueItems": true},
+			backwardErrors: []string{"Property 'field' uniqueItems changed from false to true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := check(tt.oldProp, tt.newProp)
+			if err != nil {
+				t.Fatalf("Failed to register schemas: %v", err)
+			}
+			if len(result.BackwardErrors) != len(tt.backwardErrors) || len(tt.backwardErrors) > 0 && !reflect.DeepEqual(result.BackwardErrors, tt.backwardErrors) {
+				t.Errorf("Backward errors = %v, want %v", result.BackwardErrors, tt.backwardErrors)
+			}
+			if len(result.ForwardErrors) != len(tt.forwardErrors) || len(tt.forwardErrors) > 0 && !reflect.DeepEqual(result.ForwardErrors, tt.forwardErrors) {
+				t.Errorf("Forward errors = %v, want %v", result.ForwardErrors, tt.forwardErrors)
+			}
+			if result.IsBackwardCompatible != (len(tt.backwardErrors) == 0) || result.IsForwardCompatible != (len(tt.forwardErrors) == 0) {
+				t.Errorf("Unexpected compatibility: backward %t, forward %t", result.IsBackwardCompatible, result.IsForwardCompatible)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AppliedConditional records the branch of an if/then/else block a cast applied
+type AppliedConditional struct {
+	// Path locates the schema holding the block, e.g. "allOf[1]"; empty for the schema root
+	Path string `json:"path"`
+	// Condition describes how the instance satisfied or failed the if condition
+	Condition string `json:"condition"`
+	// Branch is "then" or "else"
+	Branch string `json:"branch"`
+}
+
+// schemaConditional is an if/then/else block found in a schema
+type schemaConditional struct {
+	Path string
+	If   map[string]any
+	Then map[string]any
+	Else map[string]any
+}
+
+// location describes where the block is for messages
+func (c schemaConditional) location() string {
+	if c.Path == "" {
+		return "the schema root"
+	}
+	return c.Path
+}
+
+// collectConditionals returns the if/then/else blocks of a schema object and of its allOf parts.
+// resolve, when not nil, returns the schema a non-local $ref of an allOf part points to.
+func collectConditionals(schema map[string]any, path string, resolve func(ref string) map[string]any) []schemaConditional {
+	return collectConditionalsVisiting(schema, path, resolve, make(map[string]bool))
+}
+
+func collectConditionalsVisiting(schema map[string]any, path string, resolve func(ref string) map[string]any, visiting map[string]bool) []schemaConditional {
+	var result []schemaConditional
+	if cond, ok := schema["if"].(map[string]any); ok {
+		then, _ := schema["then"].(map[string]any)
+		els, _ := schema["else"].(map[string]any)
+		if then != nil || els != nil {
+			result = append(result, schemaConditional{Path: path, If: cond, Then: then, Else: els})
+		}
+	}
+
+	parts, _ := schema["allOf"].([]any)
+	for i, partAny := range parts {
+		part, ok := partAny.(map[string]any)
+		if !ok {
+			continue
+		}
+		partPath := buildPath(path, fmt.Sprintf("allOf[%d]", i))
+		if ref, ok := part["$ref"].(string); ok && resolve != nil && !strings.HasPrefix(ref, "#") {
+			if visiting[ref] {
+				continue
+			}
+			if target := resolve(ref); target != nil {
+				visiting[ref] = true
+				result = append(result, collectConditionalsVisiting(target, strings.TrimPrefix(ref, GtsURIPrefix), resolve, visiting)...)
+				delete(visiting, ref)
+			}
+			continue
+		}
+		result = append(result, collectConditionalsVisiting(part, partPath, resolve, visiting)...)
+	}
+	return result
+}
+
+// evaluateSimpleCondition evaluates an if schema made of const or enum constraints on properties,
+// optionally with required and type, against an instance. It follows JSON Schema semantics: a
+// constrained property that is absent does not fail the condition unless it is required.
+// ok is false when the condition uses anything else and cannot be evaluated here.
+func evaluateSimpleCondition(cond map[string]any, instance map[string]any) (matched bool, description string, ok bool) {
+	for key := range cond {
+		if key != "properties" && key != "required" && key != "type" {
+			return false, "", false
+		}
+	}
+	if t, has := cond["type"]; has && t != "object" {
+		return false, "", false
+	}
+	props, _ := cond["properties"].(map[string]any)
+
+	required := getRequiredSet(cond)
+	for _, name := range sortedKeys(required) {
+		if _, present := instance[name]; !present {
+			return false, fmt.Sprintf("%s is not set", name), true
+		}
+	}
+
+	var holds []string
+	for _, name := range sortedKeys(props) {
+		propSchema, isMap := props[name].(map[string]any)
+		if !isMap {
+			return false, "", false
+		}
+		for key := range propSchema {
+			if key != "const" && key != "enum" && key != "type" {
+				return false, "", false
+			}
+		}
+		constVal, hasConst := propSchema["const"]
+		enumVal, hasEnum := propSchema["enum"].([]any)
+		if !hasConst && !hasEnum {
+			return false, "", false
+		}
+
+		value, present := instance[name]
+		if !present {
+			holds = append(holds, fmt.Sprintf("%s is not set", name))
+			continue
+		}
+		if hasConst && !reflect.DeepEqual(value, constVal) {
+			return false, fmt.Sprintf("%s is %s, not %s", name, jsonText(value), jsonText(constVal)), true
+		}
+		if hasEnum && !containsValue(enumVal, value) {
+			return false, fmt.Sprintf("%s is %s, not one of %s", name, jsonText(value), jsonText(enumVal)), true
+		}
+		holds = append(holds, fmt.Sprintf("%s is %s", name, jsonText(value)))
+	}
+	if len(holds) == 0 {
+		return true, "the condition has no constraints", true
+	}
+	return true, strings.Join(holds, " and "), true
+}
+
+// explainConditionals describes the requirements of conditional branches an instance fails, naming
+// the property values that selected the branch. Conditions that cannot be evaluated are skipped.
+func explainConditionals(instance, schema map[string]any, resolve func(ref string) map[string]any) []string {
+	var explanations []string
+	for _, c := range collectConditionals(schema, "", resolve) {
+		matched, description, ok := evaluateSimpleCondition(c.If, instance)
+		if !ok {
+			continue
+		}
+		branchName, branch, verdict := "then", c.Then, "holds"
+		if !matched {
+			branchName, branch, verdict = "else", c.Else, "does not hold"
+		}
+		if branch == nil {
+			continue
+		}
+
+		var missing []string
+		for _, name := range sortedKeys(getRequiredSet(branch)) {
+			if _, present := instance[name]; !present {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		verb := "is"
+		if len(missing) > 1 {
+			verb = "are"
+		}
+		explanations = append(explanations, fmt.Sprintf("the condition at %s %s (%s), so its %s branch requires %s, which %s missing",
+			c.location(), verdict, description, branchName, strings.Join(missing, ", "), verb))
+	}
+	return explanations
+}
+
+// storeSchemaResolver returns a resolver of GTS schema references against the store
+func (s *GtsStore) storeSchemaResolver() func(ref string) map[string]any {
+	return func(ref string) map[string]any {
+		entity := s.Get(strings.TrimPrefix(ref, GtsURIPrefix))
+		if entity == nil || !entity.IsSchema {
+			return nil
+		}
+		return entity.Content
+	}
+}
+
+// applyConditionals merges the matching branch of each simple condition of the target schema into
+// a copy of the flattened schema: branch properties are merged into the properties of the same
+// name and branch requirements are returned separately, so that casts can name the condition when
+// they cannot be met. Conditions that cannot be evaluated are reported as warnings.
+func applyConditionals(instance, flattened map[string]any, conditionals []schemaConditional) (map[string]any, []AppliedConditional, map[string]AppliedConditional, []string) {
+	if len(conditionals) == 0 {
+		return flattened, nil, nil, nil
+	}
+
+	result := copyMap(flattened)
+	props, _ := result["properties"].(map[string]any)
+	if props == nil {
+		props = make(map[string]any)
+		result["properties"] = props
+	}
+
+	var applied []AppliedConditional
+	requiredBy := make(map[string]AppliedConditional)
+	var warnings []string
+	for _, c := range conditionals {
+		matched, description, ok := evaluateSimpleCondition(c.If, instance)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Conditional at %s was not evaluated: only const and enum conditions on properties are applied by casts", c.location()))
+			continue
+		}
+		branchName, branch := "then", c.Then
+		if !matched {
+			branchName, branch = "else", c.Else
+		}
+		if branch == nil {
+			continue
+		}
+
+		entry := AppliedConditional{Path: c.Path, Condition: description, Branch: branchName}
+		applied = append(applied, entry)
+		branchProps, _ := branch["properties"].(map[string]any)
+		for name, branchPropAny := range branchProps {
+			branchProp, isMap := branchPropAny.(map[string]any)
+			if !isMap {
+				continue
+			}
+			merged := make(map[string]any)
+			if base, isMap := props[name].(map[string]any); isMap {
+				for k, v := range base {
+					merged[k] = v
+				}
+			}
+			for k, v := range branchProp {
+				merged[k] = v
+			}
+			props[name] = merged
+		}
+		for name := range getRequiredSet(branch) {
+			if _, seen := requiredBy[name]; !seen {
+				requiredBy[name] = entry
+			}
+		}
+	}
+	return result, applied, requiredBy, warnings
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsValue reports whether values holds a value deeply equal to v
+func containsValue(values []any, v any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonText renders a JSON value for messages
+func jsonText(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	shipmentSchemaV10 = "gts.x.commerce.orders.shipment.v1.0~"
+	shipmentSchemaV11 = "gts.x.commerce.orders.shipment.v1.1~"
+)
+
+// shipmentSchema returns an order schema where shipped orders require a tracking number and carrier
+func shipmentSchema(id string, carrierDefault bool) map[string]any {
+	carrier := map[string]any{"type": "string"}
+	if carrierDefault {
+		carrier["default"] = "ups"
+	}
+	return map[string]any{
+		"$id":     "gts://" + id,
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"id":             map[string]any{"type": "string"},
+			"status":         map[string]any{"type": "string", "enum": []any{"pending", "shipped"}},
+			"trackingNumber": map[string]any{"type": "string"},
+		},
+		"if": map[string]any{
+			"properties": map[string]any{"status": map[string]any{"const": "shipped"}},
+			"required":   []any{"status"},
+		},
+		"then": map[string]any{
+			"required":   []any{"trackingNumber", "carrier"},
+			"properties": map[string]any{"carrier": carrier},
+		},
+	}
+}
+
+func newShipmentStore(t *testing.T, instances ...map[string]any) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	entities := append([]map[string]any{
+		shipmentSchema(shipmentSchemaV10, false),
+		shipmentSchema(shipmentSchemaV11, true),
+	}, instances...)
+	registerTestEntities(t, store, entities...)
+	return store
+}
+
+func TestValidate_ConditionalExplanation(t *testing.T) {
+	shippedID := shipmentSchemaV10 + "x.shop._.shipped.v1"
+	pendingID := shipmentSchemaV10 + "x.shop._.pending.v1"
+	store := newShipmentStore(t,
+		map[string]any{"id": shippedID, "status": "shipped", "carrier": "dhl"},
+		map[string]any{"id": pendingID, "status": "pending"},
+	)
+
+	result := store.ValidateInstance(shippedID)
+	if result.OK {
+		t.Fatal("Expected a shipped order without tracking number to fail")
+	}
+	expected := `the condition at the schema root holds (status is "shipped"), so its then branch requires trackingNumber, which is missing`
+	if !strings.Contains(result.Error, expected) {
+		t.Errorf("Expected the error to explain the condition, got: %s", result.Error)
+	}
+
+	if result := store.ValidateInstance(pendingID); !result.OK {
+		t.Errorf("Expected a pending order to validate, got: %s", result.Error)
+	}
+}
+
+func TestCast_ConditionalBranch(t *testing.T) {
+	trackedID := shipmentSchemaV10 + "x.shop._.tracked.v1"
+	untrackedID := shipmentSchemaV10 + "x.shop._.untracked.v1"
+	pendingID := shipmentSchemaV10 + "x.shop._.pending.v1"
+	store := newShipmentStore(t,
+		map[string]any{"id": trackedID, "status": "shipped", "trackingNumber": "1Z999", "carrier": "dhl"},
+		map[string]any{"id": untrackedID, "status": "shipped"},
+		map[string]any{"id": pendingID, "status": "pending"},
+	)
+
+	// The then branch of the target declares a carrier default; the instance keeps its own carrier
+	result, err := store.Cast(trackedID, shipmentSchemaV11)
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	applied := []AppliedConditional{{Path: "", Condition: `status is "shipped"`, Branch: "then"}}
+	if !reflect.DeepEqual(result.ConditionalBranches, applied) {
+		t.Errorf("Expected applied branches %+v, got %+v", applied, result.ConditionalBranches)
+	}
+	if result.CastedEntity["carrier"] != "dhl" || !result.IsFullyCompatible {
+		t.Errorf("Unexpected cast: %+v, reasons %v", result.CastedEntity, result.IncompatibilityReasons)
+	}
+
+	// The carrier is filled from the branch default; the missing tracking number is reported with its condition
+	result, err = store.Cast(untrackedID, shipmentSchemaV11)
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	if result.CastedEntity["carrier"] != "ups" {
+		t.Errorf("Expected the branch default carrier, got %+v", result.CastedEntity)
+	}
+	reason := `Missing property 'trackingNumber' required by the then branch of the condition at the schema root (status is "shipped") and no default is defined (target schema ` + shipmentSchemaV11 + `)`
+	if result.IsFullyCompatible || len(result.IncompatibilityReasons) == 0 || result.IncompatibilityReasons[0] != reason {
+		t.Errorf("Expected the conditional requirement to be reported, got %v", result.IncompatibilityReasons)
+	}
+	if detail := result.IncompatibilityDetails[0]; detail.Code != IncompatibilityConditionalRequired || detail.Path != "trackingNumber" {
+		t.Errorf("Unexpected incompatibility detail: %+v", detail)
+	}
+
+	// Pending orders do not select the branch
+	result, err = store.Cast(pendingID, shipmentSchemaV11)
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	if len(result.ConditionalBranches) != 0 || !result.IsFullyCompatible {
+		t.Errorf("Expected no branch for a pending order, got %+v, reasons %v", result.ConditionalBranches, result.IncompatibilityReasons)
+	}
+	if _, ok := result.CastedEntity["carrier"]; ok {
+		t.Error("Expected no carrier default outside the branch")
+	}
+}
+
+func TestCast_ComplexConditionWarning(t *testing.T) {
+	store := newShipmentStore(t)
+	complexSchema := shipmentSchema("gts.x.commerce.orders.shipment.v1.2~", true)
+	complexSchema["if"] = map[string]any{
+		"allOf": []any{map[string]any{"properties": map[string]any{"status": map[string]any{"const": "shipped"}}}},
+	}
+	instanceID := shipmentSchemaV10 + "x.shop._.complex.v1"
+	for _, content := range []map[string]any{complexSchema, {"id": instanceID, "status": "shipped", "trackingNumber": "1Z999"}} {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+	}
+
+	result, err := store.Cast(instanceID, "gts.x.commerce.orders.shipment.v1.2~")
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	found := false
+	for _, warning := range result.Warnings {
+		found = found || strings.Contains(warning, "Conditional at the schema root was not evaluated")
+	}
+	if !found || len(result.ConditionalBranches) != 0 {
+		t.Errorf("Expected a warning for the unevaluated condition, got %v", result.Warnings)
+	}
+}
@@ -5,10 +5,39 @@ Released under Apache License 2.0
 
 package gts
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
 // GtsConfig holds configuration for extracting GTS IDs from JSON content
+// and the limits applied to GTS identifiers
 type GtsConfig struct {
 	EntityIDFields []string
 	SchemaIDFields []string
+	// SchemaIDFieldPrecedence orders the schema-ID fields of instances when several name different
+	// schemas; fields it omits follow in SchemaIDFields order, which is the order when it is empty
+	SchemaIDFieldPrecedence []string
+	// SchemaResolutionOrder is the fallback chain resolving the schema of an instance: the sources
+	// consulted in order until one names a registered schema, each SchemaSourceChain (the type
+	// prefix of a chained entity ID) or a field name. Sources it omits follow in the default order,
+	// SchemaSourceChain then the schema-ID fields in SchemaIDFieldPrecedence order.
+	SchemaResolutionOrder []string
+	// MaxIDLength is the maximum ID length; zero uses the process-wide limit (see SetLimits),
+	// which defaults to MaxIDLength
+	MaxIDLength int
+	// MaxSegments is the maximum number of ID segments; zero uses the process-wide limit
+	MaxSegments int
+	// RecordPositions makes file readers record the line and column of every value of the
+	// entities they parse (see JsonEntity.PositionIndex); off by default as it costs a second pass
+	RecordPositions bool
+	// ExcludeDirs lists the directory names file readers and archive readers skip; nil uses ExcludeList
+	ExcludeDirs []string
 }
 
 // DefaultGtsConfig returns the default configuration for ID extraction
@@ -37,3 +66,93 @@ func DefaultGtsConfig() *GtsConfig {
 		},
 	}
 }
+
+// excludeDirs returns the directory names readers skip
+func (c *GtsConfig) excludeDirs() []string {
+	if c.ExcludeDirs == nil {
+		return ExcludeList
+	}
+	return c.ExcludeDirs
+}
+
+// configFile is the JSON form of GtsConfig read by LoadGtsConfig
+type configFile struct {
+	EntityIDFields          []string `json:"entity_id_fields"`
+	SchemaIDFields          []string `json:"schema_id_fields"`
+	SchemaIDFieldPrecedence []string `json:"schema_id_field_precedence"`
+	SchemaResolutionOrder   []string `json:"schema_resolution_order"`
+	MaxIDLength             int      `json:"max_id_length"`
+	MaxSegments             int      `json:"max_segments"`
+	RecordPositions         bool     `json:"record_positions"`
+	ExcludeDirs             []string `json:"exclude_dirs"`
+}
+
+// ConfigFileError is returned by LoadGtsConfig for a config file that cannot be read or is invalid;
+// Key is set when the file has a key LoadGtsConfig does not know
+type ConfigFileError struct {
+	Path string
+	Key  string
+	Err  error
+}
+
+func (e *ConfigFileError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("config file %s: unknown key '%s' (known keys: %s)", e.Path, e.Key, strings.Join(configFileKeys(), ", "))
+	}
+	return fmt.Sprintf("config file %s: %v", e.Path, e.Err)
+}
+
+func (e *ConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+// configFileKeys returns the keys of a config file
+func configFileKeys() []string {
+	t := reflect.TypeOf(configFile{})
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i] = t.Field(i).Tag.Get("json")
+	}
+	return keys
+}
+
+// LoadGtsConfig reads a GtsConfig from a JSON file, e.g.
+//
+//	{"entity_id_fields": ["$id", "id"], "schema_id_fields": ["typeRef", "type"], "exclude_dirs": ["vendor"]}
+//
+// The keys are entity_id_fields, schema_id_fields, schema_id_field_precedence,
+// schema_resolution_order, max_id_length, max_segments, record_positions and exclude_dirs; the
+// keys a file omits keep the values of DefaultGtsConfig. Unknown keys, values of the wrong type and
+// negative limits fail with ConfigFileError.
+func LoadGtsConfig(path string) (*GtsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ConfigFileError{Path: path, Err: err}
+	}
+
+	defaults := DefaultGtsConfig()
+	file := configFile{EntityIDFields: defaults.EntityIDFields, SchemaIDFields: defaults.SchemaIDFields}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		// encoding/json reports unknown keys as `json: unknown field "key"`
+		if key, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return nil, &ConfigFileError{Path: path, Key: strings.Trim(key, `"`), Err: err}
+		}
+		return nil, &ConfigFileError{Path: path, Err: err}
+	}
+	if file.MaxIDLength < 0 || file.MaxSegments < 0 {
+		return nil, &ConfigFileError{Path: path, Err: errors.New("max_id_length and max_segments must not be negative")}
+	}
+
+	return &GtsConfig{
+		EntityIDFields:          file.EntityIDFields,
+		SchemaIDFields:          file.SchemaIDFields,
+		SchemaIDFieldPrecedence: file.SchemaIDFieldPrecedence,
+		SchemaResolutionOrder:   file.SchemaResolutionOrder,
+		MaxIDLength:             file.MaxIDLength,
+		MaxSegments:             file.MaxSegments,
+		RecordPositions:         file.RecordPositions,
+		ExcludeDirs:             file.ExcludeDirs,
+	}, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile writes a config file and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gts.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadGtsConfig(t *testing.T) {
+	cfg, err := LoadGtsConfig(writeConfigFile(t, `{"schema_id_fields": ["typeRef"], "exclude_dirs": ["drafts"], "max_segments": 8}`))
+	if err != nil {
+		t.Fatalf("LoadGtsConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.SchemaIDFields, []string{"typeRef"}) || cfg.MaxSegments != 8 || !reflect.DeepEqual(cfg.ExcludeDirs, []string{"drafts"}) {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.EntityIDFields, DefaultGtsConfig().EntityIDFields) {
+		t.Errorf("Expected omitted keys to keep their defaults, got %v", cfg.EntityIDFields)
+	}
+
+	entity := NewJsonEntity(map[string]any{"id": "7a1d2f7e", "typeRef": "gts.x.core.events.order.v1~", "type": "order"}, cfg)
+	if entity.SchemaID != "gts.x.core.events.order.v1~" || entity.SelectedSchemaIDField != "typeRef" {
+		t.Errorf("Expected the schema ID to be read from typeRef, got %q from %q", entity.SchemaID, entity.SelectedSchemaIDField)
+	}
+}
+
+func TestLoadGtsConfig_Errors(t *testing.T) {
+	var cfgErr *ConfigFileError
+	_, err := LoadGtsConfig(writeConfigFile(t, `{"schema_id_field": ["typeRef"]}`))
+	if !errors.As(err, &cfgErr) || cfgErr.Key != "schema_id_field" || !strings.Contains(err.Error(), "schema_id_fields") {
+		t.Errorf("Expected an unknown key error listing the known keys, got %v", err)
+	}
+	for _, content := range []string{`{"max_id_length": "long"}`, `{"max_segments": -1}`, `{"entity_id_fields": `} {
+		if _, err := LoadGtsConfig(writeConfigFile(t, content)); !errors.As(err, &cfgErr) || cfgErr.Key != "" {
+			t.Errorf("Expected a ConfigFileError for %s, got %v", content, err)
+		}
+	}
+	if _, err := LoadGtsConfig(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
+
+func TestGtsFileReader_ExcludeDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"drafts", "build"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", sub, err)
+		}
+		content := `{"$id": "gts://gts.x.test.exclude.` + sub + `.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`
+		if err := os.WriteFile(filepath.Join(dir, sub, "schema.json"), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+	}
+
+	cfg := DefaultGtsConfig()
+	cfg.ExcludeDirs = []string{"drafts"}
+	store := NewGtsStore(NewGtsFileReader([]string{dir}, cfg))
+	if store.Get("gts.x.test.exclude.drafts.v1~") != nil || store.Get("gts.x.test.exclude.build.v1~") == nil {
+		t.Errorf("Expected ExcludeDirs to replace the default exclusions, got %d entities", store.Count())
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Conformance fixture operations
+const (
+	ConformanceParseID       = "parse-id"
+	ConformanceValidateID    = "validate-id"
+	ConformanceMatchID       = "match-id"
+	ConformanceUUID          = "uuid"
+	ConformanceExtractID     = "extract-id"
+	ConformanceCompatibility = "compatibility"
+	ConformanceCast          = "cast"
+)
+
+// ConformanceFixture describes a single operation, its inputs and the expected outputs.
+// Fixtures are language neutral JSON so that every GTS implementation can run the same set.
+//
+// Expect is matched as a subset of the operation result: every key present in Expect must be
+// present in the result with an equal value, while extra result keys are ignored. Arrays are
+// compared element by element and must have the same length.
+type ConformanceFixture struct {
+	Name      string `json:"name"`
+	Operation string `json:"operation"`
+	// Entities are registered in a fresh store before running store backed operations
+	// (compatibility, cast)
+	Entities            []map[string]any `json:"entities,omitempty"`
+	Input               map[string]any   `json:"input"`
+	Expect              map[string]any   `json:"expect,omitempty"`
+	ExpectErrorContains string           `json:"expect_error_contains,omitempty"`
+	// Skip holds the reason for skipping the fixture; an empty value means the fixture runs
+	Skip string `json:"skip,omitempty"`
+	// File is the fixture file the fixture was loaded from
+	File string `json:"-"`
+}
+
+// conformanceFile is the on-disk layout of a fixture file
+type conformanceFile struct {
+	Description string               `json:"description,omitempty"`
+	Fixtures    []ConformanceFixture `json:"fixtures"`
+}
+
+// ConformanceResult is the outcome of running a single fixture
+type ConformanceResult struct {
+	Name      string   `json:"name"`
+	File      string   `json:"file"`
+	Operation string   `json:"operation"`
+	Passed    bool     `json:"passed"`
+	Skipped   bool     `json:"skipped"`
+	Skip      string   `json:"skip,omitempty"`
+	Diffs     []string `json:"diffs,omitempty"`
+}
+
+// LoadConformanceFixtures loads every *.json fixture file found in fsys, in lexical path order
+func LoadConformanceFixtures(fsys fs.FS) ([]ConformanceFixture, error) {
+	var fixtures []ConformanceFixture
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		var file conformanceFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("invalid fixture file %s: %w", p, err)
+		}
+
+		for i, fixture := range file.Fixtures {
+			if fixture.Name == "" {
+				return fmt.Errorf("invalid fixture file %s: fixture %d has no name", p, i)
+			}
+			if !isConformanceOperation(fixture.Operation) {
+				return fmt.Errorf("invalid fixture file %s: fixture '%s' has unknown operation '%s'", p, fixture.Name, fixture.Operation)
+			}
+			fixture.File = p
+			fixtures = append(fixtures, fixture)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fixtures, nil
+}
+
+// RunConformanceSuite loads the fixtures from fsys and runs each of them against this implementation
+func RunConformanceSuite(fsys fs.FS) ([]ConformanceResult, error) {
+	fixtures, err := LoadConformanceFixtures(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ConformanceResult, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		results = append(results, RunConformanceFixture(fixture))
+	}
+	return results, nil
+}
+
+// RunConformanceFixture runs a single fixture and reports every mismatch as a diff line
+func RunConformanceFixture(fixture ConformanceFixture) ConformanceResult {
+	result := ConformanceResult{
+		Name:      fixture.Name,
+		File:      fixture.File,
+		Operation: fixture.Operation,
+	}
+	if fixture.Skip != "" {
+		result.Skipped = true
+		result.Skip = fixture.Skip
+		return result
+	}
+
+	output, err := runConformanceOperation(fixture)
+
+	var actual any
+	if output != nil {
+		actual, err = toConformanceJSON(output, err)
+	}
+
+	errText := ""
+	if err != nil {
+		errText = err.Error()
+	} else if m, ok := actual.(map[string]any); ok {
+		errText, _ = m["error"].(string)
+	}
+
+	switch {
+	case fixture.ExpectErrorContains != "":
+		if !strings.Contains(errText, fixture.ExpectErrorContains) {
+			result.Diffs = append(result.Diffs, fmt.Sprintf("error: expected error containing %q, got %q", fixture.ExpectErrorContains, errText))
+		}
+	case err != nil:
+		result.Diffs = append(result.Diffs, fmt.Sprintf("error: unexpected error %q", errText))
+	}
+
+	if fixture.Expect != nil && actual != nil {
+		compareConformanceValue("$", map[string]any(fixture.Expect), actual, &result.Diffs)
+	}
+
+	result.Passed = len(result.Diffs) == 0
+	return result
+}
+
+// runConformanceOperation dispatches a fixture to the matching GTS operation
+func runConformanceOperation(fixture ConformanceFixture) (any, error) {
+	switch fixture.Operation {
+	case ConformanceParseID:
+		id, err := conformanceString(fixture.Input, "id")
+		if err != nil {
+			return nil, err
+		}
+		return ParseGtsID(id), nil
+
+	case ConformanceValidateID:
+		id, err := conformanceString(fixture.Input, "id")
+		if err != nil {
+			return nil, err
+		}
+		return ValidateGtsID(id), nil
+
+	case ConformanceMatchID:
+		candidate, err := conformanceString(fixture.Input, "candidate")
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := conformanceString(fixture.Input, "pattern")
+		if err != nil {
+			return nil, err
+		}
+		return MatchIDPattern(candidate, pattern), nil
+
+	case ConformanceUUID:
+		id, err := conformanceString(fixture.Input, "id")
+		if err != nil {
+			return nil, err
+		}
+		return IDToUUID(id), nil
+
+	case ConformanceExtractID:
+		content, ok := fixture.Input["content"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("fixture input 'content' must be an object")
+		}
+		return ExtractGtsID(content, nil), nil
+
+	case ConformanceCompatibility:
+		store, err := newConformanceStore(fixture.Entities)
+		if err != nil {
+			return nil, err
+		}
+		oldID, err := conformanceString(fixture.Input, "old_schema_id")
+		if err != nil {
+			return nil, err
+		}
+		newID, err := conformanceString(fixture.Input, "new_schema_id")
+		if err != nil {
+			return nil, err
+		}
+		return store.CheckCompatibility(oldID, newID), nil
+
+	case ConformanceCast:
+		store, err := newConformanceStore(fixture.Entities)
+		if err != nil {
+			return nil, err
+		}
+		instanceID, err := conformanceString(fixture.Input, "instance_id")
+		if err != nil {
+			return nil, err
+		}
+		toID, err := conformanceString(fixture.Input, "to_schema_id")
+		if err != nil {
+			return nil, err
+		}
+		result, err := store.Cast(instanceID, toID)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation '%s'", fixture.Operation)
+}
+
+// newConformanceStore creates an isolated store populated with the fixture entities
+func newConformanceStore(entities []map[string]any) (*GtsStore, error) {
+	store := NewGtsStore(nil)
+	for i, content := range entities {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			return nil, fmt.Errorf("failed to register fixture entity %d: %w", i, err)
+		}
+	}
+	return store, nil
+}
+
+func isConformanceOperation(op string) bool {
+	switch op {
+	case ConformanceParseID, ConformanceValidateID, ConformanceMatchID, ConformanceUUID,
+		ConformanceExtractID, ConformanceCompatibility, ConformanceCast:
+		return true
+	}
+	return false
+}
+
+func conformanceString(input map[string]any, key string) (string, error) {
+	value, ok := input[key].(string)
+	if !ok {
+		return "", fmt.Errorf("fixture input '%s' must be a string", key)
+	}
+	return value, nil
+}
+
+// toConformanceJSON round-trips an operation result through JSON so it can be compared
+// with the generic values decoded from fixture files
+func toConformanceJSON(output any, opErr error) (any, error) {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	var actual any
+	if err := json.Unmarshal(data, &actual); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return actual, opErr
+}
+
+// compareConformanceValue checks that expected is a subset of actual, appending a diff line per mismatch
+func compareConformanceValue(p string, expected, actual any, diffs *[]string) {
+	switch exp := expected.(type) {
+	case map[string]any:
+		act, ok := actual.(map[string]any)
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", p, conformanceJSON(expected), conformanceJSON(actual)))
+			return
+		}
+		keys := make([]string, 0, len(exp))
+		for k := range exp {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			value, present := act[k]
+			if !present {
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: expected %s, got nothing", p, k, conformanceJSON(exp[k])))
+				continue
+			}
+			compareConformanceValue(p+"."+k, exp[k], value, diffs)
+		}
+
+	case []any:
+		act, ok := actual.([]any)
+		if !ok || len(act) != len(exp) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", p, conformanceJSON(expected), conformanceJSON(actual)))
+			return
+		}
+		for i := range exp {
+			compareConformanceValue(fmt.Sprintf("%s[%d]", p, i), exp[i], act[i], diffs)
+		}
+
+	default:
+		if conformanceJSON(expected) != conformanceJSON(actual) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", p, conformanceJSON(expected), conformanceJSON(actual)))
+		}
+	}
+}
+
+func conformanceJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestConformance runs the shared conformance fixtures against this implementation
+func TestConformance(t *testing.T) {
+	RunConformance(t, os.DirFS("../conformance/fixtures"))
+}
+
+func TestRunConformanceFixture_ReportsDiffs(t *testing.T) {
+	result := RunConformanceFixture(ConformanceFixture{
+		Name:      "wrong expectation",
+		Operation: ConformanceParseID,
+		Input:     map[string]any{"id": "gts.x.core.events.type.v1~"},
+		Expect: map[string]any{
+			"ok":       true,
+			"segments": []any{map[string]any{"vendor": "y", "ver_major": float64(1)}},
+		},
+	})
+
+	if result.Passed {
+		t.Fatal("Expected fixture to fail")
+	}
+	if len(result.Diffs) != 1 {
+		t.Fatalf("Expected 1 diff, got %v", result.Diffs)
+	}
+	if !strings.Contains(result.Diffs[0], `$.segments[0].vendor: expected "y", got "x"`) {
+		t.Errorf("Unexpected diff: %s", result.Diffs[0])
+	}
+}
+
+func TestRunConformanceFixture_ExpectedError(t *testing.T) {
+	fixture := ConformanceFixture{
+		Name:                "unknown instance",
+		Operation:           ConformanceCast,
+		Input:               map[string]any{"instance_id": "gts.x.core.events.type.v1~a.b.c.d.v1", "to_schema_id": "gts.x.core.events.type.v1~"},
+		ExpectErrorContains: "gts.x.core.events.type.v1~a.b.c.d.v1",
+	}
+	if result := RunConformanceFixture(fixture); !result.Passed {
+		t.Errorf("Expected fixture to pass, got diffs: %v", result.Diffs)
+	}
+
+	fixture.ExpectErrorContains = "something else"
+	if result := RunConformanceFixture(fixture); result.Passed {
+		t.Error("Expected fixture to fail on a non-matching error")
+	}
+
+	fixture.ExpectErrorContains = ""
+	if result := RunConformanceFixture(fixture); result.Passed {
+		t.Error("Expected fixture to fail on an unexpected error")
+	}
+}
+
+func TestRunConformanceFixture_Skip(t *testing.T) {
+	result := RunConformanceFixture(ConformanceFixture{
+		Name:      "skipped",
+		Operation: ConformanceUUID,
+		Skip:      "not supported yet",
+	})
+	if !result.Skipped || result.Skip != "not supported yet" {
+		t.Errorf("Expected skipped result, got %+v", result)
+	}
+}
+
+func TestLoadConformanceFixtures_UnknownOperation(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.json": {Data: []byte(`{"fixtures": [{"name": "x", "operation": "frobnicate"}]}`)},
+	}
+	if _, err := LoadConformanceFixtures(fsys); err == nil || !strings.Contains(err.Error(), "frobnicate") {
+		t.Errorf("Expected unknown operation error, got %v", err)
+	}
+}
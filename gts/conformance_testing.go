@@ -0,0 +1,38 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// RunConformance runs every fixture found in fsys as a subtest of t.
+// Skipped fixtures are reported with t.Skip and mismatches are reported with one diff line each.
+func RunConformance(t *testing.T, fsys fs.FS) {
+	t.Helper()
+
+	fixtures, err := LoadConformanceFixtures(fsys)
+	if err != nil {
+		t.Fatalf("failed to load conformance fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no conformance fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(strings.TrimSuffix(fixture.File, ".json")+"/"+fixture.Name, func(t *testing.T) {
+			result := RunConformanceFixture(fixture)
+			if result.Skipped {
+				t.Skip(result.Skip)
+			}
+			for _, diff := range result.Diffs {
+				t.Errorf("%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import "fmt"
+
+// ApplyDefaultsResult represents an instance filled with the defaults declared by its schema
+type ApplyDefaultsResult struct {
+	ID       string `json:"id,omitempty"`
+	SchemaID string `json:"schema_id"`
+	// Entity is the instance with the defaults applied; it equals the instance when Added is empty
+	Entity map[string]any `json:"entity"`
+	// Added lists the paths of the properties set from a default, e.g. "shipping.method" or "items[0].qty"
+	Added []string `json:"added"`
+	// MissingRequired lists the paths of required properties that are missing and have no default
+	MissingRequired []string `json:"missing_required,omitempty"`
+	// ConditionalBranches lists the if/then/else branches of the schema applied to the instance
+	ConditionalBranches []AppliedConditional `json:"conditional_branches,omitempty"`
+	Warnings            []string             `json:"warnings,omitempty"`
+}
+
+// ApplyDefaults fills the missing properties of a registered instance that declare a default in
+// its own schema, the way Cast fills them for a target schema: required and optional properties,
+// in nested objects, arrays of objects and the selected if/then/else branches. Unlike Cast it
+// never removes properties nor rewrites GTS ID consts, and it does not validate the result. The
+// registered instance is left unchanged.
+// A panic is returned as an error wrapping ErrInternal.
+func (s *GtsStore) ApplyDefaults(instanceID string) (result *ApplyDefaultsResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, newStoreInternalError("ApplyDefaults", r)
+		}
+	}()
+
+	entity := s.Get(instanceID)
+	if entity == nil {
+		return nil, &StoreGtsObjectNotFoundError{EntityID: instanceID}
+	}
+	return s.applyDefaults(entity, "")
+}
+
+// ApplyDefaultsToContent fills the defaults of instance content that is not registered, e.g. an
+// inbound API payload, like ApplyDefaults. The defaults come from the schema schemaID, or when
+// schemaID is empty from the schema resolved from the content as on registration. The content
+// is not modified.
+// A panic is returned as an error wrapping ErrInternal.
+func (s *GtsStore) ApplyDefaultsToContent(content map[string]any, schemaID string) (result *ApplyDefaultsResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, newStoreInternalError("ApplyDefaultsToContent", r)
+		}
+	}()
+
+	return s.applyDefaults(NewJsonEntity(content, s.GtsConfig()), schemaID)
+}
+
+// applyDefaults fills the defaults of an instance entity from schemaID, or from its own schema
+// when schemaID is empty
+func (s *GtsStore) applyDefaults(entity *JsonEntity, schemaID string) (*ApplyDefaultsResult, error) {
+	if entity.IsSchema {
+		return nil, fmt.Errorf("entity '%s' is a schema; defaults apply to instances", entity.Label)
+	}
+	if schemaID == "" {
+		resolved, _, err := s.resolveInstanceSchema(entity)
+		if err != nil {
+			return nil, err
+		}
+		schemaID = resolved
+	}
+	schema, err := s.ResolveSchema(schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, warnings := normalizeSchema(schema.Content)
+	flat, defects, unresolved := flattenSchemaWithStore(normalized, s, nil)
+	flat, branches, requiredBy, conditionalWarnings := applyConditionals(
+		entity.Content, flat, collectConditionals(normalized, "", s.storeSchemaResolver()))
+
+	filled := copyMap(entity.Content)
+	if filled == nil {
+		filled = map[string]any{}
+	}
+	added, missing := fillDefaults(filled, flat, "", newLocalRefResolver(schema.Content))
+	addedByBranch, _ := castConditionalRequirements(filled, flat, requiredBy)
+	for name := range requiredBy {
+		if _, exists := filled[name]; !exists {
+			missing = append(missing, name)
+		}
+	}
+
+	result := &ApplyDefaultsResult{
+		SchemaID:            schema.GtsID.ID,
+		Entity:              filled,
+		Added:               deduplicate(append(added, addedByBranch...)),
+		ConditionalBranches: branches,
+		Warnings: mergeWarnings(warnings, conditionalWarnings,
+			schemaDefects(schema.GtsID.ID, defects), schemaDefects(schema.GtsID.ID, unresolved)),
+	}
+	if len(missing) > 0 {
+		result.MissingRequired = deduplicate(missing)
+	}
+	if entity.GtsID != nil {
+		result.ID = entity.GtsID.ID
+	}
+	return result, nil
+}
+
+// fillDefaults sets in place the missing properties of obj that declare a default in schema and
+// recurses into nested objects and arrays of objects as castInstanceToSchema does. It returns the
+// paths of the added properties and of the missing required properties without default.
+func fillDefaults(obj, schema map[string]any, basePath string, refs *localRefResolver) ([]string, []string) {
+	targetProps := getPropertiesMap(schema)
+	added, missing := fillObjectDefaults(obj, targetProps, getRequiredSet(schema), basePath, refs)
+
+	for _, prop := range sortedKeys(targetProps) {
+		val, exists := obj[prop]
+		propSchema, ok := targetProps[prop].(map[string]any)
+		if !exists || !ok {
+			continue
+		}
+		propSchema = refs.resolve(propSchema)
+		path := buildPath(basePath, prop)
+
+		if valMap, isMap := val.(map[string]any); isMap && castsAsObject(propSchema) {
+			addSub, missSub := fillDefaults(valMap, effectiveObjectSchema(propSchema), path, refs)
+			added, missing = append(added, addSub...), append(missing, missSub...)
+		}
+		if valArray, isArray := val.([]any); isArray && getString(propSchema, "type") == "array" {
+			addSub, missSub := fillArrayDefaults(valArray, propSchema, path, refs)
+			added, missing = append(added, addSub...), append(missing, missSub...)
+		}
+	}
+	return added, missing
+}
+
+// fillArrayDefaults fills the defaults of the object elements of an array in place, using their
+// tuple (prefixItems) or items schema as castArrayToSchema does; surplus elements are kept
+func fillArrayDefaults(values []any, schema map[string]any, path string, refs *localRefResolver) ([]string, []string) {
+	var added, missing []string
+	tuple := getTupleItems(schema)
+	itemsSchema := refs.resolve(getMap(schema, "items"))
+	for idx, item := range values {
+		elemSchema := itemsSchema
+		if idx < len(tuple) {
+			elemSchema = refs.resolve(tuple[idx])
+		}
+		itemMap, isMap := item.(map[string]any)
+		if !isMap || !castsAsObject(elemSchema) {
+			continue
+		}
+		addSub, missSub := fillDefaults(itemMap, effectiveObjectSchema(elemSchema), fmt.Sprintf("%s[%d]", path, idx), refs)
+		added, missing = append(added, addSub...), append(missing, missSub...)
+	}
+	return added, missing
+}
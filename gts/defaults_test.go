@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const defaultsSchemaID = "gts.x.test.defaults.order.v1~"
+
+// registerDefaultsSchema registers a schema declaring defaults at the root, in a nested object,
+// in the elements of an array and behind a $ref, with a closed object and a const GTS ID field
+func registerDefaultsSchema(t *testing.T, store *GtsStore) {
+	t.Helper()
+	schema := map[string]any{
+		"$id":                  "gts://" + defaultsSchemaID,
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"type":                 "object",
+		"required":             []any{"id", "status", "currency"},
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"line": map[string]any{
+				"type":       "object",
+				"required":   []any{"sku"},
+				"properties": map[string]any{"sku": map[string]any{"type": "string"}, "qty": map[string]any{"type": "integer", "default": 1}},
+			},
+		},
+		"properties": map[string]any{
+			"id":       map[string]any{"type": "string"},
+			"status":   map[string]any{"type": "string", "default": "new"},
+			"currency": map[string]any{"type": "string"},
+			"priority": map[string]any{"type": "integer", "default": 0},
+			"kind":     map[string]any{"type": "string", "const": "gts.x.test.defaults.kind.v1~"},
+			"shipping": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"method": map[string]any{"type": "string", "default": "standard"}},
+			},
+			"lines": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/line"}},
+		},
+	}
+	if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+}
+
+func TestApplyDefaultsToContent(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerDefaultsSchema(t, store)
+
+	content := map[string]any{
+		"id":       defaultsSchemaID + "x.test._.order1.v1",
+		"kind":     "gts.x.test.defaults.other.v1~",
+		"legacy":   true,
+		"shipping": map[string]any{},
+		"lines":    []any{map[string]any{"sku": "a"}, map[string]any{"sku": "b", "qty": 3}, "not an object"},
+	}
+	result, err := store.ApplyDefaultsToContent(content, "")
+	if err != nil {
+		t.Fatalf("ApplyDefaultsToContent failed: %v", err)
+	}
+
+	expectedAdded := []string{"lines[0].qty", "priority", "shipping.method", "status"}
+	if !reflect.DeepEqual(result.Added, expectedAdded) {
+		t.Errorf("Expected added %v, got %v", expectedAdded, result.Added)
+	}
+	if !reflect.DeepEqual(result.MissingRequired, []string{"currency"}) {
+		t.Errorf("Expected currency to be missing, got %v", result.MissingRequired)
+	}
+	if result.SchemaID != defaultsSchemaID || result.ID != content["id"] {
+		t.Errorf("Unexpected IDs %s and %s", result.ID, result.SchemaID)
+	}
+	entity := result.Entity
+	if entity["status"] != "new" || entity["shipping"].(map[string]any)["method"] != "standard" {
+		t.Errorf("Expected the defaults to be filled, got %v", entity)
+	}
+	if entity["legacy"] != true || entity["kind"] != "gts.x.test.defaults.other.v1~" {
+		t.Errorf("Expected properties and consts to be kept, got %v", entity)
+	}
+	lines := entity["lines"].([]any)
+	if len(lines) != 3 || lines[0].(map[string]any)["qty"] != 1 || lines[1].(map[string]any)["qty"] != 3 {
+		t.Errorf("Expected the array defaults to be filled, got %v", lines)
+	}
+	if _, ok := content["status"]; ok {
+		t.Error("Expected the content not to be modified")
+	}
+
+	// Instances holding every default come back unchanged
+	complete, _ := json.Marshal(entity)
+	again, err := store.ApplyDefaultsToContent(entity, defaultsSchemaID)
+	if err != nil {
+		t.Fatalf("ApplyDefaultsToContent failed: %v", err)
+	}
+	if filled, _ := json.Marshal(again.Entity); string(filled) != string(complete) || len(again.Added) != 0 {
+		t.Errorf("Expected an unchanged entity and no added paths, got %s and %v", filled, again.Added)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerDefaultsSchema(t, store)
+	instanceID := defaultsSchemaID + "x.test._.order2.v1"
+	if err := store.Register(NewJsonEntity(map[string]any{"id": instanceID, "currency": "EUR"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+
+	result, err := store.ApplyDefaults(instanceID)
+	if err != nil {
+		t.Fatalf("ApplyDefaults failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Added, []string{"priority", "status"}) || len(result.MissingRequired) != 0 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if _, ok := store.Get(instanceID).Content["status"]; ok {
+		t.Error("Expected the registered instance not to be modified")
+	}
+
+	_, err = store.ApplyDefaults("gts.x.test.defaults.order.v1~x.test._.missing.v1")
+	var notFound *StoreGtsObjectNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected StoreGtsObjectNotFoundError, got %v", err)
+	}
+	if _, err := store.ApplyDefaults(defaultsSchemaID); err == nil {
+		t.Error("Expected an error for a schema")
+	}
+	if _, err := store.ApplyDefaultsToContent(map[string]any{"name": "untyped"}, ""); err == nil {
+		t.Error("Expected an error for content without schema")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DependentValidationMode controls how instances of a schema are re-validated when the schema is
+// overwritten with different content
+type DependentValidationMode int
+
+const (
+	// DependentValidationOff overwrites schemas without looking at their instances
+	DependentValidationOff DependentValidationMode = iota
+	// DependentValidationReport overwrites the schema and attaches a report of the instances it breaks
+	DependentValidationReport
+	// DependentValidationReject refuses to overwrite a schema that would break any of its instances
+	DependentValidationReject
+)
+
+// MaxReportedDependentFailures caps the invalid instance IDs listed in a DependentsReport
+const MaxReportedDependentFailures = 20
+
+// String returns the textual name of the mode
+func (m DependentValidationMode) String() string {
+	switch m {
+	case DependentValidationReport:
+		return "report"
+	case DependentValidationReject:
+		return "reject"
+	default:
+		return "off"
+	}
+}
+
+// ParseDependentValidationMode parses "off", "report" or "reject" into a DependentValidationMode
+func ParseDependentValidationMode(s string) (DependentValidationMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off":
+		return DependentValidationOff, nil
+	case "report":
+		return DependentValidationReport, nil
+	case "reject":
+		return DependentValidationReject, nil
+	default:
+		return DependentValidationOff, fmt.Errorf("invalid dependent validation mode '%s' (expected off, report or reject)", s)
+	}
+}
+
+// DependentsReport describes the re-validation of a schema's instances against new content
+type DependentsReport struct {
+	SchemaID string `json:"schema_id"`
+	// Checked is the number of registered instances of the schema
+	Checked int `json:"checked"`
+	// Invalid is the number of instances that do not validate against the new content
+	Invalid int `json:"invalid"`
+	// InvalidIDs lists up to MaxReportedDependentFailures invalid instance IDs in ID order
+	InvalidIDs []string `json:"invalid_ids,omitempty"`
+	// Error is set when the new content could not be compiled, in which case no instance was checked
+	Error string `json:"error,omitempty"`
+}
+
+// DependentInstancesInvalidError is returned when a schema overwrite is rejected because registered
+// instances would no longer validate
+type DependentInstancesInvalidError struct {
+	Report *DependentsReport
+}
+
+func (e *DependentInstancesInvalidError) Error() string {
+	if e.Report.Error != "" {
+		return fmt.Sprintf("Schema %s cannot replace the registered schema: %s", e.Report.SchemaID, e.Report.Error)
+	}
+	return fmt.Sprintf("Schema change for %s would invalidate %d of %d registered instance(s): %s",
+		e.Report.SchemaID, e.Report.Invalid, e.Report.Checked, strings.Join(e.Report.InvalidIDs, ", "))
+}
+
+// revalidateDependents re-validates the instances of a schema being overwritten against the new content.
+// It returns nil when the entity does not replace a registered schema with different content.
+// Instances are taken from a snapshot, so the store stays available while they are validated;
+// the new content is compiled once for all of them. Instances are those ValidateInstance would validate
+// against the schema, i.e. whose schema ID resolves to the schema's ID, including schema IDs without
+// minor version naming it as their latest minor version.
+func (s *GtsStore) revalidateDependents(entity *JsonEntity) *DependentsReport {
+	schemaID := entity.GtsID.ID
+	s.mu.RLock()
+	previous := s.byID[schemaID]
+	s.mu.RUnlock()
+	if previous == nil || !previous.IsSchema || reflect.DeepEqual(previous.Content, entity.Content) {
+		return nil
+	}
+
+	var dependents []*JsonEntity
+	for _, candidate := range s.entitySnapshot() {
+		if candidate.IsSchema || candidate.GtsID == nil {
+			continue
+		}
+		if resolved, _, _ := resolveInstanceSchemaWith(candidate, s.lookupSchema); resolved == schemaID {
+			dependents = append(dependents, candidate)
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool {
+		return dependents[i].GtsID.ID < dependents[j].GtsID.ID
+	})
+
+	report := &DependentsReport{SchemaID: schemaID, Checked: len(dependents)}
+	if len(dependents) == 0 {
+		return report
+	}
+
+	compiled, err := s.compileSchema(entity.Content)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	xGtsRefValidator := s.newInstanceXGtsRefValidator()
+	for _, dependent := range dependents {
+		if compiled.Validate(dependent.Content) == nil &&
+			len(xGtsRefValidator.ValidateInstance(dependent.Content, entity.Content, "")) == 0 {
+			continue
+		}
+		report.Invalid++
+		if len(report.InvalidIDs) < MaxReportedDependentFailures {
+			report.InvalidIDs = append(report.InvalidIDs, dependent.GtsID.ID)
+		}
+	}
+	return report
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const dependentsSchemaID = "gts.x.test.deps.user.v1~"
+
+func dependentsSchema(required ...any) map[string]any {
+	return map[string]any{
+		"$id":     "gts://" + dependentsSchemaID,
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string"},
+			"email": map[string]any{"type": "string"},
+		},
+		"required": required,
+	}
+}
+
+// newDependentsStore registers the user schema and three instances, only the first having an email
+func newDependentsStore(t *testing.T, mode DependentValidationMode) *GtsStore {
+	t.Helper()
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RevalidateDependentsOnSchemaChange: mode})
+	if err := store.Register(NewJsonEntity(dependentsSchema("name"), DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		instance := map[string]any{
+			"id":   fmt.Sprintf("%sx.test._.user%d.v1", dependentsSchemaID, i),
+			"name": fmt.Sprintf("user%d", i),
+		}
+		if i == 0 {
+			instance["email"] = "user0@example.com"
+		}
+		if err := store.Register(NewJsonEntity(instance, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register instance: %v", err)
+		}
+	}
+	return store
+}
+
+func TestRevalidateDependents_Report(t *testing.T) {
+	store := newDependentsStore(t, DependentValidationReport)
+
+	schema := NewJsonEntity(dependentsSchema("name", "email"), DefaultGtsConfig())
+	if err := store.Register(schema); err != nil {
+		t.Fatalf("Expected report mode to register the schema, got %v", err)
+	}
+	expected := &DependentsReport{
+		SchemaID:   dependentsSchemaID,
+		Checked:    3,
+		Invalid:    2,
+		InvalidIDs: []string{dependentsSchemaID + "x.test._.user1.v1", dependentsSchemaID + "x.test._.user2.v1"},
+	}
+	if !reflect.DeepEqual(schema.DependentReport, expected) {
+		t.Errorf("Expected report %+v, got %+v", expected, schema.DependentReport)
+	}
+	if !reflect.DeepEqual(store.Get(dependentsSchemaID).Content["required"], []any{"name", "email"}) {
+		t.Error("Expected the new schema to be registered")
+	}
+	if result := store.ValidateInstance(dependentsSchemaID + "x.test._.user1.v1"); result.OK {
+		t.Error("Expected the reported instance to fail validation against the new schema")
+	}
+}
+
+func TestRevalidateDependents_Reject(t *testing.T) {
+	store := newDependentsStore(t, DependentValidationReject)
+
+	err := store.Register(NewJsonEntity(dependentsSchema("name", "email"), DefaultGtsConfig()))
+	var depErr *DependentInstancesInvalidError
+	if !errors.As(err, &depErr) {
+		t.Fatalf("Expected DependentInstancesInvalidError, got %v", err)
+	}
+	if depErr.Report.Checked != 3 || depErr.Report.Invalid != 2 {
+		t.Errorf("Unexpected report: %+v", depErr.Report)
+	}
+	if !reflect.DeepEqual(store.Get(dependentsSchemaID).Content["required"], []any{"name"}) {
+		t.Error("Expected the previous schema to stay registered")
+	}
+
+	// A compatible change is accepted and reported as clean
+	relaxed := NewJsonEntity(dependentsSchema(), DefaultGtsConfig())
+	if err := store.Register(relaxed); err != nil {
+		t.Fatalf("Expected compatible schema change to be accepted, got %v", err)
+	}
+	if relaxed.DependentReport == nil || relaxed.DependentReport.Checked != 3 || relaxed.DependentReport.Invalid != 0 {
+		t.Errorf("Unexpected report: %+v", relaxed.DependentReport)
+	}
+}
+
+func TestRevalidateDependents_MinorlessSchemaID(t *testing.T) {
+	// The instance names its schema without minor version, which resolves to v1.1
+	const minorSchemaID = "gts.x.test.deps.account.v1.1~"
+	schema := func(required ...any) map[string]any {
+		content := dependentsSchema(required...)
+		content["$id"] = "gts://" + minorSchemaID
+		return content
+	}
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RevalidateDependentsOnSchemaChange: DependentValidationReject})
+	if err := store.Register(NewJsonEntity(schema("name"), DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	instanceID := "gts.x.test.deps.account.v1~x.test._.acc1.v1"
+	if err := store.Register(NewJsonEntity(map[string]any{"id": instanceID, "name": "acc1"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+	if result := store.ValidateInstance(instanceID); !result.OK || result.SchemaID != minorSchemaID {
+		t.Fatalf("Expected the instance to validate against %s, got %+v", minorSchemaID, result)
+	}
+
+	err := store.Register(NewJsonEntity(schema("name", "email"), DefaultGtsConfig()))
+	var depErr *DependentInstancesInvalidError
+	if !errors.As(err, &depErr) {
+		t.Fatalf("Expected DependentInstancesInvalidError, got %v", err)
+	}
+	if depErr.Report.Checked != 1 || !reflect.DeepEqual(depErr.Report.InvalidIDs, []string{instanceID}) {
+		t.Errorf("Unexpected report: %+v", depErr.Report)
+	}
+}
+
+func TestRevalidateDependents_Skipped(t *testing.T) {
+	// Unchanged content and new schemas are not re-validated
+	store := newDependentsStore(t, DependentValidationReject)
+	same := NewJsonEntity(dependentsSchema("name"), DefaultGtsConfig())
+	if err := store.Register(same); err != nil || same.DependentReport != nil {
+		t.Errorf("Expected no report for unchanged content, got %v, %+v", err, same.DependentReport)
+	}
+
+	// With the option off a breaking change goes through silently
+	store = newDependentsStore(t, DependentValidationOff)
+	breaking := NewJsonEntity(dependentsSchema("name", "email"), DefaultGtsConfig())
+	if err := store.Register(breaking); err != nil || breaking.DependentReport != nil {
+		t.Errorf("Expected no re-validation when off, got %v, %+v", err, breaking.DependentReport)
+	}
+}
+
+func TestParseDependentValidationMode(t *testing.T) {
+	for input, expected := range map[string]DependentValidationMode{
+		"": DependentValidationOff, "off": DependentValidationOff, "Report": DependentValidationReport, "reject": DependentValidationReject,
+	} {
+		if mode, err := ParseDependentValidationMode(input); err != nil || mode != expected {
+			t.Errorf("ParseDependentValidationMode(%q) = %v, %v", input, mode, err)
+		}
+	}
+	if _, err := ParseDependentValidationMode("always"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
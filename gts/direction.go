@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+// Directions of a compatibility check or a cast, see DirectionInfo
+const (
+	DirectionUp      = "up"
+	DirectionDown    = "down"
+	DirectionNone    = "none"
+	DirectionUnknown = "unknown"
+	// DirectionMajor is the direction between chains whose single differing segment changes its
+	// major version
+	DirectionMajor = "major"
+	// DirectionDivergent is the direction between chains whose versions differ in several segments
+	DirectionDivergent = "divergent"
+)
+
+// DirectionInfo details the direction between two GTS IDs, compared segment by segment along
+// their type chains; the instance segment of an instance ID is left out
+type DirectionInfo struct {
+	// Direction is one of the Direction constants
+	Direction string `json:"direction"`
+	// Segment is the index of the single segment whose version differs, -1 when no segment or
+	// several segments differ
+	Segment int `json:"segment"`
+	// DifferingSegments lists the indices of the segments whose version differs
+	DifferingSegments []int `json:"differing_segments,omitempty"`
+	// FromVersion and ToVersion are the versions of the single differing segment, e.g. "v1.0"
+	FromVersion string `json:"from_version,omitempty"`
+	ToVersion   string `json:"to_version,omitempty"`
+}
+
+// InferDirection infers the direction from one GTS ID to another. The chains must have the same
+// length and name the same types segment by segment, otherwise the direction is unknown. When the
+// version of exactly one segment differs, the direction is up or down with its minor version, or
+// major when its major version changes; when several differ it is divergent. Chains with no
+// differing segment are none, unless their last segment lacks a minor version, which is unknown
+// as with a single differing segment lacking one.
+// see gts-python schema_cast.py _infer_direction method
+func InferDirection(fromID, toID string) *DirectionInfo {
+	info := &DirectionInfo{Direction: DirectionUnknown, Segment: -1}
+	from := typeChain(fromID)
+	to := typeChain(toID)
+	if from == nil || to == nil || len(from) != len(to) {
+		return info
+	}
+	for i := range from {
+		f, t := from[i], to[i]
+		if f.Vendor != t.Vendor || f.Package != t.Package || f.Namespace != t.Namespace || f.Type != t.Type {
+			return info
+		}
+		if f.VerMajor != t.VerMajor || !sameMinor(f.VerMinor, t.VerMinor) {
+			info.DifferingSegments = append(info.DifferingSegments, i)
+		}
+	}
+
+	switch len(info.DifferingSegments) {
+	case 0:
+		last := from[len(from)-1]
+		if last.VerMinor != nil {
+			info.Direction = DirectionNone
+		}
+	case 1:
+		i := info.DifferingSegments[0]
+		f, t := from[i], to[i]
+		info.Segment = i
+		info.FromVersion, info.ToVersion = segmentVersion(f), segmentVersion(t)
+		switch {
+		case f.VerMajor != t.VerMajor:
+			info.Direction = DirectionMajor
+		case f.VerMinor == nil || t.VerMinor == nil:
+			// A segment without minor version names no particular minor version
+		case *t.VerMinor > *f.VerMinor:
+			info.Direction = DirectionUp
+		default:
+			info.Direction = DirectionDown
+		}
+	default:
+		info.Direction = DirectionDivergent
+	}
+	return info
+}
+
+// inferDirection returns the Direction of InferDirection
+func inferDirection(fromID, toID string) string {
+	return InferDirection(fromID, toID).Direction
+}
+
+// typeChain returns the type segments of a GTS ID, or nil when the ID is not valid
+func typeChain(id string) []*GtsIDSegment {
+	gtsID, err := NewGtsID(id)
+	if err != nil {
+		return nil
+	}
+	segments := gtsID.Segments
+	if !gtsID.IsType() && len(segments) > 1 {
+		segments = segments[:len(segments)-1]
+	}
+	return segments
+}
+
+// sameMinor reports whether two optional minor versions are equal
+func sameMinor(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,22 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+// Stable error codes set in the ErrorCode of QueryResult and AttributeResult, so that callers can
+// branch on the cause of a failure without parsing the Error message
+const (
+	// ErrorCodeQueryInvalid is set when a query expression cannot be parsed or its pattern is invalid
+	ErrorCodeQueryInvalid = "query_invalid"
+	// ErrorCodeEntityNotFound is set when the entity of an attribute selector is not registered
+	ErrorCodeEntityNotFound = "entity_not_found"
+	// ErrorCodeAttrPathInvalid is set when an attribute selector has no path, or a path segment
+	// does not fit the value it is applied to, e.g. an index into an object
+	ErrorCodeAttrPathInvalid = "attr_path_invalid"
+	// ErrorCodeAttrIndexOutOfRange is set when an array index of an attribute path is out of range
+	ErrorCodeAttrIndexOutOfRange = "attr_index_out_of_range"
+	// ErrorCodeAttrNotFound is set when a field of an attribute path does not exist
+	ErrorCodeAttrNotFound = "attr_not_found"
+)
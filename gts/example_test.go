@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts_test
+
+import (
+	"fmt"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+// The examples use the fixtures of the runnable examples under examples/
+const (
+	exampleFixtures = "../examples/fixtures"
+	orderPlacedV10  = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~"
+	orderPlacedV11  = "gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.1~"
+	exampleOrder    = orderPlacedV10 + "x.shop._.o1001.v1"
+)
+
+func loadExampleStore() *gts.GtsStore {
+	reader := gts.NewGtsFileReaderFromPath(exampleFixtures, nil)
+	return gts.NewGtsStoreWithConfig(reader, &gts.RegistryConfig{StableOrder: true})
+}
+
+func ExampleNewGtsFileReaderFromPath() {
+	store := gts.NewGtsStore(gts.NewGtsFileReaderFromPath(exampleFixtures, nil))
+	for _, id := range []string{"gts.x.shop.events.event.v1~", orderPlacedV10, exampleOrder} {
+		fmt.Printf("%s schema=%t\n", id, store.Get(id).IsSchema)
+	}
+	// Output:
+	// gts.x.shop.events.event.v1~ schema=true
+	// gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~ schema=true
+	// gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1001.v1 schema=false
+}
+
+func ExampleGtsStore_ValidateInstance() {
+	store := loadExampleStore()
+	fmt.Println(store.ValidateInstance(exampleOrder).OK)
+
+	// A negative total breaks the minimum of the order_placed schema
+	invalid := gts.NewJsonEntity(map[string]any{
+		"gtsId":      orderPlacedV10 + "x.shop._.refund.v1",
+		"type":       orderPlacedV10,
+		"tenant":     "acme",
+		"occurredAt": "2025-05-01T12:00:00Z",
+		"payload":    map[string]any{"orderId": "o-9", "total": -5},
+	}, gts.DefaultGtsConfig())
+	if err := store.Register(invalid); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(store.ValidateInstance(invalid.GtsID.ID).OK)
+	// Output:
+	// true
+	// false
+}
+
+func ExampleGtsStore_CheckCompatibility() {
+	store := loadExampleStore()
+	result := store.CheckCompatibility(orderPlacedV10, orderPlacedV11)
+	fmt.Println("backward:", result.IsBackwardCompatible)
+	fmt.Println("forward:", result.IsForwardCompatible)
+	// Output:
+	// backward: true
+	// forward: true
+}
+
+func ExampleGtsStore_Cast() {
+	store := loadExampleStore()
+	result, err := store.Cast(exampleOrder, orderPlacedV11)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	upcast := gts.NewJsonEntity(result.CastedEntity, gts.DefaultGtsConfig())
+	fmt.Println(result.AddedProperties)
+	fmt.Println(upcast.MustString("type"))
+	fmt.Println(upcast.MustString("payload.currency"))
+	// Output:
+	// [payload.currency]
+	// gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.1~
+	// EUR
+}
+
+func ExampleGtsStore_Query() {
+	store := loadExampleStore()
+	result := store.Query("gts.x.shop.events.*[tenant=globex]", 10)
+	for _, item := range result.Results {
+		fmt.Println(item["gtsId"])
+	}
+	// Output:
+	// gts.x.shop.events.event.v1~x.shop.orders.order_placed.v1.0~x.shop._.o1002.v1
+}
+
+func ExampleJsonEntity_GetInt() {
+	store := loadExampleStore()
+	order := store.Get(orderPlacedV10 + "x.shop._.o1002.v1")
+	total, ok := order.GetInt("payload.total")
+	fmt.Println(total, ok)
+	_, ok = store.Get(exampleOrder).GetInt("payload.total")
+	fmt.Println(ok)
+	// Output:
+	// 20 true
+	// false
+}
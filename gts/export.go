@@ -0,0 +1,259 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExportManifestFile is the name of the manifest written at the root of an exported tree.
+// The manifest has no GTS ID, so GtsFileReader skips it when loading the tree back.
+const ExportManifestFile = "gts-manifest.json"
+
+// exportOptions holds the optional settings of ExportTree and ExportDiff
+type exportOptions struct {
+	instances     bool
+	deleteRemoved bool
+}
+
+// ExportOption configures ExportTree and ExportDiff
+type ExportOption func(*exportOptions)
+
+// WithInstances controls whether instances are exported alongside schemas (default true)
+func WithInstances(include bool) ExportOption {
+	return func(o *exportOptions) {
+		o.instances = include
+	}
+}
+
+// ExportManifestEntry describes a single exported entity
+type ExportManifestEntry struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	IsSchema bool   `json:"is_schema"`
+	SHA256   string `json:"sha256"`
+	// Tags are the entity's store tags, which live outside the exported file (see RestoreManifestTags)
+	Tags map[string]string `json:"tags,omitempty"`
+	// RegisteredAt and UpdatedAt are the entity's store timestamps; GtsFileReader gives them back
+	// to the entities it loads from the tree, so reloading an export does not reset them
+	RegisteredAt *time.Time `json:"registered_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// ExportManifest lists every entity of an exported tree, sorted by ID
+type ExportManifest struct {
+	Pattern  string                `json:"pattern"`
+	Entities []ExportManifestEntry `json:"entities"`
+}
+
+// ExportReport summarizes an ExportTree run
+type ExportReport struct {
+	Dir       string                `json:"dir"`
+	Pattern   string                `json:"pattern"`
+	Count     int                   `json:"count"`
+	Schemas   int                   `json:"schemas"`
+	Instances int                   `json:"instances"`
+	Manifest  string                `json:"manifest"`
+	Entities  []ExportManifestEntry `json:"entities"`
+}
+
+// ExportTree writes every entity matching pattern (a Query expression; empty exports everything)
+// to dir, one canonical JSON file per entity, plus a manifest listing each exported ID and hash.
+//
+// Each segment of an ID maps to vendor/package/namespace/type/version path elements, so chained
+// types are nested under their base type's directory, e.g. gts.x.core.events.type.v1~ is written to
+// x/core/events/type/v1%7E.json and its derived types below x/core/events/type/v1%7E/.
+// Characters outside [a-z0-9_.-] ('~' in particular) are percent-encoded, which keeps type and
+// instance files with the same version apart. Files are written in canonical form (sorted keys,
+// two-space indentation), so exporting the same entities again produces byte-identical files.
+// The tree can be loaded back with GtsFileReader.
+func (s *GtsStore) ExportTree(pattern string, dir string, opts ...ExportOption) (*ExportReport, error) {
+	options := newExportOptions(opts)
+	files, err := s.exportFiles(pattern, options)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ExportReport{
+		Dir:      dir,
+		Pattern:  pattern,
+		Manifest: filepath.Join(dir, ExportManifestFile),
+		Entities: make([]ExportManifestEntry, 0, len(files)),
+	}
+	for _, file := range files {
+		if err := writeExportFile(dir, file); err != nil {
+			return nil, err
+		}
+		report.Entities = append(report.Entities, file.entry)
+		if file.entry.IsSchema {
+			report.Schemas++
+		} else {
+			report.Instances++
+		}
+	}
+	report.Count = len(report.Entities)
+
+	if err := writeExportManifest(report.Manifest, ExportManifest{Pattern: pattern, Entities: report.Entities}); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// exportFile is the encoded file of an exported entity with its manifest entry
+type exportFile struct {
+	entry ExportManifestEntry
+	data  []byte
+}
+
+// newExportOptions applies opts to the default export options
+func newExportOptions(opts []ExportOption) exportOptions {
+	options := exportOptions{instances: true, deleteRemoved: true}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// exportFiles encodes the entities matching pattern, sorted by ID
+func (s *GtsStore) exportFiles(pattern string, options exportOptions) ([]exportFile, error) {
+	expr := pattern
+	if strings.TrimSpace(expr) == "" {
+		expr = GtsPrefix + "*"
+	}
+
+	var entities []*JsonEntity
+	err := s.QueryStream(expr, func(item QueryItem) bool {
+		if entity := s.Get(item.ID); entity != nil && (options.instances || entity.IsSchema) {
+			entities = append(entities, entity)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].GtsID.ID < entities[j].GtsID.ID
+	})
+
+	files := make([]exportFile, 0, len(entities))
+	paths := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		rel := exportPath(entity.GtsID)
+		if other, ok := paths[rel]; ok {
+			return nil, fmt.Errorf("export path collision: %s and %s both map to %s", other, entity.GtsID.ID, rel)
+		}
+		paths[rel] = entity.GtsID.ID
+
+		data, err := CanonicalJSON(entity.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", entity.GtsID.ID, err)
+		}
+
+		entry := ExportManifestEntry{
+			ID:       entity.GtsID.ID,
+			Path:     rel,
+			IsSchema: entity.IsSchema,
+			SHA256:   contentDigest(data),
+			Tags:     s.GetTags(entity.GtsID.ID),
+		}
+		if !entity.RegisteredAt.IsZero() {
+			registeredAt, updatedAt := entity.RegisteredAt.UTC(), entity.UpdatedAt.UTC()
+			entry.RegisteredAt, entry.UpdatedAt = &registeredAt, &updatedAt
+		}
+		files = append(files, exportFile{entry: entry, data: data})
+	}
+	return files, nil
+}
+
+// writeExportFile writes the file of an exported entity below dir
+func writeExportFile(dir string, file exportFile) error {
+	target := filepath.Join(dir, filepath.FromSlash(file.entry.Path))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, file.data, 0o644)
+}
+
+// writeExportManifest writes manifest to path in canonical form
+func writeExportManifest(path string, manifest ExportManifest) error {
+	data, err := CanonicalJSON(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// CanonicalJSON encodes v with sorted object keys, two-space indentation, no HTML escaping
+// and a trailing newline, so equal values always produce identical bytes
+func CanonicalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ContentHash returns the hex SHA-256 of the canonical JSON of v (see CanonicalJSON), so that
+// equal contents hash identically whatever the order their keys were set in; it is the sha256
+// of the export manifest and the ContentHash of registered entities. It returns "" when v cannot
+// be encoded as JSON.
+func ContentHash(v any) string {
+	data, err := CanonicalJSON(v)
+	if err != nil {
+		return ""
+	}
+	return contentDigest(data)
+}
+
+// contentDigest returns the hex SHA-256 of encoded content
+func contentDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// exportPath returns the slash separated path of an entity file relative to the export root
+func exportPath(id *GtsID) string {
+	var parts []string
+	for _, seg := range id.Segments {
+		version := segmentVersion(seg)
+		if seg.IsType {
+			version += "~"
+		}
+		for _, token := range []string{seg.Vendor, seg.Package, seg.Namespace, seg.Type, version} {
+			parts = append(parts, escapePathElement(token))
+		}
+	}
+	return strings.Join(parts, "/") + ".json"
+}
+
+// escapePathElement percent-encodes every byte outside [a-z0-9_.-]
+func escapePathElement(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '.' || c == '-' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
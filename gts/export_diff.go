@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// WithDeleteRemoved controls whether ExportDiff deletes the files of entities that are no longer
+// exported (default true); without it they are only listed in DiffExportReport.Stale
+func WithDeleteRemoved(remove bool) ExportOption {
+	return func(o *exportOptions) {
+		o.deleteRemoved = remove
+	}
+}
+
+// DiffExportReport summarizes an ExportDiff run
+type DiffExportReport struct {
+	Dir      string `json:"dir"`
+	Pattern  string `json:"pattern"`
+	Manifest string `json:"manifest"`
+	// Added, Changed and Removed list the IDs that are new, whose file differs and that are no
+	// longer exported, relative to the previous manifest
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+	// Unchanged is the number of entities whose file was left as is
+	Unchanged int `json:"unchanged"`
+	// Written lists the files written, relative to Dir; the manifest is always rewritten
+	Written []string `json:"written"`
+	// Stale lists the files of the removed IDs, relative to Dir; Deleted is set when ExportDiff
+	// deleted them rather than leaving them to the caller
+	Stale   []string `json:"stale"`
+	Deleted bool     `json:"deleted"`
+	// Entities is the updated manifest
+	Entities []ExportManifestEntry `json:"entities"`
+}
+
+// ExportDiff updates a tree written by ExportTree to the current state of the store, touching only
+// what changed since prevManifest, the manifest of that tree: it writes the files of entities that
+// are new or whose SHA-256 differs, deletes the files of entities that are no longer exported, and
+// rewrites the manifest in dir. Entities are selected with the pattern recorded in prevManifest. A
+// nil prevManifest stands for an empty tree, exporting every entity.
+//
+// Tag and timestamp changes are recorded in the new manifest only, as they are not part of the
+// entity files.
+func (s *GtsStore) ExportDiff(prevManifest io.Reader, dir string, opts ...ExportOption) (*DiffExportReport, error) {
+	options := newExportOptions(opts)
+
+	var prev ExportManifest
+	if prevManifest != nil {
+		if err := json.NewDecoder(prevManifest).Decode(&prev); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode previous manifest: %w", err)
+		}
+	}
+	previous := make(map[string]ExportManifestEntry, len(prev.Entities))
+	for _, entry := range prev.Entities {
+		if !filepath.IsLocal(filepath.FromSlash(entry.Path)) {
+			return nil, fmt.Errorf("previous manifest entry %s has a path outside the export tree: %s", entry.ID, entry.Path)
+		}
+		previous[entry.ID] = entry
+	}
+
+	files, err := s.exportFiles(prev.Pattern, options)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DiffExportReport{
+		Dir:      dir,
+		Pattern:  prev.Pattern,
+		Manifest: filepath.Join(dir, ExportManifestFile),
+		Added:    []string{},
+		Changed:  []string{},
+		Removed:  []string{},
+		Written:  []string{},
+		Stale:    []string{},
+		Deleted:  options.deleteRemoved,
+		Entities: make([]ExportManifestEntry, 0, len(files)),
+	}
+	for _, file := range files {
+		id := file.entry.ID
+		report.Entities = append(report.Entities, file.entry)
+		old, existed := previous[id]
+		delete(previous, id)
+		switch {
+		case !existed:
+			report.Added = append(report.Added, id)
+		case old.SHA256 != file.entry.SHA256 || old.Path != file.entry.Path:
+			report.Changed = append(report.Changed, id)
+		default:
+			report.Unchanged++
+			continue
+		}
+		if err := writeExportFile(dir, file); err != nil {
+			return nil, err
+		}
+		report.Written = append(report.Written, file.entry.Path)
+	}
+
+	// What is left of the previous manifest is no longer exported
+	for _, entry := range previous {
+		report.Removed = append(report.Removed, entry.ID)
+		report.Stale = append(report.Stale, entry.Path)
+	}
+	sort.Strings(report.Removed)
+	sort.Strings(report.Stale)
+	if options.deleteRemoved {
+		for _, rel := range report.Stale {
+			if err := removeExportFile(dir, rel); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writeExportManifest(report.Manifest, ExportManifest{Pattern: prev.Pattern, Entities: report.Entities}); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// removeExportFile deletes the file rel below dir, then the directories it leaves empty
+func removeExportFile(dir, rel string) error {
+	target := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	root := filepath.Clean(dir)
+	for parent := filepath.Dir(target); parent != root && parent != "."; parent = filepath.Dir(parent) {
+		// Remove fails on directories that still hold files
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return nil
+}
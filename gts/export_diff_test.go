@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportDiff(t *testing.T) {
+	store := newExportFixtureStore(t)
+	dir := t.TempDir()
+	if _, err := store.ExportTree("", dir); err != nil {
+		t.Fatalf("ExportTree failed: %v", err)
+	}
+	prevManifest, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	// A file left unchanged by the diff keeps this marker
+	unchanged := filepath.Join(dir, "acme/billing/events/invoice/v1%7E.json")
+	if err := os.WriteFile(unchanged, []byte("marker"), 0o644); err != nil {
+		t.Fatalf("Failed to write marker: %v", err)
+	}
+
+	// Change two entities, remove one and add one
+	changes := []map[string]any{
+		{"id": "gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0", "amount": 13},
+		{"$id": "gts://gts.acme.billing.events.invoice.v1~acme.billing.events.refund.v1.1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "title": "Refund"},
+		{"id": "gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_2.v1.0", "amount": 3},
+	}
+	registerTestEntities(t, store, changes...)
+	if _, err := store.Prune(PrunePolicy{RemoveUnused: true, Protect: []string{"gts.acme.billing.*"}}, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	report, err := store.ExportDiff(bytes.NewReader(prevManifest), dir)
+	if err != nil {
+		t.Fatalf("ExportDiff failed: %v", err)
+	}
+	expected := &DiffExportReport{
+		Added:     []string{"gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_2.v1.0"},
+		Changed:   []string{"gts.acme.billing.events.invoice.v1~acme.billing.events.refund.v1.1~", "gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0"},
+		Removed:   []string{"gts.acme.crm.contacts.person.v1~"},
+		Unchanged: 2,
+		Written: []string{
+			"acme/billing/events/invoice/v1%7E/acme/billing/events/refund/v1.1%7E.json",
+			"acme/billing/events/invoice/v1%7E/acme/billing/invoices/inv_1/v1.0.json",
+			"acme/billing/events/invoice/v1%7E/acme/billing/invoices/inv_2/v1.0.json",
+		},
+		Stale:   []string{"acme/crm/contacts/person/v1%7E.json"},
+		Deleted: true,
+	}
+	got := *report
+	got.Dir, got.Manifest, got.Entities = "", "", nil
+	if !reflect.DeepEqual(&got, expected) {
+		t.Errorf("Expected report %+v, got %+v", expected, &got)
+	}
+
+	if data, _ := os.ReadFile(unchanged); string(data) != "marker" {
+		t.Error("Expected the unchanged schema file not to be rewritten")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "acme/crm")); !os.IsNotExist(err) {
+		t.Errorf("Expected the removed entity's file and empty directories to be deleted, got %v", err)
+	}
+	var written map[string]any
+	data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(expected.Written[1])))
+	if err := json.Unmarshal(data, &written); err != nil || written["amount"] != 13.0 {
+		t.Errorf("Expected the changed instance to be rewritten, got %s", data)
+	}
+
+	// The new manifest round-trips: it matches the report, and diffing against it changes nothing
+	newManifest, err := os.ReadFile(report.Manifest)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest ExportManifest
+	if err := json.Unmarshal(newManifest, &manifest); err != nil || !reflect.DeepEqual(manifest.Entities, report.Entities) || len(manifest.Entities) != 5 {
+		t.Fatalf("Expected the manifest to list the exported entities, got %v", err)
+	}
+	again, err := store.ExportDiff(bytes.NewReader(newManifest), dir)
+	if err != nil {
+		t.Fatalf("ExportDiff failed: %v", err)
+	}
+	if len(again.Added)+len(again.Changed)+len(again.Removed)+len(again.Written) != 0 || again.Unchanged != 5 {
+		t.Errorf("Expected nothing to change, got %+v", again)
+	}
+	if rewritten, _ := os.ReadFile(report.Manifest); !bytes.Equal(rewritten, newManifest) {
+		t.Error("Expected the manifest to be byte-identical")
+	}
+}
+
+func TestExportDiff_KeepRemovedFiles(t *testing.T) {
+	store := newExportFixtureStore(t)
+	dir := t.TempDir()
+	if _, err := store.ExportTree("gts.acme.crm.*", dir); err != nil {
+		t.Fatalf("ExportTree failed: %v", err)
+	}
+	prevManifest, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if _, err := store.Prune(PrunePolicy{RemoveUnused: true, Protect: []string{"gts.acme.billing.*"}}, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	report, err := store.ExportDiff(bytes.NewReader(prevManifest), dir, WithDeleteRemoved(false))
+	if err != nil {
+		t.Fatalf("ExportDiff failed: %v", err)
+	}
+	if report.Pattern != "gts.acme.crm.*" || report.Deleted || len(report.Added) != 0 || !reflect.DeepEqual(report.Stale, []string{"acme/crm/contacts/person/v1%7E.json"}) {
+		t.Errorf("Expected the removed entity to be listed only, got %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "acme/crm/contacts/person/v1%7E.json")); err != nil {
+		t.Errorf("Expected the stale file to be kept, got %v", err)
+	}
+}
+
+func TestExportDiff_Errors(t *testing.T) {
+	store := newExportFixtureStore(t)
+	if _, err := store.ExportDiff(strings.NewReader("{"), t.TempDir()); err == nil {
+		t.Error("Expected an error for an invalid manifest")
+	}
+	manifest := `{"entities": [{"id": "gts.x.a.b.c.v1~", "path": "../outside.json"}]}`
+	if _, err := store.ExportDiff(strings.NewReader(manifest), t.TempDir()); err == nil || !strings.Contains(err.Error(), "outside the export tree") {
+		t.Errorf("Expected an error for a path outside the tree, got %v", err)
+	}
+
+	// Without a previous manifest every entity is added
+	report, err := store.ExportDiff(nil, t.TempDir())
+	if err != nil || len(report.Added) != 5 || len(report.Written) != 5 {
+		t.Errorf("Expected every entity to be added, got %+v, %v", report, err)
+	}
+}
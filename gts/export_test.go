@@ -0,0 +1,214 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newExportFixtureStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	entities := []map[string]any{
+		{
+			"$id":     "gts://gts.acme.billing.events.invoice.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				"id":     map[string]any{"type": "string"},
+				"amount": map[string]any{"type": "number"},
+			},
+		},
+		{
+			"$id":     "gts://gts.acme.billing.events.invoice.v1~acme.billing.events.refund.v1.1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		},
+		{
+			"id":     "gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0",
+			"amount": 12.5,
+		},
+		{
+			"id":     "gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0~",
+			"amount": 7,
+		},
+		{
+			"$id":     "gts://gts.acme.crm.contacts.person.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		},
+	}
+	registerTestEntities(t, store, entities...)
+	return store
+}
+
+func fileHash(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestExportTree_RoundTrip(t *testing.T) {
+	store := newExportFixtureStore(t)
+	dir := t.TempDir()
+
+	report, err := store.ExportTree("gts.acme.billing.*", dir)
+	if err != nil {
+		t.Fatalf("ExportTree failed: %v", err)
+	}
+	if report.Count != 4 || report.Schemas != 2 || report.Instances != 2 {
+		t.Fatalf("Expected 4 entities (2 schemas, 2 instances), got %+v", report)
+	}
+
+	expectedPaths := map[string]string{
+		"gts.acme.billing.events.invoice.v1~":                                  "acme/billing/events/invoice/v1%7E.json",
+		"gts.acme.billing.events.invoice.v1~acme.billing.events.refund.v1.1~":  "acme/billing/events/invoice/v1%7E/acme/billing/events/refund/v1.1%7E.json",
+		"gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0":  "acme/billing/events/invoice/v1%7E/acme/billing/invoices/inv_1/v1.0.json",
+		"gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0~": "acme/billing/events/invoice/v1%7E/acme/billing/invoices/inv_1/v1.0%7E.json",
+	}
+	for _, entry := range report.Entities {
+		if want := expectedPaths[entry.ID]; entry.Path != want {
+			t.Errorf("Expected %s at %s, got %s", entry.ID, want, entry.Path)
+		}
+		if got := fileHash(t, filepath.Join(dir, filepath.FromSlash(entry.Path))); got != entry.SHA256 {
+			t.Errorf("Manifest hash mismatch for %s", entry.ID)
+		}
+	}
+
+	var manifest ExportManifest
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	if len(manifest.Entities) != report.Count {
+		t.Fatalf("Expected %d manifest entries, got %d", report.Count, len(manifest.Entities))
+	}
+
+	// Reload the tree into a fresh store with the unchanged file reader
+	reloaded := NewGtsStore(NewGtsFileReaderFromPath(dir, nil))
+	if reloaded.Count() != report.Count {
+		t.Fatalf("Expected %d reloaded entities, got %d", report.Count, reloaded.Count())
+	}
+	for _, entry := range manifest.Entities {
+		entity := reloaded.Get(entry.ID)
+		if entity == nil {
+			t.Errorf("Entity %s not reloaded", entry.ID)
+			continue
+		}
+		data, err := CanonicalJSON(entity.Content)
+		if err != nil {
+			t.Fatalf("CanonicalJSON failed: %v", err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			t.Errorf("Hash mismatch after reload for %s", entry.ID)
+		}
+	}
+
+	// Re-exporting the reloaded store produces byte-identical files
+	again := t.TempDir()
+	if _, err := reloaded.ExportTree("gts.acme.billing.*", again); err != nil {
+		t.Fatalf("Re-export failed: %v", err)
+	}
+	paths := []string{ExportManifestFile}
+	for _, entry := range manifest.Entities {
+		paths = append(paths, entry.Path)
+	}
+	for _, rel := range paths {
+		if fileHash(t, filepath.Join(dir, rel)) != fileHash(t, filepath.Join(again, rel)) {
+			t.Errorf("Re-exported %s differs", rel)
+		}
+	}
+}
+
+func TestExportTree_StrictReload(t *testing.T) {
+	store := newExportFixtureStore(t)
+	dir := t.TempDir()
+	if _, err := store.ExportTree("", dir); err != nil {
+		t.Fatalf("ExportTree failed: %v", err)
+	}
+
+	// The manifest is not an entity file: strict loading reports no error for it
+	reloaded, err := NewGtsStoreStrict(NewGtsFileReaderFromPath(dir, nil), nil)
+	if err != nil {
+		t.Fatalf("Expected the exported tree to load strictly, got %v", err)
+	}
+	if reloaded.Count() != store.Count() {
+		t.Errorf("Expected %d reloaded entities, got %d", store.Count(), reloaded.Count())
+	}
+
+	// Naming the manifest itself is not an error either
+	manifest := NewGtsFileReaderFromPath(filepath.Join(dir, ExportManifestFile), nil)
+	if entity := manifest.Next(); entity != nil || len(manifest.Errors()) != 0 {
+		t.Errorf("Expected the manifest to be skipped, got %+v %v", entity, manifest.Errors())
+	}
+}
+
+func TestExportTree_SchemasOnly(t *testing.T) {
+	store := newExportFixtureStore(t)
+
+	report, err := store.ExportTree("", t.TempDir(), WithInstances(false))
+	if err != nil {
+		t.Fatalf("ExportTree failed: %v", err)
+	}
+	if report.Count != 3 || report.Instances != 0 {
+		t.Errorf("Expected 3 schemas and no instances, got %+v", report)
+	}
+}
+
+func TestExportTree_InvalidPattern(t *testing.T) {
+	store := newExportFixtureStore(t)
+
+	if _, err := store.ExportTree("gts.acme.*.events", t.TempDir()); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestCanonicalJSON_KeyOrder(t *testing.T) {
+	a, _ := CanonicalJSON(map[string]any{"b": 1, "a": map[string]any{"y": "<", "x": 2}})
+	b, _ := CanonicalJSON(map[string]any{"a": map[string]any{"x": 2, "y": "<"}, "b": 1})
+	if string(a) != string(b) {
+		t.Errorf("Expected identical encodings, got %s and %s", a, b)
+	}
+	if want := "{\n  \"a\": {\n    \"x\": 2,\n    \"y\": \"<\"\n  },\n  \"b\": 1\n}\n"; string(a) != want {
+		t.Errorf("Unexpected canonical form: %q", a)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := ContentHash(map[string]any{"b": 1, "a": map[string]any{"y": "<", "x": 2}})
+	b := ContentHash(map[string]any{"a": map[string]any{"x": 2, "y": "<"}, "b": 1})
+	if a != b || len(a) != 64 {
+		t.Errorf("Expected identical SHA-256 hashes, got %s and %s", a, b)
+	}
+	if c := ContentHash(map[string]any{"a": map[string]any{"x": 3, "y": "<"}, "b": 1}); c == a {
+		t.Error("Expected different content to hash differently")
+	}
+
+	store := NewGtsStore(nil)
+	content := map[string]any{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "gts://gts.x.test.hash.item.v1~", "type": "object"}
+	if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if got := store.Get("gts.x.test.hash.item.v1~").ContentHash; got != ContentHash(content) {
+		t.Errorf("Expected the stored entity to carry its content hash, got %q", got)
+	}
+	if list := store.List(10); len(list.Entities) != 1 || list.Entities[0].ContentHash != ContentHash(content) {
+		t.Errorf("Expected the content hash in the list, got %+v", list.Entities)
+	}
+}
@@ -8,6 +8,7 @@ package gts
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // JsonFile represents a JSON file containing one or more entities
@@ -28,7 +29,23 @@ type JsonEntity struct {
 	File                  *JsonFile
 	ListSequence          *int
 	Label                 string
-	GtsRefs               []*GtsReference // All GTS ID references found in content
+	GtsRefs               []*GtsReference   // All GTS ID references found in content
+	UnresolvedRefs        []string          // Referenced IDs missing from the store (warn-mode reference validation)
+	IDError               error             // Why the selected "gts." entity ID could not be parsed, if it could not
+	DependentReport       *DependentsReport // Instances re-validated when this schema overwrote another (see RegistryConfig.RevalidateDependentsOnSchemaChange)
+	// ConflictingSchemaIDs lists the schema-ID fields of an instance, in precedence order, when they name different schemas
+	ConflictingSchemaIDs []SchemaIDCandidate
+	SchemaIDAdjustment   string // Why a newer minor version was preferred over the precedence order, if it was
+	// schemaSources are the schema sources of an instance in resolution order (see resolveInstanceSchema)
+	schemaSources []SchemaIDCandidate
+	// PositionIndex locates the values of Content in File; set when the entity was parsed with
+	// GtsConfig.RecordPositions
+	PositionIndex PositionIndex
+	// RegisteredAt and UpdatedAt are set by the store: when the ID was first registered and when it was last written
+	RegisteredAt time.Time
+	UpdatedAt    time.Time
+	// ContentHash is set by the store to the ContentHash of Content when the entity is stored
+	ContentHash string
 }
 
 // ExtractIDResult holds the result of extracting ID information from JSON content
@@ -38,6 +55,9 @@ type ExtractIDResult struct {
 	SelectedEntityField   *string `json:"selected_entity_field"`
 	SelectedSchemaIDField *string `json:"selected_schema_id_field"`
 	IsSchema              bool    `json:"is_schema"`
+	// ConflictingSchemaIDs and SchemaIDAdjustment report disagreeing schema-ID fields, see JsonEntity
+	ConflictingSchemaIDs []SchemaIDCandidate `json:"conflicting_schema_ids,omitempty"`
+	SchemaIDAdjustment   string              `json:"schema_id_adjustment,omitempty"`
 }
 
 // NewJsonEntity creates a JsonEntity from JSON content using the provided config
@@ -88,6 +108,15 @@ func NewJsonEntityWithFile(content map[string]any, cfg *GtsConfig, file *JsonFil
 		}
 	}
 
+	if !entity.IsSchema {
+		entity.schemaSources = entity.calcSchemaSources(cfg, entityIDValue)
+	}
+
+	// Keep the parse error of a GTS-looking ID that was rejected, e.g. for exceeding the ID limits
+	if entity.GtsID == nil && strings.HasPrefix(entityIDValue, GtsPrefix) {
+		_, entity.IDError = NewGtsID(entityIDValue)
+	}
+
 	// Extract GTS references from content
 	entity.GtsRefs = extractGtsReferences(content)
 
@@ -134,12 +163,8 @@ func (e *JsonEntity) getFieldValue(field string) string {
 		return ""
 	}
 
-	val, ok := e.Content[field]
-	if !ok {
-		return ""
-	}
-
-	strVal, ok := val.(string)
+	// Field names are looked up literally, not as attribute paths
+	strVal, ok := e.Content[field].(string)
 	if !ok {
 		return ""
 	}
@@ -189,16 +214,11 @@ func (e *JsonEntity) calcJSONEntityID(cfg *GtsConfig) string {
 // calcJSONSchemaID extracts the schema ID from JSON content
 func (e *JsonEntity) calcJSONSchemaID(cfg *GtsConfig, entityIDValue string) string {
 	if e.IsSchema {
-		// For derived schemas, derive parent type from chain
-		if entityIDValue != "" && IsValidGtsID(entityIDValue) && strings.HasSuffix(entityIDValue, "~") {
-			firstTilde := strings.Index(entityIDValue, "~")
-			if firstTilde > 0 {
-				secondTilde := strings.Index(entityIDValue[firstTilde+1:], "~")
-				if secondTilde > 0 {
-					// This is a derived schema, derive parent from chain
-					e.SelectedSchemaIDField = e.SelectedEntityField
-					return entityIDValue[:firstTilde+1]
-				}
+		// For derived schemas, derive the base type from the chain
+		if gtsID, err := NewGtsID(entityIDValue); err == nil && gtsID.IsType() && len(gtsID.Segments) > 1 {
+			if base, err := gtsID.BaseType(); err == nil {
+				e.SelectedSchemaIDField = e.SelectedEntityField
+				return base.ID
 			}
 		}
 
@@ -211,23 +231,24 @@ func (e *JsonEntity) calcJSONSchemaID(cfg *GtsConfig, entityIDValue string) stri
 	}
 
 	// For instances: try entity ID chain first, then SchemaIDFields
-	if entityIDValue != "" && IsValidGtsID(entityIDValue) {
-		// For instances, find last ~ and return everything up to and including it
-		// But skip if entity ID ends with ~ (that would be a type, not an instance)
-		if !strings.HasSuffix(entityIDValue, "~") {
-			lastTilde := strings.LastIndex(entityIDValue, "~")
-			if lastTilde > 0 {
-				e.SelectedSchemaIDField = e.SelectedEntityField
-				return entityIDValue[:lastTilde+1]
-			}
+	// The type of a chained instance is its ID without the last segment; an ID ending with ~ is a
+	// type, not an instance
+	if gtsID, err := NewGtsID(entityIDValue); err == nil && !gtsID.IsType() {
+		if parent, err := gtsID.Parent(); err == nil {
+			e.SelectedSchemaIDField = e.SelectedEntityField
+			chainID := SchemaIDCandidate{Field: e.SelectedEntityField, Value: parent.ID}
+			return e.resolveSchemaIDConflict(cfg, chainID, true)
 		}
 	}
 
-	// If no entity ID found, use SchemaIDFields to find schema reference
-	field, value := e.firstNonEmptyField(cfg.SchemaIDFields)
+	// If no entity ID found, use SchemaIDFields in precedence order to find schema reference
+	field, value := e.firstNonEmptyField(cfg.schemaIDFieldOrder())
 	if value != "" {
 		e.SelectedSchemaIDField = field
-		return value
+		if !IsValidGtsID(value) {
+			return value
+		}
+		return e.resolveSchemaIDConflict(cfg, SchemaIDCandidate{Field: field, Value: value}, false)
 	}
 
 	return ""
@@ -238,7 +259,9 @@ func ExtractID(content map[string]any, cfg *GtsConfig) *ExtractIDResult {
 	entity := NewJsonEntity(content, cfg)
 
 	result := &ExtractIDResult{
-		IsSchema: entity.IsSchema,
+		IsSchema:             entity.IsSchema,
+		ConflictingSchemaIDs: entity.ConflictingSchemaIDs,
+		SchemaIDAdjustment:   entity.SchemaIDAdjustment,
 	}
 
 	// Set SchemaID as pointer (nil if empty)
@@ -265,10 +288,8 @@ func ExtractID(content map[string]any, cfg *GtsConfig) *ExtractIDResult {
 	} else {
 		// For anonymous instances: return instance_id (UUID or non-GTS value from id field)
 		if entity.SelectedEntityField != "" {
-			if val, ok := content[entity.SelectedEntityField]; ok {
-				if strVal, ok := val.(string); ok {
-					result.ID = strVal
-				}
+			if strVal, ok := content[entity.SelectedEntityField].(string); ok {
+				result.ID = strVal
 			}
 		}
 	}
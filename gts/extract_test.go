@@ -6,6 +6,8 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -357,3 +359,128 @@ func TestExtractID_GtsURIPrefix_WithoutPrefix(t *testing.T) {
 		t.Errorf("Expected ID %q, got %q", "gts.vendor.package.namespace.type.v1.0~", result.ID)
 	}
 }
+
+// TestNewJsonEntity_ReferenceKinds checks that extracted references carry their keyword and expected kind
+func TestNewJsonEntity_ReferenceKinds(t *testing.T) {
+	entity := NewJsonEntity(map[string]any{
+		"gtsId":   "gts.vendor.package.namespace.type.v0~a.b.c.d.v1",
+		"$schema": "gts.vendor.package.namespace.type.v0~",
+		"owner":   "gts.vendor.package.namespace.user.v0~a.b.c.alice.v1",
+	}, DefaultGtsConfig())
+
+	found := 0
+	for _, ref := range entity.GtsRefs {
+		switch ref.SourcePath {
+		case "$schema":
+			found++
+			if ref.Keyword != "$schema" || ref.ExpectedKind != ReferenceKindSchema {
+				t.Errorf("Unexpected $schema reference: %+v", ref)
+			}
+		case "owner":
+			found++
+			if ref.Keyword != "owner" || ref.ExpectedKind != ReferenceKindAny {
+				t.Errorf("Unexpected owner reference: %+v", ref)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("Expected $schema and owner references, got %d", found)
+	}
+}
+
+// TestExtractID_SchemaIDConflicts checks the resolution of schema-ID fields naming different schemas
+func TestExtractID_SchemaIDConflicts(t *testing.T) {
+	t.Run("same major, different minor prefers the newer", func(t *testing.T) {
+		content := map[string]any{
+			"id":      "7a1d2f34-5c6d-4e7f-8a9b-0c1d2e3f4a5b",
+			"gtsType": "gts.x.core.events.order.v1.0~",
+			"type":    "gts.x.core.events.order.v1.2~",
+		}
+		entity := NewJsonEntity(content, nil)
+		if entity.SchemaID != "gts.x.core.events.order.v1.2~" || entity.SelectedSchemaIDField != "type" {
+			t.Errorf("expected the newer minor version from type, got %s from %s", entity.SchemaID, entity.SelectedSchemaIDField)
+		}
+		if len(entity.ConflictingSchemaIDs) != 2 || entity.SchemaIDAdjustment == "" {
+			t.Errorf("expected the conflict and the adjustment to be recorded, got %v %q", entity.ConflictingSchemaIDs, entity.SchemaIDAdjustment)
+		}
+		if err := entity.schemaIDConflictError(); err != nil {
+			t.Errorf("expected a minor-version conflict to be resolved, got %v", err)
+		}
+
+		result := ExtractID(content, nil)
+		if len(result.ConflictingSchemaIDs) != 2 || result.SchemaIDAdjustment == "" {
+			t.Errorf("expected ExtractID to report the conflict, got %+v", result)
+		}
+	})
+
+	t.Run("different types follow the precedence", func(t *testing.T) {
+		content := map[string]any{
+			"id":      "7a1d2f34-5c6d-4e7f-8a9b-0c1d2e3f4a5b",
+			"gtsType": "gts.x.core.events.order.v1~",
+			"type":    "gts.x.core.events.refund.v1~",
+		}
+		entity := NewJsonEntity(content, nil)
+		if entity.SchemaID != "gts.x.core.events.order.v1~" || entity.SchemaIDAdjustment != "" {
+			t.Errorf("expected the default precedence to pick gtsType, got %s (%q)", entity.SchemaID, entity.SchemaIDAdjustment)
+		}
+
+		cfg := DefaultGtsConfig()
+		cfg.SchemaIDFieldPrecedence = []string{"type"}
+		entity = NewJsonEntity(content, cfg)
+		if entity.SchemaID != "gts.x.core.events.refund.v1~" || entity.SelectedSchemaIDField != "type" {
+			t.Errorf("expected the configured precedence to pick type, got %s from %s", entity.SchemaID, entity.SelectedSchemaIDField)
+		}
+		err := entity.schemaIDConflictError()
+		if err == nil || !strings.Contains(err.Error(), "gtsType is 'gts.x.core.events.order.v1~'") {
+			t.Errorf("expected an error naming both fields, got %v", err)
+		}
+	})
+
+	t.Run("single field and consistent chain are unaffected", func(t *testing.T) {
+		for _, content := range []map[string]any{
+			{"id": "7a1d2f34-5c6d-4e7f-8a9b-0c1d2e3f4a5b", "type": "gts.x.core.events.order.v1~"},
+			{"id": "gts.x.core.events.order.v1~x.shop._.o1.v1", "type": "gts.x.core.events.order.v1~", "kind": "click"},
+			{"id": "gts.x.core.events.order.v1~x.shop.orders.express.v1~x.shop._.o2.v1", "type": "gts.x.core.events.order.v1~"},
+			{"id": "gts.x.core.events.order.v1~x.shop._.o3.v1", "type": "click"},
+		} {
+			entity := NewJsonEntity(content, nil)
+			if entity.ConflictingSchemaIDs != nil || entity.SchemaIDAdjustment != "" {
+				t.Errorf("expected no conflict for %v, got %v", content, entity.ConflictingSchemaIDs)
+			}
+		}
+	})
+}
+
+// TestRegister_RejectSchemaIDConflicts checks that conflicting schema-ID fields fail registration
+// only when configured, and that minor-version conflicts are accepted
+func TestRegister_RejectSchemaIDConflicts(t *testing.T) {
+	conflicting := map[string]any{
+		"id":   "gts.x.core.events.order.v1~x.shop._.o1.v1",
+		"type": "gts.x.core.events.refund.v1~",
+	}
+	minorOnly := map[string]any{
+		"id":   "gts.x.core.events.order.v1.0~x.shop._.o2.v1",
+		"type": "gts.x.core.events.order.v1.1~",
+	}
+
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RejectSchemaIDConflicts: true})
+	var conflictErr *SchemaIDConflictError
+	if err := store.Register(NewJsonEntity(conflicting, nil)); !errors.As(err, &conflictErr) {
+		t.Fatalf("expected a SchemaIDConflictError, got %v", err)
+	}
+	if conflictErr.Selected.Field != "id" || len(conflictErr.Conflicting) != 1 || conflictErr.Conflicting[0].Field != "type" {
+		t.Errorf("unexpected conflict details: %+v", conflictErr)
+	}
+	if err := store.Register(NewJsonEntity(minorOnly, nil)); err != nil {
+		t.Errorf("expected a minor-version conflict to be accepted, got %v", err)
+	}
+
+	lenient := NewGtsStore(nil)
+	if err := lenient.Register(NewJsonEntity(conflicting, nil)); err != nil {
+		t.Fatalf("expected the conflict to be accepted by default, got %v", err)
+	}
+	result := lenient.ValidateInstance("gts.x.core.events.order.v1~x.shop._.o1.v1")
+	if len(result.ConflictingSchemaIDs) != 2 {
+		t.Errorf("expected the validation result to report the conflict, got %+v", result)
+	}
+}
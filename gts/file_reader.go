@@ -7,6 +7,8 @@ package gts
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"slices"
@@ -14,7 +16,8 @@ import (
 )
 
 var (
-	// ExcludeList contains directory names to exclude during file scanning
+	// ExcludeList contains directory names to exclude during file scanning, unless
+	// GtsConfig.ExcludeDirs replaces it
 	ExcludeList = []string{"node_modules", "dist", "build"}
 )
 
@@ -27,6 +30,12 @@ type GtsFileReader struct {
 	currentFileEntities []*JsonEntity
 	currentEntityIndex  int
 	initialized         bool
+	// manifestEntries are the entries of the export manifests found at the root of directory paths, by ID
+	manifestEntries map[string]ExportManifestEntry
+	// errors are the skipped and flagged content read since the last reset, and seen the entities
+	// read so far by ID, to flag IDs read from several files
+	errors []ReaderError
+	seen   map[string][]*JsonEntity
 }
 
 // NewGtsFileReader creates a new file reader with the given paths
@@ -60,14 +69,9 @@ func NewGtsFileReaderFromPath(path string, cfg *GtsConfig) *GtsFileReader {
 
 // collectFiles collects all JSON files from the specified paths
 func (r *GtsFileReader) collectFiles() {
-	validExtensions := map[string]bool{
-		".json":  true,
-		".jsonc": true,
-		".gts":   true,
-	}
-
 	seen := make(map[string]bool)
 	var collected []string
+	r.manifestEntries = make(map[string]ExportManifestEntry)
 
 	for _, path := range r.paths {
 		// Resolve path
@@ -82,6 +86,8 @@ func (r *GtsFileReader) collectFiles() {
 		}
 
 		if info.IsDir() {
+			r.readManifest(absPath)
+
 			// Walk directory recursively
 			err := filepath.Walk(absPath, func(filePath string, info os.FileInfo, err error) error {
 				if err != nil {
@@ -90,15 +96,14 @@ func (r *GtsFileReader) collectFiles() {
 
 				// Skip excluded directories
 				if info.IsDir() {
-					if slices.Contains(ExcludeList, info.Name()) {
+					if slices.Contains(r.cfg.excludeDirs(), info.Name()) {
 						return filepath.SkipDir
 					}
 					return nil
 				}
 
-				// Check if file has valid extension
-				ext := strings.ToLower(filepath.Ext(filePath))
-				if validExtensions[ext] {
+				// Check if file has valid extension; export manifests hold no entities
+				if IsEntityFile(filePath) && info.Name() != ExportManifestFile {
 					realPath, err := filepath.EvalSymlinks(filePath)
 					if err != nil {
 						realPath = filePath
@@ -117,8 +122,7 @@ func (r *GtsFileReader) collectFiles() {
 			}
 		} else {
 			// Single file
-			ext := strings.ToLower(filepath.Ext(absPath))
-			if validExtensions[ext] {
+			if IsEntityFile(absPath) && info.Name() != ExportManifestFile {
 				realPath, err := filepath.EvalSymlinks(absPath)
 				if err != nil {
 					realPath = absPath
@@ -135,63 +139,106 @@ func (r *GtsFileReader) collectFiles() {
 	r.files = collected
 }
 
-// loadJSONFile loads JSON content from a file
-func (r *GtsFileReader) loadJSONFile(filePath string) (any, error) {
-	data, err := os.ReadFile(filePath)
+// readManifest records the entries of the export manifest at the root of dir, if there is one
+func (r *GtsFileReader) readManifest(dir string) {
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
 	if err != nil {
-		return nil, err
+		return
 	}
-
-	var content any
-	if err := json.Unmarshal(data, &content); err != nil {
-		return nil, err
+	var manifest ExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		log.Printf("Ignoring invalid export manifest in %s: %v", dir, err)
+		return
+	}
+	for _, entry := range manifest.Entities {
+		r.manifestEntries[entry.ID] = entry
 	}
+}
 
-	return content, nil
+// applyManifest gives an entity the store timestamps recorded for it in an export manifest
+func (r *GtsFileReader) applyManifest(entity *JsonEntity) *JsonEntity {
+	if entity.GtsID == nil {
+		return entity
+	}
+	if entry, ok := r.manifestEntries[entity.GtsID.ID]; ok {
+		if entry.RegisteredAt != nil {
+			entity.RegisteredAt = *entry.RegisteredAt
+		}
+		if entry.UpdatedAt != nil {
+			entity.UpdatedAt = *entry.UpdatedAt
+		}
+	}
+	return entity
 }
 
-// processFile processes a single JSON file and returns list of JsonEntity objects
+// processFile processes a single JSON file and returns list of JsonEntity objects, recording
+// the files and objects it skips and the IDs already read from other files
 func (r *GtsFileReader) processFile(filePath string) []*JsonEntity {
-	var entities []*JsonEntity
-
-	content, err := r.loadJSONFile(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return entities
+		r.errors = append(r.errors, ReaderError{Path: filePath, Category: ReaderErrorInvalidJSON, Message: err.Error()})
+		return nil
 	}
 
-	jsonFile := &JsonFile{
-		Path:    filePath,
-		Name:    filepath.Base(filePath),
-		Content: content,
+	objects, _, err := parseEntityDocument(&JsonFile{Path: filePath, Name: filepath.Base(filePath)}, data, r.cfg, true)
+	if err != nil {
+		r.errors = append(r.errors, ReaderError{Path: filePath, Category: ReaderErrorInvalidJSON, Message: err.Error()})
+		return nil
 	}
 
-	// Handle both single objects and arrays
-	switch v := content.(type) {
-	case []any:
-		// Array of items
-		for idx, item := range v {
-			if itemMap, ok := item.(map[string]any); ok {
-				entity := NewJsonEntityWithFile(itemMap, r.cfg, jsonFile, &idx)
-				if entity.GtsID != nil {
-					entities = append(entities, entity)
-				}
+	var entities []*JsonEntity
+	for _, entity := range objects {
+		if entity.GtsID == nil {
+			r.errors = append(r.errors, skippedObjectError(filePath, entity))
+			continue
+		}
+		id := entity.GtsID.ID
+		previous := r.seen[id]
+		if len(previous) > 0 && previous[len(previous)-1].File.Path != filePath {
+			sources := make([]string, 0, len(previous)+1)
+			for _, p := range previous {
+				sources = append(sources, p.Label)
 			}
+			sources = append(sources, entity.Label)
+			r.errors = append(r.errors, ReaderError{
+				Path:     filePath,
+				Index:    entity.ListSequence,
+				Category: ReaderErrorDuplicateID,
+				ID:       id,
+				Sources:  sources,
+				Message:  fmt.Sprintf("%s was already read from %s", id, previous[len(previous)-1].Label),
+			})
 		}
-	case map[string]any:
-		// Single object
-		entity := NewJsonEntityWithFile(v, r.cfg, jsonFile, nil)
-		if entity.GtsID != nil {
-			entities = append(entities, entity)
+		r.seen[id] = append(previous, entity)
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// skippedObjectError returns the ReaderError of an object read without a GTS entity ID
+func skippedObjectError(filePath string, entity *JsonEntity) ReaderError {
+	e := ReaderError{Path: filePath, Index: entity.ListSequence, Category: ReaderErrorNoGtsID, Message: "object has no GTS entity ID"}
+	if entity.IDError != nil {
+		e.Category, e.Message = ReaderErrorInvalidGtsID, entity.IDError.Error()
+		if id, ok := entity.Content[entity.SelectedEntityField].(string); ok {
+			e.ID = id
 		}
 	}
+	return e
+}
 
-	return entities
+// Errors returns the files and objects skipped since the reader was created or last reset, and
+// the IDs read again from another file, in read order
+func (r *GtsFileReader) Errors() []ReaderError {
+	return append([]ReaderError(nil), r.errors...)
 }
 
 // Next returns the next JsonEntity or nil when exhausted
 func (r *GtsFileReader) Next() *JsonEntity {
 	if !r.initialized {
 		r.collectFiles()
+		r.errors = nil
+		r.seen = make(map[string][]*JsonEntity)
 		r.initialized = true
 	}
 
@@ -199,7 +246,7 @@ func (r *GtsFileReader) Next() *JsonEntity {
 	if r.currentEntityIndex < len(r.currentFileEntities) {
 		entity := r.currentFileEntities[r.currentEntityIndex]
 		r.currentEntityIndex++
-		return entity
+		return r.applyManifest(entity)
 	}
 
 	// Move to next file
@@ -211,7 +258,7 @@ func (r *GtsFileReader) Next() *JsonEntity {
 		if len(r.currentFileEntities) > 0 {
 			entity := r.currentFileEntities[r.currentEntityIndex]
 			r.currentEntityIndex++
-			return entity
+			return r.applyManifest(entity)
 		}
 	}
 
@@ -230,4 +277,5 @@ func (r *GtsFileReader) Reset() {
 	r.currentFileEntities = nil
 	r.currentEntityIndex = 0
 	r.initialized = false
+	r.errors = nil
 }
@@ -7,6 +7,7 @@ package gts
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -383,3 +384,81 @@ func TestGtsFileReader_ReadByID(t *testing.T) {
 		t.Error("ReadByID should return nil for file reader")
 	}
 }
+
+// TestGtsFileReader_Errors tests that skipped files and objects and repeated IDs are reported
+func TestGtsFileReader_Errors(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(first, "broken.json"): `{"id": `,
+		filepath.Join(first, "items.json"): `[
+			{"id": "gts.x.core.items.item.v1~x.core._.a.v1"},
+			{"name": "no id"},
+			{"id": "gts.X.core.items.item.v1~x.core._.b.v1"}
+		]`,
+		filepath.Join(second, "a.json"): `{"id": "gts.x.core.items.item.v1~x.core._.a.v1"}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	reader := NewGtsFileReader([]string{first, second}, nil)
+	count := 0
+	for entity := reader.Next(); entity != nil; entity = reader.Next() {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected the entity and its duplicate, got %d entities", count)
+	}
+
+	byCategory := map[string]ReaderError{}
+	for _, e := range reader.Errors() {
+		byCategory[e.Category] = e
+	}
+	if len(reader.Errors()) != 4 {
+		t.Fatalf("Expected 4 errors, got %v", reader.Errors())
+	}
+	if e := byCategory[ReaderErrorInvalidJSON]; e.Path != filepath.Join(first, "broken.json") || e.Index != nil {
+		t.Errorf("Unexpected invalid_json error: %+v", e)
+	}
+	if e := byCategory[ReaderErrorNoGtsID]; e.Path != filepath.Join(first, "items.json") || e.Index == nil || *e.Index != 1 {
+		t.Errorf("Unexpected no_gts_id error: %+v", e)
+	}
+	if e := byCategory[ReaderErrorInvalidGtsID]; e.ID != "gts.X.core.items.item.v1~x.core._.b.v1" || e.Index == nil || *e.Index != 2 {
+		t.Errorf("Unexpected invalid_gts_id error: %+v", e)
+	}
+	duplicate := byCategory[ReaderErrorDuplicateID]
+	if duplicate.ID != "gts.x.core.items.item.v1~x.core._.a.v1" || duplicate.Path != filepath.Join(second, "a.json") ||
+		len(duplicate.Sources) != 2 || duplicate.Sources[0] != "items.json#0" || duplicate.Sources[1] != "a.json" {
+		t.Errorf("Unexpected duplicate_id error: %+v", duplicate)
+	}
+
+	// Reading again reports the same errors once
+	reader.Reset()
+	if len(reader.Errors()) != 0 {
+		t.Errorf("Expected Reset to clear the errors, got %v", reader.Errors())
+	}
+	for entity := reader.Next(); entity != nil; entity = reader.Next() {
+	}
+	if len(reader.Errors()) != 4 {
+		t.Errorf("Expected 4 errors after reading again, got %d", len(reader.Errors()))
+	}
+
+	store := NewGtsStore(NewGtsFileReader([]string{first, second}, nil))
+	if len(store.LoadErrors()) != 4 || store.Get("gts.x.core.items.item.v1~x.core._.a.v1").File.Path != filepath.Join(second, "a.json") {
+		t.Errorf("Expected the store to keep the load errors and the last duplicate, got %v", store.LoadErrors())
+	}
+	merged := NewGtsStoreFromReaders([]GtsReader{NewGtsFileReaderFromPath(first, nil), NewGtsFileReaderFromPath(second, nil)}, nil, 2)
+	if errs := merged.LoadErrors(); len(errs) != 3 || errs[0].Category != ReaderErrorInvalidJSON {
+		t.Errorf("Expected the errors of each reader in reader order, got %v", errs)
+	}
+
+	var loadErr *StoreLoadError
+	if _, err := NewGtsStoreStrict(NewGtsFileReaderFromPath(first, nil), nil); !errors.As(err, &loadErr) || len(loadErr.Errors) != 3 {
+		t.Errorf("Expected a StoreLoadError of 3 errors, got %v", err)
+	}
+	if store, err := NewGtsStoreStrict(NewGtsFileReaderFromPath(second, nil), nil); err != nil || store.Count() != 1 {
+		t.Errorf("Expected a clean directory to load, got %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 )
@@ -21,10 +22,93 @@ const (
 	// (e.g., "gts://gts.x.y.z..."). This is ONLY used for JSON Schema serialization/deserialization,
 	// not for GTS ID parsing.
 	GtsURIPrefix = "gts://"
-	// MaxIDLength is the maximum allowed length for a GTS identifier
+	// MaxIDLength is the default maximum allowed length for a GTS identifier
 	MaxIDLength = 1024
+	// MaxSegments is the default maximum number of segments in a GTS identifier; zero means unlimited
+	MaxSegments = 0
 )
 
+// ID limit names reported by IDLimitError
+const (
+	IDLimitLength   = "max_id_length"
+	IDLimitSegments = "max_segments"
+)
+
+// Process-wide ID limits applied by NewGtsID, ParseID and pattern validation; see SetLimits
+var (
+	limitMaxIDLength atomic.Int64
+	limitMaxSegments atomic.Int64
+)
+
+func init() {
+	limitMaxIDLength.Store(MaxIDLength)
+	limitMaxSegments.Store(MaxSegments)
+}
+
+// SetLimits sets the process-wide maximum ID length and segment count.
+// A zero maxIDLength restores the MaxIDLength default; a zero maxSegments means unlimited.
+func SetLimits(maxIDLength, maxSegments int) {
+	if maxIDLength <= 0 {
+		maxIDLength = MaxIDLength
+	}
+	if maxSegments < 0 {
+		maxSegments = 0
+	}
+	limitMaxIDLength.Store(int64(maxIDLength))
+	limitMaxSegments.Store(int64(maxSegments))
+
+	// Cached pattern parse results depend on the limits
+	sharedPatternCache.reset()
+}
+
+// Limits returns the process-wide maximum ID length and segment count (zero segments means unlimited)
+func Limits() (maxIDLength, maxSegments int) {
+	return int(limitMaxIDLength.Load()), int(limitMaxSegments.Load())
+}
+
+// idLimits is a resolved pair of ID limits
+type idLimits struct {
+	maxLength   int
+	maxSegments int
+}
+
+// currentLimits returns the process-wide limits
+func currentLimits() idLimits {
+	maxLength, maxSegments := Limits()
+	return idLimits{maxLength: maxLength, maxSegments: maxSegments}
+}
+
+// configLimits returns the limits of cfg, falling back to the process-wide limits for unset fields
+func configLimits(cfg *GtsConfig) idLimits {
+	limits := currentLimits()
+	if cfg == nil {
+		return limits
+	}
+	if cfg.MaxIDLength > 0 {
+		limits.maxLength = cfg.MaxIDLength
+	}
+	if cfg.MaxSegments > 0 {
+		limits.maxSegments = cfg.MaxSegments
+	}
+	return limits
+}
+
+// checkLength reports an IDLimitError when id is longer than the limit
+func (l idLimits) checkLength(id string, length int) error {
+	if l.maxLength > 0 && length > l.maxLength {
+		return &IDLimitError{GtsID: id, Limit: IDLimitLength, Actual: length, Max: l.maxLength}
+	}
+	return nil
+}
+
+// checkSegments reports an IDLimitError when id has more segments than the limit
+func (l idLimits) checkSegments(id string, segments int) error {
+	if l.maxSegments > 0 && segments > l.maxSegments {
+		return &IDLimitError{GtsID: id, Limit: IDLimitSegments, Actual: segments, Max: l.maxSegments}
+	}
+	return nil
+}
+
 var (
 	// GtsNamespace is the UUID namespace for GTS identifiers
 	// Generated as uuid5(NAMESPACE_URL, "gts")
@@ -50,6 +134,21 @@ func (e *InvalidGtsIDError) Error() string {
 	return fmt.Sprintf("Invalid GTS identifier: %s", e.GtsID)
 }
 
+// IDLimitError is returned when a GTS identifier exceeds a configured length or segment limit
+type IDLimitError struct {
+	GtsID  string
+	Limit  string // IDLimitLength or IDLimitSegments
+	Actual int
+	Max    int
+}
+
+func (e *IDLimitError) Error() string {
+	if e.Limit == IDLimitSegments {
+		return fmt.Sprintf("Invalid GTS identifier: %s: Too many segments: %d segments exceeds the limit of %d", e.GtsID, e.Actual, e.Max)
+	}
+	return fmt.Sprintf("Invalid GTS identifier: %s: Too long: %d characters exceeds the limit of %d", e.GtsID, e.Actual, e.Max)
+}
+
 // InvalidSegmentError represents an error in a specific segment
 type InvalidSegmentError struct {
 	Num     int
@@ -86,8 +185,17 @@ type GtsID struct {
 	Segments []*GtsIDSegment
 }
 
-// NewGtsID creates and validates a new GTS identifier
+// NewGtsID creates and validates a new GTS identifier using the process-wide limits
 func NewGtsID(id string) (*GtsID, error) {
+	return newGtsID(id, currentLimits())
+}
+
+// NewGtsIDWithConfig creates and validates a new GTS identifier using the ID limits of cfg
+func NewGtsIDWithConfig(id string, cfg *GtsConfig) (*GtsID, error) {
+	return newGtsID(id, configLimits(cfg))
+}
+
+func newGtsID(id string, limits idLimits) (*GtsID, error) {
 	raw := strings.TrimSpace(id)
 
 	// Validate lowercase
@@ -106,8 +214,8 @@ func NewGtsID(id string) (*GtsID, error) {
 	}
 
 	// Validate length
-	if len(raw) > MaxIDLength {
-		return nil, &InvalidGtsIDError{GtsID: id, Cause: "Too long"}
+	if err := limits.checkLength(id, len(raw)); err != nil {
+		return nil, err
 	}
 
 	gtsID := &GtsID{
@@ -118,6 +226,9 @@ func NewGtsID(id string) (*GtsID, error) {
 	// Split by ~ to get segments, preserving empties to detect trailing ~
 	remainder := raw[len(GtsPrefix):]
 	parts := splitPreservingTilde(remainder)
+	if err := limits.checkSegments(id, len(parts)); err != nil {
+		return nil, err
+	}
 
 	offset := len(GtsPrefix)
 	for i, part := range parts {
@@ -174,6 +285,20 @@ func (g *GtsID) ToUUID() uuid.UUID {
 	return uuid.NewSHA1(GtsNamespace, []byte(g.ID))
 }
 
+// SegmentPrefixIDs returns the cumulative segment prefixes of the identifier, from the base type
+// through each derived type to the full ID, e.g. gts.a.b.c.d.v1~ and gts.a.b.c.d.v1~e.f.g.h.v1.0
+// for a chained instance. Each prefix keeps its segment's '~' terminator.
+func (g *GtsID) SegmentPrefixIDs() []string {
+	prefixes := make([]string, 0, len(g.Segments))
+	var b strings.Builder
+	b.WriteString(GtsPrefix)
+	for _, seg := range g.Segments {
+		b.WriteString(seg.Segment)
+		prefixes = append(prefixes, b.String())
+	}
+	return prefixes
+}
+
 // splitPreservingTilde splits a string by ~ while preserving the ~ at the end of each part
 func splitPreservingTilde(s string) []string {
 	_parts := strings.Split(s, "~")
@@ -166,3 +166,140 @@ func TestToUUID_MoreExamples(t *testing.T) {
 		})
 	}
 }
+
+// TestSegmentPrefixIDs tests the cumulative segment prefixes of chained identifiers
+func TestSegmentPrefixIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		gtsID    string
+		expected []string
+	}{
+		{
+			name:     "Single-segment type",
+			gtsID:    "gts.x.test5.events.type.v1~",
+			expected: []string{"gts.x.test5.events.type.v1~"},
+		},
+		{
+			name:  "Chained type",
+			gtsID: "gts.x.test5.events.type.v1~abc.app._.custom_event.v1.1~",
+			expected: []string{
+				"gts.x.test5.events.type.v1~",
+				"gts.x.test5.events.type.v1~abc.app._.custom_event.v1.1~",
+			},
+		},
+		{
+			name:  "Chained instance",
+			gtsID: "gts.x.test5.events.type.v1~abc.app._.custom_event.v1.1~abc.app._.evt_1.v1.2",
+			expected: []string{
+				"gts.x.test5.events.type.v1~",
+				"gts.x.test5.events.type.v1~abc.app._.custom_event.v1.1~",
+				"gts.x.test5.events.type.v1~abc.app._.custom_event.v1.1~abc.app._.evt_1.v1.2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gts, err := NewGtsID(tt.gtsID)
+			if err != nil {
+				t.Fatalf("Failed to parse GTS ID: %v", err)
+			}
+
+			prefixes := gts.SegmentPrefixIDs()
+			if len(prefixes) != len(tt.expected) {
+				t.Fatalf("Expected prefixes %v, got %v", tt.expected, prefixes)
+			}
+			for i := range prefixes {
+				if prefixes[i] != tt.expected[i] {
+					t.Errorf("Expected prefix %d to be %s, got %s", i, tt.expected[i], prefixes[i])
+				}
+			}
+		})
+	}
+}
+
+// TestIDToUUIDTree tests UUID generation for every segment prefix
+func TestIDToUUIDTree(t *testing.T) {
+	tree, err := IDToUUIDTree("gts.x.test5.events.type.v1~abc.app._.custom_event.v1.2")
+	if err != nil {
+		t.Fatalf("IDToUUIDTree failed: %v", err)
+	}
+
+	expected := []UUIDTreeEntry{
+		{Prefix: "gts.x.test5.events.type.v1~", UUID: "de567dcc-10ef-597d-8f82-3c999ed9b979"},
+		{Prefix: "gts.x.test5.events.type.v1~abc.app._.custom_event.v1.2", UUID: "c7f8cca7-3af6-58af-b72b-3febfd93f1a8"},
+	}
+	if len(tree) != len(expected) {
+		t.Fatalf("Expected %d entries, got %v", len(expected), tree)
+	}
+	for i := range tree {
+		if tree[i] != expected[i] {
+			t.Errorf("Expected entry %d to be %+v, got %+v", i, expected[i], tree[i])
+		}
+		if tree[i].UUID != IDToUUID(tree[i].Prefix).UUID {
+			t.Errorf("Tree UUID of %s differs from IDToUUID", tree[i].Prefix)
+		}
+	}
+
+	if _, err := IDToUUIDTree("gts.x.test5.events.type.v1~abc.app"); err == nil {
+		t.Error("Expected error for invalid ID")
+	}
+	if result := NewUUIDTreeResult("invalid"); result.Error == "" || len(result.Tree) != 0 {
+		t.Errorf("Expected error result, got %+v", result)
+	}
+}
+
+// TestIDsToUUIDs tests that invalid IDs of a batch are reported per item
+func TestIDsToUUIDs(t *testing.T) {
+	results := IDsToUUIDs([]string{"gts.x.test5.events.type.v1~", "invalid", "gts.x.test5.events.type.v1.1~"})
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %v", results)
+	}
+	if results[0].UUID != "de567dcc-10ef-597d-8f82-3c999ed9b979" || results[2].UUID != "b9a18e35-890b-586c-81fa-a156b9a26e2b" {
+		t.Errorf("Unexpected UUIDs: %+v", results)
+	}
+	if results[1].ID != "invalid" || results[1].UUID != "" || results[1].Error == "" {
+		t.Errorf("Expected an error result for the invalid ID, got %+v", results[1])
+	}
+}
+
+// TestGetByUUID tests the reverse lookup with and without the UUID index, across overwrite and unregistration
+func TestGetByUUID(t *testing.T) {
+	const id = "gts.x.test5.events.type.v1~"
+	const u = "de567dcc-10ef-597d-8f82-3c999ed9b979"
+
+	for _, indexed := range []bool{false, true} {
+		store := NewGtsStoreWithConfig(nil, &RegistryConfig{IndexUUIDs: indexed})
+		if entity := store.GetByUUID(u); entity != nil {
+			t.Fatalf("Expected no entity before registration, got %s", entity.GtsID.ID)
+		}
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("Failed to register schema: %v", err)
+		}
+		for _, lookup := range []string{u, "DE567DCC-10EF-597D-8F82-3C999ED9B979", "urn:uuid:" + u} {
+			if entity := store.GetByUUID(lookup); entity == nil || entity.GtsID.ID != id {
+				t.Errorf("Expected %s for %s (indexed: %v), got %v", id, lookup, indexed, entity)
+			}
+		}
+		if entity := store.GetByUUID("not-a-uuid"); entity != nil {
+			t.Errorf("Expected no entity for an invalid UUID, got %s", entity.GtsID.ID)
+		}
+
+		if err := store.RegisterSchema(id, map[string]any{"type": "object", "description": "changed"}); err != nil {
+			t.Fatalf("Failed to overwrite schema: %v", err)
+		}
+		if entity := store.GetByUUID(u); entity == nil || entity.Content["description"] != "changed" {
+			t.Errorf("Expected the overwritten content (indexed: %v), got %v", indexed, entity)
+		}
+
+		if err := store.Unregister(id); err != nil {
+			t.Fatalf("Unregister failed: %v", err)
+		}
+		if entity := store.GetByUUID(u); entity != nil {
+			t.Errorf("Expected no entity after Unregister (indexed: %v), got %s", indexed, entity.GtsID.ID)
+		}
+		if indexed && len(store.byUUID) != 0 {
+			t.Errorf("Expected an empty UUID index, got %v", store.byUUID)
+		}
+	}
+}
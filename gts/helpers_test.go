@@ -0,0 +1,40 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import "testing"
+
+// newTestStore creates a store with cfg, or the default registry configuration when nil, and
+// registers contents into it in order
+func newTestStore(t testing.TB, cfg *RegistryConfig, contents ...map[string]any) *GtsStore {
+	t.Helper()
+	store := NewGtsStoreWithConfig(nil, cfg)
+	registerTestEntities(t, store, contents...)
+	return store
+}
+
+// registerTestEntities registers contents into store in order, failing the test on error
+func registerTestEntities(t testing.TB, store *GtsStore, contents ...map[string]any) {
+	t.Helper()
+	for _, content := range contents {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+	}
+}
+
+// testSchema returns a draft-07 object schema with the given GTS ID and the keywords of extra
+func testSchema(id string, extra map[string]any) map[string]any {
+	content := map[string]any{
+		"$id":     GtsURIPrefix + id,
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}
+	for k, v := range extra {
+		content[k] = v
+	}
+	return content
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import "fmt"
+
+// IDTypeMismatchError is returned by CheckIDTypeConsistency, and by Register when
+// RegistryConfig.EnforceIDTypeConsistency is set, for an instance whose ID encodes a type other
+// than the schema ID it declares
+type IDTypeMismatchError struct {
+	EntityID string
+	// ChainSchemaID is the type the instance ID encodes, the ID up to its last '~'
+	ChainSchemaID string
+	// Field and DeclaredSchemaID are the schema-ID field disagreeing with ChainSchemaID and its value
+	Field            string
+	DeclaredSchemaID string
+}
+
+func (e *IDTypeMismatchError) Error() string {
+	return fmt.Sprintf("Instance %s is declared of type %s by %s, but its ID encodes type %s",
+		e.EntityID, e.DeclaredSchemaID, e.Field, e.ChainSchemaID)
+}
+
+// CheckIDTypeConsistency checks that the schema ID an instance declares in its schema-ID fields
+// is the type its ID encodes: the instance ID minus its last segment. A declared base type of
+// that chain, or a variant differing in minor versions only, is consistent too. Schemas,
+// instances with a single-segment ID and instances declaring no schema ID always are. It fails
+// with StoreGtsObjectNotFoundError for unknown IDs and with IDTypeMismatchError otherwise.
+func (s *GtsStore) CheckIDTypeConsistency(id string) error {
+	entity := s.Get(id)
+	if entity == nil {
+		return &StoreGtsObjectNotFoundError{EntityID: id}
+	}
+	return entity.idTypeMismatchError()
+}
+
+// idTypeMismatchError returns the error for the first schema-ID field of an instance that
+// disagrees with its chained ID, or nil
+func (e *JsonEntity) idTypeMismatchError() error {
+	if e.IsSchema || e.GtsID == nil {
+		return nil
+	}
+	parent, err := e.GtsID.Parent()
+	if err != nil {
+		return nil
+	}
+	for _, source := range e.schemaSources {
+		if source.Field == SchemaSourceChain || !IsValidGtsID(source.Value) {
+			continue
+		}
+		if !chainTypeAgrees(source.Value, parent) {
+			return &IDTypeMismatchError{
+				EntityID:         e.GtsID.ID,
+				ChainSchemaID:    parent.ID,
+				Field:            source.Field,
+				DeclaredSchemaID: source.Value,
+			}
+		}
+	}
+	return nil
+}
+
+// chainTypeAgrees reports whether a declared schema ID names the chained type of an instance ID,
+// or one of its base types, up to minor versions
+func chainTypeAgrees(declared string, chain *GtsID) bool {
+	if schemaIDAgrees(declared, chain.ID) {
+		return true
+	}
+	id, err := NewGtsID(declared)
+	if err != nil || !id.IsType() || len(id.Segments) > len(chain.Segments) {
+		return false
+	}
+	return majorVersionKey(id) == majorVersionKey(&GtsID{Segments: chain.Segments[:len(id.Segments)]})
+}
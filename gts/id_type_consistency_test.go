@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckIDTypeConsistency(t *testing.T) {
+	store := NewGtsStore(nil)
+	entities := []map[string]any{
+		{"id": "gts.x.core.events.order.v1.0~x.shop._.same.v1", "type": "gts.x.core.events.order.v1.0~"},
+		{"id": "gts.x.core.events.order.v1.0~x.shop._.minor.v1", "type": "gts.x.core.events.order.v1.2~"},
+		{"id": "gts.x.core.events.order.v1~x.shop.orders.placed.v1.0~x.shop._.base.v1", "type": "gts.x.core.events.order.v1.1~"},
+		{"id": "gts.x.core.events.order.v1.0~x.shop._.undeclared.v1"},
+		{"id": "gts.x.core.events.order.v1.0~x.shop._.other.v1", "type": "gts.x.core.events.refund.v1~"},
+		{"id": "gts.x.core.events.order.v1.0~x.shop._.major.v1", "gtsType": "gts.x.core.events.order.v2~"},
+	}
+	for _, content := range entities {
+		if err := store.Register(NewJsonEntity(content, nil)); err != nil {
+			t.Fatalf("Failed to register %s: %v", content["id"], err)
+		}
+	}
+
+	for _, id := range []string{
+		"gts.x.core.events.order.v1.0~x.shop._.same.v1",
+		"gts.x.core.events.order.v1.0~x.shop._.minor.v1",
+		"gts.x.core.events.order.v1~x.shop.orders.placed.v1.0~x.shop._.base.v1",
+		"gts.x.core.events.order.v1.0~x.shop._.undeclared.v1",
+	} {
+		if err := store.CheckIDTypeConsistency(id); err != nil {
+			t.Errorf("Expected %s to be consistent, got %v", id, err)
+		}
+	}
+
+	tests := []struct {
+		id       string
+		field    string
+		declared string
+	}{
+		{"gts.x.core.events.order.v1.0~x.shop._.other.v1", "type", "gts.x.core.events.refund.v1~"},
+		{"gts.x.core.events.order.v1.0~x.shop._.major.v1", "gtsType", "gts.x.core.events.order.v2~"},
+	}
+	for _, tt := range tests {
+		var mismatch *IDTypeMismatchError
+		if err := store.CheckIDTypeConsistency(tt.id); !errors.As(err, &mismatch) {
+			t.Errorf("Expected IDTypeMismatchError for %s, got %v", tt.id, err)
+			continue
+		}
+		if mismatch.EntityID != tt.id || mismatch.ChainSchemaID != "gts.x.core.events.order.v1.0~" ||
+			mismatch.Field != tt.field || mismatch.DeclaredSchemaID != tt.declared {
+			t.Errorf("Unexpected mismatch details: %+v", mismatch)
+		}
+	}
+
+	var notFound *StoreGtsObjectNotFoundError
+	if err := store.CheckIDTypeConsistency("gts.x.core.events.order.v1.0~x.shop._.missing.v1"); !errors.As(err, &notFound) {
+		t.Errorf("Expected StoreGtsObjectNotFoundError, got %v", err)
+	}
+}
+
+func TestRegister_EnforceIDTypeConsistency(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{EnforceIDTypeConsistency: true})
+	var mismatch *IDTypeMismatchError
+	err := store.Register(NewJsonEntity(map[string]any{
+		"id":   "gts.x.core.events.order.v1~x.shop._.o1.v1",
+		"type": "gts.x.core.events.refund.v1~",
+	}, nil))
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Expected IDTypeMismatchError, got %v", err)
+	}
+	if store.Get("gts.x.core.events.order.v1~x.shop._.o1.v1") != nil {
+		t.Error("Expected the inconsistent instance not to be registered")
+	}
+	if err := store.Register(NewJsonEntity(map[string]any{
+		"id":   "gts.x.core.events.order.v1~x.shop._.o2.v1",
+		"type": "gts.x.core.events.order.v1.3~",
+	}, nil)); err != nil {
+		t.Errorf("Expected a minor-compatible declaration to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InferConfig controls InferSchema
+type InferConfig struct {
+	// ClosedObjects sets additionalProperties to false on every inferred object
+	ClosedObjects bool
+	// Strict makes values of different types at the same location an error; otherwise the
+	// location gets a union type such as ["number", "string"]
+	Strict bool
+}
+
+// InferTypeConflictError is returned by InferSchema in strict mode when the examples hold values of
+// different types at the same location
+type InferTypeConflictError struct {
+	// Path is the location of the values, with [*] standing for array items; empty for the root
+	Path  string
+	Types []string
+}
+
+func (e *InferTypeConflictError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "the root"
+	}
+	return fmt.Sprintf("Conflicting types at %s: %s", path, strings.Join(e.Types, ", "))
+}
+
+// InferSchema generates a draft-07 schema with $id typeID from example instances: every property
+// gets the type of its values, integers widening to number, and strings that are all RFC 3339
+// timestamps or UUIDs get the date-time or uuid format. Properties present in every example of
+// an object are required. Array items are inferred from all the elements. Values of different
+// types at the same location give a union type, or an InferTypeConflictError with cfg.Strict.
+// A nil cfg is the zero InferConfig. The schema validates the examples it was inferred from.
+func InferSchema(instances []map[string]any, typeID string, cfg *InferConfig) (map[string]any, error) {
+	if cfg == nil {
+		cfg = &InferConfig{}
+	}
+	gtsID, err := NewGtsID(strings.TrimPrefix(typeID, GtsURIPrefix))
+	if err != nil {
+		return nil, err
+	}
+	if !gtsID.IsType() {
+		return nil, fmt.Errorf("type ID must end with '~': %s", typeID)
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("at least one example instance is needed to infer a schema")
+	}
+
+	root := &inferredNode{}
+	for _, instance := range instances {
+		root.add(instance)
+	}
+	schema, err := root.schema("", cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{
+		"$id":     GtsURIPrefix + gtsID.ID,
+		"$schema": "http://json-schema.org/draft-07/schema#",
+	}
+	for key, value := range schema {
+		result[key] = value
+	}
+	return result, nil
+}
+
+// inferSchema returns a schema accepting value, inferred as InferSchema does
+func inferSchema(value any) map[string]any {
+	node := &inferredNode{}
+	node.add(value)
+	schema, _ := node.schema("", &InferConfig{})
+	return schema
+}
+
+// inferredNode accumulates the values seen at a location of the examples
+type inferredNode struct {
+	types map[string]bool
+	// count is the number of values seen, so that for a property it is the number of objects
+	// holding the property, and objects the number of objects seen
+	count      int
+	objects    int
+	properties map[string]*inferredNode
+	items      *inferredNode
+	// strings counts the strings seen, dateTimes and uuids those of each format
+	strings   int
+	dateTimes int
+	uuids     int
+}
+
+// add merges a value into the node
+func (n *inferredNode) add(value any) {
+	if n.types == nil {
+		n.types = make(map[string]bool)
+	}
+	n.count++
+
+	switch v := value.(type) {
+	case map[string]any:
+		n.types["object"] = true
+		n.objects++
+		if n.properties == nil {
+			n.properties = make(map[string]*inferredNode)
+		}
+		for key, propValue := range v {
+			prop := n.properties[key]
+			if prop == nil {
+				prop = &inferredNode{}
+				n.properties[key] = prop
+			}
+			prop.add(propValue)
+		}
+	case []any:
+		n.types["array"] = true
+		for _, item := range v {
+			if n.items == nil {
+				n.items = &inferredNode{}
+			}
+			n.items.add(item)
+		}
+	case string:
+		n.types["string"] = true
+		n.strings++
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			n.dateTimes++
+		} else if _, err := uuid.Parse(v); err == nil && len(v) == 36 {
+			n.uuids++
+		}
+	case bool:
+		n.types["boolean"] = true
+	case nil:
+		n.types["null"] = true
+	default:
+		if _, ok := intValue(v); ok {
+			n.types["integer"] = true
+		} else if _, ok := floatValue(v); ok {
+			n.types["number"] = true
+		}
+	}
+}
+
+// schema returns the schema of the values of the node at path
+func (n *inferredNode) schema(path string, cfg *InferConfig) (map[string]any, error) {
+	if n.types["integer"] && n.types["number"] {
+		delete(n.types, "integer")
+	}
+	types := sortedKeys(n.types)
+
+	schema := map[string]any{}
+	switch {
+	case len(types) == 1:
+		schema["type"] = types[0]
+	case len(types) > 1:
+		if cfg.Strict {
+			return nil, &InferTypeConflictError{Path: path, Types: types}
+		}
+		union := make([]any, 0, len(types))
+		for _, typ := range types {
+			union = append(union, typ)
+		}
+		schema["type"] = union
+	}
+
+	if n.types["object"] {
+		properties := make(map[string]any, len(n.properties))
+		required := []any{}
+		for _, key := range sortedKeys(n.properties) {
+			prop := n.properties[key]
+			propSchema, err := prop.schema(buildPath(path, key), cfg)
+			if err != nil {
+				return nil, err
+			}
+			properties[key] = propSchema
+			if prop.count == n.objects {
+				required = append(required, key)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		if cfg.ClosedObjects {
+			schema["additionalProperties"] = false
+		}
+	}
+	if n.types["array"] && n.items != nil {
+		items, err := n.items.schema(path+"[*]", cfg)
+		if err != nil {
+			return nil, err
+		}
+		schema["items"] = items
+	}
+	if n.strings > 0 {
+		switch n.strings {
+		case n.dateTimes:
+			schema["format"] = "date-time"
+		case n.uuids:
+			schema["format"] = "uuid"
+		}
+	}
+	return schema, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	const typeID = "gts.x.test.infer.event.v1~"
+	instances := []map[string]any{
+		{
+			"id":         typeID + "x.test._.a.v1",
+			"tenantId":   "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			"occurredAt": "2025-09-20T18:35:00Z",
+			"amount":     float64(3),
+			"payload":    map[string]any{"lines": []any{map[string]any{"sku": "A", "qty": float64(1)}}},
+		},
+		{
+			"id":         typeID + "x.test._.b.v1",
+			"tenantId":   "9b2d1f8e-1c4a-4c1e-8f0a-2d6c3b5a7e90",
+			"occurredAt": "2025-09-21T08:00:00Z",
+			"amount":     2.5,
+			"payload":    map[string]any{"lines": []any{map[string]any{"sku": "B"}}, "note": "gift"},
+			"code":       "x",
+		},
+		{
+			"id":         typeID + "x.test._.c.v1",
+			"tenantId":   "2f1e6b7a-3c9d-4e8f-a1b2-c3d4e5f60718",
+			"occurredAt": "2025-09-22T08:00:00Z",
+			"amount":     float64(1),
+			"payload":    map[string]any{"lines": []any{}},
+			"code":       float64(7),
+		},
+	}
+
+	schema, err := InferSchema(instances, typeID, &InferConfig{ClosedObjects: true})
+	if err != nil {
+		t.Fatalf("InferSchema failed: %v", err)
+	}
+	if schema["$id"] != GtsURIPrefix+typeID || schema["$schema"] != "http://json-schema.org/draft-07/schema#" {
+		t.Errorf("Unexpected $id or $schema: %v", schema)
+	}
+	if !reflect.DeepEqual(schema["required"], []any{"amount", "id", "occurredAt", "payload", "tenantId"}) {
+		t.Errorf("Expected the properties of every example to be required, got %v", schema["required"])
+	}
+	props := schema["properties"].(map[string]any)
+	expected := map[string]any{
+		"amount":     map[string]any{"type": "number"},
+		"code":       map[string]any{"type": []any{"integer", "string"}},
+		"occurredAt": map[string]any{"type": "string", "format": "date-time"},
+		"tenantId":   map[string]any{"type": "string", "format": "uuid"},
+		"payload": map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"required":             []any{"lines"},
+			"properties": map[string]any{
+				"note": map[string]any{"type": "string"},
+				"lines": map[string]any{"type": "array", "items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []any{"sku"},
+					"properties": map[string]any{
+						"qty": map[string]any{"type": "integer"},
+						"sku": map[string]any{"type": "string"},
+					},
+				}},
+			},
+		},
+	}
+	for name, want := range expected {
+		if !reflect.DeepEqual(props[name], want) {
+			t.Errorf("Property %s: expected %v, got %v", name, want, props[name])
+		}
+	}
+	if schema["additionalProperties"] != false {
+		t.Errorf("Expected a closed root object, got %v", schema["additionalProperties"])
+	}
+
+	// The inferred schema registers and validates the examples
+	store := NewGtsStore(nil)
+	if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register inferred schema: %v", err)
+	}
+	for _, instance := range instances {
+		entity := NewJsonEntity(instance, DefaultGtsConfig())
+		if err := store.Register(entity); err != nil {
+			t.Fatalf("Failed to register example: %v", err)
+		}
+		if result := store.ValidateInstance(entity.GtsID.ID); !result.OK {
+			t.Errorf("Example %s does not validate: %s", entity.GtsID.ID, result.Error)
+		}
+	}
+
+	// Strict mode refuses the conflicting types
+	_, err = InferSchema(instances, typeID, &InferConfig{Strict: true})
+	var conflict *InferTypeConflictError
+	if !errors.As(err, &conflict) || conflict.Path != "code" || !reflect.DeepEqual(conflict.Types, []string{"integer", "string"}) {
+		t.Errorf("Expected a type conflict at code, got %v", err)
+	}
+
+	if _, err := InferSchema(instances, "gts.x.test.infer.event.v1", nil); err == nil {
+		t.Error("Expected an error for a type ID without '~'")
+	}
+}
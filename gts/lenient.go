@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"log"
+	"strings"
+)
+
+// NormalizeLookupID returns id as the lenient lookup retries it: trimmed, without the gts:// URI
+// prefix (in any case) and lowercased. GTS IDs are lowercase, so the normalized form of a pasted
+// ID with stray capitals is the ID that was meant.
+func NormalizeLookupID(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) >= len(GtsURIPrefix) && strings.EqualFold(id[:len(GtsURIPrefix)], GtsURIPrefix) {
+		id = id[len(GtsURIPrefix):]
+	}
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// Lookup retrieves a JsonEntity like Get, and tells when the entity was found through the lenient
+// alias layer: with RegistryConfig.LenientLookup, an ID that is not found is normalized with
+// NormalizeLookupID and looked up again, and normalizedID is the ID the entity was found under.
+// normalizedID is empty for exact hits, for misses and when LenientLookup is not set.
+func (s *GtsStore) Lookup(entityID string) (entity *JsonEntity, normalizedID string) {
+	if entity = s.getExact(entityID); entity != nil || !s.config.LenientLookup {
+		return entity, ""
+	}
+	normalized := NormalizeLookupID(entityID)
+	if normalized == entityID || normalized == "" {
+		return nil, ""
+	}
+	if entity = s.getExact(normalized); entity == nil {
+		return nil, ""
+	}
+	s.lookups.normalizedHits.Add(1)
+	log.Printf("Lenient lookup: %q found as %s", entityID, normalized)
+	return entity, normalized
+}
+
+// lenientQueryPattern returns the base pattern of a query as it is evaluated: normalized with
+// NormalizeLookupID when RegistryConfig.LenientLookup is set, unchanged otherwise
+func (s *GtsStore) lenientQueryPattern(pattern string) string {
+	if !s.config.LenientLookup {
+		return pattern
+	}
+	return NormalizeLookupID(pattern)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import "testing"
+
+func TestNormalizeLookupID(t *testing.T) {
+	tests := map[string]string{
+		"gts.x.core.events.type.v1~":         "gts.x.core.events.type.v1~",
+		"  GTS.X.Core.Events.Type.v1~ ":      "gts.x.core.events.type.v1~",
+		"gts://gts.x.core.events.type.v1~":   "gts.x.core.events.type.v1~",
+		"GTS://GTS.x.core.events.type.v1~\n": "gts.x.core.events.type.v1~",
+		"gts.X.core.*":                       "gts.x.core.*",
+	}
+	for input, expected := range tests {
+		if got := NormalizeLookupID(input); got != expected {
+			t.Errorf("NormalizeLookupID(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+// lenientTestEntities are a schema with one instance
+func lenientTestEntities() []map[string]any {
+	return []map[string]any{
+		testSchema("gts.x.test.lenient.item.v1~", nil),
+		{"id": "gts.x.test.lenient.item.v1~x.test._.a.v1", "name": "a"},
+	}
+}
+
+func TestLookup_Lenient(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{LenientLookup: true}, lenientTestEntities()...)
+
+	entity, normalizedID := store.Lookup(" gts://GTS.X.Test.Lenient.Item.v1~ ")
+	if entity == nil || normalizedID != "gts.x.test.lenient.item.v1~" {
+		t.Fatalf("Expected the uppercase ID to be found as the normalized ID, got %v %q", entity, normalizedID)
+	}
+	if store.Get("GTS.x.test.lenient.item.v1~x.test._.a.v1") == nil {
+		t.Error("Expected Get to find the instance through the lenient lookup")
+	}
+	if _, normalizedID := store.Lookup("gts.x.test.lenient.item.v1~"); normalizedID != "" {
+		t.Errorf("Expected no normalization for an exact hit, got %q", normalizedID)
+	}
+	if stats := store.LookupStats(); stats.NormalizedHits != 2 {
+		t.Errorf("Expected 2 normalized hits, got %+v", stats)
+	}
+
+	result := store.Query("GTS.X.Test.Lenient.*", 10)
+	if result.Err != nil || result.Count != 2 || result.NormalizedPattern != "gts.x.test.lenient.*" {
+		t.Errorf("Expected the uppercase pattern to match the schema and instance, got %+v", result)
+	}
+}
+
+func TestLookup_StrictByDefault(t *testing.T) {
+	store := newTestStore(t, nil, lenientTestEntities()...)
+
+	if entity, normalizedID := store.Lookup("GTS.X.Test.Lenient.Item.v1~"); entity != nil || normalizedID != "" {
+		t.Errorf("Expected the uppercase ID to miss without LenientLookup, got %v %q", entity, normalizedID)
+	}
+	if result := store.Query("GTS.X.Test.Lenient.*", 10); result.Err == nil || result.NormalizedPattern != "" {
+		t.Errorf("Expected the uppercase pattern to be rejected without LenientLookup, got %+v", result)
+	}
+}
+
+func TestLookup_RegistrationNotLenient(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{LenientLookup: true}, lenientTestEntities()...)
+
+	// Tags and references are written against exact IDs only
+	if err := store.SetTags("GTS.X.Test.Lenient.Item.v1~", map[string]string{"owner": "a"}); err == nil {
+		t.Error("Expected SetTags to reject an ID that only matches when normalized")
+	}
+	if target := store.resolveReference(&GtsReference{ID: "GTS.X.Test.Lenient.Item.v1~"}, nil); target != nil {
+		t.Error("Expected references to resolve against exact IDs only")
+	}
+}
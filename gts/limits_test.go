@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// chainedTypeID builds a chained type ID with the given number of segments
+func chainedTypeID(segments int) string {
+	var b strings.Builder
+	b.WriteString("gts.x.limits.ns.base.v1~")
+	for i := 1; i < segments; i++ {
+		b.WriteString("x.limits.ns.derived.v1~")
+	}
+	return b.String()
+}
+
+// setTestLimits sets the process-wide limits for the duration of a test
+func setTestLimits(t *testing.T, maxIDLength, maxSegments int) {
+	t.Helper()
+	SetLimits(maxIDLength, maxSegments)
+	t.Cleanup(func() { SetLimits(0, 0) })
+}
+
+func assertIDLimitError(t *testing.T, err error, limit string, actual, max int) {
+	t.Helper()
+	var limitErr *IDLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Expected IDLimitError, got %v", err)
+	}
+	if limitErr.Limit != limit || limitErr.Actual != actual || limitErr.Max != max {
+		t.Errorf("Expected %s %d > %d, got %+v", limit, actual, max, limitErr)
+	}
+}
+
+func TestLimits_Defaults(t *testing.T) {
+	maxIDLength, maxSegments := Limits()
+	if maxIDLength != MaxIDLength || maxSegments != MaxSegments {
+		t.Errorf("Expected default limits %d/%d, got %d/%d", MaxIDLength, MaxSegments, maxIDLength, maxSegments)
+	}
+
+	_, err := NewGtsID("gts." + strings.Repeat("a", MaxIDLength))
+	assertIDLimitError(t, err, IDLimitLength, MaxIDLength+4, MaxIDLength)
+	if !strings.Contains(err.Error(), "exceeds the limit of 1024") {
+		t.Errorf("Expected error to mention the limit, got %v", err)
+	}
+}
+
+func TestLimits_Parse(t *testing.T) {
+	setTestLimits(t, 80, 2)
+
+	under := chainedTypeID(2)
+	if len(under) > 80 {
+		t.Fatalf("test ID too long: %d", len(under))
+	}
+	if result := ParseID(under); !result.OK {
+		t.Errorf("Expected %s to parse, got %s", under, result.Error)
+	}
+
+	result := ParseID(chainedTypeID(3))
+	if result.OK || !strings.Contains(result.Error, "Too many segments: 3 segments exceeds the limit of 2") {
+		t.Errorf("Expected segment limit error, got %+v", result)
+	}
+
+	long := "gts.x.limits.ns." + strings.Repeat("t", 70) + ".v1~"
+	_, err := NewGtsID(long)
+	assertIDLimitError(t, err, IDLimitLength, len(long), 80)
+
+	// Wildcard patterns are subject to the same limits
+	if result := ParseID(chainedTypeID(3) + "*"); result.OK {
+		t.Error("Expected wildcard pattern over the segment limit to be rejected")
+	}
+}
+
+func TestLimits_WithConfig(t *testing.T) {
+	id := chainedTypeID(3)
+
+	if _, err := NewGtsIDWithConfig(id, &GtsConfig{MaxSegments: 3}); err != nil {
+		t.Errorf("Expected ID at the segment limit to parse: %v", err)
+	}
+	_, err := NewGtsIDWithConfig(id, &GtsConfig{MaxSegments: 2})
+	assertIDLimitError(t, err, IDLimitSegments, 3, 2)
+
+	if result := ParseIDWithConfig(id, &GtsConfig{MaxIDLength: len(id)}); !result.OK {
+		t.Errorf("Expected ID at the length limit to parse: %s", result.Error)
+	}
+	if result := ParseIDWithConfig(id, &GtsConfig{MaxIDLength: len(id) - 1}); result.OK {
+		t.Error("Expected ID over the length limit to be rejected")
+	}
+
+	// The process-wide limits are unaffected
+	if _, err := NewGtsID(id); err != nil {
+		t.Errorf("Expected process-wide limits to be unchanged: %v", err)
+	}
+}
+
+func TestLimits_Match(t *testing.T) {
+	candidate := chainedTypeID(2) + "x.limits._.item.v1"
+	pattern := chainedTypeID(2) + "*"
+
+	if result := MatchIDPattern(candidate, pattern); !result.Match {
+		t.Fatalf("Expected match with default limits, got %+v", result)
+	}
+
+	setTestLimits(t, 0, 2)
+	result := MatchIDPattern(candidate, pattern)
+	if result.Match || !strings.Contains(result.Error, "exceeds the limit of 2") {
+		t.Errorf("Expected limit error, got %+v", result)
+	}
+
+	if result := MatchIDPattern(chainedTypeID(2), chainedTypeID(1)+"*"); !result.Match {
+		t.Errorf("Expected IDs under the limit to match, got %+v", result)
+	}
+}
+
+func TestLimits_Register(t *testing.T) {
+	schema := func(id string) *JsonEntity {
+		return NewJsonEntity(map[string]any{
+			"$id":     id,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		}, DefaultGtsConfig())
+	}
+
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{MaxSegments: 2})
+	if err := store.Register(schema(chainedTypeID(2))); err != nil {
+		t.Errorf("Expected registration under the store limit to succeed: %v", err)
+	}
+	err := store.Register(schema(chainedTypeID(3)))
+	assertIDLimitError(t, err, IDLimitSegments, 3, 2)
+
+	id := chainedTypeID(1)
+	store = NewGtsStoreWithConfig(nil, &RegistryConfig{MaxIDLength: len(id) - 1})
+	err = store.Register(schema(id))
+	assertIDLimitError(t, err, IDLimitLength, len(id), len(id)-1)
+
+	err = store.RegisterSchema(id, map[string]any{"type": "object"})
+	assertIDLimitError(t, err, IDLimitLength, len(id), len(id)-1)
+
+	// Entities whose ID exceeds the process-wide limit keep the reason
+	setTestLimits(t, 0, 2)
+	entity := schema(chainedTypeID(3))
+	if entity.GtsID != nil {
+		t.Fatal("Expected ID over the process-wide limit not to be extracted")
+	}
+	assertIDLimitError(t, entity.IDError, IDLimitSegments, 3, 2)
+}
+
+func TestLimits_Query(t *testing.T) {
+	store := NewGtsStore(nil)
+	for _, id := range []string{chainedTypeID(1), chainedTypeID(2)} {
+		if err := store.Register(NewJsonEntity(map[string]any{
+			"$id":     id,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		}, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+
+	setTestLimits(t, 0, 2)
+	if result := store.Query(chainedTypeID(1)+"*", 10); result.Error != "" || result.Count != 1 {
+		t.Errorf("Expected 1 result for a pattern under the limit, got %+v", result)
+	}
+	if result := store.Query(chainedTypeID(2)+"*", 10); !strings.Contains(result.Error, "exceeds the limit of 2") {
+		t.Errorf("Expected limit error for a pattern over the limit, got %+v", result)
+	}
+	if result := store.Query(chainedTypeID(3), 10); !strings.Contains(result.Error, "exceeds the limit of 2") {
+		t.Errorf("Expected limit error for an ID over the limit, got %+v", result)
+	}
+}
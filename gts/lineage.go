@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Actions of a property provenance entry
+const (
+	// LineageIntroduced marks the layer that first defines a property
+	LineageIntroduced = "introduced"
+	// LineageOverridden marks a later layer that defines the property differently
+	LineageOverridden = "overridden"
+)
+
+// PropertyLineage tells, for every property of a flattened schema, which schema layers define it
+type PropertyLineage struct {
+	SchemaID string `json:"schema_id"`
+	// Layers lists the schemas merged into the flattened schema, in merge order: the ancestors
+	// reached through allOf $refs first, the schema itself last
+	Layers []string `json:"layers"`
+	// Properties maps property paths (nested properties joined with dots, e.g. payload.orderId) to
+	// their provenance, the introducing layer first
+	Properties map[string][]PropertyProvenance `json:"properties"`
+	// UnresolvedRefs lists the $refs of allOf parts that name no registered schema
+	UnresolvedRefs []string `json:"unresolved_refs,omitempty"`
+}
+
+// PropertyProvenance is a layer defining a property
+type PropertyProvenance struct {
+	SchemaID string `json:"schema_id"`
+	// Location is the path of the definition within the layer's schema
+	Location string `json:"location"`
+	Action   string `json:"action"`
+	// Changes holds, for an override, the keywords whose value differs from the previous
+	// definition; nested properties are reported under their own paths
+	Changes map[string]PropertyChange `json:"changes,omitempty"`
+}
+
+// PropertyChange is a keyword changed by an override; From or To is nil when the keyword is absent
+type PropertyChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// PropertyLineage flattens the allOf hierarchy of a schema as flattenSchema does, following
+// allOf $refs to registered schemas (which is how derived types extend their chained parent),
+// and records for each property path the layer that introduced it and every later layer that
+// redefined it differently. Inline allOf parts belong to the schema containing them. A later
+// definition replaces the earlier one, as in the flattened schema, so an override lists the
+// keywords it adds, removes or changes.
+func (s *GtsStore) PropertyLineage(schemaID string) (*PropertyLineage, error) {
+	entity := s.Get(schemaID)
+	if entity == nil || !entity.IsSchema {
+		return nil, &StoreGtsSchemaNotFoundError{EntityID: schemaID}
+	}
+
+	b := &lineageBuilder{
+		resolve: s.storeSchemaResolver(),
+		result: &PropertyLineage{
+			SchemaID:   entity.GtsID.ID,
+			Layers:     []string{},
+			Properties: make(map[string][]PropertyProvenance),
+		},
+		current:  make(map[string]map[string]any),
+		visiting: map[string]bool{entity.GtsID.ID: true},
+	}
+	b.layer(entity.GtsID.ID, entity.Content)
+	sort.Strings(b.result.UnresolvedRefs)
+	return b.result, nil
+}
+
+// lineageBuilder accumulates a PropertyLineage while walking the layers of a schema
+type lineageBuilder struct {
+	resolve func(ref string) map[string]any
+	result  *PropertyLineage
+	// current holds the definition of every property path as merged so far
+	current map[string]map[string]any
+	// visiting guards against $ref cycles
+	visiting map[string]bool
+}
+
+// layer merges the schema of layer id, after the layers it references
+func (b *lineageBuilder) layer(id string, schema map[string]any) {
+	b.merge(id, schema, "")
+	b.result.Layers = append(b.result.Layers, id)
+}
+
+// merge merges the allOf parts of a schema at location within layer id, then its properties
+func (b *lineageBuilder) merge(id string, schema map[string]any, location string) {
+	parts, _ := schema["allOf"].([]any)
+	for i, partAny := range parts {
+		part, ok := partAny.(map[string]any)
+		if !ok {
+			continue
+		}
+		if ref, ok := part["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+			target := strings.TrimPrefix(ref, GtsURIPrefix)
+			if b.visiting[target] {
+				continue
+			}
+			content := b.resolve(ref)
+			if content == nil {
+				b.result.UnresolvedRefs = append(b.result.UnresolvedRefs, target)
+				continue
+			}
+			b.visiting[target] = true
+			b.layer(target, content)
+			delete(b.visiting, target)
+			continue
+		}
+		b.merge(id, part, buildPath(location, fmt.Sprintf("allOf[%d]", i)))
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	b.mergeProperties(id, props, "", buildPath(location, "properties"))
+}
+
+// mergeProperties records the definitions of props, at property path prefix, for layer id
+func (b *lineageBuilder) mergeProperties(id string, props map[string]any, prefix, location string) {
+	for _, name := range sortedKeys(props) {
+		def, ok := props[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		path := buildPath(prefix, name)
+		defLocation := buildPath(location, name)
+
+		if previous, defined := b.current[path]; !defined {
+			b.result.Properties[path] = append(b.result.Properties[path], PropertyProvenance{
+				SchemaID: id, Location: defLocation, Action: LineageIntroduced,
+			})
+		} else if changes := propertyChanges(previous, def); len(changes) > 0 {
+			b.result.Properties[path] = append(b.result.Properties[path], PropertyProvenance{
+				SchemaID: id, Location: defLocation, Action: LineageOverridden, Changes: changes,
+			})
+		}
+		b.current[path] = def
+
+		nested, _ := def["properties"].(map[string]any)
+		b.mergeProperties(id, nested, path, buildPath(defLocation, "properties"))
+	}
+}
+
+// propertyChanges compares two definitions of a property keyword by keyword, leaving out the
+// nested properties
+func propertyChanges(from, to map[string]any) map[string]PropertyChange {
+	changes := make(map[string]PropertyChange)
+	for key, value := range to {
+		if key == "properties" {
+			continue
+		}
+		if old, ok := from[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = PropertyChange{From: from[key], To: value}
+		}
+	}
+	for key, value := range from {
+		if _, ok := to[key]; !ok && key != "properties" {
+			changes[key] = PropertyChange{From: value}
+		}
+	}
+	return changes
+}
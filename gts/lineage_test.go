@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPropertyLineage_OrderPlaced(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerOrderPlacedSchemas(t, store)
+
+	const (
+		base = "gts.x.core.events.type.v1~"
+		v11  = "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.1~"
+	)
+	lineage, err := store.PropertyLineage(v11)
+	if err != nil {
+		t.Fatalf("PropertyLineage failed: %v", err)
+	}
+	if !reflect.DeepEqual(lineage.Layers, []string{base, v11}) {
+		t.Errorf("Expected layers base then v1.1, got %v", lineage.Layers)
+	}
+
+	// The envelope fields come from the base event only
+	for _, path := range []string{"id", "tenantId", "occurredAt"} {
+		expected := []PropertyProvenance{{SchemaID: base, Location: "properties." + path, Action: LineageIntroduced}}
+		if !reflect.DeepEqual(lineage.Properties[path], expected) {
+			t.Errorf("Expected %s to be introduced by the base event, got %+v", path, lineage.Properties[path])
+		}
+	}
+
+	expected := []PropertyProvenance{{
+		SchemaID: v11,
+		Location: "allOf[1].properties.payload.properties.new_field_in_v1_1",
+		Action:   LineageIntroduced,
+	}}
+	if got := lineage.Properties["payload.new_field_in_v1_1"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected new_field_in_v1_1 to be introduced by v1.1, got %+v", got)
+	}
+
+	// v1.1 replaces the base definition of type with a const
+	typeLineage := lineage.Properties["type"]
+	expectedChanges := map[string]PropertyChange{
+		"type":  {From: "string"},
+		"const": {To: v11},
+	}
+	if len(typeLineage) != 2 || typeLineage[0].SchemaID != base || typeLineage[1].Action != LineageOverridden ||
+		typeLineage[1].Location != "allOf[1].properties.type" || !reflect.DeepEqual(typeLineage[1].Changes, expectedChanges) {
+		t.Errorf("Expected type to be introduced by the base and overridden by v1.1, got %+v", typeLineage)
+	}
+
+	// The payload keeps its type but gains required properties in v1.1
+	payload := lineage.Properties["payload"]
+	if len(payload) != 2 || payload[1].SchemaID != v11 || len(payload[1].Changes) != 1 || payload[1].Changes["required"].From != nil {
+		t.Errorf("Expected v1.1 to add required to payload, got %+v", payload)
+	}
+}
+
+func TestPropertyLineage_ChainedOverride(t *testing.T) {
+	store := NewGtsStore(nil)
+	schemas := map[string]map[string]any{
+		"gts.x.test.lineage.base.v1~": {
+			"properties": map[string]any{"amount": map[string]any{"type": "number", "minimum": 0}},
+		},
+		"gts.x.test.lineage.base.v1~x.test.lineage.mid.v1~": {
+			"allOf": []any{map[string]any{"$ref": "gts://gts.x.test.lineage.base.v1~"}},
+			"properties": map[string]any{
+				"amount": map[string]any{"type": "number", "minimum": 0},
+			},
+		},
+		"gts.x.test.lineage.base.v1~x.test.lineage.mid.v1~x.test.lineage.leaf.v1~": {
+			"allOf": []any{
+				map[string]any{"$ref": "gts://gts.x.test.lineage.base.v1~x.test.lineage.mid.v1~"},
+				map[string]any{"$ref": "gts://gts.x.test.lineage.missing.v1~"},
+			},
+			"properties": map[string]any{
+				"amount": map[string]any{"type": "integer", "minimum": 0, "default": 1},
+			},
+		},
+	}
+	for id, schema := range schemas {
+		if err := store.RegisterSchema(id, schema); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+
+	leaf := "gts.x.test.lineage.base.v1~x.test.lineage.mid.v1~x.test.lineage.leaf.v1~"
+	lineage, err := store.PropertyLineage(leaf)
+	if err != nil {
+		t.Fatalf("PropertyLineage failed: %v", err)
+	}
+	if len(lineage.Layers) != 3 || !reflect.DeepEqual(lineage.UnresolvedRefs, []string{"gts.x.test.lineage.missing.v1~"}) {
+		t.Errorf("Expected three layers and one unresolved reference, got %+v", lineage)
+	}
+
+	// The identical restatement in mid is not an override
+	expected := []PropertyProvenance{
+		{SchemaID: "gts.x.test.lineage.base.v1~", Location: "properties.amount", Action: LineageIntroduced},
+		{SchemaID: leaf, Location: "properties.amount", Action: LineageOverridden, Changes: map[string]PropertyChange{
+			"type":    {From: "number", To: "integer"},
+			"default": {To: 1},
+		}},
+	}
+	if !reflect.DeepEqual(lineage.Properties["amount"], expected) {
+		t.Errorf("Expected amount to be introduced by base and overridden by leaf, got %+v", lineage.Properties["amount"])
+	}
+}
+
+func TestPropertyLineage_NotASchema(t *testing.T) {
+	store := NewGtsStore(nil)
+	if err := store.Register(NewJsonEntity(map[string]any{"id": "gts.x.test.lineage.base.v1~x.test._.item.v1"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+	var notFound *StoreGtsSchemaNotFoundError
+	for _, id := range []string{"gts.x.test.lineage.missing.v1~", "gts.x.test.lineage.base.v1~x.test._.item.v1"} {
+		if _, err := store.PropertyLineage(id); !errors.As(err, &notFound) {
+			t.Errorf("Expected StoreGtsSchemaNotFoundError for %s, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ListKind selects the kind of entities ListFiltered returns
+type ListKind string
+
+const (
+	// ListAll lists schemas and instances
+	ListAll ListKind = ""
+	// ListSchemas lists schemas only
+	ListSchemas ListKind = "schema"
+	// ListInstances lists instances only
+	ListInstances ListKind = "instance"
+)
+
+// ParseListKind parses a list kind name: all (or empty), schema or instance, in singular or plural
+func ParseListKind(s string) (ListKind, error) {
+	switch strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "s") {
+	case "", "all":
+		return ListAll, nil
+	case "schema":
+		return ListSchemas, nil
+	case "instance":
+		return ListInstances, nil
+	default:
+		return ListAll, fmt.Errorf("invalid list kind '%s' (expected all, schema or instance)", s)
+	}
+}
+
+// ListOptions selects the entities of ListFiltered and the page to return
+type ListOptions struct {
+	// Limit and Cursor select the page as for ListPage
+	Limit  int
+	Cursor string
+	Kind   ListKind
+	// Pattern is a GTS ID or wildcard pattern, e.g. "gts.x.commerce.*", matched as by Query
+	Pattern string
+	// Orphans lists only the instances whose schema is not registered: the schema named by their
+	// chained ID or schema ID field, or its latest minor version, is missing
+	Orphans bool
+}
+
+// ListFiltered returns a page of the entities selected by opts in GTS ID order, like ListPage.
+// Total counts the selected entities, so that clients know how many pages follow. An invalid
+// pattern fails with InvalidQueryError.
+func (s *GtsStore) ListFiltered(opts ListOptions) (*ListResult, error) {
+	var patternID *GtsID
+	if opts.Pattern != "" {
+		pattern := s.lenientQueryPattern(strings.TrimSpace(opts.Pattern))
+		if err := s.validateQueryPattern(pattern, strings.Contains(pattern, "*")); err != nil {
+			return nil, err
+		}
+		parsed, err := parsePattern(pattern)
+		if err != nil {
+			return nil, newInvalidQueryError(err)
+		}
+		patternID = parsed
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	for _, id := range s.sortedIDs() {
+		entity := s.byID[id]
+		if !opts.selects(entity) || (patternID != nil && !s.matchesIDPattern(entity.GtsID, patternID)) {
+			continue
+		}
+		if opts.Orphans && (entity.IsSchema || (entity.SchemaID != "" && s.lookupSchemaLocked(entity.SchemaID) != nil)) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	start := 0
+	if opts.Cursor != "" {
+		start = sort.Search(len(ids), func(i int) bool { return ids[i] > opts.Cursor })
+	}
+	end := min(start+max(opts.Limit, 0), len(ids))
+	result := &ListResult{Entities: make([]EntityInfo, 0, end-start), Total: len(ids)}
+	for _, id := range ids[start:end] {
+		result.Entities = append(result.Entities, s.entityInfoLocked(s.byID[id]))
+	}
+	result.Count = len(result.Entities)
+	if end < len(ids) && end > start {
+		result.NextCursor = ids[end-1]
+	}
+	return result, nil
+}
+
+// selects reports whether an entity is of the kind the options select
+func (opts ListOptions) selects(entity *JsonEntity) bool {
+	switch opts.Kind {
+	case ListSchemas:
+		return entity.IsSchema
+	case ListInstances:
+		return !entity.IsSchema
+	default:
+		return true
+	}
+}
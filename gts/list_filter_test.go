@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestListFiltered(t *testing.T) {
+	store := NewGtsStore(nil)
+	for _, id := range []string{"gts.x.commerce.orders.order.v1~", "gts.x.commerce.orders.order.v1.1~", "gts.x.core.events.type.v1~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+	for _, content := range []map[string]any{
+		{"id": "gts.x.commerce.orders.order.v1~x.shop._.o1.v1"},
+		{"id": "gts.x.commerce.orders.order.v1~x.shop._.o2.v1"},
+		{"id": "gts.x.core.events.type.v1~x.shop._.e1.v1"},
+		{"id": "gts.x.core.config.setting.v1~x.shop._.s1.v1"},
+	} {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %v: %v", content, err)
+		}
+	}
+
+	ids := func(opts ListOptions) ([]string, *ListResult) {
+		t.Helper()
+		if opts.Limit == 0 {
+			opts.Limit = 100
+		}
+		result, err := store.ListFiltered(opts)
+		if err != nil {
+			t.Fatalf("ListFiltered(%+v) failed: %v", opts, err)
+		}
+		var listed []string
+		for _, info := range result.Entities {
+			listed = append(listed, info.ID)
+		}
+		return listed, result
+	}
+
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected []string
+	}{
+		{"schemas of a package", ListOptions{Kind: ListSchemas, Pattern: "gts.x.commerce.*"}, []string{"gts.x.commerce.orders.order.v1.1~", "gts.x.commerce.orders.order.v1~"}},
+		{"instances of a type", ListOptions{Kind: ListInstances, Pattern: "gts.x.commerce.orders.order.v1~*"}, []string{"gts.x.commerce.orders.order.v1~x.shop._.o1.v1", "gts.x.commerce.orders.order.v1~x.shop._.o2.v1"}},
+		{"type ID", ListOptions{Pattern: "gts.x.core.events.type.v1~"}, []string{"gts.x.core.events.type.v1~", "gts.x.core.events.type.v1~x.shop._.e1.v1"}},
+		{"orphans", ListOptions{Orphans: true}, []string{"gts.x.core.config.setting.v1~x.shop._.s1.v1"}},
+		{"orphan schemas", ListOptions{Kind: ListSchemas, Orphans: true}, nil},
+	}
+	for _, tt := range tests {
+		if listed, _ := ids(tt.opts); !reflect.DeepEqual(listed, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, listed)
+		}
+	}
+
+	// Total counts the filtered entities before the limit
+	listed, page := ids(ListOptions{Kind: ListInstances, Limit: 2})
+	if page.Total != 4 || page.Count != 2 || page.NextCursor != listed[1] {
+		t.Errorf("Unexpected first page %+v", page)
+	}
+	if _, next := ids(ListOptions{Kind: ListInstances, Limit: 2, Cursor: page.NextCursor}); next.Total != 4 || next.Count != 2 || next.NextCursor != "" {
+		t.Errorf("Unexpected last page %+v", next)
+	}
+
+	var invalid *InvalidQueryError
+	if _, err := store.ListFiltered(ListOptions{Limit: 10, Pattern: "gts.x.*.bad"}); !errors.As(err, &invalid) {
+		t.Errorf("Expected InvalidQueryError, got %v", err)
+	}
+}
+
+func TestParseListKind(t *testing.T) {
+	for name, expected := range map[string]ListKind{"": ListAll, "all": ListAll, "schema": ListSchemas, "Schemas": ListSchemas, "instances": ListInstances} {
+		if kind, err := ParseListKind(name); err != nil || kind != expected {
+			t.Errorf("ParseListKind(%q) = %q, %v", name, kind, err)
+		}
+	}
+	if _, err := ParseListKind("types"); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+}
@@ -36,17 +36,7 @@ func (e *InvalidWildcardError) Error() string {
 // or Match=false with an optional Error message on failure or mismatch
 func MatchIDPattern(candidate, pattern string) MatchIDResult {
 	// Parse candidate - it can be either a regular GTS ID or a wildcard pattern
-	var candidateID *GtsID
-	var err error
-
-	if strings.Contains(candidate, "*") {
-		// Candidate contains wildcard, validate it as a wildcard pattern
-		candidateID, err = validateWildcard(candidate)
-	} else {
-		// Candidate is a regular GTS ID
-		candidateID, err = NewGtsID(candidate)
-	}
-
+	candidateID, err := parseMatchCandidate(candidate)
 	if err != nil {
 		return MatchIDResult{
 			Candidate: candidate,
@@ -56,8 +46,8 @@ func MatchIDPattern(candidate, pattern string) MatchIDResult {
 		}
 	}
 
-	// Validate and parse pattern
-	patternID, err := validateWildcard(pattern)
+	// Validate and parse pattern (parsed patterns are cached and shared)
+	patternID, err := parsePattern(pattern)
 	if err != nil {
 		return MatchIDResult{
 			Candidate: candidate,
@@ -68,7 +58,7 @@ func MatchIDPattern(candidate, pattern string) MatchIDResult {
 	}
 
 	// Perform matching
-	match := wildcardMatch(candidateID, patternID)
+	match := MatchParsedIDPattern(candidateID, patternID)
 
 	return MatchIDResult{
 		Candidate: candidate,
@@ -78,8 +68,20 @@ func MatchIDPattern(candidate, pattern string) MatchIDResult {
 	}
 }
 
-// validateWildcard validates a wildcard pattern and returns a parsed GtsID
+// MatchParsedIDPattern matches an already parsed candidate against an already parsed pattern
+// This avoids re-parsing when the caller holds parsed IDs, e.g. entities registered in a store.
+// Both arguments are treated as read-only.
+func MatchParsedIDPattern(candidate, pattern *GtsID) bool {
+	return wildcardMatch(candidate, pattern)
+}
+
+// validateWildcard validates a wildcard pattern against the process-wide limits and returns a parsed GtsID
 func validateWildcard(pattern string) (*GtsID, error) {
+	return validateWildcardWithLimits(pattern, currentLimits())
+}
+
+// validateWildcardWithLimits validates a wildcard pattern and returns a parsed GtsID
+func validateWildcardWithLimits(pattern string, limits idLimits) (*GtsID, error) {
 	p := strings.TrimSpace(pattern)
 
 	// Must start with gts.
@@ -116,7 +118,7 @@ func validateWildcard(pattern string) (*GtsID, error) {
 
 	// Try to parse the base pattern (without wildcard) using standard validation
 	// but skip single-segment instance check for wildcards
-	_, err := validateWildcardBase(tempPattern)
+	_, err := validateWildcardBase(tempPattern, limits)
 	if err != nil {
 		return nil, &InvalidWildcardError{
 			Pattern: pattern,
@@ -125,7 +127,7 @@ func validateWildcard(pattern string) (*GtsID, error) {
 	}
 
 	// Now parse the full wildcard pattern with relaxed validation
-	id, err := parseWildcardGtsID(p)
+	id, err := parseWildcardGtsID(p, limits)
 	if err != nil {
 		return nil, &InvalidWildcardError{
 			Pattern: pattern,
@@ -137,7 +139,7 @@ func validateWildcard(pattern string) (*GtsID, error) {
 }
 
 // validateWildcardBase validates the base pattern (without wildcards) with relaxed rules
-func validateWildcardBase(basePattern string) (*GtsID, error) {
+func validateWildcardBase(basePattern string, limits idLimits) (*GtsID, error) {
 	if basePattern == "" {
 		return nil, fmt.Errorf("empty base pattern")
 	}
@@ -153,8 +155,8 @@ func validateWildcardBase(basePattern string) (*GtsID, error) {
 	}
 
 	// Length validation
-	if len(basePattern) > MaxIDLength {
-		return nil, fmt.Errorf("too long")
+	if limits.maxLength > 0 && len(basePattern) > limits.maxLength {
+		return nil, fmt.Errorf("too long: %d characters exceeds the limit of %d", len(basePattern), limits.maxLength)
 	}
 
 	// Lowercase validation
@@ -173,7 +175,7 @@ func validateWildcardBase(basePattern string) (*GtsID, error) {
 }
 
 // parseWildcardGtsID parses a wildcard GTS ID with relaxed validation rules
-func parseWildcardGtsID(id string) (*GtsID, error) {
+func parseWildcardGtsID(id string, limits idLimits) (*GtsID, error) {
 	raw := strings.TrimSpace(id)
 
 	// Basic validation (same as NewGtsID but skip single-segment check)
@@ -189,8 +191,8 @@ func parseWildcardGtsID(id string) (*GtsID, error) {
 		return nil, &InvalidGtsIDError{GtsID: id, Cause: fmt.Sprintf("Does not start with '%s'", GtsPrefix)}
 	}
 
-	if len(raw) > MaxIDLength {
-		return nil, &InvalidGtsIDError{GtsID: id, Cause: "Too long"}
+	if err := limits.checkLength(id, len(raw)); err != nil {
+		return nil, err
 	}
 
 	gtsID := &GtsID{
@@ -201,6 +203,9 @@ func parseWildcardGtsID(id string) (*GtsID, error) {
 	// Split by ~ to get segments, preserving empties to detect trailing ~
 	remainder := raw[len(GtsPrefix):]
 	parts := splitPreservingTilde(remainder)
+	if err := limits.checkSegments(id, len(parts)); err != nil {
+		return nil, err
+	}
 
 	offset := len(GtsPrefix)
 	for i, part := range parts {
@@ -0,0 +1,219 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"strings"
+	"sync"
+)
+
+// MultiMatchResult represents the result of matching a GTS identifier against several patterns
+type MultiMatchResult struct {
+	Candidate string `json:"candidate"`
+	// Results holds the outcome of every pattern in the order given; an invalid pattern has its Error set
+	Results []MatchIDResult `json:"results"`
+	// Matched lists the matching patterns from the most to the least specific
+	Matched []string `json:"matched"`
+	// BestMatch is the most specific matching pattern, empty when none matches
+	BestMatch string `json:"best_match,omitempty"`
+	// Error is set when the candidate is invalid, in which case no pattern is evaluated
+	Error string `json:"error,omitempty"`
+}
+
+// MatchIDPatterns matches a candidate GTS identifier against every pattern, as MatchIDPattern
+// does, and identifies the most specific match. Patterns are ranked by, in order:
+//
+//   - an exact pattern, equal to the candidate
+//   - the number of segments of the pattern, not counting a bare "*" segment
+//   - the number of segments with an explicit minor version
+//   - the position of the wildcard: a pattern without wildcard, then the later the wildcard the
+//     more specific, so that "gts.*" comes last
+//
+// Equally specific patterns keep the order given. Invalid patterns are reported in their result
+// and do not fail the others.
+func MatchIDPatterns(candidate string, patterns []string) *MultiMatchResult {
+	set := &PatternSet{}
+	parsed := make([]*setPattern, len(patterns))
+	errs := make([]error, len(patterns))
+	for i, pattern := range patterns {
+		parsed[i], errs[i] = newSetPattern(pattern)
+	}
+	set.patterns = parsed
+
+	result := set.matchParsed(candidate)
+	if result.Error != "" {
+		return result
+	}
+	for i, err := range errs {
+		if err != nil {
+			result.Results[i].Pattern = patterns[i]
+			result.Results[i].Error = err.Error()
+		}
+	}
+	return result
+}
+
+// PatternSet is a set of validated GTS patterns matched together, for consumers that match many
+// candidates against the same patterns: each pattern is parsed once when added. Match ranks the
+// matching patterns like MatchIDPatterns. A PatternSet is safe for concurrent use; the zero value
+// is an empty set.
+type PatternSet struct {
+	mu       sync.RWMutex
+	patterns []*setPattern
+}
+
+// setPattern is a parsed pattern of a set and its specificity
+type setPattern struct {
+	pattern string
+	id      *GtsID
+	rank    patternRank
+}
+
+// patternRank is the specificity of a pattern, compared field by field, see MatchIDPatterns
+type patternRank struct {
+	segments int
+	minors   int
+	// wildcardAt is the offset of the wildcard, or past the end of the pattern without one
+	wildcardAt int
+}
+
+// NewPatternSet creates a pattern set holding patterns; it fails on the first invalid pattern
+func NewPatternSet(patterns ...string) (*PatternSet, error) {
+	set := &PatternSet{}
+	for _, pattern := range patterns {
+		if err := set.Add(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// Add validates and adds a pattern to the set; adding a pattern already in the set does nothing
+func (ps *PatternSet) Add(pattern string) error {
+	parsed, err := newSetPattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, existing := range ps.patterns {
+		if existing.pattern == pattern {
+			return nil
+		}
+	}
+	ps.patterns = append(ps.patterns, parsed)
+	return nil
+}
+
+// Patterns returns the patterns of the set in the order they were added
+func (ps *PatternSet) Patterns() []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	patterns := make([]string, len(ps.patterns))
+	for i, p := range ps.patterns {
+		patterns[i] = p.pattern
+	}
+	return patterns
+}
+
+// Match matches a candidate GTS identifier against the patterns of the set, in the order they
+// were added, and identifies the most specific match
+func (ps *PatternSet) Match(candidate string) *MultiMatchResult {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.matchParsed(candidate)
+}
+
+// matchParsed matches a candidate against the patterns of the set; nil patterns are invalid and
+// reported as not matching. The caller holds ps.mu or owns the set.
+func (ps *PatternSet) matchParsed(candidate string) *MultiMatchResult {
+	result := &MultiMatchResult{
+		Candidate: candidate,
+		Results:   make([]MatchIDResult, len(ps.patterns)),
+		Matched:   []string{},
+	}
+	candidateID, err := parseMatchCandidate(candidate)
+	if err != nil {
+		result.Results = []MatchIDResult{}
+		result.Error = err.Error()
+		return result
+	}
+
+	var matched []*setPattern
+	for i, p := range ps.patterns {
+		result.Results[i] = MatchIDResult{Candidate: candidate}
+		if p == nil {
+			continue
+		}
+		result.Results[i].Pattern = p.pattern
+		if !MatchParsedIDPattern(candidateID, p.id) {
+			continue
+		}
+		result.Results[i].Match = true
+		matched = append(matched, p)
+	}
+
+	// Insertion sort keeps equally specific patterns in the order given
+	for i := 1; i < len(matched); i++ {
+		for j := i; j > 0 && matched[j].moreSpecificThan(matched[j-1], candidateID.ID); j-- {
+			matched[j], matched[j-1] = matched[j-1], matched[j]
+		}
+	}
+	for _, p := range matched {
+		result.Matched = append(result.Matched, p.pattern)
+	}
+	if len(result.Matched) > 0 {
+		result.BestMatch = result.Matched[0]
+	}
+	return result
+}
+
+// newSetPattern parses a pattern and computes its specificity
+func newSetPattern(pattern string) (*setPattern, error) {
+	id, err := parsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := patternRank{wildcardAt: len(id.ID) + 1}
+	if i := strings.Index(id.ID, "*"); i >= 0 {
+		rank.wildcardAt = i
+	}
+	for _, seg := range id.Segments {
+		if seg.Segment == "*" {
+			continue
+		}
+		rank.segments++
+		if seg.VerMinor != nil {
+			rank.minors++
+		}
+	}
+	return &setPattern{pattern: pattern, id: id, rank: rank}, nil
+}
+
+// moreSpecificThan reports whether p ranks strictly above other for a candidate ID
+func (p *setPattern) moreSpecificThan(other *setPattern, candidate string) bool {
+	if exact, otherExact := p.id.ID == candidate, other.id.ID == candidate; exact != otherExact {
+		return exact
+	}
+	switch {
+	case p.rank.segments != other.rank.segments:
+		return p.rank.segments > other.rank.segments
+	case p.rank.minors != other.rank.minors:
+		return p.rank.minors > other.rank.minors
+	default:
+		return p.rank.wildcardAt > other.rank.wildcardAt
+	}
+}
+
+// parseMatchCandidate parses the candidate of a match, which may itself be a wildcard pattern
+func parseMatchCandidate(candidate string) (*GtsID, error) {
+	if strings.Contains(candidate, "*") {
+		return validateWildcard(candidate)
+	}
+	return NewGtsID(candidate)
+}
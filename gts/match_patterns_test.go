@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMatchIDPatterns(t *testing.T) {
+	const candidate = "gts.x.core.events.type.v1~x.shop.orders.placed.v1.2"
+	patterns := []string{
+		"gts.*",
+		"gts.x.core.events.type.v1~*",
+		"gts.x.core.*",
+		"gts.x.core.events.type.v1~x.shop.*",
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1",
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1.2",
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1.*",
+		"gts.y.*",
+		"gts.X.*",
+	}
+
+	result := MatchIDPatterns(candidate, patterns)
+	expected := []string{
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1.2",
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1",
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1.*",
+		"gts.x.core.events.type.v1~x.shop.*",
+		"gts.x.core.events.type.v1~*",
+		"gts.x.core.*",
+		"gts.*",
+	}
+	if !reflect.DeepEqual(result.Matched, expected) {
+		t.Errorf("Expected matches %v, got %v", expected, result.Matched)
+	}
+	if result.BestMatch != expected[0] {
+		t.Errorf("Expected best match %s, got %s", expected[0], result.BestMatch)
+	}
+	if len(result.Results) != len(patterns) {
+		t.Fatalf("Expected a result per pattern, got %d", len(result.Results))
+	}
+	if r := result.Results[7]; r.Match || r.Error != "" {
+		t.Errorf("Expected gts.y.* not to match without error, got %+v", r)
+	}
+	if r := result.Results[8]; r.Match || r.Error == "" || r.Pattern != "gts.X.*" {
+		t.Errorf("Expected an error for the invalid pattern, got %+v", r)
+	}
+
+	// Without an exact pattern a chain with an explicit minor ranks first
+	result = MatchIDPatterns("gts.x.core.events.type.v1.0~x.shop.orders.placed.v1.3", []string{
+		"gts.x.core.events.type.v1~x.shop.orders.placed.v1",
+		"gts.x.core.events.type.v1.0~x.shop.orders.placed.v1",
+	})
+	if result.BestMatch != "gts.x.core.events.type.v1.0~x.shop.orders.placed.v1" {
+		t.Errorf("Expected the pattern with an explicit minor, got %v", result.Matched)
+	}
+
+	result = MatchIDPatterns("gts.bad", patterns)
+	if result.Error == "" || len(result.Matched) != 0 {
+		t.Errorf("Expected an error for an invalid candidate, got %+v", result)
+	}
+}
+
+func TestPatternSet(t *testing.T) {
+	set, err := NewPatternSet("gts.*", "gts.x.core.events.type.v1~*")
+	if err != nil {
+		t.Fatalf("NewPatternSet failed: %v", err)
+	}
+	if err := set.Add("gts.x.*.bad"); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if err := set.Add("gts.*"); err != nil {
+		t.Errorf("Expected a duplicate pattern to be ignored, got %v", err)
+	}
+	if patterns := set.Patterns(); len(patterns) != 2 {
+		t.Errorf("Expected 2 patterns, got %v", patterns)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result := set.Match("gts.x.core.events.type.v1~x.shop.orders.placed.v1"); result.BestMatch != "gts.x.core.events.type.v1~*" {
+				t.Errorf("Expected the type pattern to be the best match, got %+v", result)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if result := (&PatternSet{}).Match("gts.x.core.events.type.v1~"); result.BestMatch != "" || len(result.Results) != 0 {
+		t.Errorf("Expected no match in an empty set, got %+v", result)
+	}
+}
@@ -5,7 +5,42 @@ Released under Apache License 2.0
 
 package gts
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
+
+// assertParsedEquivalent checks that matching pre-parsed IDs with MatchParsedIDPattern
+// gives the same answer as the string based MatchIDPattern
+func assertParsedEquivalent(t *testing.T, candidate, pattern string, want MatchIDResult) {
+	t.Helper()
+
+	var candidateID *GtsID
+	var err error
+	if strings.Contains(candidate, "*") {
+		candidateID, err = validateWildcard(candidate)
+	} else {
+		candidateID, err = NewGtsID(candidate)
+	}
+	if err != nil {
+		if want.Match || want.Error == "" {
+			t.Errorf("Candidate %q failed to parse (%v) but MatchIDPattern reported %+v", candidate, err, want)
+		}
+		return
+	}
+
+	patternID, err := parsePattern(pattern)
+	if err != nil {
+		if want.Match || want.Error == "" {
+			t.Errorf("Pattern %q failed to parse (%v) but MatchIDPattern reported %+v", pattern, err, want)
+		}
+		return
+	}
+
+	if got := MatchParsedIDPattern(candidateID, patternID); got != want.Match {
+		t.Errorf("MatchParsedIDPattern(%q, %q) = %v, MatchIDPattern = %v", candidate, pattern, got, want.Match)
+	}
+}
 
 // TestMatchIDPattern_Positive1 tests basic wildcard matching with chained identifiers
 func TestMatchIDPattern_Positive1(t *testing.T) {
@@ -13,6 +48,7 @@ func TestMatchIDPattern_Positive1(t *testing.T) {
 		"gts.x.test4.events.type.v1~abc.app._.custom_event.v1.2",
 		"gts.x.test4.events.type.v1~abc.*",
 	)
+	assertParsedEquivalent(t, "gts.x.test4.events.type.v1~abc.app._.custom_event.v1.2", "gts.x.test4.events.type.v1~abc.*", result)
 
 	if !result.Match {
 		t.Errorf("Expected match=true, got match=false with error: %s", result.Error)
@@ -48,6 +84,7 @@ func TestMatchIDPattern_Positive2(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v (error: %s)", tt.match, result.Match, result.Error)
@@ -81,6 +118,7 @@ func TestMatchIDPattern_Positive3(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v (error: %s)", tt.match, result.Match, result.Error)
@@ -132,6 +170,7 @@ func TestMatchIDPattern_VersionWildcards(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v (error: %s)", tt.match, result.Match, result.Error)
@@ -168,6 +207,7 @@ func TestMatchIDPattern_ChainedPatterns(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v", tt.match, result.Match)
@@ -219,6 +259,7 @@ func TestMatchIDPattern_MultiLevelWildcards(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v", tt.match, result.Match)
@@ -262,6 +303,7 @@ func TestMatchIDPattern_Negative(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match {
 				t.Errorf("Expected match=false, got match=true")
@@ -297,6 +339,7 @@ func TestMatchIDPattern_Invalid(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match {
 				t.Errorf("Expected match=false for invalid pattern")
@@ -352,6 +395,7 @@ func TestMatchIDPattern_ExactMatch(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v (error: %s)", tt.match, result.Match, result.Error)
@@ -397,6 +441,7 @@ func TestMatchIDPattern_WildcardValidation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern("gts.vendor.pkg.ns.type.v1~", tt.pattern)
+			assertParsedEquivalent(t, "gts.vendor.pkg.ns.type.v1~", tt.pattern, result)
 
 			if tt.expectError && result.Error == "" {
 				t.Error("Expected error for invalid pattern but got none")
@@ -446,6 +491,7 @@ func TestMatchIDPattern_ChainedIdentifiers(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match != tt.match {
 				t.Errorf("Expected match=%v, got match=%v (error: %s)", tt.match, result.Match, result.Error)
@@ -481,6 +527,7 @@ func TestMatchIDPattern_InvalidCandidate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MatchIDPattern(tt.candidate, tt.pattern)
+			assertParsedEquivalent(t, tt.candidate, tt.pattern, result)
 
 			if result.Match {
 				t.Error("Expected match=false for invalid candidate")
@@ -539,3 +586,39 @@ func TestWildcardMatch_DirectCall(t *testing.T) {
 		})
 	}
 }
+
+// TestPatternCache_Eviction tests that the pattern cache stays within its capacity
+func TestPatternCache_Eviction(t *testing.T) {
+	cache := newPatternCache(2)
+
+	first, err := cache.get("gts.vendor.pkg.ns.type.v1~*")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again, _ := cache.get("gts.vendor.pkg.ns.type.v1~*"); again != first {
+		t.Error("Expected cached pattern to be returned on second lookup")
+	}
+
+	cache.get("gts.vendor.pkg.ns.*")
+	cache.get("gts.vendor.pkg.*")
+	if cache.len() != 2 {
+		t.Errorf("Expected cache to hold 2 patterns, got %d", cache.len())
+	}
+
+	// The least recently used pattern was evicted and is parsed again
+	if again, _ := cache.get("gts.vendor.pkg.ns.type.v1~*"); again == first {
+		t.Error("Expected evicted pattern to be re-parsed")
+	}
+}
+
+// TestPatternCache_InvalidPattern tests that parse errors are cached and returned consistently
+func TestPatternCache_InvalidPattern(t *testing.T) {
+	cache := newPatternCache(4)
+
+	for i := 0; i < 2; i++ {
+		id, err := cache.get("gts.vendor.*.ns.type.v1~")
+		if err == nil || id != nil {
+			t.Errorf("Expected error for invalid pattern, got id=%v err=%v", id, err)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ConflictPolicy decides which entity Merge keeps when both stores hold the same ID
+type ConflictPolicy int
+
+const (
+	// ConflictKeepExisting keeps the entity of the store merged into
+	ConflictKeepExisting ConflictPolicy = iota
+	// ConflictOverwrite replaces the entity with the one of the merged store
+	ConflictOverwrite
+	// ConflictKeepNewer keeps the entity updated last; ties keep the existing entity
+	ConflictKeepNewer
+	// ConflictFail aborts the merge, changing nothing, when an ID has different content in the two stores
+	ConflictFail
+)
+
+// String returns the textual name of the policy
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictOverwrite:
+		return "overwrite"
+	case ConflictKeepNewer:
+		return "keep-newer"
+	case ConflictFail:
+		return "fail"
+	default:
+		return "keep-existing"
+	}
+}
+
+// ParseConflictPolicy parses "keep-existing", "overwrite", "keep-newer" or "fail" into a ConflictPolicy
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "keep-existing":
+		return ConflictKeepExisting, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	case "keep-newer":
+		return ConflictKeepNewer, nil
+	case "fail":
+		return ConflictFail, nil
+	default:
+		return ConflictKeepExisting, fmt.Errorf("invalid conflict policy '%s' (expected keep-existing, overwrite, keep-newer or fail)", s)
+	}
+}
+
+// Resolutions of the collisions reported by Merge
+const (
+	// MergeKept means the entity of the store merged into was kept
+	MergeKept = "kept"
+	// MergeReplaced means the entity was replaced with the one of the merged store
+	MergeReplaced = "replaced"
+)
+
+// MergeReport is the outcome of Merge
+type MergeReport struct {
+	Policy string `json:"policy"`
+	// Added is the number of entities copied under IDs the store did not hold
+	Added int `json:"added"`
+	// Replaced and Kept count the collisions by resolution
+	Replaced int `json:"replaced"`
+	Kept     int `json:"kept"`
+	// Collisions lists every ID held by both stores, sorted by ID
+	Collisions []MergeCollision `json:"collisions"`
+}
+
+// MergeCollision is an ID held by both stores
+type MergeCollision struct {
+	ID string `json:"id"`
+	// Identical is set when both entities have the same content
+	Identical  bool   `json:"identical"`
+	Resolution string `json:"resolution"`
+}
+
+// MergeConflictError is returned by Merge with ConflictFail when IDs have different content in
+// the two stores
+type MergeConflictError struct {
+	IDs []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("Merge conflict: %d ID(s) have different content in the two stores: %s", len(e.IDs), strings.Join(e.IDs, ", "))
+}
+
+// mergedEntity is an entity of the merged store with its tags
+type mergedEntity struct {
+	entity *JsonEntity
+	tags   map[string]string
+}
+
+// Merge copies every entity of other into the store with its tags, timestamps and source file,
+// resolving the IDs held by both stores with policy and reporting each of them. Entities are
+// copied, so the two stores do not share them afterwards, and written through to the writer of a
+// store created with NewGtsStoreWithPersistence. Merge fails without changing the store when it is
+// frozen, when policy is ConflictFail and an ID has different content, when a copied ID has the
+// short ID of another registered ID, or when the writer fails (StorePersistenceError), in which
+// case the entities already written are rolled back. In RefValidationWarn mode the references of every
+// entity are re-checked afterwards, as merged entities may resolve references of either store.
+func (s *GtsStore) Merge(other *GtsStore, policy ConflictPolicy) (*MergeReport, error) {
+	report := &MergeReport{Policy: policy.String(), Collisions: []MergeCollision{}}
+	if other == nil || other == s {
+		return report, nil
+	}
+
+	// Snapshot the other store first so that the two stores are never locked together
+	other.mu.RLock()
+	incoming := make([]mergedEntity, 0, len(other.byID))
+	for _, entity := range other.byID {
+		copied := *entity
+		incoming = append(incoming, mergedEntity{entity: &copied, tags: copyTags(other.tags[entity.GtsID.ID])})
+	}
+	other.mu.RUnlock()
+	sort.Slice(incoming, func(i, j int) bool { return incoming[i].entity.GtsID.ID < incoming[j].entity.GtsID.ID })
+
+	s.mu.Lock()
+	if s.frozen {
+		s.mu.Unlock()
+		return nil, &StoreFrozenError{Operation: "merge"}
+	}
+
+	var conflicts []string
+	pending := make(map[string]string)
+	replace := make([]bool, len(incoming))
+	for i, item := range incoming {
+		id := item.entity.GtsID.ID
+		existing, ok := s.byID[id]
+		if !ok {
+			if err := s.checkShortIDLocked(id, pending); err != nil {
+				s.mu.Unlock()
+				return nil, err
+			}
+			pending[shortIDOf(id)] = id
+			replace[i] = true
+			continue
+		}
+
+		collision := MergeCollision{ID: id, Identical: reflect.DeepEqual(existing.Content, item.entity.Content), Resolution: MergeKept}
+		switch policy {
+		case ConflictOverwrite:
+			replace[i] = true
+		case ConflictKeepNewer:
+			replace[i] = item.entity.UpdatedAt.After(existing.UpdatedAt)
+		case ConflictFail:
+			if !collision.Identical {
+				conflicts = append(conflicts, id)
+			}
+		}
+		if replace[i] {
+			collision.Resolution = MergeReplaced
+			report.Replaced++
+		} else {
+			report.Kept++
+		}
+		report.Collisions = append(report.Collisions, collision)
+	}
+	if len(conflicts) > 0 {
+		s.mu.Unlock()
+		return nil, &MergeConflictError{IDs: conflicts}
+	}
+
+	// Write the copied entities through first, so that a writer failure leaves the store unchanged
+	var copied []*JsonEntity
+	for i, item := range incoming {
+		if replace[i] {
+			copied = append(copied, item.entity)
+		}
+	}
+	if err := s.persistBatchLocked(copied); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	for i, item := range incoming {
+		if !replace[i] {
+			continue
+		}
+		id := item.entity.GtsID.ID
+		if _, ok := s.byID[id]; !ok {
+			report.Added++
+		}
+		// The copy keeps the timestamps of the merged store
+		s.storeLocked(item.entity)
+		if len(item.tags) > 0 {
+			s.tags[id] = item.tags
+		} else {
+			delete(s.tags, id)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.config.refValidationMode() == RefValidationWarn {
+		s.RecheckReferences()
+	}
+	return report, nil
+}
+
+// NewGtsStoreFromReaders creates a store populated from several readers at once. The readers are
+// drained concurrently by up to parallelism workers, one reader per worker at a time (parallelism
+// below 1 uses GOMAXPROCS); their entities are then loaded in reader order, so that an ID returned
+// by several readers ends up as with sequential loading: the last reader wins. References are only
+// validated once every reader is done, so they resolve across readers: in RefValidationWarn mode
+// entities are annotated with their unresolved references, and in RefValidationStrict mode entities
+// with invalid references are dropped and logged. The errors reported by the readers are kept in
+// reader order (see LoadErrors). The store keeps no reader for later lookups.
+func NewGtsStoreFromReaders(readers []GtsReader, config *RegistryConfig, parallelism int) *GtsStore {
+	store := NewGtsStoreWithConfig(nil, config)
+	if parallelism < 1 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+
+	// Phase 1: drain the readers; each reader is only ever used by one goroutine
+	batches := make([][]*JsonEntity, len(readers))
+	workers := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, reader := range readers {
+		if reader == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, reader GtsReader) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			for entity := reader.Next(); entity != nil; entity = reader.Next() {
+				if entity.GtsID != nil && entity.GtsID.ID != "" {
+					batches[i] = append(batches[i], entity)
+				}
+			}
+		}(i, reader)
+	}
+	wg.Wait()
+	for _, reader := range readers {
+		if reader != nil {
+			store.collectLoadErrors(reader)
+		}
+	}
+
+	// Phase 2: load in reader order
+	store.mu.Lock()
+	for _, batch := range batches {
+		for _, entity := range batch {
+			if err := store.checkShortIDLocked(entity.GtsID.ID, nil); err != nil {
+				log.Printf("ERROR: skipping %s: %v", entity.GtsID.ID, err)
+				continue
+			}
+			store.loadLocked(entity)
+		}
+	}
+	store.mu.Unlock()
+
+	// Phase 3: validate references against everything loaded
+	store.validateLoadedReferences()
+
+	log.Printf("Populated GtsStore with %d entities from %d readers", store.Count(), len(readers))
+	return store
+}
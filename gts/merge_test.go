@@ -0,0 +1,252 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+const (
+	mergeTestSchemaID = "gts.x.test.merge.order.v1~"
+	mergeTestShared   = "gts.x.test.merge.order.v1~x.test._.shared.v1"
+	mergeTestOnlyB    = "gts.x.test.merge.order.v1~x.test._.only_b.v1"
+)
+
+// newMergeTestStore registers the order schema and the shared instance with the given status at
+// the given time, tagged with owner
+func newMergeTestStore(t *testing.T, at time.Time, status, owner string) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	store.now = func() time.Time { return at }
+	registerTestEntities(t, store, testSchema(mergeTestSchemaID, nil), map[string]any{"id": mergeTestShared, "status": status})
+	if err := store.SetTags(mergeTestShared, map[string]string{"owner": owner}); err != nil {
+		t.Fatalf("Failed to tag entity: %v", err)
+	}
+	return store
+}
+
+func TestMerge_Policies(t *testing.T) {
+	t0 := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	tests := []struct {
+		policy ConflictPolicy
+		status string
+		owner  string
+		shared string
+	}{
+		{ConflictKeepExisting, "active", "team-a", MergeKept},
+		{ConflictOverwrite, "inactive", "team-b", MergeReplaced},
+		{ConflictKeepNewer, "inactive", "team-b", MergeReplaced},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			a := newMergeTestStore(t, t0, "active", "team-a")
+			b := newMergeTestStore(t, t1, "inactive", "team-b")
+			// The schema is identical, but registered later in b
+			if err := b.Register(NewJsonEntity(map[string]any{"id": mergeTestOnlyB}, DefaultGtsConfig())); err != nil {
+				t.Fatalf("Failed to register entity: %v", err)
+			}
+
+			report, err := a.Merge(b, tt.policy)
+			if err != nil {
+				t.Fatalf("Merge failed: %v", err)
+			}
+			schemaResolution := MergeKept
+			if tt.policy != ConflictKeepExisting {
+				schemaResolution = MergeReplaced
+			}
+			expected := []MergeCollision{
+				{ID: mergeTestSchemaID, Identical: true, Resolution: schemaResolution},
+				{ID: mergeTestShared, Identical: false, Resolution: tt.shared},
+			}
+			if report.Added != 1 || !reflect.DeepEqual(report.Collisions, expected) {
+				t.Errorf("Expected one added entity and collisions %+v, got %+v", expected, report)
+			}
+
+			shared := a.Get(mergeTestShared)
+			if shared.Content["status"] != tt.status || a.GetTags(mergeTestShared)["owner"] != tt.owner {
+				t.Errorf("Expected status %s owned by %s, got %v and %v", tt.status, tt.owner, shared.Content, a.GetTags(mergeTestShared))
+			}
+			onlyB := a.Get(mergeTestOnlyB)
+			if onlyB == nil || !onlyB.RegisteredAt.Equal(t1) || onlyB == b.Get(mergeTestOnlyB) {
+				t.Errorf("Expected a copy of %s keeping its timestamps, got %+v", mergeTestOnlyB, onlyB)
+			}
+		})
+	}
+}
+
+func TestMerge_KeepNewerKeepsNewerExisting(t *testing.T) {
+	t0 := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	a := newMergeTestStore(t, t0.Add(time.Hour), "active", "team-a")
+	b := newMergeTestStore(t, t0, "inactive", "team-b")
+
+	report, err := a.Merge(b, ConflictKeepNewer)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if report.Kept != 2 || report.Replaced != 0 || a.Get(mergeTestShared).Content["status"] != "active" {
+		t.Errorf("Expected the newer existing entities to be kept, got %+v", report)
+	}
+}
+
+func TestMerge_FailAndFrozen(t *testing.T) {
+	t0 := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	a := newMergeTestStore(t, t0, "active", "team-a")
+	b := newMergeTestStore(t, t0, "inactive", "team-b")
+	if err := b.Register(NewJsonEntity(map[string]any{"id": mergeTestOnlyB}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+
+	var conflict *MergeConflictError
+	if _, err := a.Merge(b, ConflictFail); !errors.As(err, &conflict) || !reflect.DeepEqual(conflict.IDs, []string{mergeTestShared}) {
+		t.Fatalf("Expected a conflict on %s only, got %v", mergeTestShared, err)
+	}
+	if a.Get(mergeTestOnlyB) != nil || a.Get(mergeTestShared).Content["status"] != "active" {
+		t.Error("Expected a failed merge to leave the store unchanged")
+	}
+
+	// Identical content is not a conflict
+	c := newMergeTestStore(t, t0, "active", "team-c")
+	if report, err := a.Merge(c, ConflictFail); err != nil || report.Kept != 2 {
+		t.Errorf("Expected identical entities to be kept, got %+v and %v", report, err)
+	}
+
+	a.Freeze()
+	var frozen *StoreFrozenError
+	if _, err := a.Merge(b, ConflictOverwrite); !errors.As(err, &frozen) {
+		t.Errorf("Expected StoreFrozenError, got %v", err)
+	}
+}
+
+func TestMerge_Persistence(t *testing.T) {
+	t0 := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	b := newMergeTestStore(t, t0, "inactive", "team-b")
+	if err := b.Register(NewJsonEntity(map[string]any{"id": mergeTestOnlyB}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+
+	dir := t.TempDir()
+	writer, err := NewGtsDirWriter(dir)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	a := NewGtsStoreWithPersistence(nil, writer, nil)
+	if err := a.Register(NewJsonEntity(map[string]any{"$id": "gts://" + mergeTestSchemaID, "type": "object"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	if err := a.Register(NewJsonEntity(map[string]any{"id": mergeTestShared, "status": "active"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+	if _, err := a.Merge(b, ConflictOverwrite); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	// The merged entities are written through, so a reload sees them
+	reloaded := NewGtsStore(NewGtsFileReader([]string{dir}, nil))
+	if reloaded.Get(mergeTestOnlyB) == nil || reloaded.Get(mergeTestShared).Content["status"] != "inactive" {
+		t.Errorf("Expected the merged entities after reload, got %v", reloaded.sortedIDs())
+	}
+
+	// A writer failure leaves the store unchanged
+	failing := NewGtsStoreWithPersistence(nil, failingWriter{}, nil)
+	var persistErr *StorePersistenceError
+	if _, err := failing.Merge(b, ConflictOverwrite); !errors.As(err, &persistErr) {
+		t.Errorf("Expected StorePersistenceError, got %v", err)
+	}
+	if failing.Count() != 0 {
+		t.Errorf("Expected nothing to be merged when the writer fails, got %d entities", failing.Count())
+	}
+}
+
+// writeReaderFixture writes entities as JSON files into a new temporary directory
+func writeReaderFixture(t *testing.T, files map[string]any) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		data, err := json.Marshal(content)
+		if err != nil {
+			t.Fatalf("Failed to encode %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestNewGtsStoreFromReaders(t *testing.T) {
+	schema := func(id string) map[string]any {
+		return map[string]any{"$id": "gts://" + id, "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}
+	}
+	dirs := []string{
+		writeReaderFixture(t, map[string]any{
+			"base.json": schema("gts.x.test.readers.event.v1~"),
+		}),
+		writeReaderFixture(t, map[string]any{
+			"derived.json": map[string]any{
+				"$id":     "gts://gts.x.test.readers.event.v1~x.test.readers.order.v1~",
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"allOf":   []any{map[string]any{"$ref": "gts://gts.x.test.readers.event.v1~"}},
+			},
+			"item.json": map[string]any{"id": "gts.x.test.readers.event.v1~x.test._.dup.v1", "from": "second"},
+		}),
+		writeReaderFixture(t, map[string]any{
+			"items.json": []any{
+				map[string]any{"id": "gts.x.test.readers.event.v1~x.test.readers.order.v1~x.test._.o1.v1"},
+				map[string]any{"id": "gts.x.test.readers.event.v1~x.test._.dup.v1", "from": "third"},
+				map[string]any{"id": "gts.x.test.readers.event.v1~x.test._.dangling.v1", "ref": "gts.x.test.readers.missing.v1~"},
+			},
+		}),
+	}
+	readers := func() []GtsReader {
+		result := make([]GtsReader, len(dirs))
+		for i, dir := range dirs {
+			result[i] = NewGtsFileReaderFromPath(dir, nil)
+		}
+		return result
+	}
+	contents := func(store *GtsStore) map[string]map[string]any {
+		result := make(map[string]map[string]any)
+		for id, entity := range store.Items() {
+			result[id] = entity.Content
+		}
+		return result
+	}
+
+	sequential := NewGtsStore(NewGtsFileReader(dirs, nil))
+	for _, parallelism := range []int{1, 3} {
+		parallel := NewGtsStoreFromReaders(readers(), nil, parallelism)
+		if !reflect.DeepEqual(contents(parallel), contents(sequential)) {
+			ids := make([]string, 0, parallel.Count())
+			for id := range parallel.Items() {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			t.Errorf("Expected parallelism %d to load the sequential result set, got %v", parallelism, ids)
+		}
+	}
+	if dup := sequential.Get("gts.x.test.readers.event.v1~x.test._.dup.v1"); dup == nil || dup.Content["from"] != "third" {
+		t.Errorf("Expected the last reader to win, got %+v", dup)
+	}
+
+	// References resolve across readers once every reader is done
+	warn := NewGtsStoreFromReaders(readers(), &RegistryConfig{RefValidation: RefValidationWarn}, 3)
+	if warn.UnresolvedRefCount() != 1 || len(warn.Get("gts.x.test.readers.event.v1~x.test._.dangling.v1").UnresolvedRefs) != 1 {
+		t.Errorf("Expected only the dangling reference to be unresolved, got %d", warn.UnresolvedRefCount())
+	}
+	strict := NewGtsStoreFromReaders(readers(), &RegistryConfig{RefValidation: RefValidationStrict}, 3)
+	if strict.Count() != sequential.Count()-1 || strict.Get("gts.x.test.readers.event.v1~x.test._.dangling.v1") != nil {
+		t.Errorf("Expected only the dangling entity to be dropped, got %d entities", strict.Count())
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MigrationMapTypeID is the type of the migration maps Cast applies to instances cast across
+// major versions. A migration map is an instance of this type, e.g.
+//
+//	{
+//	  "id": "gts.x.core.migration.map.v1~x.shop.orders.order_v1_v2.v1",
+//	  "from": "gts.x.shop.orders.order.v1~",
+//	  "to": "gts.x.shop.orders.order.v2~",
+//	  "operations": [
+//	    {"op": "rename", "from": "/customer", "path": "/buyer"},
+//	    {"op": "move", "from": "/street", "path": "/address/street"},
+//	    {"op": "set_default", "path": "/currency", "value": "EUR"},
+//	    {"op": "drop", "path": "/legacy_code"}
+//	  ]
+//	}
+const MigrationMapTypeID = "gts.x.core.migration.map.v1~"
+
+// Migration map operations
+const (
+	// MigrationOpRename renames the member at From to the last token of Path, in the same object
+	MigrationOpRename = "rename"
+	// MigrationOpMove moves the value at From to Path, creating missing parent objects
+	MigrationOpMove = "move"
+	// MigrationOpSetDefault sets Path to Value when it is missing, creating missing parent objects
+	MigrationOpSetDefault = "set_default"
+	// MigrationOpDrop removes the member at Path
+	MigrationOpDrop = "drop"
+)
+
+// MigrationOperation is one step of a migration map. Paths are JSON Pointers (RFC 6901) to object
+// members of the instance; an operation whose source is missing is not applied.
+type MigrationOperation struct {
+	Op    string `json:"op"`
+	From  string `json:"from,omitempty"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MigrationMap is a parsed migration map entity (see MigrationMapTypeID). From and To are schema
+// IDs; without a minor version they match every minor version of their major version.
+type MigrationMap struct {
+	ID         string               `json:"id"`
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	Operations []MigrationOperation `json:"operations"`
+}
+
+// StoreGtsMajorCastRequiresMappingError is returned by Cast when the source and target schemas
+// differ in major version and no registered migration map covers the pair
+type StoreGtsMajorCastRequiresMappingError struct {
+	FromID string
+	ToID   string
+}
+
+func (e *StoreGtsMajorCastRequiresMappingError) Error() string {
+	return fmt.Sprintf("Casting from '%s' to '%s' crosses major versions and requires a registered migration map (an instance of %s)",
+		e.FromID, e.ToID, MigrationMapTypeID)
+}
+
+// InvalidMigrationMapError is returned when a migration map entity cannot be parsed or declares
+// an invalid operation
+type InvalidMigrationMapError struct {
+	MapID  string
+	Reason string
+}
+
+func (e *InvalidMigrationMapError) Error() string {
+	return fmt.Sprintf("Invalid migration map '%s': %s", e.MapID, e.Reason)
+}
+
+// ParseMigrationMap parses and checks the content of a migration map entity
+func ParseMigrationMap(entity *JsonEntity) (*MigrationMap, error) {
+	id := entity.Label
+	if entity.GtsID != nil {
+		id = entity.GtsID.ID
+	}
+	invalid := func(format string, args ...any) error {
+		return &InvalidMigrationMapError{MapID: id, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	data, err := json.Marshal(entity.Content)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	m := &MigrationMap{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, invalid("%v", err)
+	}
+	m.ID = id
+
+	for _, endpoint := range []struct{ name, value string }{{"from", m.From}, {"to", m.To}} {
+		if !strings.HasSuffix(endpoint.value, "~") || !IsValidGtsID(endpoint.value) {
+			return nil, invalid("%s must be a schema ID, got '%s'", endpoint.name, endpoint.value)
+		}
+	}
+	for i, op := range m.Operations {
+		if _, err := splitMigrationPointer(op.Path); err != nil {
+			return nil, invalid("operation %d: path: %v", i, err)
+		}
+		switch op.Op {
+		case MigrationOpRename, MigrationOpMove:
+			if _, err := splitMigrationPointer(op.From); err != nil {
+				return nil, invalid("operation %d: from: %v", i, err)
+			}
+			if op.Op == MigrationOpRename && migrationPointerParent(op.From) != migrationPointerParent(op.Path) {
+				return nil, invalid("operation %d: rename must keep '%s' in the same object, use move", i, op.From)
+			}
+		case MigrationOpSetDefault, MigrationOpDrop:
+		default:
+			return nil, invalid("operation %d: unknown op '%s'", i, op.Op)
+		}
+	}
+	return m, nil
+}
+
+// Apply applies the operations of the map to a copy of content, returning the copy and the
+// operations applied
+func (m *MigrationMap) Apply(content map[string]any) (map[string]any, []MigrationOperation) {
+	result := copyMap(content)
+	applied := []MigrationOperation{}
+	for _, op := range m.Operations {
+		if applyMigrationOperation(result, op) {
+			applied = append(applied, op)
+		}
+	}
+	return result, applied
+}
+
+// findMigrationMap returns the registered migration map covering a cast from the schema fromID
+// to the schema toID. Maps naming both schemas exactly are preferred over maps naming their major
+// versions; among equally specific maps the one with the smallest ID is used.
+func (s *GtsStore) findMigrationMap(fromID, toID *GtsID) (*MigrationMap, error) {
+	s.mu.RLock()
+	var candidates []*JsonEntity
+	for id, entity := range s.byID {
+		if !entity.IsSchema && (strings.HasPrefix(id, MigrationMapTypeID) || entity.SchemaID == MigrationMapTypeID) {
+			candidates = append(candidates, entity)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].GtsID.ID < candidates[j].GtsID.ID })
+
+	var found *MigrationMap
+	for _, entity := range candidates {
+		if !migrationMapMatches(entity, fromID, toID) {
+			continue
+		}
+		m, err := ParseMigrationMap(entity)
+		if err != nil {
+			return nil, err
+		}
+		if m.From == fromID.ID && m.To == toID.ID {
+			return m, nil
+		}
+		if found == nil {
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, &StoreGtsMajorCastRequiresMappingError{FromID: fromID.ID, ToID: toID.ID}
+	}
+	return found, nil
+}
+
+// hasMigrationMap reports whether a migration map, valid or not, covers a cast from the schema
+// fromID to the schema toID
+func (s *GtsStore) hasMigrationMap(fromID, toID *GtsID) bool {
+	_, err := s.findMigrationMap(fromID, toID)
+	var mappingErr *StoreGtsMajorCastRequiresMappingError
+	return !errors.As(err, &mappingErr)
+}
+
+// migrationMapMatches reports whether the from and to members of a map entity cover the cast;
+// only matching maps are parsed, so an invalid map for other types is not reported
+func migrationMapMatches(entity *JsonEntity, fromID, toID *GtsID) bool {
+	from, _ := entity.Content["from"].(string)
+	to, _ := entity.Content["to"].(string)
+	fromLine, toLine := majorVersionID(fromID), majorVersionID(toID)
+	return (from == fromID.ID || from == fromLine) && (to == toID.ID || to == toLine)
+}
+
+// majorVersionID returns the ID with the minor version of its last segment removed
+func majorVersionID(id *GtsID) string {
+	last := id.LastSegment()
+	if last.VerMinor == nil {
+		return id.ID
+	}
+	return id.ID[:last.Offset] + formatSegment(last.Vendor, last.Package, last.Namespace, last.Type, last.VerMajor, nil, last.IsType)
+}
+
+// crossesMajorVersion reports whether two schema IDs differ in the major version of their last segment
+func crossesMajorVersion(fromID, toID *GtsID) bool {
+	return fromID.LastSegment().VerMajor != toID.LastSegment().VerMajor
+}
+
+// applyMigrationOperation applies one operation to content, reporting whether it changed anything
+func applyMigrationOperation(content map[string]any, op MigrationOperation) bool {
+	path, _ := splitMigrationPointer(op.Path)
+	switch op.Op {
+	case MigrationOpRename, MigrationOpMove:
+		from, _ := splitMigrationPointer(op.From)
+		value, ok := removeMigrationValue(content, from)
+		if !ok {
+			return false
+		}
+		if !setMigrationValue(content, path, value) {
+			// The target is below a value that is not an object: keep the source
+			setMigrationValue(content, from, value)
+			return false
+		}
+		return true
+	case MigrationOpSetDefault:
+		if _, ok := lookupMigrationValue(content, path); ok {
+			return false
+		}
+		return setMigrationValue(content, path, copyValue(op.Value))
+	case MigrationOpDrop:
+		_, ok := removeMigrationValue(content, path)
+		return ok
+	}
+	return false
+}
+
+// splitMigrationPointer splits a JSON Pointer into its unescaped reference tokens
+func splitMigrationPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("'%s' is not a JSON Pointer to a member", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// migrationPointerParent returns the pointer of the object holding the member a pointer names
+func migrationPointerParent(pointer string) string {
+	return pointer[:strings.LastIndex(pointer, "/")+1]
+}
+
+// migrationParent returns the object holding the member named by tokens, creating missing
+// objects on the way when create is set
+func migrationParent(content map[string]any, tokens []string, create bool) (map[string]any, bool) {
+	current := content
+	for _, token := range tokens[:len(tokens)-1] {
+		next, exists := current[token]
+		if !exists && create {
+			child := map[string]any{}
+			current[token] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current = child
+	}
+	return current, true
+}
+
+func lookupMigrationValue(content map[string]any, tokens []string) (any, bool) {
+	parent, ok := migrationParent(content, tokens, false)
+	if !ok {
+		return nil, false
+	}
+	value, ok := parent[tokens[len(tokens)-1]]
+	return value, ok
+}
+
+func removeMigrationValue(content map[string]any, tokens []string) (any, bool) {
+	parent, ok := migrationParent(content, tokens, false)
+	if !ok {
+		return nil, false
+	}
+	name := tokens[len(tokens)-1]
+	value, ok := parent[name]
+	if ok {
+		delete(parent, name)
+	}
+	return value, ok
+}
+
+func setMigrationValue(content map[string]any, tokens []string, value any) bool {
+	parent, ok := migrationParent(content, tokens, true)
+	if !ok {
+		return false
+	}
+	parent[tokens[len(tokens)-1]] = value
+	return true
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	migrationOrderV1 = "gts.x.test.mig.order.v1.0~"
+	migrationOrderV2 = "gts.x.test.mig.order.v2.0~"
+	migrationOrderID = "gts.x.test.mig.order.v1.0~x.test._.o1.v1"
+)
+
+// newMigrationTestStore registers order v1.0 and v2.0 schemas, where v2 renames customer to buyer,
+// moves street into address, requires currency and no longer has legacy_code, and a v1 order
+func newMigrationTestStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	contents := []map[string]any{
+		{
+			"$id":     "gts://" + migrationOrderV1,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				"id":          map[string]any{"type": "string"},
+				"customer":    map[string]any{"type": "string"},
+				"street":      map[string]any{"type": "string"},
+				"legacy_code": map[string]any{"type": "string"},
+			},
+		},
+		{
+			"$id":      "gts://" + migrationOrderV2,
+			"$schema":  "http://json-schema.org/draft-07/schema#",
+			"type":     "object",
+			"required": []any{"buyer", "currency"},
+			"properties": map[string]any{
+				"id":       map[string]any{"type": "string"},
+				"buyer":    map[string]any{"type": "string"},
+				"currency": map[string]any{"type": "string"},
+				"address": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"street": map[string]any{"type": "string"}},
+				},
+			},
+			"additionalProperties": false,
+		},
+		{"id": migrationOrderID, "customer": "ann", "street": "Main St", "legacy_code": "X1"},
+	}
+	registerTestEntities(t, store, contents...)
+	return store
+}
+
+// registerMigrationMap registers a migration map between the order schemas
+func registerMigrationMap(t *testing.T, store *GtsStore, id, from, to string, operations []any) {
+	t.Helper()
+	content := map[string]any{"id": id, "from": from, "to": to, "operations": operations}
+	if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register migration map: %v", err)
+	}
+}
+
+func TestCast_MajorVersionRequiresMapping(t *testing.T) {
+	store := newMigrationTestStore(t)
+
+	_, err := store.Cast(migrationOrderID, migrationOrderV2)
+	var mappingErr *StoreGtsMajorCastRequiresMappingError
+	if !errors.As(err, &mappingErr) {
+		t.Fatalf("Expected StoreGtsMajorCastRequiresMappingError, got %v", err)
+	}
+	if mappingErr.FromID != migrationOrderV1 || mappingErr.ToID != migrationOrderV2 {
+		t.Errorf("Unexpected error fields: %+v", mappingErr)
+	}
+	if batch := store.CastAll("gts.x.test.mig.order.v1~*", migrationOrderV2, 0); batch.Skipped != 1 {
+		t.Errorf("Expected the batch cast to skip the instance, got %+v", batch)
+	}
+}
+
+func TestCast_MajorVersionWithMapping(t *testing.T) {
+	store := newMigrationTestStore(t)
+	const mapID = MigrationMapTypeID + "x.test.mig.order_v1_v2.v1"
+	registerMigrationMap(t, store, mapID, "gts.x.test.mig.order.v1~", "gts.x.test.mig.order.v2~", []any{
+		map[string]any{"op": "rename", "from": "/customer", "path": "/buyer"},
+		map[string]any{"op": "move", "from": "/street", "path": "/address/street"},
+		map[string]any{"op": "set_default", "path": "/currency", "value": "EUR"},
+		map[string]any{"op": "drop", "path": "/legacy_code"},
+		map[string]any{"op": "drop", "path": "/absent"},
+	})
+
+	result, err := store.Cast(migrationOrderID, migrationOrderV2)
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	expected := map[string]any{
+		"id":       migrationOrderID,
+		"buyer":    "ann",
+		"currency": "EUR",
+		"address":  map[string]any{"street": "Main St"},
+	}
+	if !reflect.DeepEqual(result.CastedEntity, expected) {
+		t.Errorf("Expected casted entity %v, got %v", expected, result.CastedEntity)
+	}
+	if !result.IsFullyCompatible {
+		t.Errorf("Expected the migrated instance to validate, got %v", result.IncompatibilityReasons)
+	}
+	if result.MigrationMapID != mapID || len(result.MigrationOperations) != 4 {
+		t.Errorf("Expected 4 operations of %s to be applied, got %s %+v", mapID, result.MigrationMapID, result.MigrationOperations)
+	}
+	if store.Get(migrationOrderID).Content["customer"] != "ann" {
+		t.Error("Expected the registered instance to be left unchanged")
+	}
+	if batch := store.CastAll("gts.x.test.mig.order.v1~*", migrationOrderV2, 0); batch.Succeeded != 1 || batch.FullyCompatible != 1 {
+		t.Errorf("Expected the batch cast to apply the migration map, got %+v", batch)
+	}
+
+	// A map naming both schemas exactly is preferred
+	const exactID = MigrationMapTypeID + "x.test.mig.order_v1_0_v2_0.v1"
+	registerMigrationMap(t, store, exactID, migrationOrderV1, migrationOrderV2, []any{
+		map[string]any{"op": "rename", "from": "/customer", "path": "/buyer"},
+		map[string]any{"op": "set_default", "path": "/currency", "value": "USD"},
+	})
+	result, err = store.Cast(migrationOrderID, migrationOrderV2)
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	if result.MigrationMapID != exactID || result.CastedEntity["currency"] != "USD" {
+		t.Errorf("Expected the exact map to be applied, got %s %v", result.MigrationMapID, result.CastedEntity)
+	}
+}
+
+func TestCast_InvalidMigrationMap(t *testing.T) {
+	store := newMigrationTestStore(t)
+	registerMigrationMap(t, store, MigrationMapTypeID+"x.test.mig.broken.v1", migrationOrderV1, migrationOrderV2, []any{
+		map[string]any{"op": "rename", "from": "/customer", "path": "/address/buyer"},
+	})
+
+	var invalidErr *InvalidMigrationMapError
+	if _, err := store.Cast(migrationOrderID, migrationOrderV2); !errors.As(err, &invalidErr) {
+		t.Fatalf("Expected InvalidMigrationMapError, got %v", err)
+	}
+}
+
+func TestMigrationMap_Apply(t *testing.T) {
+	content := map[string]any{"a": map[string]any{"b": 1}, "s": "text", "k~/": true}
+	m, err := ParseMigrationMap(NewJsonEntity(map[string]any{
+		"id":   MigrationMapTypeID + "x.test.mig.apply.v1",
+		"from": migrationOrderV1,
+		"to":   migrationOrderV2,
+		"operations": []any{
+			map[string]any{"op": "move", "from": "/a/b", "path": "/c/d"},
+			map[string]any{"op": "move", "from": "/c/d", "path": "/s/x"},
+			map[string]any{"op": "set_default", "path": "/c/d", "value": 2},
+			map[string]any{"op": "rename", "from": "/k~0~1", "path": "/k"},
+		},
+	}, DefaultGtsConfig()))
+	if err != nil {
+		t.Fatalf("ParseMigrationMap failed: %v", err)
+	}
+
+	result, applied := m.Apply(content)
+	expected := map[string]any{"a": map[string]any{}, "c": map[string]any{"d": 1}, "s": "text", "k": true}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	// The move below a string and the default of an existing member are not applied
+	if len(applied) != 2 || applied[0].Op != MigrationOpMove || applied[1].Op != MigrationOpRename {
+		t.Errorf("Unexpected applied operations: %+v", applied)
+	}
+	if _, ok := content["k~/"]; !ok {
+		t.Error("Expected the input content to be left unchanged")
+	}
+
+	tests := []map[string]any{
+		{"from": "gts.x.test.mig.order.v1.0", "to": migrationOrderV2},
+		{"from": migrationOrderV1, "to": migrationOrderV2, "operations": []any{map[string]any{"op": "copy", "path": "/a"}}},
+		{"from": migrationOrderV1, "to": migrationOrderV2, "operations": []any{map[string]any{"op": "drop", "path": "a"}}},
+		{"from": migrationOrderV1, "to": migrationOrderV2, "operations": "drop"},
+	}
+	for _, content := range tests {
+		content["id"] = MigrationMapTypeID + "x.test.mig.invalid.v1"
+		var invalidErr *InvalidMigrationMapError
+		if _, err := ParseMigrationMap(NewJsonEntity(content, DefaultGtsConfig())); !errors.As(err, &invalidErr) {
+			t.Errorf("Expected InvalidMigrationMapError for %v, got %v", content, err)
+		}
+	}
+}
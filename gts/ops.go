@@ -96,3 +96,54 @@ func IDToUUID(gtsID string) *UUIDResult {
 		Error: "",
 	}
 }
+
+// IDsToUUIDs converts a batch of GTS IDs to UUIDs, in order. An invalid ID is reported in the
+// Error of its result and does not fail the others.
+func IDsToUUIDs(ids []string) []UUIDResult {
+	results := make([]UUIDResult, len(ids))
+	for i, id := range ids {
+		results[i] = *IDToUUID(id)
+	}
+	return results
+}
+
+// UUIDTreeEntry pairs a segment prefix of a GTS ID with its UUID
+type UUIDTreeEntry struct {
+	Prefix string `json:"prefix"`
+	UUID   string `json:"uuid"`
+}
+
+// UUIDTreeResult represents the result of converting every segment prefix of a GTS ID to a UUID
+type UUIDTreeResult struct {
+	ID    string          `json:"id"`
+	Tree  []UUIDTreeEntry `json:"tree"`
+	Error string          `json:"error"`
+}
+
+// IDToUUIDTree converts each segment prefix of a GTS ID (see GtsID.SegmentPrefixIDs) to a UUID,
+// in order from the base type to the full ID
+func IDToUUIDTree(gtsID string) ([]UUIDTreeEntry, error) {
+	id, err := NewGtsID(gtsID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := id.SegmentPrefixIDs()
+	tree := make([]UUIDTreeEntry, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		tree = append(tree, UUIDTreeEntry{
+			Prefix: prefix,
+			UUID:   (&GtsID{ID: prefix}).ToUUID().String(),
+		})
+	}
+	return tree, nil
+}
+
+// NewUUIDTreeResult runs IDToUUIDTree and wraps the outcome for JSON output
+func NewUUIDTreeResult(gtsID string) *UUIDTreeResult {
+	tree, err := IDToUUIDTree(gtsID)
+	if err != nil {
+		return &UUIDTreeResult{ID: gtsID, Tree: []UUIDTreeEntry{}, Error: err.Error()}
+	}
+	return &UUIDTreeResult{ID: gtsID, Tree: tree}
+}
@@ -32,11 +32,20 @@ type ParseIDResult struct {
 // Returns a ParseIDResult with OK=true and populated Segments on success,
 // or OK=false with an Error message on failure
 func ParseID(gtsID string) ParseIDResult {
+	return parseID(gtsID, currentLimits())
+}
+
+// ParseIDWithConfig is ParseID using the ID limits of cfg
+func ParseIDWithConfig(gtsID string, cfg *GtsConfig) ParseIDResult {
+	return parseID(gtsID, configLimits(cfg))
+}
+
+func parseID(gtsID string, limits idLimits) ParseIDResult {
 	isWildcard := strings.Contains(gtsID, "*")
 
 	if isWildcard {
 		// Handle wildcard patterns separately
-		id, err := validateWildcard(gtsID)
+		id, err := validateWildcardWithLimits(gtsID, limits)
 		if err != nil {
 			return ParseIDResult{
 				ID:         gtsID,
@@ -75,7 +84,7 @@ func ParseID(gtsID string) ParseIDResult {
 	}
 
 	// Handle regular GTS IDs
-	id, err := NewGtsID(gtsID)
+	id, err := newGtsID(gtsID, limits)
 	if err != nil {
 		return ParseIDResult{
 			ID:         gtsID,
@@ -0,0 +1,95 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultPatternCacheSize is the number of parsed wildcard patterns kept in the shared cache
+const defaultPatternCacheSize = 1024
+
+// patternCacheEntry holds a parsed pattern (or the error produced while parsing it)
+type patternCacheEntry struct {
+	pattern string
+	id      *GtsID
+	err     error
+}
+
+// patternCache is a size-bounded LRU cache of parsed wildcard patterns, safe for concurrent use
+// Cached *GtsID values are shared between callers and must be treated as read-only.
+type patternCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// newPatternCache creates a pattern cache holding at most capacity entries
+func newPatternCache(capacity int) *patternCache {
+	if capacity <= 0 {
+		capacity = defaultPatternCacheSize
+	}
+	return &patternCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// sharedPatternCache is used by MatchIDPattern, Query and other pattern consumers
+var sharedPatternCache = newPatternCache(defaultPatternCacheSize)
+
+// get returns the parsed pattern, parsing and caching it on a miss
+func (c *patternCache) get(pattern string) (*GtsID, error) {
+	c.mu.Lock()
+	if elem, ok := c.items[pattern]; ok {
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*patternCacheEntry)
+		c.mu.Unlock()
+		return entry.id, entry.err
+	}
+	c.mu.Unlock()
+
+	// Parse outside the lock; concurrent misses for the same pattern simply race to insert
+	id, err := validateWildcard(pattern)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[pattern]; ok {
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*patternCacheEntry)
+		return entry.id, entry.err
+	}
+	c.items[pattern] = c.order.PushFront(&patternCacheEntry{pattern: pattern, id: id, err: err})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*patternCacheEntry).pattern)
+	}
+	return id, err
+}
+
+// reset drops every cached pattern
+func (c *patternCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// len returns the number of cached patterns
+func (c *patternCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// parsePattern validates and parses a wildcard pattern through the shared cache
+func parsePattern(pattern string) (*GtsID, error) {
+	return sharedPatternCache.get(pattern)
+}
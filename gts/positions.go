@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position locates a JSON value in the file an entity was read from. Line and Column are
+// 1-based, Column counting characters; ByteOffset is 0-based from the start of the file.
+type Position struct {
+	Line       int `json:"line"`
+	Column     int `json:"column"`
+	ByteOffset int `json:"byte_offset"`
+}
+
+// String formats the position as line:column
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// PositionIndex maps the JSON Pointers of the values of an entity, relative to the entity ("" is
+// the entity itself), to their positions in its file. Object members are located at their key,
+// so that editors underline the property name; array elements at the start of the element.
+type PositionIndex map[string]Position
+
+// buildPositionIndex scans a JSON document and returns the position of every value in it, keyed
+// by JSON Pointer from the document root
+func buildPositionIndex(data []byte) (PositionIndex, error) {
+	p := &positionScanner{
+		data:  data,
+		dec:   json.NewDecoder(bytes.NewReader(data)),
+		index: make(PositionIndex),
+	}
+	p.lineStarts = []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			p.lineStarts = append(p.lineStarts, i+1)
+		}
+	}
+	p.index[""] = p.position(p.nextValueOffset())
+	if err := p.scanValue(""); err != nil {
+		return nil, err
+	}
+	return p.index, nil
+}
+
+// positionScanner records the positions of the values of a document while decoding its tokens
+type positionScanner struct {
+	data       []byte
+	dec        *json.Decoder
+	lineStarts []int
+	index      PositionIndex
+}
+
+// nextValueOffset returns the offset of the next token, skipping the whitespace and separators
+// the decoder has not consumed yet
+func (p *positionScanner) nextValueOffset() int {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+			continue
+		}
+		break
+	}
+	return offset
+}
+
+// position converts a byte offset into a Position
+func (p *positionScanner) position(offset int) Position {
+	line := sort.Search(len(p.lineStarts), func(i int) bool { return p.lineStarts[i] > offset }) - 1
+	start := p.lineStarts[line]
+	return Position{Line: line + 1, Column: utf8.RuneCount(p.data[start:offset]) + 1, ByteOffset: offset}
+}
+
+// scanValue consumes the value at ptr, recording the positions of its members and elements
+func (p *positionScanner) scanValue(ptr string) error {
+	tok, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for p.dec.More() {
+			offset := p.nextValueOffset()
+			key, err := p.dec.Token()
+			if err != nil {
+				return err
+			}
+			child := ptr + "/" + escapeJSONPointer(key.(string))
+			p.index[child] = p.position(offset)
+			if err := p.scanValue(child); err != nil {
+				return err
+			}
+		}
+		_, err = p.dec.Token()
+	case json.Delim('['):
+		for i := 0; p.dec.More(); i++ {
+			child := ptr + "/" + strconv.Itoa(i)
+			p.index[child] = p.position(p.nextValueOffset())
+			if err := p.scanValue(child); err != nil {
+				return err
+			}
+		}
+		_, err = p.dec.Token()
+	}
+	return err
+}
+
+// subIndex returns the entries of a document index under prefix, re-keyed relative to it
+func (idx PositionIndex) subIndex(prefix string) PositionIndex {
+	sub := make(PositionIndex)
+	for ptr, pos := range idx {
+		if ptr == prefix {
+			sub[""] = pos
+		} else if rest, ok := strings.CutPrefix(ptr, prefix+"/"); ok {
+			sub["/"+rest] = pos
+		}
+	}
+	return sub
+}
+
+// escapeJSONPointer escapes a key for use as a JSON Pointer reference token
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// Lookup returns the position of the value at path, which is a JSON Pointer ("/properties/name")
+// or a path as reported by validators ("properties.name", "items[0]", "properties/x-gts-ref").
+// A path that does not resolve is located at its closest indexed ancestor; the empty path and
+// paths with no indexed ancestor are not found.
+func (idx PositionIndex) Lookup(path string) (Position, bool) {
+	tokens := positionPathTokens(path)
+	if len(idx) == 0 || len(tokens) == 0 {
+		return Position{}, false
+	}
+	for n := len(tokens); n >= 0; n-- {
+		ptr := ""
+		for _, token := range tokens[:n] {
+			ptr += "/" + escapeJSONPointer(token)
+		}
+		if pos, ok := idx[ptr]; ok {
+			return pos, true
+		}
+	}
+	return Position{}, false
+}
+
+// positionPathTokens splits a validator path into its reference tokens
+func positionPathTokens(path string) []string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "#")
+	if path == "" || path == "root" {
+		return nil
+	}
+	if strings.HasPrefix(path, "/") {
+		tokens := strings.Split(path[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		}
+		return tokens
+	}
+
+	var tokens []string
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '.' }) {
+		// items[0][1] yields items, 0 and 1
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			tokens = append(tokens, name)
+		}
+		for rest != "" {
+			var index string
+			index, rest, _ = strings.Cut(rest, "]")
+			tokens = append(tokens, index)
+			rest = strings.TrimPrefix(rest, "[")
+		}
+	}
+	return tokens
+}
+
+// SourceLocation returns file:line:column of the value at path in the file the entity was read
+// from, or "" when the entity has no file or no position index, or path is not found
+func (e *JsonEntity) SourceLocation(path string) string {
+	if e == nil || e.File == nil {
+		return ""
+	}
+	pos, ok := e.PositionIndex.Lookup(path)
+	if !ok {
+		return ""
+	}
+	return e.File.Path + ":" + pos.String()
+}
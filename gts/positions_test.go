@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newPositionTestStore loads testdata/positions, recording positions when record is set
+func newPositionTestStore(t *testing.T, record bool) (*GtsStore, string) {
+	t.Helper()
+	dir, err := filepath.Abs(filepath.Join("testdata", "positions"))
+	if err != nil {
+		t.Fatalf("Failed to resolve testdata: %v", err)
+	}
+	cfg := DefaultGtsConfig()
+	cfg.RecordPositions = record
+	return NewGtsStore(NewGtsFileReaderFromPath(dir, cfg)), dir
+}
+
+func TestPositionIndex_FileEntities(t *testing.T) {
+	store, _ := newPositionTestStore(t, true)
+
+	tests := []struct {
+		id   string
+		path string
+		want Position
+	}{
+		// Object members are located at their key
+		{"gts.x.test.pos.order.v1~", "/properties/amount/type", Position{Line: 8, Column: 16, ByteOffset: 216}},
+		{"gts.x.test.pos.order.v1~", "", Position{Line: 1, Column: 1, ByteOffset: 0}},
+		// Array items are keyed from the item, with positions relative to the file
+		{"gts.x.test.pos.order.v1~x.test._.ok.v1", "", Position{Line: 2, Column: 3, ByteOffset: 4}},
+		{"gts.x.test.pos.order.v1~x.test._.bad.v1", "", Position{Line: 6, Column: 3, ByteOffset: 82}},
+		{"gts.x.test.pos.order.v1~x.test._.bad.v1", "/lines/1", Position{Line: 11, Column: 7, ByteOffset: 197}},
+		{"gts.x.test.pos.order.v1~x.test._.bad.v1", "/lines/1/sku", Position{Line: 11, Column: 8, ByteOffset: 198}},
+	}
+	for _, tt := range tests {
+		entity := store.Get(tt.id)
+		if entity == nil {
+			t.Fatalf("Expected %s to be loaded", tt.id)
+		}
+		if got, ok := entity.PositionIndex[tt.path]; !ok || got != tt.want {
+			t.Errorf("%s at '%s': expected %#v, got %#v", tt.id, tt.path, tt.want, got)
+		}
+	}
+
+	// Validator paths resolve to the same positions, falling back to the closest ancestor
+	bad := store.Get("gts.x.test.pos.order.v1~x.test._.bad.v1")
+	for _, path := range []string{"lines[1].sku", "lines/1/sku", "/lines/1/sku/missing"} {
+		if pos, ok := bad.PositionIndex.Lookup(path); !ok || pos.Line != 11 || pos.Column != 8 {
+			t.Errorf("Expected %s to resolve to 11:8, got %v (%v)", path, pos, ok)
+		}
+	}
+}
+
+func TestPositionIndex_Validation(t *testing.T) {
+	store, dir := newPositionTestStore(t, true)
+
+	result := store.ValidateInstance("gts.x.test.pos.order.v1~x.test._.bad.v1")
+	want := filepath.Join(dir, "orders.json") + ":11:8"
+	if result.OK || result.Path != "/lines/1/sku" || result.Location != want {
+		t.Errorf("Expected a failure at /lines/1/sku located at %s, got %+v", want, result)
+	}
+
+	report := store.BuildValidationReport([]string{"gts.x.test.pos.order.v1~x.test._.bad.v1"})
+	finding := report.Entries[0].Findings[0]
+	if finding.Position == nil || finding.Position.Line != 11 || !strings.HasPrefix(formatFinding(report.Entries[0].File, finding), want+": ") {
+		t.Errorf("Expected the report finding to be located at %s, got %+v", want, finding)
+	}
+
+	// Without the flag nothing is recorded and results are unchanged
+	plain, _ := newPositionTestStore(t, false)
+	if plain.Get("gts.x.test.pos.order.v1~x.test._.bad.v1").PositionIndex != nil {
+		t.Error("Expected no position index without RecordPositions")
+	}
+	plainResult := plain.ValidateInstance("gts.x.test.pos.order.v1~x.test._.bad.v1")
+	if plainResult.OK || plainResult.Error != result.Error || plainResult.Path != "" || plainResult.Location != "" {
+		t.Errorf("Expected the same failure without a location, got %+v", plainResult)
+	}
+	if findings := plain.BuildValidationReport([]string{"gts.x.test.pos.order.v1~x.test._.bad.v1"}).Entries[0].Findings; findings[0].Position != nil || findings[0].Path != "/lines/1/sku" {
+		t.Errorf("Expected report findings with their paths but without positions, got %+v", findings)
+	}
+}
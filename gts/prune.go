@@ -0,0 +1,263 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reasons reported for the entities removed by Prune
+const (
+	// PruneReasonSuperseded marks a schema older than the minor versions kept by PrunePolicy.KeepMinors
+	PruneReasonSuperseded = "superseded"
+	// PruneReasonUnused marks a schema without instances or dependents (PrunePolicy.RemoveUnused)
+	PruneReasonUnused = "unused"
+	// PruneReasonExpired marks an instance not updated within PrunePolicy.InstancesOlderThan
+	PruneReasonExpired = "expired"
+	// PruneReasonOrphaned marks an instance whose schema is not registered (PrunePolicy.RemoveOrphans)
+	PruneReasonOrphaned = "orphaned"
+)
+
+// PrunePolicy selects the entities removed by Prune. Schemas are only ever removed when nothing
+// left in the store references them: no instance of the schema, no type derived from it and no
+// reference to it in the content of another entity.
+type PrunePolicy struct {
+	// KeepMinors keeps the latest N minor versions of every major version of a type and removes
+	// the older ones; 0 keeps every version
+	KeepMinors int
+	// RemoveUnused removes every schema nothing references, whatever its version
+	RemoveUnused bool
+	// InstancesOlderThan removes the instances not registered or updated within the duration;
+	// 0 keeps instances regardless of age
+	InstancesOlderThan time.Duration
+	// RemoveOrphans removes the instances whose schema is not registered
+	RemoveOrphans bool
+	// Protect lists GTS IDs and wildcard patterns of entities that are never removed
+	Protect []string
+}
+
+// PruneReport is the outcome of Prune
+type PruneReport struct {
+	DryRun bool `json:"dry_run"`
+	// Removed lists the entities removed, or that would be removed in a dry run, in removal order
+	Removed []PrunedEntity `json:"removed"`
+	// Protected lists the IDs the policy selected but a Protect pattern kept, sorted
+	Protected []string `json:"protected"`
+	// Remaining is the number of entities left in the store after pruning
+	Remaining int `json:"remaining"`
+}
+
+// PrunedEntity is an entity removed by Prune
+type PrunedEntity struct {
+	ID       string `json:"id"`
+	IsSchema bool   `json:"is_schema"`
+	Reason   string `json:"reason"`
+}
+
+// Prune removes the entities selected by the policy: expired and orphaned instances first, then
+// the schemas nothing references anymore, repeated until no schema is left to remove, so that a
+// base type whose only derived type is removed is removed in turn. With dryRun the store is left
+// unchanged and the report lists what would be removed.
+func (s *GtsStore) Prune(policy PrunePolicy, dryRun bool) (*PruneReport, error) {
+	if policy.KeepMinors < 0 || policy.InstancesOlderThan < 0 {
+		return nil, fmt.Errorf("invalid prune policy: KeepMinors and InstancesOlderThan must not be negative")
+	}
+	protect := make([]*GtsID, 0, len(policy.Protect))
+	for _, pattern := range policy.Protect {
+		parsed, err := parsePattern(strings.TrimSpace(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid protect pattern '%s': %w", pattern, err)
+		}
+		protect = append(protect, parsed)
+	}
+
+	if dryRun {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	} else {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.frozen {
+			return nil, &StoreFrozenError{Operation: "prune"}
+		}
+	}
+
+	report := &PruneReport{DryRun: dryRun, Removed: []PrunedEntity{}, Protected: []string{}}
+	ids := make([]string, 0, len(s.byID))
+	for id := range s.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// referrers counts the live entities referencing each ID
+	live := make(map[string]bool, len(ids))
+	targets := make(map[string][]string, len(ids))
+	referrers := make(map[string]int)
+	for _, id := range ids {
+		live[id] = true
+		entity := s.byID[id]
+		schemaID := ""
+		if !entity.IsSchema {
+			if schemaID, _, _ = s.resolveInstanceSchemaLocked(entity); schemaID == "" {
+				schemaID = entity.SchemaID
+			}
+		}
+		targets[id] = pruneReferenceTargets(entity, schemaID)
+		for _, target := range targets[id] {
+			referrers[target]++
+		}
+	}
+
+	protected := make(map[string]bool)
+	// removable reports whether the policy may remove an ID, recording the protected ones
+	removable := func(id string) bool {
+		entity := s.byID[id]
+		for _, pattern := range protect {
+			if entity.GtsID != nil && MatchParsedIDPattern(entity.GtsID, pattern) {
+				protected[id] = true
+				return false
+			}
+		}
+		return true
+	}
+	remove := func(id, reason string) {
+		delete(live, id)
+		for _, target := range targets[id] {
+			referrers[target]--
+		}
+		report.Removed = append(report.Removed, PrunedEntity{ID: id, IsSchema: s.byID[id].IsSchema, Reason: reason})
+	}
+
+	cutoff := s.clock().Add(-policy.InstancesOlderThan)
+	for _, id := range ids {
+		entity := s.byID[id]
+		if entity.IsSchema {
+			continue
+		}
+		reason := ""
+		if policy.InstancesOlderThan > 0 && entity.UpdatedAt.Before(cutoff) {
+			reason = PruneReasonExpired
+		} else if policy.RemoveOrphans {
+			if _, _, err := s.resolveInstanceSchemaLocked(entity); err != nil {
+				reason = PruneReasonOrphaned
+			}
+		}
+		if reason != "" && removable(id) {
+			remove(id, reason)
+		}
+	}
+
+	superseded := s.supersededSchemasLocked(policy.KeepMinors)
+	for changed := true; changed; {
+		changed = false
+		for _, id := range ids {
+			if !live[id] || !s.byID[id].IsSchema || referrers[id] > 0 {
+				continue
+			}
+			reason := ""
+			if superseded[id] {
+				reason = PruneReasonSuperseded
+			} else if policy.RemoveUnused {
+				reason = PruneReasonUnused
+			}
+			if reason != "" && removable(id) {
+				remove(id, reason)
+				changed = true
+			}
+		}
+	}
+
+	for id := range protected {
+		report.Protected = append(report.Protected, id)
+	}
+	sort.Strings(report.Protected)
+	report.Remaining = len(live)
+
+	if !dryRun {
+		for i, pruned := range report.Removed {
+			// Prune stops at an entity the writer fails to delete; the entities before it are removed
+			if err := s.unpersistLocked(pruned.ID); err != nil {
+				report.Remaining += len(report.Removed) - i
+				report.Removed = report.Removed[:i]
+				return report, err
+			}
+			s.removeLocked(pruned.ID)
+		}
+	}
+	return report, nil
+}
+
+// pruneReferenceTargets returns the IDs an entity depends on: the schema of an instance, as
+// resolved to schemaID, the parent of a derived type and the GTS IDs in its content, excluding
+// its own ID
+func pruneReferenceTargets(entity *JsonEntity, schemaID string) []string {
+	self := ""
+	if entity.GtsID != nil {
+		self = entity.GtsID.ID
+	}
+	seen := make(map[string]bool)
+	var targets []string
+	add := func(id string) {
+		id, _, _ = strings.Cut(strings.TrimPrefix(id, GtsURIPrefix), "#")
+		if id != "" && id != self && !seen[id] {
+			seen[id] = true
+			targets = append(targets, id)
+		}
+	}
+
+	if !entity.IsSchema {
+		add(schemaID)
+	} else if entity.GtsID != nil && len(entity.GtsID.Segments) > 1 {
+		add(self[:entity.GtsID.Segments[len(entity.GtsID.Segments)-1].Offset])
+	}
+	for _, ref := range extractGtsReferences(entity.Content) {
+		add(ref.ID)
+	}
+	return targets
+}
+
+// supersededSchemasLocked returns the registered schemas older than the latest keepMinors minor
+// versions of their major version; versions are grouped by ID up to the version of the last segment
+func (s *GtsStore) supersededSchemasLocked(keepMinors int) map[string]bool {
+	superseded := make(map[string]bool)
+	if keepMinors <= 0 {
+		return superseded
+	}
+
+	lines := make(map[string][]TypeVersion)
+	for id, entity := range s.byID {
+		if !entity.IsSchema || entity.GtsID == nil || !entity.GtsID.IsType() {
+			continue
+		}
+		last := entity.GtsID.Segments[len(entity.GtsID.Segments)-1]
+		key := fmt.Sprintf("%s%s.v%d", id[:last.Offset], typeLineName(entity.GtsID), last.VerMajor)
+		lines[key] = append(lines[key], TypeVersion{ID: id, Major: last.VerMajor, Minor: last.VerMinor})
+	}
+	for _, versions := range lines {
+		sort.Slice(versions, func(i, j int) bool { return compareTypeVersions(versions[i], versions[j]) < 0 })
+		for _, v := range versions[:max(len(versions)-keepMinors, 0)] {
+			superseded[v.ID] = true
+		}
+	}
+	return superseded
+}
+
+// removeLocked unregisters an entity with its indexes and tags; s.mu must be held for writing
+func (s *GtsStore) removeLocked(id string) {
+	entity, ok := s.byID[id]
+	if !ok {
+		return
+	}
+	s.unresolvedRefs -= len(entity.UnresolvedRefs)
+	delete(s.byID, id)
+	delete(s.shortIDs, shortIDOf(id))
+	s.unindexUUIDLocked(entity.GtsID)
+	delete(s.tags, id)
+	delete(s.duplicateSources, id)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newPruneTestStore registers four minor versions of an event type, a derived type of the oldest,
+// an unused audit type, a protected legacy line and instances of different ages
+func newPruneTestStore(t *testing.T) *GtsStore {
+	t.Helper()
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := t0
+	store := NewGtsStore(nil)
+	store.now = func() time.Time { return now }
+
+	registerTestEntities(t, store,
+		testSchema("gts.x.test.prune.event.v1.0~", nil),
+		testSchema("gts.x.test.prune.event.v1.1~", nil),
+		testSchema("gts.x.test.prune.event.v1.2~", nil),
+		testSchema("gts.x.test.prune.event.v1.3~", nil),
+		testSchema("gts.x.test.prune.event.v1.0~x.test.prune.order.v1~", map[string]any{
+			"allOf": []any{map[string]any{"$ref": "gts://gts.x.test.prune.event.v1.0~"}},
+		}),
+		testSchema("gts.x.test.prune.audit.v1~", nil),
+		testSchema("gts.x.test.prune.legacy.v1.0~", nil),
+		testSchema("gts.x.test.prune.legacy.v1.1~", nil),
+		testSchema("gts.x.test.prune.legacy.v1.2~", nil),
+		map[string]any{"id": "gts.x.test.prune.event.v1.1~x.test._.old.v1"},
+	)
+	now = t0.Add(48 * time.Hour)
+	registerTestEntities(t, store, map[string]any{"id": "gts.x.test.prune.event.v1.3~x.test._.new.v1"})
+	return store
+}
+
+func TestPrune_KeepMinors(t *testing.T) {
+	store := newPruneTestStore(t)
+	policy := PrunePolicy{KeepMinors: 2, Protect: []string{"gts.x.test.prune.legacy.*"}}
+
+	report, err := store.Prune(policy, true)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	// v1.0 is kept by its derived type and v1.1 by its instance
+	if len(report.Removed) != 0 || report.Remaining != 11 {
+		t.Errorf("Expected nothing to be removed, got %+v", report)
+	}
+	if !reflect.DeepEqual(report.Protected, []string{"gts.x.test.prune.legacy.v1.0~"}) {
+		t.Errorf("Expected the superseded legacy version to be protected, got %v", report.Protected)
+	}
+
+	// Once the old instance expires, v1.1 is no longer referenced
+	policy.InstancesOlderThan = 24 * time.Hour
+	report, err = store.Prune(policy, true)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	expected := []PrunedEntity{
+		{ID: "gts.x.test.prune.event.v1.1~x.test._.old.v1", Reason: PruneReasonExpired},
+		{ID: "gts.x.test.prune.event.v1.1~", IsSchema: true, Reason: PruneReasonSuperseded},
+	}
+	if !reflect.DeepEqual(report.Removed, expected) {
+		t.Errorf("Expected dry run to remove %+v, got %+v", expected, report.Removed)
+	}
+	if !report.DryRun || report.Remaining != 9 || store.Count() != 11 {
+		t.Errorf("Expected a dry run leaving 11 entities and reporting 9 remaining, got %d and %+v", store.Count(), report)
+	}
+
+	// Removing unused schemas takes the derived type, then the v1.0 it kept
+	policy.RemoveUnused = true
+	applied, err := store.Prune(policy, false)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	expected = []PrunedEntity{
+		{ID: "gts.x.test.prune.event.v1.1~x.test._.old.v1", Reason: PruneReasonExpired},
+		{ID: "gts.x.test.prune.audit.v1~", IsSchema: true, Reason: PruneReasonUnused},
+		{ID: "gts.x.test.prune.event.v1.0~x.test.prune.order.v1~", IsSchema: true, Reason: PruneReasonUnused},
+		{ID: "gts.x.test.prune.event.v1.1~", IsSchema: true, Reason: PruneReasonSuperseded},
+		{ID: "gts.x.test.prune.event.v1.2~", IsSchema: true, Reason: PruneReasonUnused},
+		{ID: "gts.x.test.prune.event.v1.0~", IsSchema: true, Reason: PruneReasonSuperseded},
+	}
+	if !reflect.DeepEqual(applied.Removed, expected) || applied.Remaining != 5 || store.Count() != 5 {
+		t.Errorf("Expected %+v to be removed leaving 5 entities, got %d and %+v", expected, store.Count(), applied)
+	}
+	if len(applied.Protected) != 3 {
+		t.Errorf("Expected the 3 legacy versions to be protected, got %v", applied.Protected)
+	}
+	for _, id := range []string{"gts.x.test.prune.legacy.v1.0~", "gts.x.test.prune.legacy.v1.2~", "gts.x.test.prune.event.v1.3~"} {
+		if store.Get(id) == nil {
+			t.Errorf("Expected %s to survive", id)
+		}
+	}
+	short, _ := ShortID("gts.x.test.prune.event.v1.0~")
+	if _, err := store.FindByShortID(short); err == nil {
+		t.Error("Expected the short ID of a pruned schema to be released")
+	}
+}
+
+func TestPrune_OrphansAndErrors(t *testing.T) {
+	store := newPruneTestStore(t)
+	// Instances only reach the store without their schema when loaded by a reader
+	orphan := NewJsonEntity(map[string]any{"id": "gts.x.test.prune.gone.v1~x.test._.lost.v1"}, DefaultGtsConfig())
+	store.mu.Lock()
+	store.loadLocked(orphan)
+	store.mu.Unlock()
+
+	report, err := store.Prune(PrunePolicy{RemoveOrphans: true}, false)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	expected := []PrunedEntity{{ID: "gts.x.test.prune.gone.v1~x.test._.lost.v1", Reason: PruneReasonOrphaned}}
+	if !reflect.DeepEqual(report.Removed, expected) || store.Get(orphan.GtsID.ID) != nil {
+		t.Errorf("Expected the orphan to be removed, got %+v", report.Removed)
+	}
+
+	if _, err := store.Prune(PrunePolicy{Protect: []string{"not-a-pattern"}}, true); err == nil {
+		t.Error("Expected an invalid protect pattern to fail")
+	}
+	store.Freeze()
+	var frozen *StoreFrozenError
+	if _, err := store.Prune(PrunePolicy{RemoveUnused: true}, false); !errors.As(err, &frozen) {
+		t.Errorf("Expected StoreFrozenError, got %v", err)
+	}
+	if _, err := store.Prune(PrunePolicy{RemoveUnused: true}, true); err != nil {
+		t.Errorf("Expected a dry run on a frozen store to succeed, got %v", err)
+	}
+}
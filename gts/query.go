@@ -6,8 +6,12 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +21,53 @@ type QueryResult struct {
 	Count   int              `json:"count"`
 	Limit   int              `json:"limit"`
 	Results []map[string]any `json:"results"`
+	// IDs lists the IDs of the matches instead of Results with QueryOptions.IDsOnly
+	IDs []string `json:"ids,omitempty"`
+	// NormalizedPattern is the pattern the query was evaluated with, when RegistryConfig.LenientLookup
+	// normalized the pattern of the expression
+	NormalizedPattern string `json:"normalized_pattern,omitempty"`
+	// ErrorCode is the stable cause of Error: ErrorCodeQueryInvalid or ErrorCodeInternal
+	ErrorCode string `json:"error_code,omitempty"`
+	// Err is the error behind Error, for callers inspecting its type
+	Err error `json:"-"`
+}
+
+// InvalidQueryError is returned for query expressions that cannot be parsed or whose pattern is
+// not a valid GTS ID or wildcard pattern
+type InvalidQueryError struct {
+	Reason string
+	Err    error
+}
+
+func (e *InvalidQueryError) Error() string {
+	return "Invalid query: " + e.Reason
+}
+
+func (e *InvalidQueryError) Unwrap() error {
+	return e.Err
+}
+
+// newInvalidQueryError wraps the error behind an invalid query
+func newInvalidQueryError(err error) *InvalidQueryError {
+	return &InvalidQueryError{Reason: err.Error(), Err: err}
+}
+
+// queryErrorCode returns the ErrorCode of a query error, empty when it is not a known cause
+func queryErrorCode(err error) string {
+	var invalidErr *InvalidQueryError
+	if errors.As(err, &invalidErr) {
+		return ErrorCodeQueryInvalid
+	}
+	return ""
+}
+
+// queryChunkSize is the number of entities QueryStream fetches from the store at a time
+const queryChunkSize = 1024
+
+// QueryItem is a single match produced by QueryStream
+type QueryItem struct {
+	ID      string         `json:"id"`
+	Content map[string]any `json:"content"`
 }
 
 // Query filters entities by a GTS query expression
@@ -26,116 +77,399 @@ type QueryResult struct {
 // - With filters: "gts.x.core.events.event.v1~[status=active]"
 // - Wildcard with filters: "gts.x.core.*[status=active]"
 // - Wildcard filter values: "gts.x.core.*[status=active, category=*]"
+// - Tag filters: "gts.x.core.*[#owner=payments-team, status=active]" ('#' keys match entity tags)
+// - Comparisons: "gts.x.commerce.*[payload.totalAmount>100, payload.customer.name~=doe]"
+// Filter keys are attribute paths as accepted by GetAttribute; a key naming a top-level field
+// literally, dots included, matches that field. The operators are = and != (string forms, '*'
+// matching any non-empty value), >, >=, < and <= (numbers when the filter value is a number,
+// otherwise strings in lexical order) and ~= (substring). Entities without the attribute, or whose
+// attribute has the wrong type for a comparison, do not match.
+// Results are returned in store order, which is unspecified unless RegistryConfig.StableOrder is set;
+// QueryWithOptions sorts them with QueryOptions.OrderBy.
+// With RegistryConfig.LenientLookup the pattern is normalized as IDs are by Lookup.
+// A panic during the query is reported in the result Error field as an internal error.
 // see gts-python store.py query method
 func (s *GtsStore) Query(expr string, limit int) *QueryResult {
+	return s.QueryWithOptions(expr, limit, QueryOptions{})
+}
+
+// QueryOptions selects what QueryWithOptions returns for every match
+type QueryOptions struct {
+	// IDsOnly returns the IDs of the matches in QueryResult.IDs instead of their content; it takes
+	// precedence over Projection
+	IDsOnly bool
+	// Projection lists attribute paths, as accepted by GetAttribute, to return instead of the full
+	// content: every result is {"id": ..., "values": {path: value}}. Paths that do not resolve on an
+	// entity are omitted from its values.
+	Projection []string
+	// OrderBy sorts the matches before the limit is applied, so that a limit keeps the first
+	// matches in that order: "gtsId" sorts by entity ID, any other key is an attribute path or
+	// '#' tag key as in filters. A trailing " asc" or " desc" sets the direction, e.g.
+	// "occurredAt desc". Numbers compare numerically and strings lexically; values of different
+	// types compare by their JSON encoding. Matches without the attribute sort last in either
+	// direction, and ties keep ID order.
+	OrderBy string
+	// Descending sorts in descending order; also set by an OrderBy ending with " desc"
+	Descending bool
+}
+
+// OrderByID is the QueryOptions.OrderBy key sorting matches by entity ID
+const OrderByID = "gtsId"
+
+// QueryWithOptions is Query returning the matches sorted, or only their IDs or selected attributes
+func (s *GtsStore) QueryWithOptions(expr string, limit int, opts QueryOptions) (result *QueryResult) {
 	if limit <= 0 {
 		limit = 100 // Default limit
 	}
 
-	result := &QueryResult{
+	result = &QueryResult{
 		Error:   "",
 		Count:   0,
 		Limit:   limit,
 		Results: make([]map[string]any, 0),
 	}
 
-	// Parse the query expression to extract base pattern and filters
-	basePattern, filters, err := s.parseQueryExpression(expr)
+	// Sorted queries collect every match, so that the limit applies after sorting
+	streamLimit := limit
+	if opts.OrderBy != "" {
+		streamLimit = 0
+	}
+	var items []QueryItem
+	err := s.guardedQueryStream("Query", expr, streamLimit, func(item QueryItem) bool {
+		items = append(items, item)
+		return true
+	})
 	if err != nil {
 		result.Error = err.Error()
+		result.ErrorCode = queryErrorCode(err)
+		result.Err = err
 		return result
 	}
+	if opts.OrderBy != "" {
+		s.sortQueryItems(items, opts.OrderBy, opts.Descending)
+		if len(items) > limit {
+			items = items[:limit]
+		}
+	}
+
+	for _, item := range items {
+		switch {
+		case opts.IDsOnly:
+			result.IDs = append(result.IDs, item.ID)
+		case len(opts.Projection) > 0:
+			result.Results = append(result.Results, projectQueryItem(item, opts.Projection))
+		default:
+			result.Results = append(result.Results, item.Content)
+		}
+	}
+
+	result.Count = len(result.Results) + len(result.IDs)
+	if basePattern, _, err := s.parseQueryExpression(expr); err == nil {
+		if normalized := s.lenientQueryPattern(basePattern); normalized != basePattern {
+			result.NormalizedPattern = normalized
+		}
+	}
+	return result
+}
+
+// sortQueryItems sorts query matches by an OrderBy key, see QueryOptions.OrderBy
+func (s *GtsStore) sortQueryItems(items []QueryItem, orderBy string, descending bool) {
+	key := strings.TrimSpace(orderBy)
+	if field, direction, found := strings.Cut(key, " "); found {
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "desc":
+			key, descending = field, true
+		case "asc":
+			key = field
+		}
+	}
+
+	type sortEntry struct {
+		item     QueryItem
+		value    any
+		resolved bool
+	}
+	entries := make([]sortEntry, len(items))
+	for i, item := range items {
+		entry := sortEntry{item: item}
+		if key == OrderByID {
+			entry.value, entry.resolved = item.ID, true
+		} else {
+			var tags map[string]string
+			if strings.HasPrefix(key, TagFilterPrefix) {
+				tags = s.GetTags(item.ID)
+			}
+			entry.value, entry.resolved = filterAttribute(key, item.Content, tags)
+		}
+		entries[i] = entry
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.resolved != b.resolved {
+			return a.resolved
+		}
+		order := 0
+		if a.resolved {
+			order = compareSortValues(a.value, b.value)
+			if descending {
+				order = -order
+			}
+		}
+		if order == 0 {
+			return a.item.ID < b.item.ID
+		}
+		return order < 0
+	})
+	for i, entry := range entries {
+		items[i] = entry.item
+	}
+}
+
+// compareSortValues orders two attribute values: numbers numerically, strings lexically, false
+// before true, and values of different or other types by their JSON encoding
+func compareSortValues(a, b any) int {
+	if x, ok := floatValue(a); ok {
+		if y, ok := floatValue(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return strings.Compare(string(x), string(y))
+}
+
+// projectQueryItem returns the ID of a match and the values of the paths that resolve in it
+func projectQueryItem(item QueryItem, paths []string) map[string]any {
+	values := make(map[string]any)
+	for _, path := range paths {
+		if attr := resolveAttributePath(item.ID, path, item.Content); attr.Resolved {
+			values[path] = attr.Value
+		}
+	}
+	return map[string]any{"id": item.ID, "values": values}
+}
+
+// QueryStream evaluates a GTS query expression and invokes fn for each match as it is found,
+// without materializing the result set: only the IDs are listed up front, and the entities are
+// fetched in chunks of queryChunkSize, holding the store's read lock only while a chunk is looked
+// up, so fn may call into the store. Entities removed while the query runs are skipped and
+// entities added are not visited. Returning false from fn stops the query.
+// Matches are produced in the same (unspecified) store order as Query.
+// An error is returned when the expression is invalid, or wrapping ErrInternal when the query panics.
+func (s *GtsStore) QueryStream(expr string, fn func(item QueryItem) bool) error {
+	return s.guardedQueryStream("QueryStream", expr, 0, fn)
+}
+
+// guardedQueryStream runs queryStream, converting a panic into an error wrapping ErrInternal
+func (s *GtsStore) guardedQueryStream(operation, expr string, limit int, fn func(item QueryItem) bool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newStoreInternalError(operation, r)
+		}
+	}()
+
+	return s.queryStream(expr, limit, fn, nil)
+}
+
+// queryStream is the shared implementation of Query, QueryStream and ExplainQuery; limit <= 0 means
+// unlimited. When plan is not nil the evaluation is recorded in it; otherwise no counters are kept.
+func (s *GtsStore) queryStream(expr string, limit int, fn func(item QueryItem) bool, plan *QueryPlan) error {
+	// Parse the query expression to extract base pattern and filters
+	basePattern, filters, err := s.parseQueryExpression(expr)
+	if err != nil {
+		return err
+	}
+	basePattern = s.lenientQueryPattern(basePattern)
 
 	// Determine if pattern is wildcard
 	isWildcard := strings.Contains(basePattern, "*")
 
 	// Validate the pattern
 	if err := s.validateQueryPattern(basePattern, isWildcard); err != nil {
-		result.Error = err.Error()
-		return result
+		return err
+	}
+
+	// Parse the pattern once; entities are matched against their already parsed IDs
+	patternID, err := parsePattern(basePattern)
+	if err != nil {
+		return newInvalidQueryError(err)
 	}
 
-	// Filter entities
-	for _, entity := range s.byID {
-		if len(result.Results) >= limit {
-			break
+	// Tags are only looked up when a filter needs them
+	tagFilters := hasTagFilters(filters)
+
+	ids := s.snapshotIDs()
+	if plan != nil {
+		plan.begin(basePattern, isWildcard, filters, len(ids))
+	}
+
+	// visit evaluates an entity, returning false once the query is done
+	count := 0
+	visit := func(entity *JsonEntity) bool {
+		if limit > 0 && count >= limit {
+			return false
+		}
+		if plan != nil {
+			plan.Scanned++
 		}
 
 		// Skip entities without valid content or GTS ID
 		if len(entity.Content) == 0 || entity.GtsID == nil {
-			continue
+			if plan != nil {
+				plan.Skipped++
+			}
+			return true
 		}
 
 		// Check if ID matches the pattern
-		if !s.matchesIDPattern(entity.GtsID, basePattern, isWildcard) {
-			continue
+		if !s.matchesIDPattern(entity.GtsID, patternID) {
+			return true
 		}
 
 		// Check filters
-		if !s.matchesFilters(entity.Content, filters) {
-			continue
+		var tags map[string]string
+		if tagFilters {
+			tags = s.GetTags(entity.GtsID.ID)
+		}
+		if plan != nil {
+			plan.PatternMatched++
+			if !plan.matchFilters(entity.Content, tags) {
+				return true
+			}
+		} else if !s.matchesFilters(entity.Content, tags, filters) {
+			return true
 		}
 
-		result.Results = append(result.Results, entity.Content)
+		count++
+		return fn(QueryItem{ID: entity.GtsID.ID, Content: entity.Content})
 	}
 
-	result.Count = len(result.Results)
-	return result
+	// Entities are fetched in chunks, the read lock held only while a chunk is looked up
+	for start := 0; start < len(ids); start += queryChunkSize {
+		for _, entity := range s.entitiesOf(ids[start:min(start+queryChunkSize, len(ids))]) {
+			if !visit(entity) {
+				return nil
+			}
+		}
+	}
+	return nil
 }
 
 // parseQueryExpression parses the query expression into base pattern and filters
 // see gts-python store.py query method
-func (s *GtsStore) parseQueryExpression(expr string) (string, map[string]string, error) {
+func (s *GtsStore) parseQueryExpression(expr string) (string, []queryFilter, error) {
 	// Split by '[' to separate base pattern from filters
 	parts := strings.SplitN(expr, "[", 2)
 	basePattern := strings.TrimSpace(parts[0])
 
-	filters := make(map[string]string)
+	var filters []queryFilter
 	if len(parts) == 2 {
 		// Extract filter string (remove trailing ])
 		filterStr := strings.TrimSpace(parts[1])
 		if !strings.HasSuffix(filterStr, "]") {
-			return "", nil, errors.New("Invalid query: missing closing bracket ']'")
+			return "", nil, &InvalidQueryError{Reason: "missing closing bracket ']'"}
 		}
 		filterStr = strings.TrimSuffix(filterStr, "]")
 
 		// Check if base pattern ends with ~ or ~* (type ID/pattern) - filters not allowed on type queries
 		if strings.HasSuffix(basePattern, "~") || strings.HasSuffix(basePattern, "~*") {
-			return "", nil, errors.New("Invalid query: filters cannot be used with type patterns (ending with ~ or ~*)")
+			return "", nil, &InvalidQueryError{Reason: "filters cannot be used with type patterns (ending with ~ or ~*)"}
 		}
 
 		// Parse filters
-		filters = s.parseQueryFilters(filterStr)
+		var err error
+		if filters, err = s.parseQueryFilters(filterStr); err != nil {
+			return "", nil, err
+		}
 	}
 
 	return basePattern, filters, nil
 }
 
+// Query filter operators
+const (
+	filterOpEqual        = "="
+	filterOpNotEqual     = "!="
+	filterOpGreater      = ">"
+	filterOpGreaterEqual = ">="
+	filterOpLess         = "<"
+	filterOpLessEqual    = "<="
+	filterOpContains     = "~="
+)
+
+// queryFilter is a filter of a query expression: an attribute path or '#' tag key, an operator
+// and the value to compare with
+type queryFilter struct {
+	Key   string
+	Op    string
+	Value string
+}
+
 // parseQueryFilters parses filter expressions from query string
 // see gts-python store.py _parse_query_filters method
-func (s *GtsStore) parseQueryFilters(filterStr string) map[string]string {
-	filters := make(map[string]string)
-	if filterStr == "" {
-		return filters
+func (s *GtsStore) parseQueryFilters(filterStr string) ([]queryFilter, error) {
+	var filters []queryFilter
+	if strings.TrimSpace(filterStr) == "" {
+		return filters, nil
 	}
 
 	// Split by comma to handle multiple filters
-	parts := strings.Split(filterStr, ",")
-	for _, part := range parts {
+	for _, part := range strings.Split(filterStr, ",") {
 		part = strings.TrimSpace(part)
-		if strings.Contains(part, "=") {
-			kv := strings.SplitN(part, "=", 2)
-			key := strings.TrimSpace(kv[0])
-			value := strings.TrimSpace(kv[1])
+		if part == "" {
+			continue
+		}
+		i, op := findFilterOperator(part)
+		key := strings.TrimSpace(part[:max(i, 0)])
+		if op == "" || key == "" {
+			return nil, &InvalidQueryError{Reason: fmt.Sprintf("invalid filter '%s': expected <key><op><value> with op one of =, !=, >, >=, <, <=, ~=", part)}
+		}
+		value := strings.TrimSpace(part[i+len(op):])
 
-			// Remove quotes from value if present
-			value = strings.Trim(value, `"'`)
+		// Remove quotes from value if present
+		value = strings.Trim(value, `"'`)
 
-			filters[key] = value
-		}
+		filters = append(filters, queryFilter{Key: key, Op: op, Value: value})
 	}
 
-	return filters
+	return filters, nil
+}
+
+// findFilterOperator returns the position and operator of the first operator of a filter, or
+// -1 and "" when it has none
+func findFilterOperator(filter string) (int, string) {
+	i := strings.IndexAny(filter, "=!<>~")
+	if i < 0 {
+		return -1, ""
+	}
+	for _, op := range []string{filterOpGreaterEqual, filterOpLessEqual, filterOpNotEqual, filterOpContains} {
+		if strings.HasPrefix(filter[i:], op) {
+			return i, op
+		}
+	}
+	switch op := filter[i : i+1]; op {
+	case filterOpEqual, filterOpGreater, filterOpLess:
+		return i, op
+	}
+	return -1, ""
 }
 
 // validateQueryPattern validates the query pattern
@@ -144,31 +478,31 @@ func (s *GtsStore) validateQueryPattern(basePattern string, isWildcard bool) err
 	if isWildcard {
 		// Wildcard pattern must end with .* or ~*
 		if !strings.HasSuffix(basePattern, ".*") && !strings.HasSuffix(basePattern, "~*") {
-			return errors.New("Invalid query: wildcard patterns must end with .* or ~*")
+			return &InvalidQueryError{Reason: "wildcard patterns must end with .* or ~*"}
 		}
 
 		// Validate as wildcard pattern
 		_, err := validateWildcard(basePattern)
 		if err != nil {
-			return fmt.Errorf("Invalid query: %w", err)
+			return newInvalidQueryError(err)
 		}
 	} else {
 		// Non-wildcard pattern must be a complete valid GTS ID
 		gtsID, err := NewGtsID(basePattern)
 		if err != nil {
-			return fmt.Errorf("Invalid query: %w", err)
+			return newInvalidQueryError(err)
 		}
 
 		// Must have at least one valid segment
 		if len(gtsID.Segments) == 0 {
-			return errors.New("Invalid query: GTS ID has no valid segments")
+			return &InvalidQueryError{Reason: "GTS ID has no valid segments"}
 		}
 
 		// Check if pattern is incomplete (missing version or type)
 		// A complete GTS ID must end with a version (v1, v1.2) or ~ for types
 		lastSeg := gtsID.Segments[len(gtsID.Segments)-1]
 		if !lastSeg.IsType && lastSeg.VerMajor == 0 {
-			return errors.New("Invalid query: incomplete GTS ID pattern")
+			return &InvalidQueryError{Reason: "incomplete GTS ID pattern"}
 		}
 	}
 
@@ -177,36 +511,90 @@ func (s *GtsStore) validateQueryPattern(basePattern string, isWildcard bool) err
 
 // matchesIDPattern checks if entity ID matches the query pattern
 // see gts-python store.py _matches_id_pattern method
-func (s *GtsStore) matchesIDPattern(entityID *GtsID, basePattern string, isWildcard bool) bool {
+func (s *GtsStore) matchesIDPattern(entityID *GtsID, patternID *GtsID) bool {
 	if entityID == nil {
 		return false
 	}
 
-	// Use the existing MatchIDPattern function
-	matchResult := MatchIDPattern(entityID.ID, basePattern)
-	return matchResult.Match
+	return MatchParsedIDPattern(entityID, patternID)
 }
 
-// matchesFilters checks if entity content matches all filter criteria
+// matchesFilters checks if entity content and tags match all filter criteria
+// Keys prefixed with TagFilterPrefix match tags, all others match content attributes.
 // see gts-python store.py _matches_filters method
-func (s *GtsStore) matchesFilters(entityContent map[string]any, filters map[string]string) bool {
-	if len(filters) == 0 {
-		return true
+func (s *GtsStore) matchesFilters(entityContent map[string]any, tags map[string]string, filters []queryFilter) bool {
+	for _, filter := range filters {
+		if !matchesFilter(filter, entityContent, tags) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterAttribute returns the tag or content attribute a filter key refers to
+func filterAttribute(key string, entityContent map[string]any, tags map[string]string) (any, bool) {
+	if tagKey, isTag := strings.CutPrefix(key, TagFilterPrefix); isTag {
+		tag, ok := tags[tagKey]
+		return tag, ok
+	}
+	if value, ok := entityContent[key]; ok {
+		return value, true
 	}
+	result := resolveAttributePath("", key, entityContent)
+	return result.Value, result.Resolved
+}
 
-	for key, value := range filters {
-		entityValue := fmt.Sprintf("%v", entityContent[key])
+// matchesFilter checks a single filter against entity content and tags
+func matchesFilter(filter queryFilter, entityContent map[string]any, tags map[string]string) bool {
+	attr, ok := filterAttribute(filter.Key, entityContent, tags)
+	if !ok && filter.Op != filterOpEqual {
+		return false
+	}
+	entityValue := fmt.Sprintf("%v", attr)
+
+	switch filter.Op {
+	case filterOpEqual:
+		return filterValueEquals(entityValue, filter.Value)
+	case filterOpNotEqual:
+		return !filterValueEquals(entityValue, filter.Value)
+	case filterOpContains:
+		return strings.Contains(entityValue, filter.Value)
+	}
 
-		// Support wildcard in filter values
-		if value == "*" {
-			// Wildcard matches any non-empty value
-			if entityValue == "" || entityValue == "<nil>" {
-				return false
-			}
-		} else if entityValue != value {
+	// Ordering comparisons: numbers when the filter value is one, otherwise strings
+	var order int
+	if threshold, err := strconv.ParseFloat(filter.Value, 64); err == nil {
+		n, ok := floatValue(attr)
+		if !ok {
+			return false
+		}
+		order = cmp.Compare(n, threshold)
+	} else {
+		str, ok := attr.(string)
+		if !ok {
 			return false
 		}
+		order = strings.Compare(str, filter.Value)
 	}
+	switch filter.Op {
+	case filterOpGreater:
+		return order > 0
+	case filterOpGreaterEqual:
+		return order >= 0
+	case filterOpLess:
+		return order < 0
+	case filterOpLessEqual:
+		return order <= 0
+	}
+	return false
+}
 
-	return true
+// filterValueEquals compares the string form of an attribute with a filter value; the value '*'
+// matches any non-empty attribute
+func filterValueEquals(entityValue, value string) bool {
+	if value == "*" {
+		return entityValue != "" && entityValue != "<nil>"
+	}
+	return entityValue == value
 }
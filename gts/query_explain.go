@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueryStrategyFullScan is the only query strategy: the store has no ID index, so every
+// entity is matched against the pattern
+const QueryStrategyFullScan = "full_scan"
+
+// QueryFilterStats reports how a filter of a query narrowed the candidates it was evaluated on
+type QueryFilterStats struct {
+	Key   string `json:"key"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+	// Tag is set for '#' filters, which match entity tags
+	Tag bool `json:"tag"`
+	// In is the number of candidates the filter was evaluated on, Out the number that passed
+	In  int `json:"in"`
+	Out int `json:"out"`
+	// Missing counts the rejected candidates that do not have the attribute or tag at all
+	Missing int `json:"missing"`
+}
+
+// QueryPlan describes the evaluation of a query expression, without the matched content
+type QueryPlan struct {
+	Expression string `json:"expression"`
+	Pattern    string `json:"pattern"`
+	Wildcard   bool   `json:"wildcard"`
+	Strategy   string `json:"strategy"`
+	// EntityCount is the number of entities in the store when the query ran
+	EntityCount int `json:"entity_count"`
+	// Scanned is the number of entities examined before the scan ended or the limit was reached
+	Scanned int `json:"scanned"`
+	// Skipped counts scanned entities without content or GTS ID
+	Skipped int `json:"skipped"`
+	// PatternMatched is the number of scanned entities whose ID matches the pattern
+	PatternMatched int `json:"pattern_matched"`
+	// Filters are listed in evaluation order; a candidate rejected by a filter is not passed to the next
+	Filters    []QueryFilterStats `json:"filters"`
+	Count      int                `json:"count"`
+	Limit      int                `json:"limit"`
+	DurationMs float64            `json:"duration_ms"`
+}
+
+// ExplainQuery evaluates a query expression like Query and reports how it was evaluated instead
+// of the matches: the parsed pattern, the scan strategy, how many candidates each filter received
+// and kept, the number of matches and the time taken. limit <= 0 means unlimited; with a limit the
+// scan stops at the limit-th match as Query's does. Filters, which Query evaluates in unspecified
+// order, are evaluated in key order so the reported attrition is reproducible.
+func (s *GtsStore) ExplainQuery(expr string, limit int) (plan *QueryPlan, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			plan, err = nil, newStoreInternalError("ExplainQuery", r)
+		}
+	}()
+
+	plan = &QueryPlan{Expression: expr, Strategy: QueryStrategyFullScan, Limit: limit}
+	start := time.Now()
+	err = s.queryStream(expr, limit, func(QueryItem) bool {
+		plan.Count++
+		return true
+	}, plan)
+	plan.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// begin records the parsed query and prepares the filter statistics
+func (p *QueryPlan) begin(pattern string, wildcard bool, filters []queryFilter, entityCount int) {
+	p.Pattern = pattern
+	p.Wildcard = wildcard
+	p.EntityCount = entityCount
+	p.Filters = make([]QueryFilterStats, 0, len(filters))
+	for _, f := range filters {
+		p.Filters = append(p.Filters, QueryFilterStats{Key: f.Key, Op: f.Op, Value: f.Value, Tag: strings.HasPrefix(f.Key, TagFilterPrefix)})
+	}
+	sort.SliceStable(p.Filters, func(i, j int) bool {
+		return p.Filters[i].Key < p.Filters[j].Key
+	})
+}
+
+// matchFilters evaluates the filters in plan order, counting the candidates each one receives and keeps
+func (p *QueryPlan) matchFilters(content map[string]any, tags map[string]string) bool {
+	for i := range p.Filters {
+		f := &p.Filters[i]
+		f.In++
+		if !matchesFilter(queryFilter{Key: f.Key, Op: f.Op, Value: f.Value}, content, tags) {
+			if _, ok := filterAttribute(f.Key, content, tags); !ok {
+				f.Missing++
+			}
+			return false
+		}
+		f.Out++
+	}
+	return true
+}
+
+// String summarizes the plan on one line
+func (p *QueryPlan) String() string {
+	return fmt.Sprintf("%s of %d entities: %d scanned, %d matched the pattern, %d after filters (%.3fms)",
+		p.Strategy, p.EntityCount, p.Scanned, p.PatternMatched, p.Count, p.DurationMs)
+}
@@ -6,6 +6,13 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -102,6 +109,11 @@ func TestQuery_InvalidQuery_PartialID(t *testing.T) {
 	if !containsString(result.Error, "Invalid query") {
 		t.Errorf("Expected 'Invalid query' in error, got: %s", result.Error)
 	}
+
+	var invalidErr *InvalidQueryError
+	if result.ErrorCode != ErrorCodeQueryInvalid || !errors.As(result.Err, &invalidErr) {
+		t.Errorf("Expected error code %s and InvalidQueryError, got %s %v", ErrorCodeQueryInvalid, result.ErrorCode, result.Err)
+	}
 }
 
 // Test 3: Invalid query (missing namespace - double dots)
@@ -482,3 +494,460 @@ func indexOf(s, substr string) int {
 	}
 	return -1
 }
+
+// TestQueryStream_MatchesQuery tests that streaming yields the same result set as Query
+func TestQueryStream_MatchesQuery(t *testing.T) {
+	store := setupQueryTestStore()
+
+	for _, expr := range []string{
+		"gts.x.test10.*",
+		"gts.x.test10.query.*[status=active]",
+		"gts.x.test10.query.event.v1.0~a.b.c.d.v1",
+		"gts.x.nomatch.*",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			want := map[string]bool{}
+			for _, content := range store.Query(expr, 1000).Results {
+				want[content["gtsId"].(string)] = true
+			}
+
+			got := map[string]bool{}
+			err := store.QueryStream(expr, func(item QueryItem) bool {
+				if item.Content["gtsId"] != item.ID {
+					t.Errorf("Item ID %s does not match content %v", item.ID, item.Content["gtsId"])
+				}
+				got[item.ID] = true
+				return true
+			})
+			if err != nil {
+				t.Fatalf("QueryStream failed: %v", err)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("Expected %d streamed results, got %d", len(want), len(got))
+			}
+			for id := range want {
+				if !got[id] {
+					t.Errorf("Expected %s in streamed results", id)
+				}
+			}
+		})
+	}
+}
+
+// TestQueryStream_EarlyTermination tests that returning false from the callback stops the query
+func TestQueryStream_EarlyTermination(t *testing.T) {
+	store := setupQueryTestStore()
+
+	calls := 0
+	err := store.QueryStream("gts.x.test10.*", func(item QueryItem) bool {
+		calls++
+		return false
+	})
+	if err != nil {
+		t.Fatalf("QueryStream failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected callback to be invoked once, got %d", calls)
+	}
+}
+
+// TestQueryStream_Chunks tests that entities are fetched chunk by chunk, so that the callback can
+// change the store and entities removed in a later chunk are skipped
+func TestQueryStream_Chunks(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{StableOrder: true})
+	if err := store.RegisterSchema("gts.x.test10.chunk.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	total := 2*queryChunkSize + 5
+	for i := 0; i < total; i++ {
+		content := map[string]any{"id": fmt.Sprintf("gts.x.test10.chunk.item.v1~x.test10._.i%05d.v1", i)}
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register item %d: %v", i, err)
+		}
+	}
+	last := fmt.Sprintf("gts.x.test10.chunk.item.v1~x.test10._.i%05d.v1", total-1)
+
+	visited := 0
+	err := store.QueryStream("gts.x.test10.chunk.item.v1~x.test10.*", func(item QueryItem) bool {
+		if visited == 0 {
+			// Writing from the callback does not deadlock
+			if err := store.Unregister(last); err != nil {
+				t.Fatalf("Failed to unregister %s: %v", last, err)
+			}
+		}
+		if item.ID == last {
+			t.Errorf("Expected %s, removed during the query, to be skipped", last)
+		}
+		visited++
+		return true
+	})
+	if err != nil {
+		t.Fatalf("QueryStream failed: %v", err)
+	}
+	if visited != total-1 {
+		t.Errorf("Expected %d streamed results, got %d", total-1, visited)
+	}
+}
+
+// TestQueryStream_InvalidQuery tests that invalid expressions are reported as errors
+func TestQueryStream_InvalidQuery(t *testing.T) {
+	store := setupQueryTestStore()
+
+	err := store.QueryStream("gts.x.test10", func(item QueryItem) bool {
+		t.Error("Callback must not be invoked for an invalid query")
+		return true
+	})
+	if err == nil {
+		t.Error("Expected error for invalid query")
+	}
+}
+
+func TestQuery_StableOrder(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{StableOrder: true})
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("gts.x.pkg%d.ns.event.v1.0~a.b.c.item%d.v1", i%7, i)
+		if err := store.Register(NewJsonEntity(map[string]any{"gtsId": id, "n": i}, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+
+	marshal := func() []byte {
+		data, err := json.Marshal([]any{store.List(50), store.Query("gts.x.*", 50)})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		return data
+	}
+	first := marshal()
+	for i := 0; i < 20; i++ {
+		if !bytes.Equal(first, marshal()) {
+			t.Fatal("Expected byte-identical output for the same store state")
+		}
+	}
+
+	list := store.List(3)
+	expected := []string{"gts.x.pkg0.ns.event.v1.0~a.b.c.item0.v1", "gts.x.pkg0.ns.event.v1.0~a.b.c.item105.v1", "gts.x.pkg0.ns.event.v1.0~a.b.c.item112.v1"}
+	for i, info := range list.Entities {
+		if info.ID != expected[i] {
+			t.Errorf("Expected %s at %d, got %s", expected[i], i, info.ID)
+		}
+	}
+}
+
+func TestListPage(t *testing.T) {
+	store := NewGtsStore(nil)
+	var ids []string
+	for i := 0; i < 25; i++ {
+		id := fmt.Sprintf("gts.x.pkg.ns.event.v1.0~a.b.c.item%02d.v1", i)
+		ids = append(ids, id)
+		if err := store.Register(NewJsonEntity(map[string]any{"gtsId": id}, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+
+	var listed []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Expected the listing to end after 3 pages")
+		}
+		page := store.ListPage(10, cursor)
+		if page.Total != 25 || page.Count != len(page.Entities) {
+			t.Errorf("Unexpected counts: count %d, total %d", page.Count, page.Total)
+		}
+		for _, info := range page.Entities {
+			listed = append(listed, info.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if !reflect.DeepEqual(listed, ids) {
+		t.Errorf("Expected every entity once in ID order, got %v", listed)
+	}
+
+	// A cursor naming an ID that is not registered resumes at the next ID
+	page := store.ListPage(2, "gts.x.pkg.ns.event.v1.0~a.b.c.item10.v0")
+	if page.Count != 2 || page.Entities[0].ID != ids[10] || page.NextCursor != ids[11] {
+		t.Errorf("Expected items 10 and 11 after a missing cursor ID, got %+v", page)
+	}
+
+	// Past the end: an empty page without a next cursor
+	page = store.ListPage(10, ids[24])
+	if page.Count != 0 || page.NextCursor != "" || page.Entities == nil {
+		t.Errorf("Expected an empty page after the last ID, got %+v", page)
+	}
+}
+
+func TestExplainQuery_ScanAndFilterAttrition(t *testing.T) {
+	store := setupQueryTestStore()
+
+	plan, err := store.ExplainQuery("gts.x.test10.*[status=active, category=order]", 0)
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	if plan.Strategy != QueryStrategyFullScan || plan.Pattern != "gts.x.test10.*" || !plan.Wildcard {
+		t.Errorf("Unexpected plan header: %+v", plan)
+	}
+	if plan.EntityCount != 5 || plan.Scanned != 5 || plan.PatternMatched != 4 || plan.Count != 1 {
+		t.Errorf("Expected 5 scanned, 4 pattern matches and 1 result, got %+v", plan)
+	}
+	expected := []QueryFilterStats{
+		{Key: "category", Op: "=", Value: "order", In: 4, Out: 1},
+		{Key: "status", Op: "=", Value: "active", In: 1, Out: 1},
+	}
+	if !reflect.DeepEqual(plan.Filters, expected) {
+		t.Errorf("Expected filter stats %+v, got %+v", expected, plan.Filters)
+	}
+	if result := store.Query("gts.x.test10.*[status=active, category=order]", 100); result.Count != plan.Count {
+		t.Errorf("Expected the explained count to match Query, got %d and %d", plan.Count, result.Count)
+	}
+
+	plan, err = store.ExplainQuery("gts.x.test10.*[priority=*]", 0)
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	if f := plan.Filters[0]; f.In != 4 || f.Out != 0 || f.Missing != 4 || plan.Count != 0 {
+		t.Errorf("Expected every candidate to miss the attribute, got %+v", plan)
+	}
+
+	plan, err = store.ExplainQuery("gts.x.test10.*", 1)
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	if plan.Count != 1 || plan.Scanned < 1 || plan.Scanned > 5 || len(plan.Filters) != 0 {
+		t.Errorf("Expected the scan to stop at the limit, got %+v", plan)
+	}
+
+	if _, err := store.ExplainQuery("gts.x.test10", 0); err == nil {
+		t.Error("Expected an error for an invalid expression")
+	}
+}
+
+// benchmarkEntityCount is the size of the synthetic store used by the query benchmarks
+const benchmarkEntityCount = 50000
+
+// setupLargeQueryStore builds a synthetic store spread over several vendors, packages and versions
+func setupLargeQueryStore(b *testing.B) *GtsStore {
+	b.Helper()
+	store := NewGtsStore(nil)
+	vendors := []string{"x", "acme", "globex", "initech", "umbrella"}
+
+	for i := 0; i < benchmarkEntityCount; i++ {
+		vendor := vendors[i%len(vendors)]
+		id := fmt.Sprintf("gts.%s.pkg%d.ns.event.v%d.%d~a.b.c.item%d.v1", vendor, i%10, i%3+1, i%5, i)
+		entity := NewJsonEntity(map[string]any{
+			"gtsId":  id,
+			"status": []string{"active", "inactive"}[i%2],
+		}, DefaultGtsConfig())
+		if entity.GtsID == nil {
+			b.Fatalf("Failed to parse synthetic ID %s", id)
+		}
+		// Insert directly to avoid per-entity registration logging in the benchmark setup
+		store.byID[entity.GtsID.ID] = entity
+	}
+	return store
+}
+
+// BenchmarkQuery_LargeStore measures Query over a 50k entity store
+func BenchmarkQuery_LargeStore(b *testing.B) {
+	store := setupLargeQueryStore(b)
+	queries := map[string]string{
+		"vendor_wildcard": "gts.acme.*",
+		"version_filter":  "gts.x.pkg0.ns.event.v1.*[status=active]",
+		"exact":           "gts.globex.pkg1.ns.event.v2.1~a.b.c.item1.v1",
+	}
+
+	for name, expr := range queries {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				store.Query(expr, benchmarkEntityCount)
+			}
+		})
+	}
+}
+
+// BenchmarkQuery_StableOrder measures a full query with its JSON encoding with and without StableOrder
+func BenchmarkQuery_StableOrder(b *testing.B) {
+	store := setupLargeQueryStore(b)
+	for _, stable := range []bool{false, true} {
+		b.Run(fmt.Sprintf("stable=%v", stable), func(b *testing.B) {
+			store.config.StableOrder = stable
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(store.Query("gts.acme.*", benchmarkEntityCount)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMatch_LargeStore compares string based matching, which re-parses both IDs on every
+// call, with matching pre-parsed entity IDs against a pattern parsed once
+func BenchmarkMatch_LargeStore(b *testing.B) {
+	store := setupLargeQueryStore(b)
+	pattern := "gts.acme.*"
+
+	b.Run("MatchIDPattern", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, entity := range store.byID {
+				MatchIDPattern(entity.GtsID.ID, pattern)
+			}
+		}
+	})
+
+	b.Run("MatchParsedIDPattern", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			patternID, err := parsePattern(pattern)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, entity := range store.byID {
+				MatchParsedIDPattern(entity.GtsID, patternID)
+			}
+		}
+	})
+}
+
+func TestQuery_ComparisonFilters(t *testing.T) {
+	store := NewGtsStore(nil)
+	orders := []map[string]any{
+		{"gtsId": "gts.x.commerce.orders.order.v1.0~a.b.c.o1.v1", "status": "open", "payload": map[string]any{"totalAmount": 50.0, "customer": map[string]any{"name": "Jane Doe"}}},
+		{"gtsId": "gts.x.commerce.orders.order.v1.0~a.b.c.o2.v1", "status": "paid", "payload": map[string]any{"totalAmount": 100.0, "customer": map[string]any{"name": "John Smith"}}},
+		{"gtsId": "gts.x.commerce.orders.order.v1.0~a.b.c.o3.v1", "status": "shipped", "payload": map[string]any{"totalAmount": 250.5, "customer": map[string]any{"name": "john doe"}}},
+		{"gtsId": "gts.x.commerce.orders.order.v1.0~a.b.c.o4.v1", "status": "open", "payload": map[string]any{"totalAmount": "n/a"}},
+	}
+	for _, order := range orders {
+		if err := store.Register(NewJsonEntity(order, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register order: %v", err)
+		}
+	}
+
+	tests := map[string][]string{
+		"[payload.totalAmount>100]":                        {"o3"},
+		"[payload.totalAmount>=100]":                       {"o2", "o3"},
+		"[payload.totalAmount<100]":                        {"o1"},
+		"[payload.totalAmount<=100]":                       {"o1", "o2"},
+		"[status!=open]":                                   {"o2", "o3"},
+		"[payload.customer.name~=doe]":                     {"o3"},
+		"[payload.customer.name~=Doe, status=open]":        {"o1"},
+		"[status>open]":                                    {"o2", "o3"},
+		"[status<=open]":                                   {"o1", "o4"},
+		"[payload.totalAmount>0, payload.customer.name=*]": {"o1", "o2", "o3"},
+		"[payload.customer.name!=John Smith]":              {"o1", "o3"},
+		"[payload.missing>1]":                              {},
+	}
+	for filters, expected := range tests {
+		result := store.Query("gts.x.commerce.*"+filters, 100)
+		if result.Error != "" {
+			t.Errorf("%s: unexpected error %s", filters, result.Error)
+			continue
+		}
+		var got []string
+		for _, content := range result.Results {
+			id := content["gtsId"].(string)
+			got = append(got, id[len(id)-5:len(id)-3])
+		}
+		sort.Strings(got)
+		if len(got) != len(expected) || (len(got) > 0 && !reflect.DeepEqual(got, expected)) {
+			t.Errorf("%s: expected %v, got %v", filters, expected, got)
+		}
+	}
+
+	for _, invalid := range []string{"[payload.totalAmount]", "[>100]", "[status!open]"} {
+		result := store.Query("gts.x.commerce.*"+invalid, 100)
+		if !containsString(result.Error, "Invalid query") {
+			t.Errorf("%s: expected an invalid query error, got %q", invalid, result.Error)
+		}
+	}
+}
+
+func TestQueryWithOptions(t *testing.T) {
+	store := setupQueryTestStore()
+	store.Register(NewJsonEntity(map[string]any{
+		"gtsId":   "gts.x.test10.query.event.v1.0~a.b.c.d.v3",
+		"type":    "gts.x.test10.query.event.v1.0~",
+		"payload": map[string]any{"orderId": "o-1"},
+		"status":  "active",
+	}, DefaultGtsConfig()))
+
+	result := store.QueryWithOptions("gts.x.test10.query.event.v1.0~*", 100, QueryOptions{IDsOnly: true})
+	if result.Error != "" {
+		t.Fatalf("Expected no error, got: %s", result.Error)
+	}
+	sort.Strings(result.IDs)
+	wantIDs := []string{"gts.x.test10.query.event.v1.0~a.b.c.d.v1", "gts.x.test10.query.event.v1.0~a.b.c.d.v3"}
+	if !reflect.DeepEqual(result.IDs, wantIDs) || result.Count != 2 || len(result.Results) != 0 {
+		t.Errorf("Expected IDs %v only, got %+v", wantIDs, result)
+	}
+
+	result = store.QueryWithOptions("gts.x.test10.query.event.v1.0~*", 100, QueryOptions{Projection: []string{"payload.orderId", "status"}})
+	if result.Count != 2 {
+		t.Fatalf("Expected 2 results, got %+v", result)
+	}
+	byID := make(map[any]any)
+	for _, item := range result.Results {
+		byID[item["id"]] = item["values"]
+	}
+	want := map[any]any{
+		"gts.x.test10.query.event.v1.0~a.b.c.d.v1": map[string]any{"status": "active"},
+		"gts.x.test10.query.event.v1.0~a.b.c.d.v3": map[string]any{"payload.orderId": "o-1", "status": "active"},
+	}
+	if !reflect.DeepEqual(byID, want) {
+		t.Errorf("Expected projected values %v, got %v", want, byID)
+	}
+}
+
+func TestQueryWithOptions_OrderBy(t *testing.T) {
+	store := NewGtsStore(nil)
+	store.Register(NewJsonEntity(map[string]any{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id":     "gts://gts.x.test.sort.event.v1~",
+		"type":    "object",
+	}, DefaultGtsConfig()))
+	for _, event := range []map[string]any{
+		{"id": "gts.x.test.sort.event.v1~a.b.c.e1.v1", "occurredAt": "2025-01-02T00:00:00Z", "seq": 2},
+		{"id": "gts.x.test.sort.event.v1~a.b.c.e2.v1", "occurredAt": "2025-01-03T00:00:00Z", "seq": "x"},
+		{"id": "gts.x.test.sort.event.v1~a.b.c.e3.v1", "occurredAt": "2025-01-01T00:00:00Z", "seq": 10},
+		{"id": "gts.x.test.sort.event.v1~a.b.c.e4.v1"},
+	} {
+		if err := store.Register(NewJsonEntity(event, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register event: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		opts  QueryOptions
+		want  []string
+	}{
+		{"by ID descending", 100, QueryOptions{OrderBy: OrderByID, Descending: true}, []string{"e4", "e3", "e2", "e1"}},
+		{"newest first with limit", 2, QueryOptions{OrderBy: "occurredAt desc"}, []string{"e2", "e1"}},
+		{"missing attribute sorts last", 100, QueryOptions{OrderBy: "occurredAt"}, []string{"e3", "e1", "e2", "e4"}},
+		{"missing attribute sorts last descending", 100, QueryOptions{OrderBy: "occurredAt", Descending: true}, []string{"e2", "e1", "e3", "e4"}},
+		{"mixed types compare by JSON encoding", 100, QueryOptions{OrderBy: "seq asc"}, []string{"e2", "e1", "e3", "e4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.IDsOnly = true
+			result := store.QueryWithOptions("gts.x.test.sort.event.v1~*", tt.limit, tt.opts)
+			if result.Error != "" {
+				t.Fatalf("Expected no error, got: %s", result.Error)
+			}
+			var got []string
+			for _, id := range result.IDs {
+				got = append(got, strings.Split(id, ".")[8])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected order %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
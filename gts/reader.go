@@ -5,6 +5,8 @@ Released under Apache License 2.0
 
 package gts
 
+import "fmt"
+
 // GtsReader is an interface for reading JSON entities from various sources
 type GtsReader interface {
 	// Next returns the next JsonEntity or nil when exhausted
@@ -17,3 +19,46 @@ type GtsReader interface {
 	// Reset resets the iterator to start from the beginning
 	Reset()
 }
+
+// Categories of ReaderError
+const (
+	// ReaderErrorInvalidJSON is a file that could not be read or parsed as a JSON object or array
+	ReaderErrorInvalidJSON = "invalid_json"
+	// ReaderErrorNoGtsID is an object without a GTS entity ID
+	ReaderErrorNoGtsID = "no_gts_id"
+	// ReaderErrorInvalidGtsID is an object whose "gts." entity ID could not be parsed
+	ReaderErrorInvalidGtsID = "invalid_gts_id"
+	// ReaderErrorDuplicateID is an ID already read from another file; the entity is still returned
+	// and replaces the other when loaded into a store
+	ReaderErrorDuplicateID = "duplicate_id"
+)
+
+// ReaderError is content a reader skipped or flagged while reading
+type ReaderError struct {
+	// Path is the file the content was read from
+	Path string `json:"path"`
+	// Index is the position of the object in the file's top-level array, nil for files holding
+	// a single object and for whole files
+	Index *int `json:"index,omitempty"`
+	// Category is one of the ReaderError constants
+	Category string `json:"category"`
+	// ID is the entity ID of invalid_gts_id and duplicate_id errors
+	ID string `json:"id,omitempty"`
+	// Sources are the labels of the entities read with the ID of a duplicate_id error, in read order
+	Sources []string `json:"sources,omitempty"`
+	Message string   `json:"message"`
+}
+
+func (e ReaderError) Error() string {
+	location := e.Path
+	if e.Index != nil {
+		location = fmt.Sprintf("%s#%d", e.Path, *e.Index)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, e.Category, e.Message)
+}
+
+// GtsErrorReader is implemented by readers that report the content they skipped or flagged since
+// they were created or last reset; stores collect these errors when populated (see GtsStore.LoadErrors)
+type GtsErrorReader interface {
+	Errors() []ReaderError
+}
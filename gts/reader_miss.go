@@ -0,0 +1,195 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReaderMissPolicy controls whether Get asks the reader again for IDs it did not find
+type ReaderMissPolicy int
+
+const (
+	// ReaderMissAlwaysRetry calls reader.ReadByID on every lookup of an unregistered ID
+	ReaderMissAlwaysRetry ReaderMissPolicy = iota
+	// ReaderMissNegativeCache remembers IDs the reader did not find for RegistryConfig.NegativeCacheTTL
+	// and answers their lookups without calling it; registering an ID forgets its miss
+	ReaderMissNegativeCache
+	// ReaderMissNeverRetryAfterLoad never calls reader.ReadByID once the store has been populated
+	// from the reader, which has then returned every entity it has
+	ReaderMissNeverRetryAfterLoad
+)
+
+// Defaults of the negative cache
+const (
+	DefaultNegativeCacheTTL  = time.Minute
+	DefaultNegativeCacheSize = 1024
+)
+
+// String returns the textual name of the policy
+func (p ReaderMissPolicy) String() string {
+	switch p {
+	case ReaderMissNegativeCache:
+		return "negative-cache"
+	case ReaderMissNeverRetryAfterLoad:
+		return "never-retry-after-load"
+	default:
+		return "always-retry"
+	}
+}
+
+// ParseReaderMissPolicy parses "always-retry", "negative-cache" or "never-retry-after-load" into
+// a ReaderMissPolicy
+func ParseReaderMissPolicy(s string) (ReaderMissPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "always-retry":
+		return ReaderMissAlwaysRetry, nil
+	case "negative-cache":
+		return ReaderMissNegativeCache, nil
+	case "never-retry-after-load":
+		return ReaderMissNeverRetryAfterLoad, nil
+	default:
+		return ReaderMissAlwaysRetry, fmt.Errorf("invalid reader miss policy '%s' (expected always-retry, negative-cache or never-retry-after-load)", s)
+	}
+}
+
+// LookupStats counts the lookups made by Get, including those made by validation, casting and
+// the other operations that look entities up
+type LookupStats struct {
+	// Hits are lookups of registered entities
+	Hits int64 `json:"hits"`
+	// Misses are lookups of unregistered IDs, whether the reader then found them or not
+	Misses int64 `json:"misses"`
+	// NegativeHits are misses answered without calling the reader, by the negative cache or
+	// because the reader is not retried after loading
+	NegativeHits int64 `json:"negative_hits"`
+	// ReaderLookups are the calls to reader.ReadByID
+	ReaderLookups int64 `json:"reader_lookups"`
+	// NormalizedHits are misses found under their normalized ID (see RegistryConfig.LenientLookup);
+	// the retry itself is counted as a hit or miss too
+	NormalizedHits int64 `json:"normalized_hits"`
+	// NegativeCacheSize is the number of IDs in the negative cache, expired ones included
+	NegativeCacheSize int `json:"negative_cache_size"`
+}
+
+// lookupCounters are the counters behind LookupStats
+type lookupCounters struct {
+	hits, misses, negativeHits, readerLookups, normalizedHits atomic.Int64
+}
+
+// LookupStats returns the lookup counters of the store
+func (s *GtsStore) LookupStats() LookupStats {
+	return LookupStats{
+		Hits:              s.lookups.hits.Load(),
+		Misses:            s.lookups.misses.Load(),
+		NegativeHits:      s.lookups.negativeHits.Load(),
+		ReaderLookups:     s.lookups.readerLookups.Load(),
+		NormalizedHits:    s.lookups.normalizedHits.Load(),
+		NegativeCacheSize: s.misses.len(),
+	}
+}
+
+// skipReaderLookup reports whether the miss policy answers a lookup of id without the reader
+func (s *GtsStore) skipReaderLookup(id string) bool {
+	switch s.config.ReaderMissPolicy {
+	case ReaderMissNegativeCache:
+		return s.misses.contains(id, s.clock())
+	case ReaderMissNeverRetryAfterLoad:
+		return s.populated
+	default:
+		return false
+	}
+}
+
+// recordReaderMiss remembers that the reader did not find id, under the negative cache policy
+func (s *GtsStore) recordReaderMiss(id string) {
+	if s.config.ReaderMissPolicy == ReaderMissNegativeCache {
+		s.misses.add(id, s.clock())
+	}
+}
+
+// negativeCache is a bounded set of IDs the reader did not find, each expiring after ttl. All
+// entries live for the same ttl, so insertion order is expiry order and the oldest entry is
+// evicted when the cache is full.
+type negativeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// negativeEntry is an ID of the negative cache with its expiry
+type negativeEntry struct {
+	id      string
+	expires time.Time
+}
+
+// newNegativeCache creates a negative cache; zero ttl and size use the defaults
+func newNegativeCache(ttl time.Duration, size int) *negativeCache {
+	if ttl <= 0 {
+		ttl = DefaultNegativeCacheTTL
+	}
+	if size <= 0 {
+		size = DefaultNegativeCacheSize
+	}
+	return &negativeCache{ttl: ttl, size: size, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// contains reports whether id missed less than ttl before now, dropping it once expired
+func (c *negativeCache) contains(id string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	if now.Before(elem.Value.(*negativeEntry).expires) {
+		return true
+	}
+	c.order.Remove(elem)
+	delete(c.entries, id)
+	return false
+}
+
+// add records a miss of id at now, evicting the oldest miss when the cache is full
+func (c *negativeCache) add(id string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		elem.Value.(*negativeEntry).expires = now.Add(c.ttl)
+		c.order.MoveToBack(elem)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*negativeEntry).id)
+	}
+	c.entries[id] = c.order.PushBack(&negativeEntry{id: id, expires: now.Add(c.ttl)})
+}
+
+// remove forgets the miss of id
+func (c *negativeCache) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+	}
+}
+
+// len returns the number of IDs in the cache
+func (c *negativeCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
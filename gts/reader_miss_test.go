@@ -0,0 +1,176 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingReader is a reader holding one schema that counts its ReadByID calls
+type countingReader struct {
+	lookups atomic.Int64
+	done    bool
+}
+
+const countingReaderSchemaID = "gts.x.test.misses.known.v1~"
+
+func (r *countingReader) Next() *JsonEntity {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	return NewJsonEntity(map[string]any{
+		"$id":     "gts://" + countingReaderSchemaID,
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}, DefaultGtsConfig())
+}
+
+func (r *countingReader) ReadByID(entityID string) *JsonEntity {
+	r.lookups.Add(1)
+	return nil
+}
+
+func (r *countingReader) Reset() {
+	r.done = false
+}
+
+func TestGet_ReaderMissPolicies(t *testing.T) {
+	const missing = "gts.x.test.misses.missing.v1~"
+	tests := []struct {
+		policy        ReaderMissPolicy
+		readerLookups int64
+		negativeHits  int64
+	}{
+		{ReaderMissAlwaysRetry, 5, 0},
+		{ReaderMissNegativeCache, 1, 4},
+		{ReaderMissNeverRetryAfterLoad, 0, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			reader := &countingReader{}
+			store := NewGtsStoreWithConfig(reader, &RegistryConfig{ReaderMissPolicy: tt.policy})
+			for range 5 {
+				if store.Get(missing) != nil {
+					t.Fatalf("Expected %s to be missing", missing)
+				}
+			}
+			if store.Get(countingReaderSchemaID) == nil {
+				t.Fatal("Expected the loaded schema to be found")
+			}
+
+			if got := reader.lookups.Load(); got != tt.readerLookups {
+				t.Errorf("Expected %d ReadByID calls, got %d", tt.readerLookups, got)
+			}
+			stats := store.LookupStats()
+			expected := LookupStats{Hits: 1, Misses: 5, NegativeHits: tt.negativeHits, ReaderLookups: tt.readerLookups}
+			if tt.policy == ReaderMissNegativeCache {
+				expected.NegativeCacheSize = 1
+			}
+			if stats != expected {
+				t.Errorf("Expected stats %+v, got %+v", expected, stats)
+			}
+		})
+	}
+}
+
+func TestGet_NegativeCacheExpiryAndInvalidation(t *testing.T) {
+	now := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	reader := &countingReader{}
+	store := NewGtsStoreWithConfig(reader, &RegistryConfig{ReaderMissPolicy: ReaderMissNegativeCache, NegativeCacheTTL: time.Minute})
+	store.now = func() time.Time { return now }
+
+	const id = "gts.x.test.misses.later.v1~"
+	store.Get(id)
+	store.Get(id)
+	if reader.lookups.Load() != 1 {
+		t.Fatalf("Expected the second miss to be cached, got %d ReadByID calls", reader.lookups.Load())
+	}
+
+	// The miss expires after the TTL
+	now = now.Add(time.Minute)
+	store.Get(id)
+	if reader.lookups.Load() != 2 {
+		t.Errorf("Expected an expired miss to call the reader again, got %d ReadByID calls", reader.lookups.Load())
+	}
+
+	// Registering the ID forgets its miss
+	schema := NewJsonEntity(map[string]any{
+		"$id":     "gts://" + id,
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}, DefaultGtsConfig())
+	if err := store.Register(schema); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	if store.LookupStats().NegativeCacheSize != 0 {
+		t.Error("Expected registration to remove the ID from the negative cache")
+	}
+	if store.Get(id) != schema {
+		t.Error("Expected the registered schema to be found")
+	}
+
+	// Removing it again makes lookups reach the reader
+	if _, err := store.Prune(PrunePolicy{RemoveUnused: true}, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	store.Get(id)
+	if reader.lookups.Load() != 3 {
+		t.Errorf("Expected a lookup after removal to call the reader, got %d ReadByID calls", reader.lookups.Load())
+	}
+}
+
+func TestNegativeCache_Bounded(t *testing.T) {
+	now := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	cache := newNegativeCache(time.Minute, 2)
+	cache.add("a", now)
+	cache.add("b", now)
+	cache.add("a", now) // refreshing a makes b the oldest
+	cache.add("c", now)
+	if cache.len() != 2 || cache.contains("b", now) || !cache.contains("a", now) || !cache.contains("c", now) {
+		t.Error("Expected the oldest miss to be evicted when the cache is full")
+	}
+}
+
+func TestGet_NegativeCacheConcurrent(t *testing.T) {
+	reader := &countingReader{}
+	store := NewGtsStoreWithConfig(reader, &RegistryConfig{ReaderMissPolicy: ReaderMissNegativeCache, NegativeCacheSize: 8})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				id := "gts.x.test.misses.id" + string(rune('a'+(i+j)%16)) + ".v1~"
+				store.Get(id)
+				if j%10 == 0 {
+					_ = store.Register(NewJsonEntity(map[string]any{"id": id + "x.test._.i" + string(rune('a'+i)) + ".v1"}, DefaultGtsConfig()))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := store.LookupStats()
+	if stats.Misses != stats.NegativeHits+stats.ReaderLookups || stats.ReaderLookups != reader.lookups.Load() || stats.NegativeCacheSize > 8 {
+		t.Errorf("Expected consistent lookup stats, got %+v with %d ReadByID calls", stats, reader.lookups.Load())
+	}
+}
+
+func TestParseReaderMissPolicy(t *testing.T) {
+	for _, policy := range []ReaderMissPolicy{ReaderMissAlwaysRetry, ReaderMissNegativeCache, ReaderMissNeverRetryAfterLoad} {
+		if parsed, err := ParseReaderMissPolicy(policy.String()); err != nil || parsed != policy {
+			t.Errorf("Expected %s to parse back, got %v, %v", policy, parsed, err)
+		}
+	}
+	if _, err := ParseReaderMissPolicy("sometimes"); err == nil {
+		t.Error("Expected an error for an invalid policy")
+	}
+}
@@ -5,12 +5,88 @@ Released under Apache License 2.0
 
 package gts
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+// ReferenceKind tells whether a GTS reference targets a schema or an instance
+type ReferenceKind string
+
+const (
+	// ReferenceKindAny is used when the referencing context does not constrain the target
+	ReferenceKindAny ReferenceKind = "any"
+	// ReferenceKindSchema denotes a schema (type) entity
+	ReferenceKindSchema ReferenceKind = "schema"
+	// ReferenceKindInstance denotes an instance entity
+	ReferenceKindInstance ReferenceKind = "instance"
+)
 
 // GtsReference represents a GTS ID reference found in JSON content
 type GtsReference struct {
 	ID         string
 	SourcePath string
+	// Keyword is the JSON key that carried the reference, e.g. "$ref", "x-gts-ref" or "const"
+	Keyword string
+	// ExpectedKind is the kind of target the referencing context requires
+	ExpectedKind ReferenceKind
+	// Resolved and ResolvedKind are filled in when the store resolves the reference
+	Resolved     bool
+	ResolvedKind ReferenceKind
+}
+
+// expectedReferenceKind derives the kind of target a keyword requires:
+// $ref and $schema point to schemas, x-gts-ref points to instances unless the value is a type ID
+func expectedReferenceKind(keyword, id string) ReferenceKind {
+	switch keyword {
+	case "$ref", "$schema":
+		return ReferenceKindSchema
+	case "x-gts-ref":
+		if strings.HasSuffix(id, "~") {
+			return ReferenceKindSchema
+		}
+		return ReferenceKindInstance
+	}
+	return ReferenceKindAny
+}
+
+// entityReferenceKind returns the kind of a resolved entity
+func entityReferenceKind(entity *JsonEntity) ReferenceKind {
+	if entity.IsSchema {
+		return ReferenceKindSchema
+	}
+	return ReferenceKindInstance
+}
+
+// cloneReferences returns copies of refs, to record resolution outcomes without modifying the
+// references of another entity
+func cloneReferences(refs []*GtsReference) []*GtsReference {
+	if refs == nil {
+		return nil
+	}
+	cloned := make([]*GtsReference, len(refs))
+	for i, ref := range refs {
+		copied := *ref
+		cloned[i] = &copied
+	}
+	return cloned
+}
+
+// referenceKindMismatch describes a reference whose target, resolved to kind, is not of the expected
+// kind, or returns an empty string when the target is acceptable or kind is empty (unresolved)
+func referenceKindMismatch(ref *GtsReference, kind ReferenceKind) string {
+	if kind == "" || ref.ExpectedKind == ReferenceKindAny || ref.ExpectedKind == "" || kind == ref.ExpectedKind {
+		return ""
+	}
+	return fmt.Sprintf("%s target is %s but %s is required: %s (at %s)",
+		ref.Keyword, referenceKindNoun(kind), referenceKindNoun(ref.ExpectedKind), ref.ID, ref.SourcePath)
+}
+
+func referenceKindNoun(kind ReferenceKind) string {
+	if kind == ReferenceKindInstance {
+		return "an instance"
+	}
+	return "a " + string(kind)
 }
 
 // extractGtsReferences walks through JSON content and extracts all GTS ID references
@@ -19,12 +95,13 @@ func extractGtsReferences(content any) []*GtsReference {
 	refs := make([]*GtsReference, 0)
 	seen := make(map[string]bool)
 
-	walkAndCollectRefs(content, "", &refs, seen)
+	walkAndCollectRefs(content, "", "", &refs, seen)
 	return refs
 }
 
 // walkAndCollectRefs recursively walks JSON structure to find GTS IDs
-func walkAndCollectRefs(node any, path string, refs *[]*GtsReference, seen map[string]bool) {
+// keyword is the closest enclosing object key; array elements inherit the key of their array.
+func walkAndCollectRefs(node any, path, keyword string, refs *[]*GtsReference, seen map[string]bool) {
 	if node == nil {
 		return
 	}
@@ -39,8 +116,10 @@ func walkAndCollectRefs(node any, path string, refs *[]*GtsReference, seen map[s
 			key := str + "|" + sourcePath
 			if !seen[key] {
 				*refs = append(*refs, &GtsReference{
-					ID:         str,
-					SourcePath: sourcePath,
+					ID:           str,
+					SourcePath:   sourcePath,
+					Keyword:      keyword,
+					ExpectedKind: expectedReferenceKind(keyword, str),
 				})
 				seen[key] = true
 			}
@@ -55,7 +134,7 @@ func walkAndCollectRefs(node any, path string, refs *[]*GtsReference, seen map[s
 			if path != "" {
 				nextPath = path + "." + k
 			}
-			walkAndCollectRefs(v, nextPath, refs, seen)
+			walkAndCollectRefs(v, nextPath, k, refs, seen)
 		}
 		return
 	}
@@ -67,7 +146,7 @@ func walkAndCollectRefs(node any, path string, refs *[]*GtsReference, seen map[s
 			if path != "" {
 				nextPath = path + nextPath
 			}
-			walkAndCollectRefs(v, nextPath, refs, seen)
+			walkAndCollectRefs(v, nextPath, keyword, refs, seen)
 		}
 	}
 }
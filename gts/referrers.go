@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"sort"
+	"strings"
+)
+
+// ReferrersOptions controls the matching of FindReferrersWithOptions
+type ReferrersOptions struct {
+	// IncludeMinorVersions also counts references to any minor version of a schema ID whose last
+	// segment has no minor version, e.g. a reference to v1.2~ for gts.x.core.events.type.v1~
+	IncludeMinorVersions bool
+}
+
+// Referrer is a single reference to the looked up entity
+type Referrer struct {
+	// ID is the registered entity holding the reference
+	ID       string `json:"id"`
+	IsSchema bool   `json:"is_schema"`
+	// SourcePath locates the reference within the entity
+	SourcePath string `json:"source_path"`
+	// RefID is the referenced ID as written, which differs from the looked up ID for minor versions
+	RefID string `json:"ref_id"`
+}
+
+// ReferrersResult lists the registered entities referencing an ID, grouped by the keyword carrying
+// the reference: "$ref" (including allOf parts), "$schema", "x-gts-ref" or any other property
+// holding a GTS ID, and SchemaGraphEdgeSchemaID for the schema of an instance or the base of a
+// derived schema. Referrers of each group are sorted by entity ID and source path.
+type ReferrersResult struct {
+	ID                   string                `json:"id"`
+	IncludeMinorVersions bool                  `json:"include_minor_versions,omitempty"`
+	Count                int                   `json:"count"`
+	Referrers            map[string][]Referrer `json:"referrers"`
+	Error                string                `json:"error,omitempty"`
+}
+
+// FindReferrers lists the registered schemas and instances referencing an ID, the inverse of the
+// outgoing references resolved by BuildSchemaGraph
+func (s *GtsStore) FindReferrers(id string) *ReferrersResult {
+	return s.FindReferrersWithOptions(id, ReferrersOptions{})
+}
+
+// FindReferrersWithOptions lists the registered entities referencing an ID, see FindReferrers.
+// The ID does not have to be registered, so that dangling references can be found too.
+func (s *GtsStore) FindReferrersWithOptions(id string, opts ReferrersOptions) *ReferrersResult {
+	id = strings.TrimPrefix(strings.TrimSpace(id), GtsURIPrefix)
+	result := &ReferrersResult{ID: id, IncludeMinorVersions: opts.IncludeMinorVersions, Referrers: map[string][]Referrer{}}
+	requested, err := NewGtsID(id)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	matches := func(refID string) bool {
+		if refID == id {
+			return true
+		}
+		if !opts.IncludeMinorVersions {
+			return false
+		}
+		candidate, err := NewGtsID(refID)
+		return err == nil && isMinorVersionOf(candidate, requested)
+	}
+
+	for _, entity := range s.entitySnapshot() {
+		if entity.GtsID == nil {
+			continue
+		}
+		add := func(keyword, sourcePath, refID string) {
+			result.Referrers[keyword] = append(result.Referrers[keyword], Referrer{
+				ID:         entity.GtsID.ID,
+				IsSchema:   entity.IsSchema,
+				SourcePath: sourcePath,
+				RefID:      refID,
+			})
+			result.Count++
+		}
+
+		schemaIDListed := false
+		for _, ref := range entity.GtsRefs {
+			// Skip self-references, e.g. the entity ID field
+			if ref.ID == entity.GtsID.ID || !matches(ref.ID) {
+				continue
+			}
+			add(ref.Keyword, ref.SourcePath, ref.ID)
+			schemaIDListed = schemaIDListed || (ref.ID == entity.SchemaID && ref.SourcePath == entity.SelectedSchemaIDField)
+		}
+		// The schema ID derived from the entity ID chain is not found among the references
+		if entity.SchemaID != "" && !schemaIDListed && matches(entity.SchemaID) {
+			add(SchemaGraphEdgeSchemaID, entity.SelectedSchemaIDField, entity.SchemaID)
+		}
+	}
+
+	for _, referrers := range result.Referrers {
+		sort.Slice(referrers, func(i, j int) bool {
+			if referrers[i].ID != referrers[j].ID {
+				return referrers[i].ID < referrers[j].ID
+			}
+			return referrers[i].SourcePath < referrers[j].SourcePath
+		})
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"testing"
+)
+
+func TestFindReferrers(t *testing.T) {
+	store := NewGtsStore(nil)
+	register := func(content map[string]any) {
+		t.Helper()
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %v: %v", content, err)
+		}
+	}
+
+	register(map[string]any{
+		"$id":     "gts://gts.x.test.refs.event.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	})
+	register(map[string]any{
+		"$id":     "gts://gts.x.test.refs.event.v1~x.test.refs.created.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"allOf":   []any{map[string]any{"$ref": "gts.x.test.refs.event.v1~"}},
+	})
+	register(map[string]any{
+		"$id":     "gts://gts.x.test.refs.event.v1.2~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	})
+	register(map[string]any{
+		"$id":     "gts://gts.x.test.refs.subscription.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"eventType": map[string]any{"type": "string", "x-gts-ref": "gts.x.test.refs.event.v1~"},
+		},
+	})
+	register(map[string]any{"id": "gts.x.test.refs.event.v1.2~x.test.refs.inst.v1.0"})
+
+	result := store.FindReferrers("gts.x.test.refs.event.v1~")
+	if result.Error != "" {
+		t.Fatalf("FindReferrers failed: %s", result.Error)
+	}
+	if result.Count != 3 {
+		t.Errorf("Expected 3 references, got %d: %+v", result.Count, result.Referrers)
+	}
+	if refs := result.Referrers["$ref"]; len(refs) != 1 || refs[0].ID != "gts.x.test.refs.event.v1~x.test.refs.created.v1~" ||
+		refs[0].SourcePath != "allOf[0].$ref" || !refs[0].IsSchema {
+		t.Errorf("Unexpected $ref referrers: %+v", refs)
+	}
+	if refs := result.Referrers["x-gts-ref"]; len(refs) != 1 || refs[0].ID != "gts.x.test.refs.subscription.v1~" ||
+		refs[0].SourcePath != "properties.eventType.x-gts-ref" {
+		t.Errorf("Unexpected x-gts-ref referrers: %+v", refs)
+	}
+	if refs := result.Referrers[SchemaGraphEdgeSchemaID]; len(refs) != 1 || refs[0].ID != "gts.x.test.refs.event.v1~x.test.refs.created.v1~" {
+		t.Errorf("Expected the derived schema as schema_id referrer, got: %+v", refs)
+	}
+
+	result = store.FindReferrersWithOptions("gts.x.test.refs.event.v1~", ReferrersOptions{IncludeMinorVersions: true})
+	refs := result.Referrers[SchemaGraphEdgeSchemaID]
+	if len(refs) != 2 || refs[0].ID != "gts.x.test.refs.event.v1.2~x.test.refs.inst.v1.0" ||
+		refs[0].RefID != "gts.x.test.refs.event.v1.2~" || refs[0].IsSchema {
+		t.Errorf("Expected the instance of v1.2~ among the referrers, got: %+v", refs)
+	}
+
+	if result := store.FindReferrers("not-a-gts-id"); result.Error == "" {
+		t.Error("Expected an error for an invalid ID")
+	}
+}
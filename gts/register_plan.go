@@ -0,0 +1,149 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegisterPlan describes what registering an entity would do, see PlanRegister
+type RegisterPlan struct {
+	ID       string `json:"id"`
+	IsSchema bool   `json:"is_schema"`
+	// IsNew is set when no entity is registered under the ID
+	IsNew bool `json:"is_new"`
+	// Overwrites is set when an entity is registered under the ID and would be replaced
+	Overwrites bool `json:"overwrites"`
+	// Unchanged is set when the registered entity has the same content
+	Unchanged bool `json:"unchanged,omitempty"`
+	// PreviousID is the schema CompatibilityWithPrevious compares the new schema with: the schema
+	// it overwrites, or else the registered schema with the highest lower minor version of its type
+	PreviousID string `json:"previous_id,omitempty"`
+	// CompatibilityWithPrevious is the compatibility of the previous schema with the new content,
+	// nil for instances and for schemas without a previous version
+	CompatibilityWithPrevious *CompatibilityResult `json:"compatibility_with_previous,omitempty"`
+	// DependentReport is the re-validation of the instances of an overwritten schema, with
+	// RegistryConfig.RevalidateDependentsOnSchemaChange
+	DependentReport *DependentsReport `json:"dependents,omitempty"`
+	// UnresolvedRefs lists the referenced IDs missing from the store, which Register only rejects
+	// with RefValidationStrict
+	UnresolvedRefs []string `json:"unresolved_refs,omitempty"`
+	// ValidationErrors lists every check the entity fails; empty when it would be registered
+	ValidationErrors []string `json:"validation_errors"`
+}
+
+// PlanRegister reports what Register would do with entity without modifying the store: whether
+// the ID is new or overwrites a registered entity, and for schemas the compatibility with the
+// previous version. The checks of Register run and their failures are listed in ValidationErrors,
+// along with those of the schema's $ref and x-gts-ref constraints and, for instances, of the
+// validation against their schema. A panic during the checks is reported as an internal error.
+func (s *GtsStore) PlanRegister(entity *JsonEntity) (plan *RegisterPlan) {
+	plan = &RegisterPlan{ID: entity.Label, IsSchema: entity.IsSchema, ValidationErrors: []string{}}
+	if entity.GtsID == nil || entity.GtsID.ID == "" {
+		plan.ValidationErrors = append(plan.ValidationErrors, "entity must have a valid gts_id")
+		return plan
+	}
+	plan.ID = entity.GtsID.ID
+	defer func() {
+		if r := recover(); r != nil {
+			plan.ValidationErrors = append(plan.ValidationErrors, newStoreInternalError("PlanRegister", r).Error())
+		}
+	}()
+
+	existing := s.registered(plan.ID)
+	plan.IsNew = existing == nil
+	plan.Overwrites = existing != nil
+	plan.Unchanged = existing != nil && existing.ContentHash == ContentHash(entity.Content)
+
+	s.mu.RLock()
+	if s.frozen {
+		plan.ValidationErrors = append(plan.ValidationErrors, (&StoreFrozenError{Operation: "register " + plan.ID}).Error())
+	}
+	if err := s.checkShortIDLocked(plan.ID, nil); err != nil {
+		plan.ValidationErrors = append(plan.ValidationErrors, err.Error())
+	}
+	s.mu.RUnlock()
+
+	// The checks record their findings on the entity and its references; they run on a copy to
+	// leave it untouched
+	candidate := *entity
+	candidate.GtsRefs = cloneReferences(entity.GtsRefs)
+	if err := s.checkRegistration(&candidate, nil); err != nil {
+		plan.ValidationErrors = append(plan.ValidationErrors, err.Error())
+	}
+	plan.DependentReport = candidate.DependentReport
+	plan.UnresolvedRefs = unresolvedReferences(entity, s.referenceKinds(entity, nil))
+
+	if entity.IsSchema {
+		plan.ValidationErrors = append(plan.ValidationErrors, s.schemaConstraintErrors(entity)...)
+		s.planCompatibility(plan, entity, existing)
+	} else if result := s.ValidateEntity(entity); !result.OK {
+		plan.ValidationErrors = append(plan.ValidationErrors, result.Error)
+	}
+	return plan
+}
+
+// schemaConstraintErrors returns the failures of the $ref and x-gts-ref constraints of a schema
+func (s *GtsStore) schemaConstraintErrors(entity *JsonEntity) []string {
+	var errs []string
+	if refErrors := NewRefValidator().ValidateSchemaRefs(entity.Content, ""); len(refErrors) > 0 {
+		msgs := make([]string, 0, len(refErrors))
+		for _, err := range refErrors {
+			msgs = append(msgs, err.Error())
+		}
+		errs = append(errs, fmt.Sprintf("$ref validation failed: %s", strings.Join(msgs, "; ")))
+	}
+	if xGtsRefErrors := NewXGtsRefValidator(s).ValidateSchema(entity.Content, "", nil); len(xGtsRefErrors) > 0 {
+		msgs := make([]string, 0, len(xGtsRefErrors))
+		for _, err := range xGtsRefErrors {
+			msgs = append(msgs, err.Error())
+		}
+		errs = append(errs, fmt.Sprintf("x-gts-ref validation failed: %s", strings.Join(msgs, "; ")))
+	}
+	return errs
+}
+
+// planCompatibility compares a schema with the one it overwrites or, when it is new, with the
+// registered schema with the highest lower minor version of its type
+func (s *GtsStore) planCompatibility(plan *RegisterPlan, entity, existing *JsonEntity) {
+	previous := existing
+	if previous == nil {
+		previous = s.previousMinor(entity.GtsID)
+	}
+	if previous == nil || !previous.IsSchema || previous.Content == nil || entity.Content == nil {
+		return
+	}
+	plan.PreviousID = previous.GtsID.ID
+	plan.CompatibilityWithPrevious = s.compareSchemas(previous.GtsID.ID, entity.GtsID.ID, previous.Content, entity.Content)
+}
+
+// previousMinor returns the registered schema with the highest minor version lower than the one
+// of id, with the same preceding segments, vendor, package, namespace, type and major version
+func (s *GtsStore) previousMinor(id *GtsID) *JsonEntity {
+	minor := id.LastSegment().VerMinor
+	if minor == nil {
+		return nil
+	}
+	requested, err := NewGtsID(majorVersionID(id))
+	if err != nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var previous *JsonEntity
+	previousMinor := -1
+	for _, entity := range s.byID {
+		if !entity.IsSchema || !isMinorVersionOf(entity.GtsID, requested) {
+			continue
+		}
+		if m := *entity.GtsID.LastSegment().VerMinor; m < *minor && m > previousMinor {
+			previous, previousMinor = entity, m
+		}
+	}
+	return previous
+}
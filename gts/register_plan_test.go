@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanRegister(t *testing.T) {
+	store := NewGtsStore(nil)
+	v10 := map[string]any{
+		"$id":        "gts://gts.x.test.plan.item.v1.0~",
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": map[string]any{"name": map[string]any{"type": "string"}},
+	}
+	v11 := map[string]any{
+		"$id":        "gts://gts.x.test.plan.item.v1.1~",
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": map[string]any{"name": map[string]any{"type": "string"}, "size": map[string]any{"type": "integer"}},
+	}
+	instance := map[string]any{"id": "gts.x.test.plan.item.v1.0~x.test._.a.v1", "name": "a"}
+	registerTestEntities(t, store, v10, v11, instance)
+	count := store.Count()
+
+	// A new minor version is compared with the highest lower registered minor
+	v13 := map[string]any{
+		"$id":        "gts://gts.x.test.plan.item.v1.3~",
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"required":   []any{"size"},
+		"properties": map[string]any{"name": map[string]any{"type": "string"}, "size": map[string]any{"type": "integer"}},
+	}
+	plan := store.PlanRegister(NewJsonEntity(v13, DefaultGtsConfig()))
+	if !plan.IsNew || plan.Overwrites || len(plan.ValidationErrors) != 0 {
+		t.Errorf("Expected a new schema without errors, got %+v", plan)
+	}
+	if plan.PreviousID != "gts.x.test.plan.item.v1.1~" || plan.CompatibilityWithPrevious == nil || plan.CompatibilityWithPrevious.IsBackwardCompatible {
+		t.Errorf("Expected a backward incompatible change from v1.1, got %s %+v", plan.PreviousID, plan.CompatibilityWithPrevious)
+	}
+
+	// An overwritten schema is compared with its registered content
+	v10 = map[string]any{
+		"$id":        "gts://gts.x.test.plan.item.v1.0~",
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": map[string]any{"name": map[string]any{"type": "string"}, "note": map[string]any{"type": "string"}},
+	}
+	plan = store.PlanRegister(NewJsonEntity(v10, DefaultGtsConfig()))
+	if plan.IsNew || !plan.Overwrites || plan.Unchanged || plan.PreviousID != "gts.x.test.plan.item.v1.0~" {
+		t.Errorf("Expected an overwrite of v1.0, got %+v", plan)
+	}
+	if plan.CompatibilityWithPrevious == nil || !plan.CompatibilityWithPrevious.IsBackwardCompatible {
+		t.Errorf("Expected a backward compatible change, got %+v", plan.CompatibilityWithPrevious)
+	}
+
+	// An instance is validated against its schema and has no compatibility
+	plan = store.PlanRegister(NewJsonEntity(map[string]any{"id": "gts.x.test.plan.item.v1.0~x.test._.a.v1", "name": 1}, DefaultGtsConfig()))
+	if !plan.Overwrites || plan.CompatibilityWithPrevious != nil || len(plan.ValidationErrors) != 1 {
+		t.Errorf("Expected an overwrite failing validation, got %+v", plan)
+	}
+
+	// Checks of Register are reported rather than returned
+	store.Freeze()
+	plan = store.PlanRegister(NewJsonEntity(map[string]any{
+		"$id":     "gts://gts.x.test.plan.other.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"ref": map[string]any{"type": "string", "x-gts-ref": "not-a-pattern"},
+		},
+	}, DefaultGtsConfig()))
+	if !plan.IsNew || len(plan.ValidationErrors) != 2 || !strings.Contains(plan.ValidationErrors[0], "frozen") {
+		t.Errorf("Expected frozen and x-gts-ref errors, got %v", plan.ValidationErrors)
+	}
+	if plan := store.PlanRegister(NewJsonEntity(map[string]any{"name": "no id"}, DefaultGtsConfig())); len(plan.ValidationErrors) != 1 {
+		t.Errorf("Expected an error for an entity without ID, got %+v", plan)
+	}
+
+	if store.Count() != count || store.Get("gts.x.test.plan.item.v1.0~").Content["properties"].(map[string]any)["note"] != nil {
+		t.Error("Expected the store to be left unchanged")
+	}
+}
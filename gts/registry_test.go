@@ -6,7 +6,13 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -337,3 +343,573 @@ func TestRegistryIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestRefValidationWarnMode(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationWarn})
+
+	// Register the instance before the entities it points to
+	instance := NewJsonEntity(map[string]any{
+		"id":      "gts.test.pkg.ns.user.v1~test.app._.alice.v1",
+		"type":    "gts.test.pkg.ns.user.v1~",
+		"manager": "gts.test.pkg.ns.user.v1~test.app._.bob.v1",
+	}, DefaultGtsConfig())
+	if err := store.Register(instance); err != nil {
+		t.Fatalf("Warn mode should not reject registration: %v", err)
+	}
+
+	expected := []string{
+		"gts.test.pkg.ns.user.v1~",
+		"gts.test.pkg.ns.user.v1~test.app._.bob.v1",
+	}
+	got := store.Get(instance.GtsID.ID).UnresolvedRefs
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected unresolved refs %v, got %v", expected, got)
+	}
+	if store.UnresolvedRefCount() != 2 {
+		t.Errorf("Expected 2 unresolved refs, got %d", store.UnresolvedRefCount())
+	}
+
+	list := store.List(10)
+	if len(list.Entities) != 1 || len(list.Entities[0].UnresolvedRefs) != 2 {
+		t.Errorf("Expected unresolved refs in list output, got %+v", list.Entities)
+	}
+
+	// Register the missing referents; warnings remain until a re-check
+	schema := NewJsonEntity(map[string]any{
+		"$id":     "gts://gts.test.pkg.ns.user.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}, DefaultGtsConfig())
+	if err := store.Register(schema); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	if store.RecheckReferences() != 1 {
+		t.Errorf("Expected 1 unresolved ref after schema arrived, got %d", store.UnresolvedRefCount())
+	}
+
+	bob := NewJsonEntity(map[string]any{
+		"id": "gts.test.pkg.ns.user.v1~test.app._.bob.v1",
+	}, DefaultGtsConfig())
+	if err := store.Register(bob); err != nil {
+		t.Fatalf("Failed to register bob: %v", err)
+	}
+	if remaining := store.RecheckReferences(); remaining != 0 {
+		t.Errorf("Expected all references resolved, got %d", remaining)
+	}
+	if len(store.Get(instance.GtsID.ID).UnresolvedRefs) != 0 {
+		t.Error("Expected warnings to be cleared after re-check")
+	}
+	if store.UnresolvedRefCount() != 0 {
+		t.Errorf("Expected counter to be reset, got %d", store.UnresolvedRefCount())
+	}
+}
+
+func TestRecheckReferences_ConcurrentReads(t *testing.T) {
+	// Run with -race: the re-check must not modify entities that readers hold
+	const aliceID = "gts.test.pkg.ns.user.v1~test.app._.alice.v1"
+	const bobID = "gts.test.pkg.ns.user.v1~test.app._.bob.v1"
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationWarn})
+	if err := store.RegisterSchema("gts.test.pkg.ns.user.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	register := func(content map[string]any) {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	read := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					fn()
+				}
+			}
+		}()
+	}
+	read(func() {
+		if entity := store.Get(aliceID); entity != nil {
+			for _, ref := range entity.GtsRefs {
+				_ = ref.Resolved && ref.ResolvedKind != ""
+			}
+			_ = len(entity.UnresolvedRefs)
+		}
+	})
+	read(func() {
+		store.BuildSchemaGraph(aliceID)
+		store.FindReferrers(bobID)
+	})
+
+	for i := 0; i < 50; i++ {
+		if i > 0 {
+			if err := store.Unregister(bobID); err != nil {
+				t.Fatalf("Failed to unregister bob: %v", err)
+			}
+		}
+		register(map[string]any{"id": aliceID, "manager": bobID})
+		register(map[string]any{"id": bobID})
+		store.RecheckReferences()
+	}
+	close(done)
+	wg.Wait()
+
+	alice := store.Get(aliceID)
+	if len(alice.UnresolvedRefs) != 0 || store.UnresolvedRefCount() != 0 {
+		t.Errorf("Expected all references resolved, got %v", alice.UnresolvedRefs)
+	}
+	for _, ref := range alice.GtsRefs {
+		if ref.ID == bobID && (!ref.Resolved || ref.ResolvedKind != ReferenceKindInstance) {
+			t.Errorf("Expected the re-check to record the resolved reference, got %+v", ref)
+		}
+	}
+}
+
+func TestRefValidationStrictMode(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+
+	instance := NewJsonEntity(map[string]any{
+		"id":      "gts.test.pkg.ns.user.v1~test.app._.alice.v1",
+		"manager": "gts.test.pkg.ns.user.v1~test.app._.bob.v1",
+	}, DefaultGtsConfig())
+	if err := store.Register(instance); err == nil {
+		t.Error("Strict mode should reject entity with missing reference")
+	}
+}
+
+func TestRefValidation_ReaderLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// The instance file sorts first: references resolve across files once all are loaded
+		"a_users.json": `[
+			{"id": "gts.test.pkg.ns.user.v1~test.app._.alice.v1", "manager": "gts.test.pkg.ns.user.v1~test.app._.bob.v1"},
+			{"id": "gts.test.pkg.ns.user.v1~test.app._.bob.v1", "manager": "gts.test.pkg.ns.user.v1~test.app._.carol.v1"}
+		]`,
+		"b_user.schema.json": `{"$id": "gts://gts.test.pkg.ns.user.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	alice, bob := "gts.test.pkg.ns.user.v1~test.app._.alice.v1", "gts.test.pkg.ns.user.v1~test.app._.bob.v1"
+
+	warn := NewGtsStoreWithConfig(NewGtsFileReaderFromPath(dir, nil), &RegistryConfig{RefValidation: RefValidationWarn})
+	if warn.Count() != 3 || warn.UnresolvedRefCount() != 1 {
+		t.Errorf("Expected 3 entities and the missing carol reference, got %d entities and %d references", warn.Count(), warn.UnresolvedRefCount())
+	}
+	if refs := warn.Get(bob).UnresolvedRefs; len(refs) != 1 || refs[0] != "gts.test.pkg.ns.user.v1~test.app._.carol.v1" {
+		t.Errorf("Expected bob to reference the missing carol, got %v", refs)
+	}
+	if refs := warn.Get(alice).UnresolvedRefs; len(refs) != 0 {
+		t.Errorf("Expected the references of alice to resolve across files, got %v", refs)
+	}
+
+	strict := NewGtsStoreWithConfig(NewGtsFileReaderFromPath(dir, nil), &RegistryConfig{RefValidation: RefValidationStrict})
+	if strict.Count() != 2 || strict.Get(bob) != nil || strict.Get(alice) == nil {
+		t.Errorf("Expected only bob to be dropped, got %d entities", strict.Count())
+	}
+}
+
+func TestParseRefValidationMode(t *testing.T) {
+	for input, want := range map[string]RefValidationMode{
+		"":       RefValidationOff,
+		"off":    RefValidationOff,
+		"warn":   RefValidationWarn,
+		"STRICT": RefValidationStrict,
+	} {
+		got, err := ParseRefValidationMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseRefValidationMode(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseRefValidationMode("loud"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	store := NewGtsStore(nil)
+	schema := NewJsonEntity(map[string]any{
+		"$id":     "gts://gts.test.pkg.ns.user.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}, DefaultGtsConfig())
+	if err := store.Register(schema); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+
+	store.Freeze()
+	if !store.IsFrozen() {
+		t.Fatal("Expected store to be frozen")
+	}
+
+	instance := NewJsonEntity(map[string]any{"id": "gts.test.pkg.ns.user.v1~test.app._.alice.v1"}, DefaultGtsConfig())
+	var frozenErr *StoreFrozenError
+	if err := store.Register(instance); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected StoreFrozenError from Register, got %v", err)
+	}
+	if err := store.RegisterSchema("gts.test.pkg.ns.group.v1~", map[string]any{"type": "object"}); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected StoreFrozenError from RegisterSchema, got %v", err)
+	}
+
+	// Reads are unaffected
+	if store.Get("gts.test.pkg.ns.user.v1~") == nil {
+		t.Error("Expected reads to keep working on a frozen store")
+	}
+	if store.Count() != 1 {
+		t.Errorf("Expected 1 entity, got %d", store.Count())
+	}
+
+	if err := store.Unfreeze(); err == nil {
+		t.Error("Expected Unfreeze to fail without AllowUnfreeze")
+	}
+	if !store.IsFrozen() {
+		t.Error("Expected store to stay frozen")
+	}
+}
+
+func TestUnfreeze_AllowUnfreeze(t *testing.T) {
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{AllowUnfreeze: true})
+	store.Freeze()
+	if err := store.Unfreeze(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	instance := NewJsonEntity(map[string]any{"id": "gts.test.pkg.ns.user.v1~test.app._.alice.v1"}, DefaultGtsConfig())
+	if err := store.Register(instance); err != nil {
+		t.Errorf("Expected registration to succeed after Unfreeze, got %v", err)
+	}
+}
+
+func TestFreeze_ConcurrentRegistrations(t *testing.T) {
+	store := NewGtsStore(nil)
+
+	const workers = 50
+	var wg sync.WaitGroup
+	var registered atomic.Int32
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entity := NewJsonEntity(map[string]any{
+				"id": fmt.Sprintf("gts.test.pkg.ns.user.v1~test.app._.user%d.v1", i),
+			}, DefaultGtsConfig())
+			err := store.Register(entity)
+			var frozenErr *StoreFrozenError
+			switch {
+			case err == nil:
+				registered.Add(1)
+			case !errors.As(err, &frozenErr):
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}(i)
+		if i == workers/2 {
+			store.Freeze()
+		}
+	}
+	wg.Wait()
+
+	// Every registration either landed before the freeze or was rejected
+	if int(registered.Load()) != store.Count() {
+		t.Errorf("Expected %d entities, got %d", registered.Load(), store.Count())
+	}
+}
+
+// TestStoreOperations_RecoverFromPanic checks that panics inside public store operations
+// are returned as errors wrapping ErrInternal instead of propagating to the caller
+func TestStoreOperations_RecoverFromPanic(t *testing.T) {
+	// Every operation dereferences the store, so a nil store panics inside the operation
+	var broken *GtsStore
+
+	if _, err := broken.Cast("gts.x.core.events.type.v1~a.b.c.d.v1", "gts.x.core.events.type.v1~"); !errors.Is(err, ErrInternal) {
+		t.Errorf("Cast: expected error wrapping ErrInternal, got %v", err)
+	}
+
+	if result := broken.CheckCompatibility("gts.x.core.events.type.v1~", "gts.x.core.events.type.v1.1~"); !strings.Contains(result.Error, "Internal error in CheckCompatibility") {
+		t.Errorf("CheckCompatibility: expected internal error, got %q", result.Error)
+	}
+
+	if result := broken.ValidateInstance("gts.x.core.events.type.v1~a.b.c.d.v1"); result.OK || !strings.Contains(result.Error, "Internal error in ValidateInstance") {
+		t.Errorf("ValidateInstance: expected internal error, got %+v", result)
+	}
+
+	if result := broken.Query("gts.x.core.*", 10); !strings.Contains(result.Error, "Internal error in Query") {
+		t.Errorf("Query: expected internal error, got %q", result.Error)
+	}
+
+	store := NewGtsStore(nil)
+	if err := store.Register(NewJsonEntity(map[string]any{
+		"$id":     "gts.x.core.events.type.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	err := store.QueryStream("gts.x.core.*", func(item QueryItem) bool {
+		panic("callback failure")
+	})
+	var internalErr *StoreInternalError
+	if !errors.As(err, &internalErr) || internalErr.Operation != "QueryStream" || internalErr.Value != "callback failure" {
+		t.Errorf("QueryStream: expected StoreInternalError, got %v", err)
+	}
+}
+
+// TestGtsReferenceValidation_ExpectedKind checks that strict validation reports targets of the wrong kind
+func TestGtsReferenceValidation_ExpectedKind(t *testing.T) {
+	newStoreWithUser := func(t *testing.T) *GtsStore {
+		t.Helper()
+		store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+		for _, content := range []map[string]any{
+			{
+				"$id":     "gts.test.pkg.ns.user.v1~",
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type":    "object",
+			},
+			{
+				"gtsId": "gts.test.pkg.ns.user.v1~a.b.c.alice.v1",
+				"name":  "Alice",
+			},
+		} {
+			if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+				t.Fatalf("Failed to register entity: %v", err)
+			}
+		}
+		return store
+	}
+
+	t.Run("XGtsRefToTypeExpectsSchema", func(t *testing.T) {
+		store := newStoreWithUser(t)
+
+		// x-gts-ref to a type ID expects a schema, so resolving to the user schema is fine
+		ok := NewJsonEntity(map[string]any{
+			"$id":     "gts.test.pkg.ns.team.v1~",
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"properties": map[string]any{
+				"memberType": map[string]any{"x-gts-ref": "gts.test.pkg.ns.user.v1~"},
+			},
+		}, DefaultGtsConfig())
+		if err := store.Register(ok); err != nil {
+			t.Fatalf("Expected registration to succeed: %v", err)
+		}
+		for _, ref := range ok.GtsRefs {
+			if ref.ID == "gts.test.pkg.ns.user.v1~" && (!ref.Resolved || ref.ResolvedKind != ReferenceKindSchema) {
+				t.Errorf("Expected reference resolved to a schema, got %+v", ref)
+			}
+		}
+	})
+
+	t.Run("XGtsRefTargetIsInstance", func(t *testing.T) {
+		store := newStoreWithUser(t)
+
+		entity := NewJsonEntity(map[string]any{
+			"$id":     "gts.test.pkg.ns.team.v1~",
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"properties": map[string]any{
+				"lead": map[string]any{"x-gts-ref": "gts.test.pkg.ns.user.v1~a.b.c.alice.v1"},
+			},
+		}, DefaultGtsConfig())
+		if err := store.Register(entity); err != nil {
+			t.Fatalf("Expected registration to succeed: %v", err)
+		}
+		for _, ref := range entity.GtsRefs {
+			if ref.Keyword == "x-gts-ref" && (ref.ExpectedKind != ReferenceKindInstance || ref.ResolvedKind != ReferenceKindInstance) {
+				t.Errorf("Expected instance reference resolved to an instance, got %+v", ref)
+			}
+		}
+	})
+
+	t.Run("XGtsRefTargetIsSchema", func(t *testing.T) {
+		store := newStoreWithUser(t)
+
+		// A JSON Schema registered under an instance-style ID resolves to a schema
+		odd := NewJsonEntity(map[string]any{
+			"$id":     "gts.test.pkg.ns.user.v1~a.b.c.odd.v1",
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type":    "object",
+		}, DefaultGtsConfig())
+		if err := store.Register(odd); err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+
+		err := store.Register(NewJsonEntity(map[string]any{
+			"$id":     "gts.test.pkg.ns.team.v1~",
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"properties": map[string]any{
+				"lead": map[string]any{"x-gts-ref": "gts.test.pkg.ns.user.v1~a.b.c.odd.v1"},
+			},
+		}, DefaultGtsConfig()))
+		if err == nil || !strings.Contains(err.Error(), "x-gts-ref target is a schema but an instance is required") {
+			t.Errorf("Expected kind mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("RefTargetIsInstance", func(t *testing.T) {
+		store := newStoreWithUser(t)
+
+		err := store.Register(NewJsonEntity(map[string]any{
+			"$id":     "gts.test.pkg.ns.admin.v1~",
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"allOf": []any{
+				map[string]any{"$ref": "gts.test.pkg.ns.user.v1~a.b.c.alice.v1"},
+			},
+		}, DefaultGtsConfig()))
+		if err == nil || !strings.Contains(err.Error(), "$ref target is an instance but a schema is required") {
+			t.Errorf("Expected kind mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("SchemaKeywordTargetIsInstance", func(t *testing.T) {
+		store := newStoreWithUser(t)
+
+		err := store.Register(NewJsonEntity(map[string]any{
+			"gtsId":   "gts.test.pkg.ns.user.v1~a.b.c.bob.v1",
+			"$schema": "gts.test.pkg.ns.user.v1~a.b.c.alice.v1",
+		}, DefaultGtsConfig()))
+		if err == nil || !strings.Contains(err.Error(), "$schema target is an instance but a schema is required") {
+			t.Errorf("Expected kind mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("ReadOnlyResolution", func(t *testing.T) {
+		store := newStoreWithUser(t)
+
+		// Planning and validating resolve references without recording the outcome on them
+		team := NewJsonEntity(map[string]any{
+			"$id":        "gts.test.pkg.ns.team.v1~",
+			"$schema":    "https://json-schema.org/draft/2020-12/schema",
+			"properties": map[string]any{"lead": map[string]any{"x-gts-ref": "gts.test.pkg.ns.user.v1~a.b.c.alice.v1"}},
+		}, DefaultGtsConfig())
+		if plan := store.PlanRegister(team); len(plan.ValidationErrors) != 0 {
+			t.Fatalf("Expected a clean plan, got %v", plan.ValidationErrors)
+		}
+		if err := store.validateEntityGtsReferences(team, nil); err != nil {
+			t.Fatalf("Expected the references to validate, got %v", err)
+		}
+		for _, ref := range team.GtsRefs {
+			if ref.Resolved || ref.ResolvedKind != "" {
+				t.Errorf("Expected the reference to be left unresolved, got %+v", ref)
+			}
+		}
+	})
+}
+
+func TestRegisterSchema_Legacy(t *testing.T) {
+	t.Run("EmptyTypeID", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		var typeIDErr *StoreInvalidSchemaTypeIDError
+		for _, typeID := range []string{"", "gts.test.pkg.ns.item.v1"} {
+			if err := store.RegisterSchema(typeID, map[string]any{"type": "object"}); !errors.As(err, &typeIDErr) || typeIDErr.TypeID != typeID {
+				t.Errorf("Expected StoreInvalidSchemaTypeIDError for %q, got %v", typeID, err)
+			}
+		}
+	})
+
+	t.Run("MismatchedID", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		err := store.RegisterSchema("gts.test.pkg.ns.item.v1~", map[string]any{"$id": "gts://gts.test.pkg.ns.other.v1~"})
+		var mismatch *StoreSchemaIDMismatchError
+		if !errors.As(err, &mismatch) || !strings.Contains(err.Error(), "gts.test.pkg.ns.item.v1~") || !strings.Contains(err.Error(), "gts.test.pkg.ns.other.v1~") {
+			t.Errorf("Expected StoreSchemaIDMismatchError naming both IDs, got %v", err)
+		}
+		if store.Get("gts.test.pkg.ns.item.v1~") != nil {
+			t.Error("Mismatched schema should not be registered")
+		}
+
+		// A matching $id, with or without the gts:// prefix, is accepted
+		if err := store.RegisterSchema("gts.test.pkg.ns.item.v1~", map[string]any{"$id": "gts.test.pkg.ns.item.v1~"}); err != nil {
+			t.Errorf("Expected a matching $id to be accepted, got %v", err)
+		}
+	})
+
+	t.Run("RefsPopulated", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		if err := store.RegisterSchema("gts.test.pkg.ns.base.v1~", map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("Failed to register base: %v", err)
+		}
+		derived := "gts.test.pkg.ns.base.v1~test.pkg.ns.derived.v1~"
+		if err := store.RegisterSchema(derived, map[string]any{
+			"allOf": []any{map[string]any{"$ref": "gts.test.pkg.ns.base.v1~"}},
+		}); err != nil {
+			t.Fatalf("Failed to register derived: %v", err)
+		}
+		entity := store.Get(derived)
+		if !entity.IsSchema || entity.SchemaID != "gts.test.pkg.ns.base.v1~" || len(entity.GtsRefs) != 1 {
+			t.Errorf("Expected schema ID and references to be extracted, got schema=%v schemaID=%q refs=%d", entity.IsSchema, entity.SchemaID, len(entity.GtsRefs))
+		}
+		if graph := store.BuildSchemaGraph(derived); len(graph.Refs) != 1 || graph.SchemaID == nil || !graph.SchemaID.Resolved {
+			t.Errorf("Expected the relationship graph to follow the schema ID, got %+v", graph)
+		}
+	})
+
+	t.Run("PolicyParity", func(t *testing.T) {
+		schema := map[string]any{"allOf": []any{map[string]any{"$ref": "gts.test.pkg.ns.missing.v1~"}}}
+		typeID := "gts.test.pkg.ns.item.v1~"
+
+		strict := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+		registerErr := strict.Register(NewJsonEntity(map[string]any{"$id": typeID, "$schema": "https://json-schema.org/draft/2020-12/schema", "allOf": schema["allOf"]}, DefaultGtsConfig()))
+		if err := strict.RegisterSchema(typeID, schema); err == nil || registerErr == nil {
+			t.Errorf("Expected strict mode to reject the schema through both methods, got %v and %v", err, registerErr)
+		}
+
+		warn := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationWarn})
+		if err := warn.RegisterSchema(typeID, schema); err != nil {
+			t.Fatalf("Expected warn mode to register the schema, got %v", err)
+		}
+		if refs := warn.Get(typeID).UnresolvedRefs; len(refs) != 1 || refs[0] != "gts.test.pkg.ns.missing.v1~" {
+			t.Errorf("Expected one unresolved reference, got %v", refs)
+		}
+	})
+}
+
+// TestStore_ConcurrentAccess hammers the store from many goroutines; run with -race
+func TestStore_ConcurrentAccess(t *testing.T) {
+	store := NewGtsStore(nil)
+	schema := NewJsonEntity(map[string]any{
+		"$id":     "gts://gts.test.pkg.ns.user.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"id":   map[string]any{"type": "string"},
+			"name": map[string]any{"type": "string"},
+		},
+	}, DefaultGtsConfig())
+	if err := store.Register(schema); err != nil {
+		t.Fatalf("Register schema: %v", err)
+	}
+
+	const workers = 50
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("gts.test.pkg.ns.user.v1~test.app._.user%d.v1", i)
+			entity := NewJsonEntity(map[string]any{"id": id, "name": fmt.Sprintf("user %d", i)}, DefaultGtsConfig())
+			if err := store.Register(entity); err != nil {
+				t.Errorf("Register %s: %v", id, err)
+				return
+			}
+			if result := store.Query("gts.test.pkg.ns.user.v1~*", 0); result.Error != "" {
+				t.Errorf("Query: %s", result.Error)
+			}
+			store.List(10)
+			for range store.Items() {
+			}
+			if result := store.ValidateInstance(id); !result.OK {
+				t.Errorf("ValidateInstance %s: %s", id, result.Error)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if store.Count() != workers+1 {
+		t.Errorf("Expected %d entities, got %d", workers+1, store.Count())
+	}
+}
@@ -5,42 +5,307 @@ Released under Apache License 2.0
 
 package gts
 
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema graph output formats
+const (
+	SchemaGraphFormatTree  = "tree"
+	SchemaGraphFormatEdges = "edges"
+)
+
+// Schema graph edge kinds
+const (
+	SchemaGraphEdgeRef      = "ref"
+	SchemaGraphEdgeSchemaID = "schema_id"
+	// SchemaGraphEdgeSchema leads from an instance to its schema and SchemaGraphEdgeGtsRef from an
+	// instance to the entity named by a value its schema constrains with x-gts-ref
+	SchemaGraphEdgeSchema = "schema"
+	SchemaGraphEdgeGtsRef = "gts-ref"
+)
+
 // SchemaGraphNode represents a node in the schema relationship graph
+// Keyword, ExpectedKind and Edge, one of the SchemaGraphEdge kinds, describe the edge leading to
+// the node and are empty for the root.
+// Truncated marks a node whose outgoing edges were cut by a depth or node limit.
+// ResolvedID is the schema a schema ID without minor version resolved to (see GtsStore.ResolveSchema).
 type SchemaGraphNode struct {
-	ID       string                      `json:"id"`
-	Refs     map[string]*SchemaGraphNode `json:"refs,omitempty"`
-	SchemaID *SchemaGraphNode            `json:"schema_id,omitempty"`
-	Errors   []string                    `json:"errors,omitempty"`
+	ID           string                      `json:"id"`
+	Keyword      string                      `json:"keyword,omitempty"`
+	ExpectedKind ReferenceKind               `json:"expected_kind,omitempty"`
+	Edge         string                      `json:"edge,omitempty"`
+	Resolved     bool                        `json:"resolved"`
+	ResolvedKind ReferenceKind               `json:"resolved_kind,omitempty"`
+	ResolvedID   string                      `json:"resolved_id,omitempty"`
+	Refs         map[string]*SchemaGraphNode `json:"refs,omitempty"`
+	SchemaID     *SchemaGraphNode            `json:"schema_id,omitempty"`
+	Errors       []string                    `json:"errors,omitempty"`
+	Truncated    bool                        `json:"truncated,omitempty"`
+}
+
+// SchemaGraphOptions limits the traversal of BuildSchemaGraphWithOptions
+type SchemaGraphOptions struct {
+	// MaxDepth is the maximum number of edges between the root and any node; 0 means unlimited
+	MaxDepth int
+	// MaxNodes is the maximum number of nodes in the graph, including the root; 0 means unlimited
+	MaxNodes int
+	// Format is SchemaGraphFormatTree (default) or SchemaGraphFormatEdges
+	Format string
+}
+
+// SchemaGraphEdge is a single relationship of a flattened schema graph
+type SchemaGraphEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Kind       string `json:"kind"`
+	SourcePath string `json:"source_path"`
+}
+
+// SchemaGraphResult is a schema graph built with traversal limits
+// Truncated is set whenever a limit cut the graph short: DepthTruncated counts the nodes left
+// unexpanded at the depth limit and OmittedEdges the edges dropped once the node limit was reached.
+type SchemaGraphResult struct {
+	ID             string            `json:"id"`
+	Format         string            `json:"format"`
+	Graph          *SchemaGraphNode  `json:"graph,omitempty"`
+	Edges          []SchemaGraphEdge `json:"edges,omitempty"`
+	NodeCount      int               `json:"node_count"`
+	EdgeCount      int               `json:"edge_count"`
+	Truncated      bool              `json:"truncated"`
+	DepthTruncated int               `json:"depth_truncated,omitempty"`
+	OmittedEdges   int               `json:"omitted_edges,omitempty"`
+	MaxDepth       int               `json:"max_depth,omitempty"`
+	MaxNodes       int               `json:"max_nodes,omitempty"`
 }
 
 // BuildSchemaGraph recursively builds a relationship graph for a GTS entity
 // This matches Python's build_schema_graph method in store.py
+// The graph of an instance starts with the edge to its schema, followed by the schema's own
+// references and base types, then the values of the instance its schema constrains with
+// x-gts-ref, and any other GTS ID the instance holds.
 func (s *GtsStore) BuildSchemaGraph(gtsID string) *SchemaGraphNode {
-	seen := make(map[string]bool)
-	return s.buildNode(gtsID, seen)
+	b := &schemaGraphBuilder{store: s, seen: make(map[string]bool)}
+	return b.buildNode(gtsID, 0)
+}
+
+// BuildSchemaGraphWithOptions builds the relationship graph of a GTS entity within the given
+// depth and node limits, either as the nested tree of BuildSchemaGraph or as a flat edge list.
+// Traversal is depth-first in reference order, so the same store state always yields the same graph.
+func (s *GtsStore) BuildSchemaGraphWithOptions(gtsID string, opts SchemaGraphOptions) (*SchemaGraphResult, error) {
+	if opts.MaxDepth < 0 || opts.MaxNodes < 0 {
+		return nil, fmt.Errorf("schema graph limits must not be negative")
+	}
+	format := opts.Format
+	if format == "" {
+		format = SchemaGraphFormatTree
+	}
+	if format != SchemaGraphFormatTree && format != SchemaGraphFormatEdges {
+		return nil, fmt.Errorf("unknown schema graph format '%s', expected %s or %s", format, SchemaGraphFormatTree, SchemaGraphFormatEdges)
+	}
+
+	b := &schemaGraphBuilder{store: s, seen: make(map[string]bool), opts: opts}
+	root := b.buildNode(gtsID, 0)
+	edges := SchemaGraphEdges(root)
+
+	result := &SchemaGraphResult{
+		ID:             gtsID,
+		Format:         format,
+		NodeCount:      b.nodes,
+		EdgeCount:      len(edges),
+		Truncated:      b.depthTruncated > 0 || b.omittedEdges > 0,
+		DepthTruncated: b.depthTruncated,
+		OmittedEdges:   b.omittedEdges,
+		MaxDepth:       opts.MaxDepth,
+		MaxNodes:       opts.MaxNodes,
+	}
+	if format == SchemaGraphFormatEdges {
+		result.Edges = edges
+	} else {
+		result.Graph = root
+	}
+	return result, nil
+}
+
+// SchemaGraphEdges flattens a schema graph into its edges, parents before children
+// Edges of a node are ordered with its refs by source path first and its schema ID edge last,
+// except for instances whose edge to their schema comes first.
+func SchemaGraphEdges(root *SchemaGraphNode) []SchemaGraphEdge {
+	edges := make([]SchemaGraphEdge, 0)
+	var walk func(node *SchemaGraphNode)
+	schemaEdge := func(node *SchemaGraphNode) {
+		kind := node.SchemaID.Edge
+		if kind == "" {
+			kind = SchemaGraphEdgeSchemaID
+		}
+		edges = append(edges, SchemaGraphEdge{From: node.ID, To: node.SchemaID.ID, Kind: kind, SourcePath: node.SchemaID.Keyword})
+		walk(node.SchemaID)
+	}
+	walk = func(node *SchemaGraphNode) {
+		instanceSchema := node.SchemaID != nil && node.SchemaID.Edge == SchemaGraphEdgeSchema
+		if instanceSchema {
+			schemaEdge(node)
+		}
+		paths := make([]string, 0, len(node.Refs))
+		for path := range node.Refs {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			child := node.Refs[path]
+			kind := child.Edge
+			if kind == "" {
+				kind = SchemaGraphEdgeRef
+			}
+			edges = append(edges, SchemaGraphEdge{From: node.ID, To: child.ID, Kind: kind, SourcePath: path})
+			walk(child)
+		}
+		if node.SchemaID != nil && !instanceSchema {
+			schemaEdge(node)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	return edges
+}
+
+// WriteSchemaGraphDOT writes a schema graph in the Graphviz DOT language, e.g. for rendering with
+// `dot -Tsvg`. Nodes are named by GTS ID and filled blue for schemas and yellow for instances;
+// unresolved nodes are dashed and nodes cut short by a limit are marked "(truncated)". Edges are
+// labelled with their source path, schema ID edges being dashed.
+func WriteSchemaGraphDOT(w io.Writer, root *SchemaGraphNode) error {
+	// A node reached through several edges is declared once, truncated if any occurrence is
+	nodes := make(map[string]*SchemaGraphNode)
+	truncated := make(map[string]bool)
+	var order []string
+	var collect func(node *SchemaGraphNode)
+	collect = func(node *SchemaGraphNode) {
+		if _, ok := nodes[node.ID]; !ok || (node.Resolved && !nodes[node.ID].Resolved) {
+			if !ok {
+				order = append(order, node.ID)
+			}
+			nodes[node.ID] = node
+		}
+		truncated[node.ID] = truncated[node.ID] || node.Truncated
+		for _, path := range sortedKeys(node.Refs) {
+			collect(node.Refs[path])
+		}
+		if node.SchemaID != nil {
+			collect(node.SchemaID)
+		}
+	}
+	if root != nil {
+		collect(root)
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph gts {")
+	fmt.Fprintln(out, "  node [shape=box, style=filled];")
+	for _, id := range order {
+		node := nodes[id]
+		label := id
+		if truncated[id] {
+			label += "\\n(truncated)"
+		}
+		kind := node.ResolvedKind
+		if !node.Resolved {
+			kind = ReferenceKindInstance
+			if strings.HasSuffix(id, "~") {
+				kind = ReferenceKindSchema
+			}
+		}
+		color := "lightyellow"
+		if kind == ReferenceKindSchema {
+			color = "lightblue"
+		}
+		style := "filled"
+		if !node.Resolved {
+			style = "filled,dashed"
+		}
+		fmt.Fprintf(out, "  %s [label=\"%s\", fillcolor=%s, style=\"%s\"];\n", strconv.Quote(id), dotEscape(label), color, style)
+	}
+	for _, edge := range SchemaGraphEdges(root) {
+		attrs := fmt.Sprintf("label=%s", strconv.Quote(edge.SourcePath))
+		if edge.Kind == SchemaGraphEdgeSchemaID || edge.Kind == SchemaGraphEdgeSchema {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(out, "  %s -> %s [%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), attrs)
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// dotEscape escapes the double quotes of a DOT string, keeping escape sequences such as \n
+func dotEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// schemaGraphBuilder holds the traversal state of a single schema graph build
+type schemaGraphBuilder struct {
+	store *GtsStore
+	seen  map[string]bool
+	opts  SchemaGraphOptions
+
+	nodes          int
+	depthTruncated int
+	omittedEdges   int
 }
 
 // buildNode recursively builds a single node in the graph
-func (s *GtsStore) buildNode(gtsID string, seen map[string]bool) *SchemaGraphNode {
+func (b *schemaGraphBuilder) buildNode(gtsID string, depth int) *SchemaGraphNode {
+	b.nodes++
 	node := &SchemaGraphNode{
 		ID: gtsID,
 	}
 
+	// Get the entity from store; a schema ID without minor version resolves to its latest minor version
+	entity := b.store.lookupSchema(gtsID)
+	if entity != nil {
+		node.Resolved = true
+		node.ResolvedKind = entityReferenceKind(entity)
+		if entity.GtsID != nil && entity.GtsID.ID != gtsID {
+			node.ResolvedID = entity.GtsID.ID
+		}
+	}
+
 	// Check for cycles
-	if seen[gtsID] {
+	if b.seen[gtsID] {
 		return node
 	}
-	seen[gtsID] = true
 
-	// Get the entity from store
-	entity := s.Get(gtsID)
 	if entity == nil {
+		b.seen[gtsID] = true
 		node.Errors = append(node.Errors, "Entity not found")
 		return node
 	}
 
-	// Process GTS references found in the entity
-	refs := make(map[string]*SchemaGraphNode)
+	// Collect the outgoing edges: GTS references found in the entity, then its schema ID
+	var schemaRef *GtsReference
+	if entity.SchemaID != "" && !isJSONSchemaURL(entity.SchemaID) {
+		schemaRef = &GtsReference{
+			ID:           entity.SchemaID,
+			SourcePath:   entity.SelectedSchemaIDField,
+			Keyword:      entity.SelectedSchemaIDField,
+			ExpectedKind: ReferenceKindSchema,
+		}
+	}
+	if entity.SchemaID == "" && !entity.IsSchema {
+		// Instance without schema ID is an error
+		node.Errors = append(node.Errors, "Schema not recognized")
+	}
+	var xGtsRefPaths map[string]bool
+	if !entity.IsSchema && schemaRef != nil {
+		xGtsRefPaths = b.store.instanceXGtsRefPaths(entity)
+	}
+
+	var edges []*GtsReference
+	edgeKinds := make(map[*GtsReference]string)
 	for _, ref := range entity.GtsRefs {
 		// Skip self-references
 		if ref.ID == gtsID {
@@ -50,29 +315,107 @@ func (s *GtsStore) buildNode(gtsID string, seen map[string]bool) *SchemaGraphNod
 		if isJSONSchemaURL(ref.ID) {
 			continue
 		}
-		// Recursively build node for this reference
-		refs[ref.SourcePath] = s.buildNode(ref.ID, seen)
+		if !entity.IsSchema {
+			// The schema-ID fields declaring the schema of an instance are the edge to its schema
+			if schemaRef != nil && ref.ID == entity.SchemaID && declaresSchemaID(entity, ref.SourcePath) {
+				continue
+			}
+			if xGtsRefPaths[ref.SourcePath] {
+				gtsRef := *ref
+				gtsRef.ExpectedKind = expectedReferenceKind("x-gts-ref", ref.ID)
+				ref = &gtsRef
+				edgeKinds[ref] = SchemaGraphEdgeGtsRef
+			}
+		}
+		edges = append(edges, ref)
+	}
+
+	// Nodes at the depth limit are not expanded; they stay unseen so a shorter path may expand them
+	if b.opts.MaxDepth > 0 && depth >= b.opts.MaxDepth {
+		if len(edges) > 0 || schemaRef != nil {
+			node.Truncated = true
+			b.depthTruncated++
+		}
+		return node
+	}
+	b.seen[gtsID] = true
+
+	// The schema of an instance comes first, so that node limits keep it
+	if schemaRef != nil && !entity.IsSchema {
+		node.SchemaID = b.buildEdge(node, schemaRef, SchemaGraphEdgeSchema, depth+1)
+	}
+
+	// Recursively build the node of each reference
+	refs := make(map[string]*SchemaGraphNode)
+	for _, ref := range edges {
+		kind := edgeKinds[ref]
+		if kind == "" {
+			kind = SchemaGraphEdgeRef
+		}
+		if child := b.buildEdge(node, ref, kind, depth+1); child != nil {
+			refs[ref.SourcePath] = child
+		}
 	}
 	if len(refs) > 0 {
 		node.Refs = refs
 	}
+	if schemaRef != nil && entity.IsSchema {
+		node.SchemaID = b.buildEdge(node, schemaRef, SchemaGraphEdgeSchemaID, depth+1)
+	}
 
-	// Process schema ID if present
-	if entity.SchemaID != "" {
-		if !isJSONSchemaURL(entity.SchemaID) {
-			node.SchemaID = s.buildNode(entity.SchemaID, seen)
-		}
-	} else if !entity.IsSchema {
-		// Instance without schema ID is an error
-		node.Errors = append(node.Errors, "Schema not recognized")
+	return node
+}
+
+// buildEdge builds the node a reference points to through an edge of the given kind and annotates
+// it with the reference context
+// Once the node limit is reached the edge is omitted, the parent is marked truncated and nil is returned.
+func (b *schemaGraphBuilder) buildEdge(parent *SchemaGraphNode, ref *GtsReference, kind string, depth int) *SchemaGraphNode {
+	if b.opts.MaxNodes > 0 && b.nodes >= b.opts.MaxNodes {
+		parent.Truncated = true
+		b.omittedEdges++
+		return nil
 	}
 
+	node := b.buildNode(ref.ID, depth)
+	node.Keyword = ref.Keyword
+	node.ExpectedKind = ref.ExpectedKind
+	node.Edge = kind
+
+	if mismatch := referenceKindMismatch(ref, node.ResolvedKind); mismatch != "" {
+		node.Errors = append(node.Errors, mismatch)
+	}
 	return node
 }
 
+// declaresSchemaID reports whether the value at path of an instance is one of the schema-ID fields
+// its schema ID was selected from or agrees with
+func declaresSchemaID(entity *JsonEntity, path string) bool {
+	if path == entity.SelectedSchemaIDField {
+		return true
+	}
+	for _, source := range entity.schemaSources {
+		if source.Field == path && source.Value == entity.SchemaID {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceXGtsRefPaths returns the paths of the instance values its schema constrains with
+// x-gts-ref, found the way instance validation finds them, or nil when the schema is not registered
+func (s *GtsStore) instanceXGtsRefPaths(entity *JsonEntity) map[string]bool {
+	schema := s.lookupSchema(entity.SchemaID)
+	if schema == nil || !schema.IsSchema {
+		return nil
+	}
+	paths := make(map[string]bool)
+	v := &XGtsRefValidator{store: s, patternsOnly: true, visitRef: func(path, _ string) { paths[path] = true }}
+	var errs []*XGtsRefValidationError
+	v.visitInstance(entity.Content, schema.Content, "", schema.Content, make(map[string]bool), &errs)
+	return paths
+}
+
 // isJSONSchemaURL checks if a string is a JSON Schema meta-schema URL
 func isJSONSchemaURL(s string) bool {
-	return len(s) > 7 && (s[:7] == "http://" || s[:8] == "https://") &&
-		(len(s) > 22 && s[:23] == "http://json-schema.org" ||
-			len(s) > 23 && s[:24] == "https://json-schema.org")
+	return strings.HasPrefix(s, "http://json-schema.org") || strings.HasPrefix(s, "https://json-schema.org")
 }
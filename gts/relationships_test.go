@@ -6,6 +6,10 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -330,3 +334,391 @@ func TestExtractGtsReferences(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractGtsReferences_KeywordAndExpectedKind(t *testing.T) {
+	content := map[string]any{
+		"$id":     "gts.x.test.core.schema.v1~",
+		"$schema": "gts.x.test.core.meta.v1~",
+		"allOf": []any{
+			map[string]any{"$ref": "gts.x.test.core.base.v1~"},
+		},
+		"properties": map[string]any{
+			"owner":  map[string]any{"x-gts-ref": "gts.x.test.core.user.v1~a.b.c.d.v1"},
+			"kind":   map[string]any{"x-gts-ref": "gts.x.test.core.kind.v1~"},
+			"type":   map[string]any{"const": "gts.x.test.core.event.v1~"},
+			"status": map[string]any{"enum": []any{"gts.x.test.core.status.v1~a.b.c.active.v1"}},
+		},
+	}
+
+	tests := []struct {
+		path     string
+		keyword  string
+		expected ReferenceKind
+	}{
+		{"$id", "$id", ReferenceKindAny},
+		{"$schema", "$schema", ReferenceKindSchema},
+		{"allOf[0].$ref", "$ref", ReferenceKindSchema},
+		{"properties.owner.x-gts-ref", "x-gts-ref", ReferenceKindInstance},
+		{"properties.kind.x-gts-ref", "x-gts-ref", ReferenceKindSchema},
+		{"properties.type.const", "const", ReferenceKindAny},
+		{"properties.status.enum[0]", "enum", ReferenceKindAny},
+	}
+
+	byPath := make(map[string]*GtsReference)
+	for _, ref := range extractGtsReferences(content) {
+		byPath[ref.SourcePath] = ref
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ref, ok := byPath[tt.path]
+			if !ok {
+				t.Fatalf("Expected reference at %s", tt.path)
+			}
+			if ref.Keyword != tt.keyword {
+				t.Errorf("Expected keyword %q, got %q", tt.keyword, ref.Keyword)
+			}
+			if ref.ExpectedKind != tt.expected {
+				t.Errorf("Expected kind %q, got %q", tt.expected, ref.ExpectedKind)
+			}
+			if ref.Resolved || ref.ResolvedKind != "" {
+				t.Errorf("Expected reference to be unresolved before store validation, got %+v", ref)
+			}
+		})
+	}
+}
+
+func TestBuildSchemaGraph_EdgeKinds(t *testing.T) {
+	store := NewGtsStore(nil)
+
+	entities := []map[string]any{
+		{
+			"$id":     "gts.x.test8.graph.user.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		},
+		{
+			"id":   "gts.x.test8.graph.user.v1~x.test8._.alice.v1",
+			"type": "gts.x.test8.graph.user.v1~",
+		},
+		{
+			"$id":     "gts.x.test8.graph.order.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				// x-gts-ref to an instance ID expects an instance
+				"owner": map[string]any{"x-gts-ref": "gts.x.test8.graph.user.v1~x.test8._.alice.v1"},
+				// x-gts-ref to a type ID expects a schema
+				"manager": map[string]any{"x-gts-ref": "gts.x.test8.graph.user.v1~"},
+				// $ref expects a schema but points to an instance
+				"base": map[string]any{"$ref": "gts.x.test8.graph.user.v1~x.test8._.alice.v1"},
+			},
+		},
+	}
+
+	registerTestEntities(t, store, entities...)
+
+	graph := store.BuildSchemaGraph("gts.x.test8.graph.order.v1~")
+	if !graph.Resolved || graph.ResolvedKind != ReferenceKindSchema {
+		t.Errorf("Expected resolved schema root, got %+v", graph)
+	}
+
+	owner := graph.Refs["properties.owner.x-gts-ref"]
+	if owner == nil {
+		t.Fatal("Expected owner edge")
+	}
+	if owner.Keyword != "x-gts-ref" || owner.ExpectedKind != ReferenceKindInstance || owner.ResolvedKind != ReferenceKindInstance {
+		t.Errorf("Unexpected owner edge: %+v", owner)
+	}
+	if len(owner.Errors) != 0 {
+		t.Errorf("Expected no errors on owner edge, got %v", owner.Errors)
+	}
+
+	manager := graph.Refs["properties.manager.x-gts-ref"]
+	if manager == nil || manager.ExpectedKind != ReferenceKindSchema || manager.ResolvedKind != ReferenceKindSchema {
+		t.Errorf("Unexpected manager edge: %+v", manager)
+	}
+
+	base := graph.Refs["properties.base.$ref"]
+	if base == nil {
+		t.Fatal("Expected base edge")
+	}
+	if base.Keyword != "$ref" || base.ExpectedKind != ReferenceKindSchema || base.ResolvedKind != ReferenceKindInstance {
+		t.Errorf("Unexpected base edge: %+v", base)
+	}
+	if len(base.Errors) != 1 || !strings.Contains(base.Errors[0], "$ref target is an instance but a schema is required") {
+		t.Errorf("Expected kind mismatch error on base edge, got %v", base.Errors)
+	}
+
+	missing := store.BuildSchemaGraph("gts.x.test8.graph.missing.v1~")
+	if missing.Resolved {
+		t.Error("Expected missing entity to be unresolved")
+	}
+}
+
+func TestBuildSchemaGraph_Instance(t *testing.T) {
+	store := NewGtsStore(nil)
+	const (
+		placedID  = "gts.x.core.events.type.v1~x.shop.orders.placed.v1.0~"
+		eventID   = placedID + "x.shop._.e1.v1"
+		payID     = "gts.x.core.caps.cap.v1~x.shop._.pay.v1"
+		missingID = "gts.x.core.caps.cap.v1~x.shop._.refund.v1"
+	)
+	entities := []map[string]any{
+		{"$id": "gts://gts.x.core.events.type.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"},
+		{"$id": "gts://gts.x.core.caps.cap.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"},
+		{
+			"$id":     "gts://" + placedID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				"capabilities": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string", "x-gts-ref": "gts.x.core.caps.cap.v1~"},
+				},
+			},
+		},
+		{"id": payID, "next": eventID},
+		{
+			"id":           eventID,
+			"type":         placedID,
+			"capabilities": []any{payID, missingID},
+			"note":         "gts.x.core.caps.cap.v1~",
+		},
+	}
+	registerTestEntities(t, store, entities...)
+
+	graph := store.BuildSchemaGraph(eventID)
+	if graph.ID != eventID || graph.ResolvedKind != ReferenceKindInstance || len(graph.Errors) != 0 {
+		t.Fatalf("Expected the instance as root, got %+v", graph)
+	}
+
+	// The schema edge leads on to the base type of the schema
+	schema := graph.SchemaID
+	if schema == nil || schema.ID != placedID || schema.Edge != SchemaGraphEdgeSchema {
+		t.Fatalf("Expected a schema edge to %s, got %+v", placedID, schema)
+	}
+	if schema.SchemaID == nil || schema.SchemaID.ID != "gts.x.core.events.type.v1~" || schema.SchemaID.Edge != SchemaGraphEdgeSchemaID {
+		t.Errorf("Expected the schema to lead to its base type, got %+v", schema.SchemaID)
+	}
+	if _, ok := graph.Refs["type"]; ok {
+		t.Error("Expected the schema ID field not to be repeated as a ref")
+	}
+
+	pay := graph.Refs["capabilities[0]"]
+	if pay == nil || pay.Edge != SchemaGraphEdgeGtsRef || pay.ExpectedKind != ReferenceKindInstance || !pay.Resolved {
+		t.Fatalf("Expected a resolved gts-ref edge to %s, got %+v", payID, pay)
+	}
+	// The capability refers back to the event: the cycle ends at the already visited instance
+	if back := pay.Refs["next"]; back == nil || back.ID != eventID || back.SchemaID != nil || back.Refs != nil {
+		t.Errorf("Expected the cycle back to the event to end, got %+v", back)
+	}
+	refund := graph.Refs["capabilities[1]"]
+	if refund == nil || refund.Edge != SchemaGraphEdgeGtsRef || refund.Resolved || len(refund.Errors) != 1 || refund.Errors[0] != "Entity not found" {
+		t.Errorf("Expected an unresolved gts-ref edge to %s, got %+v", missingID, refund)
+	}
+	if note := graph.Refs["note"]; note == nil || note.Edge != SchemaGraphEdgeRef {
+		t.Errorf("Expected other GTS IDs of the instance to be ref edges, got %+v", note)
+	}
+
+	edges := SchemaGraphEdges(graph)
+	if edges[0] != (SchemaGraphEdge{From: eventID, To: placedID, Kind: SchemaGraphEdgeSchema, SourcePath: "id"}) {
+		t.Errorf("Expected the schema edge first, got %+v", edges[0])
+	}
+}
+
+// newSyntheticGraphStore registers a tree of schemas where every schema above the last level
+// references width distinct child schemas; the root is graphNodeID(0, 0)
+func newSyntheticGraphStore(t *testing.T, width, depth int) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	count := 1
+	for level := 0; level <= depth; level++ {
+		for i := 0; i < count; i++ {
+			properties := map[string]any{}
+			if level < depth {
+				for c := 0; c < width; c++ {
+					properties[fmt.Sprintf("child%d", c)] = map[string]any{"$ref": graphNodeID(level+1, i*width+c)}
+				}
+			}
+			schema := map[string]any{
+				"$id":        graphNodeID(level, i),
+				"$schema":    "http://json-schema.org/draft-07/schema#",
+				"type":       "object",
+				"properties": properties,
+			}
+			if err := store.Register(NewJsonEntity(schema, DefaultGtsConfig())); err != nil {
+				t.Fatalf("Failed to register synthetic schema: %v", err)
+			}
+		}
+		count *= width
+	}
+	return store
+}
+
+func graphNodeID(level, i int) string {
+	return fmt.Sprintf("gts.x.graph.nodes.n%d_%d.v1~", level, i)
+}
+
+// graphStats returns the node count and maximum depth of a schema graph
+func graphStats(node *SchemaGraphNode, depth int) (nodes, maxDepth int) {
+	nodes, maxDepth = 1, depth
+	children := make([]*SchemaGraphNode, 0, len(node.Refs)+1)
+	for _, child := range node.Refs {
+		children = append(children, child)
+	}
+	if node.SchemaID != nil {
+		children = append(children, node.SchemaID)
+	}
+	for _, child := range children {
+		n, d := graphStats(child, depth+1)
+		nodes += n
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return nodes, maxDepth
+}
+
+func TestBuildSchemaGraphWithOptions_Unlimited(t *testing.T) {
+	store := newSyntheticGraphStore(t, 3, 4)
+	root := graphNodeID(0, 0)
+
+	result, err := store.BuildSchemaGraphWithOptions(root, SchemaGraphOptions{})
+	if err != nil {
+		t.Fatalf("BuildSchemaGraphWithOptions failed: %v", err)
+	}
+	if result.Truncated || result.NodeCount != 121 || result.EdgeCount != 120 {
+		t.Errorf("Expected the full graph, got truncated=%v nodes=%d edges=%d", result.Truncated, result.NodeCount, result.EdgeCount)
+	}
+	if !reflect.DeepEqual(result.Graph, store.BuildSchemaGraph(root)) {
+		t.Error("Expected the unlimited tree to equal BuildSchemaGraph")
+	}
+}
+
+func TestBuildSchemaGraphWithOptions_MaxDepth(t *testing.T) {
+	store := newSyntheticGraphStore(t, 3, 4)
+
+	result, err := store.BuildSchemaGraphWithOptions(graphNodeID(0, 0), SchemaGraphOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("BuildSchemaGraphWithOptions failed: %v", err)
+	}
+	if !result.Truncated || result.DepthTruncated != 9 || result.OmittedEdges != 0 {
+		t.Errorf("Expected 9 depth-truncated nodes, got %+v", result)
+	}
+	nodes, depth := graphStats(result.Graph, 0)
+	if nodes != 13 || result.NodeCount != 13 || depth != 2 {
+		t.Errorf("Expected 13 nodes up to depth 2, got %d (reported %d) up to depth %d", nodes, result.NodeCount, depth)
+	}
+	leaf := result.Graph.Refs["properties.child0.$ref"].Refs["properties.child1.$ref"]
+	if leaf == nil || !leaf.Truncated || leaf.Refs != nil {
+		t.Errorf("Expected a truncated leaf at the depth limit, got %+v", leaf)
+	}
+
+	// The last level has no references to cut
+	result, _ = store.BuildSchemaGraphWithOptions(graphNodeID(0, 0), SchemaGraphOptions{MaxDepth: 4})
+	if result.Truncated || result.NodeCount != 121 {
+		t.Errorf("Expected no truncation at the graph depth, got %+v", result)
+	}
+}
+
+func TestBuildSchemaGraphWithOptions_MaxNodes(t *testing.T) {
+	store := newSyntheticGraphStore(t, 10, 2)
+
+	result, err := store.BuildSchemaGraphWithOptions(graphNodeID(0, 0), SchemaGraphOptions{MaxNodes: 25})
+	if err != nil {
+		t.Fatalf("BuildSchemaGraphWithOptions failed: %v", err)
+	}
+	nodes, _ := graphStats(result.Graph, 0)
+	if nodes != 25 || result.NodeCount != 25 || result.EdgeCount != 24 {
+		t.Errorf("Expected 25 nodes and 24 edges, got %d nodes (reported %d), %d edges", nodes, result.NodeCount, result.EdgeCount)
+	}
+	if !result.Truncated || result.OmittedEdges == 0 || result.DepthTruncated != 0 || !result.Graph.Truncated {
+		t.Errorf("Expected omitted edges with a truncated root, got %+v", result)
+	}
+}
+
+func TestBuildSchemaGraphWithOptions_EdgesMatchTree(t *testing.T) {
+	store := newSyntheticGraphStore(t, 4, 3)
+	opts := SchemaGraphOptions{MaxDepth: 2, MaxNodes: 15}
+
+	tree, err := store.BuildSchemaGraphWithOptions(graphNodeID(0, 0), opts)
+	if err != nil {
+		t.Fatalf("BuildSchemaGraphWithOptions failed: %v", err)
+	}
+	opts.Format = SchemaGraphFormatEdges
+	flat, err := store.BuildSchemaGraphWithOptions(graphNodeID(0, 0), opts)
+	if err != nil {
+		t.Fatalf("BuildSchemaGraphWithOptions failed: %v", err)
+	}
+
+	if flat.Graph != nil || tree.Edges != nil {
+		t.Error("Expected each format to carry only its own representation")
+	}
+	if flat.NodeCount != tree.NodeCount || flat.Truncated != tree.Truncated || flat.OmittedEdges != tree.OmittedEdges {
+		t.Errorf("Expected identical counts, got tree %+v and edges %+v", tree, flat)
+	}
+
+	// Every parent/child pair of the tree appears exactly once as an edge
+	expected := make(map[SchemaGraphEdge]bool)
+	var walk func(node *SchemaGraphNode)
+	walk = func(node *SchemaGraphNode) {
+		for path, child := range node.Refs {
+			expected[SchemaGraphEdge{From: node.ID, To: child.ID, Kind: SchemaGraphEdgeRef, SourcePath: path}] = true
+			walk(child)
+		}
+		if node.SchemaID != nil {
+			expected[SchemaGraphEdge{From: node.ID, To: node.SchemaID.ID, Kind: SchemaGraphEdgeSchemaID, SourcePath: node.SchemaID.Keyword}] = true
+			walk(node.SchemaID)
+		}
+	}
+	walk(tree.Graph)
+	if len(flat.Edges) != len(expected) || flat.EdgeCount != len(expected) {
+		t.Fatalf("Expected %d edges, got %d", len(expected), len(flat.Edges))
+	}
+	for _, edge := range flat.Edges {
+		if !expected[edge] {
+			t.Errorf("Unexpected edge %+v", edge)
+		}
+	}
+}
+
+func TestBuildSchemaGraphWithOptions_InvalidOptions(t *testing.T) {
+	store := newSyntheticGraphStore(t, 1, 1)
+	for _, opts := range []SchemaGraphOptions{{MaxDepth: -1}, {MaxNodes: -1}, {Format: "nested"}} {
+		if _, err := store.BuildSchemaGraphWithOptions(graphNodeID(0, 0), opts); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+}
+
+func TestWriteSchemaGraphDOT(t *testing.T) {
+	store := NewGtsStore(nil)
+	store.Register(NewJsonEntity(map[string]any{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id":     "gts://gts.x.test.dot.item.v1~",
+		"type":    "object",
+		"properties": map[string]any{
+			"owner": map[string]any{"$ref": "gts.x.test.dot.missing.v1~"},
+		},
+	}, DefaultGtsConfig()))
+	store.Register(NewJsonEntity(map[string]any{"id": "gts.x.test.dot.item.v1~x.test._.a.v1"}, DefaultGtsConfig()))
+
+	var buf bytes.Buffer
+	if err := WriteSchemaGraphDOT(&buf, store.BuildSchemaGraph("gts.x.test.dot.item.v1~x.test._.a.v1")); err != nil {
+		t.Fatalf("WriteSchemaGraphDOT failed: %v", err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		`digraph gts {`,
+		`"gts.x.test.dot.item.v1~x.test._.a.v1" [label="gts.x.test.dot.item.v1~x.test._.a.v1", fillcolor=lightyellow, style="filled"];`,
+		`"gts.x.test.dot.item.v1~" [label="gts.x.test.dot.item.v1~", fillcolor=lightblue, style="filled"];`,
+		`"gts.x.test.dot.missing.v1~" [label="gts.x.test.dot.missing.v1~", fillcolor=lightblue, style="filled,dashed"];`,
+		`"gts.x.test.dot.item.v1~x.test._.a.v1" -> "gts.x.test.dot.item.v1~" [label="id", style=dashed];`,
+		`"gts.x.test.dot.item.v1~" -> "gts.x.test.dot.missing.v1~" [label="properties.owner.$ref"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT output to contain %s, got:\n%s", want, dot)
+		}
+	}
+}
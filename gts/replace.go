@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"log"
+)
+
+// ReplaceIDMismatchError is returned by Replace when the ID of the new content differs from the
+// ID of the entity it replaces
+type ReplaceIDMismatchError struct {
+	EntityID  string
+	ContentID string
+}
+
+func (e *ReplaceIDMismatchError) Error() string {
+	return fmt.Sprintf("Entity ID '%s' in the content does not match '%s'", e.ContentID, e.EntityID)
+}
+
+// EntityChangedError is returned by Replace when the entity was written or removed by another
+// operation while the replacement was checked
+type EntityChangedError struct {
+	EntityID string
+}
+
+func (e *EntityChangedError) Error() string {
+	return fmt.Sprintf("Entity %s was modified concurrently", e.EntityID)
+}
+
+// Replace replaces the registered entity entityID with entity after the checks of Register. The ID
+// of entity must be entityID, otherwise ReplaceIDMismatchError is returned; entityID is matched
+// exactly, without the lenient lookup of Get, and an unknown ID returns StoreGtsObjectNotFoundError.
+// When both the registered entity and entity are schemas, the compatibility of the new content with
+// the old one is returned, as by CheckReplacementCompatibility; it is reported, not enforced.
+//
+// The compatibility is checked against the entity that is replaced: if the entity is written or
+// removed by another operation meanwhile, Replace returns EntityChangedError and leaves the store
+// unchanged.
+func (s *GtsStore) Replace(entityID string, entity *JsonEntity) (*CompatibilityResult, error) {
+	if entity.GtsID == nil || entity.GtsID.ID == "" {
+		return nil, fmt.Errorf("entity must have a valid gts_id")
+	}
+	if entity.GtsID.ID != entityID {
+		return nil, &ReplaceIDMismatchError{EntityID: entityID, ContentID: entity.GtsID.ID}
+	}
+	existing := s.getExact(entityID)
+	if existing == nil {
+		return nil, &StoreGtsObjectNotFoundError{EntityID: entityID}
+	}
+
+	var compatibility *CompatibilityResult
+	if entity.IsSchema && existing.IsSchema {
+		compatibility = s.replacementCompatibility(entityID, existing, entity.Content)
+	}
+	if err := s.checkRegistration(entity, nil); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.frozen {
+		s.mu.Unlock()
+		return nil, &StoreFrozenError{Operation: "replace " + entityID}
+	}
+	if s.byID[entityID] != existing {
+		s.mu.Unlock()
+		return nil, &EntityChangedError{EntityID: entityID}
+	}
+	if err := s.persistLocked(entity); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.putLocked(entity)
+	s.mu.Unlock()
+
+	log.Printf("Replaced entity: %s (schema: %v, refs: %d)", entityID, entity.IsSchema, len(entity.GtsRefs))
+	return compatibility, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	const schemaID = "gts.x.test.replace.item.v1~"
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{LenientLookup: true})
+	schema := func(required ...any) map[string]any {
+		return map[string]any{
+			"$id":      "gts://" + schemaID,
+			"$schema":  "http://json-schema.org/draft-07/schema#",
+			"type":     "object",
+			"required": required,
+			"properties": map[string]any{
+				"name": map[string]any{"type": "string"},
+				"size": map[string]any{"type": "integer"},
+			},
+		}
+	}
+	if err := store.Register(NewJsonEntity(schema("name"), DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+
+	var mismatchErr *ReplaceIDMismatchError
+	other := NewJsonEntity(map[string]any{"$id": "gts://gts.x.test.replace.other.v1~", "$schema": "http://json-schema.org/draft-07/schema#"}, DefaultGtsConfig())
+	if _, err := store.Replace(schemaID, other); !errors.As(err, &mismatchErr) {
+		t.Errorf("Expected ReplaceIDMismatchError, got %v", err)
+	}
+
+	var notFoundErr *StoreGtsObjectNotFoundError
+	missing := NewJsonEntity(map[string]any{"$id": "gts://gts.x.test.replace.missing.v1~", "$schema": "http://json-schema.org/draft-07/schema#"}, DefaultGtsConfig())
+	if _, err := store.Replace("gts.x.test.replace.missing.v1~", missing); !errors.As(err, &notFoundErr) {
+		t.Errorf("Expected StoreGtsObjectNotFoundError, got %v", err)
+	}
+	// The lenient lookup of Get does not apply: a normalized ID is not the ID of the content
+	if _, err := store.Replace("GTS.x.test.replace.item.v1~", NewJsonEntity(schema("name"), DefaultGtsConfig())); !errors.As(err, &mismatchErr) {
+		t.Errorf("Expected a normalized ID not to match, got %v", err)
+	}
+
+	compatibility, err := store.Replace(schemaID, NewJsonEntity(schema("name", "size"), DefaultGtsConfig()))
+	if err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	if compatibility == nil || compatibility.IsBackwardCompatible || !compatibility.IsForwardCompatible {
+		t.Errorf("Expected the added required property to break backward compatibility only, got %+v", compatibility)
+	}
+	if required := store.Get(schemaID).Content["required"]; !reflect.DeepEqual(required, []any{"name", "size"}) {
+		t.Errorf("Expected the schema to be replaced, got required %v", required)
+	}
+
+	store.Freeze()
+	var frozenErr *StoreFrozenError
+	if _, err := store.Replace(schemaID, NewJsonEntity(schema("name"), DefaultGtsConfig())); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected StoreFrozenError, got %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ValidationFinding represents a single problem found while validating an entity
+type ValidationFinding struct {
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	// Position locates Path in the entity's file when the entity carries a position index
+	Position *Position `json:"position,omitempty"`
+}
+
+// ValidationReportEntry represents the validation outcome of a single entity
+type ValidationReportEntry struct {
+	ID       string              `json:"id"`
+	IsSchema bool                `json:"is_schema"`
+	File     string              `json:"file,omitempty"`
+	OK       bool                `json:"ok"`
+	Findings []ValidationFinding `json:"findings,omitempty"`
+}
+
+// ValidationReport collects validation outcomes for a set of entities
+type ValidationReport struct {
+	Entries []ValidationReportEntry `json:"entries"`
+}
+
+// Failures returns the number of entries that did not pass validation
+func (r *ValidationReport) Failures() int {
+	failures := 0
+	for _, entry := range r.Entries {
+		if !entry.OK {
+			failures++
+		}
+	}
+	return failures
+}
+
+// BuildValidationReport validates the given entities and collects the outcomes into a report
+// Schemas (IDs ending with '~') are checked with ValidateSchema, instances with ValidateInstance.
+// Entries are sorted by ID so that serialized reports are stable.
+func (s *GtsStore) BuildValidationReport(ids []string) *ValidationReport {
+	report := &ValidationReport{Entries: make([]ValidationReportEntry, 0, len(ids))}
+
+	for _, id := range ids {
+		report.Entries = append(report.Entries, s.validationReportEntry(id))
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].ID < report.Entries[j].ID
+	})
+	return report
+}
+
+// validationReportEntry validates a single entity and converts the outcome into a report entry
+func (s *GtsStore) validationReportEntry(id string) ValidationReportEntry {
+	entry := ValidationReportEntry{
+		ID:       id,
+		IsSchema: strings.HasSuffix(id, "~"),
+	}
+
+	entity := s.Get(id)
+	if entity != nil && entity.File != nil {
+		entry.File = entity.File.Path
+	}
+
+	if entry.IsSchema {
+		if err := s.ValidateSchema(id); err != nil {
+			entry.Findings = schemaFindings(entity, err, s.config.StrictSchemaKeywords)
+		}
+	} else {
+		result := s.ValidateInstance(id)
+		if !result.OK {
+			entry.Findings = instanceFindings(result)
+		}
+	}
+
+	if entity != nil && entity.PositionIndex != nil {
+		for i, finding := range entry.Findings {
+			if pos, ok := entity.PositionIndex.Lookup(finding.Path); ok {
+				entry.Findings[i].Position = &pos
+			}
+		}
+	}
+
+	entry.OK = len(entry.Findings) == 0
+	return entry
+}
+
+// instanceFindings turns an instance validation failure into one finding per violation, each at the
+// JSON Pointer of its failing value; failures without violations, e.g. an unresolved schema, are a
+// single finding
+func instanceFindings(result *ValidationResult) []ValidationFinding {
+	if len(result.Violations) == 0 {
+		return []ValidationFinding{{Message: result.Error, Path: result.Path}}
+	}
+	findings := make([]ValidationFinding, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		findings = append(findings, ValidationFinding{Message: violation.Message, Path: violation.Path})
+	}
+	return findings
+}
+
+// schemaFindings splits a schema validation failure into findings carrying the JSON path
+// of the failing node where the underlying validators report one
+func schemaFindings(entity *JsonEntity, err error, strictKeywords bool) []ValidationFinding {
+	var findings []ValidationFinding
+
+	if entity != nil && entity.Content != nil {
+		if strictKeywords {
+			for _, finding := range CheckSchemaKeywords(entity.Content) {
+				findings = append(findings, ValidationFinding{Message: finding.Message, Path: finding.Path})
+			}
+		}
+		for _, refErr := range NewRefValidator().ValidateSchemaRefs(entity.Content, "") {
+			findings = append(findings, ValidationFinding{Message: refErr.Error(), Path: refErr.FieldPath})
+		}
+		for _, refErr := range NewXGtsRefValidator(nil).ValidateSchema(entity.Content, "", nil) {
+			findings = append(findings, ValidationFinding{Message: refErr.Error(), Path: refErr.FieldPath})
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, ValidationFinding{Message: err.Error()})
+	}
+	return findings
+}
+
+// classNameFor returns the vendor.package.namespace of an ID, used to group report entries
+func classNameFor(id string) string {
+	gtsID, err := NewGtsID(id)
+	if err != nil || len(gtsID.Segments) == 0 {
+		return "gts"
+	}
+	seg := gtsID.Segments[0]
+	return seg.Vendor + "." + seg.Package + "." + seg.Namespace
+}
+
+// JUnit XML serialization
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit serializes a validation report as JUnit XML
+// Each entity becomes a test case: the failure message is the first finding,
+// system-out lists all findings, and the classname is the entity's vendor.package.namespace.
+func WriteJUnit(w io.Writer, report *ValidationReport) error {
+	if report == nil {
+		return errors.New("validation report is nil")
+	}
+
+	suite := junitTestSuite{
+		Name:      "gts.validate",
+		Tests:     len(report.Entries),
+		Failures:  report.Failures(),
+		TestCases: make([]junitTestCase, 0, len(report.Entries)),
+	}
+
+	for _, entry := range report.Entries {
+		tc := junitTestCase{
+			Name:      entry.ID,
+			ClassName: classNameFor(entry.ID),
+		}
+		if !entry.OK {
+			all := make([]string, 0, len(entry.Findings))
+			for _, f := range entry.Findings {
+				all = append(all, formatFinding(entry.File, f))
+			}
+			failureType := "instance"
+			if entry.IsSchema {
+				failureType = "schema"
+			}
+			tc.Failure = &junitFailure{
+				Message: entry.Findings[0].Message,
+				Type:    failureType,
+				Text:    strings.Join(all, "\n"),
+			}
+			tc.SystemOut = strings.Join(all, "\n")
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	doc := junitTestSuites{
+		Name:     "gts",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// formatFinding renders a finding as a single line, prefixed with its path if known and with
+// file:line:column when the finding has a position
+func formatFinding(file string, f ValidationFinding) string {
+	line := f.Message
+	if f.Path != "" {
+		line = fmt.Sprintf("%s: %s", f.Path, f.Message)
+	}
+	if f.Position != nil && file != "" {
+		line = fmt.Sprintf("%s:%s: %s", file, f.Position, line)
+	}
+	return line
+}
+
+// SARIF serialization
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	sarifRuleSchema   = "gts-schema-validation"
+	sarifRuleInstance = "gts-instance-validation"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	ByteOffset  int `json:"byteOffset"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF serializes a validation report as a SARIF 2.1.0 log
+// Each finding becomes a result located at the entity's source file, with the JSON path
+// of the failing node (when known) recorded as the logical location and its position, when
+// the entity carries a position index, as the region.
+func WriteSARIF(w io.Writer, report *ValidationReport) error {
+	if report == nil {
+		return errors.New("validation report is nil")
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "gts",
+			InformationURI: "https://github.com/GlobalTypeSystem/gts-go",
+			Rules: []sarifRule{
+				{ID: sarifRuleSchema, ShortDescription: sarifMessage{Text: "GTS schema validation"}},
+				{ID: sarifRuleInstance, ShortDescription: sarifMessage{Text: "GTS instance validation"}},
+			},
+		}},
+		Results: []sarifResult{},
+	}
+
+	for _, entry := range report.Entries {
+		ruleID := sarifRuleInstance
+		if entry.IsSchema {
+			ruleID = sarifRuleSchema
+		}
+		for _, f := range entry.Findings {
+			result := sarifResult{
+				RuleID:  ruleID,
+				Level:   "error",
+				Message: sarifMessage{Text: fmt.Sprintf("%s: %s", entry.ID, f.Message)},
+			}
+
+			loc := sarifLocation{}
+			if entry.File != "" {
+				loc.PhysicalLocation = &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(entry.File)},
+				}
+				if f.Position != nil {
+					loc.PhysicalLocation.Region = &sarifRegion{
+						StartLine:   f.Position.Line,
+						StartColumn: f.Position.Column,
+						ByteOffset:  f.Position.ByteOffset,
+					}
+				}
+			}
+			logical := entry.ID
+			if f.Path != "" {
+				logical = entry.ID + "@" + f.Path
+			}
+			loc.LogicalLocations = []sarifLogicalLocation{{
+				Name:               f.Path,
+				FullyQualifiedName: logical,
+				Kind:               "member",
+			}}
+			result.Locations = []sarifLocation{loc}
+
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// setupReportTestStore creates a store with one valid instance, one invalid schema and one instance
+// failing two ways
+func setupReportTestStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+
+	register := func(content map[string]any, path string) {
+		file := &JsonFile{Path: path, Name: filepath.Base(path), Content: content}
+		entity := NewJsonEntityWithFile(content, DefaultGtsConfig(), file, nil)
+		if err := store.Register(entity); err != nil {
+			t.Fatalf("Failed to register %s: %v", path, err)
+		}
+	}
+
+	register(map[string]any{
+		"$id":      "gts://gts.x.report.ns.user.v1~",
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"id", "name"},
+		"properties": map[string]any{
+			"id":   map[string]any{"type": "string"},
+			"name": map[string]any{"type": "string"},
+			"manager": map[string]any{
+				"type":      "string",
+				"x-gts-ref": "gts.x.report.ns.user.v1~",
+			},
+		},
+	}, "fixtures/schemas/user.v1.json")
+
+	register(map[string]any{
+		"$id":     "gts://gts.x.report.ns.broken.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"owner": map[string]any{
+				"type":      "string",
+				"x-gts-ref": "not-a-gts-pattern",
+			},
+		},
+	}, "fixtures/schemas/broken.v1.json")
+
+	register(map[string]any{
+		"id":   "gts.x.report.ns.user.v1~x.report._.alice.v1",
+		"name": "Alice",
+	}, "fixtures/instances/alice.json")
+
+	register(map[string]any{
+		"id":      "gts.x.report.ns.user.v1~x.report._.bob.v1",
+		"manager": "gts.x.report.ns.user.v1~x.report._.carol.v1",
+	}, "fixtures/instances/bob.json")
+
+	return store
+}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Output does not match %s\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+	}
+}
+
+func TestBuildValidationReport(t *testing.T) {
+	store := setupReportTestStore(t)
+	report := store.BuildValidationReport([]string{
+		"gts.x.report.ns.user.v1~x.report._.bob.v1",
+		"gts.x.report.ns.broken.v1~",
+		"gts.x.report.ns.user.v1~x.report._.alice.v1",
+	})
+
+	if len(report.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(report.Entries))
+	}
+	if report.Failures() != 2 {
+		t.Errorf("Expected 2 failures, got %d", report.Failures())
+	}
+
+	// Entries are sorted by ID
+	if report.Entries[0].ID != "gts.x.report.ns.broken.v1~" {
+		t.Errorf("Expected broken schema first, got %s", report.Entries[0].ID)
+	}
+
+	broken := report.Entries[0]
+	if broken.OK || !broken.IsSchema {
+		t.Errorf("Expected failed schema entry, got %+v", broken)
+	}
+	if len(broken.Findings) != 1 || broken.Findings[0].Path != "properties/owner/x-gts-ref" {
+		t.Errorf("Expected finding at properties/owner/x-gts-ref, got %+v", broken.Findings)
+	}
+	if broken.File != "fixtures/schemas/broken.v1.json" {
+		t.Errorf("Expected source file to be recorded, got %q", broken.File)
+	}
+
+	if !report.Entries[1].OK {
+		t.Errorf("Expected alice to pass, got %+v", report.Entries[1])
+	}
+	bob := report.Entries[2]
+	if bob.OK || len(bob.Findings) != 2 {
+		t.Fatalf("Expected bob to fail with a finding per violation, got %+v", bob)
+	}
+	paths := map[string]bool{}
+	for _, finding := range bob.Findings {
+		paths[finding.Path] = true
+	}
+	if !paths[""] || !paths["/manager"] {
+		t.Errorf("Expected findings for the missing name and the manager reference, got %+v", bob.Findings)
+	}
+}
+
+func TestWriteJUnit_Golden(t *testing.T) {
+	store := setupReportTestStore(t)
+	report := store.BuildValidationReport([]string{
+		"gts.x.report.ns.broken.v1~",
+		"gts.x.report.ns.user.v1~x.report._.alice.v1",
+		"gts.x.report.ns.user.v1~x.report._.bob.v1",
+	})
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, report); err != nil {
+		t.Fatalf("WriteJUnit failed: %v", err)
+	}
+	checkGolden(t, "report.junit.xml", buf.Bytes())
+}
+
+func TestWriteSARIF_Golden(t *testing.T) {
+	store := setupReportTestStore(t)
+	report := store.BuildValidationReport([]string{
+		"gts.x.report.ns.broken.v1~",
+		"gts.x.report.ns.user.v1~x.report._.alice.v1",
+		"gts.x.report.ns.user.v1~x.report._.bob.v1",
+	})
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, report); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+	checkGolden(t, "report.sarif.json", buf.Bytes())
+}
+
+func TestWriteReport_NilReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, nil); err == nil {
+		t.Error("Expected error for nil report in WriteJUnit")
+	}
+	if err := WriteSARIF(&buf, nil); err == nil {
+		t.Error("Expected error for nil report in WriteSARIF")
+	}
+}
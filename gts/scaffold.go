@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DerivedTypeSpec describes the segment a scaffolded type appends to its base type
+type DerivedTypeSpec struct {
+	Vendor    string
+	Package   string
+	Namespace string
+	Name      string
+	// Version is the major or major.minor version, with or without the leading 'v' (e.g. 1.0)
+	Version string
+	// Title is the title of the schema; empty leaves it out
+	Title string
+	// PayloadExample, when not nil, is an example payload the payload properties are inferred
+	// from; otherwise the payload is an object without properties
+	PayloadExample map[string]any
+}
+
+// segment returns the type segment of the spec, e.g. acme.commerce.orders.order_cancelled.v1.0~
+func (spec DerivedTypeSpec) segment() string {
+	version := strings.TrimPrefix(strings.TrimSpace(spec.Version), "v")
+	return fmt.Sprintf("%s.%s.%s.%s.v%s~", spec.Vendor, spec.Package, spec.Namespace, spec.Name, version)
+}
+
+// ScaffoldDerivedType generates the schema of a new type derived from the registered base schema:
+// its $id chains the segment of spec to the base ID, it extends the base through an allOf $ref,
+// its type property is a const of its own ID and its payload property is an object whose
+// properties are inferred from spec.PayloadExample, when given. The $schema draft is copied from
+// the base (draft-07 when the base has none). The schema is returned as an entity ready to be
+// registered; it is not registered.
+func ScaffoldDerivedType(store *GtsStore, base string, spec DerivedTypeSpec) (*JsonEntity, error) {
+	baseID, err := NewGtsID(base)
+	if err != nil {
+		return nil, err
+	}
+	if !baseID.IsType() {
+		return nil, fmt.Errorf("base ID must end with '~': %s", base)
+	}
+	baseEntity := store.Get(baseID.ID)
+	if baseEntity == nil || !baseEntity.IsSchema {
+		return nil, &StoreGtsSchemaNotFoundError{EntityID: baseID.ID}
+	}
+
+	gtsID, err := NewGtsID(baseID.ID + spec.segment())
+	if err != nil {
+		return nil, fmt.Errorf("invalid derived type: %w", err)
+	}
+	id := gtsID.ID
+
+	payload := map[string]any{"type": "object", "properties": map[string]any{}}
+	if spec.PayloadExample != nil {
+		payload = inferSchema(spec.PayloadExample)
+	}
+
+	draft, _ := baseEntity.Content["$schema"].(string)
+	if draft == "" {
+		draft = "http://json-schema.org/draft-07/schema#"
+	}
+	content := map[string]any{
+		"$id":     GtsURIPrefix + id,
+		"$schema": draft,
+		"type":    "object",
+		"allOf": []any{
+			map[string]any{"$ref": GtsURIPrefix + baseID.ID},
+			map[string]any{
+				"type":     "object",
+				"required": []any{"type", "payload"},
+				"properties": map[string]any{
+					"type":    map[string]any{"const": id},
+					"payload": payload,
+				},
+			},
+		},
+	}
+	if spec.Title != "" {
+		content["title"] = spec.Title
+	}
+	return NewJsonEntity(content, DefaultGtsConfig()), nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestScaffoldDerivedType_IDs(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerOrderPlacedSchemas(t, store)
+	spec := DerivedTypeSpec{Vendor: "acme", Package: "commerce", Namespace: "orders", Name: "order_cancelled", Version: "1.0"}
+
+	tests := []struct {
+		name, base, version, expected string
+	}{
+		{"single segment base", "gts.x.core.events.type.v1~", "1.0",
+			"gts.x.core.events.type.v1~acme.commerce.orders.order_cancelled.v1.0~"},
+		{"multi-segment base", "gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~", "v2",
+			"gts.x.core.events.type.v1~x.commerce.orders.order_placed.v1.0~acme.commerce.orders.order_cancelled.v2~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec.Version = tt.version
+			entity, err := ScaffoldDerivedType(store, tt.base, spec)
+			if err != nil {
+				t.Fatalf("ScaffoldDerivedType failed: %v", err)
+			}
+			if entity.GtsID == nil || entity.GtsID.ID != tt.expected || !entity.IsSchema || entity.SchemaID != "gts.x.core.events.type.v1~" {
+				t.Fatalf("Expected schema %s, got %+v", tt.expected, entity)
+			}
+			part := entity.Content["allOf"].([]any)
+			if ref := part[0].(map[string]any)["$ref"]; ref != GtsURIPrefix+tt.base {
+				t.Errorf("Expected allOf to reference the base, got %v", ref)
+			}
+			typeProp := part[1].(map[string]any)["properties"].(map[string]any)["type"]
+			if !reflect.DeepEqual(typeProp, map[string]any{"const": tt.expected}) {
+				t.Errorf("Expected the type const to match the ID, got %v", typeProp)
+			}
+			if entity.Content["$schema"] != "http://json-schema.org/draft-07/schema#" {
+				t.Errorf("Expected the draft of the base, got %v", entity.Content["$schema"])
+			}
+
+			if err := store.Register(entity); err != nil {
+				t.Fatalf("Failed to register scaffolded schema: %v", err)
+			}
+			if err := store.ValidateSchema(tt.expected); err != nil {
+				t.Errorf("Scaffolded schema does not validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestScaffoldDerivedType_Errors(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerOrderPlacedSchemas(t, store)
+	spec := DerivedTypeSpec{Vendor: "acme", Package: "commerce", Namespace: "orders", Name: "order_cancelled", Version: "1"}
+
+	var notFound *StoreGtsSchemaNotFoundError
+	if _, err := ScaffoldDerivedType(store, "gts.x.core.events.missing.v1~", spec); !errors.As(err, &notFound) {
+		t.Errorf("Expected StoreGtsSchemaNotFoundError for a missing base, got %v", err)
+	}
+	if _, err := ScaffoldDerivedType(store, "gts.x.core.events.type.v1~x.core._.inst.v1", spec); err == nil {
+		t.Error("Expected an error for an instance base ID")
+	}
+	spec.Name = "Order-Cancelled"
+	if _, err := ScaffoldDerivedType(store, "gts.x.core.events.type.v1~", spec); err == nil {
+		t.Error("Expected an error for an invalid type name")
+	}
+}
+
+func TestScaffoldDerivedType_PayloadFromExample(t *testing.T) {
+	store := NewGtsStore(nil)
+	base := map[string]any{
+		"$id":      "gts://gts.x.shop.events.event.v1~",
+		"$schema":  "https://json-schema.org/draft/2020-12/schema",
+		"type":     "object",
+		"required": []any{"gtsId", "type", "payload"},
+		"properties": map[string]any{
+			"gtsId":   map[string]any{"type": "string"},
+			"type":    map[string]any{"type": "string"},
+			"payload": map[string]any{"type": "object"},
+		},
+	}
+	if err := store.Register(NewJsonEntity(base, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register base: %v", err)
+	}
+
+	example := map[string]any{
+		"orderId":     "o-1001",
+		"total":       42.5,
+		"quantity":    float64(3),
+		"gift":        false,
+		"cancelledAt": "2025-09-20T18:35:00Z",
+		"lines":       []any{map[string]any{"sku": "SKU-1"}},
+	}
+	entity, err := ScaffoldDerivedType(store, "gts.x.shop.events.event.v1~", DerivedTypeSpec{
+		Vendor: "acme", Package: "commerce", Namespace: "orders", Name: "order_cancelled", Version: "1.0",
+		Title: "Order cancelled", PayloadExample: example,
+	})
+	if err != nil {
+		t.Fatalf("ScaffoldDerivedType failed: %v", err)
+	}
+	payload := entity.Content["allOf"].([]any)[1].(map[string]any)["properties"].(map[string]any)["payload"].(map[string]any)
+	expected := map[string]any{
+		"cancelledAt": map[string]any{"type": "string", "format": "date-time"},
+		"gift":        map[string]any{"type": "boolean"},
+		"lines": map[string]any{"type": "array", "items": map[string]any{
+			"type": "object", "properties": map[string]any{"sku": map[string]any{"type": "string"}}, "required": []any{"sku"},
+		}},
+		"orderId":  map[string]any{"type": "string"},
+		"quantity": map[string]any{"type": "integer"},
+		"total":    map[string]any{"type": "number"},
+	}
+	if !reflect.DeepEqual(payload["properties"], expected) || len(payload["required"].([]any)) != len(example) {
+		t.Errorf("Unexpected inferred payload: %v", payload)
+	}
+	if entity.Content["$schema"] != base["$schema"] || entity.Content["title"] != "Order cancelled" {
+		t.Errorf("Expected the base draft and the title, got %v", entity.Content)
+	}
+
+	if err := store.Register(entity); err != nil {
+		t.Fatalf("Failed to register scaffolded schema: %v", err)
+	}
+	if err := store.ValidateSchema(entity.GtsID.ID); err != nil {
+		t.Fatalf("Scaffolded schema does not validate: %v", err)
+	}
+
+	// An instance carrying the example payload validates against the scaffolded schema
+	instanceID := entity.GtsID.ID + "acme.commerce._.c1.v1"
+	instance := map[string]any{"gtsId": instanceID, "type": entity.GtsID.ID, "payload": example}
+	if err := store.Register(NewJsonEntity(instance, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+	if result := store.ValidateInstance(instanceID); !result.OK {
+		t.Errorf("Example instance does not validate: %s", result.Error)
+	}
+}
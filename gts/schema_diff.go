@@ -0,0 +1,407 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSON Patch operations produced by DiffSchemas
+const (
+	JSONPatchAdd     = "add"
+	JSONPatchRemove  = "remove"
+	JSONPatchReplace = "replace"
+)
+
+// JSONPatchOperation is an RFC 6902 JSON Patch operation; Path is a JSON Pointer (RFC 6901)
+type JSONPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON encodes the value of add and replace operations even when it is null
+func (op JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	if op.Op == JSONPatchRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{op.Op, op.Path, op.Value})
+}
+
+// SchemaDiff is the structural difference between two registered schemas (see DiffSchemas)
+type SchemaDiff struct {
+	OldID string `json:"old"`
+	NewID string `json:"new"`
+	// Patch turns the resolved old schema into the resolved new one
+	Patch []JSONPatchOperation `json:"patch"`
+	// Summary describes each operation of Patch, in the same order
+	Summary []string `json:"summary"`
+	// Warnings lists the GTS $refs that name no registered schema and were left as they are
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DiffSchemas returns the JSON Patch between two registered schemas, with a summary line per
+// operation. Both schemas are compared resolved: normalized as for CheckCompatibility, their allOf
+// layers merged and the GTS schemas their $refs name inlined, at every depth, so that inherited
+// and referenced properties take part; $id, $schema and definitions are left out and required
+// lists are sorted. Operations follow the schemas in key order, nested properties and array item
+// schemas included, so the same schemas always give the same patch. Keywords holding values, such
+// as enum or default, are replaced as a whole, and array elements are removed from the last one
+// so that the patch applies to the resolved old schema in order.
+// A schema ID without minor version names its latest registered minor version, as in
+// ResolveSchema. A panic during the diff is returned as an error wrapping ErrInternal.
+func (s *GtsStore) DiffSchemas(oldID, newID string) (diff *SchemaDiff, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			diff, err = nil, newStoreInternalError("DiffSchemas", r)
+		}
+	}()
+
+	oldEntity, err := s.ResolveSchema(oldID)
+	if err != nil {
+		return nil, err
+	}
+	newEntity, err := s.ResolveSchema(newID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &diffSchemaResolver{resolve: s.storeSchemaResolver()}
+	oldSchema := resolver.resolveDocument(oldEntity.GtsID.ID, oldEntity.Content)
+	newSchema := resolver.resolveDocument(newEntity.GtsID.ID, newEntity.Content)
+
+	differ := &schemaDiffer{patch: []JSONPatchOperation{}, summary: []string{}}
+	differ.diffSchema("", "", oldSchema, newSchema)
+	return &SchemaDiff{
+		OldID:    oldEntity.GtsID.ID,
+		NewID:    newEntity.GtsID.ID,
+		Patch:    differ.patch,
+		Summary:  differ.summary,
+		Warnings: mergeWarnings(resolver.warnings),
+	}, nil
+}
+
+// diffSchemaResolver resolves the schemas compared by DiffSchemas
+type diffSchemaResolver struct {
+	resolve  func(ref string) map[string]any
+	visited  map[string]bool
+	warnings []string
+}
+
+// resolveDocument resolves the content of the registered schema id
+func (r *diffSchemaResolver) resolveDocument(id string, content map[string]any) map[string]any {
+	normalized, _ := normalizeSchema(content)
+	if normalized == nil {
+		return map[string]any{}
+	}
+	r.visited = map[string]bool{id: true}
+	return r.resolveNode(normalized, "")
+}
+
+// resolveNode merges the layers of a schema node at path: the schema its GTS $ref names, its allOf
+// parts in order, then its own keywords, whose subschemas are resolved too. Properties and other
+// named subschemas merge by name and required names are combined; other keywords of a later layer
+// replace those of an earlier one. A $ref closing a cycle or naming no registered schema is kept.
+func (r *diffSchemaResolver) resolveNode(node map[string]any, path string) map[string]any {
+	result := map[string]any{}
+	own := map[string]any{}
+
+	if ref, ok := node["$ref"].(string); ok && IsValidGtsID(strings.TrimPrefix(ref, GtsURIPrefix)) {
+		id := strings.TrimPrefix(ref, GtsURIPrefix)
+		content := r.resolve(ref)
+		switch {
+		case r.visited[id]:
+			own["$ref"] = ref
+		case content == nil:
+			r.warnings = append(r.warnings, fmt.Sprintf("referenced schema '%s' at %s is not registered", id, buildPath(path, "$ref")))
+			own["$ref"] = ref
+		default:
+			normalized, _ := normalizeSchema(content)
+			r.visited[id] = true
+			mergeDiffLayer(result, r.resolveNode(normalized, path))
+			delete(r.visited, id)
+		}
+	}
+	if parts, ok := node["allOf"].([]any); ok {
+		for i, part := range parts {
+			if sub, ok := part.(map[string]any); ok {
+				mergeDiffLayer(result, r.resolveNode(sub, buildPath(path, fmt.Sprintf("allOf[%d]", i))))
+			}
+		}
+	}
+
+	for k, v := range node {
+		switch {
+		case k == "$ref" || k == "allOf" || k == "$id" || k == "$schema" || k == "$defs" || k == "definitions":
+			// Resolved above, or identifying the document rather than its structure
+		case subschemaKeywords[k]:
+			if sub, ok := v.(map[string]any); ok {
+				own[k] = r.resolveNode(sub, buildPath(path, k))
+			} else {
+				own[k] = copyValue(v)
+			}
+		case subschemaListKeywords[k]:
+			list, ok := v.([]any)
+			if !ok {
+				own[k] = copyValue(v)
+				continue
+			}
+			resolved := make([]any, len(list))
+			for i, item := range list {
+				if sub, ok := item.(map[string]any); ok {
+					resolved[i] = r.resolveNode(sub, buildPath(path, fmt.Sprintf("%s[%d]", k, i)))
+				} else {
+					resolved[i] = copyValue(item)
+				}
+			}
+			own[k] = resolved
+		case namedSubschemaKeywords[k]:
+			named, ok := v.(map[string]any)
+			if !ok {
+				own[k] = copyValue(v)
+				continue
+			}
+			resolved := make(map[string]any, len(named))
+			for name, item := range named {
+				if sub, ok := item.(map[string]any); ok {
+					resolved[name] = r.resolveNode(sub, buildPath(path, name))
+				} else {
+					resolved[name] = copyValue(item)
+				}
+			}
+			own[k] = resolved
+		default:
+			own[k] = copyValue(v)
+		}
+	}
+	mergeDiffLayer(result, own)
+
+	if required, ok := result["required"].([]any); ok {
+		sort.SliceStable(required, func(i, j int) bool {
+			return fmt.Sprint(required[i]) < fmt.Sprint(required[j])
+		})
+	}
+	return result
+}
+
+// mergeDiffLayer merges a resolved layer into result
+func mergeDiffLayer(result, layer map[string]any) {
+	for k, v := range layer {
+		switch existing := result[k].(type) {
+		case map[string]any:
+			named, ok := v.(map[string]any)
+			if !namedSubschemaKeywords[k] || !ok {
+				break
+			}
+			for name, sub := range named {
+				existing[name] = sub
+			}
+			continue
+		case []any:
+			names, ok := v.([]any)
+			if k != "required" || !ok {
+				break
+			}
+			for _, name := range names {
+				if !containsValue(existing, name) {
+					existing = append(existing, name)
+				}
+			}
+			result[k] = existing
+			continue
+		}
+		result[k] = v
+	}
+}
+
+// schemaDiffer collects the operations and summary of a schema diff
+type schemaDiffer struct {
+	patch   []JSONPatchOperation
+	summary []string
+}
+
+// add records an operation with its summary line
+func (d *schemaDiffer) add(op, pointer string, value any, format string, args ...any) {
+	d.patch = append(d.patch, JSONPatchOperation{Op: op, Path: pointer, Value: value})
+	d.summary = append(d.summary, fmt.Sprintf(format, args...))
+}
+
+// diffSchema diffs two resolved schema nodes at pointer; location names the node in the summary,
+// e.g. "address.street" or "lines[].sku"
+func (d *schemaDiffer) diffSchema(pointer, location string, oldSchema, newSchema map[string]any) {
+	for _, k := range unionKeys(oldSchema, newSchema) {
+		oldVal, hadOld := oldSchema[k]
+		newVal, hasNew := newSchema[k]
+		keyPointer := pointer + "/" + escapeJSONPointer(k)
+		oldMap, oldIsMap := oldVal.(map[string]any)
+		newMap, newIsMap := newVal.(map[string]any)
+		oldList, oldIsList := oldVal.([]any)
+		newList, newIsList := newVal.([]any)
+
+		switch {
+		case hadOld && hasNew && reflect.DeepEqual(oldVal, newVal):
+		case k == "required" && oldIsList && newIsList:
+			d.diffRequired(keyPointer, location, oldList, newList)
+		case namedSubschemaKeywords[k] && oldIsMap && newIsMap:
+			d.diffNamed(keyPointer, location, k, oldMap, newMap)
+		case subschemaKeywords[k] && oldIsMap && newIsMap:
+			d.diffSchema(keyPointer, subschemaLocation(location, k), oldMap, newMap)
+		case subschemaListKeywords[k] && oldIsList && newIsList:
+			d.diffSchemaList(keyPointer, location, k, oldList, newList)
+		default:
+			d.diffKeyword(keyPointer, location, k, oldVal, hadOld, newVal, hasNew)
+		}
+	}
+}
+
+// diffRequired diffs two sorted required lists: names dropped are removed from the last one, then
+// names added are inserted at their sorted position
+func (d *schemaDiffer) diffRequired(pointer, location string, oldList, newList []any) {
+	for i := len(oldList) - 1; i >= 0; i-- {
+		if !containsValue(newList, oldList[i]) {
+			d.add(JSONPatchRemove, fmt.Sprintf("%s/%d", pointer, i), nil,
+				"Property '%s' is no longer required", buildPath(location, fmt.Sprint(oldList[i])))
+		}
+	}
+	for i, name := range newList {
+		if !containsValue(oldList, name) {
+			d.add(JSONPatchAdd, fmt.Sprintf("%s/%d", pointer, i), name,
+				"Property '%s' is now required", buildPath(location, fmt.Sprint(name)))
+		}
+	}
+}
+
+// diffNamed diffs the subschemas of properties and the other keywords mapping names to subschemas
+func (d *schemaDiffer) diffNamed(pointer, location, keyword string, oldNamed, newNamed map[string]any) {
+	for _, name := range unionKeys(oldNamed, newNamed) {
+		oldSub, hadOld := oldNamed[name]
+		newSub, hasNew := newNamed[name]
+		namePointer := pointer + "/" + escapeJSONPointer(name)
+		nameLocation := buildPath(location, name)
+		if keyword != "properties" {
+			nameLocation = buildPath(buildPath(location, keyword), name)
+		}
+		what := "property"
+		if keyword != "properties" {
+			what = keyword + " entry"
+		}
+
+		switch {
+		case !hadOld:
+			d.add(JSONPatchAdd, namePointer, newSub, "Added %s '%s'", what, nameLocation)
+		case !hasNew:
+			d.add(JSONPatchRemove, namePointer, nil, "Removed %s '%s'", what, nameLocation)
+		case reflect.DeepEqual(oldSub, newSub):
+		default:
+			oldMap, oldIsMap := oldSub.(map[string]any)
+			newMap, newIsMap := newSub.(map[string]any)
+			if oldIsMap && newIsMap {
+				d.diffSchema(namePointer, nameLocation, oldMap, newMap)
+			} else {
+				d.add(JSONPatchReplace, namePointer, newSub, "Changed the schema of %s '%s'", what, nameLocation)
+			}
+		}
+	}
+}
+
+// diffSchemaList diffs the subschemas of prefixItems, anyOf and the other keywords holding an
+// array of subschemas position by position
+func (d *schemaDiffer) diffSchemaList(pointer, location, keyword string, oldList, newList []any) {
+	for i := 0; i < len(oldList) && i < len(newList); i++ {
+		itemPointer := fmt.Sprintf("%s/%d", pointer, i)
+		oldMap, oldIsMap := oldList[i].(map[string]any)
+		newMap, newIsMap := newList[i].(map[string]any)
+		switch {
+		case reflect.DeepEqual(oldList[i], newList[i]):
+		case oldIsMap && newIsMap:
+			d.diffSchema(itemPointer, schemaListLocation(location, keyword, i), oldMap, newMap)
+		default:
+			d.add(JSONPatchReplace, itemPointer, newList[i], "Changed %s[%d] of %s", keyword, i, schemaPathLabel(location))
+		}
+	}
+	for i := len(oldList) - 1; i >= len(newList); i-- {
+		d.add(JSONPatchRemove, fmt.Sprintf("%s/%d", pointer, i), nil, "Removed %s[%d] from %s", keyword, i, schemaPathLabel(location))
+	}
+	for i := len(oldList); i < len(newList); i++ {
+		d.add(JSONPatchAdd, fmt.Sprintf("%s/%d", pointer, i), newList[i], "Added %s[%d] to %s", keyword, i, schemaPathLabel(location))
+	}
+}
+
+// diffKeyword diffs a keyword as a whole: a constraint such as maxLength, a value such as enum, or
+// a subschema that changed its kind, e.g. additionalProperties from false to a schema
+func (d *schemaDiffer) diffKeyword(pointer, location, keyword string, oldVal any, hadOld bool, newVal any, hasNew bool) {
+	where := schemaPathLabel(location)
+	switch {
+	case !hadOld && isSchemaKeyword(keyword):
+		d.add(JSONPatchAdd, pointer, newVal, "Added %s to %s", keyword, where)
+	case !hadOld:
+		d.add(JSONPatchAdd, pointer, newVal, "Added %s %s to %s", keyword, keywordValueString(keyword, newVal, true), where)
+	case !hasNew && isSchemaKeyword(keyword):
+		d.add(JSONPatchRemove, pointer, nil, "Removed %s from %s", keyword, where)
+	case !hasNew:
+		d.add(JSONPatchRemove, pointer, nil, "Removed %s %s from %s", keyword, keywordValueString(keyword, oldVal, true), where)
+	default:
+		d.add(JSONPatchReplace, pointer, newVal, "Changed %s of %s from %s to %s", keyword, where,
+			keywordValueString(keyword, oldVal, true), keywordValueString(keyword, newVal, true))
+	}
+}
+
+// isSchemaKeyword reports whether a keyword holds subschemas, whose values are not summarized
+func isSchemaKeyword(keyword string) bool {
+	return subschemaKeywords[keyword] || subschemaListKeywords[keyword] || namedSubschemaKeywords[keyword]
+}
+
+// subschemaLocation names the subschema of keyword below location: "[]" for items, the keyword
+// otherwise
+func subschemaLocation(location, keyword string) string {
+	if keyword == "items" {
+		return location + "[]"
+	}
+	return buildPath(location, keyword)
+}
+
+// schemaListLocation names the subschema at index i of keyword below location: the tuple position
+// for prefixItems, the keyword and index otherwise
+func schemaListLocation(location, keyword string, i int) string {
+	if keyword == "prefixItems" {
+		return buildIndexPath(location, i)
+	}
+	return buildPath(location, fmt.Sprintf("%s[%d]", keyword, i))
+}
+
+// schemaPathLabel names a schema location in a summary line
+func schemaPathLabel(location string) string {
+	if location == "" {
+		return "the schema"
+	}
+	return "'" + location + "'"
+}
+
+// unionKeys returns the keys of two maps, sorted
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newDiffStore registers a base entity schema and two minor versions of an order deriving from
+// it; v1.1 adds a required currency, drops a property and changes constraints in nested
+// properties and in the array item schema, a local definition
+func newDiffStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	schemas := map[string]string{
+		"gts.x.shop.base.entity.v1~": `{
+			"$id": "gts://gts.x.shop.base.entity.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"required": ["id"],
+			"properties": {"id": {"type": "string"}}
+		}`,
+		"gts.x.shop.orders.order.v1.0~": `{
+			"$id": "gts://gts.x.shop.orders.order.v1.0~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"allOf": [
+				{"$ref": "gts://gts.x.shop.base.entity.v1~"},
+				{
+					"type": "object",
+					"required": ["total"],
+					"properties": {
+						"total": {"type": "number", "minimum": 0},
+						"status": {"type": "string", "enum": ["open", "paid"]},
+						"address": {"type": "object", "properties": {"street": {"type": "string", "maxLength": 50}}},
+						"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}},
+						"legacy": {"type": "string"}
+					}
+				}
+			],
+			"definitions": {"line": {"type": "object", "properties": {"sku": {"type": "string"}}}}
+		}`,
+		"gts.x.shop.orders.order.v1.1~": `{
+			"$id": "gts://gts.x.shop.orders.order.v1.1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"allOf": [
+				{"$ref": "gts://gts.x.shop.base.entity.v1~"},
+				{
+					"type": "object",
+					"required": ["total", "currency"],
+					"properties": {
+						"total": {"type": "number", "minimum": 1},
+						"currency": {"type": "string"},
+						"status": {"type": "string", "enum": ["open", "paid", "void"]},
+						"address": {"type": "object", "properties": {"street": {"type": "string", "maxLength": 100}}},
+						"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}}
+					}
+				}
+			],
+			"definitions": {"line": {"type": "object", "required": ["sku"], "properties": {"sku": {"type": "string", "pattern": "^[A-Z]+$"}, "qty": {"type": "integer"}}}}
+		}`,
+	}
+	for _, id := range []string{"gts.x.shop.base.entity.v1~", "gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1.1~"} {
+		var content map[string]any
+		if err := json.Unmarshal([]byte(schemas[id]), &content); err != nil {
+			t.Fatalf("Failed to parse %s: %v", id, err)
+		}
+		if err := store.RegisterSchema(id, content); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+	return store
+}
+
+func TestDiffSchemas(t *testing.T) {
+	store := newDiffStore(t)
+
+	diff, err := store.DiffSchemas("gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1.1~")
+	if err != nil {
+		t.Fatalf("DiffSchemas failed: %v", err)
+	}
+	expected := []JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/properties/address/properties/street/maxLength", Value: float64(100)},
+		{Op: JSONPatchAdd, Path: "/properties/currency", Value: map[string]any{"type": "string"}},
+		{Op: JSONPatchRemove, Path: "/properties/legacy"},
+		{Op: JSONPatchAdd, Path: "/properties/lines/items/properties/qty", Value: map[string]any{"type": "integer"}},
+		{Op: JSONPatchAdd, Path: "/properties/lines/items/properties/sku/pattern", Value: "^[A-Z]+$"},
+		{Op: JSONPatchAdd, Path: "/properties/lines/items/required", Value: []any{"sku"}},
+		{Op: JSONPatchReplace, Path: "/properties/status/enum", Value: []any{"open", "paid", "void"}},
+		{Op: JSONPatchReplace, Path: "/properties/total/minimum", Value: float64(1)},
+		{Op: JSONPatchAdd, Path: "/required/0", Value: "currency"},
+	}
+	if !reflect.DeepEqual(diff.Patch, expected) {
+		t.Errorf("Unexpected patch:\n%+v", diff.Patch)
+	}
+	if len(diff.Summary) != len(diff.Patch) {
+		t.Fatalf("Expected a summary line per operation, got %v", diff.Summary)
+	}
+	for i, line := range []string{
+		"Changed maxLength of 'address.street' from 50 to 100",
+		"Added property 'currency'",
+		"Removed property 'legacy'",
+		"Added property 'lines[].qty'",
+		"Added pattern ^[A-Z]+$ to 'lines[].sku'",
+		`Added required ["sku"] to 'lines[]'`,
+		`Changed enum of 'status' from ["open","paid"] to ["open","paid","void"]`,
+		"Changed minimum of 'total' from 0 to 1",
+		"Property 'currency' is now required",
+	} {
+		if diff.Summary[i] != line {
+			t.Errorf("Summary line %d: expected %q, got %q", i, line, diff.Summary[i])
+		}
+	}
+
+	// The patch turns the resolved old schema into the resolved new one
+	resolver := &diffSchemaResolver{resolve: store.storeSchemaResolver()}
+	oldSchema := resolver.resolveDocument("gts.x.shop.orders.order.v1.0~", store.Get("gts.x.shop.orders.order.v1.0~").Content)
+	newSchema := resolver.resolveDocument("gts.x.shop.orders.order.v1.1~", store.Get("gts.x.shop.orders.order.v1.1~").Content)
+	if props := getPropertiesMap(oldSchema); props["id"] == nil {
+		t.Errorf("Expected the inherited id property in the resolved schema, got %v", props)
+	}
+	if patched := applyTestPatch(t, oldSchema, diff.Patch); !reflect.DeepEqual(patched, newSchema) {
+		t.Errorf("Expected the patch to produce the new schema, got %v", patched)
+	}
+
+	// The same schemas always give the same patch
+	again, _ := store.DiffSchemas("gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1.1~")
+	first, _ := json.Marshal(diff)
+	second, _ := json.Marshal(again)
+	if string(first) != string(second) {
+		t.Errorf("Expected a deterministic diff, got\n%s\n%s", first, second)
+	}
+
+	// The reverse diff removes the required name again
+	reverse, err := store.DiffSchemas("gts.x.shop.orders.order.v1.1~", "gts.x.shop.orders.order.v1.0~")
+	if err != nil {
+		t.Fatalf("DiffSchemas failed: %v", err)
+	}
+	last := reverse.Patch[len(reverse.Patch)-1]
+	if last.Op != JSONPatchRemove || last.Path != "/required/0" || reverse.Summary[len(reverse.Summary)-1] != "Property 'currency' is no longer required" {
+		t.Errorf("Expected currency to be no longer required, got %+v %v", last, reverse.Summary)
+	}
+}
+
+func TestDiffSchemas_Errors(t *testing.T) {
+	store := newDiffStore(t)
+
+	var notFound *StoreGtsSchemaNotFoundError
+	if _, err := store.DiffSchemas("gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1.2~"); !errors.As(err, &notFound) {
+		t.Errorf("Expected a schema not found error, got %v", err)
+	}
+
+	// A schema ID without minor version names the latest minor version
+	diff, err := store.DiffSchemas("gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1~")
+	if err != nil || diff.NewID != "gts.x.shop.orders.order.v1.1~" {
+		t.Errorf("Expected the diff with v1.1, got %+v %v", diff, err)
+	}
+
+	// Identical schemas give an empty patch, encoded as a list
+	same, err := store.DiffSchemas("gts.x.shop.orders.order.v1.0~", "gts.x.shop.orders.order.v1.0~")
+	if err != nil || len(same.Patch) != 0 {
+		t.Fatalf("Expected an empty patch, got %+v %v", same, err)
+	}
+	if encoded, _ := json.Marshal(same); !strings.Contains(string(encoded), `"patch":[]`) {
+		t.Errorf("Expected an empty patch list, got %s", encoded)
+	}
+}
+
+func TestJSONPatchOperation_MarshalJSON(t *testing.T) {
+	tests := map[string]JSONPatchOperation{
+		`{"op":"add","path":"/default","value":null}`: {Op: JSONPatchAdd, Path: "/default"},
+		`{"op":"replace","path":"/x","value":false}`:  {Op: JSONPatchReplace, Path: "/x", Value: false},
+		`{"op":"remove","path":"/properties/a~1b"}`:   {Op: JSONPatchRemove, Path: "/properties/a~1b"},
+	}
+	for expected, op := range tests {
+		if encoded, _ := json.Marshal(op); string(encoded) != expected {
+			t.Errorf("Expected %s, got %s", expected, encoded)
+		}
+	}
+}
+
+// applyTestPatch applies the add, remove and replace operations of a patch to a copy of doc
+func applyTestPatch(t *testing.T, doc map[string]any, patch []JSONPatchOperation) map[string]any {
+	t.Helper()
+	root := copyMap(doc)
+	for _, op := range patch {
+		tokens := strings.Split(op.Path[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		}
+		var parent any = root
+		for _, token := range tokens[:len(tokens)-1] {
+			switch container := parent.(type) {
+			case map[string]any:
+				parent = container[token]
+			case []any:
+				index, _ := strconv.Atoi(token)
+				parent = container[index]
+			}
+		}
+		last := tokens[len(tokens)-1]
+		switch container := parent.(type) {
+		case map[string]any:
+			if op.Op == JSONPatchRemove {
+				delete(container, last)
+			} else {
+				container[last] = copyValue(op.Value)
+			}
+		case []any:
+			// Array operations only occur in required lists, which are replaced in their parent
+			index, _ := strconv.Atoi(last)
+			list := append([]any{}, container[:index]...)
+			switch op.Op {
+			case JSONPatchAdd:
+				list = append(append(list, op.Value), container[index:]...)
+			case JSONPatchRemove:
+				list = append(list, container[index+1:]...)
+			default:
+				list = append(append(list, op.Value), container[index+1:]...)
+			}
+			setTestPatchParent(root, tokens[:len(tokens)-1], list)
+		default:
+			t.Fatalf("Cannot apply %+v", op)
+		}
+	}
+	return root
+}
+
+// setTestPatchParent sets the value at tokens, all object members, to value
+func setTestPatchParent(root map[string]any, tokens []string, value any) {
+	current := root
+	for _, token := range tokens[:len(tokens)-1] {
+		current = current[token].(map[string]any)
+	}
+	current[tokens[len(tokens)-1]] = value
+}
@@ -0,0 +1,298 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SchemaDraft identifies the JSON Schema draft a schema is written against
+type SchemaDraft string
+
+const (
+	// SchemaDraftUnknown is used when $schema is missing or not recognized; treated like 2020-12
+	SchemaDraftUnknown SchemaDraft = ""
+	SchemaDraft04      SchemaDraft = "draft-04"
+	SchemaDraft06      SchemaDraft = "draft-06"
+	SchemaDraft07      SchemaDraft = "draft-07"
+	SchemaDraft201909  SchemaDraft = "2019-09"
+	SchemaDraft202012  SchemaDraft = "2020-12"
+)
+
+// DetectSchemaDraft detects the JSON Schema draft from the $schema keyword
+func DetectSchemaDraft(schema map[string]any) SchemaDraft {
+	uri, _ := schema["$schema"].(string)
+	switch {
+	case strings.Contains(uri, "draft-04"):
+		return SchemaDraft04
+	case strings.Contains(uri, "draft-06"):
+		return SchemaDraft06
+	case strings.Contains(uri, "draft-07"):
+		return SchemaDraft07
+	case strings.Contains(uri, "2019-09"):
+		return SchemaDraft201909
+	case strings.Contains(uri, "2020-12"):
+		return SchemaDraft202012
+	default:
+		return SchemaDraftUnknown
+	}
+}
+
+// usesPrefixItems reports whether tuples are declared with prefixItems (2020-12) rather than an items array
+func (d SchemaDraft) usesPrefixItems() bool {
+	return d == SchemaDraft202012 || d == SchemaDraftUnknown
+}
+
+// supportsUnevaluated reports whether the draft defines unevaluatedProperties
+func (d SchemaDraft) supportsUnevaluated() bool {
+	return d == SchemaDraft201909 || d == SchemaDraft202012 || d == SchemaDraftUnknown
+}
+
+// unsupportedSchemaKeywords are keywords that compatibility checks and casts do not reason about
+var unsupportedSchemaKeywords = []string{
+	"$dynamicRef",
+	"$recursiveRef",
+	"anyOf",
+	"contains",
+	"dependencies",
+	"dependentRequired",
+	"dependentSchemas",
+	"maxContains",
+	"minContains",
+	"not",
+	"oneOf",
+	"patternProperties",
+	"propertyNames",
+	"unevaluatedItems",
+}
+
+// valueKeywords hold literal JSON values rather than subschemas and are not walked
+var valueKeywords = map[string]bool{
+	"const":    true,
+	"default":  true,
+	"enum":     true,
+	"examples": true,
+}
+
+// schemaMapKeywords map arbitrary names to subschemas
+var schemaMapKeywords = map[string]bool{
+	"properties":        true,
+	"patternProperties": true,
+	"dependentSchemas":  true,
+}
+
+// schemaNormalizer rewrites a schema into the canonical form used by compatibility checks and casts:
+// tuples are expressed with prefixItems (items holding the schema of the remaining positions),
+// local $ref pointers into $defs/definitions are inlined, and keywords the draft does not define are dropped.
+type schemaNormalizer struct {
+	root     map[string]any
+	draft    SchemaDraft
+	visiting map[string]bool
+	warnings map[string]bool
+}
+
+// normalizeSchema returns the canonical form of a schema together with warnings about
+// keywords that are ignored by compatibility checks and casts
+func normalizeSchema(schema map[string]any) (map[string]any, []string) {
+	if schema == nil {
+		return nil, nil
+	}
+	n := &schemaNormalizer{
+		root:     schema,
+		draft:    DetectSchemaDraft(schema),
+		visiting: make(map[string]bool),
+		warnings: make(map[string]bool),
+	}
+	normalized, _ := n.normalize(schema, "").(map[string]any)
+
+	warnings := make([]string, 0, len(n.warnings))
+	for w := range n.warnings {
+		warnings = append(warnings, w)
+	}
+	sort.Strings(warnings)
+	return normalized, warnings
+}
+
+// warn records a warning, anchoring it at path when one is known
+func (n *schemaNormalizer) warn(path, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if path != "" {
+		msg += " (at " + path + ")"
+	}
+	n.warnings[msg] = true
+}
+
+// normalize rewrites a single schema node
+func (n *schemaNormalizer) normalize(node any, path string) any {
+	switch v := node.(type) {
+	case map[string]any:
+		return n.normalizeObject(v, path)
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = n.normalize(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// normalizeObject rewrites a schema object node
+func (n *schemaNormalizer) normalizeObject(schema map[string]any, path string) map[string]any {
+	// Inline local references so that their structure takes part in the comparison. A reference
+	// to a definition being inlined closes a cycle and is kept, see localRefResolver.
+	if ref, ok := schema["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+		resolved, found := resolveLocalPointer(n.root, ref)
+		if !found {
+			n.warn(path, "Local $ref '%s' does not resolve within the schema and is ignored", ref)
+		}
+		if found && !n.visiting[ref] {
+			n.visiting[ref] = true
+			inlined := n.normalizeObject(resolved, path)
+			delete(n.visiting, ref)
+
+			merged := copyMap(inlined)
+			for k, v := range schema {
+				if k != "$ref" {
+					merged[k] = n.normalize(v, buildPath(path, k))
+				}
+			}
+			return merged
+		}
+	}
+
+	result := make(map[string]any, len(schema))
+	for k, v := range schema {
+		switch {
+		case valueKeywords[k] || k == "$defs" || k == "definitions":
+			// Definitions are reached through the references that use them
+			result[k] = v
+		case schemaMapKeywords[k]:
+			if named, ok := v.(map[string]any); ok {
+				normalized := make(map[string]any, len(named))
+				for name, sub := range named {
+					normalized[name] = n.normalize(sub, buildPath(path, name))
+				}
+				result[k] = normalized
+			} else {
+				result[k] = v
+			}
+		default:
+			result[k] = n.normalize(v, buildPath(path, k))
+		}
+	}
+
+	// Draft-specific tuple syntax
+	if !n.draft.usesPrefixItems() {
+		if _, ok := result["prefixItems"]; ok {
+			n.warn(path, "Keyword 'prefixItems' is not defined in %s and is ignored", n.draft)
+			delete(result, "prefixItems")
+		}
+	}
+	if items, ok := result["items"].([]any); ok {
+		// An items array is a tuple; it is not valid 2020-12 but is still common there, and its
+		// additionalItems tail schema becomes items
+		result["prefixItems"] = items
+		delete(result, "items")
+		if additional, ok := result["additionalItems"]; ok {
+			result["items"] = additional
+		}
+	}
+	delete(result, "additionalItems")
+
+	if _, ok := result["unevaluatedProperties"]; ok && !n.draft.supportsUnevaluated() {
+		n.warn(path, "Keyword 'unevaluatedProperties' is not defined in %s and is ignored", n.draft)
+		delete(result, "unevaluatedProperties")
+	}
+
+	if _, ok := result["if"]; ok {
+		n.warn(path, "Keyword 'if' is not considered by compatibility checks; casts apply the branch selected by const and enum conditions")
+	}
+	for _, keyword := range unsupportedSchemaKeywords {
+		if _, ok := result[keyword]; ok {
+			n.warn(path, "Keyword '%s' is not considered by compatibility checks and casts", keyword)
+		}
+	}
+
+	return result
+}
+
+// localRefResolver inlines the local $ref of a subschema on demand. normalizeSchema inlines a
+// recursive definition once and keeps the $ref closing the cycle; casts resolve such references
+// again as they descend into the instance, so a definition applies at every depth of the data.
+type localRefResolver struct {
+	root  map[string]any
+	draft SchemaDraft
+}
+
+// newLocalRefResolver creates a resolver for the local references of the schema document root
+func newLocalRefResolver(root map[string]any) *localRefResolver {
+	return &localRefResolver{root: root, draft: DetectSchemaDraft(root)}
+}
+
+// resolve returns schema with its local $ref inlined and normalized, or schema itself when it
+// holds no local $ref; a nil resolver resolves nothing
+func (r *localRefResolver) resolve(schema map[string]any) map[string]any {
+	if r == nil || schema == nil {
+		return schema
+	}
+	if ref, ok := schema["$ref"].(string); !ok || !strings.HasPrefix(ref, "#") {
+		return schema
+	}
+	n := &schemaNormalizer{root: r.root, draft: r.draft, visiting: make(map[string]bool), warnings: make(map[string]bool)}
+	return n.normalizeObject(schema, "")
+}
+
+// resolveLocalPointer resolves a local JSON pointer such as "#/$defs/address" against the root
+// schema; the pointer may be percent-encoded, as URI fragments are
+func resolveLocalPointer(root map[string]any, ref string) (map[string]any, bool) {
+	pointer := strings.TrimPrefix(ref, "#")
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	if pointer == "" {
+		return root, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	var current any = root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[token]; !ok {
+			return nil, false
+		}
+	}
+
+	resolved, ok := current.(map[string]any)
+	return resolved, ok
+}
+
+// getTupleItems returns the per-position schemas of a canonical (normalized) tuple schema
+func getTupleItems(schema map[string]any) []map[string]any {
+	list, ok := schema["prefixItems"].([]any)
+	if !ok {
+		return nil
+	}
+	items := make([]map[string]any, len(list))
+	for i, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			items[i] = m
+		} else {
+			items[i] = map[string]any{}
+		}
+	}
+	return items
+}
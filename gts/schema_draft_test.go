@@ -0,0 +1,535 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	draft07URI   = "http://json-schema.org/draft-07/schema#"
+	draft2020URI = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// anyContains reports whether any of the messages contains substr
+func anyContains(messages []string, substr string) bool {
+	for _, msg := range messages {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDetectSchemaDraft(t *testing.T) {
+	tests := map[string]SchemaDraft{
+		draft07URI: SchemaDraft07,
+		"http://json-schema.org/draft-04/schema#":      SchemaDraft04,
+		"https://json-schema.org/draft/2019-09/schema": SchemaDraft201909,
+		draft2020URI: SchemaDraft202012,
+		"":           SchemaDraftUnknown,
+	}
+	for uri, want := range tests {
+		if got := DetectSchemaDraft(map[string]any{"$schema": uri}); got != want {
+			t.Errorf("DetectSchemaDraft(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
+
+// tupleSchema builds a route schema whose coords property is a tuple in the given draft
+func tupleSchema(id, draftURI string, positions []any, minItems int) map[string]any {
+	coords := map[string]any{"type": "array", "minItems": minItems}
+	if draftURI == draft2020URI {
+		coords["prefixItems"] = positions
+	} else {
+		coords["items"] = positions
+	}
+	return map[string]any{
+		"$id":     id,
+		"$schema": draftURI,
+		"type":    "object",
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "string"},
+			"coords": coords,
+		},
+	}
+}
+
+func TestCheckCompatibility_Tuples(t *testing.T) {
+	for _, draftURI := range []string{draft07URI, draft2020URI} {
+		t.Run(string(DetectSchemaDraft(map[string]any{"$schema": draftURI})), func(t *testing.T) {
+			store := NewGtsStore(nil)
+			registerTestEntities(t, store,
+				tupleSchema("gts://gts.x.draft.ns.route.v1.0~", draftURI, []any{
+					map[string]any{"type": "number"},
+					map[string]any{"type": "number"},
+				}, 2),
+				tupleSchema("gts://gts.x.draft.ns.route.v1.1~", draftURI, []any{
+					map[string]any{"type": "number"},
+					map[string]any{"type": "string"},
+					map[string]any{"type": "number"},
+				}, 3),
+			)
+
+			result := store.CheckCompatibility("gts.x.draft.ns.route.v1.0~", "gts.x.draft.ns.route.v1.1~")
+			if result.IsBackwardCompatible {
+				t.Fatal("Expected tuple changes to break backward compatibility")
+			}
+			if !anyContains(result.BackwardErrors, "Property 'coords[1]' type changed from number to string") {
+				t.Errorf("Expected per-position type change, got %v", result.BackwardErrors)
+			}
+			if !anyContains(result.BackwardErrors, "added required tuple positions: 2 -> 3") {
+				t.Errorf("Expected added required position error, got %v", result.BackwardErrors)
+			}
+			if len(result.Warnings) != 0 {
+				t.Errorf("Expected no warnings, got %v", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestCheckCompatibility_TupleOptionalPositionAdded(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerTestEntities(t, store,
+		tupleSchema("gts://gts.x.draft.ns.route.v1.0~", draft2020URI, []any{
+			map[string]any{"type": "number"},
+		}, 1),
+		tupleSchema("gts://gts.x.draft.ns.route.v1.1~", draft2020URI, []any{
+			map[string]any{"type": "number"},
+			map[string]any{"type": "number"},
+		}, 1),
+	)
+
+	result := store.CheckCompatibility("gts.x.draft.ns.route.v1.0~", "gts.x.draft.ns.route.v1.1~")
+	if !result.IsBackwardCompatible {
+		t.Errorf("Expected optional tuple position to be backward compatible, got %v", result.BackwardErrors)
+	}
+}
+
+// closedSchema builds a profile schema closed with unevaluatedProperties in the given draft
+func closedSchema(id, draftURI string, props map[string]any, closed bool) map[string]any {
+	schema := map[string]any{
+		"$id":        id,
+		"$schema":    draftURI,
+		"type":       "object",
+		"properties": props,
+	}
+	if closed {
+		schema["unevaluatedProperties"] = false
+	}
+	return schema
+}
+
+func TestCheckCompatibility_UnevaluatedProperties(t *testing.T) {
+	oldProps := map[string]any{
+		"id":       map[string]any{"type": "string"},
+		"nickname": map[string]any{"type": "string"},
+	}
+	newProps := map[string]any{
+		"id": map[string]any{"type": "string"},
+	}
+
+	t.Run("2020-12 closes the model", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		registerTestEntities(t, store,
+			closedSchema("gts://gts.x.draft.ns.profile.v1.0~", draft2020URI, oldProps, false),
+			closedSchema("gts://gts.x.draft.ns.profile.v1.1~", draft2020URI, newProps, true),
+		)
+		result := store.CheckCompatibility("gts.x.draft.ns.profile.v1.0~", "gts.x.draft.ns.profile.v1.1~")
+		if result.IsBackwardCompatible {
+			t.Fatal("Expected closing the model over a removed property to break backward compatibility")
+		}
+		if !anyContains(result.BackwardErrors, "Removed properties not allowed by closed model: nickname") {
+			t.Errorf("Unexpected backward errors: %v", result.BackwardErrors)
+		}
+	})
+
+	t.Run("draft-07 ignores the keyword with a warning", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		registerTestEntities(t, store,
+			closedSchema("gts://gts.x.draft.ns.profile.v1.0~", draft07URI, oldProps, false),
+			closedSchema("gts://gts.x.draft.ns.profile.v1.1~", draft07URI, newProps, true),
+		)
+		result := store.CheckCompatibility("gts.x.draft.ns.profile.v1.0~", "gts.x.draft.ns.profile.v1.1~")
+		if !result.IsBackwardCompatible {
+			t.Errorf("Expected draft-07 schemas to stay compatible, got %v", result.BackwardErrors)
+		}
+		if !anyContains(result.Warnings, "'unevaluatedProperties' is not defined in draft-07") {
+			t.Errorf("Expected unevaluatedProperties warning, got %v", result.Warnings)
+		}
+	})
+}
+
+func TestCheckCompatibility_LocalDefsAndUnsupportedKeywords(t *testing.T) {
+	store := NewGtsStore(nil)
+	registerTestEntities(t, store,
+		map[string]any{
+			"$id":     "gts://gts.x.draft.ns.place.v1.0~",
+			"$schema": draft2020URI,
+			"type":    "object",
+			"$defs": map[string]any{
+				"point": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"lat": map[string]any{"type": "number"}},
+				},
+			},
+			"properties": map[string]any{
+				"location": map[string]any{"$ref": "#/$defs/point"},
+			},
+		},
+		map[string]any{
+			"$id":     "gts://gts.x.draft.ns.place.v1.1~",
+			"$schema": draft2020URI,
+			"type":    "object",
+			"$defs": map[string]any{
+				"point": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"lat": map[string]any{"type": "string"}},
+				},
+			},
+			"properties": map[string]any{
+				"location": map[string]any{"$ref": "#/$defs/point"},
+				"tags": map[string]any{
+					"type":     "array",
+					"contains": map[string]any{"const": "primary"},
+				},
+			},
+		},
+	)
+
+	result := store.CheckCompatibility("gts.x.draft.ns.place.v1.0~", "gts.x.draft.ns.place.v1.1~")
+	if !anyContains(result.BackwardErrors, "Property 'location': Property 'lat' type changed from number to string") {
+		t.Errorf("Expected change inside $defs to be detected, got %v", result.BackwardErrors)
+	}
+	if !anyContains(result.Warnings, "Keyword 'contains' is not considered by compatibility checks and casts (at tags)") {
+		t.Errorf("Expected unsupported keyword warning, got %v", result.Warnings)
+	}
+}
+
+func TestCast_Tuples(t *testing.T) {
+	stop := func(extra bool) map[string]any {
+		props := map[string]any{
+			"name": map[string]any{"type": "string"},
+		}
+		if extra {
+			props["kind"] = map[string]any{"type": "string", "default": "stop"}
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	}
+
+	for _, draftURI := range []string{draft07URI, draft2020URI} {
+		t.Run(string(DetectSchemaDraft(map[string]any{"$schema": draftURI})), func(t *testing.T) {
+			v10 := tupleSchema("gts://gts.x.draft.ns.trip.v1.0~", draftURI, []any{stop(false), stop(false)}, 0)
+			v11 := tupleSchema("gts://gts.x.draft.ns.trip.v1.1~", draftURI, []any{stop(true), stop(true)}, 0)
+			// Close the tuple in v1.1 so that surplus positions are dropped
+			coords := v11["properties"].(map[string]any)["coords"].(map[string]any)
+			if draftURI == draft2020URI {
+				coords["items"] = false
+			} else {
+				coords["additionalItems"] = false
+			}
+
+			store := NewGtsStore(nil)
+			registerTestEntities(t, store, v10, v11, map[string]any{
+				"id": "gts.x.draft.ns.trip.v1.0~x.app._.trip.v1",
+				"coords": []any{
+					map[string]any{"name": "start"},
+					map[string]any{"name": "end"},
+					map[string]any{"name": "detour"},
+				},
+			})
+
+			result, err := store.Cast("gts.x.draft.ns.trip.v1.0~x.app._.trip.v1", "gts.x.draft.ns.trip.v1.1~")
+			if err != nil {
+				t.Fatalf("Cast failed: %v", err)
+			}
+
+			coordsOut, ok := result.CastedEntity["coords"].([]any)
+			if !ok || len(coordsOut) != 2 {
+				t.Fatalf("Expected surplus tuple position to be dropped, got %v", result.CastedEntity["coords"])
+			}
+			for i, pos := range coordsOut {
+				if pos.(map[string]any)["kind"] != "stop" {
+					t.Errorf("Expected default to be filled at position %d, got %v", i, pos)
+				}
+			}
+			if !anyContains(result.AddedProperties, "coords[1].kind") {
+				t.Errorf("Expected per-position added property, got %v", result.AddedProperties)
+			}
+			if !anyContains(result.RemovedProperties, "coords[2]") {
+				t.Errorf("Expected removed surplus position, got %v", result.RemovedProperties)
+			}
+		})
+	}
+}
+
+func TestCast_UnevaluatedPropertiesPrunes(t *testing.T) {
+	props := map[string]any{
+		"id":   map[string]any{"type": "string"},
+		"name": map[string]any{"type": "string"},
+	}
+
+	t.Run("2020-12", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		registerTestEntities(t, store,
+			closedSchema("gts://gts.x.draft.ns.profile.v1.0~", draft2020URI, props, false),
+			closedSchema("gts://gts.x.draft.ns.profile.v1.1~", draft2020URI, props, true),
+			map[string]any{"id": "gts.x.draft.ns.profile.v1.0~x.app._.alice.v1", "name": "Alice", "legacy": true},
+		)
+		result, err := store.Cast("gts.x.draft.ns.profile.v1.0~x.app._.alice.v1", "gts.x.draft.ns.profile.v1.1~")
+		if err != nil {
+			t.Fatalf("Cast failed: %v", err)
+		}
+		if _, ok := result.CastedEntity["legacy"]; ok {
+			t.Error("Expected property to be pruned by unevaluatedProperties: false")
+		}
+		if !result.IsFullyCompatible {
+			t.Errorf("Expected cast result to validate, got %v", result.IncompatibilityReasons)
+		}
+	})
+
+	t.Run("draft-07", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		registerTestEntities(t, store,
+			closedSchema("gts://gts.x.draft.ns.profile.v1.0~", draft07URI, props, false),
+			closedSchema("gts://gts.x.draft.ns.profile.v1.1~", draft07URI, props, true),
+			map[string]any{"id": "gts.x.draft.ns.profile.v1.0~x.app._.alice.v1", "name": "Alice", "legacy": true},
+		)
+		result, err := store.Cast("gts.x.draft.ns.profile.v1.0~x.app._.alice.v1", "gts.x.draft.ns.profile.v1.1~")
+		if err != nil {
+			t.Fatalf("Cast failed: %v", err)
+		}
+		if _, ok := result.CastedEntity["legacy"]; !ok {
+			t.Error("Expected draft-07 cast to keep the property")
+		}
+		if len(result.Warnings) == 0 {
+			t.Error("Expected warning about unevaluatedProperties in draft-07")
+		}
+	})
+}
+
+// localDefsOrderSchema is an order schema whose total and tree properties reference definitions
+// under spelling; with v1 the money definition requires a currency and nodes a kind, both with
+// defaults. The node definition is recursive.
+func localDefsOrderSchema(id, draft, spelling string, v1 bool) map[string]any {
+	money := map[string]any{
+		"type":       "object",
+		"required":   []any{"amount"},
+		"properties": map[string]any{"amount": map[string]any{"type": "number"}},
+	}
+	node := map[string]any{
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string"},
+			"children": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/" + spelling + "/node"}},
+		},
+	}
+	if v1 {
+		money["required"] = []any{"amount", "currency"}
+		money["properties"].(map[string]any)["currency"] = map[string]any{"type": "string", "default": "USD"}
+		node["required"] = []any{"name", "kind"}
+		node["properties"].(map[string]any)["kind"] = map[string]any{"type": "string", "default": "leaf"}
+	}
+	return map[string]any{
+		"$id":     "gts://" + id,
+		"$schema": draft,
+		"type":    "object",
+		spelling:  map[string]any{"money": money, "node": node},
+		"properties": map[string]any{
+			"total": map[string]any{"$ref": "#/" + spelling + "/money"},
+			"tree":  map[string]any{"$ref": "#/" + spelling + "/node"},
+		},
+	}
+}
+
+func TestCast_LocalDefs(t *testing.T) {
+	for _, tt := range []struct{ draft, spelling string }{
+		{draft2020URI, "$defs"},
+		{"http://json-schema.org/draft-07/schema#", "definitions"},
+	} {
+		t.Run(tt.spelling, func(t *testing.T) {
+			store := NewGtsStore(nil)
+			registerTestEntities(t, store,
+				localDefsOrderSchema("gts.x.draft.ns.order.v1.0~", tt.draft, tt.spelling, false),
+				localDefsOrderSchema("gts.x.draft.ns.order.v1.1~", tt.draft, tt.spelling, true),
+				map[string]any{
+					"id":    "gts.x.draft.ns.order.v1.0~x.test._.a.v1",
+					"total": map[string]any{"amount": 10.0},
+					"tree": map[string]any{"name": "root", "children": []any{
+						map[string]any{"name": "a", "children": []any{map[string]any{"name": "a1"}}},
+					}},
+				},
+			)
+
+			result, err := store.Cast("gts.x.draft.ns.order.v1.0~x.test._.a.v1", "gts.x.draft.ns.order.v1.1~")
+			if err != nil {
+				t.Fatalf("Cast failed: %v", err)
+			}
+			for _, path := range []string{"total.currency", "tree.kind", "tree.children[0].kind", "tree.children[0].children[0].kind"} {
+				if !anyContains(result.AddedProperties, path) {
+					t.Errorf("Expected %s to be added, got %v", path, result.AddedProperties)
+				}
+			}
+			if len(result.IncompatibilityReasons) != 0 {
+				t.Errorf("Expected the cast instance to be valid, got %v", result.IncompatibilityReasons)
+			}
+			if !anyContains(result.BackwardErrors, "Property 'total': Added required properties: currency") {
+				t.Errorf("Expected the required currency to break backward compatibility, got %v", result.BackwardErrors)
+			}
+		})
+	}
+}
+
+func TestNormalizeSchema_UnresolvedLocalRef(t *testing.T) {
+	_, warnings := normalizeSchema(map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"total": map[string]any{"$ref": "#/$defs/missing"}},
+	})
+	if !anyContains(warnings, "Local $ref '#/$defs/missing' does not resolve within the schema and is ignored (at total)") {
+		t.Errorf("Expected a warning for the unresolved $ref, got %v", warnings)
+	}
+}
+
+func TestNormalizeSchema_LegacyTupleTail(t *testing.T) {
+	// An items array with additionalItems, read as a tuple in 2020-12 and without $schema too
+	for _, draftURI := range []string{draft07URI, draft2020URI, ""} {
+		schema := map[string]any{
+			"type":            "array",
+			"items":           []any{map[string]any{"type": "string"}},
+			"additionalItems": false,
+		}
+		if draftURI != "" {
+			schema["$schema"] = draftURI
+		}
+		normalized, _ := normalizeSchema(schema)
+		if _, ok := normalized["prefixItems"].([]any); !ok || normalized["items"] != false {
+			t.Errorf("Expected the tuple to stay closed for %q, got %v", draftURI, normalized)
+		}
+		if _, ok := normalized["additionalItems"]; ok {
+			t.Errorf("Expected additionalItems to be mapped for %q, got %v", draftURI, normalized)
+		}
+	}
+
+	// A closed tuple cast from an open one drops the surplus position
+	store := NewGtsStore(nil)
+	open := tupleSchema("gts://gts.x.draft.ns.pair.v1.0~", draft07URI, []any{map[string]any{"type": "string"}}, 0)
+	closed := tupleSchema("gts://gts.x.draft.ns.pair.v1.1~", draft2020URI, nil, 0)
+	coords := closed["properties"].(map[string]any)["coords"].(map[string]any)
+	delete(coords, "prefixItems")
+	coords["items"] = []any{map[string]any{"type": "string"}}
+	coords["additionalItems"] = false
+	registerTestEntities(t, store, open, closed, map[string]any{
+		"id":     "gts.x.draft.ns.pair.v1.0~x.app._.pair.v1",
+		"coords": []any{"a", "b"},
+	})
+	result, err := store.Cast("gts.x.draft.ns.pair.v1.0~x.app._.pair.v1", "gts.x.draft.ns.pair.v1.1~")
+	if err != nil {
+		t.Fatalf("Cast failed: %v", err)
+	}
+	if coordsOut, ok := result.CastedEntity["coords"].([]any); !ok || len(coordsOut) != 1 {
+		t.Errorf("Expected the surplus tuple position to be dropped, got %v", result.CastedEntity["coords"])
+	}
+}
+
+func TestCheckCompatibility_TupleRemainderItems(t *testing.T) {
+	route := func(id string, positions []any) map[string]any {
+		schema := tupleSchema(id, draft2020URI, positions, 0)
+		schema["properties"].(map[string]any)["coords"].(map[string]any)["items"] = map[string]any{"type": "number"}
+		return schema
+	}
+	store := NewGtsStore(nil)
+	registerTestEntities(t, store,
+		route("gts://gts.x.draft.ns.route.v1.0~", []any{map[string]any{"type": "string"}}),
+		route("gts://gts.x.draft.ns.route.v1.1~", []any{map[string]any{"type": "string"}, map[string]any{"type": "string"}}),
+	)
+
+	// Position 1 was governed by the items schema of v1.0
+	result := store.CheckCompatibility("gts.x.draft.ns.route.v1.0~", "gts.x.draft.ns.route.v1.1~")
+	if !anyContains(result.BackwardErrors, "Property 'coords[1]' type changed from number to string") {
+		t.Errorf("Expected the new position to be compared with the old items, got %v", result.BackwardErrors)
+	}
+	result = store.CheckCompatibility("gts.x.draft.ns.route.v1.1~", "gts.x.draft.ns.route.v1.0~")
+	if !anyContains(result.BackwardErrors, "Property 'coords[1]' type changed from string to number") {
+		t.Errorf("Expected the removed position to be compared with the new items, got %v", result.BackwardErrors)
+	}
+}
+
+// ownerSchema builds an account schema whose owner object merges allOf layers closed with
+// unevaluatedProperties; v1.1 adds an optional phone layer
+func ownerSchema(id, draftURI string, withPhone bool) map[string]any {
+	layers := []any{
+		map[string]any{"properties": map[string]any{"name": map[string]any{"type": "string"}}},
+		map[string]any{"properties": map[string]any{"email": map[string]any{"type": "string"}}},
+	}
+	if withPhone {
+		layers = append(layers, map[string]any{"properties": map[string]any{"phone": map[string]any{"type": "string"}}})
+	}
+	return map[string]any{
+		"$id":     id,
+		"$schema": draftURI,
+		"type":    "object",
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "string"},
+			"owner": map[string]any{"type": "object", "allOf": layers, "unevaluatedProperties": false},
+		},
+	}
+}
+
+func TestUnevaluatedProperties_NestedAllOf(t *testing.T) {
+	instance := func() map[string]any {
+		return map[string]any{
+			"id":    "gts.x.draft.ns.account.v1.0~x.app._.acme.v1",
+			"owner": map[string]any{"name": "Alice", "email": "alice@example.com", "legacy": true},
+		}
+	}
+
+	t.Run("2020-12", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		registerTestEntities(t, store,
+			ownerSchema("gts://gts.x.draft.ns.account.v1.0~", draft2020URI, false),
+			ownerSchema("gts://gts.x.draft.ns.account.v1.1~", draft2020URI, true),
+			instance(),
+		)
+		result, err := store.Cast("gts.x.draft.ns.account.v1.0~x.app._.acme.v1", "gts.x.draft.ns.account.v1.1~")
+		if err != nil {
+			t.Fatalf("Cast failed: %v", err)
+		}
+		owner := result.CastedEntity["owner"].(map[string]any)
+		if _, ok := owner["legacy"]; ok || owner["name"] != "Alice" || owner["email"] != "alice@example.com" {
+			t.Errorf("Expected only the properties of the allOf layers to be kept, got %v", owner)
+		}
+		if !anyContains(result.RemovedProperties, "owner.legacy") {
+			t.Errorf("Expected owner.legacy to be removed, got %v", result.RemovedProperties)
+		}
+
+		compat := store.CheckCompatibility("gts.x.draft.ns.account.v1.0~", "gts.x.draft.ns.account.v1.1~")
+		if compat.IsForwardCompatible || !anyContains(compat.ForwardErrors, "Added properties not allowed by closed model: phone") {
+			t.Errorf("Expected the closed owner to reject the added phone, got %v", compat.ForwardErrors)
+		}
+	})
+
+	t.Run("draft-07", func(t *testing.T) {
+		store := NewGtsStore(nil)
+		registerTestEntities(t, store,
+			ownerSchema("gts://gts.x.draft.ns.account.v1.0~", draft07URI, false),
+			ownerSchema("gts://gts.x.draft.ns.account.v1.1~", draft07URI, true),
+			instance(),
+		)
+		result, err := store.Cast("gts.x.draft.ns.account.v1.0~x.app._.acme.v1", "gts.x.draft.ns.account.v1.1~")
+		if err != nil {
+			t.Fatalf("Cast failed: %v", err)
+		}
+		if _, ok := result.CastedEntity["owner"].(map[string]any)["legacy"]; !ok {
+			t.Error("Expected draft-07 cast to keep the property")
+		}
+		compat := store.CheckCompatibility("gts.x.draft.ns.account.v1.0~", "gts.x.draft.ns.account.v1.1~")
+		if !compat.IsForwardCompatible {
+			t.Errorf("Expected the draft-07 owner to stay open, got %v", compat.ForwardErrors)
+		}
+	})
+}
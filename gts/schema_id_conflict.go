@@ -0,0 +1,191 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaIDCandidate is a schema ID found in a field of an instance
+type SchemaIDCandidate struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// SchemaIDConflictError is returned by Register when the schema-ID fields of an instance name
+// different schemas and RegistryConfig.RejectSchemaIDConflicts is set
+type SchemaIDConflictError struct {
+	EntityID string
+	// Selected is the schema ID the entity was given; Conflicting are the fields disagreeing with it
+	Selected    SchemaIDCandidate
+	Conflicting []SchemaIDCandidate
+}
+
+func (e *SchemaIDConflictError) Error() string {
+	parts := make([]string, 0, len(e.Conflicting))
+	for _, c := range e.Conflicting {
+		parts = append(parts, fmt.Sprintf("%s is '%s'", c.Field, c.Value))
+	}
+	return fmt.Sprintf("Conflicting schema IDs for entity %s: %s is '%s' but %s",
+		e.EntityID, e.Selected.Field, e.Selected.Value, strings.Join(parts, ", "))
+}
+
+// schemaIDFieldOrder returns the schema-ID fields in precedence order: the fields of
+// SchemaIDFieldPrecedence that are schema-ID fields, then the others in SchemaIDFields order
+func (c *GtsConfig) schemaIDFieldOrder() []string {
+	if len(c.SchemaIDFieldPrecedence) == 0 {
+		return c.SchemaIDFields
+	}
+	known := make(map[string]bool, len(c.SchemaIDFields))
+	for _, field := range c.SchemaIDFields {
+		known[field] = true
+	}
+	order := make([]string, 0, len(c.SchemaIDFields))
+	listed := make(map[string]bool, len(c.SchemaIDFieldPrecedence))
+	for _, field := range c.SchemaIDFieldPrecedence {
+		if known[field] && !listed[field] {
+			order = append(order, field)
+			listed[field] = true
+		}
+	}
+	for _, field := range c.SchemaIDFields {
+		if !listed[field] {
+			order = append(order, field)
+		}
+	}
+	return order
+}
+
+// schemaIDCandidates returns the fields holding a GTS schema ID, in the given order. Values that
+// are not GTS IDs, such as a "type" property of the payload, are not schema IDs and are ignored.
+func (e *JsonEntity) schemaIDCandidates(fields []string) []SchemaIDCandidate {
+	var candidates []SchemaIDCandidate
+	for _, field := range fields {
+		if val := e.getFieldValue(field); val != "" && IsValidGtsID(val) {
+			candidates = append(candidates, SchemaIDCandidate{Field: field, Value: val})
+		}
+	}
+	return candidates
+}
+
+// resolveSchemaIDConflict records the schema-ID fields of an instance that disagree with its
+// selected schema ID. selected is the field and value calcJSONSchemaID chose; chainDerived is set
+// when they come from the entity ID, which then always wins. Otherwise, when every candidate
+// names the same type and major version, the newest minor version is preferred over the
+// precedence order and the adjustment is noted.
+func (e *JsonEntity) resolveSchemaIDConflict(cfg *GtsConfig, selected SchemaIDCandidate, chainDerived bool) string {
+	candidates := e.schemaIDCandidates(cfg.schemaIDFieldOrder())
+	if chainDerived {
+		candidates = append([]SchemaIDCandidate{selected}, candidates...)
+	}
+
+	conflict := false
+	for _, c := range candidates {
+		if !schemaIDAgrees(c.Value, selected.Value) {
+			conflict = true
+			break
+		}
+	}
+	if !conflict {
+		return selected.Value
+	}
+	e.ConflictingSchemaIDs = candidates
+
+	if chainDerived || !schemaIDsDifferInMinorOnly(candidates) {
+		return selected.Value
+	}
+	newest := selected
+	for _, c := range candidates {
+		if compareMinorVersions(c.Value, newest.Value) > 0 {
+			newest = c
+		}
+	}
+	if newest.Value != selected.Value {
+		e.SchemaIDAdjustment = fmt.Sprintf("%s '%s' preferred over %s '%s' as the newer minor version",
+			newest.Field, newest.Value, selected.Field, selected.Value)
+		e.SelectedSchemaIDField = newest.Field
+	}
+	return newest.Value
+}
+
+// schemaIDConflictError returns the error of an instance whose schema-ID fields disagree beyond
+// minor versions, or nil
+func (e *JsonEntity) schemaIDConflictError() error {
+	if e.IsSchema || len(e.ConflictingSchemaIDs) == 0 || schemaIDsDifferInMinorOnly(e.ConflictingSchemaIDs) {
+		return nil
+	}
+	err := &SchemaIDConflictError{Selected: SchemaIDCandidate{Field: e.SelectedSchemaIDField, Value: e.SchemaID}}
+	if e.GtsID != nil {
+		err.EntityID = e.GtsID.ID
+	}
+	for _, c := range e.ConflictingSchemaIDs {
+		if !schemaIDAgrees(c.Value, e.SchemaID) {
+			err.Conflicting = append(err.Conflicting, c)
+		}
+	}
+	return err
+}
+
+// schemaIDAgrees reports whether a schema-ID field value is consistent with the selected schema
+// ID: the same ID or, for a derived schema, one of its base types
+func schemaIDAgrees(value, selected string) bool {
+	return value == selected || (strings.HasSuffix(value, "~") && strings.HasPrefix(selected, value))
+}
+
+// schemaIDsDifferInMinorOnly reports whether the candidates name the same types with the same
+// major versions, differing in minor versions only
+func schemaIDsDifferInMinorOnly(candidates []SchemaIDCandidate) bool {
+	key := ""
+	for i, c := range candidates {
+		id, err := NewGtsID(c.Value)
+		if err != nil {
+			return false
+		}
+		k := majorVersionKey(id)
+		if i == 0 {
+			key = k
+		} else if k != key {
+			return false
+		}
+	}
+	return true
+}
+
+// majorVersionKey identifies a GTS ID without its minor versions
+func majorVersionKey(id *GtsID) string {
+	parts := make([]string, 0, len(id.Segments))
+	for _, seg := range id.Segments {
+		parts = append(parts, fmt.Sprintf("%s.%s.%s.%s.v%d~%t", seg.Vendor, seg.Package, seg.Namespace, seg.Type, seg.VerMajor, seg.IsType))
+	}
+	return strings.Join(parts, "")
+}
+
+// compareMinorVersions compares the minor versions of two IDs differing in minor versions only,
+// segment by segment; a missing minor version is older than any
+func compareMinorVersions(a, b string) int {
+	idA, errA := NewGtsID(a)
+	idB, errB := NewGtsID(b)
+	if errA != nil || errB != nil || len(idA.Segments) != len(idB.Segments) {
+		return 0
+	}
+	for i := range idA.Segments {
+		minorA, minorB := -1, -1
+		if idA.Segments[i].VerMinor != nil {
+			minorA = *idA.Segments[i].VerMinor
+		}
+		if idB.Segments[i].VerMinor != nil {
+			minorB = *idB.Segments[i].VerMinor
+		}
+		if minorA != minorB {
+			if minorA < minorB {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
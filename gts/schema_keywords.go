@@ -0,0 +1,290 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GtsSchemaExtensions are the GTS keywords accepted in schemas in addition to the JSON Schema vocabulary
+var GtsSchemaExtensions = []string{
+	"x-gts-cast",
+	"x-gts-ref",
+	"x-gts-status",
+}
+
+// gtsExtensionPrefix marks GTS schema extensions; unknown ones are reported by the strict keyword check
+const gtsExtensionPrefix = "x-gts-"
+
+// commonSchemaKeywords are defined by every supported draft
+var commonSchemaKeywords = []string{
+	"$ref", "$schema",
+	"additionalItems", "additionalProperties", "allOf", "anyOf",
+	"default", "definitions", "dependencies", "description",
+	"enum", "exclusiveMaximum", "exclusiveMinimum", "format",
+	"items", "maxItems", "maxLength", "maxProperties", "maximum",
+	"minItems", "minLength", "minProperties", "minimum", "multipleOf",
+	"not", "oneOf", "pattern", "patternProperties", "properties",
+	"required", "title", "type", "uniqueItems",
+	// GTS spelling of $id and $schema, see validateWithSchema
+	"$$id", "$$schema",
+}
+
+// draftSchemaKeywords are the keywords each draft adds to commonSchemaKeywords
+var draftSchemaKeywords = map[SchemaDraft][]string{
+	SchemaDraft04: {"id"},
+	SchemaDraft06: {"$id", "const", "contains", "examples", "propertyNames"},
+	SchemaDraft07: {"$comment", "$id", "const", "contains", "contentEncoding", "contentMediaType",
+		"else", "examples", "if", "propertyNames", "readOnly", "then", "writeOnly"},
+	SchemaDraft201909: {"$anchor", "$comment", "$defs", "$id", "$recursiveAnchor", "$recursiveRef", "$vocabulary",
+		"const", "contains", "contentEncoding", "contentMediaType", "contentSchema", "dependentRequired",
+		"dependentSchemas", "deprecated", "else", "examples", "if", "maxContains", "minContains",
+		"propertyNames", "readOnly", "then", "unevaluatedItems", "unevaluatedProperties", "writeOnly"},
+	SchemaDraft202012: {"$anchor", "$comment", "$defs", "$dynamicAnchor", "$dynamicRef", "$id", "$vocabulary",
+		"const", "contains", "contentEncoding", "contentMediaType", "contentSchema", "dependentRequired",
+		"dependentSchemas", "deprecated", "else", "examples", "if", "maxContains", "minContains",
+		"prefixItems", "propertyNames", "readOnly", "then", "unevaluatedItems", "unevaluatedProperties", "writeOnly"},
+}
+
+// subschemaKeywords hold a single subschema
+var subschemaKeywords = map[string]bool{
+	"additionalItems":       true,
+	"additionalProperties":  true,
+	"contains":              true,
+	"contentSchema":         true,
+	"else":                  true,
+	"if":                    true,
+	"items":                 true,
+	"not":                   true,
+	"propertyNames":         true,
+	"then":                  true,
+	"unevaluatedItems":      true,
+	"unevaluatedProperties": true,
+}
+
+// subschemaListKeywords hold an array of subschemas
+var subschemaListKeywords = map[string]bool{
+	"allOf":       true,
+	"anyOf":       true,
+	"items":       true,
+	"oneOf":       true,
+	"prefixItems": true,
+}
+
+// namedSubschemaKeywords map arbitrary names to subschemas; the names themselves are user data
+var namedSubschemaKeywords = map[string]bool{
+	"$defs":             true,
+	"definitions":       true,
+	"dependentSchemas":  true,
+	"patternProperties": true,
+	"properties":        true,
+}
+
+// SchemaKeywordFinding reports a schema key that is not a keyword of the schema's draft
+type SchemaKeywordFinding struct {
+	Path       string `json:"path"`
+	Keyword    string `json:"keyword"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Message    string `json:"message"`
+}
+
+// CheckSchemaKeywords reports every key of a schema that is neither a JSON Schema keyword of the
+// detected draft (any draft when $schema is missing or unknown) nor a GTS extension.
+// User data is not checked: values of const, default, enum and examples, and the names under
+// properties, patternProperties, $defs, definitions and dependentSchemas. Keys starting with "x-"
+// other than "x-gts-" are treated as vendor extensions. Findings are sorted by path.
+func CheckSchemaKeywords(schema map[string]any) []SchemaKeywordFinding {
+	if schema == nil {
+		return nil
+	}
+
+	draft := DetectSchemaDraft(schema)
+	known := make(map[string]bool)
+	for _, keyword := range commonSchemaKeywords {
+		known[keyword] = true
+	}
+	for d, keywords := range draftSchemaKeywords {
+		if d == draft || draft == SchemaDraftUnknown {
+			for _, keyword := range keywords {
+				known[keyword] = true
+			}
+		}
+	}
+
+	c := &schemaKeywordChecker{draft: draft, known: known}
+	c.checkSchema(schema, "")
+	sort.Slice(c.findings, func(i, j int) bool {
+		return c.findings[i].Path < c.findings[j].Path
+	})
+	return c.findings
+}
+
+// schemaKeywordChecker walks a schema collecting unknown keywords
+type schemaKeywordChecker struct {
+	draft    SchemaDraft
+	known    map[string]bool
+	findings []SchemaKeywordFinding
+}
+
+// checkSchema checks the keys of a schema object and descends into its subschemas
+func (c *schemaKeywordChecker) checkSchema(node any, path string) {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+
+	for key, value := range schema {
+		keyPath := joinSchemaPath(path, key)
+		switch {
+		case c.known[key]:
+		case strings.HasPrefix(key, gtsExtensionPrefix):
+			if !isGtsSchemaExtension(key) {
+				c.report(keyPath, key, GtsSchemaExtensions, "Unknown GTS extension '%s'", key)
+			}
+			continue
+		case strings.HasPrefix(key, "x-"):
+			continue
+		default:
+			if definedInOtherDraft(key) {
+				c.report(keyPath, key, nil, "Keyword '%s' is not defined in %s", key, c.draft)
+			} else {
+				c.report(keyPath, key, c.knownKeywords(), "Unknown keyword '%s'", key)
+			}
+			// The value of an unknown key may be anything, so it is not walked
+			continue
+		}
+
+		if namedSubschemaKeywords[key] {
+			if named, ok := value.(map[string]any); ok {
+				for name, sub := range named {
+					c.checkSchema(sub, joinSchemaPath(keyPath, name))
+				}
+			}
+			continue
+		}
+		if subschemaListKeywords[key] {
+			if list, ok := value.([]any); ok {
+				for i, sub := range list {
+					c.checkSchema(sub, fmt.Sprintf("%s[%d]", keyPath, i))
+				}
+				continue
+			}
+		}
+		if subschemaKeywords[key] {
+			c.checkSchema(value, keyPath)
+		}
+	}
+}
+
+// report records a finding, suggesting the closest of candidates when one is close enough
+func (c *schemaKeywordChecker) report(path, key string, candidates []string, format string, args ...any) {
+	finding := SchemaKeywordFinding{
+		Path:    path,
+		Keyword: key,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if suggestion := closestKeyword(key, candidates); suggestion != "" {
+		finding.Suggestion = suggestion
+		finding.Message += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	finding.Message += " (at " + path + ")"
+	c.findings = append(c.findings, finding)
+}
+
+// knownKeywords returns the keywords of the checked draft, sorted so suggestions are deterministic
+func (c *schemaKeywordChecker) knownKeywords() []string {
+	keywords := make([]string, 0, len(c.known))
+	for keyword := range c.known {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+func isGtsSchemaExtension(key string) bool {
+	for _, extension := range GtsSchemaExtensions {
+		if key == extension {
+			return true
+		}
+	}
+	return false
+}
+
+// definedInOtherDraft reports whether key is a keyword of some draft other than the checked one
+func definedInOtherDraft(key string) bool {
+	for _, keywords := range draftSchemaKeywords {
+		for _, keyword := range keywords {
+			if keyword == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// closestKeyword returns the candidate with the smallest edit distance to key, provided the
+// distance is small relative to the key's length; ties go to the first candidate
+func closestKeyword(key string, candidates []string) string {
+	maxDistance := len(key) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	best, bestDistance := "", maxDistance+1
+	lowerKey := strings.ToLower(key)
+	for _, candidate := range candidates {
+		if d := editDistance(lowerKey, strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b, counting an adjacent
+// transposition as a single edit
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// joinSchemaPath appends a key to a slash separated schema path
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "/" + key
+}
+
+// schemaKeywordError joins strict keyword findings into a single error
+func schemaKeywordError(findings []SchemaKeywordFinding) error {
+	messages := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		messages = append(messages, finding.Message)
+	}
+	return fmt.Errorf("strict keyword check failed: %s", strings.Join(messages, "; "))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSchemaKeywords_Typos(t *testing.T) {
+	tests := []struct {
+		keyword    string
+		suggestion string
+	}{
+		{"additionnalProperties", "additionalProperties"},
+		{"additionalProperites", "additionalProperties"},
+		{"requried", "required"},
+		{"minLenght", "minLength"},
+		{"propeties", "properties"},
+		{"Type", "type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyword, func(t *testing.T) {
+			schema := map[string]any{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type":    "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string", tt.keyword: true},
+				},
+			}
+			findings := CheckSchemaKeywords(schema)
+			if len(findings) != 1 {
+				t.Fatalf("Expected 1 finding, got %+v", findings)
+			}
+			f := findings[0]
+			if f.Keyword != tt.keyword || f.Suggestion != tt.suggestion || f.Path != "properties/name/"+tt.keyword {
+				t.Errorf("Unexpected finding: %+v", f)
+			}
+			if !strings.Contains(f.Message, "did you mean '"+tt.suggestion+"'") {
+				t.Errorf("Expected suggestion in message, got %s", f.Message)
+			}
+		})
+	}
+}
+
+func TestCheckSchemaKeywords_NoSuggestionForUnrelatedKey(t *testing.T) {
+	findings := CheckSchemaKeywords(map[string]any{"type": "object", "foobarbaz": 1})
+	if len(findings) != 1 || findings[0].Suggestion != "" {
+		t.Errorf("Expected a finding without suggestion, got %+v", findings)
+	}
+}
+
+func TestCheckSchemaKeywords_GtsExtensions(t *testing.T) {
+	schema := map[string]any{
+		"$id":          "gts://gts.x.core.events.type.v1~",
+		"$schema":      "http://json-schema.org/draft-07/schema#",
+		"type":         "object",
+		"x-gts-status": "deprecated",
+		"x-vendor":     map[string]any{"anything": true},
+		"properties": map[string]any{
+			"ref":   map[string]any{"type": "string", "x-gts-ref": "gts.x.core.*"},
+			"cast":  map[string]any{"type": "string", "x-gts-cast": "rename"},
+			"typo":  map[string]any{"type": "string", "x-gts-reff": "gts.x.core.*"},
+			"other": map[string]any{"type": "string"},
+		},
+	}
+
+	findings := CheckSchemaKeywords(schema)
+	if len(findings) != 1 {
+		t.Fatalf("Expected only the misspelled extension to be reported, got %+v", findings)
+	}
+	if findings[0].Keyword != "x-gts-reff" || findings[0].Suggestion != "x-gts-ref" {
+		t.Errorf("Unexpected finding: %+v", findings[0])
+	}
+}
+
+func TestCheckSchemaKeywords_UserDataIgnored(t *testing.T) {
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "object",
+		"default": map[string]any{"additionalProperites": true},
+		"examples": []any{
+			map[string]any{"requried": "x", "nested": map[string]any{"tpye": 1}},
+		},
+		"$defs": map[string]any{
+			"requried": map[string]any{"type": "string", "const": map[string]any{"minLenght": 1}},
+		},
+		"properties": map[string]any{
+			// Property names are user data even when they look like keywords
+			"tpye":  map[string]any{"type": "string", "enum": []any{map[string]any{"propeties": 1}}},
+			"items": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"prefixItems": []any{map[string]any{"type": "string"}},
+	}
+
+	if findings := CheckSchemaKeywords(schema); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", findings)
+	}
+}
+
+func TestCheckSchemaKeywords_Draft(t *testing.T) {
+	schema := map[string]any{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"type":        "array",
+		"prefixItems": []any{map[string]any{"type": "string"}},
+		"items":       []any{map[string]any{"type": "string", "maxLenght": 3}},
+	}
+
+	findings := CheckSchemaKeywords(schema)
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Path != "items[0]/maxLenght" || findings[0].Suggestion != "maxLength" {
+		t.Errorf("Unexpected finding: %+v", findings[0])
+	}
+	if findings[1].Keyword != "prefixItems" || !strings.Contains(findings[1].Message, "not defined in draft-07") {
+		t.Errorf("Unexpected finding: %+v", findings[1])
+	}
+
+	// Without $schema every draft's keywords are accepted
+	delete(schema, "$schema")
+	if findings := CheckSchemaKeywords(schema); len(findings) != 1 {
+		t.Errorf("Expected only the typo without $schema, got %+v", findings)
+	}
+}
+
+func TestStrictSchemaKeywords_Store(t *testing.T) {
+	schema := map[string]any{
+		"$id":     "gts://gts.x.test.strict.item.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+		},
+		"additionalProperites": false,
+	}
+	instance := map[string]any{
+		"id":    "gts.x.test.strict.item.v1~x.test._.one.v1.0",
+		"name":  "one",
+		"extra": true,
+	}
+
+	for _, strict := range []bool{false, true} {
+		store := NewGtsStoreWithConfig(nil, &RegistryConfig{StrictSchemaKeywords: strict})
+		registerTestEntities(t, store, schema, instance)
+
+		schemaErr := store.ValidateSchema("gts.x.test.strict.item.v1~")
+		result := store.ValidateInstance("gts.x.test.strict.item.v1~x.test._.one.v1.0")
+		if !strict {
+			if schemaErr != nil || !result.OK {
+				t.Errorf("Expected typo to be ignored without strict checking: %v, %+v", schemaErr, result)
+			}
+			continue
+		}
+
+		if schemaErr == nil || !strings.Contains(schemaErr.Error(), "did you mean 'additionalProperties'") {
+			t.Errorf("Expected strict schema validation to fail, got %v", schemaErr)
+		}
+		if result.OK || !strings.Contains(result.Error, "additionalProperites") {
+			t.Errorf("Expected strict instance validation to fail, got %+v", result)
+		}
+
+		report := store.BuildValidationReport([]string{"gts.x.test.strict.item.v1~"})
+		if len(report.Entries[0].Findings) != 1 || report.Entries[0].Findings[0].Path != "additionalProperites" {
+			t.Errorf("Expected a report finding with path, got %+v", report.Entries[0].Findings)
+		}
+	}
+}
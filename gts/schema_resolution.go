@@ -0,0 +1,257 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaSourceChain names the schema derived from a chained entity ID, the ID up to its last '~',
+// in GtsConfig.SchemaResolutionOrder and ResolutionStep.Source
+const SchemaSourceChain = "chain"
+
+// Reasons a ResolutionStep was skipped
+const (
+	ResolutionSkipInvalidID     = "invalid ID"
+	ResolutionSkipNotRegistered = "not registered"
+	ResolutionSkipNotASchema    = "not a schema"
+)
+
+// ResolutionStep is a source consulted while resolving the schema of an instance
+type ResolutionStep struct {
+	// Source is SchemaSourceChain or the field the value was read from
+	Source string `json:"source"`
+	Value  string `json:"value"`
+	// Skipped is why the value was not used, one of the ResolutionSkip reasons; empty for the
+	// step that resolved the schema
+	Skipped string `json:"skipped,omitempty"`
+	// ResolvedID is the registered schema a value without minor version resolved to (see ResolveSchema)
+	ResolvedID string `json:"resolved_id,omitempty"`
+}
+
+func (step ResolutionStep) String() string {
+	outcome := step.Skipped
+	if outcome == "" {
+		outcome = "used"
+	}
+	if step.ResolvedID != "" {
+		outcome += " as '" + step.ResolvedID + "'"
+	}
+	return fmt.Sprintf("%s '%s' (%s)", step.Source, step.Value, outcome)
+}
+
+// SchemaResolutionError is returned when none of the schema sources of an instance names a
+// registered schema; Trace lists every source consulted. It unwraps to the
+// StoreGtsSchemaNotFoundError of the preferred source.
+type SchemaResolutionError struct {
+	EntityID string
+	Trace    []ResolutionStep
+}
+
+func (e *SchemaResolutionError) Error() string {
+	steps := make([]string, len(e.Trace))
+	for i, step := range e.Trace {
+		steps[i] = step.String()
+	}
+	return fmt.Sprintf("No schema could be resolved for instance '%s': %s", e.EntityID, strings.Join(steps, ", "))
+}
+
+func (e *SchemaResolutionError) Unwrap() error {
+	return &StoreGtsSchemaNotFoundError{EntityID: e.Trace[0].Value}
+}
+
+// schemaResolutionOrder returns the schema sources of instances in resolution order: the sources
+// of SchemaResolutionOrder, then the others in the default order
+func (c *GtsConfig) schemaResolutionOrder() []string {
+	defaults := append([]string{SchemaSourceChain}, c.schemaIDFieldOrder()...)
+	if len(c.SchemaResolutionOrder) == 0 {
+		return defaults
+	}
+	order := make([]string, 0, len(c.SchemaResolutionOrder)+len(defaults))
+	listed := make(map[string]bool, len(order))
+	for _, source := range slices.Concat(c.SchemaResolutionOrder, defaults) {
+		if !listed[source] {
+			order = append(order, source)
+			listed[source] = true
+		}
+	}
+	return order
+}
+
+// calcSchemaSources returns the schema sources of an instance holding a value, in resolution
+// order. A newer minor version preferred over the precedence order (see SchemaIDAdjustment) is
+// consulted first.
+func (e *JsonEntity) calcSchemaSources(cfg *GtsConfig, entityIDValue string) []SchemaIDCandidate {
+	var sources []SchemaIDCandidate
+	for _, source := range cfg.schemaResolutionOrder() {
+		if source == SchemaSourceChain {
+			if gtsID, err := NewGtsID(entityIDValue); err == nil && !gtsID.IsType() {
+				if parent, err := gtsID.Parent(); err == nil {
+					sources = append(sources, SchemaIDCandidate{Field: SchemaSourceChain, Value: parent.ID})
+				}
+			}
+		} else if value := e.getFieldValue(source); value != "" {
+			sources = append(sources, SchemaIDCandidate{Field: source, Value: value})
+		}
+	}
+	if e.SchemaIDAdjustment != "" {
+		for i, source := range sources {
+			if source.Field == e.SelectedSchemaIDField {
+				sources = append([]SchemaIDCandidate{source}, append(sources[:i:i], sources[i+1:]...)...)
+				break
+			}
+		}
+	}
+	return sources
+}
+
+// resolveInstanceSchema resolves the schema of an instance: its schema sources (see
+// GtsConfig.SchemaResolutionOrder) are consulted in order and the first naming a registered
+// schema is used. The trace records every source consulted and why it was skipped.
+func (s *GtsStore) resolveInstanceSchema(entity *JsonEntity) (string, []ResolutionStep, error) {
+	return resolveInstanceSchemaWith(entity, s.lookupSchema)
+}
+
+// resolveInstanceSchemaLocked is resolveInstanceSchema for callers holding s.mu; only the
+// registered entities are consulted
+func (s *GtsStore) resolveInstanceSchemaLocked(entity *JsonEntity) (string, []ResolutionStep, error) {
+	return resolveInstanceSchemaWith(entity, s.lookupSchemaLocked)
+}
+
+// ResolveSchema returns the schema registered under schemaID. When there is none and the last
+// segment of schemaID has no minor version, e.g. "gts.x.core.events.type.v1~", the registered
+// schema with the same preceding segments, vendor, package, namespace, type and major version
+// and the largest minor version is returned instead. Otherwise it fails with
+// StoreGtsSchemaNotFoundError.
+func (s *GtsStore) ResolveSchema(schemaID string) (*JsonEntity, error) {
+	schema := s.lookupSchema(schemaID)
+	if schema == nil {
+		return nil, &StoreGtsSchemaNotFoundError{EntityID: schemaID}
+	}
+	if !schema.IsSchema {
+		return nil, fmt.Errorf("entity '%s' is not a schema", schemaID)
+	}
+	return schema, nil
+}
+
+// lookupSchema returns the entity registered under id, or the latest minor version of id as
+// ResolveSchema does, or nil
+func (s *GtsStore) lookupSchema(id string) *JsonEntity {
+	if entity := s.Get(id); entity != nil {
+		return entity
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latestMinorLocked(id)
+}
+
+// lookupSchemaLocked is lookupSchema for callers holding s.mu
+func (s *GtsStore) lookupSchemaLocked(id string) *JsonEntity {
+	if entity, ok := s.byID[id]; ok {
+		return entity
+	}
+	return s.latestMinorLocked(id)
+}
+
+// latestMinorLocked returns the registered schema with the largest minor version of a schema ID
+// whose last segment has no minor version, or nil; s.mu must be held
+func (s *GtsStore) latestMinorLocked(id string) *JsonEntity {
+	if !strings.HasSuffix(id, "~") {
+		return nil
+	}
+	requested, err := NewGtsID(id)
+	if err != nil {
+		return nil
+	}
+	if last := requested.LastSegment(); last.VerMinor != nil || last.IsWildcard {
+		return nil
+	}
+
+	var latest *JsonEntity
+	latestMinor := -1
+	for _, entity := range s.byID {
+		if !entity.IsSchema || !isMinorVersionOf(entity.GtsID, requested) {
+			continue
+		}
+		if minor := *entity.GtsID.LastSegment().VerMinor; minor > latestMinor {
+			latest, latestMinor = entity, minor
+		}
+	}
+	return latest
+}
+
+// isMinorVersionOf reports whether candidate is requested with a minor version set on its last
+// segment, requested having none: the preceding segments are identical and the last segment names
+// the same vendor, package, namespace, type and major version
+func isMinorVersionOf(candidate, requested *GtsID) bool {
+	n := len(requested.Segments)
+	if candidate == nil || len(candidate.Segments) != n || strings.HasSuffix(candidate.ID, "~") != strings.HasSuffix(requested.ID, "~") {
+		return false
+	}
+	prefix := GtsPrefix
+	if n > 1 {
+		prefix = requested.SegmentPrefixIDs()[n-2]
+	}
+	if !strings.HasPrefix(candidate.ID, prefix) {
+		return false
+	}
+	seg, last := candidate.LastSegment(), requested.LastSegment()
+	return seg.VerMinor != nil && last.VerMinor == nil && seg.Vendor == last.Vendor && seg.Package == last.Package &&
+		seg.Namespace == last.Namespace && seg.Type == last.Type && seg.VerMajor == last.VerMajor
+}
+
+// registered returns the registered entity with the ID, without consulting the reader
+func (s *GtsStore) registered(id string) *JsonEntity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.byID[id]
+}
+
+// resolveInstanceSchemaWith resolves the schema of an instance looking schemas up with lookup
+func resolveInstanceSchemaWith(entity *JsonEntity, lookup func(id string) *JsonEntity) (string, []ResolutionStep, error) {
+	entityID := entity.Label
+	if entity.GtsID != nil {
+		entityID = entity.GtsID.ID
+	}
+
+	// A schema ID set after extraction, which is none of the sources, takes precedence
+	sources := entity.schemaSources
+	if entity.SchemaID != "" && !slices.ContainsFunc(sources, func(c SchemaIDCandidate) bool { return c.Value == entity.SchemaID }) {
+		field := entity.SelectedSchemaIDField
+		if field == "" {
+			field = "SchemaID"
+		}
+		sources = append([]SchemaIDCandidate{{Field: field, Value: entity.SchemaID}}, sources...)
+	}
+	if len(sources) == 0 {
+		return "", nil, &StoreGtsSchemaForInstanceNotFoundError{EntityID: entityID}
+	}
+
+	trace := make([]ResolutionStep, 0, len(sources))
+	for _, source := range sources {
+		step := ResolutionStep{Source: source.Field, Value: source.Value}
+		id := strings.TrimPrefix(source.Value, GtsURIPrefix)
+		var schema *JsonEntity
+		if !IsValidGtsID(id) || !strings.HasSuffix(id, "~") {
+			step.Skipped = ResolutionSkipInvalidID
+		} else if schema = lookup(id); schema == nil {
+			step.Skipped = ResolutionSkipNotRegistered
+		} else if !schema.IsSchema {
+			step.Skipped = ResolutionSkipNotASchema
+		}
+		if step.Skipped == "" && schema.GtsID != nil && schema.GtsID.ID != id {
+			step.ResolvedID = schema.GtsID.ID
+			id = step.ResolvedID
+		}
+		trace = append(trace, step)
+		if step.Skipped == "" {
+			return id, trace, nil
+		}
+	}
+	return "", trace, &SchemaResolutionError{EntityID: entityID, Trace: trace}
+}
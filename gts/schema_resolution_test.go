@@ -0,0 +1,264 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	resolutionSchemaA  = "gts.x.test.res.a.v1~"
+	resolutionSchemaB  = "gts.x.test.res.b.v1~"
+	resolutionMissing  = "gts.x.test.res.missing.v1~"
+	resolutionInstance = resolutionSchemaA + "x.test._.existing.v1"
+)
+
+// resolutionTestEntities are two schemas and an instance of the first
+func resolutionTestEntities() []map[string]any {
+	return []map[string]any{
+		testSchema(resolutionSchemaA, nil),
+		testSchema(resolutionSchemaB, nil),
+		{"id": resolutionInstance},
+	}
+}
+
+func TestResolveInstanceSchema(t *testing.T) {
+	store := newTestStore(t, nil, resolutionTestEntities()...)
+	typeFirst := DefaultGtsConfig()
+	typeFirst.SchemaResolutionOrder = []string{"type"}
+
+	tests := []struct {
+		name     string
+		content  map[string]any
+		cfg      *GtsConfig
+		expected string
+		trace    []ResolutionStep
+	}{
+		{
+			name:     "ChainOnly",
+			content:  map[string]any{"id": resolutionSchemaA + "x.test._.one.v1"},
+			expected: resolutionSchemaA,
+			trace:    []ResolutionStep{{Source: SchemaSourceChain, Value: resolutionSchemaA}},
+		},
+		{
+			name:     "TypeOnly",
+			content:  map[string]any{"id": "7a1d2f3e-0000-4000-8000-000000000001", "type": resolutionSchemaB},
+			expected: resolutionSchemaB,
+			trace:    []ResolutionStep{{Source: "type", Value: resolutionSchemaB}},
+		},
+		{
+			name:     "ChainPrecedesConflictingType",
+			content:  map[string]any{"id": resolutionSchemaA + "x.test._.one.v1", "type": resolutionSchemaB},
+			expected: resolutionSchemaA,
+			trace:    []ResolutionStep{{Source: SchemaSourceChain, Value: resolutionSchemaA}},
+		},
+		{
+			name:     "UnregisteredChainFallsBackToType",
+			content:  map[string]any{"id": resolutionMissing + "x.test._.one.v1", "type": resolutionSchemaB},
+			expected: resolutionSchemaB,
+			trace: []ResolutionStep{
+				{Source: SchemaSourceChain, Value: resolutionMissing, Skipped: ResolutionSkipNotRegistered},
+				{Source: "type", Value: resolutionSchemaB},
+			},
+		},
+		{
+			name:     "FieldPrecedence",
+			content:  map[string]any{"id": "7a1d2f3e-0000-4000-8000-000000000001", "gtsType": resolutionMissing, "type": resolutionSchemaB},
+			expected: resolutionSchemaB,
+			trace: []ResolutionStep{
+				{Source: "gtsType", Value: resolutionMissing, Skipped: ResolutionSkipNotRegistered},
+				{Source: "type", Value: resolutionSchemaB},
+			},
+		},
+		{
+			name:     "ConfiguredOrderPrefersType",
+			content:  map[string]any{"id": resolutionSchemaA + "x.test._.one.v1", "type": resolutionSchemaB},
+			cfg:      typeFirst,
+			expected: resolutionSchemaB,
+			trace:    []ResolutionStep{{Source: "type", Value: resolutionSchemaB}},
+		},
+		{
+			name:     "InstanceIDSkipped",
+			content:  map[string]any{"id": resolutionSchemaA + "x.test._.one.v1", "type": resolutionInstance},
+			cfg:      typeFirst,
+			expected: resolutionSchemaA,
+			trace: []ResolutionStep{
+				{Source: "type", Value: resolutionInstance, Skipped: ResolutionSkipInvalidID},
+				{Source: SchemaSourceChain, Value: resolutionSchemaA},
+			},
+		},
+		{
+			name:     "AllSkipped",
+			content:  map[string]any{"id": resolutionMissing + "x.test._.one.v1", "type": "object"},
+			expected: "",
+			trace: []ResolutionStep{
+				{Source: SchemaSourceChain, Value: resolutionMissing, Skipped: ResolutionSkipNotRegistered},
+				{Source: "type", Value: "object", Skipped: ResolutionSkipInvalidID},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg == nil {
+				cfg = DefaultGtsConfig()
+			}
+			schemaID, trace, err := store.resolveInstanceSchema(NewJsonEntity(tt.content, cfg))
+			if schemaID != tt.expected {
+				t.Errorf("Expected schema %q, got %q", tt.expected, schemaID)
+			}
+			if !reflect.DeepEqual(trace, tt.trace) {
+				t.Errorf("Expected trace %v, got %v", tt.trace, trace)
+			}
+			if tt.expected == "" {
+				var resolutionErr *SchemaResolutionError
+				if !errors.As(err, &resolutionErr) || !reflect.DeepEqual(resolutionErr.Trace, tt.trace) {
+					t.Errorf("Expected a SchemaResolutionError carrying the trace, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveInstanceSchema_WrongKind(t *testing.T) {
+	store := newTestStore(t, nil, resolutionTestEntities()...)
+	// A schema-ID field naming a registered entity that is not a schema
+	fake := NewJsonEntity(map[string]any{"gtsId": "gts.x.test.res.fake.v1~"}, DefaultGtsConfig())
+	fake.IsSchema = false
+	if err := store.Register(fake); err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+	entity := NewJsonEntity(map[string]any{"id": "7a1d2f3e-0000-4000-8000-000000000001", "gtsType": "gts.x.test.res.fake.v1~", "type": resolutionSchemaB}, DefaultGtsConfig())
+
+	schemaID, trace, err := store.resolveInstanceSchema(entity)
+	if err != nil || schemaID != resolutionSchemaB {
+		t.Fatalf("Expected %s, got %q, %v", resolutionSchemaB, schemaID, err)
+	}
+	expected := []ResolutionStep{
+		{Source: "gtsType", Value: "gts.x.test.res.fake.v1~", Skipped: ResolutionSkipNotASchema},
+		{Source: "type", Value: resolutionSchemaB},
+	}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Errorf("Expected trace %v, got %v", expected, trace)
+	}
+}
+
+func TestResolveInstanceSchema_NoSources(t *testing.T) {
+	store := newTestStore(t, nil, resolutionTestEntities()...)
+	_, trace, err := store.resolveInstanceSchema(NewJsonEntity(map[string]any{"id": "7a1d2f3e-0000-4000-8000-000000000001"}, DefaultGtsConfig()))
+	var notFound *StoreGtsSchemaForInstanceNotFoundError
+	if !errors.As(err, &notFound) || trace != nil {
+		t.Errorf("Expected StoreGtsSchemaForInstanceNotFoundError without a trace, got %v %v", err, trace)
+	}
+}
+
+func TestValidateInstance_SchemaResolution(t *testing.T) {
+	store := newTestStore(t, nil, resolutionTestEntities()...)
+	fallback := resolutionMissing + "x.test._.fallback.v1"
+	broken := resolutionMissing + "x.test._.broken.v1"
+	for _, content := range []map[string]any{
+		{"id": fallback, "type": resolutionSchemaB},
+		{"id": broken, "type": "object"},
+	} {
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register instance: %v", err)
+		}
+	}
+
+	result := store.ValidateInstance(fallback)
+	if !result.OK || result.SchemaID != resolutionSchemaB || len(result.SchemaResolution) != 2 {
+		t.Fatalf("Expected validation against %s after the unregistered chain, got %+v", resolutionSchemaB, result)
+	}
+
+	result = store.ValidateInstance(broken)
+	if result.OK || len(result.SchemaResolution) != 2 {
+		t.Fatalf("Expected a failure with the full trace, got %+v", result)
+	}
+	var notFound *StoreGtsSchemaNotFoundError
+	if !errors.As(result.Err, &notFound) || notFound.EntityID != resolutionMissing {
+		t.Errorf("Expected the error to unwrap to StoreGtsSchemaNotFoundError for %s, got %v", resolutionMissing, result.Err)
+	}
+	if !strings.Contains(result.Error, "chain '"+resolutionMissing+"' (not registered)") || !strings.Contains(result.Error, "type 'object' (invalid ID)") {
+		t.Errorf("Expected the error to list the sources consulted, got %s", result.Error)
+	}
+
+	if err := store.ValidateInstanceWithXGtsRef(fallback); err != nil {
+		t.Errorf("Expected x-gts-ref validation to use the resolved schema, got %v", err)
+	}
+	cast, err := store.Cast(fallback, resolutionSchemaB)
+	if err != nil || len(cast.SchemaResolution) != 2 {
+		t.Errorf("Expected the cast to report the resolution, got %v %v", cast, err)
+	}
+}
+
+func TestResolveSchema_LatestMinor(t *testing.T) {
+	store := NewGtsStore(nil)
+	for _, id := range []string{
+		"gts.x.core.events.type.v1.0~", "gts.x.core.events.type.v1.2~", "gts.x.core.events.type.v1.1~",
+		"gts.x.core.events.type.v2.5~", "gts.x.core.events.other.v1.9~",
+	} {
+		schema := map[string]any{
+			"$id":        GtsURIPrefix + id,
+			"$schema":    "http://json-schema.org/draft-07/schema#",
+			"type":       "object",
+			"required":   []any{"name"},
+			"properties": map[string]any{"name": map[string]any{"type": "string"}},
+		}
+		if err := store.RegisterSchema(id, schema); err != nil {
+			t.Fatalf("Failed to register schema %s: %v", id, err)
+		}
+	}
+
+	schema, err := store.ResolveSchema("gts.x.core.events.type.v1~")
+	if err != nil {
+		t.Fatalf("ResolveSchema: %v", err)
+	}
+	if schema.GtsID.ID != "gts.x.core.events.type.v1.2~" {
+		t.Errorf("Expected latest minor v1.2, got %s", schema.GtsID.ID)
+	}
+
+	// An exact match is not replaced by a newer minor version
+	if schema, err = store.ResolveSchema("gts.x.core.events.type.v1.1~"); err != nil || schema.GtsID.ID != "gts.x.core.events.type.v1.1~" {
+		t.Errorf("Expected exact match v1.1, got %v (%v)", schema, err)
+	}
+
+	var notFound *StoreGtsSchemaNotFoundError
+	for _, id := range []string{"gts.x.core.events.type.v3~", "gts.x.core.events.type.v1.7~", "gts.x.core.events.missing.v1~"} {
+		if _, err := store.ResolveSchema(id); !errors.As(err, &notFound) {
+			t.Errorf("ResolveSchema(%s): expected StoreGtsSchemaNotFoundError, got %v", id, err)
+		}
+	}
+
+	// Instances naming the major version only validate against the latest minor version
+	instance := NewJsonEntity(map[string]any{
+		"id":   "gts.x.core.events.type.v1~x.app._.created.v1",
+		"name": "created",
+	}, DefaultGtsConfig())
+	if err := store.Register(instance); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+	result := store.ValidateInstance(instance.GtsID.ID)
+	if !result.OK {
+		t.Fatalf("Expected instance to validate, got %s", result.Error)
+	}
+	if result.SchemaID != "gts.x.core.events.type.v1.2~" {
+		t.Errorf("Expected schema v1.2, got %s", result.SchemaID)
+	}
+	if len(result.SchemaResolution) != 1 || result.SchemaResolution[0].ResolvedID != "gts.x.core.events.type.v1.2~" {
+		t.Errorf("Expected the trace to record the resolved minor version, got %v", result.SchemaResolution)
+	}
+
+	graph := store.BuildSchemaGraph(instance.GtsID.ID)
+	if graph.SchemaID == nil || !graph.SchemaID.Resolved || graph.SchemaID.ResolvedID != "gts.x.core.events.type.v1.2~" {
+		t.Errorf("Expected the schema node to resolve to v1.2, got %+v", graph.SchemaID)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"strings"
+)
+
+// ShortIDLength is the length of the short IDs returned by ShortID
+const ShortIDLength = 16
+
+// ShortIDAlphabet is the alphabet of short IDs: lowercase letters and the digits 2-7 (RFC 4648
+// base32, lowercased), safe in URL path segments, DNS labels, Kafka topic names and
+// case-insensitive filenames
+const ShortIDAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+var shortIDEncoding = base32.NewEncoding(ShortIDAlphabet).WithPadding(base32.NoPadding)
+
+// shortIDDigest hashes a canonical GTS ID for ShortID; tests replace it to force collisions
+var shortIDDigest = func(id string) []byte {
+	sum := sha256.Sum256([]byte(id))
+	return sum[:]
+}
+
+// ShortIDCollisionError is returned when an entity cannot be registered because its ID has the
+// same short ID as a registered entity with a different ID
+type ShortIDCollisionError struct {
+	ShortID  string
+	ID       string
+	Existing string
+}
+
+func (e *ShortIDCollisionError) Error() string {
+	return fmt.Sprintf("Short ID collision: %s and the registered %s both map to %s", e.ID, e.Existing, e.ShortID)
+}
+
+// ShortID returns a compact, deterministic encoding of a GTS ID for places where full chained IDs
+// are too long, such as URL path segments, topic names and filenames: the first 80 bits of the
+// SHA-256 of the canonical ID (without the gts:// prefix), encoded as ShortIDLength characters of
+// ShortIDAlphabet. Short IDs cannot be decoded; look them up with GtsStore.FindByShortID.
+func ShortID(id string) (string, error) {
+	gid, err := NewGtsID(strings.TrimPrefix(strings.TrimSpace(id), GtsURIPrefix))
+	if err != nil {
+		return "", err
+	}
+	return shortIDOf(gid.ID), nil
+}
+
+// shortIDOf returns the short ID of an ID that is already canonical
+func shortIDOf(id string) string {
+	return shortIDEncoding.EncodeToString(shortIDDigest(id)[:ShortIDLength*5/8])
+}
+
+// IsShortID reports whether s has the shape of a short ID, which no GTS ID has
+func IsShortID(s string) bool {
+	if len(s) != ShortIDLength {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune(ShortIDAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindByShortID returns the registered entity with the given short ID
+func (s *GtsStore) FindByShortID(short string) (*JsonEntity, error) {
+	if !IsShortID(short) {
+		return nil, fmt.Errorf("invalid short ID '%s': expected %d characters of %s", short, ShortIDLength, ShortIDAlphabet)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.shortIDs[short]
+	if !ok {
+		return nil, &StoreGtsObjectNotFoundError{EntityID: short}
+	}
+	return s.byID[id], nil
+}
+
+// checkShortIDLocked returns a ShortIDCollisionError if the short ID of id belongs to another
+// registered ID or, when pending is not nil, to another ID about to be registered with it;
+// s.mu must be held
+func (s *GtsStore) checkShortIDLocked(id string, pending map[string]string) error {
+	short := shortIDOf(id)
+	if existing, ok := s.shortIDs[short]; ok && existing != id {
+		return &ShortIDCollisionError{ShortID: short, ID: id, Existing: existing}
+	}
+	if existing, ok := pending[short]; ok && existing != id {
+		return &ShortIDCollisionError{ShortID: short, ID: id, Existing: existing}
+	}
+	return nil
+}
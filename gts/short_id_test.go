@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShortID_Deterministic(t *testing.T) {
+	short, err := ShortID(tagTestOrderA)
+	if err != nil {
+		t.Fatalf("ShortID failed: %v", err)
+	}
+	if !IsShortID(short) {
+		t.Errorf("Expected %d characters of %s, got %q", ShortIDLength, ShortIDAlphabet, short)
+	}
+
+	again, _ := ShortID(tagTestOrderA)
+	uri, _ := ShortID(GtsURIPrefix + tagTestOrderA)
+	if again != short || uri != short {
+		t.Errorf("Expected the same short ID for the same ID, got %q, %q and %q", short, again, uri)
+	}
+	other, _ := ShortID(tagTestOrderB)
+	if other == short {
+		t.Errorf("Expected different IDs to have different short IDs, both got %q", short)
+	}
+
+	if _, err := ShortID("gts.bad"); err == nil {
+		t.Error("Expected an invalid GTS ID to fail")
+	}
+	if IsShortID(tagTestSchemaID) || IsShortID("ABCDEFGHIJKLMNOP") {
+		t.Error("Expected GTS IDs and uppercase strings not to be short IDs")
+	}
+}
+
+func TestFindByShortID(t *testing.T) {
+	store := newTestStore(t, nil, tagTestEntities()...)
+
+	for _, id := range []string{tagTestSchemaID, tagTestOrderA, tagTestOrderB} {
+		short, _ := ShortID(id)
+		entity, err := store.FindByShortID(short)
+		if err != nil {
+			t.Fatalf("FindByShortID(%s) failed: %v", short, err)
+		}
+		if entity.GtsID.ID != id {
+			t.Errorf("Expected %s, got %s", id, entity.GtsID.ID)
+		}
+	}
+
+	var notFound *StoreGtsObjectNotFoundError
+	if _, err := store.FindByShortID("aaaaaaaaaaaaaaaa"); !errors.As(err, &notFound) {
+		t.Errorf("Expected StoreGtsObjectNotFoundError, got %v", err)
+	}
+	if _, err := store.FindByShortID(tagTestOrderA); err == nil {
+		t.Error("Expected a GTS ID to be rejected as a short ID")
+	}
+
+	short, _ := ShortID(tagTestOrderA)
+	for _, info := range store.List(10).Entities {
+		if info.ID == tagTestOrderA && info.ShortID != short {
+			t.Errorf("Expected List to report short ID %s, got %s", short, info.ShortID)
+		}
+	}
+}
+
+func TestShortID_Collision(t *testing.T) {
+	digest := shortIDDigest
+	shortIDDigest = func(string) []byte { return make([]byte, 32) }
+	defer func() { shortIDDigest = digest }()
+
+	store := NewGtsStore(nil)
+	if err := store.RegisterSchema(tagTestSchemaID, map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	var collision *ShortIDCollisionError
+	if !errors.As(store.Register(NewJsonEntity(map[string]any{"id": tagTestOrderA}, DefaultGtsConfig())), &collision) {
+		t.Fatal("Expected registering a second ID with the same short ID to fail")
+	}
+	if collision.ID != tagTestOrderA || collision.Existing != tagTestSchemaID {
+		t.Errorf("Expected %s to collide with %s, got %+v", tagTestOrderA, tagTestSchemaID, collision)
+	}
+	if store.Get(tagTestOrderA) != nil {
+		t.Error("Expected the colliding entity not to be registered")
+	}
+
+	// Re-registering the same ID is not a collision
+	schema := store.Get(tagTestSchemaID)
+	if err := store.Register(schema); err != nil {
+		t.Errorf("Expected re-registering %s to succeed, got %v", tagTestSchemaID, err)
+	}
+
+	result, err := store.RegisterAll([]*JsonEntity{
+		NewJsonEntity(map[string]any{"id": tagTestOrderB}, DefaultGtsConfig()),
+	}, true)
+	if err == nil || !errors.As(result.Results[0].Err, &collision) {
+		t.Errorf("Expected the atomic batch to be rejected with a collision, got %v", err)
+	}
+}
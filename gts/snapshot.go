@@ -0,0 +1,290 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+)
+
+// Statuses of the entities reported by Import
+const (
+	// ImportAdded means the entity was registered under an ID the store did not hold
+	ImportAdded = "added"
+	// ImportReplaced means the entity replaced the registered entity with the same ID
+	ImportReplaced = "replaced"
+	// ImportSkipped means the registered entity with the same ID was kept
+	ImportSkipped = "skipped"
+	// ImportFailed means the entity could not be registered
+	ImportFailed = "failed"
+)
+
+// ImportOptions configures Import
+type ImportOptions struct {
+	// Conflict decides what happens to bundle entities whose ID is already registered:
+	// ConflictKeepExisting skips them, ConflictOverwrite replaces the registered entities and
+	// ConflictFail reports them as failed unless their content is identical, in which case they
+	// are skipped. ConflictKeepNewer is not supported, as bundles carry no timestamps.
+	Conflict ConflictPolicy
+	// Config is the configuration used to extract the IDs of the bundle entities (default: DefaultGtsConfig)
+	Config *GtsConfig
+}
+
+// ImportEntityResult is the outcome of importing one entity of a bundle
+type ImportEntityResult struct {
+	// Index is the position of the entity in the bundle
+	Index  int    `json:"index"`
+	ID     string `json:"gts_id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Err is the error behind Error, for callers inspecting its type
+	Err error `json:"-"`
+}
+
+// ImportResult reports the import of a bundle
+type ImportResult struct {
+	Policy   string               `json:"policy"`
+	Total    int                  `json:"total"`
+	Added    int                  `json:"added"`
+	Replaced int                  `json:"replaced"`
+	Skipped  int                  `json:"skipped"`
+	Failed   int                  `json:"failed"`
+	Results  []ImportEntityResult `json:"results"`
+}
+
+// bundleDocument is the bundle written by Export: the entity contents in import order and the
+// tags of the tagged entities by ID
+type bundleDocument struct {
+	Entities []map[string]any             `json:"entities"`
+	Tags     map[string]map[string]string `json:"tags,omitempty"`
+}
+
+// InvalidBundleError is returned by Import when the bundle is neither a bundle object nor a JSON
+// array of objects
+type InvalidBundleError struct {
+	Err error
+}
+
+func (e *InvalidBundleError) Error() string {
+	return fmt.Sprintf("Invalid bundle: %v", e.Err)
+}
+
+func (e *InvalidBundleError) Unwrap() error {
+	return e.Err
+}
+
+// ImportConflictError is reported for a bundle entity imported with ConflictFail whose ID is
+// registered with different content
+type ImportConflictError struct {
+	EntityID string
+}
+
+func (e *ImportConflictError) Error() string {
+	return fmt.Sprintf("Import conflict: %s is already registered with different content", e.EntityID)
+}
+
+// Export writes every entity of the store to w as a bundle that Import reads back: a JSON object
+// whose "entities" array holds the entity contents and whose "tags" object maps the IDs of tagged
+// entities to their tags. Entities are ordered so that each one follows the registered entities it
+// references, schemas first, so that importing the bundle into a store validating references
+// succeeds; entities are otherwise sorted by ID, so exporting the same store twice writes the same
+// bundle. Timestamps are not part of the bundle.
+func (s *GtsStore) Export(w io.Writer) error {
+	s.mu.RLock()
+	entities := s.exportOrderLocked()
+	bundle := bundleDocument{Entities: make([]map[string]any, len(entities))}
+	for i, entity := range entities {
+		bundle.Entities[i] = entity.Content
+		if tags := copyTags(s.tags[entity.GtsID.ID]); tags != nil {
+			if bundle.Tags == nil {
+				bundle.Tags = make(map[string]map[string]string)
+			}
+			bundle.Tags[entity.GtsID.ID] = tags
+		}
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// exportOrderLocked returns the entities of the store, schemas first, with every entity after the
+// registered entities it references (its schema, GtsRefs and $ref targets); reference cycles are
+// broken by ID order. s.mu must be held.
+func (s *GtsStore) exportOrderLocked() []*JsonEntity {
+	roots := make([]*JsonEntity, 0, len(s.byID))
+	for _, entity := range s.byID {
+		roots = append(roots, entity)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].IsSchema != roots[j].IsSchema {
+			return roots[i].IsSchema
+		}
+		return roots[i].GtsID.ID < roots[j].GtsID.ID
+	})
+
+	ordered := make([]*JsonEntity, 0, len(roots))
+	visited := make(map[string]bool, len(roots))
+	var visit func(entity *JsonEntity)
+	visit = func(entity *JsonEntity) {
+		if visited[entity.GtsID.ID] {
+			return
+		}
+		visited[entity.GtsID.ID] = true
+
+		deps := make([]string, 0, len(entity.GtsRefs)+1)
+		if entity.SchemaID != "" {
+			deps = append(deps, entity.SchemaID)
+		}
+		for _, ref := range entity.GtsRefs {
+			deps = append(deps, ref.ID)
+		}
+		if entity.IsSchema {
+			deps = append(deps, collectSchemaRefs(entity.Content)...)
+		}
+		sort.Strings(deps)
+		for _, id := range deps {
+			if dep, ok := s.byID[id]; ok {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, entity)
+	}
+	for _, entity := range roots {
+		visit(entity)
+	}
+	return ordered
+}
+
+// Import reads a bundle written by Export from r, or a plain JSON array of entity contents, and
+// registers its entities in bundle order with Register, so they go through the store's reference
+// validation. IDs already registered are handled according to opts.Conflict. Added and replaced
+// entities get the tags the bundle holds for them; skipped entities keep their tags. A failing entity is
+// reported in its result without stopping the others; Import only returns an error, registering
+// nothing, when the bundle cannot be read (InvalidBundleError), the policy is not supported or the
+// store is frozen.
+func (s *GtsStore) Import(r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if opts.Conflict == ConflictKeepNewer {
+		return nil, fmt.Errorf("conflict policy '%s' is not supported by Import", opts.Conflict)
+	}
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = DefaultGtsConfig()
+	}
+
+	bundle, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+	contents := bundle.Entities
+	if s.IsFrozen() {
+		return nil, &StoreFrozenError{Operation: "import"}
+	}
+
+	result := &ImportResult{Policy: opts.Conflict.String(), Total: len(contents), Results: make([]ImportEntityResult, len(contents))}
+	for i, content := range contents {
+		entry := &result.Results[i]
+		entry.Index = i
+		status, err := s.importEntity(NewJsonEntity(content, cfg), opts.Conflict, bundle.Tags, entry)
+		if err != nil {
+			entry.Status, entry.Err, entry.Error = ImportFailed, err, err.Error()
+			result.Failed++
+			continue
+		}
+		entry.Status = status
+		switch status {
+		case ImportAdded:
+			result.Added++
+		case ImportReplaced:
+			result.Replaced++
+		case ImportSkipped:
+			result.Skipped++
+		}
+	}
+
+	log.Printf("Imported bundle of %d entities: %d added, %d replaced, %d skipped, %d failed",
+		result.Total, result.Added, result.Replaced, result.Skipped, result.Failed)
+	return result, nil
+}
+
+// readBundle decodes a bundle object, or a JSON array of entity contents as a bundle without tags
+func readBundle(r io.Reader) (*bundleDocument, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, &InvalidBundleError{Err: err}
+	}
+	bundle := &bundleDocument{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &bundle.Entities); err != nil {
+			return nil, &InvalidBundleError{Err: err}
+		}
+		return bundle, nil
+	}
+	if err := json.Unmarshal(raw, bundle); err != nil {
+		return nil, &InvalidBundleError{Err: err}
+	}
+	if bundle.Entities == nil {
+		return nil, &InvalidBundleError{Err: errors.New("expected an object with an entities array or a JSON array of entities")}
+	}
+	return bundle, nil
+}
+
+// importEntity registers one bundle entity according to policy with its bundle tags, setting the
+// ID of its result
+func (s *GtsStore) importEntity(entity *JsonEntity, policy ConflictPolicy, tags map[string]map[string]string, entry *ImportEntityResult) (string, error) {
+	if entity.GtsID == nil {
+		if entity.IDError != nil {
+			return "", entity.IDError
+		}
+		return "", fmt.Errorf("entity must have a valid gts_id")
+	}
+	id := entity.GtsID.ID
+	entry.ID = id
+
+	s.mu.RLock()
+	existing, exists := s.byID[id]
+	s.mu.RUnlock()
+	status := ImportAdded
+	if exists {
+		switch policy {
+		case ConflictOverwrite:
+			status = ImportReplaced
+		case ConflictFail:
+			if !reflect.DeepEqual(existing.Content, entity.Content) {
+				return "", &ImportConflictError{EntityID: id}
+			}
+			return ImportSkipped, nil
+		default:
+			return ImportSkipped, nil
+		}
+	}
+
+	// Check the tag keys first, so that an entity is not registered without its tags
+	for key := range tags[id] {
+		if err := validateTagKey(key); err != nil {
+			return "", err
+		}
+	}
+	if err := s.Register(entity); err != nil {
+		return "", err
+	}
+	if len(tags[id]) > 0 {
+		if err := s.SetTags(id, tags[id]); err != nil {
+			return "", err
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// snapshotTestEntities are a schema referencing a schema sorting after it, and an instance
+func snapshotTestEntities() []map[string]any {
+	return []map[string]any{
+		testSchema("gts.x.test.snap.zone.v1~", nil),
+		testSchema("gts.x.test.snap.area.v1~", map[string]any{
+			"properties": map[string]any{
+				"zone": map[string]any{"$ref": "gts://gts.x.test.snap.zone.v1~"},
+			},
+		}),
+		{"id": "gts.x.test.snap.area.v1~x.test._.north.v1", "zone": map[string]any{"name": "n"}},
+	}
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	store := newTestStore(t, nil, snapshotTestEntities()...)
+	tags := map[string]string{"owner": "geo-team"}
+	if err := store.SetTags("gts.x.test.snap.area.v1~x.test._.north.v1", tags); err != nil {
+		t.Fatalf("Failed to tag entity: %v", err)
+	}
+	var bundle bytes.Buffer
+	if err := store.Export(&bundle); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if zone, area := strings.Index(bundle.String(), "snap.zone.v1~\""), strings.Index(bundle.String(), "snap.area.v1~\""); zone > area {
+		t.Errorf("Expected the referenced schema to be exported first:\n%s", bundle.String())
+	}
+
+	imported := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+	result, err := imported.Import(bytes.NewReader(bundle.Bytes()), ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Added != 3 || result.Failed != 0 {
+		t.Fatalf("Expected 3 added entities, got %+v", result)
+	}
+	if imported.Count() != store.Count() {
+		t.Errorf("Expected %d entities, got %d", store.Count(), imported.Count())
+	}
+	for _, item := range store.List(10).Entities {
+		original := store.Get(item.ID)
+		copied := imported.Get(item.ID)
+		if copied == nil || copied.ContentHash != original.ContentHash {
+			t.Errorf("Expected %s to be imported with hash %s, got %v", item.ID, original.ContentHash, copied)
+		}
+		if !reflect.DeepEqual(imported.GetTags(item.ID), store.GetTags(item.ID)) {
+			t.Errorf("Expected %s to be imported with tags %v, got %v", item.ID, store.GetTags(item.ID), imported.GetTags(item.ID))
+		}
+	}
+
+	// A plain array of entity contents is still accepted
+	legacy := `[{"$id": "gts://gts.x.test.snap.zone.v1~", "type": "object"}]`
+	if result, err := NewGtsStore(nil).Import(strings.NewReader(legacy), ImportOptions{}); err != nil || result.Added != 1 {
+		t.Errorf("Expected the array bundle to be imported, got %+v, %v", result, err)
+	}
+}
+
+func TestImport_ConflictPolicies(t *testing.T) {
+	const id = "gts.x.test.snap.area.v1~x.test._.north.v1"
+	bundle := `[{"id": "` + id + `", "zone": {"name": "changed"}}, {"id": "gts.x.test.snap.area.v1~x.test._.south.v1"}, {"name": "no id"}]`
+
+	tests := []struct {
+		policy ConflictPolicy
+		status string
+		zone   string
+	}{
+		{ConflictKeepExisting, ImportSkipped, "n"},
+		{ConflictOverwrite, ImportReplaced, "changed"},
+		{ConflictFail, ImportFailed, "n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			store := newTestStore(t, nil, snapshotTestEntities()...)
+			result, err := store.Import(strings.NewReader(bundle), ImportOptions{Conflict: tt.policy})
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+			if result.Results[0].Status != tt.status || result.Results[1].Status != ImportAdded || result.Results[2].Status != ImportFailed {
+				t.Errorf("Unexpected results: %+v", result.Results)
+			}
+			var conflictErr *ImportConflictError
+			if tt.policy == ConflictFail && !errors.As(result.Results[0].Err, &conflictErr) {
+				t.Errorf("Expected an ImportConflictError, got %v", result.Results[0].Err)
+			}
+			entity := store.Get(id)
+			if zone := entity.Content["zone"].(map[string]any)["name"]; zone != tt.zone {
+				t.Errorf("Expected zone %q, got %v", tt.zone, zone)
+			}
+		})
+	}
+
+	store := newTestStore(t, nil, snapshotTestEntities()...)
+	var invalidErr *InvalidBundleError
+	if _, err := store.Import(strings.NewReader(`{"id": "x"}`), ImportOptions{}); !errors.As(err, &invalidErr) {
+		t.Errorf("Expected an InvalidBundleError, got %v", err)
+	}
+	if _, err := store.Import(strings.NewReader(bundle), ImportOptions{Conflict: ConflictKeepNewer}); err == nil {
+		t.Errorf("Expected keep-newer to be refused")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StoreStats summarizes the entities of a store (see GtsStore.Stats)
+type StoreStats struct {
+	Entities  int `json:"entities"`
+	Schemas   int `json:"schemas"`
+	Instances int `json:"instances"`
+	// OrphanInstances counts the instances without schema ID or whose schema is not registered
+	// (see ListOptions.Orphans)
+	OrphanInstances int `json:"orphan_instances"`
+	// SchemasWithMissingRefs counts the schemas referencing entities that are not registered, and
+	// MissingRefs the references of any entity to entities that are not registered
+	SchemasWithMissingRefs int `json:"schemas_with_missing_refs"`
+	MissingRefs            int `json:"missing_refs"`
+	// DuplicateRegistrations counts the IDs loaded from more than one source file, the last
+	// loaded replacing the others
+	DuplicateRegistrations int `json:"duplicate_registrations"`
+	// Vendors and Packages count the entities per vendor and per vendor.package of their first segment
+	Vendors  map[string]int `json:"vendors"`
+	Packages map[string]int `json:"packages"`
+}
+
+// Kinds of IntegrityProblem
+const (
+	IntegrityOrphanInstance        = "orphan_instance"
+	IntegrityMissingReference      = "missing_reference"
+	IntegrityDuplicateRegistration = "duplicate_registration"
+)
+
+// IntegrityProblem is a problem of a registered entity found by IntegrityCheck
+type IntegrityProblem struct {
+	// Kind is one of the Integrity constants
+	Kind     string `json:"kind"`
+	EntityID string `json:"entity_id"`
+	// Source is the label of the entity, its file name and list index when it was read from a
+	// file, and File the path of that file
+	Source string `json:"source"`
+	File   string `json:"file,omitempty"`
+	// Target is the missing schema of an orphan instance, empty when it has no schema ID, or the
+	// missing entity of a reference, and Path the location of the reference in the entity
+	Target string `json:"target,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Sources lists the files an ID was loaded from, in load order, for duplicate registrations
+	Sources []string `json:"sources,omitempty"`
+	Message string   `json:"message"`
+}
+
+// IntegrityReport lists the problems of the entities of a store, in entity ID order
+type IntegrityReport struct {
+	OK       bool               `json:"ok"`
+	Checked  int                `json:"checked"`
+	Problems []IntegrityProblem `json:"problems"`
+	// Counts holds the number of problems per kind
+	Counts map[string]int `json:"counts"`
+}
+
+// Stats summarizes the registered entities: schemas and instances, orphan instances, missing
+// references, duplicate registrations and the distribution of vendors and packages. The counts
+// are those of the problems IntegrityCheck reports.
+func (s *GtsStore) Stats() *StoreStats {
+	stats := &StoreStats{Vendors: map[string]int{}, Packages: map[string]int{}}
+	schemasWithMissing := map[string]bool{}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, entity := range s.byID {
+		stats.Entities++
+		if entity.IsSchema {
+			stats.Schemas++
+		} else {
+			stats.Instances++
+		}
+		first := entity.GtsID.Segments[0]
+		stats.Vendors[first.Vendor]++
+		stats.Packages[first.Vendor+"."+first.Package]++
+	}
+	s.inspectLocked(false, func(entity *JsonEntity, problem IntegrityProblem) {
+		switch problem.Kind {
+		case IntegrityOrphanInstance:
+			stats.OrphanInstances++
+		case IntegrityMissingReference:
+			stats.MissingRefs++
+			if entity.IsSchema {
+				schemasWithMissing[entity.GtsID.ID] = true
+			}
+		case IntegrityDuplicateRegistration:
+			stats.DuplicateRegistrations++
+		}
+	})
+	stats.SchemasWithMissingRefs = len(schemasWithMissing)
+	return stats
+}
+
+// IntegrityCheck lists the problems of the registered entities: instances without schema ID or
+// whose schema is not registered, references to entities that are not registered and IDs loaded from more than one
+// source file. It reads the registered entities and their extracted references without parsing
+// content again, and changes nothing, not even the resolution state of the references.
+func (s *GtsStore) IntegrityCheck() *IntegrityReport {
+	report := &IntegrityReport{Problems: []IntegrityProblem{}, Counts: map[string]int{}}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	report.Checked = len(s.byID)
+	s.inspectLocked(true, func(_ *JsonEntity, problem IntegrityProblem) {
+		report.Problems = append(report.Problems, problem)
+		report.Counts[problem.Kind]++
+	})
+	report.OK = len(report.Problems) == 0
+	return report
+}
+
+// inspectLocked calls report with every integrity problem of the registered entities, in entity
+// ID order when ordered is set; s.mu must be held
+func (s *GtsStore) inspectLocked(ordered bool, report func(*JsonEntity, IntegrityProblem)) {
+	ids := make([]string, 0, len(s.byID))
+	if ordered {
+		ids = s.sortedIDs()
+	} else {
+		for id := range s.byID {
+			ids = append(ids, id)
+		}
+	}
+
+	// Schema IDs without minor version resolve by scanning the store, so resolve each once
+	schemaRegistered := map[string]bool{}
+	for _, id := range ids {
+		entity := s.byID[id]
+		problem := func(kind, message string) IntegrityProblem {
+			p := IntegrityProblem{Kind: kind, EntityID: id, Source: entity.Label, Message: message}
+			if entity.File != nil {
+				p.File = entity.File.Path
+			}
+			return p
+		}
+
+		if sources := s.duplicateSources[id]; len(sources) > 0 && entity.File != nil {
+			p := problem(IntegrityDuplicateRegistration,
+				fmt.Sprintf("%s was loaded from %d files; the last loaded replaced the others", id, len(sources)+1))
+			p.Sources = append(append([]string{}, sources...), entity.File.Path)
+			report(entity, p)
+		}
+
+		if !entity.IsSchema && entity.SchemaID == "" {
+			report(entity, problem(IntegrityOrphanInstance, fmt.Sprintf("instance %s has no schema ID", id)))
+		} else if !entity.IsSchema {
+			registered, ok := schemaRegistered[entity.SchemaID]
+			if !ok {
+				registered = s.lookupSchemaLocked(entity.SchemaID) != nil
+				schemaRegistered[entity.SchemaID] = registered
+			}
+			if !registered {
+				p := problem(IntegrityOrphanInstance, fmt.Sprintf("schema %s of instance %s is not registered", entity.SchemaID, id))
+				p.Target = entity.SchemaID
+				report(entity, p)
+			}
+		}
+
+		for _, ref := range entity.GtsRefs {
+			if ref.ID == id || strings.HasPrefix(ref.ID, "http://json-schema.org") || strings.HasPrefix(ref.ID, "https://json-schema.org") {
+				continue
+			}
+			if _, ok := s.byID[ref.ID]; ok {
+				continue
+			}
+			p := problem(IntegrityMissingReference, fmt.Sprintf("referenced entity not found: %s (at %s)", ref.ID, ref.SourcePath))
+			p.Target, p.Path = ref.ID, ref.SourcePath
+			report(entity, p)
+		}
+	}
+}
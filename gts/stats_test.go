@@ -0,0 +1,144 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeStatsFixtures writes an order schema referencing a missing base, an order, an instance of
+// an unregistered schema and the same setting in two directories, and returns the directories
+func writeStatsFixtures(t *testing.T) []string {
+	t.Helper()
+	first, second := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(first, "order.schema.json"): `{
+			"$id": "gts://gts.x.shop.orders.order.v1.0~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"properties": {"base": {"type": "string", "x-gts-ref": "gts.x.shop.base.entity.v1~"}}
+		}`,
+		filepath.Join(first, "orders.json"): `[
+			{"id": "gts.x.shop.orders.order.v1.0~x.shop._.o1.v1"},
+			{"id": "gts.y.crm.contacts.contact.v1.0~y.crm._.c1.v1"}
+		]`,
+		filepath.Join(first, "setting.json"):  `{"id": "gts.x.shop.orders.order.v1.0~x.shop._.dup.v1"}`,
+		filepath.Join(second, "setting.json"): `{"id": "gts.x.shop.orders.order.v1.0~x.shop._.dup.v1"}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return []string{first, second}
+}
+
+func TestStats(t *testing.T) {
+	store := NewGtsStore(NewGtsFileReader(writeStatsFixtures(t), nil))
+
+	stats := store.Stats()
+	if stats.Entities != 4 || stats.Schemas != 1 || stats.Instances != 3 {
+		t.Errorf("Expected 1 schema and 3 instances, got %+v", stats)
+	}
+	if stats.OrphanInstances != 1 || stats.SchemasWithMissingRefs != 1 || stats.DuplicateRegistrations != 1 {
+		t.Errorf("Expected one orphan, one schema with missing refs and one duplicate, got %+v", stats)
+	}
+	if stats.Vendors["x"] != 3 || stats.Vendors["y"] != 1 || stats.Packages["x.shop"] != 3 || stats.Packages["y.crm"] != 1 {
+		t.Errorf("Unexpected vendor and package distribution: %v %v", stats.Vendors, stats.Packages)
+	}
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	dirs := writeStatsFixtures(t)
+	store := NewGtsStore(NewGtsFileReader(dirs, nil))
+
+	report := store.IntegrityCheck()
+	if report.OK || report.Checked != 4 {
+		t.Fatalf("Expected problems in 4 entities, got %+v", report)
+	}
+	byKind := map[string]IntegrityProblem{}
+	for _, problem := range report.Problems {
+		byKind[problem.Kind] = problem
+	}
+
+	orphan := byKind[IntegrityOrphanInstance]
+	if orphan.EntityID != "gts.y.crm.contacts.contact.v1.0~y.crm._.c1.v1" || orphan.Target != "gts.y.crm.contacts.contact.v1.0~" ||
+		orphan.Source != "orders.json#1" || orphan.File != filepath.Join(dirs[0], "orders.json") {
+		t.Errorf("Unexpected orphan problem: %+v", orphan)
+	}
+	missing := byKind[IntegrityMissingReference]
+	if missing.EntityID != "gts.x.shop.orders.order.v1.0~" || missing.Target != "gts.x.shop.base.entity.v1~" ||
+		missing.Path != "properties.base.x-gts-ref" {
+		t.Errorf("Unexpected missing reference problem: %+v", missing)
+	}
+	duplicate := byKind[IntegrityDuplicateRegistration]
+	if duplicate.EntityID != "gts.x.shop.orders.order.v1.0~x.shop._.dup.v1" || len(duplicate.Sources) != 2 ||
+		duplicate.Sources[0] != filepath.Join(dirs[0], "setting.json") || duplicate.Sources[1] != filepath.Join(dirs[1], "setting.json") {
+		t.Errorf("Unexpected duplicate problem: %+v", duplicate)
+	}
+	if report.Counts[IntegrityOrphanInstance] != 1 || report.Counts[IntegrityDuplicateRegistration] != 1 {
+		t.Errorf("Unexpected counts: %v", report.Counts)
+	}
+
+	// The check leaves the references unresolved as they were
+	for _, ref := range store.Get("gts.x.shop.orders.order.v1.0~").GtsRefs {
+		if ref.Resolved {
+			t.Errorf("Expected the check not to resolve %s", ref.ID)
+		}
+	}
+}
+
+func TestIntegrityCheck_LargeStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the large store check in short mode")
+	}
+	store := NewGtsStore(nil)
+	if err := store.RegisterSchema("gts.x.core.load.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register the schema: %v", err)
+	}
+	for i := 0; i < 100000; i++ {
+		content := map[string]any{"id": fmt.Sprintf("gts.x.core.load.item.v1~x.core._.i%d.v1", i), "peer": "gts.x.core.load.item.v1~"}
+		if err := store.Register(NewJsonEntity(content, nil)); err != nil {
+			t.Fatalf("Failed to register item %d: %v", i, err)
+		}
+	}
+
+	start := time.Now()
+	report := store.IntegrityCheck()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the check to finish in a few seconds, took %v", elapsed)
+	}
+	if !report.OK || report.Checked != 100001 {
+		t.Errorf("Expected a clean report of 100001 entities, got %d problems of %d", len(report.Problems), report.Checked)
+	}
+}
+
+func TestStats_InstanceWithoutSchemaID(t *testing.T) {
+	// An instance provided without schema ID, e.g. by a custom reader, has no schema to validate against
+	store := NewGtsStore(nil)
+	const id = "gts.x.shop.orders.order.v1.0~x.shop._.o1.v1"
+	instance := NewJsonEntity(map[string]any{"id": id}, DefaultGtsConfig())
+	instance.SchemaID = ""
+	if err := store.Register(instance); err != nil {
+		t.Fatalf("Failed to register the instance: %v", err)
+	}
+
+	if stats := store.Stats(); stats.Instances != 1 || stats.OrphanInstances != 1 {
+		t.Errorf("Expected the instance to be counted as an orphan, got %+v", stats)
+	}
+	report := store.IntegrityCheck()
+	if len(report.Problems) != 1 || report.Problems[0].Kind != IntegrityOrphanInstance || report.Problems[0].EntityID != id {
+		t.Errorf("Expected an orphan_instance problem for %s, got %+v", id, report.Problems)
+	}
+	orphans, err := store.ListFiltered(ListOptions{Orphans: true, Limit: 10})
+	if err != nil || orphans.Total != 1 {
+		t.Errorf("Expected the stats to agree with ListOptions.Orphans, got %+v, %v", orphans, err)
+	}
+}
@@ -6,9 +6,14 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // StoreGtsObjectNotFoundError is returned when a GTS entity is not found in the store
@@ -47,10 +52,205 @@ func (e *StoreGtsCastFromSchemaNotAllowedError) Error() string {
 	return fmt.Sprintf("Cannot cast from schema ID '%s'. The from_id must be an instance (not ending with '~').", e.FromID)
 }
 
+// StoreInvalidSchemaTypeIDError is returned by RegisterSchema when the type ID is empty or not a type
+type StoreInvalidSchemaTypeIDError struct {
+	TypeID string
+}
+
+func (e *StoreInvalidSchemaTypeIDError) Error() string {
+	if e.TypeID == "" {
+		return "schema type_id must not be empty"
+	}
+	return fmt.Sprintf("schema type_id must end with '~': '%s'", e.TypeID)
+}
+
+// StoreSchemaIDMismatchError is returned by RegisterSchema when the $id of the schema content names
+// a different type than the type ID it is registered under
+type StoreSchemaIDMismatchError struct {
+	TypeID    string
+	ContentID string
+}
+
+func (e *StoreSchemaIDMismatchError) Error() string {
+	return fmt.Sprintf("schema $id '%s' does not match type_id '%s'", e.ContentID, e.TypeID)
+}
+
+// RefValidationMode controls how unresolved GTS references are handled on registration
+type RefValidationMode int
+
+const (
+	// RefValidationOff skips GTS reference validation on registration
+	RefValidationOff RefValidationMode = iota
+	// RefValidationWarn registers the entity and annotates it with its unresolved references
+	RefValidationWarn
+	// RefValidationStrict rejects entities with unresolved or invalid references
+	RefValidationStrict
+)
+
+// String returns the textual name of the mode
+func (m RefValidationMode) String() string {
+	switch m {
+	case RefValidationWarn:
+		return "warn"
+	case RefValidationStrict:
+		return "strict"
+	default:
+		return "off"
+	}
+}
+
+// ParseRefValidationMode parses "off", "warn" or "strict" into a RefValidationMode
+func ParseRefValidationMode(s string) (RefValidationMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off":
+		return RefValidationOff, nil
+	case "warn":
+		return RefValidationWarn, nil
+	case "strict":
+		return RefValidationStrict, nil
+	default:
+		return RefValidationOff, fmt.Errorf("invalid reference validation mode '%s' (expected off, warn or strict)", s)
+	}
+}
+
+// StoreFrozenError is returned when a mutation is attempted on a frozen store
+type StoreFrozenError struct {
+	Operation string
+}
+
+func (e *StoreFrozenError) Error() string {
+	return fmt.Sprintf("Store is frozen (read-only): %s is not allowed", e.Operation)
+}
+
+// StoreLoadError is returned by NewGtsStoreStrict when the reader reported errors
+type StoreLoadError struct {
+	Errors []ReaderError
+}
+
+func (e *StoreLoadError) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("Failed to load entities: %v", e.Errors[0])
+	}
+	return fmt.Sprintf("Failed to load entities: %d load errors, first: %v", len(e.Errors), e.Errors[0])
+}
+
+// ErrInternal is wrapped by the errors of store operations that recovered from a panic
+var ErrInternal = errors.New("internal error")
+
+// StoreInternalError is returned when a store operation panics; it wraps ErrInternal
+type StoreInternalError struct {
+	Operation string
+	Value     any
+}
+
+func (e *StoreInternalError) Error() string {
+	return fmt.Sprintf("Internal error in %s: %v", e.Operation, e.Value)
+}
+
+func (e *StoreInternalError) Unwrap() error {
+	return ErrInternal
+}
+
+// newStoreInternalError logs a recovered panic with its stack and converts it to an error
+func newStoreInternalError(operation string, value any) *StoreInternalError {
+	log.Printf("ERROR: recovered panic in %s: %v\n%s", operation, value, debug.Stack())
+	return &StoreInternalError{Operation: operation, Value: value}
+}
+
 // RegistryConfig configures the GtsStore behavior
 type RegistryConfig struct {
-	// ValidateGtsReferences enables validation of GTS references on entity registration
+	// ValidateGtsReferences enables strict validation of GTS references on entity registration
+	// Deprecated: use RefValidation; true is equivalent to RefValidationStrict.
 	ValidateGtsReferences bool
+
+	// RefValidation selects how GTS references are validated on entity registration
+	RefValidation RefValidationMode
+
+	// AllowUnfreeze permits Unfreeze to switch a frozen store back to read-write mode
+	AllowUnfreeze bool
+
+	// MaxIDLength and MaxSegments limit the IDs of registered entities;
+	// zero uses the process-wide limits (see SetLimits)
+	MaxIDLength int
+	MaxSegments int
+
+	// StrictSchemaKeywords makes schema and instance validation fail when a schema contains keys
+	// that are neither JSON Schema keywords of its draft nor GTS extensions (see CheckSchemaKeywords)
+	StrictSchemaKeywords bool
+
+	// StableOrder makes listing, queries and other store-wide iterations visit entities in ID order,
+	// so that repeated responses for the same store state are byte-identical. Object keys need no
+	// such option: encoding/json always writes map keys sorted.
+	StableOrder bool
+
+	// RevalidateDependentsOnSchemaChange re-validates the registered instances of a schema when
+	// Register overwrites it with different content, reporting or rejecting breaking changes
+	RevalidateDependentsOnSchemaChange DependentValidationMode
+
+	// RejectSchemaIDConflicts makes Register fail for instances whose schema-ID fields name
+	// different schemas, unless they differ in minor versions only (see JsonEntity.ConflictingSchemaIDs)
+	RejectSchemaIDConflicts bool
+
+	// EnforceIDTypeConsistency makes Register fail for instances declaring a schema ID other than
+	// the type their ID encodes, up to minor versions (see CheckIDTypeConsistency)
+	EnforceIDTypeConsistency bool
+
+	// UUIDVerifyMapLimit is the number of IDs up to which VerifyUUIDIntegrity keeps every UUID in
+	// memory; larger stores are verified with a bloom filter and a confirmation pass.
+	// Zero uses DefaultUUIDVerifyMapLimit.
+	UUIDVerifyMapLimit int
+
+	// ReaderMissPolicy decides whether Get asks the reader again for IDs it did not find;
+	// NegativeCacheTTL and NegativeCacheSize bound the negative cache of ReaderMissNegativeCache,
+	// zero using DefaultNegativeCacheTTL and DefaultNegativeCacheSize
+	ReaderMissPolicy  ReaderMissPolicy
+	NegativeCacheTTL  time.Duration
+	NegativeCacheSize int
+
+	// LenientLookup makes Get retry a missed ID in normalized form (trimmed, lowercased, without the
+	// gts:// prefix) and Query normalize its pattern the same way, so that IDs pasted with stray
+	// capitals are still found. Registration never normalizes IDs.
+	LenientLookup bool
+
+	// XGtsRefValidation selects how ValidateInstance, ValidateEntity and the re-validation of
+	// dependents check the x-gts-ref constraints of instances, declared by their schema or the
+	// schemas it references: by default values must match their pattern and name registered
+	// entities; XGtsRefPatternsOnly skips the registry check and XGtsRefOff the constraints
+	XGtsRefValidation XGtsRefMode
+
+	// IndexUUIDs keeps a UUID → ID index of the registered entities, so GetByUUID is a map lookup
+	// rather than a scan deriving the UUID of every registered ID
+	IndexUUIDs bool
+
+	// GtsConfig is the configuration the IDs of content handed to the store are extracted with,
+	// by CastContent, ApplyDefaultsToContent and RegisterSchema, and by the HTTP server for every
+	// request body; nil uses DefaultGtsConfig. It should be the configuration of the store's reader.
+	GtsConfig *GtsConfig
+}
+
+// idLimits returns the effective ID limits for registered entities
+func (c *RegistryConfig) idLimits() idLimits {
+	return configLimits(&GtsConfig{MaxIDLength: c.MaxIDLength, MaxSegments: c.MaxSegments})
+}
+
+// GtsConfig returns the configuration content handed to the store is parsed with (see
+// RegistryConfig.GtsConfig)
+func (s *GtsStore) GtsConfig() *GtsConfig {
+	if s.config.GtsConfig == nil {
+		return DefaultGtsConfig()
+	}
+	return s.config.GtsConfig
+}
+
+// refValidationMode returns the effective reference validation mode
+func (c *RegistryConfig) refValidationMode() RefValidationMode {
+	if c.RefValidation != RefValidationOff {
+		return c.RefValidation
+	}
+	if c.ValidateGtsReferences {
+		return RefValidationStrict
+	}
+	return RefValidationOff
 }
 
 // DefaultRegistryConfig returns the default registry configuration
@@ -62,9 +262,45 @@ func DefaultRegistryConfig() *RegistryConfig {
 
 // GtsStore manages a collection of JSON entities and schemas with optional GTS reference validation
 type GtsStore struct {
+	// mu guards byID, shortIDs, byUUID, tags, frozen, unresolvedRefs, duplicateSources and loadErrors
+	mu     sync.RWMutex
 	byID   map[string]*JsonEntity
+	tags   map[string]map[string]string
 	reader GtsReader
 	config *RegistryConfig
+
+	// shortIDs maps the short ID of every registered entity to its ID
+	shortIDs map[string]string
+
+	// byUUID maps the UUID of every registered entity to its ID when RegistryConfig.IndexUUIDs is set
+	byUUID map[string]string
+
+	// frozen switches the store into read-only mode
+	frozen bool
+
+	// unresolvedRefs is the total number of unresolved references recorded in warn mode
+	unresolvedRefs int
+
+	// duplicateSources maps the IDs stored from more than one file to the paths of the files
+	// whose entities were replaced, in load order (see IntegrityCheck)
+	duplicateSources map[string][]string
+
+	// loadErrors are the errors reported by the readers the store was populated from (see LoadErrors)
+	loadErrors []ReaderError
+
+	// populated is set once the store has been populated from its reader; misses holds the IDs
+	// the reader did not find and lookups counts the lookups of Get (see ReaderMissPolicy)
+	populated bool
+	misses    *negativeCache
+	lookups   lookupCounters
+
+	// writer persists registered and removed entities when set (see NewGtsStoreWithPersistence)
+	writer GtsWriter
+
+	// allocMu guards reservations made by AllocateInstanceID; when both are needed it is taken before mu
+	allocMu      sync.Mutex
+	reservations map[string]time.Time
+	now          func() time.Time
 }
 
 // NewGtsStore creates a new GtsStore, optionally populating it from a reader
@@ -73,27 +309,59 @@ func NewGtsStore(reader GtsReader) *GtsStore {
 }
 
 // NewGtsStoreWithConfig creates a new GtsStore with custom configuration
+// The references of the entities loaded from reader are validated once they are all loaded, as
+// NewGtsStoreFromReaders does.
 func NewGtsStoreWithConfig(reader GtsReader, config *RegistryConfig) *GtsStore {
 	if config == nil {
 		config = DefaultRegistryConfig()
 	}
 
 	store := &GtsStore{
-		byID:   make(map[string]*JsonEntity),
-		reader: reader,
-		config: config,
+		byID:     make(map[string]*JsonEntity),
+		tags:     make(map[string]map[string]string),
+		reader:   reader,
+		config:   config,
+		shortIDs: make(map[string]string),
+		misses:   newNegativeCache(config.NegativeCacheTTL, config.NegativeCacheSize),
+	}
+	if config.IndexUUIDs {
+		store.byUUID = make(map[string]string)
 	}
 
 	// Populate from reader if provided
 	if reader != nil {
 		store.populateFromReader()
+		store.populated = true
+		store.validateLoadedReferences()
 	}
 
-	log.Printf("Created GtsStore with %d entities (validation: %v)", len(store.byID), config.ValidateGtsReferences)
+	log.Printf("Created GtsStore with %d entities (validation: %v)", len(store.byID), config.refValidationMode())
+	return store
+}
+
+// NewGtsStoreStrict creates a GtsStore like NewGtsStoreWithConfig, but fails with a
+// *StoreLoadError when the reader reports errors (see LoadErrors)
+func NewGtsStoreStrict(reader GtsReader, config *RegistryConfig) (*GtsStore, error) {
+	store := NewGtsStoreWithConfig(reader, config)
+	if err := store.loadError(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewGtsStoreWithPersistence creates a GtsStore like NewGtsStoreWithConfig whose registrations and
+// removals are written through to writer; a change the writer fails to persist is not applied.
+// Entities loaded from the reader are not written back, so a GtsFileReader on the directory of a
+// GtsDirWriter restores the store on startup.
+func NewGtsStoreWithPersistence(reader GtsReader, writer GtsWriter, config *RegistryConfig) *GtsStore {
+	store := NewGtsStoreWithConfig(reader, config)
+	store.writer = writer
 	return store
 }
 
 // populateFromReader loads all entities from the reader into the store
+// The reader is consumed outside the store lock, which is only taken to store each entity,
+// so concurrent lookups are served while the store is populated.
 func (s *GtsStore) populateFromReader() {
 	if s.reader == nil {
 		return
@@ -105,67 +373,350 @@ func (s *GtsStore) populateFromReader() {
 			break
 		}
 		if entity.GtsID != nil && entity.GtsID.ID != "" {
-			s.byID[entity.GtsID.ID] = entity
+			s.mu.Lock()
+			if err := s.checkShortIDLocked(entity.GtsID.ID, nil); err != nil {
+				log.Printf("ERROR: skipping %s: %v", entity.GtsID.ID, err)
+			} else {
+				s.loadLocked(entity)
+			}
+			s.mu.Unlock()
 		}
 	}
+	s.collectLoadErrors(s.reader)
+}
+
+// validateLoadedReferences applies the reference validation mode to the entities loaded from
+// readers, once they are all loaded so that references resolve across files: in RefValidationWarn
+// mode entities are annotated with their unresolved references, and in RefValidationStrict mode
+// entities with invalid references are dropped and logged
+func (s *GtsStore) validateLoadedReferences() {
+	switch s.config.refValidationMode() {
+	case RefValidationWarn:
+		s.recheckReferences(true)
+	case RefValidationStrict:
+		var invalid []string
+		resolved := make(map[*JsonEntity][]ReferenceKind)
+		for _, entity := range s.entitySnapshot() {
+			kinds := s.referenceKinds(entity, nil)
+			if err := referenceErrors(entity, kinds); err != nil {
+				log.Printf("ERROR: skipping %s: GTS reference validation failed: %v", entity.GtsID.ID, err)
+				invalid = append(invalid, entity.GtsID.ID)
+				continue
+			}
+			resolved[entity] = kinds
+		}
+		s.mu.Lock()
+		for _, id := range invalid {
+			s.removeLocked(id)
+		}
+		s.updateReferenceKindsLocked(resolved)
+		s.mu.Unlock()
+	}
+}
+
+// collectLoadErrors records the errors of a reader that reports them (see GtsErrorReader)
+func (s *GtsStore) collectLoadErrors(reader GtsReader) {
+	errorReader, ok := reader.(GtsErrorReader)
+	if !ok {
+		return
+	}
+	if errs := errorReader.Errors(); len(errs) > 0 {
+		s.mu.Lock()
+		s.loadErrors = append(s.loadErrors, errs...)
+		s.mu.Unlock()
+	}
+}
+
+// LoadErrors returns the files and objects the readers of the store skipped while populating it,
+// such as invalid JSON and objects without a GTS ID, and the IDs they read from several files,
+// in reader order; it is empty for readers that do not report errors (see GtsErrorReader)
+func (s *GtsStore) LoadErrors() []ReaderError {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ReaderError(nil), s.loadErrors...)
+}
+
+// loadError returns a *StoreLoadError of the load errors, or nil when there are none
+func (s *GtsStore) loadError() error {
+	if errs := s.LoadErrors(); len(errs) > 0 {
+		return &StoreLoadError{Errors: errs}
+	}
+	return nil
 }
 
 // Register adds a JsonEntity to the store with optional GTS reference validation
 func (s *GtsStore) Register(entity *JsonEntity) error {
+	if err := s.checkRegistration(entity, nil); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.frozen {
+		s.mu.Unlock()
+		return &StoreFrozenError{Operation: "register " + entity.GtsID.ID}
+	}
+	if err := s.checkShortIDLocked(entity.GtsID.ID, nil); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if err := s.persistLocked(entity); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.putLocked(entity)
+	s.mu.Unlock()
+
+	s.releaseReservation(entity.GtsID.ID)
+	log.Printf("Registered entity: %s (schema: %v, refs: %d)", entity.GtsID.ID, entity.IsSchema, len(entity.GtsRefs))
+	return nil
+}
+
+// checkRegistration runs the checks of Register that precede storing the entity: ID limits,
+// reference validation and re-validation of the dependents of an overwritten schema.
+// References also resolve against staged, the not yet committed entities of a batch, when not nil.
+func (s *GtsStore) checkRegistration(entity *JsonEntity, staged map[string]*JsonEntity) error {
 	if entity.GtsID == nil || entity.GtsID.ID == "" {
 		return fmt.Errorf("entity must have a valid gts_id")
 	}
 
+	// The entity ID was parsed with the process-wide limits; enforce the store's own limits
+	limits := s.config.idLimits()
+	if err := limits.checkLength(entity.GtsID.ID, len(entity.GtsID.ID)); err != nil {
+		return err
+	}
+	if err := limits.checkSegments(entity.GtsID.ID, len(entity.GtsID.Segments)); err != nil {
+		return err
+	}
+
+	if s.config.RejectSchemaIDConflicts {
+		if err := entity.schemaIDConflictError(); err != nil {
+			return err
+		}
+	}
+	if s.config.EnforceIDTypeConsistency {
+		if err := entity.idTypeMismatchError(); err != nil {
+			return err
+		}
+	}
+
 	// Perform validation if enabled
-	if s.config.ValidateGtsReferences {
-		if err := s.validateEntityGtsReferences(entity); err != nil {
+	// The entity is not registered yet, so the outcome is recorded on its references
+	switch s.config.refValidationMode() {
+	case RefValidationStrict:
+		kinds := s.referenceKinds(entity, staged)
+		recordReferenceKinds(entity, kinds)
+		if err := referenceErrors(entity, kinds); err != nil {
 			return fmt.Errorf("GTS reference validation failed for entity %s: %w", entity.GtsID.ID, err)
 		}
+	case RefValidationWarn:
+		kinds := s.referenceKinds(entity, staged)
+		recordReferenceKinds(entity, kinds)
+		entity.UnresolvedRefs = unresolvedReferences(entity, kinds)
+		if len(entity.UnresolvedRefs) > 0 {
+			log.Printf("Entity %s has %d unresolved reference(s): %s", entity.GtsID.ID, len(entity.UnresolvedRefs), strings.Join(entity.UnresolvedRefs, ", "))
+		}
 	}
 
-	s.byID[entity.GtsID.ID] = entity
-	log.Printf("Registered entity: %s (schema: %v, refs: %d)", entity.GtsID.ID, entity.IsSchema, len(entity.GtsRefs))
+	// Check the instances of an overwritten schema against its new content before replacing it
+	if mode := s.config.RevalidateDependentsOnSchemaChange; mode != DependentValidationOff && entity.IsSchema {
+		entity.DependentReport = s.revalidateDependents(entity)
+		if mode == DependentValidationReject && entity.DependentReport != nil &&
+			(entity.DependentReport.Invalid > 0 || entity.DependentReport.Error != "") {
+			return &DependentInstancesInvalidError{Report: entity.DependentReport}
+		}
+	}
 	return nil
 }
 
+// putLocked stores a registered entity, replacing any entity with the same ID; s.mu must be held
+// for writing. UpdatedAt is set to the store clock, and RegisteredAt too unless the entity replaces
+// one with the same ID, whose RegisteredAt it keeps.
+func (s *GtsStore) putLocked(entity *JsonEntity) {
+	now := s.clock()
+	registeredAt := now
+	if previous, ok := s.byID[entity.GtsID.ID]; ok {
+		registeredAt = previous.RegisteredAt
+	}
+	entity.RegisteredAt, entity.UpdatedAt = registeredAt, now
+	s.storeLocked(entity)
+}
+
+// persistLocked writes an entity about to be stored through to the writer, if any; s.mu must be
+// held for writing, so that the writer sees changes in the order they are applied
+func (s *GtsStore) persistLocked(entity *JsonEntity) error {
+	if s.writer == nil {
+		return nil
+	}
+	if err := s.writer.Write(entity); err != nil {
+		return &StorePersistenceError{Operation: "write", EntityID: entity.GtsID.ID, Err: err}
+	}
+	return nil
+}
+
+// unpersistLocked deletes an entity about to be removed from the writer, if any; s.mu must be
+// held for writing
+func (s *GtsStore) unpersistLocked(id string) error {
+	if s.writer == nil {
+		return nil
+	}
+	if err := s.writer.Delete(id); err != nil {
+		return &StorePersistenceError{Operation: "delete", EntityID: id, Err: err}
+	}
+	return nil
+}
+
+// loadLocked stores an entity read from the reader, keeping the timestamps the reader provides
+// (from the manifest of an exported tree) and otherwise setting them to the load time
+func (s *GtsStore) loadLocked(entity *JsonEntity) {
+	if entity.RegisteredAt.IsZero() {
+		entity.RegisteredAt = s.clock()
+	}
+	if entity.UpdatedAt.IsZero() {
+		entity.UpdatedAt = entity.RegisteredAt
+	}
+	s.storeLocked(entity)
+}
+
+// storeLocked stores an entity and indexes it; s.mu must be held for writing
+func (s *GtsStore) storeLocked(entity *JsonEntity) {
+	entity.ContentHash = ContentHash(entity.Content)
+	if previous, ok := s.byID[entity.GtsID.ID]; ok {
+		s.unresolvedRefs -= len(previous.UnresolvedRefs)
+		if previous.File != nil && entity.File != nil && previous.File.Path != entity.File.Path {
+			if s.duplicateSources == nil {
+				s.duplicateSources = make(map[string][]string)
+			}
+			s.duplicateSources[entity.GtsID.ID] = append(s.duplicateSources[entity.GtsID.ID], previous.File.Path)
+		}
+	}
+	s.unresolvedRefs += len(entity.UnresolvedRefs)
+	s.byID[entity.GtsID.ID] = entity
+	s.shortIDs[shortIDOf(entity.GtsID.ID)] = entity.GtsID.ID
+	s.indexUUIDLocked(entity.GtsID)
+	s.misses.remove(entity.GtsID.ID)
+}
+
 // RegisterSchema registers a schema with the given type ID
-// This is a legacy method for backward compatibility
+// This is a legacy method for backward compatibility. The schema goes through the checks of
+// Register (reference validation, dependents re-validation); its content may omit $id, but a $id
+// naming another type is rejected with StoreSchemaIDMismatchError.
 func (s *GtsStore) RegisterSchema(typeID string, schema map[string]any) error {
-	if typeID[len(typeID)-1] != '~' {
-		return fmt.Errorf("schema type_id must end with '~'")
+	if typeID == "" || !strings.HasSuffix(typeID, "~") {
+		return &StoreInvalidSchemaTypeIDError{TypeID: typeID}
 	}
 
-	// Parse to validate
-	gtsID, err := NewGtsID(typeID)
+	// Parse to validate against the store's ID limits
+	gtsID, err := newGtsID(typeID, s.config.idLimits())
 	if err != nil {
 		return err
 	}
 
-	entity := &JsonEntity{
-		GtsID:    gtsID,
-		Content:  schema,
-		IsSchema: true,
+	// Extract references and the schema ID as for any other schema, then register it under typeID
+	cfg := s.GtsConfig()
+	entity := NewJsonEntity(schema, cfg)
+	if contentID := entity.getFieldValue("$id"); contentID != "" && contentID != gtsID.ID {
+		return &StoreSchemaIDMismatchError{TypeID: gtsID.ID, ContentID: contentID}
+	}
+	entity.GtsID = gtsID
+	entity.IsSchema = true
+	entity.IDError = nil
+	entity.SchemaID = entity.calcJSONSchemaID(cfg, gtsID.ID)
+	entity.setLabel()
+
+	if err := s.checkRegistration(entity, nil); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.frozen {
+		s.mu.Unlock()
+		return &StoreFrozenError{Operation: "register schema " + typeID}
+	}
+	if err := s.checkShortIDLocked(gtsID.ID, nil); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if err := s.persistLocked(entity); err != nil {
+		s.mu.Unlock()
+		return err
 	}
+	s.putLocked(entity)
+	s.mu.Unlock()
+	return nil
+}
+
+// Freeze switches the store into read-only mode
+// Subsequent registrations fail with StoreFrozenError; reads are unaffected.
+// Registrations already holding the store lock complete before the store is frozen.
+func (s *GtsStore) Freeze() {
+	s.mu.Lock()
+	s.frozen = true
+	s.mu.Unlock()
+	log.Printf("GtsStore frozen with %d entities", s.Count())
+}
 
-	s.byID[typeID] = entity
+// Unfreeze switches a frozen store back to read-write mode
+// It is only permitted when the store was created with RegistryConfig.AllowUnfreeze.
+func (s *GtsStore) Unfreeze() error {
+	if !s.config.AllowUnfreeze {
+		return fmt.Errorf("store does not allow unfreezing (AllowUnfreeze is not set)")
+	}
+	s.mu.Lock()
+	s.frozen = false
+	s.mu.Unlock()
 	return nil
 }
 
+// IsFrozen reports whether the store is in read-only mode
+func (s *GtsStore) IsFrozen() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.frozen
+}
+
 // Get retrieves a JsonEntity by its ID
-// If not found in cache, attempts to fetch from reader
+// If not found in cache, attempts to fetch from reader, as RegistryConfig.ReaderMissPolicy allows.
+// With RegistryConfig.LenientLookup a missed ID is retried in normalized form (see Lookup).
 func (s *GtsStore) Get(entityID string) *JsonEntity {
+	entity, _ := s.Lookup(entityID)
+	return entity
+}
+
+// getExact retrieves a JsonEntity by its exact ID, without the lenient retry of Get; the write
+// paths use it so that registrations, tags and references never match a normalized ID
+func (s *GtsStore) getExact(entityID string) *JsonEntity {
 	// Check cache first
-	if entity, ok := s.byID[entityID]; ok {
+	s.mu.RLock()
+	entity, ok := s.byID[entityID]
+	s.mu.RUnlock()
+	if ok {
+		s.lookups.hits.Add(1)
 		return entity
 	}
+	s.lookups.misses.Add(1)
 
 	// Try to fetch from reader
 	if s.reader != nil {
+		if s.skipReaderLookup(entityID) {
+			s.lookups.negativeHits.Add(1)
+			return nil
+		}
+		s.lookups.readerLookups.Add(1)
 		entity := s.reader.ReadByID(entityID)
-		if entity != nil {
-			s.byID[entityID] = entity
-			return entity
+		if entity == nil {
+			s.recordReaderMiss(entityID)
+			return nil
+		}
+		s.mu.Lock()
+		if existing, ok := s.byID[entityID]; ok {
+			entity = existing
+		} else if err := s.checkShortIDLocked(entityID, nil); err != nil {
+			log.Printf("ERROR: not caching %s: %v", entityID, err)
+		} else {
+			s.loadLocked(entity)
 		}
+		s.mu.Unlock()
+		return entity
 	}
 
 	return nil
@@ -184,20 +735,108 @@ func (s *GtsStore) GetSchemaContent(typeID string) (map[string]any, error) {
 }
 
 // Items returns all entity ID and entity pairs
+// The map is a snapshot: it is safe to iterate while the store is modified and is not updated by
+// later registrations.
 func (s *GtsStore) Items() map[string]*JsonEntity {
-	return s.byID
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make(map[string]*JsonEntity, len(s.byID))
+	for id, entity := range s.byID {
+		items[id] = entity
+	}
+	return items
+}
+
+// schemaContents returns a snapshot of the content of every registered schema by ID, for
+// pre-loading schema compilers without holding the store lock while compiling
+func (s *GtsStore) schemaContents() map[string]map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schemas := make(map[string]map[string]any)
+	for id, entity := range s.byID {
+		if entity.IsSchema {
+			schemas[id] = entity.Content
+		}
+	}
+	return schemas
+}
+
+// entitySnapshot returns the registered entities in store order, or in ID order with StableOrder
+// The snapshot lets callers iterate without holding the store lock, e.g. while running callbacks.
+func (s *GtsStore) entitySnapshot() []*JsonEntity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.config.StableOrder {
+		ids := s.sortedIDs()
+		entities := make([]*JsonEntity, 0, len(ids))
+		for _, id := range ids {
+			entities = append(entities, s.byID[id])
+		}
+		return entities
+	}
+	entities := make([]*JsonEntity, 0, len(s.byID))
+	for _, entity := range s.byID {
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// snapshotIDs returns the registered entity IDs in store order, or in ID order with StableOrder
+func (s *GtsStore) snapshotIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.config.StableOrder {
+		return s.sortedIDs()
+	}
+	ids := make([]string, 0, len(s.byID))
+	for id := range s.byID {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// entitiesOf returns the entities registered under ids in the same order, skipping the IDs
+// removed since they were listed
+func (s *GtsStore) entitiesOf(ids []string) []*JsonEntity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entities := make([]*JsonEntity, 0, len(ids))
+	for _, id := range ids {
+		if entity, ok := s.byID[id]; ok {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// sortedIDs returns the registered entity IDs in lexical order; the caller must hold the store lock
+func (s *GtsStore) sortedIDs() []string {
+	ids := make([]string, 0, len(s.byID))
+	for id := range s.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
 }
 
 // Count returns the number of entities in the store
 func (s *GtsStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return len(s.byID)
 }
 
 // EntityInfo represents basic information about an entity
 type EntityInfo struct {
-	ID       string `json:"id"`
-	SchemaID string `json:"schema_id"`
-	IsSchema bool   `json:"is_schema"`
+	ID             string            `json:"id"`
+	ShortID        string            `json:"short_id"`
+	SchemaID       string            `json:"schema_id"`
+	IsSchema       bool              `json:"is_schema"`
+	UnresolvedRefs []string          `json:"unresolved_refs,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	RegisteredAt   time.Time         `json:"registered_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	ContentHash    string            `json:"content_hash"`
 }
 
 // ListResult represents the result of listing entities
@@ -205,25 +844,40 @@ type ListResult struct {
 	Entities []EntityInfo `json:"entities"`
 	Count    int          `json:"count"`
 	Total    int          `json:"total"`
+	// NextCursor, set by ListPage when entities follow the page, is passed back to ListPage to
+	// continue after it
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // List returns a list of entities up to the specified limit
 func (s *GtsStore) List(limit int) *ListResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	total := len(s.byID)
 	entities := []EntityInfo{}
 
-	count := 0
-	for id, entity := range s.byID {
-		if count >= limit {
-			break
+	add := func(entity *JsonEntity) bool {
+		if len(entities) >= limit {
+			return false
 		}
-		entities = append(entities, EntityInfo{
-			ID:       id,
-			SchemaID: entity.SchemaID,
-			IsSchema: entity.IsSchema,
-		})
-		count++
+		entities = append(entities, s.entityInfoLocked(entity))
+		return true
 	}
+	if s.config.StableOrder {
+		for _, id := range s.sortedIDs() {
+			if !add(s.byID[id]) {
+				break
+			}
+		}
+	} else {
+		for _, entity := range s.byID {
+			if !add(entity) {
+				break
+			}
+		}
+	}
+	count := len(entities)
 
 	return &ListResult{
 		Entities: entities,
@@ -232,41 +886,224 @@ func (s *GtsStore) List(limit int) *ListResult {
 	}
 }
 
-// validateEntityGtsReferences validates all GTS references in an entity
-func (s *GtsStore) validateEntityGtsReferences(entity *JsonEntity) error {
+// ListPage returns up to limit entities in GTS ID order, starting after cursor: "" starts at
+// the first entity, otherwise the NextCursor of the previous page. The cursor is the ID of the
+// last entity of that page, so a page resumes at the next ID in lexical order even when that
+// entity was removed in between; a cursor past the last ID returns an empty page.
+func (s *GtsStore) ListPage(limit int, cursor string) *ListResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := s.sortedIDs()
+	start := 0
+	if cursor != "" {
+		start = sort.Search(len(ids), func(i int) bool { return ids[i] > cursor })
+	}
+	end := min(start+max(limit, 0), len(ids))
+
+	entities := make([]EntityInfo, 0, end-start)
+	for _, id := range ids[start:end] {
+		entities = append(entities, s.entityInfoLocked(s.byID[id]))
+	}
+	result := &ListResult{
+		Entities: entities,
+		Count:    len(entities),
+		Total:    len(ids),
+	}
+	if end < len(ids) && end > start {
+		result.NextCursor = ids[end-1]
+	}
+	return result
+}
+
+// entityInfoLocked describes an entity for List, ListPage and ChangedSince; s.mu must be held
+func (s *GtsStore) entityInfoLocked(entity *JsonEntity) EntityInfo {
+	id := entity.GtsID.ID
+	return EntityInfo{
+		ID:             id,
+		ShortID:        shortIDOf(id),
+		SchemaID:       entity.SchemaID,
+		IsSchema:       entity.IsSchema,
+		UnresolvedRefs: entity.UnresolvedRefs,
+		Tags:           copyTags(s.tags[id]),
+		RegisteredAt:   entity.RegisteredAt,
+		UpdatedAt:      entity.UpdatedAt,
+		ContentHash:    entity.ContentHash,
+	}
+}
+
+// ChangedSince returns the entities registered or updated at or after t (the bound is
+// inclusive, so a sync job passing the UpdatedAt of the last entity it saw receives that entity
+// again rather than missing others written in the same instant), ordered by UpdatedAt then ID
+func (s *GtsStore) ChangedSince(t time.Time) []EntityInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entities := []EntityInfo{}
+	for _, entity := range s.byID {
+		if !entity.UpdatedAt.Before(t) {
+			entities = append(entities, s.entityInfoLocked(entity))
+		}
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if !entities[i].UpdatedAt.Equal(entities[j].UpdatedAt) {
+			return entities[i].UpdatedAt.Before(entities[j].UpdatedAt)
+		}
+		return entities[i].ID < entities[j].ID
+	})
+	return entities
+}
+
+// resolveReference looks up the entity a reference points to, without modifying the reference.
+// Entities in staged, when not nil, take precedence over the store.
+func (s *GtsStore) resolveReference(ref *GtsReference, staged map[string]*JsonEntity) *JsonEntity {
+	if target := staged[ref.ID]; target != nil {
+		return target
+	}
+	return s.getExact(ref.ID)
+}
+
+// skipReference reports whether a reference of entity is left unresolved: self-references and
+// JSON Schema meta-schema references
+func skipReference(entity *JsonEntity, ref *GtsReference) bool {
+	return ref.ID == entity.GtsID.ID ||
+		strings.HasPrefix(ref.ID, "http://json-schema.org") ||
+		strings.HasPrefix(ref.ID, "https://json-schema.org")
+}
+
+// referenceKinds resolves the references of an entity against the store and the staged entities
+// when not nil: kinds[i] is the kind of the target of entity.GtsRefs[i], empty when the reference
+// is skipped or its target not found. The references themselves are not modified, since the entity
+// may be registered and read concurrently; see recordReferenceKinds.
+func (s *GtsStore) referenceKinds(entity *JsonEntity, staged map[string]*JsonEntity) []ReferenceKind {
 	if entity == nil || len(entity.GtsRefs) == 0 {
 		return nil
 	}
+	kinds := make([]ReferenceKind, len(entity.GtsRefs))
+	for i, ref := range entity.GtsRefs {
+		if skipReference(entity, ref) {
+			continue
+		}
+		if target := s.resolveReference(ref, staged); target != nil {
+			kinds[i] = entityReferenceKind(target)
+		}
+	}
+	return kinds
+}
 
-	var errors []string
+// recordReferenceKinds sets Resolved and ResolvedKind on the references of an entity from kinds
+// (see referenceKinds). The entity must not be registered yet, or s.mu must be held for writing.
+func recordReferenceKinds(entity *JsonEntity, kinds []ReferenceKind) {
+	for i, ref := range entity.GtsRefs {
+		if skipReference(entity, ref) {
+			continue
+		}
+		ref.Resolved = kinds[i] != ""
+		ref.ResolvedKind = kinds[i]
+	}
+}
 
-	for _, ref := range entity.GtsRefs {
-		if ref.ID == entity.GtsID.ID {
-			// Skip self-references
+// unresolvedReferences returns the IDs referenced by an entity whose targets were not found, given
+// the kinds of its references (see referenceKinds)
+func unresolvedReferences(entity *JsonEntity, kinds []ReferenceKind) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for i, ref := range entity.GtsRefs {
+		if kinds[i] != "" || seen[ref.ID] || skipReference(entity, ref) {
 			continue
 		}
+		missing = append(missing, ref.ID)
+		seen[ref.ID] = true
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// UnresolvedRefCount returns the total number of unresolved references recorded in warn mode
+func (s *GtsStore) UnresolvedRefCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unresolvedRefs
+}
+
+// RecheckReferences re-resolves the references of every entity carrying warnings
+// Warnings are cleared for references whose referents have been registered since.
+// It returns the number of references that are still unresolved.
+func (s *GtsStore) RecheckReferences() int {
+	return s.recheckReferences(false)
+}
+
+// recheckReferences re-resolves the references of the entities carrying warnings, or of every
+// entity when all is set, and returns the number of references that are still unresolved
+func (s *GtsStore) recheckReferences(all bool) int {
+	s.mu.RLock()
+	var pending []*JsonEntity
+	for _, entity := range s.byID {
+		if all || len(entity.UnresolvedRefs) > 0 {
+			pending = append(pending, entity)
+		}
+	}
+	s.mu.RUnlock()
 
-		// Skip JSON Schema meta-schema references
-		if strings.HasPrefix(ref.ID, "http://json-schema.org") ||
-			strings.HasPrefix(ref.ID, "https://json-schema.org") {
+	// Resolve outside the lock since lookups may populate the cache from the reader
+	resolved := make(map[*JsonEntity][]ReferenceKind, len(pending))
+	for _, entity := range pending {
+		resolved[entity] = s.referenceKinds(entity, nil)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateReferenceKindsLocked(resolved)
+	total := 0
+	for _, entity := range s.byID {
+		total += len(entity.UnresolvedRefs)
+	}
+	s.unresolvedRefs = total
+	return total
+}
+
+// updateReferenceKindsLocked records the kinds resolved for the references of registered entities
+// (see referenceKinds) and recomputes their unresolved references; s.mu must be held for writing.
+// Registered entities are read without the lock by the callers of Get, so each one is replaced
+// with an updated copy rather than modified. Entities replaced or removed since they were resolved
+// are left as they are.
+func (s *GtsStore) updateReferenceKindsLocked(resolved map[*JsonEntity][]ReferenceKind) {
+	for entity, kinds := range resolved {
+		if s.byID[entity.GtsID.ID] != entity {
+			continue
+		}
+		updated := *entity
+		updated.GtsRefs = cloneReferences(entity.GtsRefs)
+		recordReferenceKinds(&updated, kinds)
+		updated.UnresolvedRefs = unresolvedReferences(&updated, kinds)
+		s.byID[entity.GtsID.ID] = &updated
+	}
+}
+
+// validateEntityGtsReferences validates all GTS references in an entity, resolving them against the store
+// and the staged entities when not nil
+func (s *GtsStore) validateEntityGtsReferences(entity *JsonEntity, staged map[string]*JsonEntity) error {
+	return referenceErrors(entity, s.referenceKinds(entity, staged))
+}
+
+// referenceErrors reports the references of an entity whose targets were not found or are not of
+// the kind the referencing keyword requires, given the kinds of its references (see referenceKinds)
+func referenceErrors(entity *JsonEntity, kinds []ReferenceKind) error {
+	var errors []string
+	for i, ref := range entity.GtsRefs {
+		if skipReference(entity, ref) {
 			continue
 		}
 
 		// Check if the referenced entity exists in the store
-		referencedEntity := s.Get(ref.ID)
-		if referencedEntity == nil {
+		if kinds[i] == "" {
 			errors = append(errors, fmt.Sprintf("referenced entity not found: %s (at %s)", ref.ID, ref.SourcePath))
 			continue
 		}
 
-		// Additional validation for schema references
-		if entity.IsSchema {
-			if strings.Contains(ref.SourcePath, "$ref") {
-				// This is a schema reference - the referenced entity should be a schema
-				if !referencedEntity.IsSchema {
-					errors = append(errors, fmt.Sprintf("schema reference points to non-schema entity: %s (at %s)", ref.ID, ref.SourcePath))
-				}
-			}
+		// The target must be of the kind the referencing keyword requires
+		if mismatch := referenceKindMismatch(ref, kinds[i]); mismatch != "" {
+			errors = append(errors, mismatch)
 		}
 	}
 
@@ -278,6 +1115,7 @@ func (s *GtsStore) validateEntityGtsReferences(entity *JsonEntity) error {
 }
 
 // ValidateSchema validates a schema including JSON Schema meta-schema and GTS reference validation
+// With RegistryConfig.StrictSchemaKeywords, unknown keywords are reported as well.
 func (s *GtsStore) ValidateSchema(gtsID string) error {
 	if !strings.HasSuffix(gtsID, "~") {
 		return fmt.Errorf("ID '%s' is not a schema (must end with '~')", gtsID)
@@ -299,6 +1137,13 @@ func (s *GtsStore) ValidateSchema(gtsID string) error {
 		return fmt.Errorf("schema content is nil")
 	}
 
+	// Catch misspelled keywords, which JSON Schema otherwise ignores
+	if s.config.StrictSchemaKeywords {
+		if findings := CheckSchemaKeywords(entity.Content); len(findings) > 0 {
+			return schemaKeywordError(findings)
+		}
+	}
+
 	// Validate $ref constraints in the schema
 	refValidator := NewRefValidator()
 	refErrors := refValidator.ValidateSchemaRefs(entity.Content, "")
@@ -322,7 +1167,7 @@ func (s *GtsStore) ValidateSchema(gtsID string) error {
 	}
 
 	// Validate GTS references in the schema
-	if err := s.validateEntityGtsReferences(entity); err != nil {
+	if err := s.validateEntityGtsReferences(entity, nil); err != nil {
 		return fmt.Errorf("schema GTS reference validation failed: %w", err)
 	}
 
@@ -341,21 +1186,17 @@ func (s *GtsStore) ValidateInstanceWithXGtsRef(instanceID string) error {
 		return fmt.Errorf("entity '%s' is a schema, not an instance", instanceID)
 	}
 
-	// Get the schema for this instance
-	if instance.SchemaID == "" {
-		return &StoreGtsSchemaForInstanceNotFoundError{EntityID: instanceID}
+	// Resolve the schema for this instance
+	schemaID, _, err := s.resolveInstanceSchema(instance)
+	if err != nil {
+		return err
 	}
-
-	schema := s.Get(instance.SchemaID)
+	schema := s.Get(schemaID)
 	if schema == nil {
-		return &StoreGtsSchemaNotFoundError{EntityID: instance.SchemaID}
-	}
-
-	if !schema.IsSchema {
-		return fmt.Errorf("schema entity '%s' is not marked as schema", instance.SchemaID)
+		return &StoreGtsSchemaNotFoundError{EntityID: schemaID}
 	}
 
-	log.Printf("Validating instance %s against schema %s", instanceID, instance.SchemaID)
+	log.Printf("Validating instance %s against schema %s", instanceID, schemaID)
 
 	// Validate x-gts-ref constraints
 	xGtsRefValidator := NewXGtsRefValidator(s)
@@ -369,7 +1210,7 @@ func (s *GtsStore) ValidateInstanceWithXGtsRef(instanceID string) error {
 	}
 
 	// Validate GTS references in the instance
-	if err := s.validateEntityGtsReferences(instance); err != nil {
+	if err := s.validateEntityGtsReferences(instance, nil); err != nil {
 		return fmt.Errorf("instance GTS reference validation failed: %w", err)
 	}
 
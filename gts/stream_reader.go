@@ -0,0 +1,193 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// StdinStreamName is the name NewGtsStreamReader gives its stream, used in entity labels
+const StdinStreamName = "stdin"
+
+// GtsStreamReader reads JSON entities from a stream such as stdin. The stream holds a single JSON
+// object, a JSON array of objects, or a sequence of objects such as newline-delimited JSON
+// (NDJSON). Entities are labelled with the stream name and, for arrays and sequences, their
+// position, e.g. stdin#3. Values that are not objects, and objects without a GTS ID, are skipped.
+//
+// The stream is read as entities are requested. A stream cannot be rewound, so the reader keeps
+// the entities it returned: Reset replays them before reading on. Positions are not recorded
+// (see GtsConfig.RecordPositions), as the stream is not kept.
+type GtsStreamReader struct {
+	name    string
+	cfg     *GtsConfig
+	in      *bufio.Reader
+	dec     *json.Decoder
+	keepAll bool
+
+	// entities are the entities returned so far, replayed after Reset from index
+	entities []*JsonEntity
+	index    int
+
+	started bool
+	inArray bool
+	done    bool
+	seq     int
+	skipped int
+	err     error
+}
+
+// NewGtsStreamReader creates a reader for the entities of r, named stdin in entity labels
+func NewGtsStreamReader(r io.Reader, cfg *GtsConfig) *GtsStreamReader {
+	return NewGtsStreamReaderWithName(r, StdinStreamName, cfg)
+}
+
+// NewGtsStreamReaderWithName creates a reader for the entities of r, named name in entity labels
+func NewGtsStreamReaderWithName(r io.Reader, name string, cfg *GtsConfig) *GtsStreamReader {
+	if cfg == nil {
+		cfg = DefaultGtsConfig()
+	}
+	in := bufio.NewReader(r)
+	return &GtsStreamReader{name: name, cfg: cfg, in: in, dec: json.NewDecoder(in)}
+}
+
+// ParseCandidateStream reads every object of a stream as GtsStreamReader does, including objects
+// without a GTS ID, for validation with GtsStore.ValidateEntity without registering them (see
+// ParseCandidateDocument). It fails when the stream is not valid JSON.
+func ParseCandidateStream(name string, r io.Reader, cfg *GtsConfig) ([]*JsonEntity, error) {
+	reader := NewGtsStreamReaderWithName(r, name, cfg)
+	reader.keepAll = true
+
+	var entities []*JsonEntity
+	for entity := reader.Next(); entity != nil; entity = reader.Next() {
+		entities = append(entities, entity)
+	}
+	return entities, reader.Err()
+}
+
+// Next returns the next JsonEntity or nil when exhausted
+func (r *GtsStreamReader) Next() *JsonEntity {
+	if r.index < len(r.entities) {
+		entity := r.entities[r.index]
+		r.index++
+		return entity
+	}
+
+	for {
+		entity := r.readEntity()
+		if entity == nil {
+			return nil
+		}
+		if entity.GtsID == nil && !r.keepAll {
+			r.skipped++
+			continue
+		}
+		r.entities = append(r.entities, entity)
+		r.index++
+		return entity
+	}
+}
+
+// ReadByID reads a JsonEntity by its ID
+// For StreamReader, this returns nil as we don't support random access by ID
+func (r *GtsStreamReader) ReadByID(entityID string) *JsonEntity {
+	return nil
+}
+
+// Reset restarts the iteration with the entities already returned, then reads on from the stream
+func (r *GtsStreamReader) Reset() {
+	r.index = 0
+}
+
+// Err returns the error that ended the stream early, e.g. malformed JSON, or nil
+func (r *GtsStreamReader) Err() error {
+	return r.err
+}
+
+// Skipped returns the number of objects without a GTS ID skipped so far
+func (r *GtsStreamReader) Skipped() int {
+	return r.skipped
+}
+
+// readEntity decodes the next object of the stream, or returns nil at its end
+func (r *GtsStreamReader) readEntity() *JsonEntity {
+	for !r.done {
+		if !r.started {
+			r.started = true
+			if err := r.start(); err != nil {
+				r.fail(err)
+				return nil
+			}
+		}
+		if r.inArray && !r.dec.More() {
+			r.done = true
+			return nil
+		}
+
+		var value any
+		if err := r.dec.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) && !r.inArray {
+				r.done = true
+				return nil
+			}
+			r.fail(err)
+			return nil
+		}
+		seq := r.seq
+		r.seq++
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		file := &JsonFile{Path: r.name, Name: r.name, Content: item}
+		// A stream holding a single object is labelled like a file holding one
+		if !r.inArray && seq == 0 && !r.dec.More() {
+			return NewJsonEntityWithFile(item, r.cfg, file, nil)
+		}
+		return NewJsonEntityWithFile(item, r.cfg, file, &seq)
+	}
+	return nil
+}
+
+// start detects whether the stream holds an array, consuming its opening bracket
+func (r *GtsStreamReader) start() error {
+	for {
+		b, err := r.in.ReadByte()
+		if err == io.EOF {
+			r.done = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		if err := r.in.UnreadByte(); err != nil {
+			return err
+		}
+		if b == '[' {
+			if _, err := r.dec.Token(); err != nil {
+				return err
+			}
+			r.inArray = true
+		}
+		return nil
+	}
+}
+
+// fail ends the stream with err
+func (r *GtsStreamReader) fail(err error) {
+	r.done = true
+	r.err = fmt.Errorf("failed to read %s: %w", r.name, err)
+	log.Printf("ERROR: %v", r.err)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readStreamLabels drains a stream reader and returns the labels of its entities
+func readStreamLabels(reader *GtsStreamReader) []string {
+	labels := []string{}
+	for entity := reader.Next(); entity != nil; entity = reader.Next() {
+		labels = append(labels, entity.Label)
+	}
+	return labels
+}
+
+func TestGtsStreamReader_Formats(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		labels []string
+	}{
+		{"single object", `  {"id": "gts.x.test.stream.item.v1~x.test._.a.v1"}` + "\n", []string{"stdin"}},
+		{"array", `[{"id": "gts.x.test.stream.item.v1~x.test._.a.v1"}, 42, {"name": "no id"}, {"id": "gts.x.test.stream.item.v1~x.test._.b.v1"}]`, []string{"stdin#0", "stdin#3"}},
+		{"NDJSON", "{\"id\": \"gts.x.test.stream.item.v1~x.test._.a.v1\"}\n{\"name\": \"no id\"}\n\n{\"id\": \"gts.x.test.stream.item.v1~x.test._.b.v1\"}\n", []string{"stdin#0", "stdin#2"}},
+		{"empty", " \n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewGtsStreamReader(strings.NewReader(tt.input), nil)
+			if labels := readStreamLabels(reader); !reflect.DeepEqual(labels, tt.labels) {
+				t.Errorf("Expected labels %v, got %v", tt.labels, labels)
+			}
+			if reader.Err() != nil {
+				t.Errorf("Unexpected error: %v", reader.Err())
+			}
+			if reader.ReadByID("gts.x.test.stream.item.v1~x.test._.a.v1") != nil {
+				t.Errorf("Expected ReadByID to return nil")
+			}
+		})
+	}
+}
+
+func TestGtsStreamReader_ResetAndErrors(t *testing.T) {
+	input := "{\"id\": \"gts.x.test.stream.item.v1~x.test._.a.v1\"}\n{\"id\": \"gts.x.test.stream.item.v1~x.test._.b.v1\"}\n{\"id\": "
+	reader := NewGtsStreamReaderWithName(strings.NewReader(input), "configmap", nil)
+	if first := reader.Next(); first == nil || first.Label != "configmap#0" {
+		t.Fatalf("Expected the first entity, got %v", first)
+	}
+	reader.Reset()
+	if labels := readStreamLabels(reader); !reflect.DeepEqual(labels, []string{"configmap#0", "configmap#1"}) {
+		t.Errorf("Expected Reset to replay the first entity, got %v", labels)
+	}
+	if reader.Err() == nil || !strings.Contains(reader.Err().Error(), "failed to read configmap") {
+		t.Errorf("Expected the truncated object to be reported, got %v", reader.Err())
+	}
+
+	store := NewGtsStore(NewGtsStreamReader(strings.NewReader(input), nil))
+	if store.Count() != 2 {
+		t.Errorf("Expected the store to hold the 2 complete entities, got %d", store.Count())
+	}
+
+	entities, err := ParseCandidateStream("stdin", strings.NewReader(`[{"name": "no id"}, {"id": "gts.x.test.stream.item.v1~x.test._.a.v1"}]`), nil)
+	if err != nil || len(entities) != 2 || entities[0].GtsID != nil {
+		t.Errorf("Expected both candidates, got %v %v", entities, err)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TagFilterPrefix marks query filter keys that match entity tags instead of content attributes,
+// e.g. gts.x.commerce.*[#owner=payments-team, status=active]
+const TagFilterPrefix = "#"
+
+// InvalidTagError is returned for tag keys that cannot be stored or queried
+type InvalidTagError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidTagError) Error() string {
+	return fmt.Sprintf("Invalid tag '%s': %s", e.Key, e.Reason)
+}
+
+// validateTagKey checks that a tag key can be used in a query filter
+func validateTagKey(key string) error {
+	if key == "" {
+		return &InvalidTagError{Key: key, Reason: "key must not be empty"}
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("_-./", c)) {
+			return &InvalidTagError{Key: key, Reason: fmt.Sprintf("character %q not allowed, use letters, digits, '_', '-', '.' or '/'", c)}
+		}
+	}
+	return nil
+}
+
+// SetTags replaces the tags of an entity with the given set; an empty set removes all tags.
+// Tags are operational metadata kept alongside the entity, not in its content, and survive
+// re-registration of the entity. Schemas and instances are tagged alike.
+func (s *GtsStore) SetTags(gtsID string, tags map[string]string) error {
+	for key := range tags {
+		if err := validateTagKey(key); err != nil {
+			return err
+		}
+	}
+	if s.getExact(gtsID) == nil {
+		return &StoreGtsObjectNotFoundError{EntityID: gtsID}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.frozen {
+		return &StoreFrozenError{Operation: "tag " + gtsID}
+	}
+	if len(tags) == 0 {
+		delete(s.tags, gtsID)
+		return nil
+	}
+	s.tags[gtsID] = copyTags(tags)
+	return nil
+}
+
+// GetTags returns a copy of the tags of an entity, or nil if it has none
+func (s *GtsStore) GetTags(gtsID string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyTags(s.tags[gtsID])
+}
+
+// DeleteTag removes a single tag from an entity; removing a missing tag is not an error
+func (s *GtsStore) DeleteTag(gtsID string, key string) error {
+	if s.getExact(gtsID) == nil {
+		return &StoreGtsObjectNotFoundError{EntityID: gtsID}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.frozen {
+		return &StoreFrozenError{Operation: "untag " + gtsID}
+	}
+	delete(s.tags[gtsID], key)
+	if len(s.tags[gtsID]) == 0 {
+		delete(s.tags, gtsID)
+	}
+	return nil
+}
+
+// RestoreManifestTags applies the tags recorded in the manifest of a tree written by ExportTree
+// to the entities of this store, typically after loading the tree with GtsFileReader.
+// Manifest entries for entities missing from the store are skipped. It returns the number of
+// entities whose tags were restored.
+func (s *GtsStore) RestoreManifestTags(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if err != nil {
+		return 0, err
+	}
+	var manifest ExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return 0, fmt.Errorf("invalid export manifest in %s: %w", dir, err)
+	}
+
+	restored := 0
+	for _, entry := range manifest.Entities {
+		if len(entry.Tags) == 0 || s.getExact(entry.ID) == nil {
+			continue
+		}
+		if err := s.SetTags(entry.ID, entry.Tags); err != nil {
+			return restored, err
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// copyTags returns a copy of tags, or nil for an empty set
+func copyTags(tags map[string]string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = v
+	}
+	return result
+}
+
+// hasTagFilters reports whether any query filter matches tags
+func hasTagFilters(filters []queryFilter) bool {
+	for _, filter := range filters {
+		if strings.HasPrefix(filter.Key, TagFilterPrefix) {
+			return true
+		}
+	}
+	return false
+}
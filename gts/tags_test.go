@@ -0,0 +1,186 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const (
+	tagTestSchemaID = "gts.x.commerce.orders.order.v1~"
+	tagTestOrderA   = "gts.x.commerce.orders.order.v1~x.commerce._.order_a.v1"
+	tagTestOrderB   = "gts.x.commerce.orders.order.v1~x.commerce._.order_b.v1"
+)
+
+// tagTestEntities are an order schema with two orders
+func tagTestEntities() []map[string]any {
+	return []map[string]any{
+		testSchema(tagTestSchemaID, nil),
+		{"id": tagTestOrderA, "status": "active"},
+		{"id": tagTestOrderB, "status": "inactive"},
+	}
+}
+
+// queryIDs returns the sorted IDs matched by a query expression
+func queryIDs(t *testing.T, store *GtsStore, expr string) []string {
+	t.Helper()
+	var ids []string
+	if err := store.QueryStream(expr, func(item QueryItem) bool {
+		ids = append(ids, item.ID)
+		return true
+	}); err != nil {
+		t.Fatalf("Query %s failed: %v", expr, err)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestTags_CRUD(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{AllowUnfreeze: true}, tagTestEntities()...)
+
+	if tags := store.GetTags(tagTestOrderA); tags != nil {
+		t.Errorf("Expected no tags, got %v", tags)
+	}
+
+	tags := map[string]string{"owner": "payments-team", "env": "prod"}
+	if err := store.SetTags(tagTestOrderA, tags); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+	tags["owner"] = "mutated"
+	if got := store.GetTags(tagTestOrderA); !reflect.DeepEqual(got, map[string]string{"owner": "payments-team", "env": "prod"}) {
+		t.Errorf("Unexpected tags: %v", got)
+	}
+
+	if err := store.DeleteTag(tagTestOrderA, "env"); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+	if err := store.DeleteTag(tagTestOrderA, "missing"); err != nil {
+		t.Errorf("Expected deleting a missing tag to succeed, got %v", err)
+	}
+	if got := store.GetTags(tagTestOrderA); !reflect.DeepEqual(got, map[string]string{"owner": "payments-team"}) {
+		t.Errorf("Unexpected tags after delete: %v", got)
+	}
+
+	// Tags are kept when the entity is registered again and do not leak into its content
+	if err := store.Register(NewJsonEntity(map[string]any{"id": tagTestOrderA, "status": "active"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to re-register entity: %v", err)
+	}
+	if got := store.GetTags(tagTestOrderA); got["owner"] != "payments-team" {
+		t.Errorf("Expected tags to survive re-registration, got %v", got)
+	}
+	if _, ok := store.Get(tagTestOrderA).Content["owner"]; ok {
+		t.Error("Expected tags to stay out of the entity content")
+	}
+
+	// Schemas are tagged like instances and tags show up in List
+	if err := store.SetTags(tagTestSchemaID, map[string]string{"owner": "platform"}); err != nil {
+		t.Fatalf("SetTags on schema failed: %v", err)
+	}
+	for _, info := range store.List(10).Entities {
+		if info.ID == tagTestSchemaID && info.Tags["owner"] != "platform" {
+			t.Errorf("Expected schema tags in List, got %+v", info)
+		}
+		if info.ID == tagTestOrderB && info.Tags != nil {
+			t.Errorf("Expected no tags for untagged entity, got %+v", info)
+		}
+	}
+
+	if err := store.SetTags(tagTestSchemaID, nil); err != nil || store.GetTags(tagTestSchemaID) != nil {
+		t.Errorf("Expected an empty set to clear tags, got %v, %v", err, store.GetTags(tagTestSchemaID))
+	}
+}
+
+func TestTags_Errors(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{AllowUnfreeze: true}, tagTestEntities()...)
+
+	var notFound *StoreGtsObjectNotFoundError
+	if err := store.SetTags("gts.x.commerce.orders.order.v1~x.commerce._.missing.v1", map[string]string{"a": "b"}); !errors.As(err, &notFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := store.DeleteTag("gts.x.commerce.orders.order.v1~x.commerce._.missing.v1", "a"); !errors.As(err, &notFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	var invalid *InvalidTagError
+	for _, key := range []string{"", "a,b", "#owner", "a=b", "sp ace"} {
+		if err := store.SetTags(tagTestOrderA, map[string]string{key: "x"}); !errors.As(err, &invalid) {
+			t.Errorf("Expected invalid tag error for %q, got %v", key, err)
+		}
+	}
+
+	store.Freeze()
+	var frozen *StoreFrozenError
+	if err := store.SetTags(tagTestOrderA, map[string]string{"a": "b"}); !errors.As(err, &frozen) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := store.DeleteTag(tagTestOrderA, "a"); !errors.As(err, &frozen) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+}
+
+func TestQuery_TagFilters(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{AllowUnfreeze: true}, tagTestEntities()...)
+	if err := store.SetTags(tagTestOrderA, map[string]string{"owner": "payments-team"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+	if err := store.SetTags(tagTestOrderB, map[string]string{"owner": "payments-team", "env": "staging"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+	if err := store.SetTags(tagTestSchemaID, map[string]string{"owner": "platform"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{"gts.x.commerce.*[#owner=payments-team]", []string{tagTestOrderA, tagTestOrderB}},
+		{"gts.x.commerce.*[#owner=payments-team, status=active]", []string{tagTestOrderA}},
+		{"gts.x.commerce.*[#owner=platform]", []string{tagTestSchemaID}},
+		{"gts.x.commerce.*[#env=*]", []string{tagTestOrderB}},
+		{"gts.x.commerce.*[#status=active]", nil},
+		{"gts.x.commerce.*[status=active]", []string{tagTestOrderA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := queryIDs(t, store, tt.expr); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTags_ExportRoundTrip(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{AllowUnfreeze: true}, tagTestEntities()...)
+	if err := store.SetTags(tagTestOrderA, map[string]string{"owner": "payments-team"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+	if err := store.SetTags(tagTestSchemaID, map[string]string{"review": "approved"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	if _, err := store.ExportTree("", dir); err != nil {
+		t.Fatalf("ExportTree failed: %v", err)
+	}
+
+	loaded := NewGtsStore(NewGtsFileReader([]string{dir}, nil))
+	restored, err := loaded.RestoreManifestTags(dir)
+	if err != nil {
+		t.Fatalf("RestoreManifestTags failed: %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("Expected 2 tagged entities restored, got %d", restored)
+	}
+	for _, id := range []string{tagTestOrderA, tagTestOrderB, tagTestSchemaID} {
+		if got, want := loaded.GetTags(id), store.GetTags(id); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected tags %v for %s, got %v", want, id, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// changedIDs returns the IDs of ChangedSince(t), in its order
+func changedIDs(store *GtsStore, t time.Time) []string {
+	ids := []string{}
+	for _, info := range store.ChangedSince(t) {
+		ids = append(ids, info.ID)
+	}
+	return ids
+}
+
+func TestTimestamps_RegisterAndOverwrite(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := t0
+	store := NewGtsStore(nil)
+	store.now = func() time.Time { return now }
+
+	register := func(content map[string]any) {
+		t.Helper()
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+	}
+	register(map[string]any{"$id": "gts://" + tagTestSchemaID, "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"})
+	now = t0.Add(time.Minute)
+	register(map[string]any{"id": tagTestOrderA, "status": "active"})
+	now = t0.Add(2 * time.Minute)
+	register(map[string]any{"id": tagTestOrderB, "status": "active"})
+
+	a := store.Get(tagTestOrderA)
+	if !a.RegisteredAt.Equal(t0.Add(time.Minute)) || !a.UpdatedAt.Equal(a.RegisteredAt) {
+		t.Errorf("Expected %s to be registered and updated at %v, got %v and %v", tagTestOrderA, t0.Add(time.Minute), a.RegisteredAt, a.UpdatedAt)
+	}
+
+	// Overwriting keeps RegisteredAt and moves UpdatedAt
+	now = t0.Add(3 * time.Minute)
+	register(map[string]any{"id": tagTestOrderA, "status": "inactive"})
+	a = store.Get(tagTestOrderA)
+	if !a.RegisteredAt.Equal(t0.Add(time.Minute)) || !a.UpdatedAt.Equal(now) {
+		t.Errorf("Expected the overwrite to keep RegisteredAt and set UpdatedAt, got %v and %v", a.RegisteredAt, a.UpdatedAt)
+	}
+
+	// The bound is inclusive and results are ordered by UpdatedAt
+	tests := []struct {
+		since time.Time
+		ids   []string
+	}{
+		{t0, []string{tagTestSchemaID, tagTestOrderB, tagTestOrderA}},
+		{t0.Add(time.Minute), []string{tagTestOrderB, tagTestOrderA}},
+		{t0.Add(2 * time.Minute), []string{tagTestOrderB, tagTestOrderA}},
+		{t0.Add(2*time.Minute + time.Nanosecond), []string{tagTestOrderA}},
+		{t0.Add(3 * time.Minute), []string{tagTestOrderA}},
+		{t0.Add(4 * time.Minute), []string{}},
+	}
+	for _, tt := range tests {
+		if ids := changedIDs(store, tt.since); !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("ChangedSince(%v): expected %v, got %v", tt.since, tt.ids, ids)
+		}
+	}
+
+	for _, info := range store.List(10).Entities {
+		if info.ID == tagTestOrderA && (!info.RegisteredAt.Equal(a.RegisteredAt) || !info.UpdatedAt.Equal(a.UpdatedAt)) {
+			t.Errorf("Expected List to report the timestamps of %s, got %+v", tagTestOrderA, info)
+		}
+	}
+}
+
+func TestTimestamps_ExportReload(t *testing.T) {
+	// The fixture is registered with the real clock; the overwrite happens an hour later
+	source := newExportFixtureStore(t)
+	t0 := time.Now().UTC()
+	now := t0.Add(time.Hour)
+	source.now = func() time.Time { return now }
+	const id = "gts.acme.billing.events.invoice.v1~acme.billing.invoices.inv_1.v1.0"
+	if err := source.Register(source.Get(id)); err != nil {
+		t.Fatalf("Failed to overwrite entity: %v", err)
+	}
+	registeredAt := source.Get(id).RegisteredAt
+
+	dir := t.TempDir()
+	if _, err := source.ExportTree("", dir); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	// Reloading the tree keeps the exported timestamps instead of stamping the load time
+	loaded := NewGtsStore(NewGtsFileReaderFromPath(dir, nil))
+	entity := loaded.Get(id)
+	if entity == nil {
+		t.Fatalf("Expected %s to be loaded", id)
+	}
+	if !entity.RegisteredAt.Equal(registeredAt) || !entity.UpdatedAt.Equal(t0.Add(time.Hour)) {
+		t.Errorf("Expected the exported timestamps %v and %v, got %v and %v", registeredAt, t0.Add(time.Hour), entity.RegisteredAt, entity.UpdatedAt)
+	}
+	if ids := changedIDs(loaded, t0.Add(time.Hour)); !reflect.DeepEqual(ids, []string{id}) {
+		t.Errorf("Expected only %s to have changed since the overwrite, got %v", id, ids)
+	}
+
+	// Files without a manifest are stamped with the load time
+	before := time.Now()
+	plain := NewGtsStore(NewGtsFileReaderFromPath(dir+"/acme", nil))
+	if entity := plain.Get(id); entity == nil || entity.RegisteredAt.Before(before) || !entity.UpdatedAt.Equal(entity.RegisteredAt) {
+		t.Errorf("Expected %s to be stamped with the load time, got %+v", id, entity)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TypeVersion describes one version of a type line
+type TypeVersion struct {
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	Major     int    `json:"major"`
+	Minor     *int   `json:"minor,omitempty"`
+	Schemas   int    `json:"schemas"`
+	Instances int    `json:"instances"`
+}
+
+// TypeSummary aggregates every version of a type line, i.e. the type IDs whose last segment has the
+// same vendor.package.namespace.type, whatever their chained prefix
+type TypeSummary struct {
+	Type         string        `json:"type"`
+	Versions     []TypeVersion `json:"versions"`
+	LatestID     string        `json:"latest_id"`
+	Schemas      int           `json:"schemas"`
+	Instances    int           `json:"instances"`
+	DerivedTypes []string      `json:"derived_types"`
+}
+
+// TypeSummariesResult holds the type lines returned by TypeSummaries
+type TypeSummariesResult struct {
+	Error string        `json:"error,omitempty"`
+	Count int           `json:"count"`
+	Types []TypeSummary `json:"types"`
+}
+
+// TypeSummaries groups the registered types into type lines with their sorted versions, the latest
+// version ID, schema and instance counts per version and the IDs of directly derived types.
+// Instances are counted against their schema ID; a type referenced only by instances is listed with
+// zero schemas. When pattern is not empty only type IDs matching it (exact or wildcard) are included.
+// Type lines are sorted by name and versions by major, then minor version.
+func (s *GtsStore) TypeSummaries(pattern string) *TypeSummariesResult {
+	result := &TypeSummariesResult{Types: make([]TypeSummary, 0)}
+
+	var patternID *GtsID
+	if pattern = strings.TrimSpace(pattern); pattern != "" {
+		if err := s.validateQueryPattern(pattern, strings.Contains(pattern, "*")); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		var err error
+		if patternID, err = parsePattern(pattern); err != nil {
+			result.Error = fmt.Sprintf("Invalid query: %v", err)
+			return result
+		}
+	}
+
+	versions := make(map[string]*TypeVersion)
+	typeIDs := make(map[string]*GtsID)
+	// version returns the entry of a type ID, creating it on first use
+	version := func(id *GtsID) *TypeVersion {
+		if v, ok := versions[id.ID]; ok {
+			return v
+		}
+		last := id.Segments[len(id.Segments)-1]
+		v := &TypeVersion{ID: id.ID, Version: segmentVersion(last), Major: last.VerMajor, Minor: last.VerMinor}
+		versions[id.ID] = v
+		typeIDs[id.ID] = id
+		return v
+	}
+
+	parsedSchemaIDs := make(map[string]*GtsID)
+	for _, entity := range s.entitySnapshot() {
+		if entity.GtsID == nil {
+			continue
+		}
+		if entity.IsSchema {
+			if entity.GtsID.IsType() {
+				version(entity.GtsID).Schemas++
+			}
+			continue
+		}
+
+		// Instances count against their resolved schema, or the schema ID they name when unresolved
+		resolved, _, _ := resolveInstanceSchemaWith(entity, s.registered)
+		if resolved == "" {
+			resolved = entity.SchemaID
+		}
+		schemaID, ok := parsedSchemaIDs[resolved]
+		if !ok {
+			schemaID, _ = NewGtsID(resolved)
+			parsedSchemaIDs[resolved] = schemaID
+		}
+		if schemaID != nil && schemaID.IsType() {
+			version(schemaID).Instances++
+		}
+	}
+
+	lines := make(map[string]*TypeSummary)
+	derived := make(map[string]map[string]bool)
+	for id, gtsID := range typeIDs {
+		// Derived types are recorded against the line of their parent, whether or not the parent matches
+		if len(gtsID.Segments) > 1 {
+			parentID := id[:gtsID.Segments[len(gtsID.Segments)-1].Offset]
+			if parent, ok := typeIDs[parentID]; ok {
+				parentLine := typeLineName(parent)
+				if derived[parentLine] == nil {
+					derived[parentLine] = make(map[string]bool)
+				}
+				derived[parentLine][id] = true
+			}
+		}
+
+		if patternID != nil && !s.matchesIDPattern(gtsID, patternID) {
+			continue
+		}
+		name := typeLineName(gtsID)
+		line, ok := lines[name]
+		if !ok {
+			line = &TypeSummary{Type: name, DerivedTypes: make([]string, 0)}
+			lines[name] = line
+		}
+		v := versions[id]
+		line.Versions = append(line.Versions, *v)
+		line.Schemas += v.Schemas
+		line.Instances += v.Instances
+	}
+
+	for name, line := range lines {
+		sort.Slice(line.Versions, func(i, j int) bool {
+			return compareTypeVersions(line.Versions[i], line.Versions[j]) < 0
+		})
+		line.LatestID = line.Versions[len(line.Versions)-1].ID
+		for id := range derived[name] {
+			line.DerivedTypes = append(line.DerivedTypes, id)
+		}
+		sort.Strings(line.DerivedTypes)
+		result.Types = append(result.Types, *line)
+	}
+	sort.Slice(result.Types, func(i, j int) bool {
+		return result.Types[i].Type < result.Types[j].Type
+	})
+
+	result.Count = len(result.Types)
+	return result
+}
+
+// typeLineName returns the vendor.package.namespace.type of the last segment of a type ID
+func typeLineName(id *GtsID) string {
+	last := id.Segments[len(id.Segments)-1]
+	return strings.Join([]string{last.Vendor, last.Package, last.Namespace, last.Type}, ".")
+}
+
+// segmentVersion formats the version of a segment as vMAJOR[.MINOR]
+func segmentVersion(seg *GtsIDSegment) string {
+	version := "v" + strconv.Itoa(seg.VerMajor)
+	if seg.VerMinor != nil {
+		version += "." + strconv.Itoa(*seg.VerMinor)
+	}
+	return version
+}
+
+// compareTypeVersions orders versions by major, then minor (no minor first), then ID
+func compareTypeVersions(a, b TypeVersion) int {
+	if a.Major != b.Major {
+		return a.Major - b.Major
+	}
+	aMinor, bMinor := -1, -1
+	if a.Minor != nil {
+		aMinor = *a.Minor
+	}
+	if b.Minor != nil {
+		bMinor = *b.Minor
+	}
+	if aMinor != bMinor {
+		return aMinor - bMinor
+	}
+	return strings.Compare(a.ID, b.ID)
+}
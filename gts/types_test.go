@@ -0,0 +1,144 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTypeSummaryFixtureStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	schema := func(id string) map[string]any {
+		return map[string]any{
+			"$id":     "gts://" + id,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		}
+	}
+	entities := []map[string]any{
+		schema("gts.x.core.events.type.v1~"),
+		schema("gts.x.core.events.type.v1.1~"),
+		schema("gts.x.core.events.type.v2~"),
+		schema("gts.x.core.events.type.v1~acme.shop.orders.placed.v1.0~"),
+		schema("gts.x.core.events.type.v1~acme.shop.orders.placed.v1.1~"),
+		schema("gts.x.core.events.type.v2~acme.shop.orders.placed.v2.0~"),
+		{"id": "gts.x.core.events.type.v1~acme.shop.events.started.v1.0"},
+		{"id": "gts.x.core.events.type.v2~acme.shop.events.started.v1.0"},
+		{"id": "gts.x.core.events.type.v2~acme.shop.events.stopped.v1.0"},
+		{"id": "gts.x.core.events.type.v1~acme.shop.orders.placed.v1.1~acme.shop.orders.o_1.v1.0"},
+		// Instance of a type without a registered schema
+		{"id": "gts.x.core.audit.log.v1~acme.shop.audit.entry.v1.0"},
+	}
+	registerTestEntities(t, store, entities...)
+
+	// Anonymous instances have no GTS ID, so they cannot be registered and are not counted
+	anonymous := NewJsonEntity(map[string]any{
+		"id":   "7a1d2f34-5b6c-4d7e-8f90-a1b2c3d4e5f6",
+		"type": "gts.x.core.events.type.v1~",
+	}, DefaultGtsConfig())
+	if err := store.Register(anonymous); err == nil {
+		t.Fatal("Expected anonymous instance registration to fail")
+	}
+	return store
+}
+
+func findTypeSummary(t *testing.T, result *TypeSummariesResult, name string) TypeSummary {
+	t.Helper()
+	for _, summary := range result.Types {
+		if summary.Type == name {
+			return summary
+		}
+	}
+	t.Fatalf("Type line %s not found in %+v", name, result.Types)
+	return TypeSummary{}
+}
+
+func TestTypeSummaries(t *testing.T) {
+	store := newTypeSummaryFixtureStore(t)
+
+	result := store.TypeSummaries("")
+	if result.Error != "" {
+		t.Fatalf("Unexpected error: %s", result.Error)
+	}
+	if result.Count != 3 {
+		t.Fatalf("Expected 3 type lines, got %d: %+v", result.Count, result.Types)
+	}
+	if result.Types[0].Type != "acme.shop.orders.placed" || result.Types[2].Type != "x.core.events.type" {
+		t.Errorf("Expected type lines sorted by name, got %+v", result.Types)
+	}
+
+	base := findTypeSummary(t, result, "x.core.events.type")
+	var ids []string
+	for _, v := range base.Versions {
+		ids = append(ids, v.ID)
+	}
+	expected := []string{"gts.x.core.events.type.v1~", "gts.x.core.events.type.v1.1~", "gts.x.core.events.type.v2~"}
+	if !slices.Equal(ids, expected) {
+		t.Errorf("Expected versions %v, got %v", expected, ids)
+	}
+	if base.LatestID != "gts.x.core.events.type.v2~" {
+		t.Errorf("Expected latest v2, got %s", base.LatestID)
+	}
+	if base.Schemas != 3 || base.Instances != 3 {
+		t.Errorf("Expected 3 schemas and 3 instances, got %d and %d", base.Schemas, base.Instances)
+	}
+	if base.Versions[0].Instances != 1 || base.Versions[1].Instances != 0 || base.Versions[2].Instances != 2 {
+		t.Errorf("Unexpected per-version instance counts: %+v", base.Versions)
+	}
+	if base.Versions[1].Version != "v1.1" || *base.Versions[1].Minor != 1 || base.Versions[2].Minor != nil {
+		t.Errorf("Unexpected version fields: %+v", base.Versions)
+	}
+	expectedDerived := []string{
+		"gts.x.core.events.type.v1~acme.shop.orders.placed.v1.0~",
+		"gts.x.core.events.type.v1~acme.shop.orders.placed.v1.1~",
+		"gts.x.core.events.type.v2~acme.shop.orders.placed.v2.0~",
+	}
+	if !slices.Equal(base.DerivedTypes, expectedDerived) {
+		t.Errorf("Expected derived types %v, got %v", expectedDerived, base.DerivedTypes)
+	}
+
+	// Derived types are grouped across their chained prefixes
+	derived := findTypeSummary(t, result, "acme.shop.orders.placed")
+	if len(derived.Versions) != 3 || derived.LatestID != "gts.x.core.events.type.v2~acme.shop.orders.placed.v2.0~" {
+		t.Errorf("Unexpected derived type line: %+v", derived)
+	}
+	if derived.Versions[1].Instances != 1 || derived.Instances != 1 || len(derived.DerivedTypes) != 0 {
+		t.Errorf("Expected one instance of placed v1.1, got %+v", derived)
+	}
+
+	orphan := findTypeSummary(t, result, "x.core.audit.log")
+	if orphan.Schemas != 0 || orphan.Instances != 1 {
+		t.Errorf("Expected type known only from its instance, got %+v", orphan)
+	}
+}
+
+func TestTypeSummaries_Pattern(t *testing.T) {
+	store := newTypeSummaryFixtureStore(t)
+
+	result := store.TypeSummaries("gts.x.core.events.type.v1~*")
+	if result.Error != "" {
+		t.Fatalf("Unexpected error: %s", result.Error)
+	}
+	if result.Count != 1 || result.Types[0].Type != "acme.shop.orders.placed" || len(result.Types[0].Versions) != 2 {
+		t.Errorf("Expected the two placed versions derived from v1, got %+v", result.Types)
+	}
+
+	// Exact patterns follow Query semantics and also match types chained under the ID
+	result = store.TypeSummaries("gts.x.core.events.type.v2~")
+	if result.Count != 2 {
+		t.Fatalf("Expected the v2 base line and its derived line, got %+v", result.Types)
+	}
+	base := findTypeSummary(t, result, "x.core.events.type")
+	if len(base.Versions) != 1 || base.LatestID != "gts.x.core.events.type.v2~" || len(base.DerivedTypes) != 3 {
+		t.Errorf("Expected only v2 with the line's derived types, got %+v", base)
+	}
+
+	if result := store.TypeSummaries("gts.x.*.events"); result.Error == "" {
+		t.Error("Expected error for invalid pattern")
+	}
+}
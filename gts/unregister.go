@@ -0,0 +1,93 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// EntityReferencedError is returned by Unregister when, with strict reference validation, other
+// registered entities still reference the entity to remove
+type EntityReferencedError struct {
+	EntityID     string
+	ReferencedBy []string
+}
+
+func (e *EntityReferencedError) Error() string {
+	return fmt.Sprintf("Entity %s is still referenced by %d entities: %s",
+		e.EntityID, len(e.ReferencedBy), strings.Join(e.ReferencedBy, ", "))
+}
+
+// Unregister removes a registered entity with its short ID and tags. With strict reference
+// validation (see RegistryConfig.RefValidation) it refuses to remove an entity other entities
+// reference, as their schema, parent type or in their content, with EntityReferencedError; in
+// warn mode the referencing entities are logged. An ID that is not registered returns
+// StoreGtsObjectNotFoundError. The reader is not consulted, so an entity it provides may be read
+// again by a later lookup.
+func (s *GtsStore) Unregister(id string) error {
+	return s.unregister(id, false)
+}
+
+// ForceUnregister removes a registered entity like Unregister, even if other entities reference it
+func (s *GtsStore) ForceUnregister(id string) error {
+	return s.unregister(id, true)
+}
+
+func (s *GtsStore) unregister(id string, force bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.frozen {
+		return &StoreFrozenError{Operation: "unregister " + id}
+	}
+	if _, ok := s.byID[id]; !ok {
+		return &StoreGtsObjectNotFoundError{EntityID: id}
+	}
+
+	mode := s.config.refValidationMode()
+	if mode != RefValidationOff && !force {
+		if referencedBy := s.referencedByLocked(id); len(referencedBy) > 0 {
+			if mode == RefValidationStrict {
+				return &EntityReferencedError{EntityID: id, ReferencedBy: referencedBy}
+			}
+			log.Printf("Warning: unregistering %s, still referenced by %s", id, strings.Join(referencedBy, ", "))
+		}
+	}
+
+	if err := s.unpersistLocked(id); err != nil {
+		return err
+	}
+	s.removeLocked(id)
+	log.Printf("Unregistered entity: %s", id)
+	return nil
+}
+
+// referencedByLocked returns the sorted IDs of the registered entities referencing id (see
+// pruneReferenceTargets); s.mu must be held
+func (s *GtsStore) referencedByLocked(id string) []string {
+	var referencedBy []string
+	for otherID, entity := range s.byID {
+		if otherID == id {
+			continue
+		}
+		schemaID := ""
+		if !entity.IsSchema {
+			if schemaID, _, _ = s.resolveInstanceSchemaLocked(entity); schemaID == "" {
+				schemaID = entity.SchemaID
+			}
+		}
+		for _, target := range pruneReferenceTargets(entity, schemaID) {
+			if target == id {
+				referencedBy = append(referencedBy, otherID)
+				break
+			}
+		}
+	}
+	sort.Strings(referencedBy)
+	return referencedBy
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const unregisterSchemaID = "gts.x.test.unreg.item.v1~"
+
+// unregisterTestEntities are a schema with two instances
+func unregisterTestEntities() []map[string]any {
+	return []map[string]any{
+		testSchema(unregisterSchemaID, nil),
+		{"id": unregisterSchemaID + "x.test._.b.v1"},
+		{"id": unregisterSchemaID + "x.test._.a.v1"},
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	store := newTestStore(t, nil, unregisterTestEntities()...)
+	id := unregisterSchemaID + "x.test._.a.v1"
+	if err := store.SetTags(id, map[string]string{"owner": "team"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+
+	if err := store.Unregister(id); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	if store.Get(id) != nil || store.Count() != 2 || len(store.GetTags(id)) != 0 {
+		t.Error("Expected the entity and its tags to be removed")
+	}
+	if _, err := store.FindByShortID(shortIDOf(id)); err == nil {
+		t.Error("Expected the short ID to be removed")
+	}
+
+	var notFound *StoreGtsObjectNotFoundError
+	if err := store.Unregister(id); !errors.As(err, &notFound) {
+		t.Errorf("Expected StoreGtsObjectNotFoundError for a removed ID, got %v", err)
+	}
+
+	// References are only checked with reference validation
+	if err := store.Unregister(unregisterSchemaID); err != nil {
+		t.Errorf("Expected a referenced schema to be removed without reference validation, got %v", err)
+	}
+}
+
+func TestUnregister_Referenced(t *testing.T) {
+	store := newTestStore(t, &RegistryConfig{RefValidation: RefValidationStrict}, unregisterTestEntities()...)
+
+	err := store.Unregister(unregisterSchemaID)
+	var referenced *EntityReferencedError
+	if !errors.As(err, &referenced) {
+		t.Fatalf("Expected EntityReferencedError, got %v", err)
+	}
+	expected := []string{unregisterSchemaID + "x.test._.a.v1", unregisterSchemaID + "x.test._.b.v1"}
+	if !reflect.DeepEqual(referenced.ReferencedBy, expected) {
+		t.Errorf("Expected the referencing instances %v, got %v", expected, referenced.ReferencedBy)
+	}
+	if store.Get(unregisterSchemaID) == nil {
+		t.Error("Expected the refused schema to stay registered")
+	}
+
+	if err := store.Unregister(expected[0]); err != nil {
+		t.Errorf("Expected an unreferenced instance to be removed, got %v", err)
+	}
+	if err := store.ForceUnregister(unregisterSchemaID); err != nil || store.Get(unregisterSchemaID) != nil {
+		t.Errorf("Expected ForceUnregister to remove the referenced schema, got %v", err)
+	}
+}
+
+func TestUnregister_Frozen(t *testing.T) {
+	store := newTestStore(t, nil, unregisterTestEntities()...)
+	store.Freeze()
+	var frozen *StoreFrozenError
+	if err := store.Unregister(unregisterSchemaID); !errors.As(err, &frozen) {
+		t.Errorf("Expected StoreFrozenError, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"github.com/google/uuid"
+)
+
+// GetByUUID returns the registered entity whose ID derives the UUID u (see IDToUUID), or nil when
+// u is not a valid UUID or no registered ID derives it. With RegistryConfig.IndexUUIDs the lookup
+// uses the store's UUID index, otherwise the UUID of every registered ID is derived. The reader is
+// not consulted, as it cannot be searched by UUID.
+func (s *GtsStore) GetByUUID(u string) *JsonEntity {
+	parsed, err := uuid.Parse(u)
+	if err != nil {
+		return nil
+	}
+	key := parsed.String()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.byUUID != nil {
+		return s.byID[s.byUUID[key]]
+	}
+	for _, entity := range s.byID {
+		if entity.GtsID.ToUUID().String() == key {
+			return entity
+		}
+	}
+	return nil
+}
+
+// indexUUIDLocked records the UUID of a stored ID in the UUID index, if any; s.mu must be held
+// for writing
+func (s *GtsStore) indexUUIDLocked(id *GtsID) {
+	if s.byUUID != nil {
+		s.byUUID[id.ToUUID().String()] = id.ID
+	}
+}
+
+// unindexUUIDLocked removes the UUID of a removed ID from the UUID index, if any; s.mu must be
+// held for writing
+func (s *GtsStore) unindexUUIDLocked(id *GtsID) {
+	if s.byUUID == nil {
+		return
+	}
+	key := id.ToUUID().String()
+	if s.byUUID[key] == id.ID {
+		delete(s.byUUID, key)
+	}
+}
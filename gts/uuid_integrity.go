@@ -0,0 +1,241 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DefaultUUIDVerifyMapLimit is the number of registered IDs up to which VerifyUUIDIntegrity
+// detects collisions with an in-memory UUID map (see RegistryConfig.UUIDVerifyMapLimit)
+const DefaultUUIDVerifyMapLimit = 1_000_000
+
+// UUID verification strategies reported by VerifyUUIDIntegrity
+const (
+	// UUIDVerifyStrategyMap keeps the UUID of every ID in a map
+	UUIDVerifyStrategyMap = "map"
+	// UUIDVerifyStrategyBloom keeps a bloom filter of the UUIDs and confirms its hits in a second pass
+	UUIDVerifyStrategyBloom = "bloom"
+)
+
+// uuidForID derives the UUID of a registered ID for VerifyUUIDIntegrity; tests replace it to
+// force collisions
+var uuidForID = func(id *GtsID) uuid.UUID {
+	return id.ToUUID()
+}
+
+// UUIDIntegrityReport is the outcome of VerifyUUIDIntegrity
+type UUIDIntegrityReport struct {
+	// OK is set when there are neither collisions nor anomalies
+	OK       bool              `json:"ok"`
+	Total    int               `json:"total"`
+	Strategy string            `json:"strategy"`
+	Vendors  []VendorUUIDStats `json:"vendors"`
+	// Collisions lists the UUIDs shared by different IDs, sorted by UUID
+	Collisions []UUIDCollision `json:"collisions"`
+	// Anomalies lists the registered IDs that do not re-parse to themselves, sorted by ID
+	Anomalies []UUIDAnomaly `json:"anomalies"`
+}
+
+// VendorUUIDStats counts the verified IDs of a vendor (the vendor of the first segment)
+type VendorUUIDStats struct {
+	Vendor     string `json:"vendor"`
+	IDs        int    `json:"ids"`
+	Collisions int    `json:"collisions"`
+	Anomalies  int    `json:"anomalies"`
+}
+
+// UUIDCollision is a UUID derived from more than one ID
+type UUIDCollision struct {
+	UUID string   `json:"uuid"`
+	IDs  []string `json:"ids"`
+}
+
+// UUIDAnomaly is a registered ID that is not in the canonical form its UUID is derived from
+type UUIDAnomaly struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// VerifyUUIDIntegrity derives the UUID of every registered ID and reports UUIDs shared by
+// different IDs, along with IDs that are not canonical: each ID is re-parsed with NewGtsID and
+// must come back unchanged, which rules out IDs differing only by case or surrounding whitespace
+// since those do not parse. Totals are reported per vendor.
+//
+// Stores with up to RegistryConfig.UUIDVerifyMapLimit IDs are checked with a UUID map. Larger
+// stores are checked without holding every UUID: a first pass adds the UUIDs to a bloom filter
+// and remembers those it may already contain, and a second pass collects the IDs of the
+// remembered UUIDs, confirming or dismissing each candidate collision.
+func (s *GtsStore) VerifyUUIDIntegrity() *UUIDIntegrityReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := &UUIDIntegrityReport{
+		Total:      len(s.byID),
+		Strategy:   UUIDVerifyStrategyMap,
+		Vendors:    []VendorUUIDStats{},
+		Collisions: []UUIDCollision{},
+		Anomalies:  []UUIDAnomaly{},
+	}
+	vendors := make(map[string]*VendorUUIDStats)
+	vendorOf := func(id *GtsID) *VendorUUIDStats {
+		vendor := ""
+		if len(id.Segments) > 0 {
+			vendor = id.Segments[0].Vendor
+		}
+		stats, ok := vendors[vendor]
+		if !ok {
+			stats = &VendorUUIDStats{Vendor: vendor}
+			vendors[vendor] = stats
+		}
+		return stats
+	}
+
+	// ids lists the IDs sharing each colliding UUID
+	var ids map[uuid.UUID][]string
+	limit := s.config.UUIDVerifyMapLimit
+	if limit <= 0 {
+		limit = DefaultUUIDVerifyMapLimit
+	}
+
+	for key, entity := range s.byID {
+		if entity.GtsID == nil {
+			continue
+		}
+		stats := vendorOf(entity.GtsID)
+		stats.IDs++
+		if reason := uuidAnomaly(key, entity.GtsID); reason != "" {
+			report.Anomalies = append(report.Anomalies, UUIDAnomaly{ID: key, Reason: reason})
+			stats.Anomalies++
+		}
+	}
+
+	if len(s.byID) <= limit {
+		seen := make(map[uuid.UUID]string, len(s.byID))
+		ids = make(map[uuid.UUID][]string)
+		for _, entity := range s.byID {
+			if entity.GtsID == nil {
+				continue
+			}
+			u := uuidForID(entity.GtsID)
+			if first, ok := seen[u]; ok {
+				if first == entity.GtsID.ID {
+					continue
+				}
+				if len(ids[u]) == 0 {
+					ids[u] = []string{first}
+				}
+				ids[u] = append(ids[u], entity.GtsID.ID)
+				continue
+			}
+			seen[u] = entity.GtsID.ID
+		}
+	} else {
+		report.Strategy = UUIDVerifyStrategyBloom
+		filter := newUUIDBloomFilter(len(s.byID))
+		candidates := make(map[uuid.UUID][]string)
+		for _, entity := range s.byID {
+			if entity.GtsID == nil {
+				continue
+			}
+			u := uuidForID(entity.GtsID)
+			if filter.addAndCheck(u) {
+				candidates[u] = nil
+			}
+		}
+		for _, entity := range s.byID {
+			if entity.GtsID == nil {
+				continue
+			}
+			u := uuidForID(entity.GtsID)
+			if shared, ok := candidates[u]; ok && !slices.Contains(shared, entity.GtsID.ID) {
+				candidates[u] = append(shared, entity.GtsID.ID)
+			}
+		}
+		ids = make(map[uuid.UUID][]string)
+		for u, shared := range candidates {
+			// Bloom filter false positives have a single distinct ID
+			if len(shared) > 1 {
+				ids[u] = shared
+			}
+		}
+	}
+
+	for u, shared := range ids {
+		sort.Strings(shared)
+		report.Collisions = append(report.Collisions, UUIDCollision{UUID: u.String(), IDs: shared})
+		for _, id := range shared {
+			if entity := s.byID[id]; entity != nil {
+				vendorOf(entity.GtsID).Collisions++
+			}
+		}
+	}
+
+	sort.Slice(report.Collisions, func(i, j int) bool { return report.Collisions[i].UUID < report.Collisions[j].UUID })
+	sort.Slice(report.Anomalies, func(i, j int) bool { return report.Anomalies[i].ID < report.Anomalies[j].ID })
+	for _, stats := range vendors {
+		report.Vendors = append(report.Vendors, *stats)
+	}
+	sort.Slice(report.Vendors, func(i, j int) bool { return report.Vendors[i].Vendor < report.Vendors[j].Vendor })
+	report.OK = len(report.Collisions) == 0 && len(report.Anomalies) == 0
+	return report
+}
+
+// uuidAnomaly explains why a registered ID is not canonical, or returns ""
+func uuidAnomaly(key string, id *GtsID) string {
+	if key != id.ID {
+		return fmt.Sprintf("registered under '%s' but its ID is '%s'", key, id.ID)
+	}
+	if strings.TrimSpace(key) != key || strings.ToLower(key) != key {
+		return "ID has surrounding whitespace or uppercase characters"
+	}
+	parsed, err := NewGtsID(key)
+	if err != nil {
+		return fmt.Sprintf("ID does not re-parse: %v", err)
+	}
+	if parsed.ID != key {
+		return fmt.Sprintf("ID re-parses as '%s'", parsed.ID)
+	}
+	return ""
+}
+
+// uuidBloomFilter is a bloom filter of UUIDs sized for about 1% false positives
+type uuidBloomFilter struct {
+	bits []uint64
+	k    int
+}
+
+func newUUIDBloomFilter(n int) *uuidBloomFilter {
+	words := (n*10 + 63) / 64
+	if words < 1 {
+		words = 1
+	}
+	return &uuidBloomFilter{bits: make([]uint64, words), k: 7}
+}
+
+// addAndCheck adds a UUID and reports whether the filter may already have held it. The UUID is
+// itself a hash, so its halves serve as the two hashes of double hashing.
+func (f *uuidBloomFilter) addAndCheck(u uuid.UUID) bool {
+	h1 := binary.BigEndian.Uint64(u[:8])
+	h2 := binary.BigEndian.Uint64(u[8:]) | 1
+	m := uint64(len(f.bits) * 64)
+	present := true
+	for i := 0; i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			present = false
+			f.bits[word] |= mask
+		}
+	}
+	return present
+}
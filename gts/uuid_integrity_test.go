@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newUUIDIntegrityStore(t *testing.T, mapLimit int) *GtsStore {
+	t.Helper()
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{UUIDVerifyMapLimit: mapLimit})
+	for _, id := range []string{"gts.x.core.events.type.v1~", "gts.acme.billing.events.invoice.v1~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("Failed to register %s: %v", id, err)
+		}
+	}
+	if err := store.Register(NewJsonEntity(map[string]any{"id": "gts.x.core.events.type.v1~x.core._.order.v1"}, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+	return store
+}
+
+func TestVerifyUUIDIntegrity_Clean(t *testing.T) {
+	for _, limit := range []int{0, 1} {
+		report := newUUIDIntegrityStore(t, limit).VerifyUUIDIntegrity()
+		if !report.OK || len(report.Collisions) != 0 || len(report.Anomalies) != 0 {
+			t.Errorf("Expected a clean report with limit %d, got %+v", limit, report)
+		}
+		expected := []VendorUUIDStats{{Vendor: "acme", IDs: 1}, {Vendor: "x", IDs: 2}}
+		if report.Total != 3 || !reflect.DeepEqual(report.Vendors, expected) {
+			t.Errorf("Expected 3 IDs with totals %v, got %d and %v", expected, report.Total, report.Vendors)
+		}
+	}
+}
+
+func TestVerifyUUIDIntegrity_Collision(t *testing.T) {
+	derive := uuidForID
+	shared := uuid.MustParse("00000000-0000-5000-8000-000000000001")
+	uuidForID = func(id *GtsID) uuid.UUID {
+		if id.Segments[0].Vendor == "x" {
+			return shared
+		}
+		return derive(id)
+	}
+	defer func() { uuidForID = derive }()
+
+	tests := []struct {
+		limit    int
+		strategy string
+	}{
+		{0, UUIDVerifyStrategyMap},
+		{1, UUIDVerifyStrategyBloom},
+	}
+	for _, tt := range tests {
+		report := newUUIDIntegrityStore(t, tt.limit).VerifyUUIDIntegrity()
+		if report.Strategy != tt.strategy {
+			t.Errorf("Expected the %s strategy with limit %d, got %s", tt.strategy, tt.limit, report.Strategy)
+		}
+		expected := []UUIDCollision{{
+			UUID: shared.String(),
+			IDs:  []string{"gts.x.core.events.type.v1~", "gts.x.core.events.type.v1~x.core._.order.v1"},
+		}}
+		if report.OK || !reflect.DeepEqual(report.Collisions, expected) {
+			t.Errorf("Expected collision %v with the %s strategy, got %+v", expected, tt.strategy, report)
+		}
+		if report.Vendors[1].Vendor != "x" || report.Vendors[1].Collisions != 2 || report.Vendors[0].Collisions != 0 {
+			t.Errorf("Expected the collision to be counted for vendor x, got %v", report.Vendors)
+		}
+	}
+}
+
+func TestVerifyUUIDIntegrity_Anomaly(t *testing.T) {
+	store := newUUIDIntegrityStore(t, 0)
+	// Entities only reach byID through parsing, so a non-canonical key has to be injected
+	store.mu.Lock()
+	store.byID["gts.x.core.events.type.v1~ "] = store.byID["gts.x.core.events.type.v1~"]
+	store.mu.Unlock()
+
+	report := store.VerifyUUIDIntegrity()
+	if report.OK || len(report.Anomalies) != 1 || report.Anomalies[0].ID != "gts.x.core.events.type.v1~ " {
+		t.Errorf("Expected one anomaly for the padded key, got %+v", report.Anomalies)
+	}
+}
@@ -6,10 +6,12 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
 )
 
 // gtsURLLoader implements jsonschema.URLLoader for GTS ID reference resolution
@@ -43,11 +45,56 @@ type ValidationResult struct {
 	ID    string `json:"id"`
 	OK    bool   `json:"ok"`
 	Error string `json:"error"`
+	// Err is the error behind Error, for callers inspecting its type
+	Err error `json:"-"`
+	// ConflictingSchemaIDs and SchemaIDAdjustment report disagreeing schema-ID fields of the
+	// instance, which decide the schema it was validated against (see JsonEntity)
+	ConflictingSchemaIDs []SchemaIDCandidate `json:"conflicting_schema_ids,omitempty"`
+	SchemaIDAdjustment   string              `json:"schema_id_adjustment,omitempty"`
+	// SchemaID is the schema the instance was validated against and SchemaResolution the sources
+	// consulted to resolve it (see GtsConfig.SchemaResolutionOrder)
+	SchemaID         string           `json:"schema_id,omitempty"`
+	SchemaResolution []ResolutionStep `json:"schema_resolution,omitempty"`
+	// Path, Position and Location locate the failure in the instance's file when the instance
+	// carries a position index (see GtsConfig.RecordPositions); Location is file:line:column
+	Path     string    `json:"path,omitempty"`
+	Position *Position `json:"position,omitempty"`
+	Location string    `json:"location,omitempty"`
+	// Violations lists every JSON Schema and x-gts-ref failure of the instance, while Error joins
+	// them into one message
+	Violations []ValidationViolation `json:"violations,omitempty"`
+}
+
+// ValidationViolation is a single failure of an instance against its schema
+type ValidationViolation struct {
+	// Path is the JSON Pointer of the failing value in the instance, "" for the instance itself
+	Path string `json:"path"`
+	// Keyword is the schema keyword that failed, e.g. required, type, enum or x-gts-ref
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
 }
 
 // ValidateInstance validates an object instance against its schema
-// Returns ValidationResult with ok=true if validation succeeds
-func (s *GtsStore) ValidateInstance(gtsID string) *ValidationResult {
+// Returns ValidationResult with ok=true if validation succeeds; a panic during
+// validation is reported as an internal error in the result
+func (s *GtsStore) ValidateInstance(gtsID string) (result *ValidationResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr := newStoreInternalError("ValidateInstance", r)
+			result = &ValidationResult{
+				ID:    gtsID,
+				OK:    false,
+				Error: internalErr.Error(),
+				Err:   internalErr,
+			}
+		}
+	}()
+
+	return s.validateInstance(gtsID)
+}
+
+// validateInstance is the unguarded implementation of ValidateInstance
+func (s *GtsStore) validateInstance(gtsID string) *ValidationResult {
 	// Parse and validate GTS ID
 	gid, err := NewGtsID(gtsID)
 	if err != nil {
@@ -55,69 +102,136 @@ func (s *GtsStore) ValidateInstance(gtsID string) *ValidationResult {
 			ID:    gtsID,
 			OK:    false,
 			Error: fmt.Sprintf("Invalid GTS ID: %v", err),
+			Err:   err,
 		}
 	}
 
 	// Get the instance from store
 	obj := s.Get(gid.ID)
 	if obj == nil {
-		return &ValidationResult{
-			ID:    gtsID,
-			OK:    false,
-			Error: (&StoreGtsObjectNotFoundError{EntityID: gtsID}).Error(),
-		}
+		return failedValidation(gtsID, &StoreGtsObjectNotFoundError{EntityID: gtsID})
 	}
 
-	// Check if instance has a schema ID
-	if obj.SchemaID == "" {
-		return &ValidationResult{
-			ID:    gtsID,
-			OK:    false,
-			Error: (&StoreGtsSchemaForInstanceNotFoundError{EntityID: gid.ID}).Error(),
+	return s.validateLocated(gtsID, obj, nil)
+}
+
+// ValidateEntity validates an entity that need not be registered, e.g. one parsed from a candidate
+// file, as ValidateInstance validates registered instances: its schema is resolved from its schema
+// sources and its x-gts-ref constraints are checked. The store is not modified. The result's ID is
+// the entity's GTS ID, or its label when it has none.
+func (s *GtsStore) ValidateEntity(entity *JsonEntity) (result *ValidationResult) {
+	id := entity.Label
+	if entity.GtsID != nil {
+		id = entity.GtsID.ID
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr := newStoreInternalError("ValidateEntity", r)
+			result = failedValidation(id, internalErr)
+		}
+	}()
+
+	return s.validateLocated(id, entity, nil)
+}
+
+// validateLocated validates an instance and locates a failure in the instance's file
+// Schemas are compiled through cache, which may be nil.
+func (s *GtsStore) validateLocated(gtsID string, obj *JsonEntity, cache compiledSchemas) *ValidationResult {
+	result := s.validateEntity(gtsID, obj, cache)
+	result.ConflictingSchemaIDs = obj.ConflictingSchemaIDs
+	result.SchemaIDAdjustment = obj.SchemaIDAdjustment
+	if !result.OK && obj.PositionIndex != nil {
+		result.Path = validationErrorPath(result.Err)
+		if pos, ok := obj.PositionIndex.Lookup(result.Path); ok {
+			result.Position = &pos
+			result.Location = obj.SourceLocation(result.Path)
 		}
 	}
+	return result
+}
 
-	// Get the schema from store
-	schemaEntity := s.Get(obj.SchemaID)
-	if schemaEntity == nil {
-		return &ValidationResult{
-			ID:    gtsID,
-			OK:    false,
-			Error: (&StoreGtsSchemaNotFoundError{EntityID: obj.SchemaID}).Error(),
+// validationErrorPath returns the instance path of the first failure behind a validation error:
+// the JSON Pointer of the first JSON Schema error without causes, or the field of the first
+// x-gts-ref failure; "" when the error does not carry one
+func validationErrorPath(err error) string {
+	var schemaErr *jsonschema.ValidationError
+	if errors.As(err, &schemaErr) {
+		for len(schemaErr.Causes) > 0 {
+			schemaErr = schemaErr.Causes[0]
+		}
+		if len(schemaErr.InstanceLocation) == 0 {
+			return ""
 		}
+		tokens := make([]string, len(schemaErr.InstanceLocation))
+		for i, token := range schemaErr.InstanceLocation {
+			tokens[i] = escapeJSONPointer(token)
+		}
+		return "/" + strings.Join(tokens, "/")
+	}
+	var refErr *XGtsRefValidationError
+	if errors.As(err, &refErr) {
+		return refErr.FieldPath
 	}
+	return ""
+}
 
-	if !schemaEntity.IsSchema {
-		return &ValidationResult{
-			ID:    gtsID,
-			OK:    false,
-			Error: fmt.Sprintf("entity '%s' is not a schema", obj.SchemaID),
+// validateEntity validates a registered instance against the schema resolved for it, reporting
+// the resolution in the result
+func (s *GtsStore) validateEntity(gtsID string, obj *JsonEntity, cache compiledSchemas) *ValidationResult {
+	schemaID, trace, err := s.resolveInstanceSchema(obj)
+	var result *ValidationResult
+	if err != nil {
+		result = failedValidation(gtsID, err)
+	} else {
+		result = s.validateAgainstSchema(gtsID, obj, schemaID, cache)
+		result.SchemaID = schemaID
+	}
+	result.SchemaResolution = trace
+	return result
+}
+
+// validateAgainstSchema validates an instance against the registered schema schemaID, resolved
+// to its latest minor version when given without one (see ResolveSchema)
+func (s *GtsStore) validateAgainstSchema(gtsID string, obj *JsonEntity, schemaID string, cache compiledSchemas) *ValidationResult {
+	schemaEntity, err := s.ResolveSchema(schemaID)
+	if err != nil {
+		return failedValidation(gtsID, err)
+	}
+
+	// A misspelled keyword would silently accept the instance
+	if s.config.StrictSchemaKeywords {
+		if findings := CheckSchemaKeywords(schemaEntity.Content); len(findings) > 0 {
+			return failedValidation(gtsID, fmt.Errorf("schema %s: %w", schemaID, schemaKeywordError(findings)))
 		}
 	}
 
 	// Validate the instance against the schema
-	err = s.validateWithSchema(obj.Content, schemaEntity.Content)
+	compiledSchema, err := cache.compile(s, schemaEntity.GtsID.ID, schemaEntity.Content)
 	if err != nil {
-		return &ValidationResult{
-			ID:    gtsID,
-			OK:    false,
-			Error: err.Error(),
-		}
+		return failedValidation(gtsID, err)
 	}
+	schemaErr := s.validateWithCompiled(obj.Content, schemaEntity.Content, compiledSchema)
 
-	// Validate x-gts-ref constraints
-	xGtsRefValidator := NewXGtsRefValidator(s)
-	xGtsRefErrors := xGtsRefValidator.ValidateInstance(obj.Content, schemaEntity.Content, "")
+	// Validate x-gts-ref constraints as RegistryConfig.XGtsRefValidation selects; their failures are
+	// reported along with the schema's
+	xGtsRefErrors := s.newInstanceXGtsRefValidator().ValidateInstance(obj.Content, schemaEntity.Content, "")
+	var refErr error
 	if len(xGtsRefErrors) > 0 {
-		var errorMsgs []string
-		for _, err := range xGtsRefErrors {
-			errorMsgs = append(errorMsgs, err.Error())
-		}
-		return &ValidationResult{
-			ID:    gtsID,
-			OK:    false,
-			Error: fmt.Sprintf("x-gts-ref validation failed: %s", strings.Join(errorMsgs, "; ")),
+		// Wrapping every failure keeps them inspectable with errors.As
+		verbs := make([]string, len(xGtsRefErrors))
+		args := make([]any, len(xGtsRefErrors))
+		for i, err := range xGtsRefErrors {
+			verbs[i], args[i] = "%w", err
 		}
+		refErr = fmt.Errorf("x-gts-ref validation failed: "+strings.Join(verbs, "; "), args...)
+	}
+	switch {
+	case schemaErr != nil && refErr != nil:
+		return failedValidation(gtsID, fmt.Errorf("%w; %w", schemaErr, refErr))
+	case schemaErr != nil:
+		return failedValidation(gtsID, schemaErr)
+	case refErr != nil:
+		return failedValidation(gtsID, refErr)
 	}
 
 	return &ValidationResult{
@@ -127,64 +241,142 @@ func (s *GtsStore) ValidateInstance(gtsID string) *ValidationResult {
 	}
 }
 
+// failedValidation returns the result of a validation that failed with err
+func failedValidation(gtsID string, err error) *ValidationResult {
+	return &ValidationResult{
+		ID:         gtsID,
+		OK:         false,
+		Error:      err.Error(),
+		Err:        err,
+		Violations: validationViolations(err),
+	}
+}
+
+// validationViolations lists the JSON Schema and x-gts-ref failures wrapped by a validation error.
+// JSON Schema failures are the leaves of the validator's error tree, except that a failed anyOf
+// or oneOf is reported once rather than with the failures of each of its branches.
+func validationViolations(err error) []ValidationViolation {
+	var violations []ValidationViolation
+	var collectSchema func(e *jsonschema.ValidationError)
+	collectSchema = func(e *jsonschema.ValidationError) {
+		_, isAnyOf := e.ErrorKind.(*kind.AnyOf)
+		_, isOneOf := e.ErrorKind.(*kind.OneOf)
+		if len(e.Causes) > 0 && !isAnyOf && !isOneOf {
+			for _, cause := range e.Causes {
+				collectSchema(cause)
+			}
+			return
+		}
+
+		keyword := "$ref"
+		if keywordPath := e.ErrorKind.KeywordPath(); len(keywordPath) > 0 {
+			keyword = keywordPath[len(keywordPath)-1]
+		}
+		tokens := make([]string, len(e.InstanceLocation))
+		for i, token := range e.InstanceLocation {
+			tokens[i] = "/" + escapeJSONPointer(token)
+		}
+		// The error of the node alone reads "at '<pointer>': <message>"
+		leaf := &jsonschema.ValidationError{InstanceLocation: e.InstanceLocation, ErrorKind: e.ErrorKind}
+		_, message, _ := strings.Cut(leaf.Error(), ": ")
+		violations = append(violations, ValidationViolation{Path: strings.Join(tokens, ""), Keyword: keyword, Message: message})
+	}
+
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *jsonschema.ValidationError:
+			collectSchema(e)
+		case *XGtsRefValidationError:
+			path := ""
+			for _, token := range positionPathTokens(e.FieldPath) {
+				path += "/" + escapeJSONPointer(token)
+			}
+			violations = append(violations, ValidationViolation{Path: path, Keyword: "x-gts-ref", Message: e.Reason})
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			if inner := errors.Unwrap(err); inner != nil {
+				walk(inner)
+			}
+		}
+	}
+	walk(err)
+	return violations
+}
+
 // validateWithSchema performs the actual JSON Schema validation
 func (s *GtsStore) validateWithSchema(instance map[string]any, schema map[string]any) error {
-	// Normalize schema to convert $$id to $id and $$schema to $schema for JSON Schema validation
-	normalizedSchema := make(map[string]any)
-	for k, v := range schema {
-		switch k {
-		case "$$id":
-			normalizedSchema["$id"] = v
-		case "$$schema":
-			normalizedSchema["$schema"] = v
-		default:
-			normalizedSchema[k] = v
+	compiledSchema, err := s.compileSchema(schema)
+	if err != nil {
+		return err
+	}
+	return s.validateWithCompiled(instance, schema, compiledSchema)
+}
+
+// validateWithCompiled validates an instance against schema compiled to compiledSchema
+func (s *GtsStore) validateWithCompiled(instance map[string]any, schema map[string]any, compiledSchema *jsonschema.Schema) error {
+	if err := compiledSchema.Validate(instance); err != nil {
+		// Failures inside then/else branches do not say which condition selected the branch
+		if explanations := explainConditionals(instance, schema, s.storeSchemaResolver()); len(explanations) > 0 {
+			return fmt.Errorf("validation error: %w\nconditional requirements: %s", err, strings.Join(explanations, "; "))
 		}
+		return fmt.Errorf("validation error: %w", err)
 	}
 
-	// Create a custom compiler with GTS reference resolution
-	compiler := jsonschema.NewCompiler()
+	return nil
+}
 
-	// Register lenient format validators to match Python's jsonschema behavior
-	// Python's jsonschema library does NOT validate formats by default
-	lenientValidator := func(v any) error { return nil }
-	formats := []string{
-		"uuid", "date-time", "date", "time", "email", "hostname",
-		"ipv4", "ipv6", "uri", "uri-reference", "iri", "iri-reference",
-		"uri-template", "json-pointer", "relative-json-pointer", "regex",
+// compiledSchemas caches the schemas compiled while validating many instances, keyed by schema ID;
+// compile failures are cached too. A nil cache compiles every schema anew.
+type compiledSchemas map[string]compiledSchema
+
+// compiledSchema is a cached compilation outcome
+type compiledSchema struct {
+	schema *jsonschema.Schema
+	err    error
+}
+
+// compile returns the compiled registered schema schemaID with content schema
+func (c compiledSchemas) compile(s *GtsStore, schemaID string, schema map[string]any) (*jsonschema.Schema, error) {
+	if c == nil {
+		return s.compileSchema(schema)
 	}
-	for _, fmt := range formats {
-		compiler.RegisterFormat(&jsonschema.Format{
-			Name:     fmt,
-			Validate: lenientValidator,
-		})
+	if cached, ok := c[schemaID]; ok {
+		return cached.schema, cached.err
 	}
+	compiled, err := s.compileSchema(schema)
+	c[schemaID] = compiledSchema{schema: compiled, err: err}
+	return compiled, err
+}
 
-	// Set up custom loader for GTS ID references (matches Python's resolve_gts_ref handler)
-	compiler.UseLoader(&gtsURLLoader{store: s})
-
-	// Get schema ID for compilation (now from normalized schema)
-	schemaID, ok := normalizedSchema["$id"].(string)
-	if !ok || schemaID == "" {
-		return fmt.Errorf("schema must have a valid $id field")
+// compileSchema compiles a schema document, resolving its GTS references against the store
+// The document takes precedence over a stored schema with the same ID.
+func (s *GtsStore) compileSchema(schema map[string]any) (*jsonschema.Schema, error) {
+	// Normalize schema to convert $$id to $id and $$schema to $schema for JSON Schema validation
+	normalizedSchema, normalizedSchemaID := normalizeSchemaDocument(schema)
+	if normalizedSchemaID == "" {
+		return nil, fmt.Errorf("schema must have a valid $id field")
 	}
 
-	// Normalize schema ID by stripping gts:// prefix if present
-	normalizedSchemaID := strings.TrimPrefix(schemaID, GtsURIPrefix)
+	// Create a custom compiler with GTS reference resolution
+	compiler := newSchemaCompiler()
 
-	// Update the $id in the normalized schema to use the normalized ID
-	normalizedSchema["$id"] = normalizedSchemaID
+	// Set up custom loader for GTS ID references (matches Python's resolve_gts_ref handler)
+	compiler.UseLoader(&gtsURLLoader{store: s})
 
 	// Add the main schema to the compiler (use normalized schema with normalized ID)
 	if err := compiler.AddResource(normalizedSchemaID, normalizedSchema); err != nil {
-		return fmt.Errorf("add schema resource: %v", err)
+		return nil, fmt.Errorf("add schema resource: %v", err)
 	}
 
 	// Pre-load all schemas from the store (matches Python's store dict pre-population)
 	// Note: Store IDs are already normalized (without gts:// prefix)
-	for id, entity := range s.byID {
-		if entity.IsSchema && id != normalizedSchemaID {
-			if err := compiler.AddResource(id, entity.Content); err != nil {
+	for id, content := range s.schemaContents() {
+		if id != normalizedSchemaID {
+			if err := compiler.AddResource(id, content); err != nil {
 				// Ignore errors - gtsURLLoader will handle dynamic resolution
 				continue
 			}
@@ -194,13 +386,52 @@ func (s *GtsStore) validateWithSchema(instance map[string]any, schema map[string
 	// Compile the schema using the normalized ID
 	compiledSchema, err := compiler.Compile(normalizedSchemaID)
 	if err != nil {
-		return fmt.Errorf("compile schema: %v", err)
+		return nil, fmt.Errorf("compile schema: %v", err)
 	}
+	return compiledSchema, nil
+}
 
-	// Validate the instance
-	if err := compiledSchema.Validate(instance); err != nil {
-		return fmt.Errorf("validation error: %v", err)
+// normalizeSchemaDocument returns a copy of schema with $$id and $$schema renamed to $id and $schema
+// and the gts:// prefix stripped from $id, together with that normalized ID (empty if missing)
+func normalizeSchemaDocument(schema map[string]any) (map[string]any, string) {
+	normalizedSchema := make(map[string]any, len(schema))
+	for k, v := range schema {
+		switch k {
+		case "$$id":
+			normalizedSchema["$id"] = v
+		case "$$schema":
+			normalizedSchema["$schema"] = v
+		default:
+			normalizedSchema[k] = v
+		}
 	}
 
-	return nil
+	schemaID, ok := normalizedSchema["$id"].(string)
+	if !ok || schemaID == "" {
+		return normalizedSchema, ""
+	}
+	normalizedSchemaID := strings.TrimPrefix(schemaID, GtsURIPrefix)
+	normalizedSchema["$id"] = normalizedSchemaID
+	return normalizedSchema, normalizedSchemaID
+}
+
+// newSchemaCompiler creates a JSON Schema compiler with lenient format validators
+func newSchemaCompiler() *jsonschema.Compiler {
+	compiler := jsonschema.NewCompiler()
+
+	// Register lenient format validators to match Python's jsonschema behavior
+	// Python's jsonschema library does NOT validate formats by default
+	lenientValidator := func(v any) error { return nil }
+	formats := []string{
+		"uuid", "date-time", "date", "time", "email", "hostname",
+		"ipv4", "ipv6", "uri", "uri-reference", "iri", "iri-reference",
+		"uri-template", "json-pointer", "relative-json-pointer", "regex",
+	}
+	for _, fmt := range formats {
+		compiler.RegisterFormat(&jsonschema.Format{
+			Name:     fmt,
+			Validate: lenientValidator,
+		})
+	}
+	return compiler
 }
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"sort"
+)
+
+// ValidateAllResult reports the validation of every registered entity
+type ValidateAllResult struct {
+	OK bool `json:"ok"`
+	// Valid and Invalid count the schemas and instances checked; Skipped counts the instances
+	// without schema ID, which cannot be validated
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	Skipped int `json:"skipped"`
+	// Schemas and Instances hold the result of every checked entity in ID order
+	Schemas   []*ValidationResult `json:"schemas"`
+	Instances []*ValidationResult `json:"instances"`
+	// SkippedIDs lists the skipped instances in ID order
+	SkippedIDs []string `json:"skipped_ids,omitempty"`
+}
+
+// ValidateAll validates every registered entity: schemas with ValidateSchema, which includes the
+// $ref and x-gts-ref checks, and instances against their schema like ValidateInstance. Each schema
+// is compiled once for all of its instances. The result is OK when no entity is invalid; skipped
+// instances do not fail it. A panic while validating an entity is reported in its result.
+func (s *GtsStore) ValidateAll() *ValidateAllResult {
+	result := &ValidateAllResult{Schemas: []*ValidationResult{}, Instances: []*ValidationResult{}}
+	entities := s.entitySnapshot()
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].GtsID.ID < entities[j].GtsID.ID
+	})
+
+	cache := compiledSchemas{}
+	for _, entity := range entities {
+		id := entity.GtsID.ID
+		if !entity.IsSchema && entity.SchemaID == "" {
+			result.SkippedIDs = append(result.SkippedIDs, id)
+			result.Skipped++
+			continue
+		}
+
+		entityResult := s.validateAllEntity(entity, cache)
+		if entity.IsSchema {
+			result.Schemas = append(result.Schemas, entityResult)
+		} else {
+			result.Instances = append(result.Instances, entityResult)
+		}
+		if entityResult.OK {
+			result.Valid++
+		} else {
+			result.Invalid++
+		}
+	}
+	result.OK = result.Invalid == 0
+	return result
+}
+
+// validateAllEntity validates a single entity of ValidateAll
+func (s *GtsStore) validateAllEntity(entity *JsonEntity, cache compiledSchemas) (result *ValidationResult) {
+	id := entity.GtsID.ID
+	defer func() {
+		if r := recover(); r != nil {
+			result = failedValidation(id, newStoreInternalError("ValidateAll", r))
+		}
+	}()
+
+	if entity.IsSchema {
+		if err := s.ValidateSchema(id); err != nil {
+			return failedValidation(id, err)
+		}
+		return &ValidationResult{ID: id, OK: true}
+	}
+	return s.validateLocated(id, entity, cache)
+}
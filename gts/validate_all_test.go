@@ -0,0 +1,64 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"testing"
+)
+
+func TestValidateAll(t *testing.T) {
+	store := NewGtsStore(nil)
+	register := func(content map[string]any) {
+		t.Helper()
+		if err := store.Register(NewJsonEntity(content, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register %v: %v", content, err)
+		}
+	}
+
+	register(map[string]any{
+		"$id":      "gts://gts.x.test.all.item.v1~",
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+		},
+	})
+	register(map[string]any{
+		"$id":     "gts://gts.x.test.all.broken.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"properties": map[string]any{
+			"owner": map[string]any{"type": "string", "x-gts-ref": "not-a-gts-pattern"},
+		},
+	})
+	register(map[string]any{"id": "gts.x.test.all.item.v1~x.test._.a.v1", "name": "A"})
+	register(map[string]any{"id": "gts.x.test.all.item.v1~x.test._.b.v1", "name": "B"})
+	register(map[string]any{"id": "gts.x.test.all.item.v1~x.test._.c.v1", "name": 3})
+
+	// An entity provided without schema ID, e.g. by a custom reader, cannot be validated
+	orphan := NewJsonEntity(map[string]any{"id": "gts.x.test.all.item.v1~x.test._.orphan.v1"}, DefaultGtsConfig())
+	orphan.SchemaID = ""
+	if err := store.Register(orphan); err != nil {
+		t.Fatalf("Failed to register orphan: %v", err)
+	}
+
+	result := store.ValidateAll()
+	if result.OK {
+		t.Fatal("Expected ValidateAll to fail")
+	}
+	if result.Valid != 3 || result.Invalid != 2 || result.Skipped != 1 {
+		t.Errorf("Expected 3 valid, 2 invalid and 1 skipped, got %d, %d and %d", result.Valid, result.Invalid, result.Skipped)
+	}
+	if len(result.Schemas) != 2 || result.Schemas[0].ID != "gts.x.test.all.broken.v1~" || result.Schemas[0].OK {
+		t.Errorf("Expected the schema with an invalid x-gts-ref to fail, got %+v", result.Schemas)
+	}
+	if len(result.Instances) != 3 || !result.Instances[0].OK || !result.Instances[1].OK || result.Instances[2].OK {
+		t.Errorf("Expected only instance c to fail, got %+v", result.Instances)
+	}
+	if len(result.SkippedIDs) != 1 || result.SkippedIDs[0] != "gts.x.test.all.item.v1~x.test._.orphan.v1" {
+		t.Errorf("Expected the instance without schema ID to be skipped, got %v", result.SkippedIDs)
+	}
+}
@@ -6,6 +6,7 @@ Released under Apache License 2.0
 package gts
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -177,6 +178,52 @@ func TestValidateInstance_InvalidInstance_MissingRequiredField(t *testing.T) {
 	}
 }
 
+func TestValidateInstance_Violations(t *testing.T) {
+	store := NewGtsStore(nil)
+	if err := store.RegisterSchema("gts.x.test.viol.target.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register target schema: %v", err)
+	}
+	schema := map[string]any{
+		"$id":      "gts://gts.x.test.viol.order.v1~",
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"id", "name"},
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "string"},
+			"name":   map[string]any{"type": "string"},
+			"count":  map[string]any{"type": "integer"},
+			"target": map[string]any{"type": "string", "x-gts-ref": "gts.x.test.viol.target.v1~"},
+		},
+	}
+	if err := store.RegisterSchema("gts.x.test.viol.order.v1~", schema); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	instance := map[string]any{
+		"id":     "gts.x.test.viol.order.v1~x.test._.a.v1",
+		"count":  "three",
+		"target": "gts.x.test.viol.other.v1~",
+	}
+	if err := store.Register(NewJsonEntity(instance, DefaultGtsConfig())); err != nil {
+		t.Fatalf("Failed to register instance: %v", err)
+	}
+
+	result := store.ValidateInstance("gts.x.test.viol.order.v1~x.test._.a.v1")
+	if result.OK {
+		t.Fatalf("Expected validation to fail")
+	}
+	got := map[string]string{}
+	for _, violation := range result.Violations {
+		got[violation.Keyword] = violation.Path
+		if violation.Message == "" {
+			t.Errorf("Expected a message for violation %+v", violation)
+		}
+	}
+	want := map[string]string{"required": "", "type": "/count", "x-gts-ref": "/target"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected violations %v, got %+v", want, result.Violations)
+	}
+}
+
 func TestValidateInstance_NotFound(t *testing.T) {
 	store := NewGtsStore(nil)
 
@@ -428,3 +475,51 @@ func TestValidateInstance_NoSchemaID(t *testing.T) {
 		t.Errorf("Expected error message for instance without schema")
 	}
 }
+
+func TestValidateEntity_Unregistered(t *testing.T) {
+	store := NewGtsStore(nil)
+	schema := NewJsonEntity(map[string]any{
+		"$id":        "gts.x.test.candidate.item.v1~",
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"required":   []any{"name"},
+		"properties": map[string]any{"name": map[string]any{"type": "string"}},
+	}, DefaultGtsConfig())
+	if err := store.Register(schema); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+
+	data := []byte(`[
+  {"id": "7a1d2f3e-0000-4000-8000-000000000001", "type": "gts.x.test.candidate.item.v1~", "name": "ok"},
+  {"id": "gts.x.test.candidate.item.v1~x.test._.bad.v1", "name": 42}
+]`)
+	cfg := DefaultGtsConfig()
+	cfg.RecordPositions = true
+	entities, err := ParseCandidateDocument("candidates.json", data, cfg)
+	if err != nil {
+		t.Fatalf("ParseCandidateDocument: %v", err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(entities))
+	}
+
+	valid := store.ValidateEntity(entities[0])
+	if !valid.OK || valid.SchemaID != "gts.x.test.candidate.item.v1~" {
+		t.Errorf("Expected the UUID instance to validate against its type, got %+v", valid)
+	}
+	if valid.ID != "candidates.json#0" {
+		t.Errorf("Expected the label as ID of an instance without GTS ID, got %s", valid.ID)
+	}
+
+	invalid := store.ValidateEntity(entities[1])
+	if invalid.OK {
+		t.Fatal("Expected the instance with a non-string name to fail")
+	}
+	if invalid.Location != "candidates.json:3:58" {
+		t.Errorf("Expected the failure to be located, got %q", invalid.Location)
+	}
+
+	if store.Count() != 1 {
+		t.Errorf("Expected validation not to register candidates, store has %d entities", store.Count())
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is how often WatchPaths polls the watched files for changes
+const DefaultWatchInterval = time.Second
+
+// WatchOptions configures WatchPaths
+type WatchOptions struct {
+	// Interval is the time between two polls of the watched files (default: DefaultWatchInterval)
+	Interval time.Duration
+	// Config is the configuration used to extract the IDs of the entities in the files (default: DefaultGtsConfig)
+	Config *GtsConfig
+}
+
+// watchedFile is the state of a watched file at its last poll
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	// ids are the IDs of the entities the file defined when it was last parsed
+	ids []string
+}
+
+// pathWatcher polls the files of a GtsFileReader and applies their changes to a store
+type pathWatcher struct {
+	store  *GtsStore
+	reader *GtsFileReader
+	cfg    *GtsConfig
+	files  map[string]*watchedFile
+}
+
+// WatchPath watches a file or directory like WatchPaths with the default options
+func (s *GtsStore) WatchPath(path string) (stop func(), err error) {
+	return s.WatchPaths([]string{path}, WatchOptions{})
+}
+
+// WatchPaths keeps the store in sync with the entity files of paths, selected like GtsFileReader
+// does, polling them for changes. The files are first compared with the store, then every poll
+// re-parses the added and modified files and registers their entities with Register, replacing
+// the previous versions; entities whose content did not change are left alone. Entities no longer
+// defined by any watched file, because their file was edited or deleted, are removed with
+// Unregister. A file that cannot be parsed, e.g. while it is being saved, and an entity that fails
+// to register or unregister are logged and the previous version is kept. stop ends the watch and
+// waits for a running poll to finish. WatchPaths fails when a path does not exist.
+func (s *GtsStore) WatchPaths(paths []string, opts WatchOptions) (stop func(), err error) {
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = DefaultGtsConfig()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	reader := NewGtsFileReader(paths, cfg)
+	for _, path := range reader.paths {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+	}
+	w := &pathWatcher{store: s, reader: reader, cfg: cfg, files: make(map[string]*watchedFile)}
+	w.poll()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}, nil
+}
+
+// poll applies the changes of the watched files since the previous poll to the store
+func (w *pathWatcher) poll() {
+	w.reader.collectFiles()
+
+	// stale are the IDs of changed and deleted files, removed unless a watched file still defines them
+	var stale []string
+	var entities []*JsonEntity
+	present := make(map[string]bool, len(w.reader.files))
+	for _, file := range w.reader.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		present[file] = true
+		previous, known := w.files[file]
+		if known && previous.modTime.Equal(info.ModTime()) && previous.size == info.Size() {
+			continue
+		}
+
+		state := &watchedFile{modTime: info.ModTime(), size: info.Size()}
+		if known {
+			state.ids = previous.ids
+		}
+		w.files[file] = state
+		parsed, err := w.parseFile(file)
+		if err != nil {
+			log.Printf("Watch: keeping the entities of %s, which cannot be parsed: %v", file, err)
+			continue
+		}
+		stale = append(stale, state.ids...)
+		state.ids = nil
+		for _, entity := range parsed {
+			if entity.GtsID != nil {
+				state.ids = append(state.ids, entity.GtsID.ID)
+				entities = append(entities, entity)
+			}
+		}
+	}
+	for file, state := range w.files {
+		if !present[file] {
+			stale = append(stale, state.ids...)
+			delete(w.files, file)
+		}
+	}
+
+	registered := w.register(entities)
+	removed := w.remove(stale)
+	if registered > 0 || removed > 0 {
+		log.Printf("Watch: %d entities registered, %d removed", registered, removed)
+	}
+}
+
+// parseFile reads the entities of a watched file
+func (w *pathWatcher) parseFile(file string) ([]*JsonEntity, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	entities, _, err := ParseEntityDocument(file, data, w.cfg)
+	return entities, err
+}
+
+// register registers the entities of changed files, schemas first so that instances find them,
+// and returns the number of entities registered
+func (w *pathWatcher) register(entities []*JsonEntity) int {
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].IsSchema && !entities[j].IsSchema })
+
+	count := 0
+	for _, entity := range entities {
+		if existing := w.store.registered(entity.GtsID.ID); existing != nil && existing.ContentHash == ContentHash(entity.Content) {
+			continue
+		}
+		if err := w.store.Register(entity); err != nil {
+			log.Printf("Watch: keeping the previous version of %s from %s: %v", entity.GtsID.ID, entity.Label, err)
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// remove unregisters the stale IDs no watched file defines anymore, instances first so that the
+// schemas they reference can follow, and returns the number of entities removed
+func (w *pathWatcher) remove(stale []string) int {
+	sort.SliceStable(stale, func(i, j int) bool { return !strings.HasSuffix(stale[i], "~") && strings.HasSuffix(stale[j], "~") })
+	defined := make(map[string]bool)
+	for _, state := range w.files {
+		for _, id := range state.ids {
+			defined[id] = true
+		}
+	}
+
+	count := 0
+	for _, id := range stale {
+		if defined[id] || w.store.registered(id) == nil {
+			continue
+		}
+		if err := w.store.Unregister(id); err != nil {
+			log.Printf("Watch: keeping %s, whose file no longer defines it: %v", id, err)
+			continue
+		}
+		count++
+	}
+	return count
+}
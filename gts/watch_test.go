@@ -0,0 +1,129 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeWatchedFile writes a file and moves its modification time forward, so that a poll sees the
+// change even on file systems with coarse timestamps
+func writeWatchedFile(t *testing.T, path, content string) {
+	t.Helper()
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	if !modTime.IsZero() {
+		if err := os.Chtimes(path, modTime.Add(time.Second), modTime.Add(time.Second)); err != nil {
+			t.Fatalf("Failed to touch %s: %v", path, err)
+		}
+	}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchPaths(t *testing.T) {
+	const schemaID = "gts.x.test.watch.item.v1~"
+	const itemA = "gts.x.test.watch.item.v1~x.test._.a.v1"
+	const itemB = "gts.x.test.watch.item.v1~x.test._.b.v1"
+
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "item.schema.json")
+	itemsFile := filepath.Join(dir, "items.json")
+	writeWatchedFile(t, schemaFile, `{"$id": "gts://`+schemaID+`", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`)
+	writeWatchedFile(t, itemsFile, `[{"id": "`+itemA+`", "n": 1}, {"id": "`+itemB+`", "n": 1}]`)
+
+	store := NewGtsStoreWithConfig(nil, &RegistryConfig{RefValidation: RefValidationStrict})
+	stop, err := store.WatchPaths([]string{dir}, WatchOptions{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WatchPaths failed: %v", err)
+	}
+	defer stop()
+	if store.Count() != 3 {
+		t.Fatalf("Expected the initial poll to register 3 entities, got %d", store.Count())
+	}
+
+	// An edit replaces the entities of the file and removes those it no longer defines
+	writeWatchedFile(t, itemsFile, `[{"id": "`+itemA+`", "n": 2}]`)
+	waitFor(t, "the edit", func() bool { return store.Get(itemB) == nil })
+	if n := store.Get(itemA).Content["n"]; n != float64(2) {
+		t.Errorf("Expected the edited content, got n=%v", n)
+	}
+
+	// A file that cannot be parsed keeps the previous entities
+	writeWatchedFile(t, itemsFile, `[{"id": "`+itemA+`", "n": 3`)
+	time.Sleep(50 * time.Millisecond)
+	if entity := store.Get(itemA); entity == nil || entity.Content["n"] != float64(2) {
+		t.Errorf("Expected the previous version to be kept, got %v", entity)
+	}
+
+	// A deleted file removes its entities
+	if err := os.Remove(itemsFile); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	waitFor(t, "the removal", func() bool { return store.Get(itemA) == nil })
+	if err := os.Remove(schemaFile); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	waitFor(t, "the schema removal", func() bool { return store.Get(schemaID) == nil })
+
+	stop()
+	writeWatchedFile(t, itemsFile, `{"id": "`+itemA+`"}`)
+	time.Sleep(50 * time.Millisecond)
+	if store.Get(itemA) != nil {
+		t.Error("Expected no changes after stop")
+	}
+
+	if _, err := store.WatchPath(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestWatchPaths_RegistrationFailureKeepsPrevious(t *testing.T) {
+	const schemaID = "gts.x.test.watch.frozen.v1~"
+	dir := t.TempDir()
+	file := filepath.Join(dir, "frozen.schema.json")
+	writeWatchedFile(t, file, `{"$id": "gts://`+schemaID+`", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`)
+
+	store := NewGtsStore(nil)
+	stop, err := store.WatchPaths([]string{dir}, WatchOptions{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WatchPaths failed: %v", err)
+	}
+	defer stop()
+
+	// A frozen store refuses the new version and the removal
+	store.Freeze()
+	writeWatchedFile(t, file, `{"$id": "gts://`+schemaID+`", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "description": "changed"}`)
+	time.Sleep(50 * time.Millisecond)
+	if entity := store.Get(schemaID); entity == nil || entity.Content["description"] != nil {
+		t.Errorf("Expected the previous version to be kept, got %v", entity)
+	}
+	if err := os.Remove(file); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if store.Get(schemaID) == nil {
+		t.Error("Expected the entity to be kept")
+	}
+}
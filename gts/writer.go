@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GtsWriter is an interface for persisting the entities of a store, see NewGtsStoreWithPersistence
+type GtsWriter interface {
+	// Write stores the content of a registered entity, replacing any earlier version
+	Write(entity *JsonEntity) error
+
+	// Delete removes a stored entity; deleting an ID that is not stored is not an error
+	Delete(id string) error
+}
+
+// StorePersistenceError is returned when the writer of a store fails; the change is not applied
+// to the store. It wraps both ErrInternal and the writer error.
+type StorePersistenceError struct {
+	Operation string
+	EntityID  string
+	Err       error
+}
+
+func (e *StorePersistenceError) Error() string {
+	return fmt.Sprintf("Failed to persist %s of %s: %v", e.Operation, e.EntityID, e.Err)
+}
+
+func (e *StorePersistenceError) Unwrap() []error {
+	return []error{ErrInternal, e.Err}
+}
+
+// GtsDirWriter writes every entity to its own file in a directory, named by PersistedFileName,
+// so that a GtsFileReader on the same directory loads the entities back
+type GtsDirWriter struct {
+	dir string
+}
+
+// NewGtsDirWriter creates a writer for dir, creating the directory if needed
+func NewGtsDirWriter(dir string) (*GtsDirWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &GtsDirWriter{dir: dir}, nil
+}
+
+// Dir returns the directory of the writer
+func (w *GtsDirWriter) Dir() string {
+	return w.dir
+}
+
+// Write writes the content of entity in canonical form (see CanonicalJSON). A schema registered
+// with content without $id gets its ID added, so the file identifies it when read back.
+// The file is replaced atomically, so readers never see a partial file.
+func (w *GtsDirWriter) Write(entity *JsonEntity) error {
+	if entity.GtsID == nil {
+		return fmt.Errorf("entity must have a valid gts_id")
+	}
+	content := entity.Content
+	if entity.IsSchema && entity.getFieldValue("$id") == "" {
+		content = copyMap(content)
+		content["$id"] = GtsURIPrefix + entity.GtsID.ID
+	}
+	data, err := CanonicalJSON(content)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(w.dir, PersistedFileName(entity.GtsID.ID))
+	tmp, err := os.CreateTemp(w.dir, ".gts-write-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete removes the file of an entity
+func (w *GtsDirWriter) Delete(id string) error {
+	err := os.Remove(filepath.Join(w.dir, PersistedFileName(id)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// PersistedFileName returns the name of the file GtsDirWriter writes an entity to: the ID with
+// every byte outside [a-z0-9_.-] percent-encoded, e.g. '~' as %7E, and a .json extension.
+// The encoding is reversible, so distinct IDs never share a file.
+func PersistedFileName(id string) string {
+	return escapePathElement(id) + ".json"
+}
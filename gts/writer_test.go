@@ -0,0 +1,90 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package gts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGtsDirWriter_WriteThrough(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewGtsDirWriter(dir)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	store := NewGtsStoreWithPersistence(nil, writer, nil)
+
+	if err := store.RegisterSchema("gts.x.test.persist.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("Failed to register schema: %v", err)
+	}
+	derived := map[string]any{
+		"$id":   "gts://gts.x.test.persist.item.v1~x.test.persist.book.v1~",
+		"allOf": []any{map[string]any{"$ref": "gts.x.test.persist.item.v1~"}},
+	}
+	instance := map[string]any{"id": "gts.x.test.persist.item.v1~x.test._.a.v1", "title": "A"}
+	registerTestEntities(t, store, derived, instance)
+
+	for _, name := range []string{
+		"gts.x.test.persist.item.v1%7E.json",
+		"gts.x.test.persist.item.v1%7Ex.test.persist.book.v1%7E.json",
+		"gts.x.test.persist.item.v1%7Ex.test._.a.v1.json",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected file %s: %v", name, err)
+		}
+	}
+
+	reloaded := NewGtsStore(NewGtsFileReader([]string{dir}, nil))
+	if reloaded.Count() != store.Count() {
+		t.Fatalf("Expected %d entities after reload, got %d", store.Count(), reloaded.Count())
+	}
+	for _, id := range store.sortedIDs() {
+		entity := reloaded.Get(id)
+		if entity == nil {
+			t.Errorf("Expected %s after reload", id)
+			continue
+		}
+		if id != "gts.x.test.persist.item.v1~" && !reflect.DeepEqual(entity.Content, store.Get(id).Content) {
+			t.Errorf("Content of %s differs after reload: %v", id, entity.Content)
+		}
+	}
+
+	if err := store.Unregister("gts.x.test.persist.item.v1~x.test._.a.v1"); err != nil {
+		t.Fatalf("Failed to unregister: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gts.x.test.persist.item.v1%7Ex.test._.a.v1.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the file of the unregistered instance to be deleted, got %v", err)
+	}
+}
+
+// failingWriter rejects every write
+type failingWriter struct{}
+
+func (failingWriter) Write(entity *JsonEntity) error { return errors.New("disk full") }
+func (failingWriter) Delete(id string) error         { return errors.New("disk full") }
+
+func TestGtsStore_PersistenceFailure(t *testing.T) {
+	store := NewGtsStoreWithPersistence(nil, failingWriter{}, nil)
+	err := store.Register(NewJsonEntity(map[string]any{"$id": "gts://gts.x.test.persist.item.v1~"}, DefaultGtsConfig()))
+
+	var persistErr *StorePersistenceError
+	if !errors.As(err, &persistErr) || !errors.Is(err, ErrInternal) {
+		t.Fatalf("Expected StorePersistenceError, got %v", err)
+	}
+	if store.Count() != 0 {
+		t.Error("Expected the entity not to be registered when it could not be persisted")
+	}
+}
+
+func TestPersistedFileName(t *testing.T) {
+	if got := PersistedFileName("gts.x.core.events.type.v1~x.app._.Name.v1"); got != "gts.x.core.events.type.v1%7Ex.app._.%4Eame.v1.json" {
+		t.Errorf("Unexpected file name %s", got)
+	}
+}
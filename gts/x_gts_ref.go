@@ -7,6 +7,7 @@ package gts
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -22,9 +23,53 @@ func (e *XGtsRefValidationError) Error() string {
 	return fmt.Sprintf("x-gts-ref validation failed for field '%s': %s", e.FieldPath, e.Reason)
 }
 
+// XGtsRefMode selects how instance validation checks x-gts-ref constraints
+type XGtsRefMode int
+
+const (
+	// XGtsRefFull checks that referencing values match their x-gts-ref pattern and name registered entities
+	XGtsRefFull XGtsRefMode = iota
+	// XGtsRefPatternsOnly checks the patterns only, e.g. for registries loaded partially
+	XGtsRefPatternsOnly
+	// XGtsRefOff skips the x-gts-ref constraints of instances
+	XGtsRefOff
+)
+
+// String returns the textual name of the mode
+func (m XGtsRefMode) String() string {
+	switch m {
+	case XGtsRefPatternsOnly:
+		return "patterns"
+	case XGtsRefOff:
+		return "off"
+	default:
+		return "full"
+	}
+}
+
+// ParseXGtsRefMode parses an x-gts-ref validation mode name: full, patterns or off
+func ParseXGtsRefMode(s string) (XGtsRefMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "full":
+		return XGtsRefFull, nil
+	case "patterns":
+		return XGtsRefPatternsOnly, nil
+	case "off":
+		return XGtsRefOff, nil
+	default:
+		return XGtsRefFull, fmt.Errorf("invalid x-gts-ref validation mode '%s' (expected full, patterns or off)", s)
+	}
+}
+
 // XGtsRefValidator validates x-gts-ref constraints in GTS schemas
 type XGtsRefValidator struct {
 	store *GtsStore
+	// patternsOnly skips the check that referenced entities are registered; the store still
+	// resolves the schemas referenced with $ref
+	patternsOnly bool
+	// visitRef, when set, is called with the path and value of every instance string under an
+	// x-gts-ref constraint, e.g. to find the references of an instance (see BuildSchemaGraph)
+	visitRef func(path, value string)
 }
 
 // NewXGtsRefValidator creates a new x-gts-ref validator
@@ -34,11 +79,40 @@ func NewXGtsRefValidator(store *GtsStore) *XGtsRefValidator {
 	}
 }
 
-// ValidateInstance validates an instance against x-gts-ref constraints in schema
+// newInstanceXGtsRefValidator returns the validator of the x-gts-ref constraints of instances set
+// by RegistryConfig.XGtsRefValidation, or nil when they are not checked
+func (s *GtsStore) newInstanceXGtsRefValidator() *XGtsRefValidator {
+	switch s.config.XGtsRefValidation {
+	case XGtsRefOff:
+		return nil
+	case XGtsRefPatternsOnly:
+		return &XGtsRefValidator{store: s, patternsOnly: true}
+	default:
+		return NewXGtsRefValidator(s)
+	}
+}
+
+// ValidateInstance validates an instance against x-gts-ref constraints in schema.
+// Constraints are found through properties and items, in every allOf member, in the anyOf and
+// oneOf branches that apply to the instance (see branchApplies) and behind $ref: local references
+// resolve within the schema and, when the validator has a store, GTS references to registered schemas.
 func (v *XGtsRefValidator) ValidateInstance(instance map[string]interface{}, schema map[string]interface{}, instancePath string) []*XGtsRefValidationError {
+	if v == nil {
+		return nil
+	}
 	var errors []*XGtsRefValidationError
-	v.visitInstance(instance, schema, instancePath, schema, &errors)
-	return errors
+	v.visitInstance(instance, schema, instancePath, schema, make(map[string]bool), &errors)
+
+	// A field reached through several routes (a $ref and the schema it is part of) is reported once
+	seen := make(map[string]bool, len(errors))
+	unique := errors[:0]
+	for _, err := range errors {
+		if key := err.FieldPath + "\x00" + err.Reason; !seen[key] {
+			seen[key] = true
+			unique = append(unique, err)
+		}
+	}
+	return unique
 }
 
 // ValidateSchema validates x-gts-ref fields in a schema definition
@@ -52,8 +126,10 @@ func (v *XGtsRefValidator) ValidateSchema(schema map[string]interface{}, schemaP
 	return errors
 }
 
-// visitInstance recursively visits instance nodes and validates x-gts-ref constraints
-func (v *XGtsRefValidator) visitInstance(instance interface{}, schema map[string]interface{}, path string, rootSchema map[string]interface{}, errors *[]*XGtsRefValidationError) {
+// visitInstance recursively visits instance nodes and validates x-gts-ref constraints.
+// rootSchema is the document relative x-gts-ref pointers and local $refs resolve against;
+// visiting holds the $refs being followed for each instance path, so that recursive schemas terminate.
+func (v *XGtsRefValidator) visitInstance(instance interface{}, schema map[string]interface{}, path string, rootSchema map[string]interface{}, visiting map[string]bool, errors *[]*XGtsRefValidationError) {
 	if schema == nil {
 		return
 	}
@@ -61,14 +137,45 @@ func (v *XGtsRefValidator) visitInstance(instance interface{}, schema map[string
 	// Check for x-gts-ref constraint
 	if xGtsRef, hasRef := schema["x-gts-ref"]; hasRef {
 		if strInstance, ok := instance.(string); ok {
+			if v.visitRef != nil {
+				v.visitRef(path, strInstance)
+			}
 			if err := v.validateRefValue(strInstance, xGtsRef, path, rootSchema); err != nil {
 				*errors = append(*errors, err)
 			}
 		}
 	}
 
+	// Follow $ref to the schema it points to; recursive schemas only cycle when the same reference
+	// is reached again for the same instance node
+	if ref, ok := schema["$ref"].(string); ok {
+		target, targetRoot, key := v.resolveSchemaRef(ref, rootSchema)
+		key += "@" + path
+		if target != nil && !visiting[key] {
+			visiting[key] = true
+			v.visitInstance(instance, target, path, targetRoot, visiting, errors)
+			delete(visiting, key)
+		}
+	}
+
+	// The instance must satisfy every allOf member
+	if members, ok := schema["allOf"].([]interface{}); ok {
+		for _, member := range members {
+			if memberMap, ok := member.(map[string]interface{}); ok {
+				v.visitInstance(instance, memberMap, path, rootSchema, visiting, errors)
+			}
+		}
+	}
+
+	// and one of the anyOf and oneOf branches that apply to it
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if branches, ok := schema[keyword].([]interface{}); ok {
+			v.visitBranches(instance, branches, path, rootSchema, visiting, errors)
+		}
+	}
+
 	// Recurse into object properties
-	if schemaType, ok := schema["type"].(string); ok && schemaType == "object" {
+	if schemaType, ok := schema["type"].(string); !ok || schemaType == "object" {
 		if properties, hasProps := schema["properties"].(map[string]interface{}); hasProps {
 			if instanceMap, ok := instance.(map[string]interface{}); ok {
 				for propName, propSchema := range properties {
@@ -78,7 +185,7 @@ func (v *XGtsRefValidator) visitInstance(instance interface{}, schema map[string
 							propPath = path + "." + propName
 						}
 						if propSchemaMap, ok := propSchema.(map[string]interface{}); ok {
-							v.visitInstance(propValue, propSchemaMap, propPath, rootSchema, errors)
+							v.visitInstance(propValue, propSchemaMap, propPath, rootSchema, visiting, errors)
 						}
 					}
 				}
@@ -87,18 +194,135 @@ func (v *XGtsRefValidator) visitInstance(instance interface{}, schema map[string
 	}
 
 	// Recurse into array items
-	if schemaType, ok := schema["type"].(string); ok && schemaType == "array" {
+	if schemaType, ok := schema["type"].(string); !ok || schemaType == "array" {
 		if items, hasItems := schema["items"].(map[string]interface{}); hasItems {
 			if instanceArray, ok := instance.([]interface{}); ok {
 				for idx, item := range instanceArray {
 					itemPath := fmt.Sprintf("%s[%d]", path, idx)
-					v.visitInstance(item, items, itemPath, rootSchema, errors)
+					v.visitInstance(item, items, itemPath, rootSchema, visiting, errors)
 				}
 			}
 		}
 	}
 }
 
+// visitBranches validates an instance against the anyOf or oneOf branches that apply to it.
+// The constraints are met when one applicable branch has no x-gts-ref errors; otherwise the
+// errors of every applicable branch are reported.
+func (v *XGtsRefValidator) visitBranches(instance interface{}, branches []interface{}, path string, rootSchema map[string]interface{}, visiting map[string]bool, errors *[]*XGtsRefValidationError) {
+	var branchErrors []*XGtsRefValidationError
+	for _, branch := range branches {
+		branchMap, ok := branch.(map[string]interface{})
+		if !ok || !v.branchApplies(instance, branchMap, rootSchema) {
+			continue
+		}
+		var errs []*XGtsRefValidationError
+		v.visitInstance(instance, branchMap, path, rootSchema, visiting, &errs)
+		if len(errs) == 0 {
+			return
+		}
+		branchErrors = append(branchErrors, errs...)
+	}
+	*errors = append(*errors, branchErrors...)
+}
+
+// branchApplies reports whether an anyOf or oneOf branch describes the instance: its type, if
+// any, matches the structural type of the instance and its const properties, if any, equal the
+// instance's values. A $ref branch is judged by the schema it points to.
+func (v *XGtsRefValidator) branchApplies(instance interface{}, branch map[string]interface{}, rootSchema map[string]interface{}) bool {
+	if ref, ok := branch["$ref"].(string); ok {
+		if target, _, _ := v.resolveSchemaRef(ref, rootSchema); target != nil {
+			branch = target
+		}
+	}
+
+	if schemaType, ok := branch["type"]; ok && !jsonTypeMatches(instance, schemaType) {
+		return false
+	}
+
+	properties, _ := branch["properties"].(map[string]interface{})
+	instanceMap, isMap := instance.(map[string]interface{})
+	for propName, propSchema := range properties {
+		propSchemaMap, ok := propSchema.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		constValue, hasConst := propSchemaMap["const"]
+		if !hasConst {
+			continue
+		}
+		if !isMap || !reflect.DeepEqual(instanceMap[propName], constValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveSchemaRef returns the schema a $ref points to, the document it belongs to and the key
+// identifying the reference for cycle protection. Local references resolve within rootSchema;
+// GTS references resolve to registered schemas when the validator has a store.
+func (v *XGtsRefValidator) resolveSchemaRef(ref string, rootSchema map[string]interface{}) (map[string]interface{}, map[string]interface{}, string) {
+	if strings.HasPrefix(ref, "#") {
+		target, ok := resolveLocalPointer(rootSchema, ref)
+		if !ok {
+			return nil, nil, ""
+		}
+		rootID, _ := rootSchema["$id"].(string)
+		return target, rootSchema, rootID + ref
+	}
+	if v.store == nil {
+		return nil, nil, ""
+	}
+	target := v.store.storeSchemaResolver()(ref)
+	if target == nil {
+		return nil, nil, ""
+	}
+	return target, target, strings.TrimPrefix(ref, GtsURIPrefix)
+}
+
+// jsonTypeMatches reports whether a decoded JSON value has the JSON Schema type, or one of the
+// types, given by a type keyword
+func jsonTypeMatches(value interface{}, schemaType interface{}) bool {
+	switch t := schemaType.(type) {
+	case string:
+		return jsonTypeOf(value) == t || (t == "number" && jsonTypeOf(value) == "integer")
+	case []interface{}:
+		for _, item := range t {
+			if jsonTypeMatches(value, item) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// jsonTypeOf returns the JSON Schema type of a decoded JSON value
+func jsonTypeOf(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == float64(int64(val)) {
+			return "integer"
+		}
+		return "number"
+	case int, int32, int64:
+		return "integer"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return ""
+	}
+}
+
 // visitSchema recursively visits schema nodes
 func (v *XGtsRefValidator) visitSchema(schema map[string]interface{}, path string, rootSchema map[string]interface{}, errors *[]*XGtsRefValidationError) {
 	if schema == nil {
@@ -247,14 +471,13 @@ func (v *XGtsRefValidator) validateGtsIDOrPattern(pattern, fieldPath string) *XG
 	}
 
 	if strings.Contains(pattern, "*") {
-		// Wildcard pattern - validate prefix
-		prefix := strings.TrimSuffix(pattern, "*")
-		if !strings.HasPrefix(prefix, "gts.") {
+		// Wildcard pattern - validated as MatchIDPattern parses it for matching instance values
+		if _, err := parsePattern(pattern); err != nil {
 			return &XGtsRefValidationError{
 				FieldPath:  fieldPath,
 				Value:      pattern,
 				RefPattern: pattern,
-				Reason:     fmt.Sprintf("Invalid GTS wildcard pattern: %s", pattern),
+				Reason:     err.Error(),
 			}
 		}
 		return nil
@@ -284,30 +507,26 @@ func (v *XGtsRefValidator) validateGtsPattern(value, pattern, fieldPath string)
 		}
 	}
 
-	// Check pattern match
-	if pattern == "gts.*" {
-		// Any valid GTS ID matches
-	} else if strings.HasSuffix(pattern, "*") {
-		prefix := pattern[:len(pattern)-1]
-		if !strings.HasPrefix(value, prefix) {
+	// Check pattern match by GTS segments, so that "v1~" does not match "v10~" and a pattern
+	// without minor version matches every minor version of the referenced type
+	if pattern != "gts.*" {
+		if match := MatchIDPattern(value, pattern); !match.Match {
+			reason := fmt.Sprintf("Value '%s' does not match pattern '%s'", value, pattern)
+			if match.Error != "" {
+				// The value is a valid GTS ID, so the pattern failed to parse
+				reason = fmt.Sprintf("Invalid x-gts-ref pattern '%s': %s", pattern, match.Error)
+			}
 			return &XGtsRefValidationError{
 				FieldPath:  fieldPath,
 				Value:      value,
 				RefPattern: pattern,
-				Reason:     fmt.Sprintf("Value '%s' does not match pattern '%s'", value, pattern),
+				Reason:     reason,
 			}
 		}
-	} else if !strings.HasPrefix(value, pattern) {
-		return &XGtsRefValidationError{
-			FieldPath:  fieldPath,
-			Value:      value,
-			RefPattern: pattern,
-			Reason:     fmt.Sprintf("Value '%s' does not match pattern '%s'", value, pattern),
-		}
 	}
 
 	// Optionally check if entity exists in store
-	if v.store != nil {
+	if v.store != nil && !v.patternsOnly {
 		entity := v.store.Get(value)
 		if entity == nil {
 			return &XGtsRefValidationError{
@@ -463,6 +463,39 @@ func TestXGtsRefValidator_ValidateGtsPattern(t *testing.T) {
 			shouldFail:    true,
 			errorContains: "does not match pattern",
 		},
+		{
+			name:          "major version is not a string prefix",
+			value:         "gts.x.test.ns.capability.v10~x.vendor._.ws.v1",
+			pattern:       "gts.x.test.ns.capability.v1~",
+			shouldFail:    true,
+			errorContains: "does not match pattern 'gts.x.test.ns.capability.v1~'",
+		},
+		{
+			name:       "pattern without minor version matches any minor",
+			value:      "gts.x.test.ns.capability.v1.3~x.vendor._.ws.v1",
+			pattern:    "gts.x.test.ns.capability.v1~",
+			shouldFail: false,
+		},
+		{
+			name:       "wildcard after a chained type",
+			value:      "gts.x.test.ns.capability.v1.2~x.vendor._.ws.v1",
+			pattern:    "gts.x.test.ns.capability.v1~*",
+			shouldFail: false,
+		},
+		{
+			name:          "pattern with minor version",
+			value:         "gts.x.test.ns.capability.v1.3~x.vendor._.ws.v1",
+			pattern:       "gts.x.test.ns.capability.v1.2~",
+			shouldFail:    true,
+			errorContains: "does not match pattern",
+		},
+		{
+			name:          "wildcard in the middle of a segment",
+			value:         "gts.x.test.ns.capability.v1~",
+			pattern:       "gts.x.te*",
+			shouldFail:    true,
+			errorContains: "Invalid x-gts-ref pattern 'gts.x.te*'",
+		},
 		{
 			name:          "invalid GTS ID",
 			value:         "not.gts.format",
@@ -491,6 +524,35 @@ func TestXGtsRefValidator_ValidateGtsPattern(t *testing.T) {
 	}
 }
 
+func TestGtsStore_ValidateInstanceWithXGtsRef_MinorVersions(t *testing.T) {
+	store := NewGtsStore(nil)
+	for _, id := range []string{"gts.x.test.ns.capability.v1.3~", "gts.x.test.ns.capability.v10~"} {
+		if err := store.RegisterSchema(id, map[string]any{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}); err != nil {
+			t.Fatalf("Failed to register schema: %v", err)
+		}
+	}
+	for _, id := range []string{"gts.x.test.ns.capability.v1.3~x.vendor._.ws.v1", "gts.x.test.ns.capability.v10~x.vendor._.ws.v1"} {
+		if err := store.Register(NewJsonEntity(map[string]any{"id": id}, DefaultGtsConfig())); err != nil {
+			t.Fatalf("Failed to register capability: %v", err)
+		}
+	}
+	validator := NewXGtsRefValidator(store)
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"capability": map[string]any{"type": "string", "x-gts-ref": "gts.x.test.ns.capability.v1~"},
+		},
+	}
+
+	if errs := validator.ValidateInstance(map[string]any{"capability": "gts.x.test.ns.capability.v1.3~x.vendor._.ws.v1"}, schema, ""); len(errs) != 0 {
+		t.Errorf("Expected a v1.3 capability to match a v1 pattern, got: %v", errs)
+	}
+	errs := validator.ValidateInstance(map[string]any{"capability": "gts.x.test.ns.capability.v10~x.vendor._.ws.v1"}, schema, "")
+	if len(errs) != 1 || errs[0].RefPattern != "gts.x.test.ns.capability.v1~" {
+		t.Errorf("Expected a v10 capability to be refused with the original pattern, got: %v", errs)
+	}
+}
+
 func TestGtsStore_ValidateSchemaWithXGtsRef(t *testing.T) {
 	store := NewGtsStore(nil)
 
@@ -781,3 +843,214 @@ func TestStripGtsURIPrefix(t *testing.T) {
 		})
 	}
 }
+
+// newCompositionRefStore registers the capability schema and instance of the module fixtures,
+// plus a base module schema declaring the capabilities property for $ref tests
+func newCompositionRefStore(t *testing.T) *GtsStore {
+	t.Helper()
+	store := NewGtsStore(nil)
+	entities := []map[string]interface{}{
+		{
+			"$id":     "gts.x.testref.ns.capability.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{"type": "string", "x-gts-ref": "/$id"},
+			},
+		},
+		{"id": "gts.x.testref.ns.capability.v1~x.vendor._.has_ws.v1", "description": "Has WebSocket"},
+		{
+			"$id":     "gts://gts.x.testref.ns.module_base.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]interface{}{
+				"capabilities": capabilitiesProperty(),
+			},
+		},
+	}
+	registerTestEntities(t, store, entities...)
+	return store
+}
+
+func capabilitiesProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":      "string",
+			"x-gts-ref": "gts.x.testref.ns.capability.v1~",
+		},
+	}
+}
+
+func TestXGtsRefValidator_ValidateInstance_Composition(t *testing.T) {
+	store := newCompositionRefStore(t)
+	validator := NewXGtsRefValidator(store)
+
+	allOfSchema := map[string]interface{}{
+		"$id": "gts://gts.x.testref.ns.module.v1~x.testref.ns.chat_module.v1~",
+		"allOf": []interface{}{
+			map[string]interface{}{"$ref": "gts://gts.x.testref.ns.module_base.v1~"},
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"extra_capabilities": capabilitiesProperty(),
+				},
+			},
+		},
+	}
+	oneOfSchema := map[string]interface{}{
+		"$id": "gts://gts.x.testref.ns.module.v1~",
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"kind":         map[string]interface{}{"const": "plugin"},
+					"capabilities": capabilitiesProperty(),
+				},
+			},
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"kind":         map[string]interface{}{"const": "external"},
+					"capabilities": map[string]interface{}{"type": "array"},
+				},
+			},
+		},
+	}
+	recursiveSchema := map[string]interface{}{
+		"$id": "gts://gts.x.testref.ns.module.v1~",
+		"$defs": map[string]interface{}{
+			"module": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"capabilities": capabilitiesProperty(),
+					"submodules": map[string]interface{}{
+						"type":  "array",
+						"items": map[string]interface{}{"$ref": "#/$defs/module"},
+					},
+				},
+			},
+		},
+		"$ref": "#/$defs/module",
+	}
+
+	const valid = "gts.x.testref.ns.capability.v1~x.vendor._.has_ws.v1"
+	const wrongPrefix = "gts.y.other._.capability.v1~x.vendor._.foo.v1"
+	const missing = "gts.x.testref.ns.capability.v1~x.vendor._.nonexistent.v1"
+	tests := []struct {
+		name          string
+		schema        map[string]interface{}
+		instance      map[string]interface{}
+		errorContains string
+	}{
+		{"allOf $ref member valid", allOfSchema, map[string]interface{}{"capabilities": []interface{}{valid}}, ""},
+		{"allOf $ref member wrong prefix", allOfSchema, map[string]interface{}{"capabilities": []interface{}{wrongPrefix}}, "does not match pattern"},
+		{"allOf inline member missing capability", allOfSchema, map[string]interface{}{"extra_capabilities": []interface{}{missing}}, "not found in registry"},
+		{"oneOf matching branch valid", oneOfSchema, map[string]interface{}{"kind": "plugin", "capabilities": []interface{}{valid}}, ""},
+		{"oneOf matching branch wrong prefix", oneOfSchema, map[string]interface{}{"kind": "plugin", "capabilities": []interface{}{wrongPrefix}}, "does not match pattern"},
+		{"oneOf other branch unconstrained", oneOfSchema, map[string]interface{}{"kind": "external", "capabilities": []interface{}{wrongPrefix}}, ""},
+		{"recursive $ref valid", recursiveSchema, map[string]interface{}{
+			"submodules": []interface{}{map[string]interface{}{"capabilities": []interface{}{valid}}},
+		}, ""},
+		{"recursive $ref nested missing capability", recursiveSchema, map[string]interface{}{
+			"submodules": []interface{}{map[string]interface{}{"capabilities": []interface{}{missing}}},
+		}, "not found in registry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validator.ValidateInstance(tt.instance, tt.schema, "")
+			if tt.errorContains == "" {
+				if len(errs) > 0 {
+					t.Errorf("Expected validation to pass, got %v", errs)
+				}
+				return
+			}
+			found := false
+			for _, err := range errs {
+				found = found || strings.Contains(err.Error(), tt.errorContains)
+			}
+			if !found {
+				t.Errorf("Expected an error containing '%s', got %v", tt.errorContains, errs)
+			}
+		})
+	}
+
+	// A schema referring to itself for the same instance node terminates
+	selfRef := map[string]interface{}{
+		"$ref":       "#",
+		"properties": map[string]interface{}{"capabilities": capabilitiesProperty()},
+	}
+	if errs := validator.ValidateInstance(map[string]interface{}{"capabilities": []interface{}{wrongPrefix}}, selfRef, ""); len(errs) != 1 {
+		t.Errorf("Expected the self-referencing schema to report one error, got %v", errs)
+	}
+
+	// Without a store, GTS $refs cannot be followed
+	if errs := NewXGtsRefValidator(nil).ValidateInstance(map[string]interface{}{"capabilities": []interface{}{wrongPrefix}}, allOfSchema, ""); len(errs) != 0 {
+		t.Errorf("Expected the GTS $ref to be skipped without a store, got %v", errs)
+	}
+}
+
+func TestValidateInstance_XGtsRefModes(t *testing.T) {
+	const instanceID = "gts.x.test.xref.base.v1~x.test.xref.leaf.v1~x.test._.a.v1"
+	setup := func(mode XGtsRefMode, capability string) *GtsStore {
+		t.Helper()
+		store := NewGtsStoreWithConfig(nil, &RegistryConfig{XGtsRefValidation: mode})
+		// The x-gts-ref is declared by the base schema, which the leaf schema references with allOf
+		base := map[string]any{
+			"$id":     "gts://gts.x.test.xref.base.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+			"properties": map[string]any{
+				"id":         map[string]any{"type": "string"},
+				"capability": map[string]any{"type": "string", "x-gts-ref": "gts.x.test.xref.cap.v1~"},
+			},
+		}
+		leaf := map[string]any{
+			"$id":     "gts://gts.x.test.xref.base.v1~x.test.xref.leaf.v1~",
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"allOf":   []any{map[string]any{"$ref": "gts://gts.x.test.xref.base.v1~"}, map[string]any{"type": "object"}},
+		}
+		instance := map[string]any{"id": instanceID, "capability": capability}
+		registerTestEntities(t, store, base, leaf, instance)
+		return store
+	}
+
+	tests := []struct {
+		name       string
+		mode       XGtsRefMode
+		capability string
+		ok         bool
+	}{
+		{"full, unregistered reference", XGtsRefFull, "gts.x.test.xref.cap.v1~x.test._.missing.v1", false},
+		{"full, mismatched reference", XGtsRefFull, "gts.x.test.xref.other.v1~x.test._.a.v1", false},
+		{"patterns, unregistered reference", XGtsRefPatternsOnly, "gts.x.test.xref.cap.v1~x.test._.missing.v1", true},
+		{"patterns, mismatched reference", XGtsRefPatternsOnly, "gts.x.test.xref.other.v1~x.test._.a.v1", false},
+		{"off, mismatched reference", XGtsRefOff, "gts.x.test.xref.other.v1~x.test._.a.v1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := setup(tt.mode, tt.capability).ValidateInstance(instanceID)
+			if result.OK != tt.ok {
+				t.Fatalf("Expected ok=%v, got %+v", tt.ok, result)
+			}
+			if !tt.ok && (len(result.Violations) != 1 || result.Violations[0].Keyword != "x-gts-ref") {
+				t.Errorf("Expected an x-gts-ref violation, got %+v", result.Violations)
+			}
+		})
+	}
+}
+
+func TestParseXGtsRefMode(t *testing.T) {
+	for name, expected := range map[string]XGtsRefMode{"": XGtsRefFull, "full": XGtsRefFull, "Patterns": XGtsRefPatternsOnly, "off": XGtsRefOff} {
+		if mode, err := ParseXGtsRefMode(name); err != nil || mode != expected {
+			t.Errorf("ParseXGtsRefMode(%q) = %v, %v", name, mode, err)
+		}
+		if name != "" && !strings.EqualFold(expected.String(), name) {
+			t.Errorf("Expected %v to be named %q", expected, name)
+		}
+	}
+	if _, err := ParseXGtsRefMode("strict"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
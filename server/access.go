@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// AccessConfig configures cross-origin access and authentication of the server
+type AccessConfig struct {
+	// CORSOrigins lists the origins, e.g. "https://ui.example.com", allowed to call the API from a
+	// browser; "*" allows any origin. Without origins no CORS headers are sent.
+	CORSOrigins []string
+	// AuthToken, when set, must be sent as "Authorization: Bearer <token>" on POST, PUT, PATCH and
+	// DELETE requests, which are answered 401 otherwise
+	AuthToken string
+	// AuthReads requires the token on GET and HEAD requests too
+	AuthReads bool
+}
+
+// CORS headers of preflight answers
+const (
+	corsAllowMethods  = "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Authorization, Content-Type, If-None-Match, " + requestIDHeader
+	corsExposeHeaders = "ETag, " + requestIDHeader + ", " + normalizedIDHeader
+	corsMaxAge        = "600"
+)
+
+// SetAccessConfig sets the CORS origins and the auth token of the server
+func (s *Server) SetAccessConfig(cfg AccessConfig) {
+	s.access = cfg
+}
+
+// ParseCORSOrigins splits a comma-separated list of CORS origins, e.g. the value of a command-line
+// flag, dropping blanks and trailing slashes
+func ParseCORSOrigins(list string) []string {
+	var origins []string
+	for _, origin := range strings.Split(list, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// withCORS adds the Access-Control-Allow-* headers to the responses of allowed origins and answers
+// their preflight requests with 204. It wraps withAuth, so that preflights need no token and 401
+// answers can be read by the browser.
+func (s *Server) withCORS(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if len(s.access.CORSOrigins) == 0 || origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		allowed := s.corsOriginAllowed(origin)
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if allowed {
+			if slices.Contains(s.access.CORSOrigins, "*") {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if allowed {
+				header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+				header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				header.Set("Access-Control-Max-Age", corsMaxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// corsOriginAllowed reports whether origin is one of the configured CORS origins
+func (s *Server) corsOriginAllowed(origin string) bool {
+	for _, allowed := range s.access.CORSOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// withAuth answers 401 to the requests that need the auth token and do not carry it
+func (s *Server) withAuth(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.access.AuthToken == "" || !s.requiresAuth(r.Method) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.access.AuthToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gts"`)
+			message := "Missing bearer token"
+			if ok {
+				message = "Invalid bearer token"
+			}
+			s.writeError(w, http.StatusUnauthorized, message)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// requiresAuth reports whether requests with the method need the auth token
+func (s *Server) requiresAuth(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	case http.MethodOptions:
+		return false
+	default:
+		return s.access.AuthReads
+	}
+}
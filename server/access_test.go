@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+const testEntity = `{"id": "gts.x.test.access.item.v1~x.test._.a.v1"}`
+
+func newAccessTestServer(t *testing.T, cfg AccessConfig) *httptest.Server {
+	t.Helper()
+	srv := NewServer(gts.NewGtsStore(nil), "127.0.0.1", 0, 0)
+	srv.SetAccessConfig(cfg)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// doRequest sends a request with the given headers and returns the response, whose body is closed
+// when the test ends
+func doRequest(t *testing.T, method, url, body string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	ts := newAccessTestServer(t, AccessConfig{CORSOrigins: []string{"https://ui.example.com"}, AuthToken: "secret"})
+
+	preflight := map[string]string{"Origin": "https://ui.example.com", "Access-Control-Request-Method": "POST"}
+	resp := doRequest(t, http.MethodOptions, ts.URL+"/entities", "", preflight)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for a preflight without token, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+		t.Errorf("expected the origin to be allowed, got %q", got)
+	}
+	if !strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "Authorization") ||
+		!strings.Contains(resp.Header.Get("Access-Control-Allow-Methods"), "DELETE") {
+		t.Errorf("expected the allowed methods and headers, got %v", resp.Header)
+	}
+
+	preflight["Origin"] = "https://other.example.com"
+	resp = doRequest(t, http.MethodOptions, ts.URL+"/entities", "", preflight)
+	if resp.Header.Get("Access-Control-Allow-Origin") != "" || resp.Header.Get("Access-Control-Allow-Methods") != "" {
+		t.Errorf("expected no CORS headers for another origin, got %v", resp.Header)
+	}
+
+	resp = doRequest(t, http.MethodGet, ts.URL+"/validate-id?gts_id=gts.x.test.access.item.v1~", "", map[string]string{"Origin": "https://ui.example.com"})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "https://ui.example.com" ||
+		!strings.Contains(resp.Header.Get("Access-Control-Expose-Headers"), requestIDHeader) {
+		t.Errorf("expected CORS headers on a simple request, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	ts = newAccessTestServer(t, AccessConfig{})
+	resp = doRequest(t, http.MethodGet, ts.URL+"/validate-id?gts_id=gts.x.test.access.item.v1~", "", map[string]string{"Origin": "https://ui.example.com"})
+	if resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected no CORS headers without configured origins")
+	}
+}
+
+func TestAuthToken(t *testing.T) {
+	ts := newAccessTestServer(t, AccessConfig{CORSOrigins: []string{"*"}, AuthToken: "secret"})
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		message       string
+	}{
+		{"missing token", "", http.StatusUnauthorized, "Missing bearer token"},
+		{"wrong scheme", "Basic c2VjcmV0", http.StatusUnauthorized, "Missing bearer token"},
+		{"wrong token", "Bearer guess", http.StatusUnauthorized, "Invalid bearer token"},
+		{"valid token", "Bearer secret", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Origin": "https://ui.example.com"}
+			if tt.authorization != "" {
+				headers["Authorization"] = tt.authorization
+			}
+			resp := doRequest(t, http.MethodPost, ts.URL+"/entities", testEntity, headers)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+			if resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+				t.Error("expected CORS headers on every answer")
+			}
+			if tt.status != http.StatusUnauthorized {
+				return
+			}
+			var body errorEnvelope
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+				t.Fatalf("expected an error envelope: %v", err)
+			}
+			if body.Error.Code != ErrorCodeUnauthorized || body.Error.Message != tt.message || resp.Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("expected %s %q with a challenge, got %+v", ErrorCodeUnauthorized, tt.message, body.Error)
+			}
+		})
+	}
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		if resp := doRequest(t, method, ts.URL+"/entities/gts.x.test.access.item.v1~x.test._.a.v1", testEntity, nil); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected 401 for %s without token, got %d", method, resp.StatusCode)
+		}
+	}
+	if resp := doRequest(t, http.MethodGet, ts.URL+"/entities/gts.x.test.access.item.v1~x.test._.a.v1", "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected reads to stay open, got %d", resp.StatusCode)
+	}
+
+	ts = newAccessTestServer(t, AccessConfig{AuthToken: "secret", AuthReads: true})
+	if resp := doRequest(t, http.MethodGet, ts.URL+"/entities", "", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a read with AuthReads, got %d", resp.StatusCode)
+	}
+	if resp := doRequest(t, http.MethodGet, ts.URL+"/entities", "", map[string]string{"Authorization": "Bearer secret"}); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for a read with the token, got %d", resp.StatusCode)
+	}
+}
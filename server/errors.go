@@ -0,0 +1,282 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+// Error codes of the error envelope. Every failed request is answered with
+// {"error": {"code": ..., "message": ..., "details": ...}}; the code tells what went wrong
+// independently of the endpoint and the message is meant for humans.
+const (
+	// ErrorCodeInvalidID is answered with 422 for malformed GTS IDs and IDs over the configured limits
+	ErrorCodeInvalidID = "GTS_INVALID_ID"
+	// ErrorCodeEntityNotFound is answered with 404 when the requested entity is not registered
+	ErrorCodeEntityNotFound = "GTS_ENTITY_NOT_FOUND"
+	// ErrorCodeSchemaNotFound is answered with 404 when a schema an operation needs is not registered
+	ErrorCodeSchemaNotFound = "GTS_SCHEMA_NOT_FOUND"
+	// ErrorCodeAttributeNotFound is answered with 404 when an attribute path names a field or array
+	// element the entity does not have
+	ErrorCodeAttributeNotFound = "GTS_ATTRIBUTE_NOT_FOUND"
+	// ErrorCodeValidationFailed is answered with 422 when content is rejected by validation
+	ErrorCodeValidationFailed = "GTS_VALIDATION_FAILED"
+	// ErrorCodeConflict is answered with 409 when the request conflicts with the store state
+	ErrorCodeConflict = "GTS_CONFLICT"
+	// ErrorCodeBadRequest is answered with 400 for malformed requests, or 413 for uploads over the size limits
+	ErrorCodeBadRequest = "GTS_BAD_REQUEST"
+	// ErrorCodeUnauthorized is answered with 401 when a request needs the auth token and does not carry it
+	ErrorCodeUnauthorized = "GTS_UNAUTHORIZED"
+	// ErrorCodeInternal is answered with 500 when the server failed
+	ErrorCodeInternal = "GTS_INTERNAL"
+)
+
+// APIError is the error object of an error response
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details holds machine-readable context specific to the code, e.g. the offending limit of an ID
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// errorEnvelope is the body of an error response
+type errorEnvelope struct {
+	Error *APIError `json:"error"`
+}
+
+// codeForStatus returns the error code of failures the handlers detect themselves
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		return ErrorCodeEntityNotFound
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeValidationFailed
+	case http.StatusInternalServerError:
+		return ErrorCodeInternal
+	default:
+		return ErrorCodeBadRequest
+	}
+}
+
+// translateError maps an error returned by the gts package to the HTTP status and error object of
+// its response. Errors without a dedicated type are raised when the store rejects content
+// (reference, x-gts-ref, tag and CloudEvent validation among others), so they are validation failures.
+func translateError(err error) (int, *APIError) {
+	apiErr := &APIError{Message: err.Error()}
+
+	var (
+		frozenErr        *gts.StoreFrozenError
+		dependentsErr    *gts.DependentInstancesInvalidError
+		referencedErr    *gts.EntityReferencedError
+		replaceIDErr     *gts.ReplaceIDMismatchError
+		changedErr       *gts.EntityChangedError
+		objectErr        *gts.StoreGtsObjectNotFoundError
+		resolutionErr    *gts.SchemaResolutionError
+		schemaErr        *gts.StoreGtsSchemaNotFoundError
+		instanceErr      *gts.StoreGtsSchemaForInstanceNotFoundError
+		bundleSchemaErr  *gts.BundleSchemaNotFoundError
+		castFromErr      *gts.StoreGtsCastFromSchemaNotAllowedError
+		limitErr         *gts.IDLimitError
+		invalidIDErr     *gts.InvalidGtsIDError
+		invalidSegErr    *gts.InvalidSegmentError
+		invalidWildErr   *gts.InvalidWildcardError
+		archiveMemberErr *gts.ArchiveMemberTooLargeError
+		archiveTotalErr  *gts.ArchiveTooLargeError
+		archiveDepthErr  *gts.ArchiveNestingTooDeepError
+		batchErr         *gts.BatchRejectedError
+		schemaIDErr      *gts.SchemaIDConflictError
+		idTypeErr        *gts.IDTypeMismatchError
+		shortIDErr       *gts.ShortIDCollisionError
+		typeIDErr        *gts.StoreInvalidSchemaTypeIDError
+		schemaIDMismatch *gts.StoreSchemaIDMismatchError
+		bundleErr        *gts.InvalidBundleError
+		majorCastErr     *gts.StoreGtsMajorCastRequiresMappingError
+		migrationErr     *gts.InvalidMigrationMapError
+	)
+	switch {
+	case errors.Is(err, gts.ErrInternal):
+		apiErr.Code = ErrorCodeInternal
+		return http.StatusInternalServerError, apiErr
+	case errors.As(err, &frozenErr):
+		apiErr.Code = ErrorCodeConflict
+		apiErr.Details = map[string]any{"frozen": true}
+		return http.StatusConflict, apiErr
+	case errors.As(err, &shortIDErr):
+		apiErr.Code = ErrorCodeConflict
+		apiErr.Details = map[string]any{"short_id": shortIDErr.ShortID, "existing": shortIDErr.Existing}
+		return http.StatusConflict, apiErr
+	case errors.As(err, &dependentsErr):
+		apiErr.Code = ErrorCodeConflict
+		apiErr.Details = map[string]any{"dependents": dependentsErr.Report}
+		return http.StatusConflict, apiErr
+	case errors.As(err, &referencedErr):
+		apiErr.Code = ErrorCodeConflict
+		apiErr.Details = map[string]any{"gts_id": referencedErr.EntityID, "referenced_by": referencedErr.ReferencedBy}
+		return http.StatusConflict, apiErr
+	case errors.As(err, &replaceIDErr):
+		apiErr.Code = ErrorCodeConflict
+		apiErr.Details = map[string]any{"path_id": replaceIDErr.EntityID, "body_id": replaceIDErr.ContentID}
+		return http.StatusConflict, apiErr
+	case errors.As(err, &changedErr):
+		apiErr.Code = ErrorCodeConflict
+		apiErr.Details = map[string]any{"gts_id": changedErr.EntityID}
+		return http.StatusConflict, apiErr
+	case errors.As(err, &objectErr):
+		apiErr.Code = ErrorCodeEntityNotFound
+		apiErr.Details = map[string]any{"gts_id": objectErr.EntityID}
+		return http.StatusNotFound, apiErr
+	case errors.As(err, &resolutionErr):
+		apiErr.Code = ErrorCodeSchemaNotFound
+		apiErr.Details = map[string]any{"instance_id": resolutionErr.EntityID, "schema_resolution": resolutionErr.Trace}
+		return http.StatusNotFound, apiErr
+	case errors.As(err, &schemaErr):
+		apiErr.Code = ErrorCodeSchemaNotFound
+		apiErr.Details = map[string]any{"gts_id": schemaErr.EntityID}
+		return http.StatusNotFound, apiErr
+	case errors.As(err, &instanceErr):
+		apiErr.Code = ErrorCodeSchemaNotFound
+		apiErr.Details = map[string]any{"instance_id": instanceErr.EntityID}
+		return http.StatusNotFound, apiErr
+	case errors.As(err, &bundleSchemaErr):
+		apiErr.Code = ErrorCodeSchemaNotFound
+		apiErr.Details = map[string]any{"gts_id": bundleSchemaErr.SchemaID}
+		return http.StatusNotFound, apiErr
+	case errors.As(err, &castFromErr):
+		apiErr.Code = ErrorCodeBadRequest
+		apiErr.Details = map[string]any{"gts_id": castFromErr.FromID}
+		return http.StatusBadRequest, apiErr
+	case errors.As(err, &majorCastErr):
+		apiErr.Code = ErrorCodeBadRequest
+		apiErr.Details = map[string]any{"from_id": majorCastErr.FromID, "to_id": majorCastErr.ToID}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &migrationErr):
+		apiErr.Code = ErrorCodeBadRequest
+		apiErr.Details = map[string]any{"migration_map_id": migrationErr.MapID}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &limitErr):
+		apiErr.Code = ErrorCodeInvalidID
+		apiErr.Details = map[string]any{
+			"gts_id": limitErr.GtsID,
+			"limit": map[string]any{
+				"name":   limitErr.Limit,
+				"actual": limitErr.Actual,
+				"max":    limitErr.Max,
+			},
+		}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &invalidIDErr), errors.As(err, &invalidSegErr), errors.As(err, &invalidWildErr):
+		apiErr.Code = ErrorCodeInvalidID
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &typeIDErr):
+		apiErr.Code = ErrorCodeInvalidID
+		apiErr.Details = map[string]any{"type_id": typeIDErr.TypeID}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &schemaIDMismatch):
+		apiErr.Code = ErrorCodeValidationFailed
+		apiErr.Details = map[string]any{"type_id": schemaIDMismatch.TypeID, "content_id": schemaIDMismatch.ContentID}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &bundleErr):
+		apiErr.Code = ErrorCodeBadRequest
+		return http.StatusBadRequest, apiErr
+	case errors.As(err, &archiveMemberErr), errors.As(err, &archiveTotalErr), errors.As(err, &archiveDepthErr):
+		apiErr.Code = ErrorCodeBadRequest
+		return http.StatusRequestEntityTooLarge, apiErr
+	case errors.As(err, &schemaIDErr):
+		apiErr.Code = ErrorCodeValidationFailed
+		apiErr.Details = map[string]any{"selected": schemaIDErr.Selected, "conflicting": schemaIDErr.Conflicting}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &idTypeErr):
+		apiErr.Code = ErrorCodeValidationFailed
+		apiErr.Details = map[string]any{"chain_schema_id": idTypeErr.ChainSchemaID, "field": idTypeErr.Field, "declared_schema_id": idTypeErr.DeclaredSchemaID}
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &batchErr):
+		apiErr.Code = ErrorCodeValidationFailed
+		return http.StatusUnprocessableEntity, apiErr
+	default:
+		apiErr.Code = ErrorCodeValidationFailed
+		return http.StatusUnprocessableEntity, apiErr
+	}
+}
+
+// writeAPIError writes an error envelope
+func (s *Server) writeAPIError(w http.ResponseWriter, status int, apiErr *APIError) {
+	s.writeJSON(w, status, errorEnvelope{Error: apiErr})
+}
+
+// writeError writes an error envelope for a failure detected by the handler itself, with the code
+// matching the status
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeAPIError(w, status, &APIError{Code: codeForStatus(status), Message: message})
+}
+
+// writeErrorCode writes an error envelope with an explicit code and optional details
+func (s *Server) writeErrorCode(w http.ResponseWriter, status int, code, message string, details map[string]any) {
+	s.writeAPIError(w, status, &APIError{Code: code, Message: message, Details: details})
+}
+
+// writeStoreError writes the error envelope of an error returned by the gts package. Internal
+// errors are answered like recovered panics, with the request ID and without the panic value.
+func (s *Server) writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	status, apiErr := translateError(err)
+	if status == http.StatusInternalServerError {
+		s.writeInternalError(w, r)
+		return
+	}
+	s.writeAPIError(w, status, apiErr)
+}
+
+// writeValidationError writes the error envelope of a failed validation; the violations of an
+// instance rejected by its schema are listed in the details
+func (s *Server) writeValidationError(w http.ResponseWriter, r *http.Request, result *gts.ValidationResult) {
+	status, apiErr := translateError(result.Err)
+	if status == http.StatusInternalServerError {
+		s.writeInternalError(w, r)
+		return
+	}
+	if len(result.Violations) > 0 {
+		if apiErr.Details == nil {
+			apiErr.Details = map[string]any{}
+		}
+		apiErr.Details["violations"] = result.Violations
+	}
+	s.writeAPIError(w, status, apiErr)
+}
+
+// writeInternalError answers 500 with the request ID so the failure can be found in the logs
+func (s *Server) writeInternalError(w http.ResponseWriter, r *http.Request) {
+	s.writeAPIError(w, http.StatusInternalServerError, &APIError{
+		Code:    ErrorCodeInternal,
+		Message: "Internal server error",
+		Details: map[string]any{"request_id": requestIDFromContext(r.Context())},
+	})
+}
+
+// failedItem describes a failed entity of a bulk request: the results of bulk requests keep their
+// per-entity shape, with the error code and details of the failure merged into the entity result
+func failedItem(err error) map[string]any {
+	_, apiErr := translateError(err)
+	return failedItemWithCode(apiErr.Code, apiErr.Message, apiErr.Details)
+}
+
+// failedItemWithCode describes a failed entity of a bulk request from a code and message
+func failedItemWithCode(code, message string, details map[string]any) map[string]any {
+	item := map[string]any{
+		"ok":    false,
+		"code":  code,
+		"error": message,
+	}
+	for k, v := range details {
+		item[k] = v
+	}
+	return item
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+// TestTranslateError checks that every error type of the gts package maps to its documented
+// code and HTTP status, also when wrapped
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"replace ID mismatch", &gts.ReplaceIDMismatchError{EntityID: "gts.x.a.b.c.v1~", ContentID: "gts.x.a.b.d.v1~"}, http.StatusConflict, ErrorCodeConflict},
+		{"entity changed", &gts.EntityChangedError{EntityID: "gts.x.a.b.c.v1~"}, http.StatusConflict, ErrorCodeConflict},
+		{"object not found", &gts.StoreGtsObjectNotFoundError{EntityID: "gts.x.a.b.c.v1~x.a.b.c.v1"}, http.StatusNotFound, ErrorCodeEntityNotFound},
+		{"schema not found", &gts.StoreGtsSchemaNotFoundError{EntityID: "gts.x.a.b.c.v1~"}, http.StatusNotFound, ErrorCodeSchemaNotFound},
+		{"schema for instance not found", &gts.StoreGtsSchemaForInstanceNotFoundError{EntityID: "gts.x.a.b.c.v1~x.a.b.c.v1"}, http.StatusNotFound, ErrorCodeSchemaNotFound},
+		{"schema resolution failed", &gts.SchemaResolutionError{EntityID: "gts.x.a.b.c.v1~x.a.b.c.v1", Trace: []gts.ResolutionStep{{Source: gts.SchemaSourceChain, Value: "gts.x.a.b.c.v1~", Skipped: gts.ResolutionSkipNotRegistered}}}, http.StatusNotFound, ErrorCodeSchemaNotFound},
+		{"bundle schema not found", &gts.BundleSchemaNotFoundError{SchemaID: "gts.x.a.b.c.v1~"}, http.StatusNotFound, ErrorCodeSchemaNotFound},
+		{"cast from schema", &gts.StoreGtsCastFromSchemaNotAllowedError{FromID: "gts.x.a.b.c.v1~"}, http.StatusBadRequest, ErrorCodeBadRequest},
+		{"frozen", &gts.StoreFrozenError{Operation: "registration"}, http.StatusConflict, ErrorCodeConflict},
+		{"short ID collision", &gts.ShortIDCollisionError{ShortID: "aaaaaaaaaaaaaaaa", ID: "gts.x.a.b.c.v1~", Existing: "gts.x.a.b.d.v1~"}, http.StatusConflict, ErrorCodeConflict},
+		{"dependents invalid", &gts.DependentInstancesInvalidError{Report: &gts.DependentsReport{SchemaID: "gts.x.a.b.c.v1~"}}, http.StatusConflict, ErrorCodeConflict},
+		{"ID limit", &gts.IDLimitError{GtsID: "gts.x.a.b.c.v1~", Limit: gts.IDLimitLength, Actual: 20, Max: 10}, http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"invalid ID", &gts.InvalidGtsIDError{GtsID: "gts.bad"}, http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"invalid segment", &gts.InvalidSegmentError{Num: 1, Segment: "bad"}, http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"invalid wildcard", &gts.InvalidWildcardError{Pattern: "gts.*.a"}, http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"schema ID conflict", &gts.SchemaIDConflictError{EntityID: "gts.x.a.b.c.v1~x.a.b.c.v1"}, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"ID type mismatch", &gts.IDTypeMismatchError{EntityID: "gts.x.a.b.c.v1~x.a.b.c.v1", ChainSchemaID: "gts.x.a.b.c.v1~", Field: "type", DeclaredSchemaID: "gts.x.a.b.d.v1~"}, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"batch rejected", &gts.BatchRejectedError{Failed: 1, Total: 2}, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"archive member too large", &gts.ArchiveMemberTooLargeError{Path: "a.zip/b.json", Size: 20, Limit: 10}, http.StatusRequestEntityTooLarge, ErrorCodeBadRequest},
+		{"archive too large", &gts.ArchiveTooLargeError{Path: "a.zip", Size: 30, Limit: 20}, http.StatusRequestEntityTooLarge, ErrorCodeBadRequest},
+		{"archive nesting too deep", &gts.ArchiveNestingTooDeepError{Path: "a.zip/b.zip", Limit: 1}, http.StatusRequestEntityTooLarge, ErrorCodeBadRequest},
+		{"invalid bundle", &gts.InvalidBundleError{Err: errors.New("unexpected EOF")}, http.StatusBadRequest, ErrorCodeBadRequest},
+		{"major cast without mapping", &gts.StoreGtsMajorCastRequiresMappingError{FromID: "gts.x.a.b.c.v1~", ToID: "gts.x.a.b.c.v2~"}, http.StatusUnprocessableEntity, ErrorCodeBadRequest},
+		{"invalid migration map", &gts.InvalidMigrationMapError{MapID: "gts.x.core.migration.map.v1~x.a._.m.v1", Reason: "unknown op"}, http.StatusUnprocessableEntity, ErrorCodeBadRequest},
+		{"invalid tag", &gts.InvalidTagError{Key: "", Reason: "key must not be empty"}, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"invalid CloudEvent", &gts.CloudEventError{Attribute: "type", Reason: "missing"}, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"unresolved bundle refs", &gts.BundleUnresolvedRefsError{Refs: map[string][]string{"gts.x.a.b.c.v1~": {"gts.x.a.b.d.v1~"}}}, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"internal", &gts.StoreInternalError{Operation: "Cast", Value: "boom"}, http.StatusInternalServerError, ErrorCodeInternal},
+		{"untyped", errors.New("schema validation failed"), http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"wrapped", fmt.Errorf("registering: %w", &gts.StoreGtsSchemaNotFoundError{EntityID: "gts.x.a.b.c.v1~"}), http.StatusNotFound, ErrorCodeSchemaNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, apiErr := translateError(tt.err)
+			if status != tt.status || apiErr.Code != tt.code {
+				t.Errorf("expected %d %s, got %d %s", tt.status, tt.code, status, apiErr.Code)
+			}
+			if apiErr.Message != tt.err.Error() {
+				t.Errorf("expected the error message to be kept, got %q", apiErr.Message)
+			}
+		})
+	}
+}
@@ -6,13 +6,27 @@ Released under Apache License 2.0
 package server
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/GlobalTypeSystem/gts-go/gts"
+	"github.com/google/uuid"
 )
 
+// noGtsIDMessage is the error message of entities without a GTS ID
+const noGtsIDMessage = "Unable to extract GTS ID from entity"
+
 // Entity Management Handlers
 
 func (s *Server) handleGetEntities(w http.ResponseWriter, r *http.Request) {
@@ -24,7 +38,38 @@ func (s *Server) handleGetEntities(w http.ResponseWriter, r *http.Request) {
 		limit = 1000
 	}
 
-	result := s.store.List(limit)
+	if since := r.URL.Query().Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid since: expected an RFC3339 timestamp")
+			return
+		}
+		changed := s.store.ChangedSince(t)
+		result := &gts.ListResult{Entities: changed, Total: len(changed)}
+		if len(changed) > limit {
+			result.Entities = changed[:limit]
+		}
+		result.Count = len(result.Entities)
+		s.writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	kind, err := gts.ParseListKind(r.URL.Query().Get("kind"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	result, err := s.store.ListFiltered(gts.ListOptions{
+		Limit:   limit,
+		Cursor:  r.URL.Query().Get("cursor"),
+		Kind:    kind,
+		Pattern: r.URL.Query().Get("pattern"),
+		Orphans: r.URL.Query().Get("orphans") == "true",
+	})
+	if err != nil {
+		s.writeQueryError(w, r, err)
+		return
+	}
 	s.writeJSON(w, http.StatusOK, result)
 }
 
@@ -35,30 +80,188 @@ func (s *Server) handleGetEntity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entity := s.store.Get(id)
+	entity, err := s.lookupEntity(w, id)
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+	s.writeEntity(w, r, entity)
+}
+
+// handleGetEntityByUUID returns the registered entity whose ID derives the UUID in the path
+func (s *Server) handleGetEntityByUUID(w http.ResponseWriter, r *http.Request) {
+	u := r.PathValue("uuid")
+	if err := uuid.Validate(u); err != nil {
+		s.writeErrorCode(w, http.StatusBadRequest, ErrorCodeBadRequest, fmt.Sprintf("Invalid UUID '%s'", u), map[string]any{"uuid": u})
+		return
+	}
+
+	entity := s.store.GetByUUID(u)
+	if entity == nil {
+		s.writeErrorCode(w, http.StatusNotFound, ErrorCodeEntityNotFound, fmt.Sprintf("No registered entity has UUID %s", u), map[string]any{"uuid": u})
+		return
+	}
+	s.writeEntity(w, r, entity)
+}
+
+// writeEntity answers an entity lookup with the entity, or the values of the selected paths
+func (s *Server) writeEntity(w http.ResponseWriter, r *http.Request, entity *gts.JsonEntity) {
+	// The ETag covers the whole representation, also for partial responses; a matching
+	// If-None-Match is answered with 304
+	tags := s.store.GetTags(entity.GtsID.ID)
+	etag := entityETag(entity, tags)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// With path or fields selectors only the selected values are returned
+	if selectors := entitySelectors(r); len(selectors) > 0 {
+		partial, err := s.store.GetPartial(entity.GtsID.ID, selectors)
+		if err != nil {
+			s.writeStoreError(w, r, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, partial)
+		return
+	}
+
+	short, _ := gts.ShortID(entity.GtsID.ID)
+	response := map[string]any{
+		"id":            entity.GtsID.ID,
+		"short_id":      short,
+		"content":       entity.Content,
+		"content_hash":  entity.ContentHash,
+		"registered_at": entity.RegisteredAt,
+		"updated_at":    entity.UpdatedAt,
+	}
+	if tags != nil {
+		response["tags"] = tags
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// entityETag derives the ETag of an entity from what its response carries besides the ID: the
+// content hash, the update time and the tags, which change without the content
+func entityETag(entity *gts.JsonEntity, tags map[string]string) string {
+	data, _ := json.Marshal(struct {
+		ContentHash string            `json:"content_hash"`
+		UpdatedAt   time.Time         `json:"updated_at"`
+		Tags        map[string]string `json:"tags,omitempty"`
+	}{entity.ContentHash, entity.UpdatedAt, tags})
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// entitySelectors returns the attribute paths of the path and comma-separated fields parameters
+func entitySelectors(r *http.Request) []string {
+	query := r.URL.Query()
+	var selectors []string
+	for _, path := range query["path"] {
+		if path = strings.TrimSpace(path); path != "" {
+			selectors = append(selectors, path)
+		}
+	}
+	for _, fields := range query["fields"] {
+		for _, path := range strings.Split(fields, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				selectors = append(selectors, path)
+			}
+		}
+	}
+	return selectors
+}
+
+// normalizedIDHeader tells the ID or query pattern a request was answered for, when the store's
+// lenient lookup normalized the one requested (see gts.RegistryConfig.LenientLookup)
+const normalizedIDHeader = "X-GTS-Normalized-ID"
+
+// lookupEntity returns the entity of an {id} path value, which is a GTS ID or a short ID, and sets
+// the normalized ID header when the entity was found through the lenient lookup
+func (s *Server) lookupEntity(w http.ResponseWriter, id string) (*gts.JsonEntity, error) {
+	if gts.IsShortID(id) {
+		return s.store.FindByShortID(id)
+	}
+	entity, normalizedID := s.store.Lookup(id)
 	if entity == nil {
-		s.writeError(w, http.StatusNotFound, fmt.Sprintf("Entity not found: %s", id))
+		return nil, &gts.StoreGtsObjectNotFoundError{EntityID: id}
+	}
+	if normalizedID != "" {
+		w.Header().Set(normalizedIDHeader, normalizedID)
+	}
+	return entity, nil
+}
+
+// handleSetTags replaces the tags of an entity with the JSON object in the request body
+func (s *Server) handleSetTags(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if gts.IsShortID(id) {
+		entity, err := s.store.FindByShortID(id)
+		if err != nil {
+			s.writeStoreError(w, r, err)
+			return
+		}
+		id = entity.GtsID.ID
+	}
+
+	var tags map[string]string
+	if err := s.readJSON(r, &tags); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON: expected an object of string tag values")
+		return
+	}
+
+	if err := s.store.SetTags(id, tags); err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"ok":   true,
+		"id":   id,
+		"tags": s.store.GetTags(id),
+	})
+}
+
+func (s *Server) handleDeleteEntity(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if gts.IsShortID(id) {
+		entity, err := s.store.FindByShortID(id)
+		if err != nil {
+			s.writeStoreError(w, r, err)
+			return
+		}
+		id = entity.GtsID.ID
+	}
+
+	unregister := s.store.Unregister
+	if s.getQueryParam(r, "force") == "true" {
+		unregister = s.store.ForceUnregister
+	}
+	if err := unregister(id); err != nil {
+		s.writeStoreError(w, r, err)
 		return
 	}
 
 	s.writeJSON(w, http.StatusOK, map[string]any{
-		"id":      entity.GtsID.ID,
-		"content": entity.Content,
+		"ok": true,
+		"id": id,
 	})
 }
 
 func (s *Server) handleAddEntity(w http.ResponseWriter, r *http.Request) {
+	// A dry run leaves the store untouched, so it is answered by a frozen store too
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	if !dryRun && s.rejectIfFrozen(w, r) {
+		return
+	}
+
 	var content map[string]any
 	if err := s.readJSON(r, &content); err != nil {
 		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 
-	validationParam := r.URL.Query().Get("validate")
-	if validationParam == "" {
-		validationParam = r.URL.Query().Get("validation")
-	}
-
 	hasSchemaField := false
 	if schemaVal, ok := content["$schema"]; ok && schemaVal != nil {
 		hasSchemaField = true
@@ -75,70 +278,50 @@ func (s *Server) handleAddEntity(w http.ResponseWriter, r *http.Request) {
 	if hasSchemaField {
 		idField, exists := content["$id"]
 		if !exists || idField == nil {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "JSON Schema $id field is required when $schema is present",
-			})
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id field is required when $schema is present", nil)
 			return
 		}
 		idStr, ok := idField.(string)
 		if !ok {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "JSON Schema $id field must be a string",
-			})
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id field must be a string", nil)
 			return
 		}
 		idStr = strings.TrimSpace(idStr)
 		if idStr == "" {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "JSON Schema $id field cannot be empty",
-			})
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id field cannot be empty", nil)
 			return
 		}
 		if !strings.HasPrefix(idStr, gts.GtsURIPrefix) && !strings.HasPrefix(idStr, gts.GtsPrefix) {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "JSON Schema $id must be a valid GTS identifier (optionally using gts:// prefix)",
-			})
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id must be a valid GTS identifier (optionally using gts:// prefix)", nil)
 			return
 		}
 		normalizedID := strings.TrimPrefix(idStr, gts.GtsURIPrefix)
 		if strings.Contains(normalizedID, "*") {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "Wildcards are not allowed in schema IDs, only in patterns for access control",
-			})
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "Wildcards are not allowed in schema IDs, only in patterns for access control", nil)
 			return
 		}
 		isBaseSchemaID := strings.Count(normalizedID, "~") == 1 && strings.HasSuffix(normalizedID, "~")
 		if isBaseSchemaID && !strings.HasPrefix(idStr, gts.GtsURIPrefix) {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "JSON Schema $id field must use gts:// URI prefix for base schemas",
-			})
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id field must use gts:// URI prefix for base schemas", nil)
 			return
 		}
-		if !gts.IsValidGtsID(normalizedID) {
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": "JSON Schema $id must be a well-formed GTS identifier",
-			})
+		if _, err := gts.NewGtsID(normalizedID); err != nil {
+			if limitErr := idLimitError(err); limitErr != nil {
+				s.writeStoreError(w, r, limitErr)
+				return
+			}
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id must be a well-formed GTS identifier", nil)
 			return
 		}
 	}
 
-	entity := gts.NewJsonEntity(content, gts.DefaultGtsConfig())
+	entity := gts.NewJsonEntity(content, s.store.GtsConfig())
 	if entity.GtsID == nil {
-		status := http.StatusOK
-		if validationParam == "true" {
-			status = http.StatusUnprocessableEntity
+		if limitErr := idLimitError(entity.IDError); limitErr != nil {
+			s.writeStoreError(w, r, limitErr)
+			return
 		}
-		s.writeJSON(w, status, map[string]any{
-			"ok":    false,
-			"error": "Unable to extract GTS ID from entity",
-		})
+		s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, noGtsIDMessage, nil)
 		return
 	}
 
@@ -154,245 +337,847 @@ func (s *Server) handleAddEntity(w http.ResponseWriter, r *http.Request) {
 					tildeParts := strings.Split(idStr, "~")
 					// If it's a base schema (only 2 parts: prefix and empty after ~), require gts://
 					if len(tildeParts) == 2 && tildeParts[1] == "" {
-						s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-							"ok":    false,
-							"error": "JSON Schema $id field must use gts:// URI prefix for GTS identifiers, not plain gts. prefix",
-						})
+						s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "JSON Schema $id field must use gts:// URI prefix for GTS identifiers, not plain gts. prefix", nil)
 						return
 					}
 				}
 				// Check for wildcards in any GTS schema IDs
 				if (strings.HasPrefix(idStr, "gts://") || strings.HasPrefix(idStr, "gts.")) && strings.Contains(idStr, "*") {
-					s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-						"ok":    false,
-						"error": "Wildcards are not allowed in schema IDs, only in patterns for access control",
-					})
+					s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, "Wildcards are not allowed in schema IDs, only in patterns for access control", nil)
 					return
 				}
 			}
 		}
 
-		// Validate $ref constraints in the schema
-		refValidator := gts.NewRefValidator()
-		refErrors := refValidator.ValidateSchemaRefs(entity.Content, "")
-		if len(refErrors) > 0 {
-			var errorMsgs []string
-			for _, err := range refErrors {
-				errorMsgs = append(errorMsgs, err.Error())
-			}
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": fmt.Sprintf("$ref validation failed: %s", strings.Join(errorMsgs, "; ")),
-			})
+		if !dryRun && !s.checkSchemaRefs(w, entity) {
 			return
 		}
+	}
 
-		// Create a validator to validate x-gts-ref patterns in schema definition
-		xGtsRefValidator := gts.NewXGtsRefValidator(s.store)
-		xGtsRefErrors := xGtsRefValidator.ValidateSchema(entity.Content, "", nil)
-		if len(xGtsRefErrors) > 0 {
-			var errorMsgs []string
-			for _, err := range xGtsRefErrors {
-				errorMsgs = append(errorMsgs, err.Error())
-			}
-			s.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-				"ok":    false,
-				"error": fmt.Sprintf("x-gts-ref validation failed: %s", strings.Join(errorMsgs, "; ")),
-			})
-			return
-		}
+	if dryRun {
+		// The plan lists the failures of every check instead of answering the first one
+		s.writeJSON(w, http.StatusOK, s.store.PlanRegister(entity))
+		return
 	}
 
 	// Check if instance validation is requested via query parameter
 	validation := r.URL.Query().Get("validation")
 	if validation == "true" && !entity.IsSchema {
 		// For non-schema entities with validation=true, register first then validate
-		err := s.store.Register(entity)
-		if err != nil {
-			s.writeJSON(w, http.StatusOK, map[string]any{
-				"ok":    false,
-				"error": err.Error(),
-			})
+		if err := s.store.Register(entity); err != nil {
+			s.writeStoreError(w, r, err)
 			return
 		}
 
 		// Validate the instance
+		start := time.Now()
 		result := s.store.ValidateInstance(entity.GtsID.ID)
+		s.metrics.observeSince(operationValidation, start)
 		if !result.OK {
-			s.writeJSON(w, http.StatusOK, map[string]any{
-				"ok":    false,
-				"error": result.Error,
-			})
+			s.writeValidationError(w, r, result)
 			return
 		}
 
-		s.writeJSON(w, http.StatusOK, map[string]any{
+		s.writeJSON(w, http.StatusOK, withReferenceWarnings(map[string]any{
 			"ok":     true,
 			"gts_id": entity.GtsID.ID,
-		})
+		}, entity))
 		return
 	}
 
-	err := s.store.Register(entity)
-	if err != nil {
-		s.writeJSON(w, http.StatusOK, map[string]any{
-			"ok":    false,
-			"error": err.Error(),
-		})
+	if err := s.store.Register(entity); err != nil {
+		s.writeStoreError(w, r, err)
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, map[string]any{
+	s.writeJSON(w, http.StatusOK, withDependentsReport(withReferenceWarnings(map[string]any{
 		"ok":     true,
 		"gts_id": entity.GtsID.ID,
-	})
+	}, entity), entity))
+}
+
+// checkSchemaRefs validates the $ref and x-gts-ref constraints of a schema definition, writing
+// the error response and returning false when they fail
+func (s *Server) checkSchemaRefs(w http.ResponseWriter, entity *gts.JsonEntity) bool {
+	// Validate $ref constraints in the schema
+	refValidator := gts.NewRefValidator()
+	refErrors := refValidator.ValidateSchemaRefs(entity.Content, "")
+	if len(refErrors) > 0 {
+		var errorMsgs []string
+		for _, err := range refErrors {
+			errorMsgs = append(errorMsgs, err.Error())
+		}
+		s.writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("$ref validation failed: %s", strings.Join(errorMsgs, "; ")))
+		return false
+	}
+
+	// Create a validator to validate x-gts-ref patterns in schema definition
+	xGtsRefValidator := gts.NewXGtsRefValidator(s.store)
+	xGtsRefErrors := xGtsRefValidator.ValidateSchema(entity.Content, "", nil)
+	if len(xGtsRefErrors) > 0 {
+		var errorMsgs []string
+		for _, err := range xGtsRefErrors {
+			errorMsgs = append(errorMsgs, err.Error())
+		}
+		s.writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("x-gts-ref validation failed: %s", strings.Join(errorMsgs, "; ")))
+		return false
+	}
+	return true
+}
+
+// handleReplaceEntity replaces the content of a registered entity with the request body. The ID
+// of the body must be the one of the path. Schemas are checked as when they are added and the
+// compatibility of the new content with the old one is returned; with validation=true, instances
+// are validated against their schema first. The old content is kept when a check fails.
+func (s *Server) handleReplaceEntity(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfFrozen(w, r) {
+		return
+	}
+
+	// The path ID is matched exactly: a replacement never applies to a normalized ID
+	id := r.PathValue("id")
+	if gts.IsShortID(id) {
+		existing, err := s.store.FindByShortID(id)
+		if err != nil {
+			s.writeStoreError(w, r, err)
+			return
+		}
+		id = existing.GtsID.ID
+	}
+
+	var content map[string]any
+	if err := s.readJSON(r, &content); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	entity := gts.NewJsonEntity(content, s.store.GtsConfig())
+	if entity.GtsID == nil {
+		if limitErr := idLimitError(entity.IDError); limitErr != nil {
+			s.writeStoreError(w, r, limitErr)
+			return
+		}
+		s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, noGtsIDMessage, nil)
+		return
+	}
+
+	if entity.IsSchema {
+		if !s.checkSchemaRefs(w, entity) {
+			return
+		}
+	} else if s.getQueryParam(r, "validation") == "true" {
+		start := time.Now()
+		result := s.store.ValidateEntity(entity)
+		s.metrics.observeSince(operationValidation, start)
+		if !result.OK {
+			s.writeValidationError(w, r, result)
+			return
+		}
+	}
+
+	// The ID comparison, compatibility check and write are done by the store against the same entity
+	compatibility, err := s.store.Replace(id, entity)
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	resp := map[string]any{
+		"ok":     true,
+		"gts_id": id,
+	}
+	if compatibility != nil {
+		resp["compatibility"] = compatibility
+	}
+	s.writeJSON(w, http.StatusOK, withDependentsReport(withReferenceWarnings(resp, entity), entity))
+}
+
+// withReferenceWarnings adds a warnings array to a registration response when the entity
+// was registered with unresolved references (warn-mode reference validation)
+func withReferenceWarnings(resp map[string]any, entity *gts.JsonEntity) map[string]any {
+	if len(entity.UnresolvedRefs) == 0 {
+		return resp
+	}
+	warnings := make([]string, 0, len(entity.UnresolvedRefs))
+	for _, ref := range entity.UnresolvedRefs {
+		warnings = append(warnings, fmt.Sprintf("unresolved reference: %s", ref))
+	}
+	resp["warnings"] = warnings
+	return resp
+}
+
+// withDependentsReport adds the re-validation report of the instances of an overwritten schema
+// to a registration response
+func withDependentsReport(resp map[string]any, entity *gts.JsonEntity) map[string]any {
+	if entity.DependentReport != nil {
+		resp["dependents"] = entity.DependentReport
+	}
+	return resp
 }
 
 func (s *Server) handleAddEntities(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfFrozen(w, r) {
+		return
+	}
+
 	var contents []map[string]any
 	if err := s.readJSON(r, &contents); err != nil {
 		s.writeError(w, http.StatusBadRequest, "Invalid JSON array")
 		return
 	}
+	// With ordered=false the batch is registered in dependency order instead of array order
+	dependencyOrder := r.URL.Query().Get("ordered") == "false"
+	if r.URL.Query().Get("atomic") == "true" {
+		s.addEntitiesAtomically(w, r, contents, dependencyOrder)
+		return
+	}
+
+	entities := make([]*gts.JsonEntity, len(contents))
+	order := make([]int, len(contents))
+	for i, content := range contents {
+		entities[i] = gts.NewJsonEntity(content, s.store.GtsConfig())
+		order[i] = i
+	}
+	if dependencyOrder {
+		order = gts.DependencyOrder(entities)
+	}
 
 	result := make([]map[string]any, len(contents))
 	successCount := 0
 
-	for i, content := range contents {
-		entity := gts.NewJsonEntity(content, gts.DefaultGtsConfig())
+	for _, i := range order {
+		entity := entities[i]
 		if entity.GtsID == nil {
-			result[i] = map[string]any{
-				"ok":    false,
-				"error": "Unable to extract GTS ID from entity",
-			}
+			result[i] = noGtsIDItem(entity)
 			continue
 		}
 
-		err := s.store.Register(entity)
-		if err != nil {
-			result[i] = map[string]any{
-				"ok":    false,
-				"error": err.Error(),
-			}
+		if err := s.store.Register(entity); err != nil {
+			result[i] = failedItem(err)
+			result[i]["gts_id"] = entity.GtsID.ID
 			continue
 		}
 
-		result[i] = map[string]any{
+		result[i] = withReferenceWarnings(map[string]any{
 			"ok":     true,
 			"gts_id": entity.GtsID.ID,
-		}
+		}, entity)
 		successCount++
 	}
 
-	s.writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"ok":      successCount == len(contents),
 		"count":   successCount,
 		"total":   len(contents),
 		"results": result,
-	})
-}
-
-func (s *Server) handleAddSchema(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		TypeID string         `json:"type_id"`
-		Schema map[string]any `json:"schema"`
-	}
-	if err := s.readJSON(r, &req); err != nil {
-		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
-		return
 	}
-
-	err := s.store.RegisterSchema(req.TypeID, req.Schema)
-	if err != nil {
-		s.writeJSON(w, http.StatusOK, map[string]any{
-			"ok":      false,
-			"type_id": req.TypeID,
-			"error":   err.Error(),
-		})
-		return
+	if dependencyOrder {
+		resp["order"] = order
 	}
-
-	s.writeJSON(w, http.StatusOK, map[string]any{
-		"ok":      true,
-		"type_id": req.TypeID,
-	})
+	s.writeJSON(w, http.StatusOK, resp)
 }
 
-// Operation Handlers
+// addEntitiesAtomically registers a bulk request with GtsStore.RegisterAllWithOptions, committing
+// all entities or none of them
+func (s *Server) addEntitiesAtomically(w http.ResponseWriter, r *http.Request, contents []map[string]any, dependencyOrder bool) {
+	entities := make([]*gts.JsonEntity, len(contents))
+	for i, content := range contents {
+		entities[i] = gts.NewJsonEntity(content, s.store.GtsConfig())
+	}
 
-// OP#1 - Validate ID
-func (s *Server) handleValidateID(w http.ResponseWriter, r *http.Request) {
-	gtsID := s.getQueryParam(r, "gts_id")
-	if gtsID == "" {
-		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
+	batch, err := s.store.RegisterAllWithOptions(entities, gts.BatchOptions{Atomic: true, DependencyOrder: dependencyOrder})
+	var rejected *gts.BatchRejectedError
+	if err != nil && !errors.As(err, &rejected) {
+		s.writeStoreError(w, r, err)
 		return
 	}
 
-	result := gts.ValidateGtsID(gtsID)
-	s.writeJSON(w, http.StatusOK, result)
-}
+	result := make([]map[string]any, len(entities))
+	for i, entry := range batch.Results {
+		switch {
+		case entry.OK:
+			result[i] = withDependentsReport(withReferenceWarnings(map[string]any{
+				"ok":     true,
+				"gts_id": entry.ID,
+			}, entities[i]), entities[i])
+		case entry.Err == nil:
+			// Valid itself, but not committed because other entities failed
+			result[i] = map[string]any{"ok": false, "gts_id": entry.ID}
+		case entities[i].GtsID == nil:
+			result[i] = noGtsIDItem(entities[i])
+		default:
+			result[i] = failedItem(entry.Err)
+			result[i]["gts_id"] = entry.ID
+		}
+	}
 
-// OP#2 - Extract ID
-func (s *Server) handleExtractID(w http.ResponseWriter, r *http.Request) {
-	var content map[string]any
-	if err := s.readJSON(r, &content); err != nil {
-		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+	summary := map[string]any{
+		"atomic":    true,
+		"committed": batch.Committed,
+		"count":     batch.Registered,
+		"failed":    batch.Failed,
+		"total":     len(entities),
+		"results":   result,
+	}
+	if batch.Order != nil {
+		summary["order"] = batch.Order
+	}
+	if rejected != nil {
+		// Nothing was registered: the per-entity results explain the rejection
+		status, apiErr := translateError(rejected)
+		apiErr.Details = summary
+		s.writeAPIError(w, status, apiErr)
 		return
 	}
-
-	result := gts.ExtractGtsID(content, gts.DefaultGtsConfig())
-	s.writeJSON(w, http.StatusOK, result)
+	summary["ok"] = true
+	s.writeJSON(w, http.StatusOK, summary)
 }
 
-// OP#3 - Parse ID
-func (s *Server) handleParseID(w http.ResponseWriter, r *http.Request) {
-	gtsID := s.getQueryParam(r, "gts_id")
-	if gtsID == "" {
-		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
-		return
+// noGtsIDItem describes an entity of a bulk request without a GTS ID
+func noGtsIDItem(entity *gts.JsonEntity) map[string]any {
+	if limitErr := idLimitError(entity.IDError); limitErr != nil {
+		return failedItem(limitErr)
 	}
+	return failedItemWithCode(ErrorCodeInvalidID, noGtsIDMessage, nil)
+}
 
-	result := gts.ParseGtsID(gtsID)
-	s.writeJSON(w, http.StatusOK, result)
+// uploadedFile is a file part of a POST /entities:upload request
+type uploadedFile struct {
+	name string
+	data []byte
 }
 
-// OP#4 - Match ID Pattern
-func (s *Server) handleMatchIDPattern(w http.ResponseWriter, r *http.Request) {
-	candidate := s.getQueryParam(r, "candidate")
-	pattern := s.getQueryParam(r, "pattern")
+// writeUploadTooLarge writes a 413 response for an upload exceeding a size limit
+func (s *Server) writeUploadTooLarge(w http.ResponseWriter, msg string) {
+	s.writeError(w, http.StatusRequestEntityTooLarge, msg)
+}
 
-	if candidate == "" || pattern == "" {
-		s.writeError(w, http.StatusBadRequest, "Missing candidate or pattern parameter")
-		return
+// readUploadedFiles reads the file parts of a multipart request, enforcing the per-file limit.
+// The whole request is limited by the caller. It returns false after writing an error response.
+func (s *Server) readUploadedFiles(w http.ResponseWriter, r *http.Request) ([]uploadedFile, bool) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Expected a multipart/form-data body")
+		return nil, false
 	}
 
-	result := gts.MatchIDPattern(candidate, pattern)
-	s.writeJSON(w, http.StatusOK, result)
-}
+	var files []uploadedFile
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return files, true
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			s.writeUploadTooLarge(w, fmt.Sprintf("Upload exceeds the limit of %d bytes", s.maxUploadSize))
+			return nil, false
+		}
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid multipart body: %v", err))
+			return nil, false
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
 
-// OP#5 - UUID
-func (s *Server) handleUUID(w http.ResponseWriter, r *http.Request) {
-	gtsID := s.getQueryParam(r, "gts_id")
-	if gtsID == "" {
-		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
-		return
+		data, err := io.ReadAll(io.LimitReader(part, s.maxUploadFileSize+1))
+		part.Close()
+		if errors.As(err, &maxBytesErr) {
+			s.writeUploadTooLarge(w, fmt.Sprintf("Upload exceeds the limit of %d bytes", s.maxUploadSize))
+			return nil, false
+		}
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Failed to read %s: %v", part.FileName(), err))
+			return nil, false
+		}
+		if int64(len(data)) > s.maxUploadFileSize {
+			s.writeUploadTooLarge(w, fmt.Sprintf("File '%s' exceeds the limit of %d bytes", part.FileName(), s.maxUploadFileSize))
+			return nil, false
+		}
+		files = append(files, uploadedFile{name: part.FileName(), data: data})
 	}
-
-	result := gts.IDToUUID(gtsID)
-	s.writeJSON(w, http.StatusOK, result)
 }
 
-// OP#6 - Validate Instance
-func (s *Server) handleValidateInstance(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		InstanceID string `json:"instance_id"`
+// handleUploadEntities registers the entities of uploaded JSON documents and zip archives.
+// All files are read before anything is registered, so a request rejected for its size registers
+// nothing. Documents are then registered one by one; a malformed document is reported in its
+// result without stopping the others.
+func (s *Server) handleUploadEntities(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfFrozen(w, r) {
+		return
 	}
-	if err := s.readJSON(r, &req); err != nil {
-		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxUploadSize)
+	files, ok := s.readUploadedFiles(w, r)
+	if !ok {
 		return
 	}
 
+	var documents []gts.EntityDocument
+	for _, file := range files {
+		if strings.EqualFold(path.Ext(file.name), ".zip") {
+			// Archives may inflate to the request size limit in total, which bounds nested zip bombs
+			limits := gts.ArchiveLimits{MaxMemberSize: s.maxUploadFileSize, MaxTotalSize: s.maxUploadSize, MaxDepth: gts.DefaultMaxArchiveDepth}
+			members, err := gts.ReadEntityArchiveWithLimits(file.name, file.data, limits, s.store.GtsConfig())
+			if gts.IsArchiveLimitError(err) {
+				s.writeStoreError(w, r, err)
+				return
+			}
+			if err != nil {
+				documents = append(documents, gts.EntityDocument{Path: file.name, Err: err})
+				continue
+			}
+			documents = append(documents, members...)
+			continue
+		}
+		entities, skipped, err := gts.ParseEntityDocument(file.name, file.data, s.store.GtsConfig())
+		documents = append(documents, gts.EntityDocument{Path: file.name, Entities: entities, Skipped: skipped, Err: err})
+	}
+
+	results := make([]map[string]any, len(documents))
+	registeredIn := make(map[string]string)
+	successCount, total, failedDocuments := 0, 0, 0
+
+	for i, doc := range documents {
+		if doc.Err != nil {
+			results[i] = failedItemWithCode(ErrorCodeBadRequest, doc.Err.Error(), map[string]any{"member": doc.Path})
+			failedDocuments++
+			continue
+		}
+
+		entityResults := make([]map[string]any, len(doc.Entities))
+		docOK := true
+		for j, entity := range doc.Entities {
+			total++
+			entityResults[j] = s.registerUploadedEntity(entity, doc.Path, registeredIn)
+			if entityResults[j]["ok"] == true {
+				successCount++
+			} else {
+				docOK = false
+			}
+		}
+		if !docOK {
+			failedDocuments++
+		}
+		results[i] = map[string]any{
+			"member":   doc.Path,
+			"ok":       docOK,
+			"skipped":  doc.Skipped,
+			"entities": entityResults,
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"ok":        failedDocuments == 0,
+		"files":     len(files),
+		"documents": len(documents),
+		"failed":    failedDocuments,
+		"count":     successCount,
+		"total":     total,
+		"results":   results,
+	})
+}
+
+// registerUploadedEntity registers an entity of an uploaded document. registeredIn maps the IDs
+// already registered by the upload to their document, so that an ID repeated within the upload is
+// reported instead of silently overwriting the earlier entity.
+func (s *Server) registerUploadedEntity(entity *gts.JsonEntity, member string, registeredIn map[string]string) map[string]any {
+	id := entity.GtsID.ID
+	if first, ok := registeredIn[id]; ok {
+		return failedItemWithCode(ErrorCodeConflict, fmt.Sprintf("Duplicate GTS ID in upload, already registered from %s", first),
+			map[string]any{"gts_id": id, "label": entity.Label})
+	}
+
+	if err := s.store.Register(entity); err != nil {
+		resp := failedItem(err)
+		resp["gts_id"] = id
+		resp["label"] = entity.Label
+		return resp
+	}
+
+	registeredIn[id] = member
+	return withDependentsReport(withReferenceWarnings(map[string]any{
+		"ok":     true,
+		"gts_id": id,
+		"label":  entity.Label,
+	}, entity), entity)
+}
+
+func (s *Server) handleAddSchema(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfFrozen(w, r) {
+		return
+	}
+
+	var req struct {
+		TypeID string         `json:"type_id"`
+		Schema map[string]any `json:"schema"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := s.store.RegisterSchema(req.TypeID, req.Schema); err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"ok":      true,
+		"type_id": req.TypeID,
+	})
+}
+
+// schemaMediaType is the Content-Type of schema documents
+const schemaMediaType = "application/schema+json"
+
+// handleGetSchemaDocument serves the registered content of a schema as a plain JSON Schema
+// document, so that external tooling can fetch it by URL. The {id} is a GTS ID (with '~' sent
+// as is or as %7E) or a short ID. The ETag is the SHA-256 of the served bytes and a matching
+// If-None-Match is answered with 304. The GET route also answers HEAD requests.
+//
+// With rewrite_id=true the $id and the gts:// $refs of the document are rewritten to the URLs
+// this server serves them at, so that tooling following the references stays on this server.
+func (s *Server) handleGetSchemaDocument(w http.ResponseWriter, r *http.Request) {
+	entity, err := s.lookupEntity(w, r.PathValue("id"))
+	if err == nil && !entity.IsSchema {
+		err = &gts.StoreGtsSchemaNotFoundError{EntityID: entity.GtsID.ID}
+	}
+	if err != nil {
+		var notFound *gts.StoreGtsObjectNotFoundError
+		if errors.As(err, &notFound) {
+			err = &gts.StoreGtsSchemaNotFoundError{EntityID: notFound.EntityID}
+		}
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	var document any = entity.Content
+	if s.getQueryParam(r, "rewrite_id") == "true" {
+		base := schemaDocumentBaseURL(r)
+		rewritten := rewriteSchemaRefs(entity.Content, base).(map[string]any)
+		rewritten["$id"] = base + url.PathEscape(entity.GtsID.ID)
+		document = rewritten
+	}
+	body, err := gts.CanonicalJSON(document)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to encode schema: %v", err))
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", schemaMediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// schemaDocumentBaseURL returns the absolute URL schema documents are served under, ending with '/'
+func schemaDocumentBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/schemas/"
+}
+
+// rewriteSchemaRefs returns a copy of a schema node with its gts:// $refs pointing at the schema
+// documents under base; JSON pointer fragments are kept
+func rewriteSchemaRefs(node any, base string) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" && strings.HasPrefix(ref, gts.GtsURIPrefix) {
+				id, fragment, found := strings.Cut(strings.TrimPrefix(ref, gts.GtsURIPrefix), "#")
+				ref = base + url.PathEscape(id)
+				if found {
+					ref += "#" + fragment
+				}
+				out[key] = ref
+				continue
+			}
+			out[key] = rewriteSchemaRefs(value, base)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = rewriteSchemaRefs(item, base)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing weakly as RFC 9110 requires
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePrune removes the entities selected by the prune policy in the request body
+func (s *Server) handlePrune(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		KeepMinors    int      `json:"keep_minors"`
+		RemoveUnused  bool     `json:"remove_unused"`
+		OlderThan     string   `json:"older_than"`
+		RemoveOrphans bool     `json:"remove_orphans"`
+		Protect       []string `json:"protect"`
+		DryRun        bool     `json:"dry_run"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	policy := gts.PrunePolicy{
+		KeepMinors:    req.KeepMinors,
+		RemoveUnused:  req.RemoveUnused,
+		RemoveOrphans: req.RemoveOrphans,
+		Protect:       req.Protect,
+	}
+	if req.OlderThan != "" {
+		d, err := time.ParseDuration(req.OlderThan)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid older_than: expected a duration such as 720h")
+			return
+		}
+		policy.InstancesOlderThan = d
+	}
+
+	report, err := s.store.Prune(policy, req.DryRun)
+	if err != nil {
+		var frozen *gts.StoreFrozenError
+		if errors.As(err, &frozen) {
+			s.writeStoreError(w, r, err)
+			return
+		}
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// handleExport writes every entity of the store as a bundle
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := s.store.Export(&buf); err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="gts-bundle.json"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// handleImport registers the entities of a bundle written by GET /export
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfFrozen(w, r) {
+		return
+	}
+	policy, err := gts.ParseConflictPolicy(s.getQueryParam(r, "on_conflict"))
+	if err != nil || policy == gts.ConflictKeepNewer {
+		s.writeError(w, http.StatusBadRequest, "Invalid on_conflict: expected keep-existing, overwrite or fail")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxUploadSize)
+	result, err := s.store.Import(r.Body, gts.ImportOptions{Conflict: policy})
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		s.writeUploadTooLarge(w, fmt.Sprintf("Bundle exceeds the limit of %d bytes", s.maxUploadSize))
+		return
+	}
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleGetState reports the runtime state of the store
+func (s *Server) handleGetState(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"frozen":          s.store.IsFrozen(),
+		"entity_count":    s.store.Count(),
+		"unresolved_refs": s.store.UnresolvedRefCount(),
+		"lookups":         s.store.LookupStats(),
+	})
+}
+
+// handleGetStats summarizes the registered entities
+func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.store.Stats())
+}
+
+// handleGetIntegrity lists the integrity problems of the registered entities; problems are the
+// answer, not a failure of the request
+func (s *Server) handleGetIntegrity(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.store.IntegrityCheck())
+}
+
+// handleGetTypes summarizes the registered type lines, optionally filtered by a pattern
+func (s *Server) handleGetTypes(w http.ResponseWriter, r *http.Request) {
+	result := s.store.TypeSummaries(s.getQueryParam(r, "pattern"))
+	if result.Error != "" {
+		s.writeError(w, http.StatusBadRequest, result.Error)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// rejectIfFrozen answers a mutation request with 409 Conflict when the store is frozen
+func (s *Server) rejectIfFrozen(w http.ResponseWriter, r *http.Request) bool {
+	if !s.store.IsFrozen() {
+		return false
+	}
+	s.writeStoreError(w, r, &gts.StoreFrozenError{Operation: "registration"})
+	return true
+}
+
+// idLimitError returns the IDLimitError wrapped by err, if any
+func idLimitError(err error) *gts.IDLimitError {
+	var limitErr *gts.IDLimitError
+	if errors.As(err, &limitErr) {
+		return limitErr
+	}
+	return nil
+}
+
+// Operation Handlers
+
+// OP#1 - Validate ID
+func (s *Server) handleValidateID(w http.ResponseWriter, r *http.Request) {
+	gtsID := s.getQueryParam(r, "gts_id")
+	if gtsID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
+		return
+	}
+
+	result := gts.ValidateGtsID(gtsID)
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// OP#2 - Extract ID
+func (s *Server) handleExtractID(w http.ResponseWriter, r *http.Request) {
+	var content map[string]any
+	if err := s.readJSON(r, &content); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	result := gts.ExtractGtsID(content, s.store.GtsConfig())
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// OP#3 - Parse ID
+func (s *Server) handleParseID(w http.ResponseWriter, r *http.Request) {
+	gtsID := s.getQueryParam(r, "gts_id")
+	if gtsID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
+		return
+	}
+
+	result := gts.ParseGtsID(gtsID)
+	if !result.OK {
+		s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, result.Error, map[string]any{"gts_id": gtsID})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// OP#4 - Match ID Pattern
+func (s *Server) handleMatchIDPattern(w http.ResponseWriter, r *http.Request) {
+	candidate := s.getQueryParam(r, "candidate")
+	pattern := s.getQueryParam(r, "pattern")
+
+	if candidate == "" || pattern == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing candidate or pattern parameter")
+		return
+	}
+
+	result := gts.MatchIDPattern(candidate, pattern)
+	if result.Error != "" {
+		s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, result.Error, nil)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// OP#5 - UUID
+func (s *Server) handleUUID(w http.ResponseWriter, r *http.Request) {
+	gtsID := s.getQueryParam(r, "gts_id")
+	if gtsID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
+		return
+	}
+
+	if s.getQueryParam(r, "tree") == "true" {
+		result := gts.NewUUIDTreeResult(gtsID)
+		if result.Error != "" {
+			s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, result.Error, map[string]any{"gts_id": gtsID})
+			return
+		}
+		s.writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	result := gts.IDToUUID(gtsID)
+	if result.Error != "" {
+		s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, result.Error, map[string]any{"gts_id": gtsID})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleVerifyUUIDs reports UUID collisions and non-canonical IDs among the registered entities;
+// the report is the verdict, so anomalies are not an error
+func (s *Server) handleVerifyUUIDs(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.store.VerifyUUIDIntegrity())
+}
+
+// OP#6 - Validate Instance
+func (s *Server) handleValidateInstance(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InstanceID string `json:"instance_id"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	start := time.Now()
 	result := s.store.ValidateInstance(req.InstanceID)
+	s.metrics.observeSince(operationValidation, start)
+	if !result.OK {
+		s.writeValidationError(w, r, result)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleValidateAll validates every registered entity; invalid entities are reported in the
+// result, so the response is 200 unless the request itself fails
+func (s *Server) handleValidateAll(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	result := s.store.ValidateAll()
+	s.metrics.observeSince(operationValidation, start)
 	s.writeJSON(w, http.StatusOK, result)
 }
 
@@ -404,7 +1189,70 @@ func (s *Server) handleResolveRelationships(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	result := s.store.BuildSchemaGraph(gtsID)
+	query := r.URL.Query()
+	if !query.Has("depth") && !query.Has("max_nodes") && !query.Has("format") {
+		result := s.store.BuildSchemaGraph(gtsID)
+		s.writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	// Any traversal option switches to the limited graph result with truncation details
+	opts := gts.SchemaGraphOptions{Format: query.Get("format")}
+	limits := []struct {
+		param  string
+		target *int
+	}{{"depth", &opts.MaxDepth}, {"max_nodes", &opts.MaxNodes}}
+	for _, limit := range limits {
+		if !query.Has(limit.param) {
+			continue
+		}
+		value, err := strconv.Atoi(query.Get(limit.param))
+		if err != nil || value < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter: must be a non-negative integer", limit.param))
+			return
+		}
+		*limit.target = value
+	}
+
+	result, err := s.store.BuildSchemaGraphWithOptions(gtsID, opts)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleLineage reports which layers of a schema's allOf hierarchy define each property
+func (s *Server) handleLineage(w http.ResponseWriter, r *http.Request) {
+	gtsID := s.getQueryParam(r, "gts_id")
+	if gtsID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
+		return
+	}
+
+	lineage, err := s.store.PropertyLineage(gtsID)
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, lineage)
+}
+
+// handleReferrers lists the registered entities referencing an ID
+func (s *Server) handleReferrers(w http.ResponseWriter, r *http.Request) {
+	gtsID := s.getQueryParam(r, "gts_id")
+	if gtsID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing gts_id parameter")
+		return
+	}
+
+	result := s.store.FindReferrersWithOptions(gtsID, gts.ReferrersOptions{
+		IncludeMinorVersions: s.getQueryParam(r, "include_minor_versions") == "true",
+	})
+	if result.Error != "" {
+		s.writeErrorCode(w, http.StatusUnprocessableEntity, ErrorCodeInvalidID, result.Error, map[string]any{"gts_id": gtsID})
+		return
+	}
 	s.writeJSON(w, http.StatusOK, result)
 }
 
@@ -419,31 +1267,137 @@ func (s *Server) handleCompatibility(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result := s.store.CheckCompatibility(oldSchemaID, newSchemaID)
+	if result.Err != nil {
+		s.writeStoreError(w, r, result.Err)
+		return
+	}
+	if s.getQueryParam(r, "include_diff") == "true" {
+		diff, err := s.store.DiffSchemas(oldSchemaID, newSchemaID)
+		if err != nil {
+			s.writeStoreError(w, r, err)
+			return
+		}
+		result.Diff = diff
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleCompatibilitySeries checks the compatibility of the registered minor versions of a type
+func (s *Server) handleCompatibilitySeries(w http.ResponseWriter, r *http.Request) {
+	typeID := s.getQueryParam(r, "type_id")
+	if typeID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing type_id parameter")
+		return
+	}
+
+	result := s.store.CheckCompatibilitySeries(typeID)
+	if result.Err != nil {
+		s.writeStoreError(w, r, result.Err)
+		return
+	}
 	s.writeJSON(w, http.StatusOK, result)
 }
 
 // OP#9 - Cast
 func (s *Server) handleCast(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		InstanceID string `json:"instance_id"`
-		ToSchemaID string `json:"to_schema_id"`
+		InstanceID string         `json:"instance_id"`
+		Instance   map[string]any `json:"instance"`
+		ToSchemaID string         `json:"to_schema_id"`
 	}
 	if err := s.readJSON(r, &req); err != nil {
 		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
+	if (req.InstanceID == "") == (req.Instance == nil) {
+		s.writeError(w, http.StatusBadRequest, "Exactly one of instance_id and instance is required")
+		return
+	}
 
-	result, err := s.store.Cast(req.InstanceID, req.ToSchemaID)
+	var result *gts.CastResult
+	var err error
+	start := time.Now()
+	if req.Instance != nil {
+		// Ad-hoc content is cast without being registered
+		result, err = s.store.CastContent(req.Instance, req.ToSchemaID)
+	} else {
+		result, err = s.store.Cast(req.InstanceID, req.ToSchemaID)
+	}
+	s.metrics.observeSince(operationCast, start)
 	if err != nil {
-		s.writeJSON(w, http.StatusOK, map[string]any{
-			"error": err.Error(),
-		})
+		s.writeStoreError(w, r, err)
 		return
 	}
 
 	s.writeJSON(w, http.StatusOK, result)
 }
 
+// handleApplyDefaults fills the defaults declared by the schema of an instance
+func (s *Server) handleApplyDefaults(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InstanceID string         `json:"instance_id"`
+		Instance   map[string]any `json:"instance"`
+		SchemaID   string         `json:"schema_id"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if (req.InstanceID == "") == (req.Instance == nil) {
+		s.writeError(w, http.StatusBadRequest, "Exactly one of instance_id and instance is required")
+		return
+	}
+	if req.InstanceID != "" && req.SchemaID != "" {
+		s.writeError(w, http.StatusBadRequest, "schema_id can only be given with instance")
+		return
+	}
+
+	var result *gts.ApplyDefaultsResult
+	var err error
+	if req.Instance != nil {
+		result, err = s.store.ApplyDefaultsToContent(req.Instance, req.SchemaID)
+	} else {
+		result, err = s.store.ApplyDefaults(req.InstanceID)
+	}
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleCastBatch casts every instance matching a pattern to a target schema
+func (s *Server) handleCastBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Pattern    string `json:"pattern"`
+		ToSchemaID string `json:"to_schema_id"`
+		Limit      int    `json:"limit"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if req.Pattern == "" || req.ToSchemaID == "" {
+		s.writeError(w, http.StatusBadRequest, "Missing pattern or to_schema_id")
+		return
+	}
+
+	start := time.Now()
+	result := s.store.CastAll(req.Pattern, req.ToSchemaID, req.Limit)
+	s.metrics.observeSince(operationCast, start)
+	if result.Err != nil {
+		var schemaErr *gts.StoreGtsSchemaNotFoundError
+		if errors.As(result.Err, &schemaErr) {
+			s.writeStoreError(w, r, result.Err)
+		} else {
+			s.writeQueryError(w, r, result.Err)
+		}
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
 // OP#10 - Query
 func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 	expr := s.getQueryParam(r, "expr")
@@ -452,6 +1406,23 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("explain") == "true" {
+		// The plan holds no entity content, so the scan is not capped; limit=0 means no limit
+		plan, err := s.store.ExplainQuery(expr, s.getQueryParamInt(r, "limit", 0))
+		if err != nil {
+			s.writeQueryError(w, r, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	if wantsNDJSON(r) {
+		// Streaming does not hold results in memory, so it is not capped; limit=0 means no limit
+		s.streamQuery(w, r, expr, s.getQueryParamInt(r, "limit", 0))
+		return
+	}
+
 	limit := s.getQueryParamInt(r, "limit", 100)
 	if limit < 1 {
 		limit = 1
@@ -460,10 +1431,81 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		limit = 1000
 	}
 
-	result := s.store.Query(expr, limit)
+	result := s.store.QueryWithOptions(expr, limit, gts.QueryOptions{
+		IDsOnly:    s.getQueryParam(r, "ids_only") == "true",
+		Projection: entitySelectors(r),
+		OrderBy:    s.getQueryParam(r, "order_by"),
+		Descending: s.getQueryParam(r, "desc") == "true",
+	})
+	if result.Err != nil {
+		s.writeQueryError(w, r, result.Err)
+		return
+	}
+	if result.NormalizedPattern != "" {
+		w.Header().Set(normalizedIDHeader, result.NormalizedPattern)
+	}
 	s.writeJSON(w, http.StatusOK, result)
 }
 
+// writeQueryError answers a query that failed: the expression is invalid unless the store failed
+func (s *Server) writeQueryError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, gts.ErrInternal) {
+		s.writeInternalError(w, r)
+		return
+	}
+	s.writeErrorCode(w, http.StatusBadRequest, ErrorCodeBadRequest, err.Error(), map[string]any{
+		"error_code": gts.ErrorCodeQueryInvalid,
+	})
+}
+
+// ndjsonFlushInterval is the number of streamed query items written between flushes
+const ndjsonFlushInterval = 100
+
+// wantsNDJSON reports whether the client asked for a streamed NDJSON response
+func wantsNDJSON(r *http.Request) bool {
+	if r.URL.Query().Get("stream") == "true" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+}
+
+// streamQuery writes query matches as NDJSON, one result object per line, as they are found
+func (s *Server) streamQuery(w http.ResponseWriter, r *http.Request, expr string, limit int) {
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	written := 0
+	err := s.store.QueryStream(expr, func(item gts.QueryItem) bool {
+		if written == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := enc.Encode(item.Content); err != nil {
+			// The client went away; stop the query
+			return false
+		}
+		written++
+		if written%ndjsonFlushInterval == 0 {
+			_ = rc.Flush()
+		}
+		return limit <= 0 || written < limit
+	})
+	if err != nil {
+		if written == 0 {
+			s.writeQueryError(w, r, err)
+		}
+		// Otherwise the status is already sent; the truncated stream is all the client gets
+		return
+	}
+
+	if written == 0 {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = rc.Flush()
+}
+
 // OP#11 - Attribute Access
 func (s *Server) handleAttribute(w http.ResponseWriter, r *http.Request) {
 	gtsWithPath := s.getQueryParam(r, "gts_with_path")
@@ -473,5 +1515,93 @@ func (s *Server) handleAttribute(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result := s.store.GetAttribute(gtsWithPath)
+	if result.Err != nil {
+		s.writeStoreError(w, r, result.Err)
+		return
+	}
+	if !result.Resolved {
+		// A missing field or element is not found, a path that does not fit the entity is malformed;
+		// the available fields help correct either
+		status, code := http.StatusBadRequest, ErrorCodeBadRequest
+		if result.ErrorCode == gts.ErrorCodeAttrNotFound || result.ErrorCode == gts.ErrorCodeAttrIndexOutOfRange {
+			status, code = http.StatusNotFound, ErrorCodeAttributeNotFound
+		}
+		s.writeErrorCode(w, status, code, result.Error, map[string]any{
+			"gts_id":           result.GtsID,
+			"path":             result.Path,
+			"error_code":       result.ErrorCode,
+			"available_fields": result.AvailableFields,
+		})
+		return
+	}
 	s.writeJSON(w, http.StatusOK, result)
 }
+
+// Instance ID allocation
+func (s *Server) handleAllocateID(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SchemaID          string `json:"schema_id"`
+		Vendor            string `json:"vendor"`
+		Package           string `json:"package"`
+		Namespace         string `json:"namespace"`
+		Type              string `json:"type"`
+		Major             *int   `json:"major"`
+		ReserveTTLSeconds int    `json:"reserve_ttl_seconds"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	var opts []gts.AllocateOption
+	if req.Major != nil {
+		opts = append(opts, gts.WithMajorVersion(*req.Major))
+	}
+	if req.ReserveTTLSeconds > 0 {
+		opts = append(opts, gts.WithReservation(time.Duration(req.ReserveTTLSeconds)*time.Second))
+	}
+
+	id, err := s.store.AllocateInstanceID(req.SchemaID, req.Vendor, req.Package, req.Namespace, req.Type, opts...)
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"ok":       true,
+		"id":       id,
+		"reserved": req.ReserveTTLSeconds > 0,
+	})
+}
+
+// handleCloudEvent ingests a structured-mode CloudEvents envelope: the instance carried in data is
+// validated against the schema named by the type/dataschema attributes and registered
+func (s *Server) handleCloudEvent(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfFrozen(w, r) {
+		return
+	}
+
+	var envelope map[string]any
+	if err := s.readJSON(r, &envelope); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	entity, err := gts.FromCloudEvent(envelope, s.store, &gts.CloudEventsConfig{Validate: true, GtsConfig: s.store.GtsConfig()})
+	if err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	if err := s.store.Register(entity); err != nil {
+		s.writeStoreError(w, r, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, withReferenceWarnings(map[string]any{
+		"ok":        true,
+		"gts_id":    entity.GtsID.ID,
+		"schema_id": entity.SchemaID,
+		"event_id":  envelope["id"],
+	}, entity))
+}
@@ -0,0 +1,1610 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// TestStableOrder_RepeatedRequests checks that with StableOrder repeated list and query requests
+// for the same store state answer byte-identical bodies
+func TestStableOrder_RepeatedRequests(t *testing.T) {
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{StableOrder: true})
+	for i := 0; i < 100; i++ {
+		content := map[string]any{
+			"gtsId":   fmt.Sprintf("gts.x.pkg%d.ns.event.v1.0~a.b.c.item%d.v1", i%5, i),
+			"payload": map[string]any{"n": i, "tags": []any{"a", "b"}, "name": fmt.Sprintf("item%d", i)},
+		}
+		if err := store.Register(gts.NewJsonEntity(content, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register entity: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	for _, path := range []string{"/entities?limit=1000", "/query?expr=gts.x.*&limit=1000"} {
+		var first []byte
+		for i := 0; i < 10; i++ {
+			resp, err := http.Get(ts.URL + path)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil || resp.StatusCode != http.StatusOK {
+				t.Fatalf("unexpected response for %s: %d %v", path, resp.StatusCode, err)
+			}
+			if first == nil {
+				first = body
+			} else if !bytes.Equal(first, body) {
+				t.Fatalf("expected byte-identical responses for %s", path)
+			}
+		}
+	}
+}
+
+// newUploadRequest builds a multipart POST /entities:upload request with the given files
+func newUploadRequest(t *testing.T, url string, files map[string][]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, data := range files {
+		part, err := mw.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write(data)
+	}
+	mw.Close()
+	req, err := http.NewRequest(http.MethodPost, url+"/entities:upload", &body)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// buildUploadZip returns an in-memory zip archive holding the given member contents in name order
+func buildUploadZip(t *testing.T, members map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create zip member: %v", err)
+		}
+		f.Write([]byte(members[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadEntities(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	archive := buildUploadZip(t, map[string]string{
+		"orders/2025/items.json": `[{"id": "gts.x.test.upload.item.v1~x.test._.a.v1"}, {"id": "gts.x.test.upload.item.v1~x.test._.b.v1"}]`,
+		"orders/dup.json":        `{"id": "gts.x.test.upload.item.v1~x.test._.a.v1"}`,
+		"orders/broken.json":     `{"id": `,
+		"node_modules/skip.json": `{"id": "gts.x.test.upload.item.v1~x.test._.skipped.v1"}`,
+	})
+	req := newUploadRequest(t, ts.URL, map[string][]byte{
+		"batch.zip": archive,
+		"one.json":  []byte(`{"id": "gts.x.test.upload.item.v1~x.test._.c.v1"}`),
+	})
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		OK        bool `json:"ok"`
+		Files     int  `json:"files"`
+		Documents int  `json:"documents"`
+		Failed    int  `json:"failed"`
+		Count     int  `json:"count"`
+		Total     int  `json:"total"`
+		Results   []struct {
+			Member   string           `json:"member"`
+			OK       bool             `json:"ok"`
+			Error    string           `json:"error"`
+			Entities []map[string]any `json:"entities"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.OK || result.Files != 2 || result.Documents != 4 || result.Failed != 2 || result.Count != 3 || result.Total != 4 {
+		t.Fatalf("unexpected aggregate counts: %+v", result)
+	}
+	for _, doc := range result.Results {
+		switch doc.Member {
+		case "batch.zip/orders/broken.json":
+			if doc.OK || doc.Error == "" {
+				t.Errorf("expected the malformed member to fail, got %+v", doc)
+			}
+		case "batch.zip/orders/dup.json":
+			if doc.OK || len(doc.Entities) != 1 || doc.Entities[0]["ok"] != false {
+				t.Errorf("expected the duplicate ID to be reported, got %+v", doc)
+			}
+		case "batch.zip/orders/2025/items.json":
+			if !doc.OK || len(doc.Entities) != 2 || doc.Entities[1]["label"] != "orders/2025/items.json#1" {
+				t.Errorf("unexpected nested member result: %+v", doc)
+			}
+		case "one.json":
+			if !doc.OK {
+				t.Errorf("expected the JSON file to be registered, got %+v", doc)
+			}
+		default:
+			t.Errorf("unexpected member %s", doc.Member)
+		}
+	}
+	if store.Get("gts.x.test.upload.item.v1~x.test._.skipped.v1") != nil {
+		t.Error("expected excluded directories to be skipped")
+	}
+}
+
+func TestUploadEntities_TooLarge(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	srv := NewServer(store, "127.0.0.1", 0, 0)
+	srv.SetUploadLimits(512, 2048)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	// The padding compresses well, so the archive fits the file limit while its member does not
+	large := fmt.Sprintf(`{"id": "gts.x.test.upload.item.v1~x.test._.big.v1", "padding": "%02000d"}`, 0)
+	many := make(map[string][]byte)
+	for i := 0; i < 5; i++ {
+		many[fmt.Sprintf("part%d.json", i)] = bytes.Repeat([]byte(" "), 500)
+	}
+	tests := map[string]map[string][]byte{
+		"file":           {"big.json": []byte(large)},
+		"archive member": {"batch.zip": buildUploadZip(t, map[string]string{"big.json": large})},
+		"request":        many,
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(newUploadRequest(t, ts.URL, files))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusRequestEntityTooLarge {
+				t.Errorf("expected 413, got %d", resp.StatusCode)
+			}
+		})
+	}
+	if len(store.List(10).Entities) != 0 {
+		t.Error("expected nothing to be registered by refused uploads")
+	}
+}
+
+func TestAddEntities_Atomic(t *testing.T) {
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{RefValidation: gts.RefValidationStrict})
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	batch := `[
+		{"id": "gts.x.test.atomic.item.v1~x.test._.a.v1", "type": "gts.x.test.atomic.item.v1~"},
+		{"$id": "gts://gts.x.test.atomic.item.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"},
+		{"id": "gts.x.test.atomic.item.v1~x.test._.b.v1", "type": "gts.x.test.atomic.missing.v1~"}
+	]`
+	post := func(body string) (int, map[string]any) {
+		resp, err := http.Post(ts.URL+"/entities:batch?atomic=true", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.StatusCode, result
+	}
+
+	status, result := post(batch)
+	apiErr, _ := result["error"].(map[string]any)
+	if status != http.StatusUnprocessableEntity || apiErr == nil || apiErr["code"] != ErrorCodeValidationFailed {
+		t.Fatalf("expected a 422 validation error envelope, got %d %v", status, result)
+	}
+	details, _ := apiErr["details"].(map[string]any)
+	if details["committed"] != false || details["failed"] != float64(1) || store.Count() != 0 {
+		t.Fatalf("expected the batch to be rejected without registering anything, got %v (count %d)", details, store.Count())
+	}
+
+	status, result = post(strings.Replace(batch, "atomic.missing", "atomic.item", 1))
+	if status != http.StatusOK || result["committed"] != true || result["count"] != float64(3) || store.Count() != 3 {
+		t.Errorf("expected the batch to be committed, got %d %v (count %d)", status, result, store.Count())
+	}
+}
+
+func TestAddEntities_DependencyOrder(t *testing.T) {
+	batch := `[
+		{"$id": "gts://gts.x.test.order.base.v1~x.test.order.derived.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "allOf": [{"$ref": "gts://gts.x.test.order.base.v1~"}]},
+		{"id": "gts.x.test.order.base.v1~x.test.order.derived.v1~x.test._.a.v1"},
+		{"$id": "gts://gts.x.test.order.base.v1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}
+	]`
+	for _, query := range []string{"ordered=false", "ordered=false&atomic=true"} {
+		t.Run(query, func(t *testing.T) {
+			store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{RefValidation: gts.RefValidationStrict})
+			ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+			defer ts.Close()
+
+			resp, err := http.Post(ts.URL+"/entities:batch?"+query, "application/json", strings.NewReader(batch))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			var result map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK || result["count"] != float64(3) || store.Count() != 3 {
+				t.Fatalf("expected the batch registered, got %d %v", resp.StatusCode, result)
+			}
+			if order, _ := result["order"].([]any); len(order) != 3 || order[0] != float64(2) || order[2] != float64(1) {
+				t.Errorf("expected the base first and the instance last, got %v", result["order"])
+			}
+		})
+	}
+}
+
+func TestAddEntity_DryRun(t *testing.T) {
+	const schemaID = "gts.x.test.dryrun.item.v1.0~"
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema(schemaID, map[string]any{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	store.Freeze()
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	body := `{"$id": "gts://gts.x.test.dryrun.item.v1.1~", "$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "required": ["name"]}`
+	resp, err := http.Post(ts.URL+"/entities?dry_run=true", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var plan gts.RegisterPlan
+	if err := json.NewDecoder(resp.Body).Decode(&plan); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !plan.IsNew || plan.PreviousID != schemaID || plan.CompatibilityWithPrevious == nil {
+		t.Fatalf("expected a plan comparing with %s, got %d %+v", schemaID, resp.StatusCode, plan)
+	}
+	if plan.CompatibilityWithPrevious.IsBackwardCompatible || len(plan.ValidationErrors) != 1 {
+		t.Errorf("expected a breaking change and the frozen store error, got %+v", plan)
+	}
+	if store.Count() != 1 {
+		t.Errorf("expected nothing to be registered, got %d entities", store.Count())
+	}
+}
+
+func TestErrorEnvelope_Handlers(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	schema := map[string]any{
+		"$id":      "gts://gts.x.test.errors.item.v1~",
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"name"},
+	}
+	instance := map[string]any{"id": "gts.x.test.errors.item.v1~x.test._.a.v1"}
+	for _, content := range []map[string]any{schema, instance} {
+		if err := store.Register(gts.NewJsonEntity(content, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register entity: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name, method, path, body string
+		status                   int
+		code                     string
+	}{
+		{"missing entity", http.MethodGet, "/entities/gts.x.test.errors.item.v1~x.test._.missing.v1", "", http.StatusNotFound, ErrorCodeEntityNotFound},
+		{"invalid JSON", http.MethodPost, "/entities", "{", http.StatusBadRequest, ErrorCodeBadRequest},
+		{"entity without ID", http.MethodPost, "/entities", `{"name": "x"}`, http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"invalid instance", http.MethodPost, "/validate-instance", `{"instance_id": "gts.x.test.errors.item.v1~x.test._.a.v1"}`, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"cast to missing schema", http.MethodPost, "/cast", `{"instance_id": "gts.x.test.errors.item.v1~x.test._.a.v1", "to_schema_id": "gts.x.test.errors.item.v1.1~"}`, http.StatusNotFound, ErrorCodeSchemaNotFound},
+		{"compatibility of missing schema", http.MethodGet, "/compatibility?old_schema_id=gts.x.test.errors.item.v1~&new_schema_id=gts.x.test.errors.item.v2~", "", http.StatusNotFound, ErrorCodeSchemaNotFound},
+		{"invalid ID", http.MethodGet, "/parse-id?gts_id=gts.bad", "", http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"schema without type ID", http.MethodPost, "/schemas", `{"type_id": "", "schema": {"type": "object"}}`, http.StatusUnprocessableEntity, ErrorCodeInvalidID},
+		{"schema with another $id", http.MethodPost, "/schemas", `{"type_id": "gts.x.test.errors.other.v1~", "schema": {"$id": "gts://gts.x.test.errors.item.v2~"}}`, http.StatusUnprocessableEntity, ErrorCodeValidationFailed},
+		{"invalid query", http.MethodGet, "/query?expr=gts.x.test.errors.*[", "", http.StatusBadRequest, ErrorCodeBadRequest},
+		{"missing attribute", http.MethodGet, "/attr?gts_with_path=gts.x.test.errors.item.v1~x.test._.a.v1@name", "", http.StatusNotFound, ErrorCodeAttributeNotFound},
+		{"invalid attribute path", http.MethodGet, "/attr?gts_with_path=gts.x.test.errors.item.v1~x.test._.a.v1@[0]", "", http.StatusBadRequest, ErrorCodeBadRequest},
+		{"attribute of missing entity", http.MethodGet, "/attr?gts_with_path=gts.x.test.errors.item.v1~x.test._.missing.v1@name", "", http.StatusNotFound, ErrorCodeEntityNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			var body errorEnvelope
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+				t.Fatalf("expected an error envelope: %v", err)
+			}
+			if resp.StatusCode != tt.status || body.Error.Code != tt.code || body.Error.Message == "" {
+				t.Errorf("expected %d %s, got %d %+v", tt.status, tt.code, resp.StatusCode, body.Error)
+			}
+		})
+	}
+
+	store.Freeze()
+	resp, err := http.Post(ts.URL+"/entities", "application/json", strings.NewReader(`{"id": "gts.x.test.errors.item.v1~x.test._.b.v1"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var body errorEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+		t.Fatalf("expected an error envelope: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict || body.Error.Code != ErrorCodeConflict || body.Error.Details["frozen"] != true {
+		t.Errorf("expected a 409 conflict for the frozen store, got %d %+v", resp.StatusCode, body.Error)
+	}
+}
+
+func TestGetEntity_ShortID(t *testing.T) {
+	const id = "gts.x.test.short.item.v1~"
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	short, err := gts.ShortID(id)
+	if err != nil {
+		t.Fatalf("ShortID failed: %v", err)
+	}
+	for _, path := range []string{id, short} {
+		resp, err := http.Get(ts.URL + "/entities/" + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result map[string]any
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected response for %s: %d %v", path, resp.StatusCode, err)
+		}
+		if result["id"] != id || result["short_id"] != short {
+			t.Errorf("expected %s with short ID %s, got %v", id, short, result)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/entities/aaaaaaaaaaaaaaaa")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown short ID, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetEntityByUUID(t *testing.T) {
+	const id = "gts.x.test.byuuid.item.v1~"
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{IndexUUIDs: true})
+	if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	u := gts.IDToUUID(id).UUID
+	resp, err := http.Get(ts.URL + "/entities/by-uuid/" + u)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var result map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || result["id"] != id {
+		t.Fatalf("unexpected response: %d %v %v", resp.StatusCode, err, result)
+	}
+
+	if err := store.Unregister(id); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	tests := []struct {
+		uuid   string
+		status int
+		code   string
+	}{
+		{u, http.StatusNotFound, ErrorCodeEntityNotFound},
+		{"not-a-uuid", http.StatusBadRequest, ErrorCodeBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + "/entities/by-uuid/" + tt.uuid)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var envelope errorEnvelope
+		err = json.NewDecoder(resp.Body).Decode(&envelope)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != tt.status || envelope.Error.Code != tt.code {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.uuid, tt.status, tt.code, resp.StatusCode, envelope)
+		}
+	}
+}
+
+func TestGetEntities_Since(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema("gts.x.test.since.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		since  string
+		status int
+		count  float64
+	}{
+		{"2000-01-01T00:00:00Z", http.StatusOK, 1},
+		{time.Now().Add(time.Hour).UTC().Format(time.RFC3339), http.StatusOK, 0},
+		{"yesterday", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + "/entities?since=" + tt.since)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result map[string]any
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != tt.status {
+			t.Fatalf("expected %d for since=%s, got %d %v", tt.status, tt.since, resp.StatusCode, err)
+		}
+		if tt.status == http.StatusOK && result["count"] != tt.count {
+			t.Errorf("expected %v entities changed since %s, got %v", tt.count, tt.since, result)
+		}
+	}
+}
+
+func TestGetEntities_Cursor(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	ids := []string{"gts.x.test.cursor.a.v1~", "gts.x.test.cursor.b.v1~", "gts.x.test.cursor.c.v1~"}
+	for _, id := range ids {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	var listed []string
+	cursor := ""
+	for pages := 0; pages < 3; pages++ {
+		resp, err := http.Get(ts.URL + "/entities?limit=2&cursor=" + cursor)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result gts.ListResult
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d %v", resp.StatusCode, err)
+		}
+		for _, info := range result.Entities {
+			listed = append(listed, info.ID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	if !reflect.DeepEqual(listed, ids) {
+		t.Errorf("expected %v across the pages, got %v", ids, listed)
+	}
+}
+
+func TestGetEntities_Filters(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	for _, id := range []string{"gts.x.commerce.orders.order.v1~", "gts.x.core.events.type.v1~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	for _, id := range []string{"gts.x.commerce.orders.order.v1~x.shop._.o1.v1", "gts.x.commerce.orders.order.v1~x.shop._.o2.v1"} {
+		if err := store.Register(gts.NewJsonEntity(map[string]any{"id": id}, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register instance: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		count  int
+		total  int
+	}{
+		{"schemas of a package", "?kind=schema&pattern=gts.x.commerce.*", http.StatusOK, 1, 1},
+		{"instances with limit", "?kind=instance&limit=1", http.StatusOK, 1, 2},
+		{"invalid kind", "?kind=types", http.StatusBadRequest, 0, 0},
+		{"invalid pattern", "?pattern=gts.x.*.bad", http.StatusBadRequest, 0, 0},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + "/entities" + tt.query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result gts.ListResult
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+			continue
+		}
+		if tt.status == http.StatusOK && (result.Count != tt.count || result.Total != tt.total) {
+			t.Errorf("%s: expected count %d and total %d, got %d and %d", tt.name, tt.count, tt.total, result.Count, result.Total)
+		}
+	}
+}
+
+func TestDeleteEntity(t *testing.T) {
+	const schemaID = "gts.x.test.delete.item.v1~"
+	const instanceID = schemaID + "x.test._.a.v1"
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{RefValidation: gts.RefValidationStrict})
+	if err := store.RegisterSchema(schemaID, map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	if err := store.Register(gts.NewJsonEntity(map[string]any{"id": instanceID}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	del := func(path string) (int, map[string]any) {
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	status, body := del("/entities/" + schemaID)
+	if status != http.StatusConflict {
+		t.Fatalf("expected 409 for a referenced schema, got %d %v", status, body)
+	}
+	details, _ := body["error"].(map[string]any)["details"].(map[string]any)
+	if !reflect.DeepEqual(details["referenced_by"], []any{instanceID}) {
+		t.Errorf("expected the referencing instance in the details, got %v", body)
+	}
+
+	if status, _ := del("/entities/" + schemaID + "?force=true"); status != http.StatusOK {
+		t.Errorf("expected 200 for a forced delete, got %d", status)
+	}
+	if status, _ := del("/entities/" + schemaID); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a deleted ID, got %d", status)
+	}
+	if store.Get(schemaID) != nil || store.Get(instanceID) == nil {
+		t.Error("expected only the schema to be removed")
+	}
+}
+
+func TestGetEntity_ETag(t *testing.T) {
+	const id = "gts.x.test.etag.item.v1~"
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	get := func(query, ifNoneMatch string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/entities/"+id+query, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := get("", "")
+	var result map[string]any
+	err := json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if err != nil || resp.StatusCode != http.StatusOK || etag == "" || result["content_hash"] != store.Get(id).ContentHash {
+		t.Fatalf("expected 200 with an ETag, got %d %q %v", resp.StatusCode, etag, result)
+	}
+
+	resp = get("", etag)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified || len(body) != 0 {
+		t.Errorf("expected 304 with an empty body for a matching If-None-Match, got %d %q", resp.StatusCode, body)
+	}
+
+	resp = get("", `"stale"`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for a stale If-None-Match, got %d", resp.StatusCode)
+	}
+
+	// Partial responses carry the same ETag and are conditional too
+	resp = get("?fields=type", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != etag {
+		t.Errorf("expected 200 with ETag %s for a partial GET, got %d %q", etag, resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	resp = get("?fields=type", etag)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified || len(body) != 0 {
+		t.Errorf("expected 304 for a conditional partial GET, got %d %q", resp.StatusCode, body)
+	}
+
+	// Tagging leaves the content unchanged but not the response
+	if err := store.SetTags(id, map[string]string{"team": "core"}); err != nil {
+		t.Fatalf("failed to set tags: %v", err)
+	}
+	resp = get("", etag)
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag || result["tags"] == nil {
+		t.Errorf("expected 200 with the tags and a new ETag after tagging, got %d %q %v", resp.StatusCode, resp.Header.Get("ETag"), result)
+	}
+}
+
+func TestGetEntity_PartialContent(t *testing.T) {
+	const id = "gts.x.test.partial.item.v1~"
+	store := gts.NewGtsStore(nil)
+	schema := map[string]any{
+		"description": "An item",
+		"type":        "object",
+		"required":    []any{"name", "size"},
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string", "maxLength": float64(10)},
+		},
+	}
+	if err := store.RegisterSchema(id, schema); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	get := func(query string) map[string]any {
+		resp, err := http.Get(ts.URL + "/entities/" + id + "?" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected response for %s: %d %v", query, resp.StatusCode, err)
+		}
+		return result
+	}
+
+	result := get("fields=description,properties.name.maxLength,required[1],properties.missing&path=required")
+	expected := map[string]any{
+		"description":               "An item",
+		"properties.name.maxLength": float64(10),
+		"required[1]":               "size",
+		"required":                  []any{"name", "size"},
+	}
+	if result["id"] != id || !reflect.DeepEqual(result["values"], expected) {
+		t.Errorf("expected values %v, got %v", expected, result)
+	}
+	if !reflect.DeepEqual(result["missing"], []any{"properties.missing"}) {
+		t.Errorf("expected properties.missing to be missing, got %v", result["missing"])
+	}
+	if _, ok := result["content"]; ok {
+		t.Error("expected the partial response to omit the content")
+	}
+
+	if result := get("fields=,"); result["content"] == nil {
+		t.Errorf("expected an empty selector list to return the full entity, got %v", result)
+	}
+}
+
+// httpSchemaLoader loads schema documents over HTTP for the jsonschema compiler
+type httpSchemaLoader struct{}
+
+func (httpSchemaLoader) Load(url string) (any, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return jsonschema.UnmarshalJSON(resp.Body)
+}
+
+func TestGetSchemaDocument(t *testing.T) {
+	const (
+		baseID    = "gts.x.test.docs.event.v1~"
+		derivedID = "gts.x.test.docs.event.v1~x.test.docs.order.v1~"
+	)
+	store := gts.NewGtsStore(nil)
+	schemas := []map[string]any{
+		{
+			"$id":        "gts://" + baseID,
+			"$schema":    "http://json-schema.org/draft-07/schema#",
+			"type":       "object",
+			"required":   []any{"id"},
+			"properties": map[string]any{"id": map[string]any{"type": "string"}},
+		},
+		{
+			"$id":     "gts://" + derivedID,
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"allOf": []any{
+				map[string]any{"$ref": "gts://" + baseID},
+				map[string]any{"required": []any{"amount"}, "properties": map[string]any{"amount": map[string]any{"type": "number"}}},
+			},
+		},
+	}
+	for _, content := range schemas {
+		if err := store.Register(gts.NewJsonEntity(content, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	// The '~' of a chained ID may be URL-encoded
+	encoded := strings.ReplaceAll(derivedID, "~", "%7E")
+	resp, err := http.Get(ts.URL + "/schemas/" + encoded)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var document map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&document)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected response: %d %v", resp.StatusCode, err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/schema+json" {
+		t.Errorf("expected application/schema+json, got %s", ct)
+	}
+	if !reflect.DeepEqual(document, store.Get(derivedID).Content) {
+		t.Errorf("expected the registered content, got %v", document)
+	}
+
+	// HEAD answers the headers only and a matching If-None-Match answers 304
+	etag := resp.Header.Get("ETag")
+	head, err := http.Head(ts.URL + "/schemas/" + derivedID)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	head.Body.Close()
+	if head.StatusCode != http.StatusOK || etag == "" || head.Header.Get("ETag") != etag {
+		t.Errorf("expected HEAD to answer 200 with ETag %s, got %d and %s", etag, head.StatusCode, head.Header.Get("ETag"))
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/schemas/"+derivedID, nil)
+	req.Header.Set("If-None-Match", etag)
+	cached, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	cached.Body.Close()
+	if cached.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching If-None-Match, got %d", cached.StatusCode)
+	}
+
+	// Instances and non-existent IDs are not schema documents
+	for _, id := range []string{"gts.x.test.docs.event.v1~x.test._.missing.v1", "gts.x.test.docs.missing.v1~"} {
+		resp, err := http.Get(ts.URL + "/schemas/" + id)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected 404 for %s, got %d", id, resp.StatusCode)
+		}
+	}
+
+	// With rewrite_id the references resolve against this server
+	schemaURL := ts.URL + "/schemas/" + encoded + "?rewrite_id=true"
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(jsonschema.SchemeURLLoader{"http": httpSchemaLoader{}})
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		t.Fatalf("failed to compile %s: %v", schemaURL, err)
+	}
+	if err := compiled.Validate(map[string]any{"id": "o-1", "amount": 10.0}); err != nil {
+		t.Errorf("expected a valid instance, got %v", err)
+	}
+	if err := compiled.Validate(map[string]any{"amount": 10.0}); err == nil {
+		t.Error("expected the instance to fail the required id of the referenced base schema")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	for _, id := range []string{"gts.x.test.prune.item.v1.0~", "gts.x.test.prune.item.v1.1~", "gts.x.test.prune.keep.v1.0~", "gts.x.test.prune.keep.v1.1~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	prune := func(body string) (int, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/prune", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.StatusCode, result
+	}
+
+	status, result := prune(`{"keep_minors": 1, "protect": ["gts.x.test.prune.keep.*"], "dry_run": true}`)
+	if status != http.StatusOK || result["dry_run"] != true || len(result["removed"].([]any)) != 1 || store.Count() != 4 {
+		t.Errorf("expected a dry run reporting one removal, got %d %v", status, result)
+	}
+	status, result = prune(`{"keep_minors": 1, "protect": ["gts.x.test.prune.keep.*"]}`)
+	if status != http.StatusOK || result["remaining"] != 3.0 || store.Get("gts.x.test.prune.item.v1.0~") != nil {
+		t.Errorf("expected gts.x.test.prune.item.v1.0~ to be pruned, got %d %v", status, result)
+	}
+
+	if status, _ := prune(`{"older_than": "a while"}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid duration, got %d", status)
+	}
+	store.Freeze()
+	if status, result := prune(`{"remove_unused": true}`); status != http.StatusConflict {
+		t.Errorf("expected 409 on a frozen store, got %d %v", status, result)
+	}
+}
+
+func TestGetState_LookupStats(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema("gts.x.test.state.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	store.Get("gts.x.test.state.item.v1~")
+	store.Get("gts.x.test.state.missing.v1~")
+
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/state")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		EntityCount int             `json:"entity_count"`
+		Lookups     gts.LookupStats `json:"lookups"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.EntityCount != 1 || result.Lookups.Hits < 1 || result.Lookups.Misses != 1 {
+		t.Errorf("expected one entity, a hit and a miss, got %+v", result)
+	}
+}
+
+// TestGtsConfig_RequestBodies checks that request bodies are parsed with the store's GtsConfig
+func TestGtsConfig_RequestBodies(t *testing.T) {
+	cfg := gts.DefaultGtsConfig()
+	cfg.EntityIDFields = []string{"uri"}
+	cfg.SchemaIDFields = []string{"typeRef"}
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{GtsConfig: cfg})
+	if err := store.RegisterSchema("gts.x.test.cfg.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	post := func(path, body string) map[string]any {
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d: %v", path, resp.StatusCode, result)
+		}
+		return result
+	}
+
+	if result := post("/entities", `{"uri": "gts.x.test.cfg.item.v1~x.test._.a.v1"}`); result["gts_id"] != "gts.x.test.cfg.item.v1~x.test._.a.v1" {
+		t.Errorf("expected the ID to be read from uri, got %v", result)
+	}
+	result := post("/extract-id", `{"id": "7a1d2f7e", "typeRef": "gts.x.test.cfg.item.v1~"}`)
+	if result["schema_id"] != "gts.x.test.cfg.item.v1~" || result["selected_schema_id_field"] != "typeRef" {
+		t.Errorf("expected the schema ID to be read from typeRef, got %v", result)
+	}
+}
+
+func TestStatsAndIntegrity(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema("gts.x.test.stats.item.v1.0~", map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"peer": map[string]any{"x-gts-ref": "gts.x.test.stats.peer.v1~"}},
+	}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	for _, id := range []string{"gts.x.test.stats.item.v1.0~x.test._.a.v1", "gts.y.test.stats.other.v1.0~y.test._.b.v1"} {
+		if err := store.Register(gts.NewJsonEntity(map[string]any{"id": id}, nil)); err != nil {
+			t.Fatalf("failed to register %s: %v", id, err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	getJSON := func(path string, out any) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d", path, resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+
+	var stats gts.StoreStats
+	getJSON("/stats", &stats)
+	if stats.Schemas != 1 || stats.Instances != 2 || stats.OrphanInstances != 1 || stats.SchemasWithMissingRefs != 1 || stats.Vendors["y"] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	var report gts.IntegrityReport
+	getJSON("/integrity", &report)
+	if report.OK || report.Checked != 3 || len(report.Problems) != 2 ||
+		report.Problems[0].Kind != gts.IntegrityMissingReference || report.Problems[1].EntityID != "gts.y.test.stats.other.v1.0~y.test._.b.v1" {
+		t.Errorf("unexpected integrity report: %+v", report)
+	}
+}
+
+func TestLineage(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	const (
+		base    = "gts.x.test.lineage.event.v1~"
+		derived = "gts.x.test.lineage.event.v1~x.test._.created.v1~"
+	)
+	schemas := []struct {
+		id     string
+		schema map[string]any
+	}{
+		{base, map[string]any{"type": "object", "properties": map[string]any{"id": map[string]any{"type": "string"}}}},
+		{derived, map[string]any{"allOf": []any{
+			map[string]any{"$ref": "gts://" + base},
+			map[string]any{"properties": map[string]any{"name": map[string]any{"type": "string"}}},
+		}}},
+	}
+	for _, s := range schemas {
+		if err := store.RegisterSchema(s.id, s.schema); err != nil {
+			t.Fatalf("failed to register %s: %v", s.id, err)
+		}
+	}
+
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/lineage?gts_id=" + derived)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var lineage gts.PropertyLineage
+	if err := json.NewDecoder(resp.Body).Decode(&lineage); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || lineage.Properties["id"][0].SchemaID != base || lineage.Properties["name"][0].SchemaID != derived {
+		t.Errorf("expected id from the base and name from the derived schema, got %d %+v", resp.StatusCode, lineage)
+	}
+
+	for query, status := range map[string]int{"": http.StatusBadRequest, "?gts_id=gts.x.test.lineage.missing.v1~": http.StatusNotFound} {
+		resp, err := http.Get(ts.URL + "/lineage" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("expected %d for %q, got %d", status, query, resp.StatusCode)
+		}
+	}
+}
+
+func TestLenientLookup_NormalizedIDHeader(t *testing.T) {
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{LenientLookup: true})
+	if err := store.RegisterSchema("gts.x.test.lenient.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		path, header string
+	}{
+		{"/entities/gts.x.test.lenient.item.v1~", ""},
+		{"/entities/GTS.X.Test.Lenient.Item.v1~", "gts.x.test.lenient.item.v1~"},
+		{"/schemas/GTS.X.Test.Lenient.Item.v1~", "gts.x.test.lenient.item.v1~"},
+		{"/query?expr=gts.x.test.lenient.*", ""},
+		{"/query?expr=GTS.X.Test.Lenient.*", "gts.x.test.lenient.*"},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get(normalizedIDHeader) != tt.header {
+			t.Errorf("%s: expected 200 with %s %q, got %d %q", tt.path, normalizedIDHeader, tt.header, resp.StatusCode, resp.Header.Get(normalizedIDHeader))
+		}
+	}
+}
+
+func TestCastBatch(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	for _, id := range []string{"gts.x.test.cast.item.v1.0~", "gts.x.test.cast.item.v1.1~", "gts.x.test.cast.item.v2.0~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	for _, id := range []string{"gts.x.test.cast.item.v1.0~x.test._.a.v1", "gts.x.test.cast.item.v2.0~x.test._.b.v1"} {
+		if err := store.Register(gts.NewJsonEntity(map[string]any{"id": id}, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register instance: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	castBatch := func(body string) (int, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/operations/cast-batch", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.StatusCode, result
+	}
+
+	status, result := castBatch(`{"pattern": "gts.x.test.cast.*", "to_schema_id": "gts.x.test.cast.item.v1.1~"}`)
+	if status != http.StatusOK || result["matched"] != 2.0 || result["succeeded"] != 1.0 || result["skipped"] != 1.0 {
+		t.Errorf("expected one cast and one skipped instance, got %d %v", status, result)
+	}
+	if status, _ := castBatch(`{"pattern": "gts.x.test.cast.*", "to_schema_id": "gts.x.test.cast.missing.v1~"}`); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing target schema, got %d", status)
+	}
+	if status, _ := castBatch(`{"pattern": "not-a-pattern", "to_schema_id": "gts.x.test.cast.item.v1.1~"}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid pattern, got %d", status)
+	}
+	if status, _ := castBatch(`{"pattern": "gts.x.test.cast.*"}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without a target schema, got %d", status)
+	}
+}
+
+func TestCast_InstanceContent(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	for _, id := range []string{"gts.x.test.cast.item.v1.0~", "gts.x.test.cast.item.v1.1~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"instance content", `{"instance": {"id": "gts.x.test.cast.item.v1.0~x.test._.a.v1"}, "to_schema_id": "gts.x.test.cast.item.v1.1~"}`, http.StatusOK},
+		{"content without schema", `{"instance": {"name": "a"}, "to_schema_id": "gts.x.test.cast.item.v1.1~"}`, http.StatusNotFound},
+		{"both instance_id and instance", `{"instance_id": "gts.x.test.cast.item.v1.0~x.test._.a.v1", "instance": {}, "to_schema_id": "gts.x.test.cast.item.v1.1~"}`, http.StatusBadRequest},
+		{"neither instance_id nor instance", `{"to_schema_id": "gts.x.test.cast.item.v1.1~"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(ts.URL+"/operations/cast", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+	if store.Count() != 2 {
+		t.Errorf("expected cast content not to be registered, got %d entities", store.Count())
+	}
+}
+
+func TestCompatibilitySeries(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	for _, id := range []string{"gts.x.test.series.item.v1.0~", "gts.x.test.series.item.v1.2~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"registered series", "?type_id=gts.x.test.series.item.v1~", http.StatusOK},
+		{"unknown type", "?type_id=gts.x.test.series.other.v1~", http.StatusNotFound},
+		{"minor version", "?type_id=gts.x.test.series.item.v1.0~", http.StatusUnprocessableEntity},
+		{"missing type_id", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + "/compatibility/series" + tt.query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result gts.SeriesCompatibilityResult
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+			continue
+		}
+		if tt.status == http.StatusOK && (len(result.Pairs) != 1 || !result.IsBackwardCompatible ||
+			len(result.MissingVersions) != 1 || result.MissingVersions[0] != "gts.x.test.series.item.v1.1~") {
+			t.Errorf("%s: unexpected result %+v", tt.name, result)
+		}
+	}
+}
+
+func TestCompatibility_IncludeDiff(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	schemas := map[string]map[string]any{
+		"gts.x.test.diff.item.v1.0~": {"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}},
+		"gts.x.test.diff.item.v1.1~": {"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string", "maxLength": 10}}},
+	}
+	for id, schema := range schemas {
+		if err := store.RegisterSchema(id, schema); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	for _, includeDiff := range []bool{false, true} {
+		url := ts.URL + "/compatibility?old_schema_id=gts.x.test.diff.item.v1.0~&new_schema_id=gts.x.test.diff.item.v1.1~"
+		if includeDiff {
+			url += "&include_diff=true"
+		}
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result gts.CompatibilityResult
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("include_diff=%v: expected 200, got %d", includeDiff, resp.StatusCode)
+		}
+		if !includeDiff {
+			if result.Diff != nil {
+				t.Errorf("expected no diff without include_diff, got %+v", result.Diff)
+			}
+			continue
+		}
+		if result.Diff == nil || len(result.Diff.Patch) != 1 || result.Diff.Patch[0].Path != "/properties/name/maxLength" ||
+			result.Diff.Summary[0] != "Added maxLength 10 to 'name'" {
+			t.Errorf("expected the added maxLength in the diff, got %+v", result.Diff)
+		}
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"id": map[string]any{"type": "string"}, "status": map[string]any{"type": "string", "default": "new"}},
+	}
+	if err := store.RegisterSchema("gts.x.test.defaults.item.v1~", schema); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	if err := store.Register(gts.NewJsonEntity(map[string]any{"id": "gts.x.test.defaults.item.v1~x.test._.a.v1"}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		added  int
+	}{
+		{"registered instance", `{"instance_id": "gts.x.test.defaults.item.v1~x.test._.a.v1"}`, http.StatusOK, 1},
+		{"instance content", `{"instance": {"id": "gts.x.test.defaults.item.v1~x.test._.b.v1", "status": "done"}}`, http.StatusOK, 0},
+		{"content with schema_id", `{"instance": {"name": "b"}, "schema_id": "gts.x.test.defaults.item.v1~"}`, http.StatusOK, 1},
+		{"unknown instance", `{"instance_id": "gts.x.test.defaults.item.v1~x.test._.z.v1"}`, http.StatusNotFound, 0},
+		{"neither instance_id nor instance", `{}`, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(ts.URL+"/operations/apply-defaults", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result struct {
+			Entity map[string]any `json:"entity"`
+			Added  []string       `json:"added"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+			continue
+		}
+		if tt.status == http.StatusOK && (len(result.Added) != tt.added || result.Entity["status"] == nil) {
+			t.Errorf("%s: expected %d added defaults, got %v in %v", tt.name, tt.added, result.Added, result.Entity)
+		}
+	}
+	if store.Count() != 2 {
+		t.Errorf("expected nothing to be registered, got %d entities", store.Count())
+	}
+}
+
+func TestReferrers(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	for _, id := range []string{"gts.x.test.refs.item.v1~", "gts.x.test.refs.item.v1.3~"} {
+		if err := store.RegisterSchema(id, map[string]any{"type": "object"}); err != nil {
+			t.Fatalf("failed to register schema: %v", err)
+		}
+	}
+	if err := store.Register(gts.NewJsonEntity(map[string]any{"id": "gts.x.test.refs.item.v1.3~x.test._.a.v1"}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	referrers := func(query string) (int, map[string]any) {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/operations/referrers?" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.StatusCode, result
+	}
+
+	if status, result := referrers("gts_id=gts.x.test.refs.item.v1~"); status != http.StatusOK || result["count"] != 0.0 {
+		t.Errorf("expected no exact referrers, got %d %v", status, result)
+	}
+	status, result := referrers("gts_id=gts.x.test.refs.item.v1~&include_minor_versions=true")
+	if status != http.StatusOK || result["count"] != 1.0 {
+		t.Errorf("expected the instance of v1.3~ as referrer, got %d %v", status, result)
+	}
+	if status, _ := referrers("gts_id=invalid"); status != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for an invalid ID, got %d", status)
+	}
+	if status, _ := referrers(""); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without gts_id, got %d", status)
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	schema := map[string]any{"$id": "gts://gts.x.test.all.item.v1~", "type": "object", "required": []any{"name"}}
+	if err := store.RegisterSchema("gts.x.test.all.item.v1~", schema); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	for _, content := range []map[string]any{
+		{"id": "gts.x.test.all.item.v1~x.test._.a.v1", "name": "A"},
+		{"id": "gts.x.test.all.item.v1~x.test._.b.v1"},
+	} {
+		if err := store.Register(gts.NewJsonEntity(content, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register instance: %v", err)
+		}
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/operations/validate-all", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var result map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || result["ok"] != false || result["valid"] != 2.0 || result["invalid"] != 1.0 {
+		t.Errorf("expected the schema and one instance to be valid, got %d %v", resp.StatusCode, result)
+	}
+}
+
+func TestValidateInstance_Violations(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	schema := map[string]any{
+		"$id":        "gts://gts.x.test.viol.item.v1~",
+		"type":       "object",
+		"required":   []any{"name"},
+		"properties": map[string]any{"count": map[string]any{"type": "integer"}},
+	}
+	if err := store.RegisterSchema("gts.x.test.viol.item.v1~", schema); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	if err := store.Register(gts.NewJsonEntity(map[string]any{"id": "gts.x.test.viol.item.v1~x.test._.a.v1", "count": "x"}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/operations/validate", "application/json", strings.NewReader(`{"instance_id": "gts.x.test.viol.item.v1~x.test._.a.v1"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var body errorEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+		t.Fatalf("expected an error envelope: %v", err)
+	}
+	violations, _ := body.Error.Details["violations"].([]any)
+	if resp.StatusCode != http.StatusUnprocessableEntity || body.Error.Code != ErrorCodeValidationFailed || len(violations) != 2 {
+		t.Fatalf("expected a 422 with two violations, got %d %+v", resp.StatusCode, body.Error)
+	}
+	keywords := map[any]any{}
+	for _, violation := range violations {
+		v := violation.(map[string]any)
+		keywords[v["keyword"]] = v["path"]
+	}
+	if keywords["required"] != "" || keywords["type"] != "/count" {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+}
+
+func TestReplaceEntity(t *testing.T) {
+	const schemaID = "gts.x.test.replace.item.v1~"
+	const instanceID = schemaID + "x.test._.a.v1"
+	store := gts.NewGtsStoreWithConfig(nil, &gts.RegistryConfig{LenientLookup: true})
+	schema := map[string]any{
+		"$id":        "gts://" + schemaID,
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"required":   []any{"name"},
+		"properties": map[string]any{"name": map[string]any{"type": "string"}},
+	}
+	if err := store.RegisterSchema(schemaID, schema); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	if err := store.Register(gts.NewJsonEntity(map[string]any{"id": instanceID, "name": "A"}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	put := func(path string, content map[string]any) (int, map[string]any) {
+		data, _ := json.Marshal(content)
+		req, err := http.NewRequest(http.MethodPut, ts.URL+path, bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	if status, body := put("/entities/"+schemaID+"x.test._.missing.v1", map[string]any{"id": schemaID + "x.test._.missing.v1"}); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown ID, got %d %v", status, body)
+	}
+	if status, body := put("/entities/"+instanceID, map[string]any{"id": schemaID + "x.test._.b.v1", "name": "B"}); status != http.StatusConflict {
+		t.Errorf("expected 409 for a body ID that differs from the path, got %d %v", status, body)
+	}
+	// The path ID is matched exactly, even when lookups are lenient
+	if status, body := put("/entities/GTS.x.test.replace.item.v1~x.test._.a.v1", map[string]any{"id": instanceID, "name": "B"}); status != http.StatusConflict {
+		t.Errorf("expected 409 for a path ID that only matches leniently, got %d %v", status, body)
+	}
+	if status, body := put("/entities/"+instanceID+"?validation=true", map[string]any{"id": instanceID}); status != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for an invalid instance, got %d %v", status, body)
+	}
+	if name := store.Get(instanceID).Content["name"]; name != "A" {
+		t.Errorf("expected the old content to be kept after a failed validation, got name %v", name)
+	}
+	if status, body := put("/entities/"+instanceID+"?validation=true", map[string]any{"id": instanceID, "name": "B"}); status != http.StatusOK {
+		t.Errorf("expected 200 for a valid instance, got %d %v", status, body)
+	}
+	if name := store.Get(instanceID).Content["name"]; name != "B" {
+		t.Errorf("expected the content to be replaced, got name %v", name)
+	}
+
+	// Requiring a new property breaks backward compatibility
+	updated := map[string]any{
+		"$id":      "gts://" + schemaID,
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"name", "size"},
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+			"size": map[string]any{"type": "integer"},
+		},
+	}
+	status, body := put("/entities/"+schemaID, updated)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200 for a schema update, got %d %v", status, body)
+	}
+	compatibility, _ := body["compatibility"].(map[string]any)
+	if compatibility["is_backward_compatible"] != false || compatibility["is_forward_compatible"] != true {
+		t.Errorf("expected the added required property to break backward compatibility only, got %v", compatibility)
+	}
+	if required := store.Get(schemaID).Content["required"]; !reflect.DeepEqual(required, []any{"name", "size"}) {
+		t.Errorf("expected the schema to be replaced, got required %v", required)
+	}
+}
+
+func TestQuery_ProjectionAndOrder(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	if err := store.Register(gts.NewJsonEntity(map[string]any{
+		"id":      "gts.x.test.proj.order.v1~x.test._.a.v1",
+		"payload": map[string]any{"orderId": "o-1"},
+	}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	get := func(path string) map[string]any {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d %v", path, resp.StatusCode, body)
+		}
+		return body
+	}
+
+	body := get("/query?expr=gts.x.test.proj.*&ids_only=true")
+	if !reflect.DeepEqual(body["ids"], []any{"gts.x.test.proj.order.v1~x.test._.a.v1"}) || len(body["results"].([]any)) != 0 {
+		t.Errorf("expected only the ID, got %v", body)
+	}
+
+	body = get("/query?expr=gts.x.test.proj.*&fields=payload.orderId,status")
+	want := []any{map[string]any{"id": "gts.x.test.proj.order.v1~x.test._.a.v1", "values": map[string]any{"payload.orderId": "o-1"}}}
+	if !reflect.DeepEqual(body["results"], want) {
+		t.Errorf("expected the projected order ID, got %v", body["results"])
+	}
+
+	if err := store.Register(gts.NewJsonEntity(map[string]any{
+		"id":      "gts.x.test.proj.order.v1~x.test._.b.v1",
+		"payload": map[string]any{"orderId": "o-2"},
+	}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	body = get("/query?expr=gts.x.test.proj.*&ids_only=true&order_by=payload.orderId&desc=true&limit=1")
+	if !reflect.DeepEqual(body["ids"], []any{"gts.x.test.proj.order.v1~x.test._.b.v1"}) {
+		t.Errorf("expected the order with the greatest order ID, got %v", body)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	source := gts.NewGtsStore(nil)
+	for _, content := range []map[string]any{
+		{"$id": "gts://gts.x.test.bundle.item.v1~", "type": "object"},
+		{"id": "gts.x.test.bundle.item.v1~x.test._.a.v1", "name": "A"},
+	} {
+		if err := source.Register(gts.NewJsonEntity(content, gts.DefaultGtsConfig())); err != nil {
+			t.Fatalf("failed to register entity: %v", err)
+		}
+	}
+	sourceServer := httptest.NewServer(NewServer(source, "127.0.0.1", 0, 0).Handler())
+	defer sourceServer.Close()
+	target := gts.NewGtsStore(nil)
+	targetServer := httptest.NewServer(NewServer(target, "127.0.0.1", 0, 0).Handler())
+	defer targetServer.Close()
+
+	resp, err := http.Get(sourceServer.URL + "/export")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	bundle, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	for _, wantAdded := range []float64{2, 0} {
+		resp, err = http.Post(targetServer.URL+"/import?on_conflict=fail", "application/json", bytes.NewReader(bundle))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var result map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || result["added"] != wantAdded || result["failed"] != 0.0 {
+			t.Errorf("expected %v added entities, got %d %v", wantAdded, resp.StatusCode, result)
+		}
+	}
+	if target.Count() != source.Count() {
+		t.Errorf("expected %d imported entities, got %d", source.Count(), target.Count())
+	}
+
+	for _, tt := range []struct{ query, body string }{{"", "{"}, {"?on_conflict=newest", "[]"}} {
+		resp, err = http.Post(targetServer.URL+"/import"+tt.query, "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected 400 for %q %q, got %d", tt.query, tt.body, resp.StatusCode)
+		}
+	}
+}
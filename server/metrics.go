@@ -0,0 +1,189 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations whose durations are recorded by GET /metrics
+const (
+	operationValidation = "validation"
+	operationCast       = "cast"
+)
+
+// durationBuckets are the upper bounds in seconds of the operation duration histograms
+var durationBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// endpointKey identifies an endpoint by method and route pattern
+type endpointKey struct {
+	method string
+	route  string
+}
+
+// histogram counts observations per bucket of durationBuckets; counts are not cumulative
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// metrics collects the counters and histograms served by GET /metrics
+type metrics struct {
+	mu        sync.Mutex
+	requests  map[endpointKey]uint64
+	errors    map[endpointKey]uint64
+	durations map[string]*histogram
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:  make(map[endpointKey]uint64),
+		errors:    make(map[endpointKey]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// recordRequest counts a request to an endpoint and, for a 4xx or 5xx status, an error
+func (m *metrics) recordRequest(method, route string, status int) {
+	key := endpointKey{method: method, route: route}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[key]++
+	if status >= http.StatusBadRequest {
+		m.errors[key]++
+	}
+}
+
+// observeSince records the duration of an operation started at start; meant to be deferred
+func (m *metrics) observeSince(operation string, start time.Time) {
+	seconds := time.Since(start).Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.durations[operation]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(durationBuckets))}
+		m.durations[operation] = h
+	}
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// write writes the metrics in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer, storeSize int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP gts_http_requests_total Number of HTTP requests by endpoint.")
+	fmt.Fprintln(w, "# TYPE gts_http_requests_total counter")
+	writeEndpointCounters(w, "gts_http_requests_total", m.requests)
+	fmt.Fprintln(w, "# HELP gts_http_request_errors_total Number of HTTP requests answered with a 4xx or 5xx status by endpoint.")
+	fmt.Fprintln(w, "# TYPE gts_http_request_errors_total counter")
+	writeEndpointCounters(w, "gts_http_request_errors_total", m.errors)
+
+	fmt.Fprintln(w, "# HELP gts_store_entities Number of entities in the store.")
+	fmt.Fprintln(w, "# TYPE gts_store_entities gauge")
+	fmt.Fprintf(w, "gts_store_entities %d\n", storeSize)
+
+	fmt.Fprintln(w, "# HELP gts_operation_duration_seconds Duration of validation and cast operations.")
+	fmt.Fprintln(w, "# TYPE gts_operation_duration_seconds histogram")
+	operations := make([]string, 0, len(m.durations))
+	for operation := range m.durations {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.durations[operation]
+		label := escapeLabelValue(operation)
+		var cumulative uint64
+		for i, bound := range durationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "gts_operation_duration_seconds_bucket{operation=\"%s\",le=\"%s\"} %d\n", label, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "gts_operation_duration_seconds_bucket{operation=\"%s\",le=\"+Inf\"} %d\n", label, h.count)
+		fmt.Fprintf(w, "gts_operation_duration_seconds_sum{operation=\"%s\"} %s\n", label, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "gts_operation_duration_seconds_count{operation=\"%s\"} %d\n", label, h.count)
+	}
+}
+
+// writeEndpointCounters writes one sample per endpoint, sorted by route and method
+func writeEndpointCounters(w io.Writer, name string, counters map[endpointKey]uint64) {
+	keys := make([]endpointKey, 0, len(counters))
+	for key := range counters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].method < keys[j].method
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{method=\"%s\",route=\"%s\"} %d\n", name, escapeLabelValue(key.method), escapeLabelValue(key.route), counters[key])
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a Prometheus label value
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// statusWriter records the status code of a response
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	sw.statusCode = code
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap exposes the underlying writer so that http.ResponseController can flush streamed responses
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// withMetrics counts requests and errors per endpoint. Endpoints are labelled with the route
+// pattern the mux matched, e.g. /entities/{id}, so that IDs do not create a label each;
+// requests matching no route are labelled "unmatched".
+func (s *Server) withMetrics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		handler.ServeHTTP(wrapped, r)
+
+		// The mux sets the matched pattern, e.g. "GET /entities/{id}", on the request
+		route := "unmatched"
+		if r.Pattern != "" {
+			route = r.Pattern
+			if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+				route = path
+			}
+		}
+		s.metrics.recordRequest(r.Method, route, wrapped.statusCode)
+	})
+}
+
+// handleMetrics serves the metrics in the Prometheus text exposition format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w, s.store.Count())
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+func TestMetrics(t *testing.T) {
+	store := gts.NewGtsStore(nil)
+	if err := store.RegisterSchema("gts.x.test.metrics.item.v1~", map[string]any{"type": "object"}); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+	ts := httptest.NewServer(NewServer(store, "127.0.0.1", 0, 0).Handler())
+	defer ts.Close()
+
+	for _, path := range []string{"/entities/gts.x.test.metrics.item.v1~", "/entities/gts.x.test.metrics.missing.v1~", "/no-such-route"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	resp, err := http.Post(ts.URL+"/validate-instance", "application/json", strings.NewReader(`{"instance_id": "gts.x.test.metrics.item.v1~x.test._.a.v1"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("expected 200 text/plain, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	data, _ := io.ReadAll(resp.Body)
+	body := string(data)
+
+	for _, want := range []string{
+		`gts_http_requests_total{method="GET",route="/entities/{id}"} 2`,
+		`gts_http_request_errors_total{method="GET",route="/entities/{id}"} 1`,
+		`gts_http_requests_total{method="GET",route="unmatched"} 1`,
+		`gts_http_request_errors_total{method="POST",route="/validate-instance"} 1`,
+		"gts_store_entities 1",
+		`gts_operation_duration_seconds_bucket{operation="validation",le="+Inf"} 1`,
+		`gts_operation_duration_seconds_count{operation="validation"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
@@ -7,18 +7,38 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"log"
 	"net/http"
+	"runtime/debug"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// requestIDHeader carries the request ID on requests and responses for correlation
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client supplied request IDs
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// requestIDFromContext returns the request ID assigned by withRequestID, if any
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// responseWriter wraps http.ResponseWriter to capture status code, and the body when captureBody
+// is set; streamed responses are only held in memory in that case
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
-	body       bytes.Buffer
+	statusCode  int
+	captureBody bool
+	body        bytes.Buffer
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
@@ -27,11 +47,79 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 func (rw *responseWriter) Write(p []byte) (int, error) {
-	// Capture body
-	rw.body.Write(p)
+	if rw.captureBody {
+		rw.body.Write(p)
+	}
 	return rw.ResponseWriter.Write(p)
 }
 
+// Unwrap exposes the underlying writer so that http.ResponseController can flush streamed responses
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// withRequestID assigns every request an ID, reusing the client's X-Request-ID when present,
+// and echoes it in the response header
+func (s *Server) withRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// recoveryWriter records whether the response header has been sent
+type recoveryWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (rw *recoveryWriter) WriteHeader(code int) {
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoveryWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying writer so that http.ResponseController can flush streamed responses
+func (rw *recoveryWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// withRecovery turns a panic in a handler into a JSON 500 response instead of crashing the server
+func (s *Server) withRecovery(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped := &recoveryWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			requestID := requestIDFromContext(r.Context())
+			log.Printf("ERROR: panic serving %s %s (request_id=%s): %v\n%s", r.Method, r.URL.Path, requestID, rec, debug.Stack())
+
+			if wrapped.wroteHeader {
+				// Part of the response is already sent; abort the connection so the client sees a failure
+				panic(http.ErrAbortHandler)
+			}
+			s.writeInternalError(w, r)
+		}()
+
+		handler.ServeHTTP(wrapped, r)
+	})
+}
+
 // withLogging wraps the handler with request logging
 func (s *Server) withLogging(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -41,7 +129,7 @@ func (s *Server) withLogging(handler http.Handler) http.Handler {
 		}
 
 		start := time.Now()
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK, captureBody: s.verbose >= 2}
 
 		// If highest verbosity, capture request body (log later after handler)
 		var reqBodyData []byte
@@ -57,11 +145,12 @@ func (s *Server) withLogging(handler http.Handler) http.Handler {
 		handler.ServeHTTP(wrapped, r)
 
 		duration := time.Since(start)
-		log.Printf("%s %s -> %d in %.1fms",
+		log.Printf("%s %s -> %d in %.1fms (request_id=%s)",
 			r.Method,
 			r.URL.Path,
 			wrapped.statusCode,
 			float64(duration.Microseconds())/1000.0,
+			requestIDFromContext(r.Context()),
 		)
 
 		if s.verbose >= 2 {
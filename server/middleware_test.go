@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRecovery_PanickingStore checks that a panic inside a handler answers 500 JSON
+// with the request ID and that the server keeps serving afterwards
+func TestRecovery_PanickingStore(t *testing.T) {
+	// A nil store stands in for a broken store: every store call from a handler panics
+	srv := NewServer(nil, "127.0.0.1", 0, 0)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/attr?gts_with_path=gts.x.core.events.type.v1~@name")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", resp.StatusCode)
+	}
+	var body errorEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+		t.Fatalf("expected JSON error envelope: %v", err)
+	}
+	if body.Error.Code != ErrorCodeInternal || body.Error.Message == "" {
+		t.Errorf("expected internal error code and message, got %+v", body.Error)
+	}
+	requestID := resp.Header.Get(requestIDHeader)
+	if requestID == "" || body.Error.Details["request_id"] != requestID {
+		t.Errorf("expected request_id %q in body and header, got body %v", requestID, body.Error.Details["request_id"])
+	}
+
+	resp, err = http.Get(ts.URL + "/validate-id?gts_id=gts.x.core.events.type.v1~")
+	if err != nil {
+		t.Fatalf("server stopped serving after panic: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200 after recovered panic, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get(requestIDHeader) == "" {
+		t.Error("expected request ID header on every response")
+	}
+}
+
+func TestRequestID_ReusesClientHeader(t *testing.T) {
+	srv := NewServer(nil, "127.0.0.1", 0, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/validate-id?gts_id=gts.x.core.events.type.v1~", nil)
+	req.Header.Set(requestIDHeader, "client-supplied-id")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(requestIDHeader); got != "client-supplied-id" {
+		t.Errorf("expected client request ID to be echoed, got %q", got)
+	}
+}
+
+func TestLogging_CapturesBodyOnlyWhenVerbose(t *testing.T) {
+	for _, verbose := range []int{1, 2} {
+		srv := NewServer(nil, "127.0.0.1", 0, verbose)
+		var captured int
+		handler := srv.withLogging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A streamed response written in chunks, like /query?stream=true
+			for i := 0; i < 100; i++ {
+				w.Write([]byte("{\"id\": \"gts.x.core.events.type.v1~\"}\n"))
+			}
+			captured = w.(*responseWriter).body.Len()
+		}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/query?stream=true", nil))
+
+		if verbose < 2 && captured != 0 {
+			t.Errorf("expected no body to be held at verbose=%d, got %d bytes", verbose, captured)
+		}
+		if verbose >= 2 && captured != rec.Body.Len() {
+			t.Errorf("expected the body to be captured at verbose=%d, got %d of %d bytes", verbose, captured, rec.Body.Len())
+		}
+	}
+}
@@ -0,0 +1,309 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+)
+
+// OpenAPIOptions configures GenerateOpenAPISpec and GenerateOpenAPISplit
+type OpenAPIOptions struct {
+	// Pattern limits the registered schemas that become components to those matching it
+	// (a GTS ID pattern as accepted by gts.MatchIDPattern); empty includes every schema
+	Pattern string
+	// Progress, when not nil, is called after each schema is converted, with the number of
+	// schemas converted so far, the total and the ID of the schema just converted
+	Progress func(done, total int, currentID string)
+}
+
+// OpenAPISplit is an OpenAPI specification split into a root document and one components
+// document per package, keyed by file name
+type OpenAPISplit struct {
+	Root     map[string]any
+	Packages map[string]map[string]any
+}
+
+// openAPIComponent is a registered schema converted to an OpenAPI component
+type openAPIComponent struct {
+	id      string
+	name    string
+	file    string
+	content map[string]any
+}
+
+// GenerateOpenAPISpec returns the OpenAPI specification of the server (see GetOpenAPISpec) with
+// the registered schemas selected by opts as components, in GTS ID order. The GTS $refs between
+// components point at the components; $refs to schemas left out by the pattern are kept.
+// Cancelling ctx stops the generation between schemas with ctx.Err().
+func (s *Server) GenerateOpenAPISpec(ctx context.Context, opts OpenAPIOptions) (map[string]any, error) {
+	components, err := s.openAPIComponents(ctx, opts, openAPIComponentName, openAPISchema, func(_, to *openAPIComponent) string {
+		return "#/components/schemas/" + to.name
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spec := s.GetOpenAPISpec()
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, c := range components {
+		schemas[c.name] = c.content
+	}
+	return spec, nil
+}
+
+// GenerateOpenAPISplit generates the specification of GenerateOpenAPISpec split by package: the
+// components of the schemas whose last segment is in vendor.package go to the document
+// vendor.package.json, and the root document lists every component as a $ref into its file
+func (s *Server) GenerateOpenAPISplit(ctx context.Context, opts OpenAPIOptions) (*OpenAPISplit, error) {
+	components, err := s.openAPIComponents(ctx, opts, openAPIComponentName, openAPISchema, func(from, to *openAPIComponent) string {
+		if from.file == to.file {
+			return "#/components/schemas/" + to.name
+		}
+		return to.file + "#/components/schemas/" + to.name
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	split := &OpenAPISplit{Root: s.GetOpenAPISpec(), Packages: make(map[string]map[string]any)}
+	rootSchemas := split.Root["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, c := range components {
+		doc, ok := split.Packages[c.file]
+		if !ok {
+			doc = map[string]any{
+				"openapi":    split.Root["openapi"],
+				"info":       map[string]any{"title": strings.TrimSuffix(c.file, ".json"), "version": "0.1.0"},
+				"paths":      map[string]any{},
+				"components": map[string]any{"schemas": map[string]any{}},
+			}
+			split.Packages[c.file] = doc
+		}
+		doc["components"].(map[string]any)["schemas"].(map[string]any)[c.name] = c.content
+		rootSchemas[c.name] = map[string]any{"$ref": c.file + "#/components/schemas/" + c.name}
+	}
+	return split, nil
+}
+
+// GenerateSchemaComponents returns an OpenAPI 3.1 document holding the registered schemas
+// selected by opts as components.schemas, without the server API. Components are named after
+// their GTS ID with '.' and '~' written as '_'. Schemas are flattened with GtsStore.FlattenSchema,
+// which merges the allOf parts that $ref registered schemas, how derived types extend their parent,
+// so each component describes its type completely; other GTS $refs point at the components.
+// Vendor extensions such as x-gts-ref are kept.
+func (s *Server) GenerateSchemaComponents(ctx context.Context, opts OpenAPIOptions) (map[string]any, error) {
+	convert := func(schema map[string]any, refFor func(id string) (string, bool)) map[string]any {
+		return openAPISchema(s.store.FlattenSchema(schema), refFor)
+	}
+	components, err := s.openAPIComponents(ctx, opts, openAPISchemaComponentName, convert, func(_, to *openAPIComponent) string {
+		return "#/components/schemas/" + to.name
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]any, len(components))
+	for _, c := range components {
+		if _, taken := schemas[c.name]; taken {
+			return nil, fmt.Errorf("schemas map to the same component name '%s'", c.name)
+		}
+		schemas[c.name] = c.content
+	}
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "GTS Schemas",
+			"version":     "0.1.0",
+			"description": "GTS (Global Type System) schemas as OpenAPI components",
+		},
+		"paths":      map[string]any{},
+		"components": map[string]any{"schemas": schemas},
+	}, nil
+}
+
+// WriteDir writes the documents of the split to dir, the root document as openapi.json. Each file
+// is written to a temporary file first and renamed into place, so a failed or interrupted run
+// does not leave truncated documents behind.
+func (split *OpenAPISplit) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := make([]string, 0, len(split.Packages))
+	for file := range split.Packages {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := writeJSONFileAtomic(filepath.Join(dir, file), split.Packages[file]); err != nil {
+			return err
+		}
+	}
+	return writeJSONFileAtomic(filepath.Join(dir, "openapi.json"), split.Root)
+}
+
+// WriteOpenAPIFile writes an OpenAPI document to path as canonical JSON, or as YAML when format is
+// "yaml". Like WriteDir it writes a temporary file first and renames it into place.
+func WriteOpenAPIFile(path string, doc map[string]any, format string) error {
+	if format != "yaml" {
+		return writeJSONFileAtomic(path, doc)
+	}
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, doc); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeJSONFileAtomic writes v as canonical JSON to path with writeFileAtomic
+func writeJSONFileAtomic(path string, v any) error {
+	data, err := gts.CanonicalJSON(v)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it to path, which is
+// therefore either left untouched or entirely replaced
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// openAPIComponents converts the registered schemas selected by opts, sorted by GTS ID, naming
+// them with name and converting their content with convert; refFor returns the $ref to component
+// to in the document of component from
+func (s *Server) openAPIComponents(
+	ctx context.Context,
+	opts OpenAPIOptions,
+	name func(id string) string,
+	convert func(schema map[string]any, refFor func(id string) (string, bool)) map[string]any,
+	refFor func(from, to *openAPIComponent) string,
+) ([]*openAPIComponent, error) {
+	var entities []*gts.JsonEntity
+	err := s.store.QueryStream(gts.GtsPrefix+"*", func(item gts.QueryItem) bool {
+		if entity := s.store.Get(item.ID); entity != nil && entity.IsSchema {
+			entities = append(entities, entity)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].GtsID.ID < entities[j].GtsID.ID
+	})
+
+	selected := make([]*gts.JsonEntity, 0, len(entities))
+	for _, entity := range entities {
+		if opts.Pattern != "" {
+			match := gts.MatchIDPattern(entity.GtsID.ID, opts.Pattern)
+			if match.Error != "" {
+				return nil, fmt.Errorf("invalid pattern '%s': %s", opts.Pattern, match.Error)
+			}
+			if !match.Match {
+				continue
+			}
+		}
+		selected = append(selected, entity)
+	}
+
+	components := make([]*openAPIComponent, len(selected))
+	byID := make(map[string]*openAPIComponent, len(selected))
+	for i, entity := range selected {
+		last := entity.GtsID.Segments[len(entity.GtsID.Segments)-1]
+		components[i] = &openAPIComponent{
+			id:   entity.GtsID.ID,
+			name: name(entity.GtsID.ID),
+			file: last.Vendor + "." + last.Package + ".json",
+		}
+		byID[entity.GtsID.ID] = components[i]
+	}
+
+	for i, entity := range selected {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		c := components[i]
+		c.content = convert(entity.Content, func(id string) (string, bool) {
+			target, ok := byID[id]
+			if !ok {
+				return "", false
+			}
+			return refFor(c, target), true
+		})
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(components), c.id)
+		}
+	}
+	return components, nil
+}
+
+// openAPIComponentName returns the component name of a schema: its GTS ID with '~', which OpenAPI
+// does not allow in component names, replaced with '-', which GTS IDs do not contain
+func openAPIComponentName(id string) string {
+	return strings.ReplaceAll(id, "~", "-")
+}
+
+// openAPISchemaComponentName returns the component name of a schema in GenerateSchemaComponents:
+// its GTS ID with '.' and '~' replaced with '_'
+func openAPISchemaComponentName(id string) string {
+	return strings.NewReplacer(".", "_", "~", "_").Replace(id)
+}
+
+// openAPISchema returns a copy of a schema for use as an OpenAPI component: $id and $schema,
+// which OpenAPI 3.0 schemas do not have, are dropped from the top level, and the GTS $refs that
+// refFor resolves are replaced with the component $refs
+func openAPISchema(schema map[string]any, refFor func(id string) (string, bool)) map[string]any {
+	out := rewriteComponentRefs(schema, refFor).(map[string]any)
+	delete(out, "$id")
+	delete(out, "$schema")
+	return out
+}
+
+// rewriteComponentRefs returns a copy of node with the $refs to GTS IDs resolved by refFor replaced
+func rewriteComponentRefs(node any, refFor func(id string) (string, bool)) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" && !strings.HasPrefix(ref, "#") {
+				if target, ok := refFor(strings.TrimPrefix(ref, gts.GtsURIPrefix)); ok {
+					out[key] = target
+					continue
+				}
+			}
+			out[key] = rewriteComponentRefs(value, refFor)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = rewriteComponentRefs(item, refFor)
+		}
+		return out
+	default:
+		return v
+	}
+}
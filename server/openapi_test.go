@@ -0,0 +1,368 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GlobalTypeSystem/gts-go/gts"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const (
+	openAPIBaseID    = "gts.x.core.events.type.v1~"
+	openAPIPlacedID  = "gts.x.core.events.type.v1~acme.commerce.orders.placed.v1.0~"
+	openAPIInvoiceID = "gts.x.core.events.type.v1~acme.billing.invoices.issued.v1.0~"
+)
+
+// newOpenAPITestServer returns a server over a base event schema and two derived schemas in
+// different packages
+func newOpenAPITestServer(t *testing.T) *Server {
+	t.Helper()
+	store := gts.NewGtsStore(nil)
+	schemas := map[string]map[string]any{
+		openAPIBaseID: {"type": "object", "properties": map[string]any{"type": map[string]any{"type": "string"}}},
+	}
+	for _, id := range []string{openAPIPlacedID, openAPIInvoiceID} {
+		schemas[id] = map[string]any{"allOf": []any{
+			map[string]any{"$ref": gts.GtsURIPrefix + openAPIBaseID},
+			map[string]any{"properties": map[string]any{"type": map[string]any{"const": id}}},
+		}}
+	}
+	for id, schema := range schemas {
+		schema["$id"] = gts.GtsURIPrefix + id
+		schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+		if err := store.RegisterSchema(id, schema); err != nil {
+			t.Fatalf("failed to register %s: %v", id, err)
+		}
+	}
+	if err := store.Register(gts.NewJsonEntity(map[string]any{"id": openAPIBaseID + "x.core._.e1.v1", "type": "x"}, gts.DefaultGtsConfig())); err != nil {
+		t.Fatalf("failed to register instance: %v", err)
+	}
+	return NewServer(store, "127.0.0.1", 8000, 0)
+}
+
+func openAPISchemas(spec map[string]any) map[string]any {
+	return spec["components"].(map[string]any)["schemas"].(map[string]any)
+}
+
+func TestGenerateOpenAPISpec(t *testing.T) {
+	srv := newOpenAPITestServer(t)
+
+	var progress []string
+	spec, err := srv.GenerateOpenAPISpec(context.Background(), OpenAPIOptions{
+		Progress: func(done, total int, currentID string) {
+			if done != len(progress)+1 || total != 3 {
+				t.Errorf("unexpected progress %d/%d", done, total)
+			}
+			progress = append(progress, currentID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPISpec failed: %v", err)
+	}
+	if expected := []string{openAPIBaseID, openAPIInvoiceID, openAPIPlacedID}; !reflect.DeepEqual(progress, expected) {
+		t.Errorf("expected the schemas in ID order, got %v", progress)
+	}
+
+	schemas := openAPISchemas(spec)
+	placed, ok := schemas[openAPIComponentName(openAPIPlacedID)].(map[string]any)
+	if !ok || schemas["Error"] == nil || len(schemas) != 4 {
+		t.Fatalf("expected the Error schema and three components, got %v", schemas)
+	}
+	if _, ok := placed["$id"]; ok {
+		t.Error("expected $id to be dropped from components")
+	}
+	ref := placed["allOf"].([]any)[0].(map[string]any)["$ref"]
+	if ref != "#/components/schemas/"+openAPIComponentName(openAPIBaseID) {
+		t.Errorf("expected the base $ref to point at its component, got %v", ref)
+	}
+
+	// Repeated runs produce identical documents
+	again, err := srv.GenerateOpenAPISpec(context.Background(), OpenAPIOptions{})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPISpec failed: %v", err)
+	}
+	first, _ := gts.CanonicalJSON(spec)
+	second, _ := gts.CanonicalJSON(again)
+	if string(first) != string(second) {
+		t.Error("expected repeated generation to be byte-identical")
+	}
+}
+
+func TestGenerateOpenAPISpec_Pattern(t *testing.T) {
+	srv := newOpenAPITestServer(t)
+
+	spec, err := srv.GenerateOpenAPISpec(context.Background(), OpenAPIOptions{Pattern: "gts.x.core.events.type.v1~acme.commerce.*"})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPISpec failed: %v", err)
+	}
+	schemas := openAPISchemas(spec)
+	if len(schemas) != 2 || schemas[openAPIComponentName(openAPIPlacedID)] == nil {
+		t.Fatalf("expected only the matching component, got %v", schemas)
+	}
+	// The base is not a component, so the $ref to it is kept
+	placed := schemas[openAPIComponentName(openAPIPlacedID)].(map[string]any)
+	if ref := placed["allOf"].([]any)[0].(map[string]any)["$ref"]; ref != gts.GtsURIPrefix+openAPIBaseID {
+		t.Errorf("expected the $ref to the excluded base to be kept, got %v", ref)
+	}
+
+	if _, err := srv.GenerateOpenAPISpec(context.Background(), OpenAPIOptions{Pattern: "gts.x.*.bad*"}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestGenerateOpenAPISpec_Cancel(t *testing.T) {
+	srv := newOpenAPITestServer(t)
+	dir := t.TempDir()
+	existing := []byte(`{"previous": true}`)
+	if err := os.WriteFile(filepath.Join(dir, "openapi.json"), existing, 0o644); err != nil {
+		t.Fatalf("failed to write previous spec: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	split, err := srv.GenerateOpenAPISplit(ctx, OpenAPIOptions{
+		Progress: func(done, total int, currentID string) {
+			if done == 1 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) || split != nil {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Nothing was written, so the previous spec is intact
+	entries, _ := os.ReadDir(dir)
+	data, _ := os.ReadFile(filepath.Join(dir, "openapi.json"))
+	if len(entries) != 1 || string(data) != string(existing) {
+		t.Errorf("expected the previous spec to be left untouched, got %d files and %s", len(entries), data)
+	}
+}
+
+func TestGenerateOpenAPISplit_Reassembles(t *testing.T) {
+	srv := newOpenAPITestServer(t)
+	split, err := srv.GenerateOpenAPISplit(context.Background(), OpenAPIOptions{})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPISplit failed: %v", err)
+	}
+	dir := t.TempDir()
+	if err := split.WriteDir(dir); err != nil {
+		t.Fatalf("WriteDir failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	if expected := []string{"acme.billing.json", "acme.commerce.json", "openapi.json", "x.core.json"}; !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected one file per package and the root document, got %v", files)
+	}
+
+	readJSON := func(name string) map[string]any {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("failed to decode %s: %v", name, err)
+		}
+		return doc
+	}
+
+	// Replace every root $ref with the component it names, making references local
+	root := readJSON("openapi.json")
+	rootSchemas := openAPISchemas(root)
+	for name, schema := range rootSchemas {
+		ref, ok := schema.(map[string]any)["$ref"].(string)
+		if !ok {
+			continue
+		}
+		file, pointer, _ := strings.Cut(ref, "#")
+		component := openAPISchemas(readJSON(file))[name]
+		if pointer != "/components/schemas/"+name || component == nil {
+			t.Fatalf("root $ref %s does not resolve", ref)
+		}
+		rootSchemas[name] = component
+	}
+	reassembled, _ := json.Marshal(root)
+	localized := strings.ReplaceAll(string(reassembled), `"x.core.json#/`, `"#/`)
+
+	spec, err := srv.GenerateOpenAPISpec(context.Background(), OpenAPIOptions{})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPISpec failed: %v", err)
+	}
+	expected, _ := json.Marshal(spec)
+	var a, b any
+	_ = json.Unmarshal([]byte(localized), &a)
+	_ = json.Unmarshal(expected, &b)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("reassembled split spec differs from the single-file spec:\n%s\n%s", localized, expected)
+	}
+}
+
+// validateOpenAPIDocument validates doc against the OpenAPI 3.1 meta-schema in testdata
+func validateOpenAPIDocument(t *testing.T, doc map[string]any) {
+	t.Helper()
+	const metaSchemaURL = "https://spec.openapis.org/oas/3.1/schema/2022-10-07"
+	file, err := os.Open(filepath.Join("testdata", "openapi-3.1-schema.json"))
+	if err != nil {
+		t.Fatalf("failed to open the OpenAPI meta-schema: %v", err)
+	}
+	defer file.Close()
+	metaSchema, err := jsonschema.UnmarshalJSON(file)
+	if err != nil {
+		t.Fatalf("failed to read the OpenAPI meta-schema: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(metaSchemaURL, metaSchema); err != nil {
+		t.Fatalf("failed to add the OpenAPI meta-schema: %v", err)
+	}
+	compiled, err := compiler.Compile(metaSchemaURL)
+	if err != nil {
+		t.Fatalf("failed to compile the OpenAPI meta-schema: %v", err)
+	}
+
+	// Validate the document as it is serialized
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode the document: %v", err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode the document: %v", err)
+	}
+	if err := compiled.Validate(instance); err != nil {
+		t.Errorf("expected a valid OpenAPI 3.1 document, got %v", err)
+	}
+}
+
+func TestGenerateSchemaComponents(t *testing.T) {
+	srv := newOpenAPITestServer(t)
+	ref := map[string]any{
+		"$id":     gts.GtsURIPrefix + "gts.x.core.events.ref.v1~",
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"event":  map[string]any{"$ref": gts.GtsURIPrefix + openAPIPlacedID},
+			"target": map[string]any{"type": "string", "x-gts-ref": "gts.x.core.events.*"},
+		},
+	}
+	if err := srv.store.RegisterSchema("gts.x.core.events.ref.v1~", ref); err != nil {
+		t.Fatalf("failed to register schema: %v", err)
+	}
+
+	spec, err := srv.GenerateSchemaComponents(context.Background(), OpenAPIOptions{})
+	if err != nil {
+		t.Fatalf("GenerateSchemaComponents: %v", err)
+	}
+	validateOpenAPIDocument(t, spec)
+	if spec["openapi"] != "3.1.0" {
+		t.Errorf("expected OpenAPI 3.1.0, got %v", spec["openapi"])
+	}
+	if paths := spec["paths"].(map[string]any); len(paths) != 0 {
+		t.Errorf("expected no server paths, got %d", len(paths))
+	}
+
+	schemas := openAPISchemas(spec)
+	placed, ok := schemas["gts_x_core_events_type_v1_acme_commerce_orders_placed_v1_0_"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected a sanitized component for %s, got %v", openAPIPlacedID, reflect.ValueOf(schemas).MapKeys())
+	}
+	if _, hasAllOf := placed["allOf"]; hasAllOf {
+		t.Errorf("expected the allOf chain to be flattened, got %v", placed["allOf"])
+	}
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"type": map[string]any{"const": openAPIPlacedID},
+		},
+	}
+	if !reflect.DeepEqual(placed, want) {
+		t.Errorf("unexpected flattened component:\n got %v\nwant %v", placed, want)
+	}
+
+	props := schemas["gts_x_core_events_ref_v1_"].(map[string]any)["properties"].(map[string]any)
+	if got := props["event"].(map[string]any)["$ref"]; got != "#/components/schemas/gts_x_core_events_type_v1_acme_commerce_orders_placed_v1_0_" {
+		t.Errorf("expected the GTS $ref to point at the component, got %v", got)
+	}
+	if got := props["target"].(map[string]any)["x-gts-ref"]; got != "gts.x.core.events.*" {
+		t.Errorf("expected x-gts-ref to be kept, got %v", got)
+	}
+}
+
+func TestWriteOpenAPIFile(t *testing.T) {
+	dir := t.TempDir()
+	doc := map[string]any{"openapi": "3.1.0", "paths": map[string]any{}}
+	for _, tt := range []struct{ name, format, want string }{
+		{"spec.json", "json", "{\n  \"openapi\": \"3.1.0\",\n  \"paths\": {}\n}\n"},
+		{"spec.yaml", "yaml", "openapi: \"3.1.0\"\npaths: {}\n"},
+	} {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte("previous"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if err := WriteOpenAPIFile(path, doc, tt.format); err != nil {
+			t.Fatalf("WriteOpenAPIFile failed: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != tt.want {
+			t.Errorf("expected %s to be replaced with %q, got %q", tt.name, tt.want, data)
+		}
+	}
+
+	// Only the documents are left, no temporary files
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected 2 files, got %d", len(entries))
+	}
+
+	// A document that cannot be written leaves the previous file untouched
+	path := filepath.Join(dir, "spec.json")
+	if err := WriteOpenAPIFile(path, map[string]any{"bad": make(chan int)}, "json"); err == nil {
+		t.Error("expected an error for a document that cannot be encoded")
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "3.1.0") {
+		t.Errorf("expected the previous document to be kept, got %q", data)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	var b strings.Builder
+	err := WriteYAML(&b, map[string]any{
+		"openapi": "3.1.0",
+		"paths":   map[string]any{},
+		"list":    []any{map[string]any{"$ref": "#/a", "count": 1}, "yes", true},
+	})
+	if err != nil {
+		t.Fatalf("WriteYAML: %v", err)
+	}
+	want := `list:
+  -
+    "$ref": "#/a"
+    count: 1
+  - "yes"
+  - true
+openapi: "3.1.0"
+paths: {}
+`
+	if b.String() != want {
+		t.Errorf("unexpected YAML:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
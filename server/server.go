@@ -22,8 +22,23 @@ type Server struct {
 	port    int
 	verbose int
 	mux     *http.ServeMux
+	metrics *metrics
+
+	// maxUploadFileSize and maxUploadSize limit POST /entities:upload, and maxUploadSize POST /import
+	// (see SetUploadLimits)
+	maxUploadFileSize int64
+	maxUploadSize     int64
+
+	// access holds the CORS origins and the auth token (see SetAccessConfig)
+	access AccessConfig
 }
 
+// Default limits of POST /entities:upload
+const (
+	DefaultMaxUploadFileSize int64 = 10 << 20
+	DefaultMaxUploadSize     int64 = 50 << 20
+)
+
 // NewServer creates a new GTS HTTP server
 func NewServer(store *gts.GtsStore, host string, port int, verbose int) *Server {
 	s := &Server{
@@ -32,19 +47,48 @@ func NewServer(store *gts.GtsStore, host string, port int, verbose int) *Server
 		port:    port,
 		verbose: verbose,
 		mux:     http.NewServeMux(),
+		metrics: newMetrics(),
+
+		maxUploadFileSize: DefaultMaxUploadFileSize,
+		maxUploadSize:     DefaultMaxUploadSize,
 	}
 	s.registerRoutes()
 	return s
 }
 
+// SetUploadLimits sets the maximum size in bytes of each uploaded file or archive member and of a
+// whole POST /entities:upload or POST /import request; zero keeps the current limit
+func (s *Server) SetUploadLimits(maxFileSize, maxTotalSize int64) {
+	if maxFileSize > 0 {
+		s.maxUploadFileSize = maxFileSize
+	}
+	if maxTotalSize > 0 {
+		s.maxUploadSize = maxTotalSize
+	}
+}
+
 // registerRoutes registers all HTTP routes
 func (s *Server) registerRoutes() {
 	// Entity management
 	s.mux.HandleFunc("GET /entities", s.handleGetEntities)
 	s.mux.HandleFunc("GET /entities/{id}", s.handleGetEntity)
+	s.mux.HandleFunc("GET /entities/by-uuid/{uuid}", s.handleGetEntityByUUID)
+	s.mux.HandleFunc("PUT /entities/{id}", s.handleReplaceEntity)
+	s.mux.HandleFunc("DELETE /entities/{id}", s.handleDeleteEntity)
+	s.mux.HandleFunc("PUT /entities/{id}/tags", s.handleSetTags)
 	s.mux.HandleFunc("POST /entities", s.handleAddEntity)
 	s.mux.HandleFunc("POST /entities/bulk", s.handleAddEntities)
+	s.mux.HandleFunc("POST /entities:batch", s.handleAddEntities)
+	s.mux.HandleFunc("POST /entities:upload", s.handleUploadEntities)
 	s.mux.HandleFunc("POST /schemas", s.handleAddSchema)
+	s.mux.HandleFunc("GET /schemas/{id}", s.handleGetSchemaDocument)
+	s.mux.HandleFunc("POST /prune", s.handlePrune)
+	s.mux.HandleFunc("GET /export", s.handleExport)
+	s.mux.HandleFunc("POST /import", s.handleImport)
+	s.mux.HandleFunc("GET /state", s.handleGetState)
+	s.mux.HandleFunc("GET /types", s.handleGetTypes)
+	s.mux.HandleFunc("GET /stats", s.handleGetStats)
+	s.mux.HandleFunc("GET /integrity", s.handleGetIntegrity)
 
 	// OP#1 - Validate ID
 	s.mux.HandleFunc("GET /validate-id", s.handleValidateID)
@@ -60,24 +104,42 @@ func (s *Server) registerRoutes() {
 
 	// OP#5 - UUID
 	s.mux.HandleFunc("GET /uuid", s.handleUUID)
+	s.mux.HandleFunc("GET /uuid/verify", s.handleVerifyUUIDs)
 
 	// OP#6 - Validate Instance
 	s.mux.HandleFunc("POST /validate-instance", s.handleValidateInstance)
+	s.mux.HandleFunc("POST /operations/validate", s.handleValidateInstance)
+	s.mux.HandleFunc("POST /operations/validate-all", s.handleValidateAll)
 
 	// OP#7 - Resolve Relationships
 	s.mux.HandleFunc("GET /resolve-relationships", s.handleResolveRelationships)
+	s.mux.HandleFunc("GET /lineage", s.handleLineage)
+	s.mux.HandleFunc("GET /operations/referrers", s.handleReferrers)
 
 	// OP#8 - Compatibility
 	s.mux.HandleFunc("GET /compatibility", s.handleCompatibility)
+	s.mux.HandleFunc("GET /compatibility/series", s.handleCompatibilitySeries)
 
 	// OP#9 - Cast
 	s.mux.HandleFunc("POST /cast", s.handleCast)
+	s.mux.HandleFunc("POST /operations/cast", s.handleCast)
+	s.mux.HandleFunc("POST /operations/cast-batch", s.handleCastBatch)
+	s.mux.HandleFunc("POST /operations/apply-defaults", s.handleApplyDefaults)
 
 	// OP#10 - Query
 	s.mux.HandleFunc("GET /query", s.handleQuery)
 
 	// OP#11 - Attribute Access
 	s.mux.HandleFunc("GET /attr", s.handleAttribute)
+
+	// Instance ID allocation
+	s.mux.HandleFunc("POST /allocate-id", s.handleAllocateID)
+
+	// CloudEvents ingestion
+	s.mux.HandleFunc("POST /cloudevents", s.handleCloudEvent)
+
+	// Prometheus metrics
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
 }
 
 // Start starts the HTTP server
@@ -85,8 +147,13 @@ func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
 	log.Printf("Starting GTS server on http://%s", addr)
 
-	handler := s.withLogging(s.mux)
-	return http.ListenAndServe(addr, handler)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// Handler returns the server's routes wrapped with request ID, logging, metrics, CORS,
+// authentication and panic recovery middleware
+func (s *Server) Handler() http.Handler {
+	return s.withRequestID(s.withLogging(s.withMetrics(s.withCORS(s.withAuth(s.withRecovery(s.mux))))))
 }
 
 // Helper methods
@@ -99,10 +166,6 @@ func (s *Server) writeJSON(w http.ResponseWriter, status int, data any) {
 	}
 }
 
-func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
-	s.writeJSON(w, status, map[string]string{"error": message})
-}
-
 func (s *Server) readJSON(r *http.Request, v any) error {
 	return json.NewDecoder(r.Body).Decode(v)
 }
@@ -150,11 +213,248 @@ func (s *Server) GetOpenAPISpec() map[string]any {
 							"description": "Maximum number of entities to return",
 							"schema":      map[string]any{"type": "integer", "default": 100},
 						},
+						{
+							"name":        "since",
+							"in":          "query",
+							"description": "Only return entities registered or updated at or after this RFC3339 timestamp, oldest first",
+							"schema":      map[string]any{"type": "string", "format": "date-time"},
+						},
+						{
+							"name":        "cursor",
+							"in":          "query",
+							"description": "Continue after the page whose next_cursor this is; entities are listed in GTS ID order",
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "kind",
+							"in":          "query",
+							"description": "List schemas or instances only; total counts the entities of the kind",
+							"schema":      map[string]any{"type": "string", "enum": []string{"all", "schema", "instance"}},
+						},
+						{
+							"name":        "pattern",
+							"in":          "query",
+							"description": "GTS ID or wildcard pattern the listed IDs match, as in /query, e.g. gts.x.commerce.*; an invalid pattern answers 400",
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "orphans",
+							"in":          "query",
+							"description": "List only the instances whose schema is not registered",
+							"schema":      map[string]any{"type": "boolean"},
+						},
 					},
 				},
 				"post": map[string]any{
 					"summary":     "Register a single entity (object or schema)",
 					"operationId": "addEntity",
+					"description": "With dry_run=true nothing is registered and the response is the registration plan: is_new, overwrites, for schemas the compatibility_with_previous with the overwritten schema or the highest lower registered minor version (previous_id), and the validation_errors of every check, including instance validation. A dry run is answered by a frozen store too.",
+					"parameters": []map[string]any{
+						{"name": "validation", "in": "query", "schema": map[string]any{"type": "boolean"}},
+						{"name": "dry_run", "in": "query", "schema": map[string]any{"type": "boolean"}},
+					},
+				},
+			},
+			"/entities:batch": map[string]any{
+				"post": map[string]any{
+					"summary":     "Register a JSON array of entities (same as /entities/bulk)",
+					"operationId": "addEntitiesBatch",
+					"description": "With atomic=true every entity is validated first, references between entities of the batch resolving whatever their order, and the batch is committed only if all pass; otherwise nothing is registered and the results detail every failure. With ordered=false the entities are registered in dependency order rather than array order, schemas before instances and referenced entities before the entities referencing them; results keep array order and order lists the array indices in registration order.",
+					"parameters": []map[string]any{
+						{"name": "atomic", "in": "query", "schema": map[string]any{"type": "boolean"}},
+						{"name": "ordered", "in": "query", "schema": map[string]any{"type": "boolean", "default": true}},
+					},
+				},
+			},
+			"/entities:upload": map[string]any{
+				"post": map[string]any{
+					"summary":     "Register the entities of uploaded JSON files and zip archives (multipart/form-data)",
+					"operationId": "uploadEntities",
+					"description": "Every file part is a JSON document, a JSON array or a zip archive read like a directory. The response lists a result per document with aggregate counts; files over the size limits and archives inflating past the request size limit or nested too deeply answer 413.",
+				},
+			},
+			"/entities/by-uuid/{uuid}": map[string]any{
+				"get": map[string]any{
+					"summary":     "Get the registered entity whose GTS ID derives a UUID",
+					"operationId": "getEntityByUUID",
+					"description": "The reverse of GET /uuid: the UUID is matched against the UUIDs derived from the registered IDs. The response and its path and fields selectors are those of GET /entities/{id}. An invalid UUID answers 400, a UUID no registered ID derives 404.",
+					"parameters": []map[string]any{
+						{
+							"name":        "uuid",
+							"in":          "path",
+							"description": "UUID of the entity",
+							"required":    true,
+							"schema":      map[string]any{"type": "string", "format": "uuid"},
+						},
+						{
+							"name":        "path",
+							"in":          "query",
+							"description": "Attribute path to return, e.g. properties.name or required[0]; may be repeated",
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "fields",
+							"in":          "query",
+							"description": "Comma-separated attribute paths to return",
+							"schema":      map[string]any{"type": "string"},
+						},
+					},
+				},
+			},
+			"/entities/{id}": map[string]any{
+				"get": map[string]any{
+					"summary":     "Get an entity, or selected attribute paths of its content",
+					"operationId": "getEntity",
+					"description": "With path or fields the response is {id, values, missing}: the value of every selected path that resolves, and the paths that do not. In both cases the ETag is derived from the content_hash, updated_at and tags of the entity, and a matching If-None-Match answers 304.",
+					"parameters": []map[string]any{
+						{
+							"name":        "id",
+							"in":          "path",
+							"description": "GTS ID or short ID of the entity",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "path",
+							"in":          "query",
+							"description": "Attribute path to return, e.g. properties.name or required[0]; may be repeated",
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "fields",
+							"in":          "query",
+							"description": "Comma-separated attribute paths to return",
+							"schema":      map[string]any{"type": "string"},
+						},
+					},
+				},
+				"put": map[string]any{
+					"summary":     "Replace the content of a registered entity",
+					"operationId": "replaceEntity",
+					"description": "The ID in the body must match the path ID, otherwise 409 Conflict is returned; the path ID is matched exactly and unknown IDs answer 404. An entity written concurrently also answers 409. Schemas are checked as on registration and the response carries the compatibility of the new content with the old one. With validation=true instances are validated against their schema first. The old content is kept when a check fails.",
+					"parameters": []map[string]any{
+						{
+							"name":        "id",
+							"in":          "path",
+							"description": "GTS ID or short ID of the entity",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+						{"name": "validation", "in": "query", "schema": map[string]any{"type": "boolean"}},
+					},
+				},
+				"delete": map[string]any{
+					"summary":     "Unregister an entity",
+					"operationId": "deleteEntity",
+					"description": "With strict reference validation an entity other entities reference is refused with 409 Conflict listing them in referenced_by, unless force=true. Unknown IDs answer 404.",
+					"parameters": []map[string]any{
+						{
+							"name":        "id",
+							"in":          "path",
+							"description": "GTS ID or short ID of the entity",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+						{"name": "force", "in": "query", "schema": map[string]any{"type": "boolean"}},
+					},
+				},
+			},
+			"/entities/{id}/tags": map[string]any{
+				"put": map[string]any{
+					"summary":     "Replace the tags of an entity with the key/value object in the body",
+					"operationId": "setEntityTags",
+					"parameters": []map[string]any{
+						{
+							"name":        "id",
+							"in":          "path",
+							"description": "GTS ID or short ID of the entity to tag",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+					},
+				},
+			},
+			"/schemas/{id}": map[string]any{
+				"get": map[string]any{
+					"summary":     "Get the registered content of a schema as application/schema+json (HEAD is supported)",
+					"operationId": "getSchemaDocument",
+					"description": "The ETag is the SHA-256 of the served document; a matching If-None-Match answers 304. The '~' of GTS IDs may be sent as is or URL-encoded as %7E.",
+					"parameters": []map[string]any{
+						{
+							"name":        "id",
+							"in":          "path",
+							"description": "GTS ID or short ID of the schema",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "rewrite_id",
+							"in":          "query",
+							"description": "Rewrite $id and gts:// $refs to the URLs of this endpoint",
+							"schema":      map[string]any{"type": "boolean"},
+						},
+					},
+				},
+			},
+			"/prune": map[string]any{
+				"post": map[string]any{
+					"summary":     "Remove superseded schema versions, unused schemas and stale or orphaned instances",
+					"operationId": "prune",
+					"description": "The body is a policy {keep_minors, remove_unused, older_than, remove_orphans, protect, dry_run}; older_than is a Go duration such as 720h. Schemas are only removed when nothing left references them. The response lists every removed entity with its reason; a frozen store answers 409.",
+				},
+			},
+			"/export": map[string]any{
+				"get": map[string]any{
+					"summary":     "Export every entity as a bundle",
+					"operationId": "exportBundle",
+					"description": "The bundle is a JSON object whose entities array holds the entity contents, each entity after the entities it references and schemas first, and whose tags object maps the IDs of tagged entities to their tags; POST /import loads it into another registry. Timestamps are not exported.",
+				},
+			},
+			"/import": map[string]any{
+				"post": map[string]any{
+					"summary":     "Import a bundle written by GET /export",
+					"operationId": "importBundle",
+					"description": "Entities are registered in bundle order with reference validation. The response reports the status of each entity (added, replaced, skipped or failed) with counts; a body that is neither a bundle nor a JSON array of objects answers 400 and a frozen store 409.",
+					"parameters": []map[string]any{
+						{
+							"name":        "on_conflict",
+							"in":          "query",
+							"description": "What to do with registered IDs: keep-existing (skip them, default), overwrite, or fail (report them as failed unless their content is identical)",
+							"schema":      map[string]any{"type": "string", "enum": []string{"keep-existing", "overwrite", "fail"}},
+						},
+					},
+				},
+			},
+			"/state": map[string]any{
+				"get": map[string]any{
+					"summary":     "Get the runtime state of the registry (frozen, entity count, unresolved references, lookup statistics)",
+					"operationId": "getState",
+				},
+			},
+			"/stats": map[string]any{
+				"get": map[string]any{
+					"summary":     "Count schemas, instances, orphan instances, missing references and duplicate registrations, and the entities per vendor and package",
+					"operationId": "getStats",
+				},
+			},
+			"/integrity": map[string]any{
+				"get": map[string]any{
+					"summary":     "List orphan instances, references to unregistered entities and IDs loaded from several files, with their sources",
+					"operationId": "getIntegrity",
+				},
+			},
+			"/types": map[string]any{
+				"get": map[string]any{
+					"summary":     "Summarize type lines with their versions, instance counts and derived types",
+					"operationId": "getTypes",
+					"parameters": []map[string]any{
+						{
+							"name":        "pattern",
+							"in":          "query",
+							"description": "GTS ID or wildcard pattern selecting the types to summarize",
+							"schema":      map[string]any{"type": "string"},
+						},
+					},
 				},
 			},
 			"/validate-id": map[string]any{
@@ -197,42 +497,226 @@ func (s *Server) GetOpenAPISpec() map[string]any {
 				"get": map[string]any{
 					"summary":     "Generate UUID from a GTS ID",
 					"operationId": "uuid",
+					"parameters": []map[string]any{
+						{
+							"name":        "gts_id",
+							"in":          "query",
+							"description": "GTS ID to convert",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "tree",
+							"in":          "query",
+							"description": "Return the UUID of every segment prefix of a chained ID",
+							"schema":      map[string]any{"type": "boolean", "default": false},
+						},
+					},
+				},
+			},
+			"/uuid/verify": map[string]any{
+				"get": map[string]any{
+					"summary":     "Verify that the UUIDs of the registered IDs are collision-free and derived from canonical IDs, with totals per vendor",
+					"operationId": "verifyUUIDs",
 				},
 			},
 			"/validate-instance": map[string]any{
 				"post": map[string]any{
-					"summary":     "Validate an instance against its schema",
+					"summary":     "Validate an instance against its schema (same as /operations/validate)",
 					"operationId": "validateInstance",
+					"description": "An instance rejected by its schema is answered with 422 GTS_VALIDATION_FAILED whose details list every violation as {path, keyword, message}: the JSON Pointer of the failing value, the failed schema keyword (x-gts-ref included) and the message.",
+				},
+			},
+			"/operations/validate-all": map[string]any{
+				"post": map[string]any{
+					"summary":     "Validate every registered schema and instance",
+					"operationId": "validateAll",
+					"description": "Schemas are checked including their $ref and x-gts-ref constraints, instances against their schemas. The response holds the result of every entity with valid, invalid and skipped counts (instances without schema ID); ok is false when any entity is invalid.",
 				},
 			},
 			"/resolve-relationships": map[string]any{
 				"get": map[string]any{
 					"summary":     "Resolve relationships for an entity",
 					"operationId": "resolveRelationships",
+					"description": "Without depth, max_nodes or format the full graph is returned; with any of them the response reports node and edge counts and whether a limit truncated the graph. The graph of an instance starts with a schema edge to its schema and has a gts-ref edge for every value its schema constrains with x-gts-ref.",
+					"parameters": []map[string]any{
+						{
+							"name":        "gts_id",
+							"in":          "query",
+							"description": "GTS ID of the entity",
+							"required":    true,
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "depth",
+							"in":          "query",
+							"description": "Maximum number of edges from the entity (0 = unlimited)",
+							"schema":      map[string]any{"type": "integer", "minimum": 0},
+						},
+						{
+							"name":        "max_nodes",
+							"in":          "query",
+							"description": "Maximum number of nodes in the graph (0 = unlimited)",
+							"schema":      map[string]any{"type": "integer", "minimum": 0},
+						},
+						{
+							"name":        "format",
+							"in":          "query",
+							"description": "tree for the nested graph, edges for a flat list of {from, to, kind, source_path}",
+							"schema":      map[string]any{"type": "string", "enum": []string{"tree", "edges"}, "default": "tree"},
+						},
+					},
+				},
+			},
+			"/lineage": map[string]any{
+				"get": map[string]any{
+					"summary":     "Property lineage of a schema",
+					"operationId": "lineage",
+					"description": "Maps every property path of the flattened schema to the layers of its allOf hierarchy that introduced and overrode it, with the keywords each override changed",
+					"parameters": []map[string]any{
+						{"name": "gts_id", "in": "query", "required": true, "schema": map[string]any{"type": "string"}},
+					},
+				},
+			},
+			"/operations/referrers": map[string]any{
+				"get": map[string]any{
+					"summary":     "List the registered entities referencing an ID",
+					"operationId": "referrers",
+					"description": "Referrers are grouped by the keyword carrying the reference ($ref, $schema, x-gts-ref, another property, or schema_id for the schema of an instance or the base of a derived schema), each with its source path",
+					"parameters": []map[string]any{
+						{"name": "gts_id", "in": "query", "required": true, "schema": map[string]any{"type": "string"}},
+						{
+							"name":        "include_minor_versions",
+							"in":          "query",
+							"description": "Also count references to minor versions of a schema ID without minor version",
+							"schema":      map[string]any{"type": "boolean"},
+						},
+					},
 				},
 			},
 			"/compatibility": map[string]any{
 				"get": map[string]any{
 					"summary":     "Check compatibility between two schemas",
 					"operationId": "compatibility",
+					"description": "With include_diff=true the response carries diff: the RFC 6902 JSON Patch turning the old schema into the new one, both resolved with their allOf layers merged and the GTS schemas their $refs name inlined, and a summary line per operation. Operations are sorted by path, nested properties and array item schemas included.",
+					"parameters": []map[string]any{
+						{"name": "old_schema_id", "in": "query", "required": true, "schema": map[string]any{"type": "string"}},
+						{"name": "new_schema_id", "in": "query", "required": true, "schema": map[string]any{"type": "string"}},
+						{"name": "include_diff", "in": "query", "schema": map[string]any{"type": "boolean"}},
+					},
+				},
+			},
+			"/compatibility/series": map[string]any{
+				"get": map[string]any{
+					"summary":     "Check compatibility across the registered minor versions of a type",
+					"operationId": "compatibilitySeries",
+					"description": "type_id is a schema ID without minor version, e.g. gts.x.core.compat.event.v1~. Its registered minor versions are checked pairwise in minor version order and between the lowest and the highest; is_backward_compatible holds when every check does. Unregistered minor versions inside the series are listed in missing_versions. A type without registered minor version answers 404.",
+					"parameters": []map[string]any{
+						{"name": "type_id", "in": "query", "required": true, "schema": map[string]any{"type": "string"}},
+					},
 				},
 			},
 			"/cast": map[string]any{
 				"post": map[string]any{
-					"summary":     "Cast an instance to a target schema",
+					"summary":     "Cast an instance to a target schema (same as /operations/cast)",
 					"operationId": "cast",
+					"description": "The body holds to_schema_id and either instance_id, a registered instance, or instance, instance content cast without being registered; its schema is resolved from the content as on registration. Across major versions the registered migration map for the pair (an instance of gts.x.core.migration.map.v1~) is applied first and reported in migration_map_id and migration_operations; without one the cast answers 422.",
+				},
+			},
+			"/operations/cast-batch": map[string]any{
+				"post": map[string]any{
+					"summary":     "Cast every instance matching a pattern to a target schema",
+					"operationId": "castBatch",
+					"description": "The body holds pattern (a query expression), to_schema_id and an optional limit. The response lists the outcome of every matched instance, cast, failed or skipped when its schema is another type or major version not covered by a migration map, with summary counts.",
+				},
+			},
+			"/operations/apply-defaults": map[string]any{
+				"post": map[string]any{
+					"summary":     "Fill the defaults declared by the schema of an instance",
+					"operationId": "applyDefaults",
+					"description": "The body holds either instance_id, a registered instance, or instance, instance content that is not registered, with an optional schema_id to take the defaults from instead of the schema resolved from the content. Missing properties declaring a default are set, in nested objects and arrays of objects too, and listed in added; properties are never removed and consts never rewritten, so an instance holding every default comes back unchanged. Required properties without default are listed in missing_required. Nothing is registered.",
 				},
 			},
 			"/query": map[string]any{
 				"get": map[string]any{
 					"summary":     "Query entities using an expression",
 					"operationId": "query",
+					"description": "With explain=true the response is the query plan instead of the results: pattern, scan strategy, scanned entity count, per-filter candidates in and out, match count and duration. With ids_only=true the matches are listed in ids instead of results; with fields (or repeated path) every result is {id, values} holding the selected attribute paths that resolve. With order_by the matches are sorted before the limit applies. An invalid expression is answered with 400 and details.error_code query_invalid.",
+					"parameters": []map[string]any{
+						{"name": "expr", "in": "query", "required": true, "schema": map[string]any{"type": "string"}},
+						{"name": "limit", "in": "query", "schema": map[string]any{"type": "integer"}},
+						{"name": "explain", "in": "query", "schema": map[string]any{"type": "boolean"}},
+						{"name": "ids_only", "in": "query", "schema": map[string]any{"type": "boolean"}},
+						{
+							"name":        "order_by",
+							"in":          "query",
+							"description": "gtsId or an attribute path to sort the matches by, optionally followed by asc or desc, e.g. 'occurredAt desc'; matches without the attribute sort last",
+							"schema":      map[string]any{"type": "string"},
+						},
+						{"name": "desc", "in": "query", "description": "Sort in descending order", "schema": map[string]any{"type": "boolean"}},
+						{
+							"name":        "fields",
+							"in":          "query",
+							"description": "Comma-separated attribute paths to return for every match, e.g. payload.orderId,status",
+							"schema":      map[string]any{"type": "string"},
+						},
+						{
+							"name":        "path",
+							"in":          "query",
+							"description": "Attribute path to return for every match; may be repeated",
+							"schema":      map[string]any{"type": "string"},
+						},
+					},
 				},
 			},
 			"/attr": map[string]any{
 				"get": map[string]any{
 					"summary":     "Get attribute value from a GTS entity",
 					"operationId": "attr",
+					"description": "A path that does not resolve is answered with details.error_code: 404 GTS_ATTRIBUTE_NOT_FOUND for attr_not_found and attr_index_out_of_range, 400 GTS_BAD_REQUEST for attr_path_invalid. An unknown entity is answered with 404 GTS_ENTITY_NOT_FOUND.",
+				},
+			},
+			"/allocate-id": map[string]any{
+				"post": map[string]any{
+					"summary":     "Allocate the next free instance ID under a type",
+					"operationId": "allocateID",
+				},
+			},
+			"/cloudevents": map[string]any{
+				"post": map[string]any{
+					"summary":     "Validate and register the instance carried by a structured-mode CloudEvent",
+					"operationId": "ingestCloudEvent",
+				},
+			},
+			"/metrics": map[string]any{
+				"get": map[string]any{
+					"summary":     "Metrics in the Prometheus text format",
+					"operationId": "getMetrics",
+					"description": "Request and error counters per endpoint, the number of entities in the store and histograms of validation and cast durations.",
+				},
+			},
+		},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Error": map[string]any{
+					"description": "Body of every error response",
+					"type":        "object",
+					"properties": map[string]any{
+						"error": map[string]any{
+							"type":     "object",
+							"required": []string{"code", "message"},
+							"properties": map[string]any{
+								"code": map[string]any{
+									"type": "string",
+									"enum": []string{ErrorCodeInvalidID, ErrorCodeEntityNotFound, ErrorCodeSchemaNotFound,
+										ErrorCodeAttributeNotFound, ErrorCodeValidationFailed, ErrorCodeConflict, ErrorCodeBadRequest,
+										ErrorCodeUnauthorized, ErrorCodeInternal},
+								},
+								"message": map[string]any{"type": "string"},
+								"details": map[string]any{"type": "object"},
+							},
+						},
+					},
 				},
 			},
 		},
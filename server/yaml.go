@@ -0,0 +1,116 @@
+/*
+Copyright © 2025 Global Type System
+Released under Apache License 2.0
+*/
+
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// plainYAMLKey matches the mapping keys written without quotes
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// WriteYAML writes a JSON-compatible value as a YAML document with mapping keys in sorted order,
+// so that the output is stable. Strings are written as double-quoted scalars, which YAML reads the
+// same way as JSON strings.
+func WriteYAML(w io.Writer, v any) error {
+	// Round-trip through JSON to reduce v to maps, slices and scalars
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	writeYAMLValue(bw, value, 0)
+	return bw.Flush()
+}
+
+// writeYAMLValue writes value as the content of a node at indent; block collections start on a
+// new line, scalars and empty collections follow on the current one
+func writeYAMLValue(w *bufio.Writer, value any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			w.WriteString("{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			w.WriteString(pad + yamlKey(key) + ":")
+			writeYAMLChild(w, v[key], indent+1)
+		}
+	case []any:
+		if len(v) == 0 {
+			w.WriteString("[]\n")
+			return
+		}
+		for _, item := range v {
+			w.WriteString(pad + "-")
+			writeYAMLChild(w, item, indent+1)
+		}
+	default:
+		w.WriteString(yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLChild writes the value following a key or list marker
+func writeYAMLChild(w *bufio.Writer, value any, indent int) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) > 0 {
+			w.WriteString("\n")
+			writeYAMLValue(w, v, indent)
+			return
+		}
+	case []any:
+		if len(v) > 0 {
+			w.WriteString("\n")
+			writeYAMLValue(w, v, indent)
+			return
+		}
+	}
+	w.WriteString(" ")
+	writeYAMLValue(w, value, indent)
+}
+
+// yamlKey returns a mapping key, quoted unless it is a plain identifier
+func yamlKey(key string) string {
+	if plainYAMLKey.MatchString(key) && !isYAMLKeyword(key) {
+		return key
+	}
+	return yamlScalar(key)
+}
+
+// isYAMLKeyword reports whether a plain scalar would be read as a boolean or null
+func isYAMLKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	return false
+}
+
+// yamlScalar returns a scalar in its JSON form, which is valid YAML
+func yamlScalar(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}